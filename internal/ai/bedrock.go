@@ -0,0 +1,301 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// bedrockUsage is the token usage in Converse responses
+type bedrockUsage struct {
+	InputTokens  int `json:"inputTokens"`
+	OutputTokens int `json:"outputTokens"`
+}
+
+// AWS Bedrock Converse API format, which works the same for Claude, Llama
+// and the other Bedrock models
+func (c *Client) callBedrock(ctx context.Context, settings config.AISettings, messages []Message) Response {
+	resp, err := c.bedrockRequest(ctx, settings, messages, "converse")
+	if err != nil {
+		return Response{Error: err}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Response{Error: err}
+	}
+
+	if resp.StatusCode != 200 {
+		return Response{Error: &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}}
+	}
+
+	var result struct {
+		Output struct {
+			Message struct {
+				Content []struct {
+					Text string `json:"text"`
+				} `json:"content"`
+			} `json:"message"`
+		} `json:"output"`
+		Usage bedrockUsage `json:"usage"`
+	}
+
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return Response{Error: err}
+	}
+	recordTokens("bedrock", result.Usage.InputTokens, result.Usage.OutputTokens)
+
+	if len(result.Output.Message.Content) == 0 {
+		return Response{Error: fmt.Errorf("no response from API")}
+	}
+
+	var content strings.Builder
+	for _, block := range result.Output.Message.Content {
+		content.WriteString(block.Text)
+	}
+	return Response{Content: content.String()}
+}
+
+// streamBedrock reads a ConverseStream response, which is in the AWS event
+// stream encoding
+func (c *Client) streamBedrock(ctx context.Context, settings config.AISettings, messages []Message, onChunk func(string)) Response {
+	resp, err := c.bedrockRequest(ctx, settings, messages, "converse-stream")
+	if err != nil {
+		return Response{Error: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return Response{Error: &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}}
+	}
+
+	var content strings.Builder
+	for {
+		headers, payload, err := readEventStreamMessage(resp.Body)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Response{Content: content.String(), Error: err}
+		}
+
+		if headers[":message-type"] == "exception" {
+			return Response{Content: content.String(), Error: fmt.Errorf("%s: %s", headers[":exception-type"], payload)}
+		}
+		switch headers[":event-type"] {
+		case "contentBlockDelta":
+			var event struct {
+				Delta struct {
+					Text string `json:"text"`
+				} `json:"delta"`
+			}
+			if err := json.Unmarshal(payload, &event); err != nil {
+				return Response{Content: content.String(), Error: err}
+			}
+			if event.Delta.Text != "" {
+				content.WriteString(event.Delta.Text)
+				onChunk(event.Delta.Text)
+			}
+		case "metadata":
+			var event struct {
+				Usage bedrockUsage `json:"usage"`
+			}
+			if json.Unmarshal(payload, &event) == nil {
+				recordTokens("bedrock", event.Usage.InputTokens, event.Usage.OutputTokens)
+			}
+		}
+	}
+
+	return Response{Content: content.String()}
+}
+
+// bedrockRequest sends messages to the model's Converse operation, e.g.
+// converse or converse-stream
+func (c *Client) bedrockRequest(ctx context.Context, settings config.AISettings, messages []Message, operation string) (*http.Response, error) {
+	model := c.provider.DefaultModel
+	if model == "" {
+		model = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+	}
+
+	// Convert messages to Converse format: the system prompt is separate and
+	// content is a list of blocks
+	type block struct {
+		Text string `json:"text"`
+	}
+	type message struct {
+		Role    string  `json:"role"`
+		Content []block `json:"content"`
+	}
+	reqBody := map[string]interface{}{}
+	var converseMessages []message
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			reqBody["system"] = []block{{Text: msg.Content}}
+		} else {
+			converseMessages = append(converseMessages, message{Role: msg.Role, Content: []block{{Text: msg.Content}}})
+		}
+	}
+	reqBody["messages"] = converseMessages
+
+	inferenceConfig := map[string]interface{}{}
+	if settings.Temperature != nil {
+		inferenceConfig["temperature"] = *settings.Temperature
+	}
+	if settings.MaxTokens > 0 {
+		inferenceConfig["maxTokens"] = settings.MaxTokens
+	}
+	if len(inferenceConfig) > 0 {
+		reqBody["inferenceConfig"] = inferenceConfig
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	region := c.bedrockRegion()
+	baseURL := c.provider.BaseURL
+	if baseURL == "" {
+		baseURL = "https://bedrock-runtime." + region + ".amazonaws.com"
+	}
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	// Model IDs contain ":", which has to be escaped in the path
+	basePath := u.EscapedPath()
+	u.Path += "/model/" + model + "/" + operation
+	u.RawPath = basePath + "/model/" + awsEscape(model) + "/" + operation
+
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	creds, err := c.awsCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signV4(req, body, creds, region, "bedrock", time.Now())
+
+	return c.httpClient.Do(req)
+}
+
+// bedrockRegion returns the provider's region, or the AWS default
+func (c *Client) bedrockRegion() string {
+	for _, region := range []string{c.provider.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION")} {
+		if region != "" {
+			return region
+		}
+	}
+	return "us-east-1"
+}
+
+// awsCredentials returns the provider's AWS keys, then those in the
+// environment, then those the AWS CLI resolves for the current profile
+func (c *Client) awsCredentials(ctx context.Context) (awsCredentials, error) {
+	if c.provider.AccessKeyID != "" && c.provider.SecretAccessKey != "" {
+		return awsCredentials{AccessKeyID: c.provider.AccessKeyID, SecretAccessKey: c.provider.SecretAccessKey}, nil
+	}
+	if id, secret := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); id != "" && secret != "" {
+		return awsCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	out, err := exec.CommandContext(ctx, "aws", "configure", "export-credentials", "--format", "process").Output()
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to get AWS credentials: %w", err)
+	}
+	var creds awsCredentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to parse AWS credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// readEventStreamMessage reads one message in the AWS event stream
+// encoding and returns its string headers and payload
+func readEventStreamMessage(r io.Reader) (map[string]string, []byte, error) {
+	var prelude [12]byte
+	if _, err := io.ReadFull(r, prelude[:]); err != nil {
+		return nil, nil, err
+	}
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, nil, fmt.Errorf("event stream prelude checksum mismatch")
+	}
+	if totalLen < 16+headersLen || totalLen > 16<<20 {
+		return nil, nil, fmt.Errorf("invalid event stream message length %d", totalLen)
+	}
+
+	rest := make([]byte, totalLen-12)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, nil, fmt.Errorf("failed to read event stream message: %w", err)
+	}
+	crc := crc32.Update(crc32.ChecksumIEEE(prelude[:]), crc32.IEEETable, rest[:len(rest)-4])
+	if crc != binary.BigEndian.Uint32(rest[len(rest)-4:]) {
+		return nil, nil, fmt.Errorf("event stream message checksum mismatch")
+	}
+
+	headers := make(map[string]string)
+	h := rest[:headersLen]
+	for len(h) > 0 {
+		nameLen := int(h[0])
+		if len(h) < 2+nameLen {
+			return nil, nil, fmt.Errorf("invalid event stream header")
+		}
+		name := string(h[1 : 1+nameLen])
+		valueType := h[1+nameLen]
+		h = h[2+nameLen:]
+
+		// Value sizes by type: bool, byte, short, int, long, bytes,
+		// string, timestamp, uuid
+		var size int
+		switch valueType {
+		case 0, 1:
+			size = 0
+		case 2:
+			size = 1
+		case 3:
+			size = 2
+		case 4:
+			size = 4
+		case 5, 8:
+			size = 8
+		case 6, 7:
+			if len(h) < 2 {
+				return nil, nil, fmt.Errorf("invalid event stream header")
+			}
+			size = 2 + int(binary.BigEndian.Uint16(h))
+		case 9:
+			size = 16
+		default:
+			return nil, nil, fmt.Errorf("unknown event stream header type %d", valueType)
+		}
+		if len(h) < size {
+			return nil, nil, fmt.Errorf("invalid event stream header")
+		}
+		if valueType == 7 {
+			headers[name] = string(h[2:size])
+		}
+		h = h[size:]
+	}
+
+	return headers, rest[headersLen : len(rest)-4], nil
+}
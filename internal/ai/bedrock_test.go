@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestSignV4(t *testing.T) {
+	// The get-vanilla case of the AWS SigV4 test suite
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s, want %s", got, want)
+	}
+}
+
+// eventStreamMessage encodes payload as an AWS event stream message with
+// string headers
+func eventStreamMessage(headers map[string]string, payload string) []byte {
+	var h bytes.Buffer
+	for name, value := range headers {
+		h.WriteByte(byte(len(name)))
+		h.WriteString(name)
+		h.WriteByte(7)
+		binary.Write(&h, binary.BigEndian, uint16(len(value)))
+		h.WriteString(value)
+	}
+	var msg bytes.Buffer
+	binary.Write(&msg, binary.BigEndian, uint32(16+h.Len()+len(payload)))
+	binary.Write(&msg, binary.BigEndian, uint32(h.Len()))
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(h.Bytes())
+	msg.WriteString(payload)
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	return msg.Bytes()
+}
+
+func TestBedrock(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		paths = append(paths, r.URL.EscapedPath())
+		if strings.HasSuffix(r.URL.Path, "/converse") {
+			w.Write([]byte(`{"output":{"message":{"content":[{"text":"kubectl get pods"}]}}}`))
+			return
+		}
+		delta := map[string]string{":message-type": "event", ":event-type": "contentBlockDelta"}
+		w.Write(eventStreamMessage(delta, `{"delta":{"text":"Run "}}`))
+		w.Write(eventStreamMessage(delta, `{"delta":{"text":"kubectl get pods"}}`))
+		w.Write(eventStreamMessage(map[string]string{":message-type": "event", ":event-type": "messageStop"}, `{}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(config.ProviderConfig{
+		Name:            "bedrock",
+		ProviderType:    "bedrock",
+		BaseURL:         srv.URL,
+		Region:          "eu-west-1",
+		DefaultModel:    "meta.llama3-70b-instruct-v1:0",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+
+	if resp := c.GenerateCommand("list pods", ""); resp.Error != nil || resp.Content != "kubectl get pods" {
+		t.Fatalf("GenerateCommand() = %+v", resp)
+	}
+	var chunks []string
+	resp := c.AskStream("how do I list pods?", "", func(s string) { chunks = append(chunks, s) })
+	if resp.Error != nil || resp.Content != "Run kubectl get pods" || len(chunks) != 2 {
+		t.Errorf("AskStream() = %+v, chunks %q", resp, chunks)
+	}
+	if len(paths) != 2 || paths[0] != "/model/meta.llama3-70b-instruct-v1%3A0/converse" {
+		t.Errorf("paths = %q", paths)
+	}
+}
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,8 +11,12 @@ import (
 	"time"
 
 	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/metrics"
 )
 
+// DefaultTimeout bounds a request unless the provider sets a timeout
+const DefaultTimeout = 60 * time.Second
+
 // Client handles AI provider API calls
 type Client struct {
 	provider   config.ProviderConfig
@@ -28,20 +33,25 @@ type Message struct {
 type Response struct {
 	Content string
 	Error   error
+	// Set by Router: the provider that answered and the providers that
+	// failed before it
+	Provider   string
+	FailedOver []Failure
 }
 
 // NewClient creates a new AI client for the given provider
 func NewClient(provider config.ProviderConfig) *Client {
 	return &Client{
-		provider: provider,
-		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		provider:   provider,
+		httpClient: &http.Client{},
 	}
 }
 
 // GetDefaultClient returns a client for the default provider
 func GetDefaultClient(cfg config.Config) (*Client, error) {
+	if cfg.Policy.AIDisabled {
+		return nil, config.ErrAIDisabled
+	}
 	if cfg.AI.DefaultProvider == "" {
 		return nil, fmt.Errorf("no default provider configured")
 	}
@@ -57,29 +67,38 @@ func GetDefaultClient(cfg config.Config) (*Client, error) {
 
 // Ask sends a question to the AI with optional context
 func (c *Client) Ask(question string, context string) Response {
-	systemPrompt := `You are a helpful CLI assistant for skitz, a command center tool.
+	return c.chat(config.FeatureAsk, c.askMessages(question, context))
+}
+
+// AskStream is Ask with the answer passed to onChunk as it arrives.
+// Providers without streaming pass the whole answer at once.
+func (c *Client) AskStream(question string, context string, onChunk func(string)) Response {
+	return c.Stream(config.FeatureAsk, c.askMessages(question, context), onChunk)
+}
+
+// askMessages builds the messages for a question
+func (c *Client) askMessages(question, context string) []Message {
+	systemPrompt := c.systemPrompt(config.FeatureAsk, `You are a helpful CLI assistant for skitz, a command center tool.
 You help users understand and work with command-line tools.
 Be concise and practical. When suggesting commands, format them in backticks.
-If you suggest a runnable command, put it on its own line starting with $ like: $ command here`
+If you suggest a runnable command, put it on its own line starting with $ like: $ command here`)
 
 	if context != "" {
-		systemPrompt += "\n\nHere is the current resource content for context:\n" + context
+		systemPrompt += "\n\nHere is the current resource content and any attached context:\n" + context
 	}
 
-	messages := []Message{
+	return []Message{
 		{Role: "system", Content: systemPrompt},
 		{Role: "user", Content: question},
 	}
-
-	return c.chat(messages)
 }
 
 // GenerateCommand asks the AI to generate a specific command
 func (c *Client) GenerateCommand(description string, context string) Response {
-	systemPrompt := `You are a command generator for CLI tools.
+	systemPrompt := c.systemPrompt(config.FeatureGenerateCommand, `You are a command generator for CLI tools.
 Given a description of what the user wants to do, generate the appropriate command.
 ONLY output the command itself, nothing else. No explanation, no backticks, just the raw command.
-If you cannot generate a valid command, respond with "ERROR: " followed by a brief explanation.`
+If you cannot generate a valid command, respond with "ERROR: " followed by a brief explanation.`)
 
 	if context != "" {
 		systemPrompt += "\n\nHere are example commands from the current resource:\n" + context
@@ -90,7 +109,73 @@ If you cannot generate a valid command, respond with "ERROR: " followed by a bri
 		{Role: "user", Content: description},
 	}
 
-	return c.chat(messages)
+	return c.chat(config.FeatureGenerateCommand, messages)
+}
+
+// Review asks the AI to review code following prompt, or the provider's
+// review system prompt when prompt is empty
+func (c *Client) Review(prompt, code string) Response {
+	if prompt == "" {
+		prompt = c.systemPrompt(config.FeatureReview, `You are an experienced code reviewer.
+Review the code or diff for bugs, security problems, and maintainability issues.
+List each finding with the line or function it concerns, most important first.
+Be concise. If there is nothing worth changing, say so.`)
+	}
+
+	messages := []Message{
+		{Role: "system", Content: prompt},
+		{Role: "user", Content: code},
+	}
+
+	return c.chat(config.FeatureReview, messages)
+}
+
+// systemPrompt returns the provider's system prompt for feature, or def
+func (c *Client) systemPrompt(feature, def string) string {
+	if p := c.provider.SettingsFor(feature).SystemPrompt; p != "" {
+		return p
+	}
+	return def
+}
+
+// DescribeCommands asks the AI for a short description of each command.
+// The result has one entry per command; commands the AI skipped get an
+// empty description.
+func (c *Client) DescribeCommands(commands []string) ([]string, error) {
+	systemPrompt := `You describe shell commands for a command reference.
+For each numbered command, write a short description of what it does (at most 8 words).
+Output exactly one line per command in the form "N: description". No other text.`
+
+	var prompt strings.Builder
+	for i, cmd := range commands {
+		fmt.Fprintf(&prompt, "%d: %s\n", i+1, cmd)
+	}
+
+	resp := c.chat("", []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: prompt.String()},
+	})
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	return parseNumberedLines(resp.Content, len(commands)), nil
+}
+
+// parseNumberedLines maps "N: text" lines to a slice of n entries.
+func parseNumberedLines(content string, n int) []string {
+	out := make([]string, n)
+	for _, line := range strings.Split(content, "\n") {
+		num, text, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		var i int
+		if _, err := fmt.Sscanf(strings.TrimSpace(num), "%d", &i); err != nil || i < 1 || i > n {
+			continue
+		}
+		out[i-1] = strings.TrimSpace(text)
+	}
+	return out
 }
 
 // DetectProviderType determines the provider type from API key format, URL, or name
@@ -99,6 +184,9 @@ func DetectProviderType(apiKey, baseURL, name string) string {
 	if strings.HasPrefix(apiKey, "sk-ant-") {
 		return "anthropic"
 	}
+	if strings.HasPrefix(apiKey, "AIza") {
+		return "gemini"
+	}
 	if strings.HasPrefix(apiKey, "sk-") && !strings.HasPrefix(apiKey, "sk-ant-") {
 		return "openai"
 	}
@@ -113,6 +201,15 @@ func DetectProviderType(apiKey, baseURL, name string) string {
 	if strings.Contains(baseURL, "11434") || strings.Contains(baseURL, "ollama") {
 		return "ollama"
 	}
+	if strings.Contains(baseURL, "aiplatform.googleapis.com") {
+		return "vertex"
+	}
+	if strings.Contains(baseURL, "generativelanguage.googleapis.com") {
+		return "gemini"
+	}
+	if strings.Contains(baseURL, "bedrock") {
+		return "bedrock"
+	}
 
 	// 3. Check name as fallback
 	nameLower := strings.ToLower(name)
@@ -122,6 +219,12 @@ func DetectProviderType(apiKey, baseURL, name string) string {
 	if strings.Contains(nameLower, "openai") || strings.Contains(nameLower, "gpt") {
 		return "openai"
 	}
+	if strings.Contains(nameLower, "gemini") {
+		return "gemini"
+	}
+	if strings.Contains(nameLower, "bedrock") {
+		return "bedrock"
+	}
 	if strings.Contains(nameLower, "ollama") || strings.Contains(nameLower, "llama") {
 		return "ollama"
 	}
@@ -130,23 +233,63 @@ func DetectProviderType(apiKey, baseURL, name string) string {
 	return "openai"
 }
 
-func (c *Client) chat(messages []Message) Response {
-	// Use explicit provider type if set, otherwise detect
-	providerType := c.provider.ProviderType
-	if providerType == "" {
-		providerType = DetectProviderType(c.provider.APIKey, c.provider.BaseURL, c.provider.Name)
-	}
+// chat sends messages with the provider's settings for feature
+func (c *Client) chat(feature string, messages []Message) Response {
+	settings := c.provider.SettingsFor(feature)
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout(settings))
+	defer cancel()
 
-	switch providerType {
+	switch c.providerType() {
 	case "anthropic":
-		return c.callAnthropic(messages)
+		return c.callAnthropic(ctx, settings, messages)
 	case "ollama":
-		return c.callOllama(messages)
+		return c.callOllama(ctx, settings, messages)
+	case "gemini", "vertex":
+		return c.callGemini(ctx, settings, messages)
+	case "bedrock":
+		return c.callBedrock(ctx, settings, messages)
 	default:
-		return c.callOpenAI(messages)
+		return c.callOpenAI(ctx, settings, messages)
 	}
 }
 
+// Stream sends messages like chat, passing the response to onChunk as it
+// arrives. Gemini, Vertex AI and Bedrock stream; other providers pass the
+// whole response at once.
+func (c *Client) Stream(feature string, messages []Message, onChunk func(string)) Response {
+	stream := c.streamGemini
+	switch c.providerType() {
+	case "gemini", "vertex":
+	case "bedrock":
+		stream = c.streamBedrock
+	default:
+		resp := c.chat(feature, messages)
+		if resp.Error == nil {
+			onChunk(resp.Content)
+		}
+		return resp
+	}
+
+	settings := c.provider.SettingsFor(feature)
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout(settings))
+	defer cancel()
+	return stream(ctx, settings, messages, onChunk)
+}
+
+// requestTimeout returns the timeout in settings, or DefaultTimeout
+func requestTimeout(settings config.AISettings) time.Duration {
+	if settings.Timeout > 0 {
+		return settings.Timeout
+	}
+	return DefaultTimeout
+}
+
+// recordTokens adds the token usage reported by a provider to the metrics
+func recordTokens(provider string, input, output int) {
+	metrics.AITokens.Add(float64(input), provider, "input")
+	metrics.AITokens.Add(float64(output), provider, "output")
+}
+
 // TestConnection verifies the provider connection works
 func (c *Client) TestConnection() error {
 	// Send a minimal request to verify authentication
@@ -154,12 +297,12 @@ func (c *Client) TestConnection() error {
 		{Role: "user", Content: "Hi"},
 	}
 
-	resp := c.chat(messages)
+	resp := c.chat("", messages)
 	return resp.Error
 }
 
 // OpenAI API format
-func (c *Client) callOpenAI(messages []Message) Response {
+func (c *Client) callOpenAI(ctx context.Context, settings config.AISettings, messages []Message) Response {
 	baseURL := c.provider.BaseURL
 	if baseURL == "" {
 		baseURL = "https://api.openai.com/v1"
@@ -174,13 +317,19 @@ func (c *Client) callOpenAI(messages []Message) Response {
 		"model":    model,
 		"messages": messages,
 	}
+	if settings.Temperature != nil {
+		reqBody["temperature"] = *settings.Temperature
+	}
+	if settings.MaxTokens > 0 {
+		reqBody["max_tokens"] = settings.MaxTokens
+	}
 
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return Response{Error: err}
 	}
 
-	req, err := http.NewRequest("POST", baseURL+"/chat/completions", bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/chat/completions", bytes.NewReader(body))
 	if err != nil {
 		return Response{Error: err}
 	}
@@ -200,7 +349,7 @@ func (c *Client) callOpenAI(messages []Message) Response {
 	}
 
 	if resp.StatusCode != 200 {
-		return Response{Error: fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))}
+		return Response{Error: &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}}
 	}
 
 	var result struct {
@@ -209,11 +358,16 @@ func (c *Client) callOpenAI(messages []Message) Response {
 				Content string `json:"content"`
 			} `json:"message"`
 		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		return Response{Error: err}
 	}
+	recordTokens("openai", result.Usage.PromptTokens, result.Usage.CompletionTokens)
 
 	if len(result.Choices) == 0 {
 		return Response{Error: fmt.Errorf("no response from API")}
@@ -223,7 +377,7 @@ func (c *Client) callOpenAI(messages []Message) Response {
 }
 
 // Anthropic API format
-func (c *Client) callAnthropic(messages []Message) Response {
+func (c *Client) callAnthropic(ctx context.Context, settings config.AISettings, messages []Message) Response {
 	baseURL := c.provider.BaseURL
 	if baseURL == "" {
 		baseURL = "https://api.anthropic.com"
@@ -249,11 +403,18 @@ func (c *Client) callAnthropic(messages []Message) Response {
 		}
 	}
 
+	maxTokens := settings.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = 2048
+	}
 	reqBody := map[string]interface{}{
 		"model":      model,
-		"max_tokens": 2048,
+		"max_tokens": maxTokens,
 		"messages":   anthropicMessages,
 	}
+	if settings.Temperature != nil {
+		reqBody["temperature"] = *settings.Temperature
+	}
 	if systemPrompt != "" {
 		reqBody["system"] = systemPrompt
 	}
@@ -263,7 +424,7 @@ func (c *Client) callAnthropic(messages []Message) Response {
 		return Response{Error: err}
 	}
 
-	req, err := http.NewRequest("POST", baseURL+"/v1/messages", bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/v1/messages", bytes.NewReader(body))
 	if err != nil {
 		return Response{Error: err}
 	}
@@ -284,18 +445,23 @@ func (c *Client) callAnthropic(messages []Message) Response {
 	}
 
 	if resp.StatusCode != 200 {
-		return Response{Error: fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))}
+		return Response{Error: &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}}
 	}
 
 	var result struct {
 		Content []struct {
 			Text string `json:"text"`
 		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
 	}
 
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		return Response{Error: err}
 	}
+	recordTokens("anthropic", result.Usage.InputTokens, result.Usage.OutputTokens)
 
 	if len(result.Content) == 0 {
 		return Response{Error: fmt.Errorf("no response from API")}
@@ -305,7 +471,7 @@ func (c *Client) callAnthropic(messages []Message) Response {
 }
 
 // Ollama API format
-func (c *Client) callOllama(messages []Message) Response {
+func (c *Client) callOllama(ctx context.Context, settings config.AISettings, messages []Message) Response {
 	baseURL := c.provider.BaseURL
 	if baseURL == "" {
 		baseURL = "http://localhost:11434"
@@ -330,13 +496,23 @@ func (c *Client) callOllama(messages []Message) Response {
 		"messages": ollamaMessages,
 		"stream":   false,
 	}
+	options := map[string]interface{}{}
+	if settings.Temperature != nil {
+		options["temperature"] = *settings.Temperature
+	}
+	if settings.MaxTokens > 0 {
+		options["num_predict"] = settings.MaxTokens
+	}
+	if len(options) > 0 {
+		reqBody["options"] = options
+	}
 
 	body, err := json.Marshal(reqBody)
 	if err != nil {
 		return Response{Error: err}
 	}
 
-	req, err := http.NewRequest("POST", baseURL+"/api/chat", bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/api/chat", bytes.NewReader(body))
 	if err != nil {
 		return Response{Error: err}
 	}
@@ -355,18 +531,21 @@ func (c *Client) callOllama(messages []Message) Response {
 	}
 
 	if resp.StatusCode != 200 {
-		return Response{Error: fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))}
+		return Response{Error: &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}}
 	}
 
 	var result struct {
 		Message struct {
 			Content string `json:"content"`
 		} `json:"message"`
+		PromptEvalCount int `json:"prompt_eval_count"`
+		EvalCount       int `json:"eval_count"`
 	}
 
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		return Response{Error: err}
 	}
+	recordTokens("ollama", result.PromptEvalCount, result.EvalCount)
 
 	return Response{Content: result.Message.Content}
 }
@@ -0,0 +1,43 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestAskUsesProviderSettings(t *testing.T) {
+	var body struct {
+		Messages    []Message `json:"messages"`
+		Temperature *float64  `json:"temperature"`
+		MaxTokens   int       `json:"max_tokens"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer srv.Close()
+
+	temp := 0.1
+	p := config.ProviderConfig{
+		Name:         "local",
+		ProviderType: "openai-compatible",
+		BaseURL:      srv.URL,
+		AISettings:   config.AISettings{Temperature: &temp, MaxTokens: 300},
+		Features: map[string]config.AISettings{
+			config.FeatureAsk: {SystemPrompt: "Answer like a pirate."},
+		},
+	}
+	if resp := NewClient(p).Ask("hi", "# Docker"); resp.Error != nil || resp.Content != "ok" {
+		t.Fatalf("Ask() = %+v", resp)
+	}
+	if body.Temperature == nil || *body.Temperature != 0.1 || body.MaxTokens != 300 {
+		t.Errorf("temperature = %v, max_tokens = %d", body.Temperature, body.MaxTokens)
+	}
+	if len(body.Messages) == 0 || body.Messages[0].Content != "Answer like a pirate.\n\nHere is the current resource content and any attached context:\n# Docker" {
+		t.Errorf("system prompt = %+v", body.Messages)
+	}
+}
@@ -0,0 +1,93 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultContextLimit is the most bytes of one attachment sent with a
+// question unless ai.context_limit is set
+const DefaultContextLimit = 16 * 1024
+
+// Truncation says which part of an attachment over the limit is kept
+type Truncation int
+
+const (
+	// KeepHead keeps the start, e.g. of a diff
+	KeepHead Truncation = iota
+	// KeepTail keeps the end, e.g. of command output where errors are
+	KeepTail
+	// KeepEnds keeps the start and the end, e.g. of a file
+	KeepEnds
+)
+
+// Attachment is extra context for a question, such as a file or the last
+// command output
+type Attachment struct {
+	Label     string // e.g. "git diff"
+	Content   string
+	Size      int // bytes before truncation
+	Truncated bool
+}
+
+// NewAttachment truncates content to limit bytes with strategy. A limit of
+// 0 or less uses DefaultContextLimit.
+func NewAttachment(label, content string, limit int, strategy Truncation) Attachment {
+	if limit <= 0 {
+		limit = DefaultContextLimit
+	}
+	a := Attachment{Label: label, Content: content, Size: len(content)}
+	if len(content) <= limit {
+		return a
+	}
+
+	a.Truncated = true
+	switch strategy {
+	case KeepTail:
+		a.Content = "[… truncated]\n" + validTail(content[len(content)-limit:])
+	case KeepEnds:
+		half := limit / 2
+		a.Content = validHead(content[:half]) + "\n[… truncated]\n" + validTail(content[len(content)-half:])
+	default:
+		a.Content = validHead(content[:limit]) + "\n[… truncated]"
+	}
+	return a
+}
+
+// FormatAttachments renders attachments as context sections after base
+func FormatAttachments(base string, attachments []Attachment) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for _, a := range attachments {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "--- %s ---\n%s", a.Label, strings.TrimRight(a.Content, "\n"))
+	}
+	return b.String()
+}
+
+// validHead drops a rune cut in half at the end of s
+func validHead(s string) string {
+	for len(s) > 0 {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if r != utf8.RuneError || size != 1 {
+			break
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// validTail drops a rune cut in half at the start of s
+func validTail(s string) string {
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r != utf8.RuneError || size != 1 {
+			break
+		}
+		s = s[1:]
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewAttachment(t *testing.T) {
+	content := "first line\n" + strings.Repeat("x", 100) + "\nerror: last line"
+	tests := []struct {
+		name     string
+		limit    int
+		strategy Truncation
+		prefix   string
+		suffix   string
+	}{
+		{"fits", 1000, KeepHead, "first line", "last line"},
+		{"head", 20, KeepHead, "first line", "[… truncated]"},
+		{"tail", 20, KeepTail, "[… truncated]", "error: last line"},
+		{"ends", 40, KeepEnds, "first line", "error: last line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAttachment("output", content, tt.limit, tt.strategy)
+			if !strings.HasPrefix(a.Content, tt.prefix) || !strings.HasSuffix(a.Content, tt.suffix) {
+				t.Errorf("content = %q", a.Content)
+			}
+			if a.Size != len(content) || a.Truncated != (tt.limit < len(content)) {
+				t.Errorf("size = %d, truncated = %v", a.Size, a.Truncated)
+			}
+		})
+	}
+}
+
+func TestNewAttachmentKeepsRunesWhole(t *testing.T) {
+	a := NewAttachment("file", strings.Repeat("é", 10), 5, KeepHead)
+	if a.Content != "éé\n[… truncated]" {
+		t.Errorf("content = %q", a.Content)
+	}
+}
+
+func TestFormatAttachments(t *testing.T) {
+	got := FormatAttachments("# Docker", []Attachment{{Label: "git diff", Content: "+added\n"}})
+	if got != "# Docker\n\n--- git diff ---\n+added" {
+		t.Errorf("got %q", got)
+	}
+	if got := FormatAttachments("", []Attachment{{Label: "f", Content: "x"}}); got != "--- f ---\nx" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,293 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Found is a provider whose credentials already exist on this machine.
+type Found struct {
+	Provider config.ProviderConfig
+	// Source says where it was found, e.g. "$OPENAI_API_KEY" or "mods".
+	Source string
+}
+
+// defaultModels are the models imported providers start with when their
+// source doesn't name one
+var defaultModels = map[string]string{
+	"openai":    "gpt-4",
+	"anthropic": "claude-sonnet-4-20250514",
+	"ollama":    "llama3",
+	"gemini":    "gemini-2.5-flash",
+}
+
+// Discover finds provider credentials in OPENAI_API_KEY, ANTHROPIC_API_KEY
+// and OLLAMA_HOST, and in the configs of mods, llm and aichat. Providers
+// already in existing, and repeats of one key across sources, are left
+// out; names are made unique against existing.
+func Discover(existing []config.ProviderConfig) []Found {
+	configDir, _ := os.UserConfigDir()
+	return discover(os.Getenv, configDir, existing)
+}
+
+func discover(getenv func(string) string, configDir string, existing []config.ProviderConfig) []Found {
+	var candidates []Found
+	candidates = append(candidates, fromEnv(getenv)...)
+	if configDir != "" {
+		candidates = append(candidates, fromMods(filepath.Join(configDir, "mods", "mods.yml"), getenv)...)
+		candidates = append(candidates, fromLLM(filepath.Join(envOr(getenv, "LLM_USER_PATH", filepath.Join(configDir, "io.datasette.llm")), "keys.json"))...)
+		candidates = append(candidates, fromAIChat(filepath.Join(envOr(getenv, "AICHAT_CONFIG_DIR", filepath.Join(configDir, "aichat")), "config.yaml"))...)
+	}
+
+	seen := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, p := range existing {
+		seen[providerKey(p)] = true
+		names[p.Name] = true
+	}
+
+	var found []Found
+	for _, f := range candidates {
+		p := &f.Provider
+		if seen[providerKey(*p)] {
+			continue
+		}
+		seen[providerKey(*p)] = true
+
+		p.Enabled = true
+		if p.DefaultModel == "" {
+			p.DefaultModel = defaultModels[p.ProviderType]
+		}
+		source := f.Source
+		if strings.HasPrefix(source, "$") {
+			source = "env"
+		}
+		p.Name = uniqueName(names, p.ProviderType, source)
+		names[p.Name] = true
+		found = append(found, f)
+	}
+	return found
+}
+
+// providerKey identifies the account a provider uses: its API key, or its
+// endpoint when it has none
+func providerKey(p config.ProviderConfig) string {
+	if p.APIKey != "" {
+		return "key:" + p.APIKey
+	}
+	return "url:" + p.ProviderType + ":" + strings.TrimRight(p.BaseURL, "/")
+}
+
+// uniqueName returns base, or base-source and then numbered names when it
+// is taken
+func uniqueName(taken map[string]bool, base, source string) string {
+	if !taken[base] {
+		return base
+	}
+	name := base + "-" + source
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s-%s-%d", base, source, i)
+	}
+	return name
+}
+
+func envOr(getenv func(string) string, key, def string) string {
+	if v := getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func fromEnv(getenv func(string) string) []Found {
+	var found []Found
+	if key := getenv("OPENAI_API_KEY"); key != "" {
+		found = append(found, Found{
+			Provider: config.ProviderConfig{ProviderType: "openai", APIKey: key, BaseURL: getenv("OPENAI_BASE_URL")},
+			Source:   "$OPENAI_API_KEY",
+		})
+	}
+	if key := getenv("ANTHROPIC_API_KEY"); key != "" {
+		found = append(found, Found{
+			Provider: config.ProviderConfig{ProviderType: "anthropic", APIKey: key},
+			Source:   "$ANTHROPIC_API_KEY",
+		})
+	}
+	if host := getenv("OLLAMA_HOST"); host != "" {
+		found = append(found, Found{
+			Provider: config.ProviderConfig{ProviderType: "ollama", BaseURL: ollamaURL(host)},
+			Source:   "$OLLAMA_HOST",
+		})
+	}
+	return found
+}
+
+// ollamaURL turns an OLLAMA_HOST value such as 127.0.0.1 or
+// localhost:11434 into a URL
+func ollamaURL(host string) string {
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	scheme, addr, _ := strings.Cut(strings.TrimRight(host, "/"), "://")
+	if !strings.Contains(addr, ":") {
+		addr += ":11434"
+	}
+	return scheme + "://" + addr
+}
+
+// modsConfig is the part of mods.yml that names providers
+type modsConfig struct {
+	DefaultModel string `yaml:"default-model"`
+	APIs         map[string]struct {
+		BaseURL   string         `yaml:"base-url"`
+		APIKey    string         `yaml:"api-key"`
+		APIKeyEnv string         `yaml:"api-key-env"`
+		Models    map[string]any `yaml:"models"`
+	} `yaml:"apis"`
+}
+
+// fromMods reads the APIs in mods.yml that have a key. Keys are in the
+// file or in the variable its api-key-env names.
+func fromMods(path string, getenv func(string) string) []Found {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cfg modsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+
+	var found []Found
+	for _, name := range slices.Sorted(maps.Keys(cfg.APIs)) {
+		api := cfg.APIs[name]
+		key := api.APIKey
+		if key == "" && api.APIKeyEnv != "" {
+			key = getenv(api.APIKeyEnv)
+		}
+		if key == "" {
+			continue
+		}
+
+		p := config.ProviderConfig{APIKey: key}
+		switch name {
+		case "openai", "anthropic", "gemini":
+			p.ProviderType = name
+		case "google":
+			p.ProviderType = "gemini"
+		case "azure", "azure-ad":
+			// Azure OpenAI needs deployments, not just a key
+			continue
+		default:
+			if api.BaseURL == "" {
+				continue
+			}
+			p.ProviderType, p.BaseURL = "openai-compatible", api.BaseURL
+		}
+		if _, ok := api.Models[cfg.DefaultModel]; ok {
+			p.DefaultModel = cfg.DefaultModel
+		}
+		found = append(found, Found{Provider: p, Source: "mods"})
+	}
+	return found
+}
+
+// llmKeyTypes maps the key names in llm's keys.json to provider types
+var llmKeyTypes = map[string]string{
+	"openai":    "openai",
+	"anthropic": "anthropic",
+	"claude":    "anthropic",
+	"gemini":    "gemini",
+}
+
+// fromLLM reads the keys saved with llm keys set
+func fromLLM(path string) []Found {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var keys map[string]string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil
+	}
+
+	var found []Found
+	for _, name := range slices.Sorted(maps.Keys(keys)) {
+		if typ, ok := llmKeyTypes[name]; ok && keys[name] != "" {
+			found = append(found, Found{
+				Provider: config.ProviderConfig{ProviderType: typ, APIKey: keys[name]},
+				Source:   "llm",
+			})
+		}
+	}
+	return found
+}
+
+// aichatConfig is the part of aichat's config.yaml that names providers
+type aichatConfig struct {
+	Model   string `yaml:"model"`
+	Clients []struct {
+		Type    string `yaml:"type"`
+		Name    string `yaml:"name"`
+		APIBase string `yaml:"api_base"`
+		APIKey  string `yaml:"api_key"`
+	} `yaml:"clients"`
+}
+
+// fromAIChat reads the clients in aichat's config.yaml that have a key
+func fromAIChat(path string) []Found {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cfg aichatConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+
+	var found []Found
+	for _, c := range cfg.Clients {
+		if c.APIKey == "" {
+			continue
+		}
+		p := config.ProviderConfig{APIKey: c.APIKey}
+		switch c.Type {
+		case "openai":
+			p.ProviderType = "openai"
+			if c.APIBase != "" {
+				p.ProviderType, p.BaseURL = "openai-compatible", c.APIBase
+			}
+		case "claude":
+			p.ProviderType = "anthropic"
+		case "gemini":
+			p.ProviderType = "gemini"
+		case "openai-compatible":
+			if c.APIBase == "" {
+				continue
+			}
+			p.ProviderType, p.BaseURL = "openai-compatible", c.APIBase
+		default:
+			continue
+		}
+
+		// model is "client:model", where client is the client's name or,
+		// without one, its type
+		client := c.Name
+		if client == "" {
+			client = c.Type
+		}
+		if prefix, model, ok := strings.Cut(cfg.Model, ":"); ok && prefix == client {
+			p.DefaultModel = model
+		}
+		found = append(found, Found{Provider: p, Source: "aichat"})
+	}
+	return found
+}
@@ -0,0 +1,96 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestOllamaURL(t *testing.T) {
+	tests := map[string]string{
+		"127.0.0.1":              "http://127.0.0.1:11434",
+		"localhost:8080":         "http://localhost:8080",
+		"https://ollama.example": "https://ollama.example:11434",
+		"http://gpu-box:11434/":  "http://gpu-box:11434",
+	}
+	for host, want := range tests {
+		if got := ollamaURL(host); got != want {
+			t.Errorf("ollamaURL(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("mods/mods.yml", `
+default-model: claude-3-5-sonnet
+apis:
+  openai:
+    api-key-env: OPENAI_API_KEY
+  anthropic:
+    api-key-env: MODS_ANTHROPIC_KEY
+    models:
+      claude-3-5-sonnet: {}
+  groq:
+    base-url: https://api.groq.com/openai/v1
+    api-key: gsk-groq
+  ollama:
+    base-url: http://localhost:11434/api
+  azure:
+    api-key: azure-key
+`)
+	write("io.datasette.llm/keys.json", `{"// Note": "keys", "openai": "sk-llm", "mistral": "m-key"}`)
+	write("aichat/config.yaml", `
+model: work:gpt-4o-mini
+clients:
+  - type: openai-compatible
+    name: work
+    api_base: https://llm.example.com/v1
+    api_key: sk-work
+  - type: claude
+    api_key: sk-ant-env
+`)
+
+	env := map[string]string{
+		"OPENAI_API_KEY":     "sk-env",
+		"ANTHROPIC_API_KEY":  "sk-ant-env",
+		"OLLAMA_HOST":        "0.0.0.0",
+		"MODS_ANTHROPIC_KEY": "sk-ant-mods",
+	}
+	existing := []config.ProviderConfig{{Name: "openai", ProviderType: "openai", APIKey: "sk-old"}}
+
+	got := discover(func(k string) string { return env[k] }, dir, existing)
+	want := []Found{
+		{config.ProviderConfig{Name: "openai-env", ProviderType: "openai", APIKey: "sk-env", DefaultModel: "gpt-4", Enabled: true}, "$OPENAI_API_KEY"},
+		{config.ProviderConfig{Name: "anthropic", ProviderType: "anthropic", APIKey: "sk-ant-env", DefaultModel: "claude-sonnet-4-20250514", Enabled: true}, "$ANTHROPIC_API_KEY"},
+		{config.ProviderConfig{Name: "ollama", ProviderType: "ollama", BaseURL: "http://0.0.0.0:11434", DefaultModel: "llama3", Enabled: true}, "$OLLAMA_HOST"},
+		{config.ProviderConfig{Name: "anthropic-mods", ProviderType: "anthropic", APIKey: "sk-ant-mods", DefaultModel: "claude-3-5-sonnet", Enabled: true}, "mods"},
+		{config.ProviderConfig{Name: "openai-compatible", ProviderType: "openai-compatible", APIKey: "gsk-groq", BaseURL: "https://api.groq.com/openai/v1", Enabled: true}, "mods"},
+		{config.ProviderConfig{Name: "openai-llm", ProviderType: "openai", APIKey: "sk-llm", DefaultModel: "gpt-4", Enabled: true}, "llm"},
+		{config.ProviderConfig{Name: "openai-compatible-aichat", ProviderType: "openai-compatible", APIKey: "sk-work", BaseURL: "https://llm.example.com/v1", DefaultModel: "gpt-4o-mini", Enabled: true}, "aichat"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("discover() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Importing them all leaves nothing to find
+	for _, f := range got {
+		existing = append(existing, f.Provider)
+	}
+	if again := discover(func(k string) string { return env[k] }, dir, existing); len(again) != 0 {
+		t.Errorf("discover() after import = %+v, want none", again)
+	}
+}
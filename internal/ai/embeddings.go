@@ -0,0 +1,127 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// ErrNoEmbeddings is returned for providers whose embeddings API is not
+// supported, such as Anthropic
+var ErrNoEmbeddings = errors.New("provider has no embeddings API")
+
+// DefaultEmbeddingModel returns the embedding model used for a provider
+// type unless ai.rag.model is set
+func DefaultEmbeddingModel(providerType string) string {
+	if providerType == "ollama" {
+		return "nomic-embed-text"
+	}
+	return "text-embedding-3-small"
+}
+
+// GetEmbeddingClient returns a client for the ai.rag provider, falling
+// back to the default provider, and the embedding model to use
+func GetEmbeddingClient(cfg config.Config) (*Client, string, error) {
+	if cfg.Policy.AIDisabled {
+		return nil, "", config.ErrAIDisabled
+	}
+	name := cfg.AI.RAG.Provider
+	if name == "" {
+		name = cfg.AI.DefaultProvider
+	}
+	for _, p := range cfg.AI.Providers {
+		if p.Name == name && p.Enabled {
+			c := NewClient(p)
+			model := cfg.AI.RAG.Model
+			if model == "" {
+				model = DefaultEmbeddingModel(c.providerType())
+			}
+			return c, model, nil
+		}
+	}
+	return nil, "", fmt.Errorf("embedding provider '%s' not found or disabled", name)
+}
+
+// Embed returns one embedding vector per text
+func (c *Client) Embed(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	switch c.providerType() {
+	case "anthropic", "gemini", "vertex", "bedrock":
+		return nil, ErrNoEmbeddings
+	case "ollama":
+		baseURL := c.provider.BaseURL
+		if baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+		var result struct {
+			Embeddings [][]float32 `json:"embeddings"`
+		}
+		err := c.postJSON(ctx, baseURL+"/api/embed", map[string]any{"model": model, "input": texts}, &result)
+		return result.Embeddings, err
+	default:
+		baseURL := c.provider.BaseURL
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
+		var result struct {
+			Data []struct {
+				Index     int       `json:"index"`
+				Embedding []float32 `json:"embedding"`
+			} `json:"data"`
+		}
+		if err := c.postJSON(ctx, baseURL+"/embeddings", map[string]any{"model": model, "input": texts}, &result); err != nil {
+			return nil, err
+		}
+		vectors := make([][]float32, len(texts))
+		for _, d := range result.Data {
+			if d.Index >= 0 && d.Index < len(vectors) {
+				vectors[d.Index] = d.Embedding
+			}
+		}
+		return vectors, nil
+	}
+}
+
+// providerType returns the configured or detected provider type
+func (c *Client) providerType() string {
+	if c.provider.ProviderType != "" {
+		return c.provider.ProviderType
+	}
+	return DetectProviderType(c.provider.APIKey, c.provider.BaseURL, c.provider.Name)
+}
+
+// postJSON posts body to url and decodes the JSON response into out
+func (c *Client) postJSON(ctx context.Context, url string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.provider.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.provider.APIKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	return json.Unmarshal(respBody, out)
+}
@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Error classes a request can fail with. Failover to the next provider
+// happens on the classes in ai.failover_on.
+const (
+	ErrorRateLimit  = "rate_limit"   // HTTP 429
+	ErrorServer     = "server_error" // HTTP 5xx, e.g. an outage or overload
+	ErrorTimeout    = "timeout"
+	ErrorNetwork    = "network" // the provider could not be reached
+	ErrorAuth       = "auth"    // HTTP 401 or 403
+	ErrorBadRequest = "bad_request"
+)
+
+// DefaultFailoverOn are the error classes failed over on unless
+// ai.failover_on is set
+var DefaultFailoverOn = []string{ErrorRateLimit, ErrorServer, ErrorTimeout, ErrorNetwork}
+
+// APIError is an error response from a provider API
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Body)
+}
+
+// ClassifyError returns the error class of err
+func ClassifyError(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == 429:
+			return ErrorRateLimit
+		case apiErr.StatusCode == 401 || apiErr.StatusCode == 403:
+			return ErrorAuth
+		case apiErr.StatusCode >= 500:
+			return ErrorServer
+		}
+		return ErrorBadRequest
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ErrorTimeout
+		}
+		return ErrorNetwork
+	}
+	return ""
+}
+
+// Router sends a feature's requests to its providers in order, moving to
+// the next provider when one fails with an error class in failoverOn
+type Router struct {
+	clients    []*Client
+	failoverOn []string
+}
+
+// NewRouter returns the router for feature: the providers listed under
+// ai.routing.<feature>, or the default provider
+func NewRouter(cfg config.Config, feature string) (*Router, error) {
+	if cfg.Policy.AIDisabled {
+		return nil, config.ErrAIDisabled
+	}
+	names := cfg.AI.Routing[feature]
+	if len(names) == 0 {
+		if cfg.AI.DefaultProvider == "" {
+			return nil, fmt.Errorf("no default provider configured")
+		}
+		names = []string{cfg.AI.DefaultProvider}
+	}
+
+	r := &Router{failoverOn: cfg.AI.FailoverOn}
+	if len(r.failoverOn) == 0 {
+		r.failoverOn = DefaultFailoverOn
+	}
+	for _, name := range names {
+		for _, p := range cfg.AI.Providers {
+			if p.Name == name && p.Enabled {
+				r.clients = append(r.clients, NewClient(p))
+				break
+			}
+		}
+	}
+	if len(r.clients) == 0 {
+		return nil, fmt.Errorf("no enabled provider for %s among %v", feature, names)
+	}
+	return r, nil
+}
+
+// Ask is Client.Ask with failover
+func (r *Router) Ask(question, context string) Response {
+	return r.do(func(c *Client) Response { return c.Ask(question, context) })
+}
+
+// GenerateCommand is Client.GenerateCommand with failover
+func (r *Router) GenerateCommand(description, context string) Response {
+	return r.do(func(c *Client) Response { return c.GenerateCommand(description, context) })
+}
+
+// Failure is a provider a Router moved past
+type Failure struct {
+	Provider string
+	Err      error
+}
+
+func (r *Router) do(call func(*Client) Response) Response {
+	var failed []Failure
+	for i, c := range r.clients {
+		resp := call(c)
+		resp.Provider = c.provider.Name
+		resp.FailedOver = failed
+		if resp.Error == nil || i == len(r.clients)-1 || !slices.Contains(r.failoverOn, ClassifyError(resp.Error)) {
+			return resp
+		}
+		failed = append(failed, Failure{Provider: c.provider.Name, Err: resp.Error})
+	}
+	return Response{Error: errors.New("no providers")}
+}
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&APIError{StatusCode: 429}, ErrorRateLimit},
+		{&APIError{StatusCode: 529}, ErrorServer},
+		{&APIError{StatusCode: 401}, ErrorAuth},
+		{&APIError{StatusCode: 400}, ErrorBadRequest},
+		{fmt.Errorf("post: %w", context.DeadlineExceeded), ErrorTimeout},
+		{errors.New("other"), ""},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRouterFailsOver(t *testing.T) {
+	newServer := func(status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+		}))
+	}
+	limited, unauthorized, healthy := newServer(429), newServer(401), newServer(200)
+	defer limited.Close()
+	defer unauthorized.Close()
+	defer healthy.Close()
+
+	provider := func(name, url string) config.ProviderConfig {
+		return config.ProviderConfig{Name: name, ProviderType: "openai-compatible", BaseURL: url, Enabled: true}
+	}
+	cfg := config.Config{AI: config.AIConfig{
+		DefaultProvider: "limited",
+		Providers: []config.ProviderConfig{
+			provider("limited", limited.URL),
+			provider("unauthorized", unauthorized.URL),
+			provider("healthy", healthy.URL),
+		},
+		Routing: map[string][]string{
+			config.FeatureAsk:             {"limited", "healthy"},
+			config.FeatureGenerateCommand: {"unauthorized", "healthy"},
+		},
+	}}
+
+	r, err := NewRouter(cfg, config.FeatureAsk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := r.Ask("hi", "")
+	if resp.Error != nil || resp.Provider != "healthy" || len(resp.FailedOver) != 1 || resp.FailedOver[0].Provider != "limited" {
+		t.Errorf("Ask() = %+v, want healthy after limited", resp)
+	}
+
+	r, _ = NewRouter(cfg, config.FeatureGenerateCommand)
+	if resp := r.GenerateCommand("list files", ""); resp.Error == nil || resp.Provider != "unauthorized" {
+		t.Errorf("GenerateCommand() = %+v, want the auth error without failover", resp)
+	}
+}
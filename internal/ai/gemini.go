@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// geminiResponse is a generateContent response, or one event of a
+// streamGenerateContent response
+type geminiResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+func (r geminiResponse) text() string {
+	var b strings.Builder
+	for _, c := range r.Candidates {
+		for _, p := range c.Content.Parts {
+			b.WriteString(p.Text)
+		}
+	}
+	return b.String()
+}
+
+// Gemini API format, for Google AI Studio ("gemini") and Vertex AI
+// ("vertex")
+func (c *Client) callGemini(ctx context.Context, settings config.AISettings, messages []Message) Response {
+	resp, err := c.geminiRequest(ctx, settings, messages, "generateContent")
+	if err != nil {
+		return Response{Error: err}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Response{Error: err}
+	}
+
+	if resp.StatusCode != 200 {
+		return Response{Error: &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}}
+	}
+
+	var result geminiResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return Response{Error: err}
+	}
+	recordTokens(c.providerType(), result.UsageMetadata.PromptTokenCount, result.UsageMetadata.CandidatesTokenCount)
+
+	if len(result.Candidates) == 0 {
+		return Response{Error: fmt.Errorf("no response from API")}
+	}
+
+	return Response{Content: result.text()}
+}
+
+// streamGemini reads a streamGenerateContent response as server-sent
+// events
+func (c *Client) streamGemini(ctx context.Context, settings config.AISettings, messages []Message, onChunk func(string)) Response {
+	resp, err := c.geminiRequest(ctx, settings, messages, "streamGenerateContent?alt=sse")
+	if err != nil {
+		return Response{Error: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return Response{Error: &APIError{StatusCode: resp.StatusCode, Body: string(respBody)}}
+	}
+
+	var content strings.Builder
+	var last geminiResponse
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var event geminiResponse
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return Response{Content: content.String(), Error: err}
+		}
+		if text := event.text(); text != "" {
+			content.WriteString(text)
+			onChunk(text)
+		}
+		last = event
+	}
+	if err := scanner.Err(); err != nil {
+		return Response{Content: content.String(), Error: err}
+	}
+	recordTokens(c.providerType(), last.UsageMetadata.PromptTokenCount, last.UsageMetadata.CandidatesTokenCount)
+
+	return Response{Content: content.String()}
+}
+
+// geminiRequest sends messages to the model's method, e.g. generateContent
+func (c *Client) geminiRequest(ctx context.Context, settings config.AISettings, messages []Message, method string) (*http.Response, error) {
+	model := c.provider.DefaultModel
+	if model == "" {
+		model = "gemini-2.5-flash"
+	}
+
+	// Convert messages to Gemini format: the system prompt is separate and
+	// the assistant role is "model"
+	type part struct {
+		Text string `json:"text"`
+	}
+	type content struct {
+		Role  string `json:"role,omitempty"`
+		Parts []part `json:"parts"`
+	}
+	reqBody := map[string]interface{}{}
+	var contents []content
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			reqBody["systemInstruction"] = content{Parts: []part{{Text: msg.Content}}}
+		case "assistant":
+			contents = append(contents, content{Role: "model", Parts: []part{{Text: msg.Content}}})
+		default:
+			contents = append(contents, content{Role: "user", Parts: []part{{Text: msg.Content}}})
+		}
+	}
+	reqBody["contents"] = contents
+
+	generationConfig := map[string]interface{}{}
+	if settings.Temperature != nil {
+		generationConfig["temperature"] = *settings.Temperature
+	}
+	if settings.MaxTokens > 0 {
+		generationConfig["maxOutputTokens"] = settings.MaxTokens
+	}
+	if len(generationConfig) > 0 {
+		reqBody["generationConfig"] = generationConfig
+	}
+
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, err := c.geminiBaseURL()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/models/"+model+":"+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.providerType() == "vertex" {
+		token, err := c.vertexToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else {
+		req.Header.Set("x-goog-api-key", c.provider.APIKey)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// geminiBaseURL returns the API root models are under. Vertex AI needs a
+// project, from the provider or GOOGLE_CLOUD_PROJECT.
+func (c *Client) geminiBaseURL() (string, error) {
+	if c.provider.BaseURL != "" {
+		return strings.TrimRight(c.provider.BaseURL, "/"), nil
+	}
+	if c.providerType() != "vertex" {
+		return "https://generativelanguage.googleapis.com/v1beta", nil
+	}
+
+	project := c.provider.Project
+	if project == "" {
+		project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if project == "" {
+		return "", fmt.Errorf("no Google Cloud project set for %s", c.provider.Name)
+	}
+	location := c.provider.Region
+	if location == "" {
+		location = "us-central1"
+	}
+	host := location + "-aiplatform.googleapis.com"
+	if location == "global" {
+		host = "aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s/v1/projects/%s/locations/%s/publishers/google", host, project, location), nil
+}
+
+// vertexToken returns the provider's API key as an OAuth access token, or
+// one from gcloud
+func (c *Client) vertexToken(ctx context.Context) (string, error) {
+	if c.provider.APIKey != "" {
+		return c.provider.APIKey, nil
+	}
+	out, err := exec.CommandContext(ctx, "gcloud", "auth", "print-access-token").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get Google Cloud access token: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
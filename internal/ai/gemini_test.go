@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestGemini(t *testing.T) {
+	var body struct {
+		SystemInstruction struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"systemInstruction"`
+		GenerationConfig struct {
+			MaxOutputTokens int `json:"maxOutputTokens"`
+		} `json:"generationConfig"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-goog-api-key") != "AIza-test" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/models/gemini-test:generateContent":
+			w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"docker ps"}]}}]}`))
+		case "/models/gemini-test:streamGenerateContent":
+			w.Write([]byte("data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"Use \"}]}}]}\n\n" +
+				"data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"docker ps\"}]}}]}\n\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(config.ProviderConfig{
+		Name:         "gemini",
+		ProviderType: "gemini",
+		APIKey:       "AIza-test",
+		BaseURL:      srv.URL,
+		DefaultModel: "gemini-test",
+		AISettings:   config.AISettings{MaxTokens: 100},
+	})
+
+	if resp := c.GenerateCommand("list containers", ""); resp.Error != nil || resp.Content != "docker ps" {
+		t.Fatalf("GenerateCommand() = %+v", resp)
+	}
+	if !strings.HasPrefix(body.SystemInstruction.Parts[0].Text, "You are a command generator") || body.GenerationConfig.MaxOutputTokens != 100 {
+		t.Errorf("request = %+v", body)
+	}
+
+	var chunks []string
+	resp := c.AskStream("how do I list containers?", "", func(s string) { chunks = append(chunks, s) })
+	if resp.Error != nil || resp.Content != "Use docker ps" || len(chunks) != 2 {
+		t.Errorf("AskStream() = %+v, chunks %q", resp, chunks)
+	}
+}
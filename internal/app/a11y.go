@@ -0,0 +1,316 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Accessibility mode renders each screen as plain labeled lines for screen
+// readers: no borders, colors, animation or emoji, a fixed order of
+// sections, and a status line describing the current state that changes
+// whenever the state does.
+
+// a11yWidth caps the width panes are rendered at before being flattened,
+// so card grids and side-by-side layouts fall back to a single column
+const a11yWidth = 60
+
+// a11yGlyphs replaces symbols that carry meaning with words
+var a11yGlyphs = strings.NewReplacer(
+	"✓", "ok", "✔", "ok", "✗", "failed", "✘", "failed", "⚠", "warning",
+	"▶", ">", "›", ">", "»", ">", "•", "-", "…", "...",
+	"↑", "up", "↓", "down", "←", "left", "→", "right",
+)
+
+// plainText strips styling from rendered output and drops box drawing,
+// block and braille characters, emoji and decorative symbols, leaving
+// the words. Blank lines are collapsed.
+func plainText(s string) string {
+	s = a11yGlyphs.Replace(ansi.Strip(s))
+
+	var out []string
+	blank := true
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(strings.Map(plainRune, line)), " ")
+		if line == "" {
+			if !blank {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		out = append(out, line)
+		blank = false
+	}
+	if len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
+
+// plainRune maps decorative characters to a space and drops emoji
+// modifiers
+func plainRune(r rune) rune {
+	switch {
+	case r >= 0x2500 && r <= 0x25FF, // box drawing, blocks, geometric shapes
+		r >= 0x2800 && r <= 0x28FF, // braille, used by spinners
+		r >= 0x2600 && r <= 0x27BF, // symbols and dingbats
+		r >= 0x1F000:               // emoji
+		return ' '
+	case r == 0xFE0F || r == 0x200D:
+		return -1
+	case !unicode.IsPrint(r):
+		return -1
+	}
+	return r
+}
+
+// renderAccessible renders the current screen in accessibility mode
+func (m model) renderAccessible() string {
+	var body string
+	switch {
+	case m.help != nil:
+		body = m.accessibleHelp()
+	case m.recentSwitcher != nil:
+		body = plainText(m.renderRecentSwitcher())
+	case m.palette.State != PaletteStateIdle:
+		body = m.accessiblePalette()
+	case m.term.active:
+		body = plainText(m.renderTerminalPane())
+	case m.askPanel != nil && m.askPanel.Active:
+		body = plainText(m.renderAskPanel(min(m.width, a11yWidth)))
+	case m.currentView == viewDetail:
+		body = m.accessibleDetail()
+	default:
+		body = m.accessibleDashboard()
+	}
+
+	footer := []string{"Status: " + m.a11ySummary()}
+	if n := len(m.notifications); n > 0 {
+		last := m.notifications[n-1]
+		footer = append(footer, fmt.Sprintf("Notice: %s: %s", last.Style, plainText(last.Message)))
+	}
+
+	lines := strings.Split(body, "\n")
+	if room := m.height - len(footer) - 1; room >= 0 && len(lines) > room {
+		lines = lines[:room]
+	}
+	return strings.Join(append(append(lines, ""), footer...), "\n")
+}
+
+// a11ySummary describes where the user is and what is happening in one
+// line
+func (m model) a11ySummary() string {
+	var parts []string
+	switch {
+	case m.help != nil:
+		parts = append(parts, "help open, press ? or esc to close")
+	case m.recentSwitcher != nil:
+		v := m.recent[m.recentSwitcher.cursor]
+		parts = append(parts, fmt.Sprintf("recent resources, %s %s, %d of %d", v.Resource, v.Section, m.recentSwitcher.cursor+1, len(m.recent)))
+	case m.palette.State == PaletteStateExecuting:
+		parts = append(parts, "working")
+	case m.palette.State == PaletteStateShowingResult:
+		if m.palette.ResultFailed {
+			parts = append(parts, "result failed")
+		} else {
+			parts = append(parts, "result ready")
+		}
+	case m.palette.State != PaletteStateIdle:
+		parts = append(parts, "command palette, "+a11yCount(len(m.palette.Filtered), "result"))
+	case m.term.active && m.term.exited:
+		state := "command finished"
+		if m.term.exitErr != nil {
+			state = "command failed: " + m.term.exitErr.Error()
+		}
+		parts = append(parts, state)
+	case m.term.active:
+		parts = append(parts, "command running")
+	case m.askPanel != nil && m.askPanel.Active:
+		switch {
+		case m.askPanel.Loading:
+			parts = append(parts, "asking AI")
+		case m.askPanel.Error != "":
+			parts = append(parts, "AI error")
+		default:
+			parts = append(parts, "ask AI")
+		}
+	case m.currentView == viewDetail:
+		if res := m.currentResource(); res != nil {
+			parts = append(parts, res.name)
+		}
+		if len(m.commands) > 0 {
+			parts = append(parts, fmt.Sprintf("command %d of %d", m.cmdCursor+1, len(m.commands)))
+		}
+	default:
+		parts = append(parts, dashboardTabNames[m.dashboardTab]+" tab")
+		if m.dashboardTab == 0 && len(m.resources) > 0 {
+			parts = append(parts, fmt.Sprintf("resource %d of %d", m.resCursor+1, len(m.resources)))
+		}
+	}
+	if n := len(m.activeAgents); n > 0 {
+		parts = append(parts, a11yCount(n, "agent")+" running")
+	}
+	if m.offline {
+		parts = append(parts, "offline")
+	}
+	if m.count != "" {
+		parts = append(parts, "count "+m.count)
+	}
+	if r := m.lastRun; r != nil {
+		parts = append(parts, fmt.Sprintf("last command exited %d after %s", r.exitCode, formatDuration(r.elapsed)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (m model) accessibleHelp() string {
+	lines := []string{"Keyboard help"}
+	for _, g := range m.help.groups {
+		lines = append(lines, "", plainText(g.title)+":")
+		for _, b := range g.bindings {
+			if b.Enabled() {
+				lines = append(lines, fmt.Sprintf("%s: %s", plainText(b.Help().Key), b.Help().Desc))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) accessiblePalette() string {
+	switch m.palette.State {
+	case PaletteStateSearching:
+		lines := []string{"Command palette", "Search: " + m.palette.Query}
+		if len(m.palette.Filtered) == 0 {
+			return strings.Join(append(lines, "No results"), "\n")
+		}
+		for _, i := range a11yWindow(m.palette.Cursor, len(m.palette.Filtered), m.height-6) {
+			item := m.palette.Filtered[i]
+			line := fmt.Sprintf("%d. %s", i+1, item.Title)
+			if item.Subtitle != "" {
+				line += ": " + item.Subtitle
+			}
+			if m.palette.markIndex(item.ID) >= 0 {
+				line += " (marked)"
+			}
+			if i == m.palette.Cursor {
+				line += " (selected)"
+			}
+			lines = append(lines, plainText(line))
+		}
+		return strings.Join(lines, "\n")
+	case PaletteStateExecuting:
+		line := "Working: " + m.palette.LoadingText
+		if elapsed := m.paletteElapsed(); elapsed != "" {
+			line += ", " + elapsed
+		}
+		return plainText(line) + "\nPress esc to cancel"
+	}
+	return plainText(m.renderPalette())
+}
+
+func (m model) accessibleDetail() string {
+	switch {
+	case m.cmdEditor != nil, m.kubeSwitcher != nil, m.tableView != nil:
+		return plainText(m.renderResourceView())
+	}
+	res := m.currentResource()
+	if res == nil {
+		return "No resource"
+	}
+
+	lines := []string{"Resource " + res.name}
+	if sec := m.currentSection(); sec != nil {
+		lines = append(lines, fmt.Sprintf("Section %d of %d: %s", m.secCursor+1, len(res.sections), sec.title))
+	}
+	if m.tagFilter != "" {
+		lines = append(lines, "Tag filter: "+m.tagFilter)
+	}
+
+	if len(m.commands) > 0 {
+		lines = append(lines, "", "Commands:")
+		for _, i := range a11yWindow(m.cmdCursor, len(m.commands), m.height/2) {
+			c := m.commands[i]
+			line := fmt.Sprintf("%d. %s", i+1, c.raw)
+			if c.description != "" {
+				line += ": " + c.description
+			}
+			if missing := missingCapabilities(c.requires, m.config.Capabilities); len(missing) > 0 {
+				line += " (requires " + strings.Join(missing, ", ") + ")"
+			}
+			if i == m.cmdCursor {
+				line += " (selected)"
+			}
+			lines = append(lines, plainText(line))
+		}
+	}
+	if notes := plainText(m.cachedMarkdownContext); notes != "" {
+		lines = append(lines, "", "Notes:", notes)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) accessibleDashboard() string {
+	lines := []string{fmt.Sprintf("Dashboard, %s tab (%d of %d)",
+		dashboardTabNames[m.dashboardTab], m.dashboardTab+1, len(dashboardTabNames)), ""}
+
+	width := min(m.width, a11yWidth)
+	height := max(m.height-4, 1)
+	switch m.dashboardTab {
+	case 0:
+		if m.deleteResourceWizard != nil && m.deleteResourceWizard.InputForm != nil {
+			return plainText(m.deleteResourceWizard.InputForm.View())
+		}
+		for _, g := range m.resourceGroups() {
+			if m.collapsedGroups[g.name] {
+				lines = append(lines, fmt.Sprintf("%s, %s, collapsed", g.name, a11yCount(len(g.indices), "resource")))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s, %s:", g.name, a11yCount(len(g.indices), "resource")))
+			for n, idx := range g.indices {
+				res := m.resources[idx]
+				line := fmt.Sprintf("%d. %s", n+1, res.name)
+				if res.description != "" {
+					line += ": " + res.description
+				}
+				if idx == m.resCursor {
+					line += " (selected)"
+				}
+				lines = append(lines, plainText(line))
+			}
+		}
+	case 1:
+		lines = append(lines, plainText(m.renderActionsTab(width, height)))
+	case 2:
+		lines = append(lines, plainText(m.renderAgentsTab(width, height)))
+	case deploymentsTab:
+		lines = append(lines, plainText(m.renderDeploymentsTab(width, height)))
+	case pluginsTab:
+		lines = append(lines, plainText(m.renderPluginsTab(width, height)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// a11yWindow returns the indices of up to size of n items, keeping the
+// cursor in view
+func a11yWindow(cursor, n, size int) []int {
+	size = max(size, 3)
+	start := 0
+	if n > size {
+		start = min(max(cursor-size/2, 0), n-size)
+	}
+	var idx []int
+	for i := start; i < min(start+size, n); i++ {
+		idx = append(idx, i)
+	}
+	return idx
+}
+
+// a11yCount formats n of noun, pluralized
+func a11yCount(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"styled", "\x1b[1mkubectl\x1b[0m get pods", "kubectl get pods"},
+		{"border", "╭──────╮\n│ hi   │\n╰──────╯", "hi"},
+		{"emoji", "🚀 Deploy ✨", "Deploy"},
+		{"status glyphs", "✓ saved  ✗ lint", "ok saved failed lint"},
+		{"spinner", "⠋ Loading", "Loading"},
+		{"blank lines", "a\n\n\n\nb\n\n", "a\n\nb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plainText(tt.in); got != tt.want {
+				t.Errorf("plainText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderAccessible(t *testing.T) {
+	m := model{
+		width: 80, height: 30, accessible: true,
+		resources: []resource{
+			{name: "docker", description: "Containers", sections: []section{{title: "Basics"}}},
+			{name: "git", description: "Version control"},
+		},
+		resCursor: 1,
+	}
+
+	view := m.View()
+	for _, want := range []string{"Dashboard, Resources tab (1 of 5)", "VCS, 1 resource:", "1. git: Version control (selected)", "Status: Resources tab, resource 2 of 2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("dashboard missing %q:\n%s", want, view)
+		}
+	}
+
+	m.currentView = viewDetail
+	m.resCursor = 0
+	m.commands = []command{{raw: "docker ps", description: "list containers"}, {raw: "docker images"}}
+	m.cmdCursor = 1
+	m.notifications = []Notification{{Message: "✓ Copied", Style: "success"}}
+	view = m.View()
+	for _, want := range []string{"Resource docker", "Section 1 of 1: Basics", "1. docker ps: list containers", "2. docker images (selected)", "Status: docker, command 2 of 2", "Notice: success: ok Copied"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail view missing %q:\n%s", want, view)
+		}
+	}
+
+	for _, r := range view {
+		if r >= 0x2500 && r <= 0x25FF || r >= 0x1F000 || r == '\x1b' {
+			t.Fatalf("view contains %q:\n%s", r, view)
+		}
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/logging"
 	"github.com/htelsiz/skitz/internal/resources"
+	runtimepkg "github.com/htelsiz/skitz/internal/runtime"
 )
 
 // QuickAction represents an action that can be triggered
@@ -24,29 +27,32 @@ type QuickAction struct {
 	Command  string
 }
 
+// builtinActions are the built-in actions by ID. Custom actions with type
+// "skitz" run one of these.
+var builtinActions = map[string]struct {
+	name    string
+	icon    string
+	handler func(m *model) (tea.Cmd, bool)
+}{
+	"repeat_last":     {"Repeat Last", "⚡", actionRepeatLast},
+	"copy_command":    {"Copy Command", "📋", actionCopyCommand},
+	"search":          {"Search", "🔍", actionSearch},
+	"edit_file":       {"Edit File", "📝", actionEditFile},
+	"favorite":        {"Favorite", "⭐", actionToggleFavorite},
+	"refresh":         {"Refresh", "🔄", actionRefresh},
+	"reset_resources": {"Reset Resources", "↺", actionResetResources},
+	"open_log":        {"Open Log", "📜", actionOpenLog},
+}
+
 // buildQuickActions builds quick actions from config
 func buildQuickActions(cfg config.Config) []QuickAction {
 	var actions []QuickAction
 
-	builtinHandlers := map[string]struct {
-		name    string
-		icon    string
-		handler func(m *model) (tea.Cmd, bool)
-	}{
-		"repeat_last":     {"Repeat Last", "⚡", actionRepeatLast},
-		"copy_command":    {"Copy Command", "📋", actionCopyCommand},
-		"search":          {"Search", "🔍", actionSearch},
-		"edit_file":       {"Edit File", "📝", actionEditFile},
-		"favorite":        {"Favorite", "⭐", actionToggleFavorite},
-		"refresh":         {"Refresh", "🔄", actionRefresh},
-		"reset_resources": {"Reset Resources", "↺", actionResetResources},
-	}
-
 	for _, b := range cfg.QuickActions.Builtin {
 		if !b.Enabled {
 			continue
 		}
-		if handler, ok := builtinHandlers[b.ID]; ok {
+		if handler, ok := builtinActions[b.ID]; ok {
 			actions = append(actions, QuickAction{
 				ID:       b.ID,
 				Name:     handler.name,
@@ -80,24 +86,18 @@ func actionRepeatLast(m *model) (tea.Cmd, bool) {
 	}
 	lastCmd := m.history[0].Command
 	lastTool := m.history[0].Tool
-
-	displayCmd := lastCmd
-	if len(displayCmd) > 30 {
-		displayCmd = displayCmd[:27] + "..."
+	if err := m.config.Policy.CheckCommand(lastCmd); err != nil {
+		return m.showNotification("🔒", err.Error(), "warning"), true
 	}
+
+	displayCmd := truncate(lastCmd, 30)
 	notifyCmd := m.showNotification("⚡", "Repeating: "+displayCmd, "info")
 
-	ic := &interactiveCmd{
-		cmd:        lastCmd,
-		needsInput: false,
-		tool:       lastTool,
-	}
-	execCmd := tea.Exec(ic, func(err error) tea.Msg {
-		return commandDoneMsg{
-			command: ic.finalCmd,
-			tool:    ic.tool,
-			success: ic.success,
-		}
+	execCmd := m.startRun(commandRun{
+		command:  lastCmd,
+		mode:     CommandInteractive,
+		resource: lastTool,
+		shell:    resolveShell(m.config.Execution, lastTool),
 	})
 	return tea.Batch(notifyCmd, execCmd), true
 }
@@ -121,10 +121,7 @@ func actionCopyCommand(m *model) (tea.Cmd, bool) {
 		return m.showNotification("❌", "Failed to copy: "+err.Error(), "error"), true
 	}
 
-	displayCmd := cmdText
-	if len(displayCmd) > 25 {
-		displayCmd = displayCmd[:22] + "..."
-	}
+	displayCmd := truncate(cmdText, 25)
 	return m.showNotification("📋", "Copied "+source+": "+displayCmd, "success"), true
 }
 
@@ -176,26 +173,17 @@ func actionToggleFavorite(m *model) (tea.Cmd, bool) {
 	}
 
 	cmdText := m.commands[m.cmdCursor].cmd
-	displayCmd := cmdText
-	if len(displayCmd) > 20 {
-		displayCmd = displayCmd[:17] + "..."
-	}
+	displayCmd := truncate(cmdText, 20)
 
 	if m.favorites[cmdText] {
 		delete(m.favorites, cmdText)
-		newFavs := []string{}
-		for _, f := range m.config.Favorites {
-			if f != cmdText {
-				newFavs = append(newFavs, f)
-			}
-		}
-		m.config.Favorites = newFavs
+		config.SetFavorite(&m.config, cmdText, false, time.Now())
 		config.Save(m.config)
 		return m.showNotification("☆", "Unfavorited: "+displayCmd, "info"), true
 	}
 
 	m.favorites[cmdText] = true
-	m.config.Favorites = append(m.config.Favorites, cmdText)
+	config.SetFavorite(&m.config, cmdText, true, time.Now())
 	config.Save(m.config)
 	return m.showNotification("⭐", "Favorited: "+displayCmd, "success"), true
 }
@@ -223,3 +211,27 @@ func actionResetResources(m *model) (tea.Cmd, bool) {
 	}
 	return m.showNotification("↺", fmt.Sprintf("Reset to %d default resources", len(m.resources)), "success"), true
 }
+
+func actionOpenLog(m *model) (tea.Cmd, bool) {
+	logPath := logging.Path()
+	if _, err := os.Stat(logPath); err != nil {
+		return m.showNotification("!", "No log file yet: "+logPath, "error"), true
+	}
+
+	return m.runCommand(CommandSpec{
+		Command: openLogCommand(),
+		Mode:    CommandInteractive,
+	}), true
+}
+
+// openLogCommand returns the pager command used to view the log file
+func openLogCommand() string {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+		if _, err := exec.LookPath(pager); err != nil {
+			pager = "more"
+		}
+	}
+	return pager + " " + runtimepkg.Quote(logging.Path())
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/logging"
+)
+
+func TestOpenLogCommand(t *testing.T) {
+	oldDataDir := config.DataDir
+	config.DataDir = filepath.Join(t.TempDir(), `it's "$HOME"`)
+	defer func() { config.DataDir = oldDataDir }()
+	t.Setenv("PAGER", "cat")
+
+	if err := os.MkdirAll(logging.Dir(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logging.Path(), []byte("logged\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The shell must see the path exactly as written
+	out, err := exec.Command("sh", "-c", openLogCommand()).CombinedOutput()
+	if err != nil || string(out) != "logged\n" {
+		t.Errorf("sh -c %s = %q, %v", openLogCommand(), out, err)
+	}
+}
+
+func TestPaletteCommandPreview(t *testing.T) {
+	m := model{history: []config.HistoryEntry{{Command: "rm -rf build", Tool: "make"}}}
+
+	items := m.getActionPaletteItems()
+	if items[0].ID != "action:repeat_last" || !slices.Equal(items[0].Commands, []string{"rm -rf build"}) {
+		t.Fatalf("first action = %+v, want Repeat Last running the last command", items[0])
+	}
+	for _, item := range items {
+		if item.ID == "action:open_log" && !slices.Equal(item.Commands, []string{openLogCommand()}) {
+			t.Errorf("Open Log runs %v", item.Commands)
+		}
+	}
+
+	preview := ansi.Strip(strings.Join(renderCommandPreview(items[0].Commands, 60), "\n"))
+	if !strings.Contains(preview, "$ rm -rf build") || !strings.Contains(preview, "ctrl+y to copy") {
+		t.Errorf("preview = %q", preview)
+	}
+
+	// Items that run nothing have nothing to copy
+	m.palette.State = PaletteStateSearching
+	m.palette.Filtered = []PaletteItem{{ID: "action:flags", Title: "Feature Flags", Category: "action"}}
+	m.handlePaletteKeys(tea.KeyMsg{Type: tea.KeyCtrlY})
+	if msg := m.notifications[len(m.notifications)-1].Message; msg != "Nothing to copy" {
+		t.Errorf("ctrl+y notification = %q", msg)
+	}
+
+	// Repeating the last command is subject to the policy, like any run
+	policy, err := config.ParsePolicy([]byte("commands:\n  deny: ['rm -rf']\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.config.Policy = policy
+	actionRepeatLast(&m)
+	if n := m.notifications[len(m.notifications)-1]; n.Icon != "🔒" {
+		t.Errorf("repeat last notification = %+v, want it refused", n)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/yarlson/tap"
 
+	"github.com/htelsiz/skitz/internal/audit"
 	"github.com/htelsiz/skitz/internal/config"
 	mcppkg "github.com/htelsiz/skitz/internal/mcp"
 )
@@ -32,9 +34,11 @@ func ReviewCodeWithBIA(ctx context.Context, code string) (string, error) {
 		return "", fmt.Errorf("failed to get MCP client: %w", err)
 	}
 
+	started := time.Now()
 	result, err := client.CallTool(ctx, "bia_junior_agent", map[string]any{
 		"code": code,
 	})
+	auditCall(audit.KindMCP, "bia_junior_agent", mcppkg.GetDefaultMCPServerURL(), started, err)
 	if err != nil {
 		return "", fmt.Errorf("failed to call bia_junior_agent: %w", err)
 	}
@@ -119,28 +123,32 @@ func GetAvailableMCPTools() ([]string, error) {
 	return names, nil
 }
 
-// biaCodeReviewCmd implements tea.ExecCommand for BIA code review
-type biaCodeReviewCmd struct {
+// codeReviewCmd implements tea.ExecCommand for the code review wizard
+type codeReviewCmd struct {
+	profile     reviewProfile
+	github      config.GitHubConfig // for pull request reviews
 	success     bool
 	interaction config.AgentInteraction
 }
 
-func (c *biaCodeReviewCmd) Run() error {
+func (c *codeReviewCmd) Run() error {
 	ctx := context.Background()
 
 	fmt.Print("\033[H\033[2J")
-	tap.Intro("🔍 BIA Code Review")
+	tap.Intro("🔍 " + c.profile.name)
 
-	spinner := tap.NewSpinner(tap.SpinnerOptions{})
-	spinner.Start("Connecting to MCP server...")
+	if c.profile.mcp {
+		spinner := tap.NewSpinner(tap.SpinnerOptions{})
+		spinner.Start("Connecting to MCP server...")
 
-	if !CheckMCPServer() {
-		spinner.Stop("", 0)
-		tap.Box("MCP server not available.\nCheck your MCP server configuration in:\n  ~/.config/skitz/config.yaml", "Error", tap.BoxOptions{})
-		waitForEnterMCP()
-		return nil
+		if !CheckMCPServer() {
+			spinner.Stop("", 0)
+			tap.Box("MCP server not available.\nCheck your MCP server configuration in:\n  ~/.config/skitz/config.yaml", "Error", tap.BoxOptions{})
+			waitForEnterMCP()
+			return nil
+		}
+		spinner.Stop("Connected to MCP server", 1)
 	}
-	spinner.Stop("Connected to MCP server", 1)
 
 	stty := exec.Command("stty", "sane")
 	stty.Stdin = os.Stdin
@@ -150,16 +158,21 @@ func (c *biaCodeReviewCmd) Run() error {
 	inputOptions := []tap.SelectOption[string]{
 		{Value: "file", Label: "Enter file path", Hint: "Review a file from disk"},
 		{Value: "paste", Label: "Paste code", Hint: "Paste code directly"},
+		{Value: "diff", Label: "Current git diff", Hint: "Review staged or unstaged changes"},
+		{Value: "dir", Label: "Directory", Hint: "Review the files matching a pattern"},
+		{Value: "pr", Label: "GitHub pull request", Hint: "Review a pull request's changes"},
 	}
 	inputType := tap.Select(ctx, tap.SelectOptions[string]{
 		Message: "How would you like to provide code?",
 		Options: inputOptions,
 	})
 
-	var code string
+	var files []reviewFile
+	var source string
 	reader := bufio.NewReader(os.Stdin)
 
-	if inputType == "file" {
+	switch inputType {
+	case "file":
 		filePath := tap.Text(ctx, tap.TextOptions{
 			Message:     "File path:",
 			Placeholder: "e.g., ./main.py or /path/to/file.py",
@@ -170,19 +183,79 @@ func (c *biaCodeReviewCmd) Run() error {
 			return nil
 		}
 
-		if strings.HasPrefix(filePath, "~/") {
-			home, _ := os.UserHomeDir()
-			filePath = home + filePath[1:]
-		}
-
+		filePath = expandHome(filePath)
 		content, err := os.ReadFile(filePath)
 		if err != nil {
 			tap.Box(fmt.Sprintf("Failed to read file: %v", err), "Error", tap.BoxOptions{})
 			waitForEnterMCP()
 			return nil
 		}
-		code = string(content)
-	} else {
+		files = []reviewFile{{file: filePath, code: string(content)}}
+		source = filePath
+
+	case "diff":
+		staged := tap.Select(ctx, tap.SelectOptions[bool]{
+			Message: "Which changes?",
+			Options: []tap.SelectOption[bool]{
+				{Value: false, Label: "Unstaged", Hint: "git diff"},
+				{Value: true, Label: "Staged", Hint: "git diff --cached"},
+			},
+		})
+		var err error
+		if files, err = gitDiffFiles(ctx, staged); err != nil {
+			tap.Box(err.Error(), "Error", tap.BoxOptions{})
+			waitForEnterMCP()
+			return nil
+		}
+		source = "git diff"
+		if staged {
+			source = "git diff --cached"
+		}
+
+	case "dir":
+		dir := tap.Text(ctx, tap.TextOptions{
+			Message:      "Directory:",
+			DefaultValue: ".",
+			Placeholder:  ".",
+		})
+		glob := tap.Text(ctx, tap.TextOptions{
+			Message:      "File pattern:",
+			DefaultValue: "*",
+			Placeholder:  "e.g., *.go or *.py",
+		})
+		dir = expandHome(dir)
+		var err error
+		if files, err = dirFiles(dir, glob); err != nil {
+			tap.Box(err.Error(), "Error", tap.BoxOptions{})
+			waitForEnterMCP()
+			return nil
+		}
+		source = filepath.Join(dir, glob)
+
+	case "pr":
+		url := tap.Text(ctx, tap.TextOptions{
+			Message:     "Pull request URL:",
+			Placeholder: "https://github.com/owner/repo/pull/123",
+		})
+		if url == "" {
+			tap.Cancel("No pull request URL provided")
+			return nil
+		}
+
+		spinner := tap.NewSpinner(tap.SpinnerOptions{})
+		spinner.Start("Fetching pull request...")
+		var err error
+		files, err = pullRequestFiles(ctx, c.github, url)
+		if err != nil {
+			spinner.Stop("", 0)
+			tap.Box(err.Error(), "Error", tap.BoxOptions{})
+			waitForEnterMCP()
+			return nil
+		}
+		spinner.Stop("Fetched pull request", 1)
+		source = strings.TrimSpace(url)
+
+	default:
 		stty := exec.Command("stty", "sane")
 		stty.Stdin = os.Stdin
 		stty.Run()
@@ -213,39 +286,65 @@ func (c *biaCodeReviewCmd) Run() error {
 			}
 		}
 
-		code = strings.TrimRight(strings.Join(lines, ""), "\n\t ")
+		code := strings.TrimRight(strings.Join(lines, ""), "\n\t ")
+		files = []reviewFile{{code: code}}
+		source = truncate(strings.TrimSpace(code), 100)
 	}
 
-	if strings.TrimSpace(code) == "" {
+	if files = c.profile.filter(files); len(files) == 0 {
+		tap.Box(fmt.Sprintf("No files match the include and exclude patterns of %s.", c.profile.name), "Error", tap.BoxOptions{})
+		waitForEnterMCP()
+		return nil
+	}
+	if err := checkReviewFiles(files); err != nil {
+		tap.Box(err.Error(), "Error", tap.BoxOptions{})
+		waitForEnterMCP()
+		return nil
+	}
+	chunks := chunkFiles(files, reviewChunkSize)
+	if len(chunks) == 0 {
 		tap.Cancel("No code provided")
 		return nil
 	}
 
-	lineCount := strings.Count(code, "\n") + 1
-	fmt.Printf("\n📊 Reviewing %d lines of code...\n\n", lineCount)
-
-	reviewCtx, cancel := context.WithTimeout(ctx, 120*time.Second)
-	defer cancel()
+	lineCount := 0
+	for _, f := range files {
+		lineCount += strings.Count(f.code, "\n") + 1
+	}
+	if len(files) > 1 {
+		fmt.Printf("\n📊 Reviewing %d lines of code in %d files...\n\n", lineCount, len(files))
+	} else {
+		fmt.Printf("\n📊 Reviewing %d lines of code...\n\n", lineCount)
+	}
 
 	spinner2 := tap.NewSpinner(tap.SpinnerOptions{})
 	spinner2.Start("Analyzing code...")
 
-	feedback, err := ReviewCodeWithBIA(reviewCtx, code)
+	results := make([]reviewResult, len(chunks))
+	failed := 0
+	for i, chunk := range chunks {
+		if len(chunks) > 1 {
+			spinner2.Message(fmt.Sprintf("Analyzing %s (%d/%d)...", chunkLabel(chunk), i+1, len(chunks)))
+		}
+		reviewCtx, cancel := context.WithTimeout(ctx, 120*time.Second)
+		feedback, err := c.profile.review(reviewCtx, chunk.code)
+		cancel()
+		results[i] = reviewResult{chunk: chunk, feedback: feedback, err: err}
+		if err != nil {
+			failed++
+		}
+	}
 	spinner2.Stop("Analysis complete", 1)
 
 	c.interaction = config.AgentInteraction{
-		Agent:     "BIA Junior",
+		Agent:     c.profile.agent,
 		Action:    "Code Review",
 		Timestamp: time.Now(),
+		Input:     source,
 	}
 
-	inputSummary := strings.TrimSpace(code)
-	if len(inputSummary) > 100 {
-		inputSummary = inputSummary[:100] + "..."
-	}
-	c.interaction.Input = inputSummary
-
-	if err != nil {
+	if failed == len(chunks) {
+		err := results[0].err
 		c.interaction.Success = false
 		c.interaction.Output = err.Error()
 		tap.Box(fmt.Sprintf("Review failed: %v", err), "Error", tap.BoxOptions{})
@@ -253,12 +352,11 @@ func (c *biaCodeReviewCmd) Run() error {
 		return nil
 	}
 
+	feedback := reviewMarkdown(results)
 	outputSummary := strings.TrimSpace(feedback)
-	if len(outputSummary) > 200 {
-		outputSummary = outputSummary[:200] + "..."
-	}
+	outputSummary = truncate(outputSummary, 200)
 	c.interaction.Output = outputSummary
-	c.interaction.Success = true
+	c.interaction.Success = failed == 0
 
 	fmt.Println()
 	rendered, err := renderMarkdown(feedback)
@@ -275,21 +373,21 @@ func (c *biaCodeReviewCmd) Run() error {
 	waitForEnterMCP()
 
 	tap.Outro("Review complete")
-	c.success = true
+	c.success = c.interaction.Success
 	return nil
 }
 
-func (c biaCodeReviewCmd) SetStdin(r io.Reader)  {}
-func (c biaCodeReviewCmd) SetStdout(w io.Writer) {}
-func (c biaCodeReviewCmd) SetStderr(w io.Writer) {}
+func (c codeReviewCmd) SetStdin(r io.Reader)  {}
+func (c codeReviewCmd) SetStdout(w io.Writer) {}
+func (c codeReviewCmd) SetStderr(w io.Writer) {}
 
-func runBIACodeReview() tea.Cmd {
-	cmd := &biaCodeReviewCmd{}
+func runCodeReview(profile reviewProfile, gh config.GitHubConfig) tea.Cmd {
+	cmd := &codeReviewCmd{profile: profile, github: gh}
 	return tea.Exec(cmd, func(err error) tea.Msg {
 		return tea.BatchMsg{
 			func() tea.Msg {
 				return commandDoneMsg{
-					command: "bia-review",
+					command: profile.command,
 					tool:    "skitz",
 					success: cmd.success,
 				}
@@ -454,7 +552,9 @@ func (c *mcpToolCmd) Run() error {
 	}
 	defer client.Close()
 
+	started := time.Now()
 	result, err := client.CallTool(callCtx, c.tool.Name, args)
+	auditCall(audit.KindMCP, c.tool.Name, c.serverURL, started, err)
 	spinner.Stop("Complete", 1)
 
 	if err != nil {
@@ -475,9 +575,7 @@ func (c *mcpToolCmd) Run() error {
 	}
 
 	outputSummary := strings.TrimSpace(output)
-	if len(outputSummary) > 200 {
-		outputSummary = outputSummary[:200] + "..."
-	}
+	outputSummary = truncate(outputSummary, 200)
 	c.interaction.Output = outputSummary
 	c.interaction.Success = true
 
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// The Agents tab keeps the agent_history.max_items most recent runs, and
+// none older than agent_history.max_age. Runs pruned from it are appended
+// to a compressed archive per month in the data directory, so their
+// output isn't lost, and the archives can be browsed from the palette.
+
+// addAgentInteraction puts entry at the top of the agent history, pruning
+// and saving it
+func (m *model) addAgentInteraction(entry config.AgentInteraction) {
+	m.agentHistory = append([]config.AgentInteraction{entry}, m.agentHistory...)
+	m.pruneAgentHistory()
+	config.SaveAgentHistory(m.agentHistory)
+}
+
+// pruneAgentHistory drops the runs beyond the configured limits from the
+// agent history, archiving them unless archiving is off. It reports
+// whether any were dropped.
+func (m *model) pruneAgentHistory() bool {
+	kept, pruned := config.PruneAgentHistory(m.agentHistory, m.config.AgentHistory, time.Now())
+	if len(pruned) == 0 {
+		return false
+	}
+	if m.config.AgentHistory.Archives() {
+		if err := config.ArchiveAgentInteractions(pruned); err != nil {
+			// Keep the runs rather than lose them
+			slog.Error("failed to archive agent history", "error", err)
+			return false
+		}
+	}
+	m.agentHistory = kept
+	if m.selectedAgentIdx >= len(kept) {
+		m.selectedAgentIdx = max(len(kept)-1, 0)
+	}
+	return true
+}
+
+// getAgentArchivePaletteItems returns the action listing the agent history
+// archives, once there are any
+func (m *model) getAgentArchivePaletteItems() []PaletteItem {
+	archives := config.AgentArchives()
+	if len(archives) == 0 {
+		return nil
+	}
+	return []PaletteItem{{
+		ID:       "action:agent_archives",
+		Icon:     "🗄",
+		Title:    "Browse Agent Archives",
+		Subtitle: fmt.Sprintf("Agent runs pruned from the history, %d months", len(archives)),
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			m.showAgentArchives(archives)
+			return nil
+		},
+	}}
+}
+
+// showAgentArchives replaces the palette list with an item per archive.
+// Selecting one lists its runs.
+func (m *model) showAgentArchives(archives []config.AgentArchive) {
+	var items []PaletteItem
+	for _, archive := range archives {
+		items = append(items, PaletteItem{
+			ID:       "agent_archive:" + archive.Month,
+			Icon:     "🗄",
+			Title:    archive.Month,
+			Subtitle: fmt.Sprintf("%.1f KB compressed", float64(archive.Size)/1024),
+			Category: "agent-archive",
+			Handler: func(m *model) tea.Cmd {
+				return m.showAgentArchive(archive)
+			},
+		})
+	}
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
+}
+
+// showAgentArchive replaces the palette list with an item per run in
+// archive. Selecting one shows it as markdown.
+func (m *model) showAgentArchive(archive config.AgentArchive) tea.Cmd {
+	entries, err := config.LoadAgentArchive(archive.Path)
+	if err != nil && len(entries) == 0 {
+		m.showPaletteResult("Agent Archive "+archive.Month, "Error: "+err.Error(), true)
+		return nil
+	}
+	var items []PaletteItem
+	for i, entry := range entries {
+		icon := "✓"
+		if !entry.Success {
+			icon = "✗"
+		}
+		items = append(items, PaletteItem{
+			ID:       fmt.Sprintf("agent_archive:%s:%d", archive.Month, i),
+			Icon:     icon,
+			Title:    entry.Agent + ": " + truncate(entry.Input, 50),
+			Subtitle: entry.Timestamp.Format("Jan 2 15:04") + " · " + interactionStatus(entry),
+			Category: "agent-archive",
+			Handler: func(m *model) tea.Cmd {
+				m.showPaletteResult(entry.Agent, interactionMarkdown(entry), !entry.Success)
+				return nil
+			},
+		})
+	}
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
+	if err != nil {
+		// A truncated archive still lists the runs before the damage
+		return m.showNotification("⚠️", "Part of the archive couldn't be read: "+err.Error(), "warning")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestAgentHistoryArchive(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = orig }()
+
+	m := model{}
+	m.config.AgentHistory.MaxItems = 2
+	if items := m.getAgentArchivePaletteItems(); items != nil {
+		t.Fatalf("archive action without archives: %+v", items)
+	}
+	for i := range 3 {
+		m.addAgentInteraction(config.AgentInteraction{ID: fmt.Sprint(i), Agent: "Code Reviewer", Input: "review #" + fmt.Sprint(i), Timestamp: time.Now(), Success: true})
+	}
+	if len(m.agentHistory) != 2 || m.agentHistory[0].ID != "2" || len(config.LoadAgentHistory()) != 2 {
+		t.Fatalf("history = %+v", m.agentHistory)
+	}
+
+	items := m.getAgentArchivePaletteItems()
+	if len(items) != 1 {
+		t.Fatalf("archive action = %+v", items)
+	}
+	items[0].Handler(&m)
+	if len(m.palette.Items) != 1 || m.palette.Items[0].Title != time.Now().Format("2006-01") {
+		t.Fatalf("archives = %+v", m.palette.Items)
+	}
+	m.palette.Items[0].Handler(&m)
+	if len(m.palette.Items) != 1 || !strings.Contains(m.palette.Items[0].Title, "review #0") {
+		t.Fatalf("archived runs = %+v", m.palette.Items)
+	}
+	m.palette.Items[0].Handler(&m)
+	if !strings.Contains(m.palette.ResultText, "review #0") {
+		t.Errorf("archived run shown as %q", m.palette.ResultText)
+	}
+
+	// Without archiving, pruned runs are dropped
+	off := false
+	m.config.AgentHistory.Archive = &off
+	m.addAgentInteraction(config.AgentInteraction{ID: "3", Timestamp: time.Now()})
+	if entries, _ := config.LoadAgentArchive(config.AgentArchives()[0].Path); len(entries) != 1 || len(m.agentHistory) != 2 {
+		t.Errorf("archived %d runs, kept %d", len(entries), len(m.agentHistory))
+	}
+}
@@ -0,0 +1,236 @@
+package app
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/runtime/e2b"
+)
+
+// The E2B runtime runs an agent in a cloud sandbox instead of a local
+// container: the wizard picks the sandbox template and a directory to
+// upload, the run's output streams into the agent's detail view, and the
+// sandbox is killed when the agent finishes, is cancelled, or uses up its
+// budget (e2b.max_duration and e2b.max_cost).
+
+// agentLog collects the output an agent streams while it runs
+type agentLog struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *agentLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+// String returns the output so far
+func (l *agentLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+// tail returns the last n lines of the output so far
+func (l *agentLog) tail(n int) []string {
+	lines := strings.Split(strings.TrimRight(l.String(), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines[max(len(lines)-n, 0):]
+}
+
+// e2bClient returns the configured E2B client
+func e2bClient(cfg config.E2BConfig) (e2b.Client, error) {
+	key, err := e2b.APIKey(cfg.APIKey)
+	if err != nil {
+		return e2b.Client{}, err
+	}
+	return e2b.Client{APIKey: key, APIURL: cfg.APIURL, Domain: cfg.Domain}, nil
+}
+
+// e2bBudget returns the budget of an E2B run with the wizard's timeout,
+// which replaces e2b.max_duration when set
+func e2bBudget(cfg config.E2BConfig, timeout time.Duration) e2b.Budget {
+	budget := e2b.Budget{MaxDuration: cfg.MaxDuration, MaxCost: cfg.MaxCost, CostPerHour: cfg.CostPerHour}
+	if timeout > 0 {
+		budget.MaxDuration = timeout
+	}
+	return budget
+}
+
+// e2bAgentFields returns the Run Agent wizard's E2B settings: the sandbox
+// template, suggested from the account's templates, the workspace to
+// upload and the time limit
+func (m *model) e2bAgentFields(wizard *RunAgentWizard) []huh.Field {
+	if wizard.Template == "" {
+		wizard.Template = cmp.Or(m.config.E2B.Template, e2b.DefaultTemplate)
+	}
+	if wizard.Workspace == "" {
+		wizard.Workspace = m.config.E2B.Workspace
+	}
+	cfg := m.config.E2B
+	return []huh.Field{
+		huh.NewInput().
+			Title("Sandbox Template").
+			Description("Template to start the sandbox from (ctrl+e completes)").
+			Placeholder(e2b.DefaultTemplate).
+			SuggestionsFunc(func() []string { return e2bTemplateNames(cfg) }, nil).
+			Value(&wizard.Template),
+		huh.NewInput().
+			Title("Workspace").
+			Description(fmt.Sprintf("Directory uploaded to %s (empty = none)", e2b.WorkspaceDir)).
+			Placeholder(".").
+			Value(&wizard.Workspace).
+			Validate(validateAgentWorkspace),
+		huh.NewInput().
+			Title("Timeout").
+			Description(fmt.Sprintf("Stop the run after this long (empty = %s)", e2bBudget(cfg, 0).Limit())).
+			Placeholder("10m").
+			Value(&wizard.Timeout).
+			Validate(func(s string) error {
+				_, err := parseAgentTimeout(s)
+				return err
+			}),
+	}
+}
+
+// e2bTemplateNames returns the names of the account's sandbox templates,
+// or none when they can't be listed
+func e2bTemplateNames(cfg config.E2BConfig) []string {
+	client, err := e2bClient(cfg)
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	templates, err := client.Templates(ctx)
+	if err != nil {
+		slog.Debug("failed to list E2B templates", "error", err)
+		return nil
+	}
+	names := make([]string, 0, len(templates))
+	for _, t := range templates {
+		names = append(names, t.Name())
+	}
+	return names
+}
+
+// e2bRunSummary describes an E2B run for the wizard's confirmation
+func (m *model) e2bRunSummary(wizard *RunAgentWizard) string {
+	timeout, _ := parseAgentTimeout(wizard.Timeout)
+	budget := e2bBudget(m.config.E2B, timeout)
+	summary := fmt.Sprintf("Sandbox: %s template", cmp.Or(strings.TrimSpace(wizard.Template), e2b.DefaultTemplate))
+	if ws := strings.TrimSpace(wizard.Workspace); ws != "" {
+		workspace, err := e2b.ScanWorkspace(expandHome(ws))
+		if err != nil {
+			summary += "\n⚠ " + err.Error()
+		} else {
+			summary += fmt.Sprintf(", uploading %s (%s)", ws, workspace)
+		}
+	}
+	limit := budget.Limit()
+	summary += fmt.Sprintf("\nBudget: stopped after %s (~$%.2f)", limit, budget.Cost(limit))
+	if _, err := e2b.APIKey(m.config.E2B.APIKey); err != nil {
+		summary += "\n⚠ " + err.Error()
+	}
+	return summary
+}
+
+// startE2BAgent runs the agent of the wizard in an E2B sandbox, saving the
+// template and workspace for the next run
+func (m *model) startE2BAgent(provider config.ProviderConfig, agent ActiveAgent, wizard *RunAgentWizard) tea.Cmd {
+	client, err := e2bClient(m.config.E2B)
+	if err != nil {
+		m.removeActiveAgent(agent.ID)
+		return m.showNotification("!", err.Error(), "error")
+	}
+
+	template := cmp.Or(strings.TrimSpace(wizard.Template), e2b.DefaultTemplate)
+	workspace := strings.TrimSpace(wizard.Workspace)
+	if m.config.E2B.Template != template || m.config.E2B.Workspace != workspace {
+		m.config.E2B.Template, m.config.E2B.Workspace = template, workspace
+		config.Save(m.config)
+	}
+
+	timeout, _ := parseAgentTimeout(wizard.Timeout)
+	budget := e2bBudget(m.config.E2B, timeout)
+	agent.Timeout = budget.Limit()
+	agent.Log = &agentLog{}
+	for i := range m.activeAgents {
+		if m.activeAgents[i].ID == agent.ID {
+			m.activeAgents[i] = agent
+		}
+	}
+
+	opts := e2b.RunOptions{
+		Template:  template,
+		Workspace: expandHome(workspace),
+		Process:   e2b.Process{Command: e2b.FastAgentCommand, Env: agentEnv(provider, agent.Task, provider.APIKey)},
+		Budget:    budget,
+	}
+	slog.Info("starting agent", "provider", provider.Name, "runtime", "e2b", "template", template, "agent_id", agent.ID, "limit", agent.Timeout)
+	return tea.Batch(
+		func() tea.Msg {
+			return agentStartedMsg{agent: agent}
+		},
+		m.runE2BAgent(client, opts, agent),
+	)
+}
+
+// runE2BAgent runs opts in a sandbox and reports the agent's completion.
+// cancelAgent stops the run; the sandbox is killed either way.
+func (m *model) runE2BAgent(client e2b.Client, opts e2b.RunOptions, agent ActiveAgent) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	if m.agentCancels == nil {
+		m.agentCancels = make(map[string]context.CancelFunc)
+	}
+	m.agentCancels[agent.ID] = cancel
+
+	return func() tea.Msg {
+		defer cancel()
+		result, err := client.RunSandbox(ctx, opts, agent.Log)
+
+		status := config.AgentStatusCompleted
+		switch {
+		case errors.Is(err, e2b.ErrBudgetExceeded):
+			status = config.AgentStatusTimedOut
+		case ctx.Err() != nil:
+			status = config.AgentStatusCancelled
+		case err != nil || result.ExitCode != 0:
+			status = config.AgentStatusFailed
+		}
+
+		output := agent.Log.String()
+		if err != nil {
+			output += "\n" + err.Error()
+		} else if result.ExitCode != 0 {
+			output += fmt.Sprintf("\nexit status %d", result.ExitCode)
+		}
+		if result.SandboxID != "" {
+			output += fmt.Sprintf("\n\nE2B sandbox %s · %s · ~$%.4f", result.SandboxID, result.Duration.Round(time.Second), result.Cost)
+		}
+
+		auditAgent(agent, opts.Template+": "+opts.Process.Command, status)
+
+		return agentCompletedMsg{
+			agentID:  agent.ID,
+			success:  status == config.AgentStatusCompleted,
+			output:   strings.TrimSpace(output),
+			duration: time.Since(agent.StartTime).Milliseconds(),
+			status:   status,
+		}
+	}
+}
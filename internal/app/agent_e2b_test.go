@@ -0,0 +1,50 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/runtime/e2b"
+)
+
+func TestE2BAgent(t *testing.T) {
+	cfg := config.E2BConfig{MaxDuration: time.Hour, MaxCost: 0.05}
+	if got := e2bBudget(cfg, 0).Limit(); got != 30*time.Minute {
+		t.Errorf("budget limit = %v, want the 30m max_cost pays for", got)
+	}
+	if got := e2bBudget(cfg, 5*time.Minute).Limit(); got != 5*time.Minute {
+		t.Errorf("budget limit with a timeout = %v, want 5m", got)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"code":403,"message":"Team is blocked"}`))
+	}))
+	defer srv.Close()
+
+	m := model{width: 100, height: 40}
+	agent := ActiveAgent{ID: "a1", Name: "reviewer", Runtime: "e2b", StartTime: time.Now(), Log: &agentLog{}}
+	agent.Log.Write([]byte("one\ntwo\nthree\n"))
+	if got := agent.Log.tail(2); strings.Join(got, ",") != "two,three" {
+		t.Errorf("tail(2) = %v", got)
+	}
+	m.activeAgents = []ActiveAgent{agent}
+	if view := ansi.Strip(m.renderActiveAgentDetail(100, 40)); !strings.Contains(view, "Output:") || !strings.Contains(view, "three") {
+		t.Errorf("detail view doesn't stream the output:\n%s", view)
+	}
+
+	client := e2b.Client{APIKey: "key", APIURL: srv.URL}
+	msg := m.runE2BAgent(client, e2b.RunOptions{Budget: e2bBudget(cfg, 0)}, agent)().(agentCompletedMsg)
+	if msg.success || msg.status != config.AgentStatusFailed || !strings.Contains(msg.output, "Team is blocked") {
+		t.Errorf("failed run = %+v", msg)
+	}
+	if _, ok := m.agentCancels["a1"]; !ok {
+		t.Error("run can't be cancelled")
+	}
+}
@@ -0,0 +1,236 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/github"
+	"github.com/htelsiz/skitz/internal/webhook"
+)
+
+// An agent interaction's detail view shares it outside skitz as markdown:
+// c copies it, s saves it to a file, w posts it to the configured webhooks
+// and p publishes it as a secret gist, copying the gist's URL.
+
+// agentSharedMsg reports where an interaction was shared, or why it
+// couldn't be
+type agentSharedMsg struct {
+	what string // e.g. "Posted to #agents"
+	url  string // the gist's URL
+	err  error
+}
+
+// interactionStatus returns the outcome of an interaction, e.g. "Success"
+func interactionStatus(entry config.AgentInteraction) string {
+	switch {
+	case entry.Status == config.AgentStatusCancelled:
+		return "Cancelled"
+	case entry.Status == config.AgentStatusTimedOut:
+		return "Timed out"
+	case !entry.Success:
+		return "Failed"
+	}
+	return "Success"
+}
+
+// interactionMarkdown returns an interaction as markdown: its details,
+// task and output
+func interactionMarkdown(entry config.AgentInteraction) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Agent %s\n\n", entry.Agent)
+	fmt.Fprintf(&b, "- **Status:** %s\n", interactionStatus(entry))
+	if entry.Provider != "" {
+		fmt.Fprintf(&b, "- **Provider:** %s\n", entry.Provider)
+	}
+	if entry.Runtime != "" {
+		fmt.Fprintf(&b, "- **Runtime:** %s\n", entry.Runtime)
+	}
+	fmt.Fprintf(&b, "- **Ran:** %s\n", entry.Timestamp.Format("2006-01-02 15:04:05"))
+	if entry.Duration > 0 {
+		fmt.Fprintf(&b, "- **Duration:** %s\n", formatDuration(time.Duration(entry.Duration)*time.Millisecond))
+	}
+
+	b.WriteString("\n### Task\n\n")
+	b.WriteString(strings.TrimSpace(entry.Input) + "\n")
+
+	b.WriteString("\n### Output\n\n")
+	if output := strings.TrimRight(entry.Output, "\n"); output != "" {
+		b.WriteString(codeBlock("", output) + "\n")
+	} else {
+		b.WriteString("*No output.*\n")
+	}
+	return b.String()
+}
+
+// interactionFileName is the file an interaction is saved or shared as,
+// e.g. "code-reviewer-20250131-093000.md"
+func interactionFileName(entry config.AgentInteraction) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == ' ' || r == os.PathSeparator {
+			return '-'
+		}
+		return r
+	}, entry.Agent)
+	return fmt.Sprintf("%s-%s.md", name, entry.Timestamp.Format("20060102-150405"))
+}
+
+// selectedInteraction returns the interaction shown in the detail view
+func (m model) selectedInteraction() (config.AgentInteraction, bool) {
+	if m.selectedAgentIdx < 0 || m.selectedAgentIdx >= len(m.agentHistory) {
+		return config.AgentInteraction{}, false
+	}
+	return m.agentHistory[m.selectedAgentIdx], true
+}
+
+// copyInteraction copies the selected interaction as markdown
+func (m *model) copyInteraction() tea.Cmd {
+	entry, ok := m.selectedInteraction()
+	if !ok {
+		return nil
+	}
+	if err := clipboard.WriteAll(interactionMarkdown(entry)); err != nil {
+		return m.showNotification("!", "Copy failed: "+err.Error(), "error")
+	}
+	return m.showNotification("📋", "Interaction copied as markdown", "success")
+}
+
+// startInteractionSave asks where to save the selected interaction
+func (m *model) startInteractionSave() tea.Cmd {
+	entry, ok := m.selectedInteraction()
+	if !ok {
+		return nil
+	}
+	params := []config.ActionParam{
+		{Name: "path", Title: "File", Default: interactionFileName(entry), Required: true},
+	}
+	return m.collectActionParams("Save Interaction", "💾", params, func(m *model, values map[string]string) tea.Cmd {
+		path := expandHome(values["path"])
+		if err := os.WriteFile(path, []byte(interactionMarkdown(entry)), 0644); err != nil {
+			slog.Error("failed to save agent interaction", "path", path, "error", err)
+			return m.showNotification("✗", "Save failed: "+err.Error(), "error")
+		}
+		return m.showNotification("💾", "Saved to "+path, "success")
+	})
+}
+
+// interactionSummary describes an interaction for webhooks
+func interactionSummary(entry config.AgentInteraction) webhook.Summary {
+	return webhook.Summary{
+		Event:    webhook.EventAgent,
+		Title:    "Agent " + entry.Agent,
+		Task:     entry.Input,
+		Success:  entry.Success,
+		Status:   strings.ToLower(interactionStatus(entry)),
+		Duration: time.Duration(entry.Duration) * time.Millisecond,
+		Output:   entry.Output,
+		User:     webhook.CurrentUser(),
+		Details: []webhook.Detail{
+			{Name: "Runtime", Value: entry.Runtime},
+			{Name: "Provider", Value: entry.Provider},
+			{Name: "Ran", Value: entry.Timestamp.Format("2006-01-02 15:04")},
+		},
+	}
+}
+
+// postInteraction posts the selected interaction to the configured
+// webhooks, asking which when there are several. Their event filters are
+// ignored: sharing a run posts it wherever it's sent.
+func (m *model) postInteraction() tea.Cmd {
+	entry, ok := m.selectedInteraction()
+	if !ok {
+		return nil
+	}
+	var hooks []config.WebhookConfig
+	var names []string
+	for i, hook := range m.config.Integrations.Webhooks {
+		if hook.URL == "" {
+			continue
+		}
+		if hook.Name == "" {
+			hook.Name = fmt.Sprintf("webhook %d", i+1)
+		}
+		hooks = append(hooks, hook)
+		names = append(names, hook.Name)
+	}
+	switch len(hooks) {
+	case 0:
+		return m.showNotification("📡", "No webhooks configured: add them under integrations.webhooks", "warning")
+	case 1:
+		return postInteractionCmd(hooks[0], entry)
+	}
+
+	params := []config.ActionParam{
+		{Name: "webhook", Title: "Webhook", Options: append([]string{"All"}, names...), Required: true},
+	}
+	return m.collectActionParams("Post Interaction", "📡", params, func(m *model, values map[string]string) tea.Cmd {
+		var cmds []tea.Cmd
+		for _, hook := range hooks {
+			if values["webhook"] == "All" || values["webhook"] == hook.Name {
+				cmds = append(cmds, postInteractionCmd(hook, entry))
+			}
+		}
+		return tea.Batch(cmds...)
+	})
+}
+
+// postInteractionCmd posts entry to hook in the background
+func postInteractionCmd(hook config.WebhookConfig, entry config.AgentInteraction) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := webhook.Post(ctx, hook, interactionSummary(entry)); err != nil {
+			return agentSharedMsg{err: fmt.Errorf("%s: %w", hook.Name, err)}
+		}
+		return agentSharedMsg{what: "Posted to " + hook.Name}
+	}
+}
+
+// shareInteractionGist creates a secret gist with the selected interaction
+func (m *model) shareInteractionGist() tea.Cmd {
+	entry, ok := m.selectedInteraction()
+	if !ok {
+		return nil
+	}
+	gh := m.config.Integrations.GitHub
+	notify := m.showNotification("⏳", "Creating gist...", "info")
+	return tea.Batch(notify, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		token, err := github.Token(ctx, gh.Token)
+		if err != nil {
+			return agentSharedMsg{err: err}
+		}
+		url, err := github.Client{Token: token, BaseURL: gh.APIURL}.CreateGist(ctx, github.Gist{
+			Description: fmt.Sprintf("skitz: agent %s, %s", entry.Agent, entry.Timestamp.Format("2006-01-02 15:04")),
+			Files:       map[string]string{interactionFileName(entry): interactionMarkdown(entry)},
+		})
+		if err != nil {
+			return agentSharedMsg{err: fmt.Errorf("failed to create gist: %w", err)}
+		}
+		return agentSharedMsg{what: "Created gist", url: url}
+	})
+}
+
+// handleAgentShared tells the user where an interaction was shared,
+// copying a gist's URL
+func (m *model) handleAgentShared(msg agentSharedMsg) tea.Cmd {
+	if msg.err != nil {
+		slog.Warn("failed to share agent interaction", "error", msg.err)
+		return m.showNotification("✗", msg.err.Error(), "error")
+	}
+	if msg.url == "" {
+		return m.showNotification("📡", msg.what, "success")
+	}
+	if err := clipboard.WriteAll(msg.url); err != nil {
+		return m.showNotification("🔗", msg.what+": "+msg.url, "success")
+	}
+	return m.showNotification("🔗", msg.what+", URL copied: "+msg.url, "success")
+}
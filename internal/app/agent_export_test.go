@@ -0,0 +1,115 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/webhook"
+)
+
+func TestAgentInteractionExport(t *testing.T) {
+	entry := config.AgentInteraction{
+		Agent:     "code reviewer",
+		Input:     "Review the diff",
+		Output:    "Found 2 issues\n```go\nx := 1\n```\n",
+		Timestamp: time.Date(2025, 1, 31, 9, 30, 0, 0, time.UTC),
+		Success:   true,
+		Runtime:   "docker",
+		Provider:  "anthropic",
+		Duration:  1500,
+	}
+	md := interactionMarkdown(entry)
+	for _, want := range []string{"## Agent code reviewer", "- **Status:** Success", "- **Duration:** 1.5s", "### Task\n\nReview the diff", "````\nFound 2 issues"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown lacks %q:\n%s", want, md)
+		}
+	}
+	if name := interactionFileName(entry); name != "code-reviewer-20250131-093000.md" {
+		t.Errorf("interactionFileName() = %q", name)
+	}
+
+	var posted webhook.Summary
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&posted)
+	}))
+	defer srv.Close()
+
+	m := model{agentHistory: []config.AgentInteraction{entry}}
+	if m.postInteraction(); len(m.notifications) != 1 || !strings.Contains(m.notifications[0].Message, "No webhooks") {
+		t.Errorf("post without webhooks: %+v", m.notifications)
+	}
+
+	// A webhook's event filter doesn't stop sharing to it
+	m.config.Integrations.Webhooks = []config.WebhookConfig{{Name: "deploys", URL: srv.URL, Type: webhook.TypeJSON, Events: []string{"deploy"}}}
+	msg, ok := m.postInteraction()().(agentSharedMsg)
+	if !ok || msg.err != nil || msg.what != "Posted to deploys" {
+		t.Fatalf("post = %+v", msg)
+	}
+	if posted.Title != "Agent code reviewer" || posted.Task != "Review the diff" || posted.Status != "success" {
+		t.Errorf("posted %+v", posted)
+	}
+
+	// Several webhooks ask which
+	m.config.Integrations.Webhooks = append(m.config.Integrations.Webhooks, config.WebhookConfig{URL: srv.URL})
+	m.postInteraction()
+	if m.palette.State != PaletteStateCollectingParams || m.palette.PendingAction == nil {
+		t.Errorf("several webhooks didn't ask which, state = %v", m.palette.State)
+	}
+	m.closePalette()
+
+	// Saving asks for the file, defaulting to the interaction's name
+	dir := t.TempDir()
+	m.startInteractionSave()
+	pa := m.palette.PendingAction
+	if pa == nil {
+		t.Fatal("save didn't ask for a file")
+	}
+	path := filepath.Join(dir, "review.md")
+	pa.Run(&m, map[string]string{"path": path})
+	if data, err := os.ReadFile(path); err != nil || string(data) != md {
+		t.Errorf("saved %q, %v", data, err)
+	}
+}
+
+func TestAgentInteractionGist(t *testing.T) {
+	var files map[string]map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Files map[string]map[string]string `json:"files"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		files = body.Files
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url": "https://gist.github.com/aa5a315d"}`))
+	}))
+	defer srv.Close()
+
+	m := model{agentHistory: []config.AgentInteraction{{Agent: "reviewer", Input: "Review", Success: true}}}
+	m.config.Integrations.GitHub = config.GitHubConfig{Token: "t0ken", APIURL: srv.URL}
+
+	var shared agentSharedMsg
+	for _, cmd := range m.shareInteractionGist()().(tea.BatchMsg) {
+		if msg, ok := cmd().(agentSharedMsg); ok {
+			shared = msg
+		}
+	}
+	if shared.err != nil || shared.url != "https://gist.github.com/aa5a315d" {
+		t.Fatalf("shared = %+v", shared)
+	}
+	if len(files) != 1 || !strings.Contains(files["reviewer-00010101-000000.md"]["content"], "## Agent reviewer") {
+		t.Errorf("gist files = %v", files)
+	}
+	m.handleAgentShared(shared)
+	if last := m.notifications[len(m.notifications)-1]; !strings.Contains(last.Message, shared.url) {
+		t.Errorf("notification = %q", last.Message)
+	}
+}
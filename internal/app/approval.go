@@ -0,0 +1,88 @@
+package app
+
+import (
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/approval"
+	"github.com/htelsiz/skitz/internal/audit"
+)
+
+// Every way of acting on a command an AI generated goes through
+// approveGenerated: running it (ctrl+r) and handing it off to the shell
+// (ctrl+t), whether the Ask panel was opened directly or to ask about a
+// failed command or a palette result. The decisions are recorded in the
+// audit log.
+
+// runGeneratedCommand runs the Ask panel's generated command if the
+// approval policy allows it, and otherwise asks first or refuses it
+func (m *model) runGeneratedCommand() tea.Cmd {
+	command := m.askPanel.GeneratedCmd
+	return m.approveGenerated(command, "run", func(m *model) tea.Cmd {
+		m.askPanel = nil
+		return m.runCommand(CommandSpec{Command: command, Mode: CommandEmbedded})
+	})
+}
+
+// handOffGeneratedCommand leaves the Ask panel's generated command at the
+// shell prompt if the approval policy allows it
+func (m *model) handOffGeneratedCommand() tea.Cmd {
+	command := m.askPanel.GeneratedCmd
+	return m.approveGenerated(command, "handoff", func(m *model) tea.Cmd {
+		return m.handOff(command)
+	})
+}
+
+// approveGenerated calls act, which runs command the way how names, if the
+// approval policy allows command. Commands the policy asks about wait for
+// resolveApproval; denied ones are refused.
+func (m *model) approveGenerated(command, how string, act func(*model) tea.Cmd) tea.Cmd {
+	engine, err := approval.New(m.config.Approval)
+	if err != nil {
+		slog.Error("invalid approval policy", "error", err)
+		return m.showNotification("⚠", err.Error(), "error")
+	}
+
+	result := engine.Evaluate(command)
+	switch result.Decision {
+	case approval.Deny:
+		m.auditApproval(command, how, approval.Denied, result.Reason)
+		return m.showNotification("🔒", "Command denied: "+result.Reason, "error")
+	case approval.Ask:
+		m.askPanel.PendingApproval = result.Reason
+		m.askPanel.pendingAction = func(m *model, approved bool) tea.Cmd {
+			if !approved {
+				m.auditApproval(command, how, approval.Rejected, result.Reason)
+				return nil
+			}
+			m.auditApproval(command, how, approval.Approved, result.Reason)
+			return act(m)
+		}
+		return nil
+	}
+
+	m.auditApproval(command, how, approval.Allowed, result.Reason)
+	return act(m)
+}
+
+// resolveApproval runs or drops the command waiting for approval
+func (m *model) resolveApproval(approved bool) tea.Cmd {
+	action := m.askPanel.pendingAction
+	m.askPanel.PendingApproval, m.askPanel.pendingAction = "", nil
+	if action == nil {
+		return nil
+	}
+	return action(m, approved)
+}
+
+// auditApproval records a decision about an AI-generated command in the
+// audit log
+func (m *model) auditApproval(command, how, outcome, reason string) {
+	e := audit.Entry{Kind: audit.KindApproval, Action: command, Mode: how, Status: outcome, Reason: reason}
+	if res := m.currentResource(); res != nil {
+		e.Target = res.name
+	}
+	slog.Info("AI command approval", "command", command, "how", how, "outcome", outcome, "reason", reason)
+	audit.Record(e)
+}
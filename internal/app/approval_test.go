@@ -0,0 +1,61 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/approval"
+	"github.com/htelsiz/skitz/internal/audit"
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestApproveGenerated(t *testing.T) {
+	dir := t.TempDir()
+	if err := audit.Configure(config.AuditConfig{Enabled: true}, dir); err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Configure(config.AuditConfig{}, dir)
+
+	m := model{}
+	m.config.Approval = config.ApprovalConfig{Deny: []string{`^curl\b`}}
+
+	// Handing a destructive command off to the shell waits for approval
+	m.askPanel = &AskPanel{GeneratedCmd: "rm -rf build"}
+	if cmd := m.handOffGeneratedCommand(); cmd != nil || m.handoff != "" || m.askPanel.PendingApproval != "looks destructive" {
+		t.Fatalf("handoff not held for approval: handoff = %q, pending = %q", m.handoff, m.askPanel.PendingApproval)
+	}
+	m.resolveApproval(false)
+	if m.handoff != "" || m.askPanel.PendingApproval != "" {
+		t.Errorf("rejected command handed off: %q", m.handoff)
+	}
+
+	// A denied command is never run
+	m.askPanel = &AskPanel{GeneratedCmd: "curl example.com"}
+	m.runGeneratedCommand()
+	if m.askPanel == nil || m.askPanel.PendingApproval != "" {
+		t.Error("denied command ran or waits for approval")
+	}
+	audit.Close()
+
+	data, err := os.ReadFile(audit.Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for line := range strings.SplitSeq(strings.TrimSpace(string(data)), "\n") {
+		var e audit.Entry
+		json.Unmarshal([]byte(line), &e)
+		if e.Kind != audit.KindApproval || e.Reason == "" {
+			t.Errorf("entry = %+v", e)
+		}
+		got = append(got, e.Mode+" "+e.Action+" "+e.Status)
+	}
+	slices.Sort(got)
+	want := []string{"handoff rm -rf build " + approval.Rejected, "run curl example.com " + approval.Denied}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("audit log = %q, want %q", got, want)
+	}
+}
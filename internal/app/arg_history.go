@@ -0,0 +1,93 @@
+package app
+
+import (
+	"log/slog"
+	"maps"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Values submitted in an MCP tool's parameter form are remembered per tool
+// parameter, and values typed for {{NAME}} placeholders per placeholder
+// name. The next form starts with the last value and suggests the earlier
+// ones as you type. Names matching arg_history.exclude, by default those
+// that look like passwords or tokens, are never remembered.
+
+// mcpArgKey is the argument history key of a tool's parameter
+func mcpArgKey(toolRef, param string) string {
+	return "mcp:" + toolRef + ":" + param
+}
+
+// placeholderArgKey is the argument history key of a placeholder
+func placeholderArgKey(name string) string {
+	return "var:" + name
+}
+
+// argSuggestions returns the remembered values of the argument name,
+// stored under key
+func (m model) argSuggestions(key, name string) []string {
+	if !m.config.ArgHistory.Remembers(name) {
+		return nil
+	}
+	return m.argHistory[key]
+}
+
+// lastArg returns the last value submitted for the argument name, stored
+// under key
+func (m model) lastArg(key, name string) string {
+	if !m.config.ArgHistory.Remembers(name) {
+		return ""
+	}
+	value, _ := m.argHistory.Last(key)
+	return value
+}
+
+// mcpArgDefaults returns the values the parameter form of tool starts
+// with: those of preset, then the last submitted, then the defaults of
+// the tool's schema
+func (m model) mcpArgDefaults(server string, tool mcp.Tool, preset map[string]string) map[string]string {
+	defaults := maps.Clone(preset)
+	if defaults == nil {
+		defaults = make(map[string]string)
+	}
+	ref := mcpToolRef(server, tool.Name)
+	for name := range tool.InputSchema.Properties {
+		if defaults[name] != "" {
+			continue
+		}
+		if value := m.lastArg(mcpArgKey(ref, name), name); value != "" {
+			defaults[name] = value
+			continue
+		}
+		if schema, ok := tool.InputSchema.Properties[name].(map[string]any); ok {
+			if value := schemaDefault(schema); value != "" {
+				defaults[name] = value
+			}
+		}
+	}
+	return defaults
+}
+
+// rememberArgs adds the submitted values, keyed by key(name), to the
+// argument history and saves it
+func (m *model) rememberArgs(values map[string]string, key func(name string) string) {
+	if m.argHistory == nil {
+		m.argHistory = config.ArgHistory{}
+	}
+	changed := false
+	for name, value := range values {
+		if value == "" || !m.config.ArgHistory.Remembers(name) {
+			continue
+		}
+		m.argHistory.Add(key(name), value)
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	if err := config.SaveArgHistory(m.argHistory); err != nil {
+		slog.Warn("failed to save argument history", "error", err)
+	}
+}
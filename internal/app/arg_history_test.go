@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestArgHistoryPrefill(t *testing.T) {
+	oldDataDir := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = oldDataDir }()
+
+	tool := mcp.Tool{Name: "search_logs", InputSchema: mcp.ToolInputSchema{Properties: map[string]any{
+		"query":   map[string]any{"type": "string"},
+		"api_key": map[string]any{"type": "string"},
+		"limit":   map[string]any{"type": "integer"},
+	}}}
+	var m model
+	m.palette.PendingTool = &mcpPendingTool{ServerName: "datadog", Tool: tool, FormValues: mcpFormValues(tool, map[string]string{"query": "status:error", "api_key": "s3cret", "limit": "10"})}
+	m.handleParameterSubmit()
+
+	history := config.LoadArgHistory()
+	if last, _ := history.Last("mcp:datadog.search_logs:query"); last != "status:error" {
+		t.Errorf("query not remembered: %v", history)
+	}
+	if _, ok := history.Last("mcp:datadog.search_logs:api_key"); ok {
+		t.Error("api_key remembered")
+	}
+
+	defaults := m.mcpArgDefaults("datadog", tool, map[string]string{"limit": "50"})
+	if defaults["query"] != "status:error" || defaults["limit"] != "50" || defaults["api_key"] != "" {
+		t.Errorf("mcpArgDefaults() = %v, want the last query and the preset limit", defaults)
+	}
+	if got := m.argSuggestions("mcp:datadog.search_logs:query", "query"); len(got) != 1 {
+		t.Errorf("argSuggestions() = %v", got)
+	}
+
+	m.config.ArgHistory.Disabled = true
+	if defaults := m.mcpArgDefaults("datadog", tool, nil); len(defaults) != 0 {
+		t.Errorf("mcpArgDefaults() with history disabled = %v", defaults)
+	}
+}
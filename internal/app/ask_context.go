@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aaronjanse/3mux/vterm"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+
+	"github.com/htelsiz/skitz/internal/ai"
+)
+
+// Context sources offered by the Ask AI context picker
+const (
+	askContextOutput = "output"
+	askContextDiff   = "diff"
+	askContextFile   = "file"
+)
+
+// commandOutput is the captured output of the last embedded or ^table
+// command, kept for the Ask AI context picker
+type commandOutput struct {
+	command string
+	text    string
+}
+
+// askContextMsg carries an attachment read in the background
+type askContextMsg struct {
+	attachment ai.Attachment
+	err        error
+}
+
+// startAskContextPicker shows the form for attaching extra context to the
+// Ask AI question
+func (m *model) startAskContextPicker() tea.Cmd {
+	var options []huh.Option[string]
+	if m.lastOutput != nil {
+		options = append(options, huh.NewOption("Last command output ("+m.lastOutput.command+")", askContextOutput))
+	}
+	options = append(options,
+		huh.NewOption("Git diff", askContextDiff),
+		huh.NewOption("File…", askContextFile),
+	)
+
+	p := m.askPanel
+	p.ContextChoice = options[0].Value
+	p.ContextPath = ""
+	p.ContextForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Attach context").
+				Description("Sent with your question, truncated to fit").
+				Options(options...).
+				Value(&p.ContextChoice),
+		),
+		huh.NewGroup(
+			huh.NewInput().
+				Title("File").
+				Placeholder("path/to/file").
+				Value(&p.ContextPath).
+				Validate(func(s string) error {
+					if strings.TrimSpace(s) == "" {
+						return fmt.Errorf("enter a file path")
+					}
+					return nil
+				}),
+		).WithHideFunc(func() bool { return p.ContextChoice != askContextFile }),
+	).
+		WithWidth(60).
+		WithShowHelp(true).
+		WithTheme(huh.ThemeCatppuccin())
+	return p.ContextForm.Init()
+}
+
+// attachAskContext reads the context chosen in the picker
+func (m *model) attachAskContext() tea.Cmd {
+	p := m.askPanel
+	p.ContextForm = nil
+	limit := m.config.AI.ContextLimit
+
+	switch p.ContextChoice {
+	case askContextOutput:
+		if m.lastOutput == nil {
+			return nil
+		}
+		out := *m.lastOutput
+		return func() tea.Msg {
+			return askContextMsg{attachment: ai.NewAttachment("output of "+out.command, out.text, limit, ai.KeepTail)}
+		}
+
+	case askContextDiff:
+		return func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			out, err := exec.CommandContext(ctx, "git", "diff", "HEAD").Output()
+			if err != nil {
+				return askContextMsg{err: fmt.Errorf("failed to run git diff: %w", err)}
+			}
+			if len(out) == 0 {
+				return askContextMsg{err: fmt.Errorf("no uncommitted changes")}
+			}
+			return askContextMsg{attachment: ai.NewAttachment("git diff", string(out), limit, ai.KeepHead)}
+		}
+
+	case askContextFile:
+		path := expandHome(strings.TrimSpace(p.ContextPath))
+		return func() tea.Msg {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return askContextMsg{err: fmt.Errorf("failed to read file: %w", err)}
+			}
+			return askContextMsg{attachment: ai.NewAttachment(filepath.Base(path), string(data), limit, ai.KeepEnds)}
+		}
+	}
+	return nil
+}
+
+// handleAskContext adds a read attachment to the Ask AI panel
+func (m *model) handleAskContext(msg askContextMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.showNotification("📎", msg.err.Error(), "error")
+	}
+	if m.askPanel == nil {
+		return nil
+	}
+	m.askPanel.Contexts = append(m.askPanel.Contexts, msg.attachment)
+	return nil
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// terminalText returns the scrollback and screen of a terminal as plain
+// text without trailing blank lines
+func terminalText(vt *vterm.VTerm) string {
+	rows := append(vt.Scrollback[:len(vt.Scrollback):len(vt.Scrollback)], vt.Screen...)
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		var line strings.Builder
+		for _, ch := range row {
+			if ch.Rune == 0 {
+				line.WriteRune(' ')
+			} else {
+				line.WriteRune(ch.Rune)
+			}
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// formatAttachmentSize formats an attachment's size for the Ask AI panel,
+// e.g. "2.1 KB, truncated"
+func formatAttachmentSize(a ai.Attachment) string {
+	size := fmt.Sprintf("%d B", a.Size)
+	if a.Size >= 1024 {
+		size = fmt.Sprintf("%.1f KB", float64(a.Size)/1024)
+	}
+	if a.Truncated {
+		size += ", truncated"
+	}
+	return size
+}
@@ -1,11 +1,14 @@
 package app
 
 import (
+	"log/slog"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/rag"
 )
 
 func (m *model) submitAskPanel() tea.Cmd {
@@ -18,21 +21,44 @@ func (m *model) submitAskPanel() tea.Cmd {
 	m.askPanel.Error = ""
 	m.askPanel.GeneratedCmd = ""
 
+	m.askPanel.Sources = nil
+
 	question := m.askPanel.Input
 	context := ""
-	if res := m.currentResource(); res != nil {
+	if m.askPanel.Attachment != "" {
+		context = m.askPanel.Attachment
+	} else if res := m.currentResource(); res != nil {
 		context = res.content
 	}
+	attachments := m.askPanel.Contexts
+
+	// With the index, context comes from all resources rather than only
+	// the current one
+	ix, cfg := m.ragIndex, m.config
+	var docs []rag.Document
+	if ix != nil && m.askPanel.Attachment == "" {
+		docs = ragDocuments(m.resources, m.history)
+	}
 
 	return func() tea.Msg {
-		client, err := ai.GetDefaultClient(m.config)
+		router, err := ai.NewRouter(cfg, config.FeatureAsk)
 		if err != nil {
 			return aiResponseMsg{err: err}
 		}
 
-		resp := client.Ask(question, context)
+		var sources []string
+		if docs != nil {
+			retrieved, found, err := retrieveContext(cfg, ix, docs, question)
+			if err != nil {
+				slog.Warn("rag retrieval failed, using the current resource", "error", err)
+			} else {
+				context, sources = retrieved, found
+			}
+		}
+
+		resp := router.Ask(question, ai.FormatAttachments(context, attachments))
 		if resp.Error != nil {
-			return aiResponseMsg{err: resp.Error}
+			return aiResponseMsg{err: resp.Error, provider: resp.Provider, failedOver: resp.FailedOver}
 		}
 
 		var generatedCmd string
@@ -48,6 +74,9 @@ func (m *model) submitAskPanel() tea.Cmd {
 		return aiResponseMsg{
 			response:     resp.Content,
 			generatedCmd: generatedCmd,
+			sources:      sources,
+			provider:     resp.Provider,
+			failedOver:   resp.FailedOver,
 		}
 	}
 }
@@ -70,27 +99,32 @@ func (m *model) submitGenerateCommand() tea.Cmd {
 		}
 	}
 
+	cfg := m.config
 	return func() tea.Msg {
-		client, err := ai.GetDefaultClient(m.config)
+		router, err := ai.NewRouter(cfg, config.FeatureGenerateCommand)
 		if err != nil {
 			return aiResponseMsg{err: err}
 		}
 
-		resp := client.GenerateCommand(description, context)
+		resp := router.GenerateCommand(description, context)
 		if resp.Error != nil {
-			return aiResponseMsg{err: resp.Error}
+			return aiResponseMsg{err: resp.Error, provider: resp.Provider, failedOver: resp.FailedOver}
 		}
 
 		content := strings.TrimSpace(resp.Content)
 		if strings.HasPrefix(content, "ERROR:") {
 			return aiResponseMsg{
-				response: content,
+				response:   content,
+				provider:   resp.Provider,
+				failedOver: resp.FailedOver,
 			}
 		}
 
 		return aiResponseMsg{
 			response:     "Generated command:",
 			generatedCmd: content,
+			provider:     resp.Provider,
+			failedOver:   resp.FailedOver,
 		}
 	}
 }
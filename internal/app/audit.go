@@ -0,0 +1,80 @@
+package app
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/audit"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/hooks"
+)
+
+// audit: in config.yaml, or in the organization policy, which takes
+// precedence, records every command, job, MCP tool call, agent run, plugin
+// call and deploy action in the audit log. History can be turned off or trimmed; the audit log
+// is only ever appended to.
+
+// configureAudit starts or stops the audit log for cfg
+func configureAudit(cfg config.Config) {
+	if err := audit.Configure(cfg.EffectiveAudit(), config.DataDir); err != nil {
+		slog.Error("audit log disabled", "error", err)
+	}
+}
+
+// auditCommand records a finished command
+func auditCommand(ev hooks.Event) {
+	code := ev.ExitCode
+	audit.Record(audit.Entry{
+		Kind:       audit.KindCommand,
+		Action:     ev.Command,
+		Target:     ev.Resource,
+		Mode:       ev.Mode,
+		Status:     auditStatus(code == 0),
+		ExitCode:   &code,
+		DurationMS: ev.Duration.Milliseconds(),
+	})
+}
+
+// auditCall records an MCP tool call or deploy action of kind on target
+// that took since started and failed with err, if not nil
+func auditCall(kind, action, target string, started time.Time, err error) {
+	e := audit.Entry{
+		Kind:   kind,
+		Action: action,
+		Target: target,
+		Status: auditStatus(err == nil),
+	}
+	if !started.IsZero() {
+		e.DurationMS = time.Since(started).Milliseconds()
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	audit.Record(e)
+}
+
+// auditAgent records an agent run that ended with status. command must
+// have its API keys redacted already.
+func auditAgent(agent ActiveAgent, command, status string) {
+	e := audit.Entry{
+		Kind:   audit.KindAgent,
+		Action: command,
+		Target: agent.Provider,
+		Mode:   agent.Runtime,
+		Status: auditStatus(status == config.AgentStatusCompleted),
+	}
+	if status != config.AgentStatusCompleted {
+		e.Error = status
+	}
+	if !agent.StartTime.IsZero() {
+		e.DurationMS = time.Since(agent.StartTime).Milliseconds()
+	}
+	audit.Record(e)
+}
+
+func auditStatus(ok bool) string {
+	if ok {
+		return audit.StatusOK
+	}
+	return audit.StatusFailed
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/audit"
+	"github.com/htelsiz/skitz/internal/config"
+	pluginspkg "github.com/htelsiz/skitz/internal/plugins"
+)
+
+func TestAuditAgentsAndPlugins(t *testing.T) {
+	dir := t.TempDir()
+	if err := audit.Configure(config.AuditConfig{Enabled: true}, dir); err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Configure(config.AuditConfig{}, dir)
+
+	m := model{pluginPanels: make(map[string]pluginPanelState)}
+	m.config.AI.Providers = []config.ProviderConfig{{Name: "openai", APIKey: "sk-secret"}}
+	agent := ActiveAgent{ID: "a1", Provider: "openai", Runtime: "docker"}
+	m.runAgentCommand(CommandSpec{Command: "OPENAI_API_KEY=sk-secret true"}, agent)()
+
+	plugin := pluginspkg.Plugin{Path: "/bin/true", Manifest: pluginspkg.Manifest{Name: "k8s"}}
+	m.fetchPluginPanel(pluginPanel{plugin: plugin, panel: pluginspkg.Panel{ID: "nodes"}})()
+	audit.Close()
+
+	data, err := os.ReadFile(audit.Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-secret") {
+		t.Errorf("API key in the audit log: %s", data)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit log = %s, want an agent and a plugin entry", data)
+	}
+	entries := map[string]audit.Entry{}
+	for _, line := range lines {
+		var e audit.Entry
+		json.Unmarshal([]byte(line), &e)
+		entries[e.Kind] = e
+	}
+	if e := entries[audit.KindAgent]; e.Action != "OPENAI_API_KEY=**** true" || e.Target != "openai" || e.Mode != "docker" || e.Status != audit.StatusOK {
+		t.Errorf("agent entry = %+v", e)
+	}
+	if e := entries[audit.KindPlugin]; e.Action != "panel nodes" || e.Target != "/bin/true" {
+		t.Errorf("plugin entry = %+v", e)
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// The dashboard header shows a logo, the title art and a quote typed out
+// at startup. banner: in config.yaml replaces any of them, with art inline
+// or in a .txt file in the banners directory, and picks the quote from a
+// list.
+
+// defaultLogo is the BIA crane, drawn above the BIA bar
+const defaultLogo = `⣿⣿⣿⣿⣿⣿⣿⣿⣿⡿⠿⠿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿
+⣿⣿⣿⣿⣿⣿⡿⠟⠋⣁⡄⠀⢠⣄⣉⡙⠛⠿⢿⣿⣿⣿⣿⣿
+⣿⣿⣿⣿⠿⠛⣁⣤⣶⣿⠇⣤⠈⣿⣿⣿⣿⣶⣦⣄⣉⠙⠛⠿
+⣿⣿⣯⣤⣴⣿⣿⣿⣿⣿⣤⣿⣤⣽⣿⣿⣿⣿⣿⣿⣿⣿⣷⣦
+⣿⡇⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⢸⣿
+⣿⣿⣿⡟⠛⠛⠛⣿⣿⣿⣿⡟⠛⢻⡟⠛⢻⣿⣿⣿⣿⣿⣿⣿
+⣿⣿⣿⣷⣶⣶⣶⣿⣿⣿⣿⣇⣀⣸⣇⣀⣼⣿⣿⣿⣿⣿⣿⣿
+⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⡏⠉⢹⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿
+⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⡇⠀⢸⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿
+⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⠿⡇⠀⢸⡿⣿⣿⣿⣿⠀⠀⠀⢸⣿
+⣿⣿⣿⣿⣿⣿⣿⡿⠋⣁⣴⡇⠀⢸⣷⣌⠙⢿⣿⣿⣿⣿⣿⣿
+⣿⣿⣿⣿⣿⣿⣿⣷⣾⣿⣿⣷⣤⣼⣿⣿⣿⣶⣿⣿⣿⣿⣿⣿`
+
+// defaultTitle is the skitz block title
+const defaultTitle = `█▀ █▄▀ █ ▀█▀ ▀█
+▄█ █ █ █  █  █▄`
+
+// dashboardBanner is the header art and quote in use. Empty fields show
+// the defaults.
+type dashboardBanner struct {
+	logo      string // replaces the BIA crane and bar
+	title     string
+	quote     string
+	hideLogo  bool
+	hideTitle bool
+}
+
+// loadBanner resolves cfg's art and picks one of its quotes
+func loadBanner(cfg config.BannerConfig) dashboardBanner {
+	var b dashboardBanner
+	if cfg.Logo != "" {
+		b.logo = bannerArt(cfg.Logo)
+		b.hideLogo = b.logo == ""
+	}
+	if cfg.Title != "" {
+		b.title = bannerArt(cfg.Title)
+		b.hideTitle = b.title == ""
+	}
+	if len(cfg.Quotes) > 0 {
+		b.quote = cfg.Quotes[rand.IntN(len(cfg.Quotes))]
+	}
+	return b
+}
+
+// quoteText returns the quote typed out on the dashboard
+func (b dashboardBanner) quoteText() string {
+	if b.quote == "" {
+		return dashboardQuote
+	}
+	return b.quote
+}
+
+// bannerArt returns the art value stands for: none, a file in the banners
+// directory, or the art itself
+func bannerArt(value string) string {
+	if value == config.BannerNone {
+		return ""
+	}
+	if !strings.Contains(value, "\n") {
+		name := strings.TrimSuffix(value, ".txt") + ".txt"
+		if filepath.Base(name) == name {
+			data, err := os.ReadFile(filepath.Join(config.BannersDir(), name))
+			if err == nil {
+				return strings.TrimRight(string(data), "\n")
+			}
+			if !os.IsNotExist(err) {
+				slog.Warn("failed to read banner", "file", name, "error", err)
+			}
+		}
+	}
+	return strings.TrimRight(value, "\n")
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestLoadBanner(t *testing.T) {
+	oldConfigDir := config.ConfigDir
+	config.ConfigDir = t.TempDir()
+	defer func() { config.ConfigDir = oldConfigDir }()
+
+	if err := os.MkdirAll(config.BannersDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(config.BannersDir(), "rocket.txt"), []byte(" /\\\n/__\\\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := loadBanner(config.BannerConfig{})
+	if b.logo != "" || b.hideLogo || b.title != "" || b.quoteText() != dashboardQuote {
+		t.Errorf("default banner = %+v", b)
+	}
+
+	quotes := []string{"Ship it", "Measure twice"}
+	b = loadBanner(config.BannerConfig{Logo: "rocket", Title: config.BannerNone, Quotes: quotes})
+	if b.logo != " /\\\n/__\\" {
+		t.Errorf("logo = %q, want the contents of rocket.txt", b.logo)
+	}
+	if !b.hideTitle {
+		t.Error("title not hidden by none")
+	}
+	if !slices.Contains(quotes, b.quoteText()) {
+		t.Errorf("quote = %q, want one of %v", b.quoteText(), quotes)
+	}
+
+	// Art that names no file is used as is
+	if got := bannerArt("SKITZ"); got != "SKITZ" {
+		t.Errorf("bannerArt(inline) = %q", got)
+	}
+	if got := bannerArt("../rocket"); got != "../rocket" {
+		t.Errorf("bannerArt(path) = %q, want it used as is", got)
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// branding: in config.yaml renames skitz for teams that run it as their
+// own command center: the name replaces SKITZ in the status bar and the
+// title art on the dashboard and heads the palette, the accent replaces
+// the purple used throughout, and the banner text replaces BIA under the
+// logo.
+
+// defaultBrandName is shown in the status bar without branding.name
+const defaultBrandName = "SKITZ"
+
+// defaultBannerText is shown under the logo without branding.banner
+const defaultBannerText = "BIA"
+
+// defaultAccent is the accent color without branding.accent
+const defaultAccent = lipgloss.Color("99")
+
+var hexColorRe = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// applyBranding sets the accent color. An accent that is neither an ANSI
+// 256 color nor a hex color is ignored.
+func applyBranding(cfg config.BrandingConfig) {
+	primary = defaultAccent
+	if cfg.Accent == "" {
+		return
+	}
+	if accent, ok := parseAccent(cfg.Accent); ok {
+		primary = accent
+		return
+	}
+	slog.Warn("ignoring invalid branding accent", "accent", cfg.Accent)
+}
+
+// parseAccent returns the color s names
+func parseAccent(s string) (lipgloss.Color, bool) {
+	s = strings.TrimSpace(s)
+	if hexColorRe.MatchString(s) {
+		return lipgloss.Color(s), true
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(s), true
+	}
+	return "", false
+}
+
+// brandName returns the name shown in the status bar, e.g. SKITZ
+func (m model) brandName() string {
+	if name := strings.TrimSpace(m.config.Branding.Name); name != "" {
+		return strings.ToUpper(name)
+	}
+	return defaultBrandName
+}
+
+// bannerBarText returns the text of the bar under the dashboard logo,
+// letter-spaced, e.g. "B I A"
+func (m model) bannerBarText() string {
+	text := strings.TrimSpace(m.config.Branding.Banner)
+	if text == "" {
+		text = defaultBannerText
+	}
+	return strings.Join(strings.Split(strings.ToUpper(text), ""), " ")
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestBranding(t *testing.T) {
+	defer applyBranding(config.BrandingConfig{})
+
+	var m model
+	if m.brandName() != "SKITZ" || m.bannerBarText() != "B I A" {
+		t.Errorf("defaults = %q, %q", m.brandName(), m.bannerBarText())
+	}
+
+	m.config.Branding = config.BrandingConfig{Name: "Acme Ops", Accent: "#ff6600", Banner: "acme"}
+	applyBranding(m.config.Branding)
+	if primary != "#ff6600" {
+		t.Errorf("primary = %q, want the accent", primary)
+	}
+	if m.brandName() != "ACME OPS" || m.bannerBarText() != "A C M E" {
+		t.Errorf("branded = %q, %q", m.brandName(), m.bannerBarText())
+	}
+	m.width = 200
+	if bar := ansi.Strip(m.renderStatusBar()); !strings.Contains(bar, "ACME OPS") || strings.Contains(bar, "SKITZ") {
+		t.Errorf("status bar = %q, want the brand name", bar)
+	}
+
+	// An invalid accent keeps the default
+	applyBranding(config.BrandingConfig{Accent: "orange"})
+	if primary != defaultAccent {
+		t.Errorf("primary = %q after an invalid accent", primary)
+	}
+	for _, s := range []string{"99", "0", "255", "#fff", "#A0b1C2"} {
+		if _, ok := parseAccent(s); !ok {
+			t.Errorf("parseAccent(%q) failed", s)
+		}
+	}
+	for _, s := range []string{"256", "-1", "#ff66", "purple"} {
+		if _, ok := parseAccent(s); ok {
+			t.Errorf("parseAccent(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// A command annotated ^requires:prod-access only runs for users who list
+// prod-access under capabilities in config.yaml, so shared resources can
+// mark the steps meant for SREs. Other users see the command dimmed, with
+// what it requires, and running it is refused.
+
+// errMissingCapability is returned for a command that requires a
+// capability the user hasn't declared
+var errMissingCapability = errors.New("missing capability")
+
+// missingCapabilities returns the capabilities in requires not in declared
+func missingCapabilities(requires, declared []string) []string {
+	var missing []string
+	for _, r := range requires {
+		found := false
+		for _, d := range declared {
+			if strings.EqualFold(r, d) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// checkCapabilities returns errMissingCapability, explaining what to
+// declare, when the user may not run a command that requires requires
+func checkCapabilities(requires, declared []string) error {
+	missing := missingCapabilities(requires, declared)
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w %s: add it to capabilities in config.yaml if you have it",
+		errMissingCapability, strings.Join(missing, ", "))
+}
+
+// commandLocked reports whether c requires capabilities the user lacks
+func (m model) commandLocked(c command) bool {
+	return len(missingCapabilities(c.requires, m.config.Capabilities)) > 0
+}
+
+// renderRequiresChip renders what a locked command requires
+func renderRequiresChip(c command, declared []string) string {
+	missing := missingCapabilities(c.requires, declared)
+	if len(missing) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render("🔒 requires " + strings.Join(missing, ", "))
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCapabilities(t *testing.T) {
+	cmds := parseCommands("`kubectl rollout restart deploy/api` Restart ^requires:prod-access ^run\n`kubectl get pods` Pods ^run\n")
+	if len(cmds) != 2 || len(cmds[0].requires) != 1 || cmds[0].requires[0] != "prod-access" || cmds[0].description != "Restart" {
+		t.Fatalf("parseCommands() = %+v", cmds)
+	}
+
+	if err := checkCapabilities(cmds[0].requires, []string{"Prod-Access"}); err != nil {
+		t.Errorf("declared capability refused: %v", err)
+	}
+	err := checkCapabilities(cmds[0].requires, nil)
+	if !errors.Is(err, errMissingCapability) || !strings.Contains(err.Error(), "prod-access") {
+		t.Errorf("err = %v, want the missing capability", err)
+	}
+
+	m := model{
+		currentView: viewDetail,
+		resources:   []resource{{name: "kubectl", sections: []section{{title: "Commands", content: "`kubectl rollout restart deploy/api` Restart ^requires:prod-access ^run\n"}}}},
+	}
+	m.contentView = viewport.New(120, 30)
+	m.updateViewportContent()
+
+	view := ansi.Strip(m.contentView.View())
+	if !strings.Contains(view, "requires prod-access") {
+		t.Errorf("locked command doesn't say what it requires:\n%s", view)
+	}
+	m.runSelectedCommand(false, false)
+	if m.term.active || len(m.notifications) != 1 || !strings.Contains(m.notifications[0].Message, "prod-access") {
+		t.Errorf("locked command ran, notifications = %+v", m.notifications)
+	}
+
+	m.config.Capabilities = []string{"prod-access"}
+	m.updateViewportContent()
+	if view := ansi.Strip(m.contentView.View()); strings.Contains(view, "requires prod-access") {
+		t.Errorf("unlocked command still marked:\n%s", view)
+	}
+}
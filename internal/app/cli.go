@@ -0,0 +1,689 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/audit"
+	"github.com/htelsiz/skitz/internal/cheatsheet"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/hooks"
+	"github.com/htelsiz/skitz/internal/logging"
+	mcppkg "github.com/htelsiz/skitz/internal/mcp"
+	"github.com/htelsiz/skitz/internal/recording"
+	"github.com/htelsiz/skitz/internal/secrets"
+	"github.com/htelsiz/skitz/internal/server"
+)
+
+// ErrUnknownCommand is returned by RunCLI for names that are not subcommands.
+var ErrUnknownCommand = errors.New("unknown command")
+
+// ErrLintFailed is returned by the lint subcommand when errors were found.
+var ErrLintFailed = errors.New("lint found issues")
+
+// CLIOptions controls how non-interactive subcommands write their output.
+type CLIOptions struct {
+	JSON bool
+	Out  io.Writer
+}
+
+type cliHandler func(args []string, opts CLIOptions) error
+
+var cliCommands = map[string]cliHandler{
+	"history":    cliHistory,
+	"grep":       cliGrep,
+	"lint":       cliLint,
+	"run":        cliRun,
+	"serve":      cliServe,
+	"daemon":     cliDaemon,
+	"config":     cliConfig,
+	"recordings": cliRecordings,
+	"export":     cliExport,
+	"doctor":     cliDoctor,
+	"shell-init": cliShellInit,
+}
+
+// IsCLICommand reports whether name is a non-interactive subcommand.
+func IsCLICommand(name string) bool {
+	_, ok := cliCommands[name]
+	return ok
+}
+
+// RunCLI executes a non-interactive subcommand. args[0] is the subcommand name.
+func RunCLI(args []string, opts CLIOptions) error {
+	if len(args) == 0 {
+		return ErrUnknownCommand
+	}
+	handler, ok := cliCommands[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+	}
+	if opts.Out == nil {
+		opts.Out = os.Stdout
+	}
+	return handler(args[1:], opts)
+}
+
+// newCLIFlagSet creates a flag set that also accepts --json after the
+// subcommand name, so `skitz history --json` and `skitz --json history` agree.
+func newCLIFlagSet(name string, opts *CLIOptions) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.BoolVar(&opts.JSON, "json", opts.JSON, "write machine-readable JSON output")
+	return fs
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// cliHistory prints command history.
+func cliHistory(args []string, opts CLIOptions) error {
+	fs := newCLIFlagSet("history", &opts)
+	limit := fs.Int("n", 0, "show only the N most recent entries")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	history := config.LoadHistory()
+	if *limit > 0 && len(history) > *limit {
+		history = history[:*limit]
+	}
+
+	if opts.JSON {
+		if history == nil {
+			history = []config.HistoryEntry{}
+		}
+		return writeJSON(opts.Out, history)
+	}
+
+	for _, h := range history {
+		status := "✓"
+		if !h.Success {
+			status = "✗"
+		}
+		fmt.Fprintf(opts.Out, "%s  %s  %-10s %s\n", h.Timestamp.Format("2006-01-02 15:04"), status, h.Tool, h.Command)
+	}
+	return nil
+}
+
+// cliMatch is the JSON schema for a grep result.
+type cliMatch struct {
+	Resource    string   `json:"resource"`
+	Section     string   `json:"section"`
+	Line        int      `json:"line"`
+	Command     string   `json:"command"`
+	Description string   `json:"description,omitempty"`
+	InputVar    string   `json:"input_var,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Requires    []string `json:"requires,omitempty"`
+}
+
+// findCommands returns runnable commands whose text or description contains
+// query and that carry every tag in tags.
+func findCommands(resources []resource, resourceName, query string, tags ...string) []cliMatch {
+	query = strings.ToLower(query)
+	matches := []cliMatch{}
+	for _, res := range resources {
+		if resourceName != "" && res.name != resourceName {
+			continue
+		}
+		for _, sec := range res.sections {
+		commands:
+			for _, c := range parseCommands(sec.content) {
+				for _, t := range tags {
+					if !commandHasTag(c, t) {
+						continue commands
+					}
+				}
+				if query != "" &&
+					!strings.Contains(strings.ToLower(c.raw), query) &&
+					!strings.Contains(strings.ToLower(c.description), query) {
+					continue
+				}
+				matches = append(matches, cliMatch{
+					Resource:    res.name,
+					Section:     sec.title,
+					Line:        c.lineNum,
+					Command:     c.raw,
+					Description: c.description,
+					InputVar:    c.inputVar,
+					Tags:        c.tags,
+					Requires:    c.requires,
+				})
+			}
+		}
+	}
+	return matches
+}
+
+// cliGrep searches runnable commands across all resources.
+func cliGrep(args []string, opts CLIOptions) error {
+	fs := newCLIFlagSet("grep", &opts)
+	resourceName := fs.String("r", "", "limit search to a single resource")
+	tag := fs.String("t", "", "only commands with this tag (comma-separated for several)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 && *tag == "" {
+		return errors.New("usage: skitz grep [-r resource] [-t tag] <pattern>")
+	}
+
+	var tags []string
+	if *tag != "" {
+		tags = strings.Split(*tag, ",")
+	}
+	matches := findCommands(loadAllResources(), *resourceName, strings.Join(fs.Args(), " "), tags...)
+
+	if opts.JSON {
+		return writeJSON(opts.Out, matches)
+	}
+	for _, mt := range matches {
+		line := fmt.Sprintf("%s:%d  %s", mt.Resource, mt.Line, mt.Command)
+		if mt.Description != "" {
+			line += "  — " + mt.Description
+		}
+		for _, t := range mt.Tags {
+			line += " #" + t
+		}
+		fmt.Fprintln(opts.Out, line)
+	}
+	return nil
+}
+
+// lintIssue is the JSON schema for a lint finding.
+type lintIssue struct {
+	Resource string `json:"resource"`
+	Section  string `json:"section"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+var placeholderRe = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+// lintResource checks command annotations in a single resource.
+func lintResource(res resource) []lintIssue {
+	var issues []lintIssue
+	seen := make(map[string]int)
+
+	for _, sec := range res.sections {
+		parsed := make(map[int]command)
+		for _, c := range parseCommands(sec.content) {
+			parsed[c.lineNum] = c
+		}
+
+		for i, line := range strings.Split(sec.content, "\n") {
+			lineNum := i + 1
+			if !strings.Contains(line, "^run") {
+				continue
+			}
+			issue := lintIssue{Resource: res.name, Section: sec.title, Line: lineNum}
+
+			c, ok := parsed[lineNum]
+			if !ok {
+				issue.Severity = "error"
+				issue.Message = "^run annotation without a `command` in backticks"
+				issues = append(issues, issue)
+				continue
+			}
+
+			if c.inputVar != "" && !strings.Contains(c.raw, "{{"+c.inputVar+"}}") {
+				issue.Severity = "error"
+				issue.Message = fmt.Sprintf("^run:%s but command has no {{%s}} placeholder", c.inputVar, c.inputVar)
+				issues = append(issues, issue)
+			}
+
+			for _, ph := range placeholderRe.FindAllStringSubmatch(c.raw, -1) {
+				if ph[1] != c.inputVar {
+					issue.Severity = "warning"
+					issue.Message = fmt.Sprintf("placeholder {{%s}} is never prompted for (use ^run:%s)", ph[1], ph[1])
+					issues = append(issues, issue)
+				}
+			}
+
+			if sec.title == "Commands" {
+				if prev, dup := seen[c.raw]; dup {
+					issue.Severity = "warning"
+					issue.Message = fmt.Sprintf("duplicate command (first seen on line %d)", prev)
+					issues = append(issues, issue)
+				} else {
+					seen[c.raw] = lineNum
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// cliLint validates command annotations in resources.
+func cliLint(args []string, opts CLIOptions) error {
+	fs := newCLIFlagSet("lint", &opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	issues := []lintIssue{}
+	for _, res := range loadAllResources() {
+		if fs.NArg() > 0 && res.name != fs.Arg(0) {
+			continue
+		}
+		issues = append(issues, lintResource(res)...)
+	}
+
+	if opts.JSON {
+		if err := writeJSON(opts.Out, issues); err != nil {
+			return err
+		}
+	} else {
+		for _, is := range issues {
+			fmt.Fprintf(opts.Out, "%s:%d: %s: %s\n", is.Resource, is.Line, is.Severity, is.Message)
+		}
+	}
+
+	for _, is := range issues {
+		if is.Severity == "error" {
+			return ErrLintFailed
+		}
+	}
+	return nil
+}
+
+// runResult is the JSON schema for the run subcommand.
+type runResult struct {
+	Command    string `json:"command"`
+	Resource   string `json:"resource"`
+	ExitCode   int    `json:"exit_code"`
+	Success    bool   `json:"success"`
+	DurationMS int64  `json:"duration_ms"`
+	Output     string `json:"output,omitempty"`
+}
+
+// cliRun executes a resource command without starting the TUI.
+// The command is selected by 1-based index or by substring match.
+func cliRun(args []string, opts CLIOptions) error {
+	fs := newCLIFlagSet("run", &opts)
+	input := fs.String("input", "", "value for the command's {{VAR}} placeholder")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return errors.New("usage: skitz run [--input value] <resource> <index|pattern>")
+	}
+
+	resName, selector := fs.Arg(0), strings.Join(fs.Args()[1:], " ")
+	matches := findCommands(loadAllResources(), resName, "")
+	if len(matches) == 0 {
+		return fmt.Errorf("no runnable commands in resource %q", resName)
+	}
+
+	var target *cliMatch
+	if n, err := strconv.Atoi(selector); err == nil {
+		if n < 1 || n > len(matches) {
+			return fmt.Errorf("command index %d out of range (1-%d)", n, len(matches))
+		}
+		target = &matches[n-1]
+	} else {
+		filtered := findCommands(loadAllResources(), resName, selector)
+		if len(filtered) == 0 {
+			return fmt.Errorf("no command in %q matches %q", resName, selector)
+		}
+		target = &filtered[0]
+	}
+
+	vars := config.LoadVariables()
+	values := make(map[string]string)
+	for _, name := range commandPlaceholders(target.Command) {
+		if v, ok := vars.Lookup(resName, name); ok {
+			values[name] = v
+		}
+	}
+	if target.InputVar != "" {
+		if *input != "" {
+			values[target.InputVar] = *input
+		}
+		if _, ok := values[target.InputVar]; !ok {
+			return fmt.Errorf("command requires --input for {{%s}}", target.InputVar)
+		}
+	}
+	cmdStr := expandActionParams(target.Command, values)
+
+	cfg := config.Load(mcppkg.GetDefaultMCPServerURL())
+	if err := cfg.Policy.CheckCommand(cmdStr); err != nil {
+		return err
+	}
+	if err := checkCapabilities(target.Requires, cfg.Capabilities); err != nil {
+		return err
+	}
+	env, secretValues, err := secrets.Env(context.Background(), cmdStr, cfg.Secrets)
+	if err != nil {
+		return err
+	}
+	shell := resolveShell(cfg.Execution, resName)
+	execTarget := cfg.Execution.Targets[resName]
+	cmdStr = wrapForTarget(secrets.Reference(cmdStr, referenceShell(cmdStr, shell, execTarget)), execTarget, envNames(env)...)
+	shell.env = append(shell.env, env...)
+
+	// Hooks run around the command like they do in the TUI; a failing
+	// pre-run hook stops it
+	ev := hooks.Event{Command: cmdStr, Resource: resName, Mode: "cli"}
+	hookEnv, err := hooks.RunPre(context.Background(), cfg.Hooks.PreRun, ev)
+	if err != nil {
+		return err
+	}
+	shell.env = append(shell.env, hookEnv...)
+	c := newShellCommand(shell, cmdStr)
+	var captured strings.Builder
+	if opts.JSON {
+		c.Stdout = &captured
+		c.Stderr = &captured
+	} else {
+		c.Stdout = opts.Out
+		c.Stderr = os.Stderr
+		c.Stdin = os.Stdin
+	}
+
+	configureAudit(cfg)
+	defer audit.Close()
+	start := time.Now()
+	runErr := c.Run()
+	result := runResult{
+		Command:    cmdStr,
+		Resource:   resName,
+		Success:    runErr == nil,
+		DurationMS: time.Since(start).Milliseconds(),
+		Output:     secrets.Redact(captured.String(), secretValues),
+	}
+	var exitErr *exec.ExitError
+	if errors.As(runErr, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	} else if runErr != nil {
+		result.ExitCode = -1
+	}
+	ev.ExitCode, ev.Duration = result.ExitCode, time.Since(start)
+	auditCommand(ev)
+	for _, err := range hooks.RunPost(context.Background(), cfg.Hooks.PostRun, ev) {
+		fmt.Fprintln(os.Stderr, "skitz:", err)
+	}
+
+	if cfg.History.Enabled && cfg.History.Persist {
+		history := config.AddToHistory(config.LoadHistory(), config.HistoryEntry{
+			Command:    cmdStr,
+			Tool:       resName,
+			Timestamp:  start,
+			Success:    result.Success,
+			ExitCode:   result.ExitCode,
+			DurationMS: result.DurationMS,
+		}, cfg.History.MaxItems)
+		config.SaveHistory(history, cfg.History.MaxItems)
+	}
+
+	if opts.JSON {
+		if err := writeJSON(opts.Out, result); err != nil {
+			return err
+		}
+	}
+	if runErr != nil {
+		return fmt.Errorf("command failed: %w", runErr)
+	}
+	return nil
+}
+
+// cliServe runs skitz headless until SIGINT or SIGTERM.
+func cliServe(args []string, opts CLIOptions) error {
+	fs := newCLIFlagSet("serve", &opts)
+	addr := fs.String("addr", server.DefaultAddr, "address to listen on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return server.New(*addr).Run(ctx)
+}
+
+// cliDaemon manages running serve mode as a user service.
+func cliDaemon(args []string, opts CLIOptions) error {
+	if len(args) == 0 || args[0] != "install" {
+		return errors.New("usage: skitz daemon install [--addr host:port]")
+	}
+
+	fs := newCLIFlagSet("daemon install", &opts)
+	addr := fs.String("addr", server.DefaultAddr, "address the service listens on")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	sf, err := server.InstallService(*addr, filepath.Join(logging.Dir(), "daemon.err"))
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		return writeJSON(opts.Out, sf)
+	}
+	fmt.Fprintf(opts.Out, "Wrote %s unit to %s\nEnable it with:\n  %s\n", sf.Kind, sf.Path, sf.Enable)
+	return nil
+}
+
+// cliConfig manages the config file. Only `migrate` is supported.
+func cliConfig(args []string, opts CLIOptions) error {
+	if len(args) == 0 || args[0] != "migrate" {
+		return errors.New("usage: skitz config migrate [--dry-run]")
+	}
+
+	fs := newCLIFlagSet("config migrate", &opts)
+	dryRun := fs.Bool("dry-run", false, "show the changes without writing the config")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	result, err := config.Migrate(mcppkg.GetDefaultMCPServerURL(), *dryRun)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		return writeJSON(opts.Out, result)
+	}
+	if len(result.Applied) == 0 {
+		fmt.Fprintf(opts.Out, "%s is up to date (version %d)\n", result.Path, result.To)
+		return nil
+	}
+
+	fmt.Fprintf(opts.Out, "%s: version %d → %d\n", result.Path, result.From, result.To)
+	for _, a := range result.Applied {
+		fmt.Fprintf(opts.Out, "  %s\n", a)
+	}
+	fmt.Fprintln(opts.Out)
+	for _, line := range diffLines(result.Before, result.After) {
+		fmt.Fprintln(opts.Out, line)
+	}
+	fmt.Fprintln(opts.Out)
+	if *dryRun {
+		fmt.Fprintln(opts.Out, "Dry run: nothing was written.")
+	} else {
+		fmt.Fprintf(opts.Out, "Wrote %s (backup: %s)\n", result.Path, result.Backup)
+	}
+	return nil
+}
+
+// cliRecording is the JSON schema for a recorded session.
+type cliRecording struct {
+	ID       string    `json:"id"`
+	Command  string    `json:"command"`
+	Resource string    `json:"resource,omitempty"`
+	Started  time.Time `json:"started"`
+	Seconds  float64   `json:"seconds"`
+	ExitCode *int      `json:"exit_code,omitempty"`
+}
+
+// cliRecordings lists recorded terminal sessions, or exports one as an
+// asciicast file.
+func cliRecordings(args []string, opts CLIOptions) error {
+	dir := recording.Dir(config.DataDir)
+	if len(args) > 0 && args[0] == "export" {
+		fs := newCLIFlagSet("recordings export", &opts)
+		idleLimit := fs.Duration("idle-limit", replayIdleLimit, "longest pause players show (0 keeps every pause)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 || fs.NArg() > 2 {
+			return errors.New("usage: skitz recordings export [--idle-limit 2s] <id> [file.cast]")
+		}
+
+		id := fs.Arg(0)
+		path := id + ".cast"
+		if fs.NArg() == 2 {
+			path = fs.Arg(1)
+		}
+		if path == "-" {
+			rec, err := recording.Load(filepath.Join(dir, id+recording.Ext))
+			if err != nil {
+				return err
+			}
+			return recording.WriteCast(opts.Out, rec, *idleLimit)
+		}
+		if err := recording.Export(dir, id, path, *idleLimit); err != nil {
+			return err
+		}
+		fmt.Fprintf(opts.Out, "Wrote %s\n", path)
+		return nil
+	}
+
+	fs := newCLIFlagSet("recordings", &opts)
+	limit := fs.Int("n", 0, "show only the N most recent recordings")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	recs, err := recording.List(dir)
+	if err != nil {
+		return err
+	}
+	if *limit > 0 && len(recs) > *limit {
+		recs = recs[:*limit]
+	}
+
+	if opts.JSON {
+		out := []cliRecording{}
+		for _, rec := range recs {
+			out = append(out, cliRecording{
+				ID:       rec.ID,
+				Command:  rec.Command,
+				Resource: rec.Resource,
+				Started:  rec.Started,
+				Seconds:  rec.Duration.Seconds(),
+				ExitCode: rec.ExitCode,
+			})
+		}
+		return writeJSON(opts.Out, out)
+	}
+
+	for _, rec := range recs {
+		fmt.Fprintf(opts.Out, "%s  %s  %-8s %s\n", rec.ID, recordingIcon(rec), rec.Duration.Round(time.Second), rec.Command)
+	}
+	return nil
+}
+
+func cliExport(args []string, opts CLIOptions) error {
+	fs := newCLIFlagSet("export", &opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 || fs.NArg() > 2 {
+		return errors.New("usage: skitz export <resource> [file.html|file.pdf]")
+	}
+
+	name := fs.Arg(0)
+	var sheet *cheatsheet.Sheet
+	for _, res := range loadAllResources() {
+		if strings.EqualFold(res.name, name) {
+			s := model{}.cheatSheet(&res)
+			sheet = &s
+			break
+		}
+	}
+	if sheet == nil {
+		return fmt.Errorf("unknown resource %q", name)
+	}
+
+	path := sheet.Title + ".html"
+	if fs.NArg() == 2 {
+		path = fs.Arg(1)
+	}
+	if path == "-" {
+		doc, err := cheatsheet.HTML(*sheet)
+		if err != nil {
+			return err
+		}
+		_, err = opts.Out.Write(doc)
+		return err
+	}
+	if err := cheatsheet.Write(context.Background(), *sheet, path); err != nil {
+		return err
+	}
+	fmt.Fprintf(opts.Out, "Wrote %s\n", path)
+	return nil
+}
+
+// diffLines returns a line diff of a and b with "  ", "- " and "+ "
+// prefixes, based on their longest common subsequence.
+func diffLines(a, b string) []string {
+	x := strings.Split(strings.TrimRight(a, "\n"), "\n")
+	y := strings.Split(strings.TrimRight(b, "\n"), "\n")
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []string
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, "  "+x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, "- "+x[i])
+			i++
+		default:
+			out = append(out, "+ "+y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		out = append(out, "- "+x[i])
+	}
+	for ; j < len(y); j++ {
+		out = append(out, "+ "+y[j])
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/hooks"
+)
+
+func TestLintResource(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantSeverity []string
+	}{
+		{
+			name:    "clean",
+			content: "`docker ps` list ^run\n`docker logs {{c}}` logs ^run:c\n",
+		},
+		{
+			name:         "missing backticks",
+			content:      "docker ps ^run\n",
+			wantSeverity: []string{"error"},
+		},
+		{
+			name:         "input var without placeholder",
+			content:      "`docker logs` logs ^run:c\n",
+			wantSeverity: []string{"error"},
+		},
+		{
+			name:         "unprompted placeholder",
+			content:      "`docker logs {{c}}` logs ^run\n",
+			wantSeverity: []string{"warning"},
+		},
+		{
+			name:         "duplicate command",
+			content:      "`git status` status ^run\n`git status` again ^run\n",
+			wantSeverity: []string{"warning"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resource{
+				name:     "test",
+				sections: []section{{title: "Commands", content: tt.content}},
+			}
+			issues := lintResource(res)
+			if len(issues) != len(tt.wantSeverity) {
+				t.Fatalf("lintResource() = %+v, want %d issues", issues, len(tt.wantSeverity))
+			}
+			for i, want := range tt.wantSeverity {
+				if issues[i].Severity != want {
+					t.Errorf("issue %d severity = %q, want %q", i, issues[i].Severity, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCLIRunHooks(t *testing.T) {
+	oldConfigDir, oldDataDir, oldResourcesDir := config.ConfigDir, config.DataDir, config.ResourcesDir
+	config.ConfigDir, config.DataDir, config.ResourcesDir = t.TempDir(), t.TempDir(), t.TempDir()
+	defer func() {
+		config.ConfigDir, config.DataDir, config.ResourcesDir = oldConfigDir, oldDataDir, oldResourcesDir
+	}()
+
+	dir := t.TempDir()
+	resource := "# hooktest\n\n## Commands\n\n`echo $GREETING > " + dir + "/ran` greet ^run\n"
+	if err := os.WriteFile(filepath.Join(config.ResourcesDir, "hooktest.md"), []byte(resource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig := func(hooks string) {
+		if err := os.WriteFile(filepath.Join(config.ConfigDir, "config.yaml"), []byte(fmt.Sprintf("version: %d\nhooks:\n%s", config.CurrentVersion, hooks)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var out bytes.Buffer
+
+	// A failing pre-run hook stops the command
+	writeConfig("  pre_run:\n    - name: guard\n      run: exit 3\n")
+	if err := cliRun([]string{"hooktest", "1"}, CLIOptions{Out: &out}); !errors.Is(err, hooks.ErrPreRunFailed) {
+		t.Errorf("cliRun() error = %v, want ErrPreRunFailed", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ran")); err == nil {
+		t.Error("command ran after its pre-run hook failed")
+	}
+
+	// Pre-run hooks export variables to the command; post-run hooks see
+	// how it went
+	writeConfig("  pre_run:\n    - run: echo GREETING=hi\n  post_run:\n    - run: echo $SKITZ_EXIT_CODE > " + dir + "/post\n")
+	if err := cliRun([]string{"hooktest", "1"}, CLIOptions{Out: &out}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "ran")); string(data) != "hi\n" {
+		t.Errorf("command output = %q, want the hook's variable", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "post")); string(data) != "0\n" {
+		t.Errorf("post-run hook saw exit code %q", data)
+	}
+}
+
+func TestFindCommandsTags(t *testing.T) {
+	resources := []resource{{name: "kubectl", sections: []section{{title: "Pods", content: "`kubectl get pods` list ^run ^tag:k8s ^tag:safe\n" +
+		"`kubectl delete pod {{pod}}` delete ^run:pod ^tag:k8s ^tag:dangerous\n"}}}}
+
+	matches := findCommands(resources, "", "", "K8S", "safe")
+	if len(matches) != 1 || matches[0].Command != "kubectl get pods" || fmt.Sprint(matches[0].Tags) != "[k8s safe]" {
+		t.Errorf("findCommands(#k8s #safe) = %+v", matches)
+	}
+	if matches := findCommands(resources, "", "delete", "k8s"); len(matches) != 1 || matches[0].InputVar != "pod" {
+		t.Errorf("findCommands(delete #k8s) = %+v", matches)
+	}
+	if matches := findCommands(resources, "", "", "prod"); len(matches) != 0 {
+		t.Errorf("findCommands(#prod) = %+v, want none", matches)
+	}
+}
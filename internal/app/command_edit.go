@@ -0,0 +1,270 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/resources"
+)
+
+// startCommandEdit opens the quick-edit form for the selected command
+func (m *model) startCommandEdit() tea.Cmd {
+	if len(m.commands) == 0 || m.cmdCursor >= len(m.commands) {
+		return nil
+	}
+
+	target := m.commands[m.cmdCursor]
+	m.cmdEditor = &CommandEditor{
+		Target:      target,
+		Command:     target.raw,
+		Description: target.description,
+	}
+	m.cmdEditor.Form = huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Command").
+				Validate(validateCommandText).
+				Value(&m.cmdEditor.Command),
+			huh.NewInput().
+				Title("Description").
+				Validate(func(s string) error {
+					if strings.Contains(s, "^") {
+						return errors.New("description cannot contain ^")
+					}
+					return nil
+				}).
+				Value(&m.cmdEditor.Description),
+		),
+	).
+		WithWidth(80).
+		WithShowHelp(true).
+		WithTheme(huh.ThemeCatppuccin())
+	return m.cmdEditor.Form.Init()
+}
+
+// validateCommandText rejects commands that would not parse back as a
+// ^run line
+func validateCommandText(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return errors.New("command cannot be empty")
+	}
+	if strings.Contains(s, "`") {
+		return errors.New("command cannot contain backticks")
+	}
+	return nil
+}
+
+// handleCommandEditorKeys routes keys to the quick-edit form
+func (m *model) handleCommandEditorKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" {
+		m.cmdEditor = nil
+		return m, nil
+	}
+
+	form, cmd := m.cmdEditor.Form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		m.cmdEditor.Form = f
+		if f.State == huh.StateCompleted {
+			return m, m.saveCommandEdit()
+		}
+	}
+	return m, cmd
+}
+
+// saveCommandEdit writes the edited command back to the line it came from
+// and reloads resources. Embedded resources are copied to the user
+// resources directory first.
+func (m *model) saveCommandEdit() tea.Cmd {
+	editor := m.cmdEditor
+	m.cmdEditor = nil
+
+	res := m.currentResource()
+	sec := m.currentSection()
+	if res == nil || sec == nil {
+		return m.showNotification("!", "No resource selected", "error")
+	}
+	if sec.file == "" || sec.dynamic != nil {
+		return m.showNotification("!", "Only commands written in a resource file can be edited", "error")
+	}
+
+	sectionLines := strings.Split(sec.content, "\n")
+	lineIdx := editor.Target.lineNum - 1
+	if lineIdx < 0 || lineIdx >= len(sectionLines) {
+		return m.showNotification("!", "Command line not found", "error")
+	}
+	oldLine := sectionLines[lineIdx]
+	newLine := formatCommandLine(oldLine, strings.TrimSpace(editor.Command), strings.TrimSpace(editor.Description))
+	if newLine == oldLine {
+		return nil
+	}
+
+	if err := os.MkdirAll(config.ResourcesDir, 0755); err != nil {
+		return m.showNotification("!", "Failed to create directory: "+err.Error(), "error")
+	}
+
+	files := resourceSourceFiles(res)
+	i := slices.IndexFunc(files, func(f resourceSourceFile) bool {
+		return filepath.Base(f.path) == sec.file
+	})
+	if i < 0 {
+		return m.showNotification("!", sec.file+" not found", "error")
+	}
+	updated, ok := replaceLine(files[i].content, sec.line+lineIdx, oldLine, newLine)
+	if !ok {
+		return m.showNotification("!", sec.file+" changed since it was loaded, reload and try again", "error")
+	}
+	if err := os.WriteFile(files[i].path, []byte(updated), 0644); err != nil {
+		return m.showNotification("!", "Failed to save: "+err.Error(), "error")
+	}
+
+	if res.embedded {
+		if err := copyMissingResourceFiles(files); err != nil {
+			return m.showNotification("!", "Failed to copy resource: "+err.Error(), "error")
+		}
+	}
+
+	cursor := m.cmdCursor
+	m.loadResources()
+	m.updateViewportContent()
+	if cursor < len(m.commands) {
+		m.cmdCursor = cursor
+		m.refreshCommandListDisplay()
+	}
+	return m.showNotification("✓", "Command updated", "success")
+}
+
+// resourceSourceFile is a resource file and its current content
+type resourceSourceFile struct {
+	path    string
+	content string
+}
+
+// resourceSourceFiles returns the main and detail files a resource was
+// loaded from, as paths in the user resources directory. Embedded
+// resources report their embedded content.
+func resourceSourceFiles(res *resource) []resourceSourceFile {
+	mainPath, mainContent := resourceFileContent(res)
+	files := []resourceSourceFile{{path: mainPath, content: mainContent}}
+
+	detailName := res.name + "-detail.md"
+	detailPath := filepath.Join(config.ResourcesDir, detailName)
+	if res.embedded {
+		if _, err := os.Stat(detailPath); err == nil {
+			return files
+		}
+		if data, err := resources.Default.ReadFile(detailName); err == nil {
+			files = append(files, resourceSourceFile{path: detailPath, content: string(data)})
+		}
+		return files
+	}
+	if data, err := os.ReadFile(detailPath); err == nil {
+		files = append(files, resourceSourceFile{path: detailPath, content: string(data)})
+	}
+	return files
+}
+
+// copyMissingResourceFiles writes the files that do not exist yet. A user
+// copy of an embedded resource's main file replaces it entirely, so its
+// detail file must be copied along with it.
+func copyMissingResourceFiles(files []resourceSourceFile) error {
+	for _, f := range files {
+		if _, err := os.Stat(f.path); !os.IsNotExist(err) {
+			continue
+		}
+		if err := os.WriteFile(f.path, []byte(f.content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatCommandLine rewrites a ^run line with a new command and
+// description, keeping its prefix (such as a list marker), input variable
+// and tags.
+func formatCommandLine(line, cmd, desc string) string {
+	open := strings.Index(line, "`")
+	if open < 0 {
+		return line
+	}
+	closing := strings.Index(line[open+1:], "`")
+	if closing < 0 {
+		return line
+	}
+	closing += open + 1
+	run := strings.Index(line[closing:], "^run")
+	if run < 0 {
+		return line
+	}
+	run += closing
+
+	// Keep tags, ^table, ^pager, ^onfail and ^requires written between
+	// the description and ^run
+	var tags []string
+	for _, t := range tagRe.FindAllString(line[closing+1:run], -1) {
+		tags = append(tags, strings.TrimSpace(t))
+	}
+	for _, t := range onFailRe.FindAllString(line[closing+1:run], -1) {
+		tags = append(tags, strings.TrimSpace(t))
+	}
+	for _, t := range requiresRe.FindAllString(line[closing+1:run], -1) {
+		tags = append(tags, strings.TrimSpace(t))
+	}
+	if tableRe.MatchString(line[closing+1 : run]) {
+		tags = append(tags, "^table")
+	}
+	if pagerRe.MatchString(line[closing+1 : run]) {
+		tags = append(tags, "^pager")
+	}
+
+	parts := []string{line[:open] + "`" + cmd + "`"}
+	if desc != "" {
+		parts = append(parts, desc)
+	}
+	parts = append(parts, tags...)
+	return strings.Join(parts, " ") + " " + line[run:]
+}
+
+// replaceLine replaces line idx (counting from zero) of content with
+// newLine, provided it still is oldLine.
+func replaceLine(content string, idx int, oldLine, newLine string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	if idx < 0 || idx >= len(lines) || lines[idx] != oldLine {
+		return content, false
+	}
+	lines[idx] = newLine
+	return strings.Join(lines, "\n"), true
+}
+
+// renderCommandEditor renders the quick-edit form in place of the command
+// list
+func (m model) renderCommandEditor(width int) string {
+	if m.cmdEditor == nil || m.cmdEditor.Form == nil {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("213")).
+		Bold(true)
+	lineStyle := lipgloss.NewStyle().
+		Foreground(subtle)
+
+	title := titleStyle.Render("Edit command")
+	original := lineStyle.Render("$ " + m.cmdEditor.Target.raw)
+
+	editorStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("99")).
+		Padding(1, 2).
+		Width(width - 10)
+
+	content := lipgloss.JoinVertical(lipgloss.Left, title, original, "", m.cmdEditor.Form.View())
+	return lipgloss.NewStyle().Padding(1, 2).Render(editorStyle.Render(content))
+}
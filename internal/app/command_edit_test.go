@@ -0,0 +1,171 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestFormatCommandLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		cmd  string
+		desc string
+		want string
+	}{
+		{
+			name: "command and description",
+			line: "`docker ps` List containers ^run",
+			cmd:  "docker ps -a",
+			desc: "List all containers",
+			want: "`docker ps -a` List all containers ^run",
+		},
+		{
+			name: "keeps list marker, input variable and trailing tags",
+			line: "- `docker logs {{name}}` Logs ^run:name ^tag:debug",
+			cmd:  "docker logs -f {{name}}",
+			desc: "Follow logs",
+			want: "- `docker logs -f {{name}}` Follow logs ^run:name ^tag:debug",
+		},
+		{
+			name: "keeps tags before ^run",
+			line: "`rm -rf build` Clean ^tag:danger ^run",
+			cmd:  "rm -rf build dist",
+			desc: "Clean outputs",
+			want: "`rm -rf build dist` Clean outputs ^tag:danger ^run",
+		},
+		{
+			name: "keeps ^table before ^run",
+			line: "`docker ps` Containers ^table ^run",
+			cmd:  "docker ps -a",
+			desc: "All containers",
+			want: "`docker ps -a` All containers ^table ^run",
+		},
+		{
+			name: "keeps ^pager before ^run",
+			line: "`journalctl -u nginx` Logs ^pager ^run",
+			cmd:  "journalctl -u nginx -n 1000",
+			desc: "Recent logs",
+			want: "`journalctl -u nginx -n 1000` Recent logs ^pager ^run",
+		},
+		{
+			name: "keeps ^onfail before ^run",
+			line: "`terraform apply` Apply ^onfail:`terraform plan` ^run",
+			cmd:  "terraform apply -auto-approve",
+			desc: "Apply",
+			want: "`terraform apply -auto-approve` Apply ^onfail:`terraform plan` ^run",
+		},
+		{
+			name: "keeps ^requires before ^run",
+			line: "`kubectl drain node-1` Drain ^requires:prod-access ^run",
+			cmd:  "kubectl drain node-1 --ignore-daemonsets",
+			desc: "Drain",
+			want: "`kubectl drain node-1 --ignore-daemonsets` Drain ^requires:prod-access ^run",
+		},
+		{
+			name: "empty description",
+			line: "`make` Build ^run",
+			cmd:  "make all",
+			want: "`make all` ^run",
+		},
+		{
+			name: "not a command line",
+			line: "plain text",
+			cmd:  "ls",
+			want: "plain text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatCommandLine(tt.line, tt.cmd, tt.desc); got != tt.want {
+				t.Errorf("formatCommandLine() = %q, want %q", got, tt.want)
+			}
+			// The rewritten line must parse back to the edited command
+			if tt.want != tt.line {
+				cmds := parseCommands(tt.want)
+				if len(cmds) != 1 || cmds[0].raw != tt.cmd || cmds[0].description != tt.desc {
+					t.Errorf("parseCommands(%q) = %+v", tt.want, cmds)
+				}
+			}
+		})
+	}
+}
+
+func TestReplaceLine(t *testing.T) {
+	content := "# A\n`ls` x ^run\n# B\n`ls` x ^run"
+
+	tests := []struct {
+		name   string
+		idx    int
+		old    string
+		want   string
+		wantOK bool
+	}{
+		{"first line", 1, "`ls` x ^run", "# A\nNEW\n# B\n`ls` x ^run", true},
+		{"repeated line", 3, "`ls` x ^run", "# A\n`ls` x ^run\n# B\nNEW", true},
+		{"line changed", 2, "`ls` x ^run", content, false},
+		{"past the end", 4, "`ls` x ^run", content, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := replaceLine(content, tt.idx, tt.old, "NEW")
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("replaceLine() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSaveCommandEdit(t *testing.T) {
+	oldResourcesDir := config.ResourcesDir
+	config.ResourcesDir = t.TempDir()
+	defer func() { config.ResourcesDir = oldResourcesDir }()
+
+	// The same line in the main file and in two detail sections
+	main := "---\ncategory: Test\n---\n# Tool\n\n`ls` List ^run\n"
+	detail := "## First\n\n`ls` List ^run\n\n## Second\n\n`ls` List ^run\n"
+	mainPath := filepath.Join(config.ResourcesDir, "tool.md")
+	detailPath := filepath.Join(config.ResourcesDir, "tool-detail.md")
+	os.WriteFile(mainPath, []byte(main), 0644)
+	os.WriteFile(detailPath, []byte(detail), 0644)
+
+	edit := func(title string) {
+		t.Helper()
+		m := model{width: 100, height: 40, contentView: viewport.New(100, 30)}
+		m.loadResources()
+		m.resCursor = slices.IndexFunc(m.resources, func(r resource) bool { return r.name == "tool" })
+		if m.resCursor < 0 {
+			t.Fatal("tool not loaded")
+		}
+		m.secCursor = slices.IndexFunc(m.resources[m.resCursor].sections, func(s section) bool { return s.title == title })
+		if m.secCursor < 0 {
+			t.Fatalf("no %s section", title)
+		}
+		m.commands = parseCommands(m.currentSection().content)
+		m.cmdEditor = &CommandEditor{Target: m.commands[0], Command: "ls -la", Description: title}
+		m.saveCommandEdit()
+	}
+
+	edit("Second")
+	want := "## First\n\n`ls` List ^run\n\n## Second\n\n`ls -la` Second ^run\n"
+	if got, _ := os.ReadFile(detailPath); string(got) != want {
+		t.Errorf("detail file = %q, want %q", got, want)
+	}
+	if got, _ := os.ReadFile(mainPath); string(got) != main {
+		t.Errorf("main file changed to %q", got)
+	}
+
+	edit("Commands")
+	want = "---\ncategory: Test\n---\n# Tool\n\n`ls -la` Commands ^run\n"
+	if got, _ := os.ReadFile(mainPath); string(got) != want {
+		t.Errorf("main file = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/resources"
+)
+
+// communitySectionTitle is the title of the section community examples are
+// shown in
+const communitySectionTitle = "Community"
+
+// communityExamplesMsg carries fetched tldr or cheat.sh examples. create is
+// set when they should become a new resource file.
+type communityExamplesMsg struct {
+	resource string
+	page     resources.Page
+	err      error
+	create   bool
+}
+
+func fetchCommunityExamplesCmd(tool string, create bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		page, err := resources.FetchExamples(ctx, tool)
+		return communityExamplesMsg{resource: tool, page: page, err: err, create: create}
+	}
+}
+
+// showCommunityExamples opens the Community section of the current
+// resource, fetching it on first use
+func (m *model) showCommunityExamples() tea.Cmd {
+	res := m.currentResource()
+	if res == nil {
+		return nil
+	}
+	for i, sec := range res.sections {
+		if sec.title == communitySectionTitle {
+			m.secCursor = i
+			m.cmdCursor = 0
+			m.updateViewportContent()
+			return nil
+		}
+	}
+	return tea.Batch(
+		m.showNotification("🌐", "Fetching community examples for "+res.name, "info"),
+		fetchCommunityExamplesCmd(res.name, false),
+	)
+}
+
+// handleCommunityExamples adds fetched examples as a section of their
+// resource, or writes them to a new resource file
+func (m *model) handleCommunityExamples(msg communityExamplesMsg) tea.Cmd {
+	if errors.Is(msg.err, resources.ErrNoExamples) {
+		return m.showNotification("⚠", "No tldr or cheat.sh examples for "+msg.resource, "warning")
+	}
+	if msg.err != nil {
+		return m.showNotification("!", msg.err.Error(), "error")
+	}
+
+	if msg.create {
+		if err := os.MkdirAll(config.ResourcesDir, 0755); err != nil {
+			return m.showNotification("!", "Failed to create directory: "+err.Error(), "error")
+		}
+		filePath := filepath.Join(config.ResourcesDir, msg.resource+".md")
+		if err := os.WriteFile(filePath, []byte(msg.page.Markdown()), 0644); err != nil {
+			return m.showNotification("!", "Failed to create file: "+err.Error(), "error")
+		}
+		m.loadResources()
+		return m.showNotification("", fmt.Sprintf("Created resource: %s (%d examples)", msg.resource, len(msg.page.Examples)), "success")
+	}
+
+	for i := range m.resources {
+		res := &m.resources[i]
+		if res.name != msg.resource {
+			continue
+		}
+		res.sections = append(res.sections, section{title: communitySectionTitle, content: msg.page.Markdown()})
+		if m.currentView == viewDetail && m.resCursor == i {
+			m.secCursor = len(res.sections) - 1
+			m.cmdCursor = 0
+			m.updateViewportContent()
+		}
+		break
+	}
+	return m.showNotification("🌐", fmt.Sprintf("%d examples from %s", len(msg.page.Examples), msg.page.Source), "success")
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Numbers typed on the dashboard and in the detail view are counts, as in
+// vim: 5j moves five rows, 12 Enter picks the twelfth item. A number that
+// nothing follows within countTimeout opens the card or section with that
+// number.
+
+// countTimeout is how long a number waits for another digit, a motion or
+// Enter before it is used on its own
+const countTimeout = 500 * time.Millisecond
+
+// countTimeoutMsg fires countTimeout after a digit; gen drops the timeouts
+// of digits that were followed by another key
+type countTimeoutMsg struct{ gen int }
+
+// typeCountDigit adds a typed digit to the count and reports whether msg
+// was one. 0 only counts after another digit.
+func (m *model) typeCountDigit(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		return nil, false
+	}
+	r := msg.Runes[0]
+	if r < '0' || r > '9' || (r == '0' && m.count == "") {
+		return nil, false
+	}
+
+	m.count += string(r)
+	m.countGen++
+	gen := m.countGen
+	return tea.Tick(countTimeout, func(time.Time) tea.Msg {
+		return countTimeoutMsg{gen: gen}
+	}), true
+}
+
+// takeCount returns the typed count, if any, and clears it
+func (m *model) takeCount() (int, bool) {
+	n, err := strconv.Atoi(m.count)
+	m.count = ""
+	return n, err == nil && n > 0
+}
+
+// handleCountTimeout uses a number nothing followed
+func (m *model) handleCountTimeout(msg countTimeoutMsg) tea.Cmd {
+	if msg.gen != m.countGen || m.count == "" {
+		return nil
+	}
+	return m.applyCount()
+}
+
+// applyCount opens the card, or in the detail view the section, with the
+// typed number
+func (m *model) applyCount() tea.Cmd {
+	n, ok := m.takeCount()
+	if !ok {
+		return nil
+	}
+	if m.currentView == viewDetail {
+		m.jumpToSection(n - 1)
+		return nil
+	}
+	return m.openDashboardNumber(n - 1)
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// typeKeys sends each key in turn and returns the last command
+func typeKeys(m *model, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		if k == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		_, cmd = m.handleKeyMsg(msg)
+	}
+	return cmd
+}
+
+func TestCountPrefix(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = orig }()
+
+	content := ""
+	for i := range 25 {
+		content += fmt.Sprintf("`echo %d` ^run\n", i+1)
+	}
+	m := &model{width: 120, height: 40, resources: []resource{{name: "big", sections: []section{
+		{title: "Commands", content: content}, {title: "Notes"}, {title: "More"},
+	}}}}
+	m.jumpToCommand(0, 0, 0)
+
+	tests := []struct {
+		keys   []string
+		cursor int
+	}{
+		{[]string{"5", "j"}, 5},
+		{[]string{"j"}, 6},
+		{[]string{"1", "2", "enter"}, 11},
+		{[]string{"3", "k"}, 8},
+		{[]string{"4", "0", "j"}, 24},
+		{[]string{"2", "0", "G"}, 19},
+	}
+	for _, tt := range tests {
+		typeKeys(m, tt.keys...)
+		if m.cmdCursor != tt.cursor {
+			t.Errorf("%v: cursor = %d, want %d", tt.keys, m.cmdCursor, tt.cursor)
+		}
+		if m.count != "" {
+			t.Errorf("%v: count %q left over", tt.keys, m.count)
+		}
+	}
+
+	// A number on its own jumps to that section once it times out
+	cmd := typeKeys(m, "2")
+	if cmd == nil || m.count != "2" {
+		t.Fatalf("count = %q, want it pending", m.count)
+	}
+	m.handleCountTimeout(countTimeoutMsg{gen: m.countGen - 1})
+	if m.secCursor != 0 {
+		t.Error("a stale timeout used the count")
+	}
+	m.handleCountTimeout(countTimeoutMsg{gen: m.countGen})
+	if m.secCursor != 1 || m.count != "" {
+		t.Errorf("section = %d, count = %q after the timeout", m.secCursor, m.count)
+	}
+}
+
+func TestDashboardNumbers(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = orig }()
+
+	newDashboard := func() *model {
+		m := &model{width: 120, height: 40}
+		for i := range 12 {
+			m.resources = append(m.resources, resource{name: fmt.Sprintf("res%02d", i+1), category: "Tools", sections: []section{{title: "Commands"}}})
+		}
+		return m
+	}
+
+	// A number opens the card once nothing follows it
+	m := newDashboard()
+	cmd := typeKeys(m, "1")
+	typeKeys(m, "2")
+	if cmd == nil || m.currentView != viewDashboard {
+		t.Fatal("12 opened a card before it timed out")
+	}
+	m.handleCountTimeout(countTimeoutMsg{gen: m.countGen})
+	if m.currentView != viewDetail || m.resCursor != 11 {
+		t.Errorf("12 opened %d", m.resCursor)
+	}
+
+	m = newDashboard()
+	typeKeys(m, "1", "0", "j")
+	if m.currentView != viewDashboard || m.resCursor != 10 {
+		t.Errorf("10j moved to %d", m.resCursor)
+	}
+	typeKeys(m, "1", "enter")
+	if m.currentView != viewDetail || m.resCursor != 0 {
+		t.Errorf("1 enter opened %d", m.resCursor)
+	}
+}
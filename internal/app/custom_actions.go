@@ -0,0 +1,187 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Custom action types; an empty type runs a shell command
+const (
+	customActionShell = "shell"
+	customActionSkitz = "skitz"
+)
+
+// pendingCustomAction is a custom or plugin action waiting for its
+// parameter form; Run is called with the submitted values
+type pendingCustomAction struct {
+	Name   string
+	Icon   string
+	Values map[string]*string
+	Run    func(m *model, values map[string]string) tea.Cmd
+}
+
+// getCustomActionItems returns the palette items for custom actions
+// defined in config
+func (m *model) getCustomActionItems() []PaletteItem {
+	if !m.config.QuickActions.Enabled {
+		return nil
+	}
+
+	var items []PaletteItem
+	for _, c := range m.config.QuickActions.Custom {
+		if c.Name == "" || c.Action.Command == "" {
+			continue
+		}
+		icon := c.Icon
+		if icon == "" {
+			icon = "⚙"
+		}
+		subtitle := c.Description
+		var commands []string
+		if c.Action.Type == customActionSkitz {
+			if subtitle == "" {
+				subtitle = "skitz action " + c.Action.Command
+			}
+		} else {
+			commands = []string{c.Action.Command}
+			if subtitle == "" {
+				subtitle = c.Action.Command
+			}
+		}
+
+		items = append(items, PaletteItem{
+			ID:       "custom:" + c.Name,
+			Icon:     icon,
+			Title:    c.Name,
+			Subtitle: subtitle,
+			Category: "action",
+			Commands: commands,
+			Handler: func(m *model) tea.Cmd {
+				return m.startCustomAction(c)
+			},
+		})
+	}
+	return items
+}
+
+// startCustomAction runs a custom action, asking for its parameters first
+// if it has any
+func (m *model) startCustomAction(c config.CustomActionConfig) tea.Cmd {
+	if len(c.Params) == 0 {
+		m.closePalette()
+		return m.runCustomAction(c, nil)
+	}
+
+	icon := c.Icon
+	if icon == "" {
+		icon = "⚙"
+	}
+	return m.collectActionParams(c.Name, icon, c.Params, func(m *model, values map[string]string) tea.Cmd {
+		return m.runCustomAction(c, values)
+	})
+}
+
+// collectActionParams opens the palette form for params and calls run with
+// the submitted values
+func (m *model) collectActionParams(name, icon string, params []config.ActionParam, run func(*model, map[string]string) tea.Cmd) tea.Cmd {
+	pending := &pendingCustomAction{Name: name, Icon: icon, Values: make(map[string]*string), Run: run}
+	fields := make([]huh.Field, 0, len(params))
+	for _, p := range params {
+		value := p.Default
+		pending.Values[p.Name] = &value
+		fields = append(fields, customActionField(p, &value))
+	}
+
+	m.palette.PendingAction = pending
+	m.palette.InputForm = huh.NewForm(huh.NewGroup(fields...)).
+		WithWidth(100).
+		WithShowHelp(true).
+		WithShowErrors(true).
+		WithTheme(huh.ThemeCatppuccin())
+	m.palette.State = PaletteStateCollectingParams
+	return m.palette.InputForm.Init()
+}
+
+// customActionField builds the form field for one parameter
+func customActionField(p config.ActionParam, value *string) huh.Field {
+	label := p.Title
+	if label == "" {
+		label = p.Name
+	}
+	title := label
+	if p.Required {
+		title += " *"
+	}
+
+	if len(p.Options) > 0 {
+		return huh.NewSelect[string]().
+			Title(title).
+			Options(huh.NewOptions(p.Options...)...).
+			Value(value)
+	}
+
+	input := huh.NewInput().
+		Title(title).
+		Placeholder(p.Placeholder).
+		Value(value)
+	if p.Required {
+		input = input.Validate(func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return fmt.Errorf("%s is required", label)
+			}
+			return nil
+		})
+	}
+	return input
+}
+
+// submitCustomAction runs the pending action with the form values
+func (m *model) submitCustomAction() tea.Cmd {
+	pending := m.palette.PendingAction
+	values := make(map[string]string, len(pending.Values))
+	for name, v := range pending.Values {
+		values[name] = strings.TrimSpace(*v)
+	}
+	m.closePalette()
+	return pending.Run(m, values)
+}
+
+// runCustomAction runs a shell command or a built-in skitz action
+func (m *model) runCustomAction(c config.CustomActionConfig, values map[string]string) tea.Cmd {
+	switch c.Action.Type {
+	case customActionSkitz:
+		b, ok := builtinActions[c.Action.Command]
+		if !ok {
+			return m.showNotification("⚠️", fmt.Sprintf("%s: unknown skitz action %q", c.Name, c.Action.Command), "warning")
+		}
+		cmd, _ := b.handler(m)
+		return cmd
+
+	case "", customActionShell:
+		command := expandActionParams(c.Action.Command, values)
+		mode := CommandEmbedded
+		if isInteractiveCommand(command) {
+			mode = CommandInteractive
+		}
+		return m.runCommand(CommandSpec{Command: command, Mode: mode})
+
+	default:
+		return m.showNotification("⚠️", fmt.Sprintf("%s: unknown action type %q", c.Name, c.Action.Type), "warning")
+	}
+}
+
+// expandActionParams replaces {{name}} placeholders with parameter values.
+// Placeholders without a parameter are left as they are.
+func expandActionParams(command string, values map[string]string) string {
+	return placeholderRe.ReplaceAllStringFunc(command, func(ph string) string {
+		if v, ok := values[placeholderRe.FindStringSubmatch(ph)[1]]; ok {
+			return v
+		}
+		return ph
+	})
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestExpandActionParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		values  map[string]string
+		want    string
+	}{
+		{"no params", "make test", nil, "make test"},
+		{"fills placeholders", "kubectl logs -f deploy/{{app}} -n {{ns}}", map[string]string{"app": "api", "ns": "prod"}, "kubectl logs -f deploy/api -n prod"},
+		{"repeated placeholder", "echo {{x}} {{x}}", map[string]string{"x": "hi"}, "echo hi hi"},
+		{"unknown placeholder is kept", "echo {{x}} {{y}}", map[string]string{"x": "hi"}, "echo hi {{y}}"},
+		{"empty value", "ls {{dir}}", map[string]string{"dir": ""}, "ls "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandActionParams(tt.command, tt.values); got != tt.want {
+				t.Errorf("expandActionParams() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomActionItems(t *testing.T) {
+	m := model{config: config.Config{QuickActions: config.QuickActionsConfig{
+		Enabled: true,
+		Custom: []config.CustomActionConfig{
+			{Name: "Deploy", Action: config.CustomAction{Command: "make deploy"}},
+			{Name: "Reload", Icon: "🔄", Description: "Reload resources", Action: config.CustomAction{Type: "skitz", Command: "refresh"}},
+			{Name: "Broken"},
+		},
+	}}}
+
+	items := m.getCustomActionItems()
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	if items[0].Icon != "⚙" || items[0].Subtitle != "make deploy" || len(items[0].Commands) != 1 {
+		t.Errorf("shell item = %+v", items[0])
+	}
+	if items[1].Subtitle != "Reload resources" || items[1].Commands != nil {
+		t.Errorf("skitz item = %+v", items[1])
+	}
+
+	m.config.QuickActions.Enabled = false
+	if items := m.getCustomActionItems(); items != nil {
+		t.Errorf("quick actions disabled, got %d items", len(items))
+	}
+}
+
+func TestStartCustomActionParams(t *testing.T) {
+	var m model
+	m.palette.State = PaletteStateSearching
+	m.startCustomAction(config.CustomActionConfig{
+		Name:   "Logs",
+		Action: config.CustomAction{Command: "kubectl logs {{pod}}"},
+		Params: []config.ActionParam{{Name: "pod", Default: "web-0", Required: true}},
+	})
+
+	if m.palette.State != PaletteStateCollectingParams || m.palette.InputForm == nil {
+		t.Fatalf("state = %v, form = %v", m.palette.State, m.palette.InputForm)
+	}
+	if got := *m.palette.PendingAction.Values["pod"]; got != "web-0" {
+		t.Errorf("default value = %q, want web-0", got)
+	}
+}
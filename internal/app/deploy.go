@@ -2,71 +2,49 @@ package app
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/yarlson/tap"
-)
-
-// AgentType represents the type of agent to deploy
-type AgentType string
 
-const (
-	AgentClaude AgentType = "claude"
-	AgentCursor AgentType = "cursor"
-	AgentCustom AgentType = "custom"
+	"github.com/htelsiz/skitz/internal/audit"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/deploy"
+	"github.com/htelsiz/skitz/internal/webhook"
 )
 
-// DeployMethod represents how to deploy the agent
-type DeployMethod string
+// deployAgentCmd implements tea.ExecCommand for interactive deployment
+type deployAgentCmd struct {
+	success bool
 
-const (
-	DeployACI      DeployMethod = "aci"
-	DeployPipeline DeployMethod = "pipeline"
-)
+	// azure starts as the remembered subscription and is updated when one
+	// is picked; azureChanged reports whether it should be saved.
+	azure        config.AzureDeployConfig
+	azureChanged bool
 
-// DeployConfig holds the configuration for agent deployment
-type DeployConfig struct {
-	AgentType     AgentType
-	DeployMethod  DeployMethod
-	AgentName     string
-	ResourceGroup string
-	Location      string
-	Prompt        string
-	AIAccount     string
-	AIEndpoint    string
-	AIDeployment  string
-	AIModel       string
-}
+	// deployment is the record of a successful deployment.
+	deployment *config.Deployment
 
-// AzureAIAccount represents an Azure AI Services account
-type AzureAIAccount struct {
-	Name          string
-	ResourceGroup string
-	Location      string
-	Endpoint      string
-	Kind          string
+	// finished summarizes the deployment for webhooks once one was
+	// attempted.
+	finished *webhook.Summary
 }
 
-// AzureAIDeployment represents a model deployment
-type AzureAIDeployment struct {
-	Name     string
-	Model    string
-	Version  string
-	SKU      string
-	Capacity int
+// deploymentRecordedMsg carries a new deployment to track in the
+// Deployments tab.
+type deploymentRecordedMsg struct {
+	deployment config.Deployment
 }
 
-// deployAgentCmd implements tea.ExecCommand for interactive deployment
-type deployAgentCmd struct {
-	success bool
+// deploySettingsMsg carries wizard choices to remember in config.
+type deploySettingsMsg struct {
+	azure config.AzureDeployConfig
 }
 
 func (c *deployAgentCmd) Run() error {
@@ -76,92 +54,177 @@ func (c *deployAgentCmd) Run() error {
 
 	tap.Intro("🚀 Deploy Agent")
 
-	if !checkAzureCLI() {
-		tap.Box("Azure CLI required. Install from:\nhttps://docs.microsoft.com/en-us/cli/azure/install-azure-cli", "Error", tap.BoxOptions{})
+	providerOptions := make([]tap.SelectOption[string], 0, len(deploy.All()))
+	for _, p := range deploy.All() {
+		hint := ""
+		if !p.Available() {
+			hint = "CLI not installed"
+		}
+		providerOptions = append(providerOptions, tap.SelectOption[string]{Value: p.Name(), Label: p.Label(), Hint: hint})
+	}
+	selectedProvider := tap.Select(ctx, tap.SelectOptions[string]{
+		Message: "Select cloud provider:",
+		Options: providerOptions,
+	})
+	if selectedProvider == "" {
+		tap.Cancel("Cancelled")
+		return nil
+	}
+	provider, err := deploy.Get(selectedProvider)
+	if err != nil {
+		tap.Box(err.Error(), "Error", tap.BoxOptions{})
+		waitForEnter()
+		return nil
+	}
+	if !provider.Available() {
+		tap.Box(provider.InstallHint(), "Error", tap.BoxOptions{})
 		waitForEnter()
 		return nil
 	}
 
 	spinner := tap.NewSpinner(tap.SpinnerOptions{})
-	spinner.Start("Loading Azure AI accounts...")
-	accounts := getAzureAIAccounts()
+	if sp, ok := provider.(deploy.SubscriptionProvider); ok {
+		spinner.Start("Loading subscriptions...")
+		subs, err := sp.Subscriptions(ctx)
+		spinner.Stop("", 0)
+
+		if err != nil {
+			tap.Box(err.Error(), "Error", tap.BoxOptions{})
+			waitForEnter()
+			return nil
+		}
+		if len(subs) == 0 {
+			tap.Box("No subscriptions found. Run az login first.", "No Subscriptions", tap.BoxOptions{})
+			waitForEnter()
+			return nil
+		}
+
+		sub, ok := c.selectSubscription(ctx, subs)
+		if !ok {
+			tap.Cancel("Cancelled")
+			return nil
+		}
+		provider = sp.WithSubscription(sub.ID)
+	}
+
+	spinner.Start(fmt.Sprintf("Loading %s accounts...", provider.Label()))
+	accounts, err := provider.Accounts(ctx)
 	spinner.Stop("", 0)
 
+	if err != nil {
+		tap.Box(err.Error(), "Error", tap.BoxOptions{})
+		waitForEnter()
+		return nil
+	}
 	if len(accounts) == 0 {
-		tap.Box("No Azure AI accounts found.\nCreate one at: https://ai.azure.com", "No AI Accounts", tap.BoxOptions{})
+		tap.Box(fmt.Sprintf("No %s accounts found.", provider.Label()), "No Accounts", tap.BoxOptions{})
 		waitForEnter()
 		return nil
 	}
 
-	accountOptions := make([]tap.SelectOption[string], len(accounts))
-	accountMap := make(map[string]AzureAIAccount)
-	for i, acc := range accounts {
-		accountOptions[i] = tap.SelectOption[string]{
-			Value: acc.Name,
-			Label: acc.Name,
-			Hint:  fmt.Sprintf("%s (%s)", acc.Kind, acc.Location),
+	account := accounts[0]
+	if len(accounts) > 1 {
+		accountOptions := make([]tap.SelectOption[string], len(accounts))
+		accountMap := make(map[string]deploy.Account)
+		for i, acc := range accounts {
+			accountOptions[i] = tap.SelectOption[string]{
+				Value: acc.Name,
+				Label: acc.Name,
+				Hint:  fmt.Sprintf("%s (%s)", acc.Kind, acc.Location),
+			}
+			accountMap[acc.Name] = acc
 		}
-		accountMap[acc.Name] = acc
-	}
-
-	selectedAccount := tap.Select(ctx, tap.SelectOptions[string]{
-		Message: "Select Azure AI account:",
-		Options: accountOptions,
-	})
-	if selectedAccount == "" {
-		tap.Cancel("Cancelled")
-		return nil
+		selectedAccount := tap.Select(ctx, tap.SelectOptions[string]{
+			Message: "Select account:",
+			Options: accountOptions,
+		})
+		if selectedAccount == "" {
+			tap.Cancel("Cancelled")
+			return nil
+		}
+		account = accountMap[selectedAccount]
 	}
-	account := accountMap[selectedAccount]
 
-	spinner.Start("Loading model deployments...")
-	deployments := getAzureAIDeployments(account.ResourceGroup, account.Name)
+	spinner.Start("Loading models...")
+	models, err := provider.Models(ctx, account)
 	spinner.Stop("", 0)
 
-	if len(deployments) == 0 {
-		tap.Box("No model deployments found in this account.\nDeploy a model at: https://ai.azure.com", "No Deployments", tap.BoxOptions{})
+	if err != nil {
+		tap.Box(err.Error(), "Error", tap.BoxOptions{})
+		waitForEnter()
+		return nil
+	}
+	if len(models) == 0 {
+		tap.Box(fmt.Sprintf("No models found in %s.", account.Name), "No Models", tap.BoxOptions{})
 		waitForEnter()
 		return nil
 	}
 
-	deploymentOptions := make([]tap.SelectOption[string], len(deployments))
-	deploymentMap := make(map[string]AzureAIDeployment)
-	for i, dep := range deployments {
-		hint := dep.Model
-		if dep.Version != "" {
-			hint += " v" + dep.Version
+	modelOptions := make([]tap.SelectOption[string], len(models))
+	modelMap := make(map[string]deploy.Model)
+	for i, mdl := range models {
+		hint := mdl.Vendor
+		if mdl.ID != mdl.Name {
+			hint = mdl.ID
+		}
+		if mdl.Version != "" {
+			hint += " v" + mdl.Version
 		}
-		deploymentOptions[i] = tap.SelectOption[string]{
-			Value: dep.Name,
-			Label: dep.Name,
+		modelOptions[i] = tap.SelectOption[string]{
+			Value: mdl.Name,
+			Label: mdl.Name,
 			Hint:  hint,
 		}
-		deploymentMap[dep.Name] = dep
+		modelMap[mdl.Name] = mdl
 	}
 
-	selectedDeployment := tap.Select(ctx, tap.SelectOptions[string]{
-		Message: "Select model deployment:",
-		Options: deploymentOptions,
+	selectedModel := tap.Select(ctx, tap.SelectOptions[string]{
+		Message: "Select model:",
+		Options: modelOptions,
 	})
-	if selectedDeployment == "" {
+	if selectedModel == "" {
 		tap.Cancel("Cancelled")
 		return nil
 	}
-	deployment := deploymentMap[selectedDeployment]
+	model := modelMap[selectedModel]
 
-	deployOptions := []tap.SelectOption[DeployMethod]{
-		{Value: DeployACI, Label: "Azure Container Instance", Hint: "Run once in a container"},
-		{Value: DeployPipeline, Label: "Azure Pipeline", Hint: "Run as CI/CD pipeline"},
+	methodOptions := make([]tap.SelectOption[string], 0, len(provider.Methods()))
+	for _, method := range provider.Methods() {
+		methodOptions = append(methodOptions, tap.SelectOption[string]{Value: method.ID, Label: method.Label, Hint: method.Hint})
 	}
 
-	deployMethod := tap.Select(ctx, tap.SelectOptions[DeployMethod]{
+	selectedMethod := tap.Select(ctx, tap.SelectOptions[string]{
 		Message: "How to run:",
-		Options: deployOptions,
+		Options: methodOptions,
 	})
-	if deployMethod == "" {
+	if selectedMethod == "" {
 		tap.Cancel("Cancelled")
 		return nil
 	}
+	method, err := deploy.FindMethod(provider, selectedMethod)
+	if err != nil {
+		tap.Box(err.Error(), "Error", tap.BoxOptions{})
+		waitForEnter()
+		return nil
+	}
+
+	values := make(map[string]string)
+	for _, field := range method.Fields {
+		opts := tap.TextOptions{
+			Message:      field.Label + ":",
+			Placeholder:  field.Placeholder,
+			DefaultValue: field.Default,
+		}
+		if field.Required {
+			opts.Validate = func(s string) error {
+				if strings.TrimSpace(s) == "" && field.Default == "" {
+					return fmt.Errorf("%s is required", field.Label)
+				}
+				return nil
+			}
+		}
+		values[field.Key] = tap.Text(ctx, opts)
+	}
 
 	prompt := tap.Text(ctx, tap.TextOptions{
 		Message:     "Task for the agent:",
@@ -172,36 +235,32 @@ func (c *deployAgentCmd) Run() error {
 		return nil
 	}
 
-	agentType := AgentCustom
-	modelLower := strings.ToLower(deployment.Model)
-	if strings.Contains(modelLower, "gpt") || strings.Contains(modelLower, "openai") {
-		agentType = AgentCursor
-	} else if strings.Contains(modelLower, "claude") {
-		agentType = AgentClaude
+	req := deploy.Request{
+		Name:    fmt.Sprintf("agent-%d", time.Now().Unix()),
+		Prompt:  prompt,
+		Account: account,
+		Model:   model,
+		Method:  method.ID,
+		Values:  values,
 	}
 
-	dconfig := DeployConfig{
-		AgentType:     agentType,
-		DeployMethod:  deployMethod,
-		AgentName:     fmt.Sprintf("agent-%d", time.Now().Unix()),
-		ResourceGroup: account.ResourceGroup,
-		Location:      account.Location,
-		Prompt:        prompt,
-		AIAccount:     account.Name,
-		AIEndpoint:    account.Endpoint,
-		AIDeployment:  deployment.Name,
-		AIModel:       deployment.Model,
+	scope := "default"
+	if _, ok := provider.(deploy.SubscriptionProvider); ok && c.azure.Subscription != "" {
+		scope = c.azure.Subscription
 	}
-
-	summaryText := fmt.Sprintf(`AI Account:  %s
-Model:       %s (%s)
+	summaryText := fmt.Sprintf(`Provider:    %s
+Scope:       %s
+Account:     %s (%s)
+Model:       %s
 Method:      %s
 Task:        %s`,
-		dconfig.AIAccount,
-		dconfig.AIDeployment,
-		dconfig.AIModel,
-		dconfig.DeployMethod,
-		truncate(dconfig.Prompt, 35),
+		provider.Label(),
+		scope,
+		account.Name,
+		account.Location,
+		model.Name,
+		method.Label,
+		truncate(prompt, 35),
 	)
 	tap.Box(summaryText, "Deployment Summary", tap.BoxOptions{})
 
@@ -213,122 +272,41 @@ Task:        %s`,
 		return nil
 	}
 
-	spinner.Start("Getting API key...")
-	apiKey := getAzureAIKey(dconfig.ResourceGroup, dconfig.AIAccount)
-	if apiKey == "" {
-		spinner.Stop("Failed to get API key", 1)
+	finished := webhook.Summary{
+		Event: webhook.EventDeploy,
+		Title: "Deploy " + req.Name,
+		Task:  prompt,
+		Details: []webhook.Detail{
+			{Name: "Provider", Value: provider.Label()},
+			{Name: "Account", Value: account.Name},
+			{Name: "Model", Value: model.Name},
+			{Name: "Method", Value: method.Label},
+		},
+	}
+	c.finished = &finished
+
+	spinner.Start("Deploying agent...")
+	started := time.Now()
+	result, err := provider.Deploy(ctx, req)
+	finished.Duration = time.Since(started)
+	auditCall(audit.KindDeploy, "deploy", provider.Label()+"/"+req.Name, started, err)
+	if err != nil {
+		finished.Status, finished.Output = "failed", err.Error()
+		spinner.Stop(err.Error(), 1)
 		waitForEnter()
 		return nil
 	}
-	spinner.Stop("Ready", 0)
-
-	switch dconfig.DeployMethod {
-	case DeployACI:
-		spinner.Start("Deploying container...")
-
-		image := "python:3.11-slim"
-		envVars := []string{
-			fmt.Sprintf("AZURE_OPENAI_ENDPOINT=%s", dconfig.AIEndpoint),
-			fmt.Sprintf("AZURE_OPENAI_API_KEY=%s", apiKey),
-			fmt.Sprintf("AZURE_OPENAI_DEPLOYMENT=%s", dconfig.AIDeployment),
-			fmt.Sprintf("AGENT_PROMPT=%s", dconfig.Prompt),
-		}
-
-		script := generateAzureAgentScript(dconfig)
+	finished.Success, finished.Status, finished.Output = true, "succeeded", result.Summary
+	spinner.Stop("Agent deployed!", 0)
+	tap.Box(result.Summary, "Deployed", tap.BoxOptions{})
+	c.deployment = &result.Deployment
 
-		args := []string{
-			"container", "create",
-			"--resource-group", dconfig.ResourceGroup,
-			"--name", dconfig.AgentName,
-			"--image", image,
-			"--restart-policy", "Never",
-			"--location", dconfig.Location,
-		}
-
-		for _, env := range envVars {
-			args = append(args, "--environment-variables", env)
-		}
-
-		args = append(args, "--command-line", script)
-
-		aciCmd := exec.Command("az", args...)
-		output, err := aciCmd.CombinedOutput()
-		if err != nil {
-			spinner.Stop("Deployment failed: "+string(output), 1)
-			return nil
-		}
-		spinner.Stop("Container deployed!", 0)
-
-		showLogs := tap.Confirm(ctx, tap.ConfirmOptions{
-			Message: "Stream container logs?",
-		})
-		if showLogs {
-			fmt.Println("\n--- Container Logs ---")
-			logsCmd := exec.Command("az", "container", "logs",
-				"--resource-group", dconfig.ResourceGroup,
-				"--name", dconfig.AgentName,
-				"--follow",
-			)
-			logsCmd.Stdout = os.Stdout
-			logsCmd.Stderr = os.Stderr
-			logsCmd.Run()
-		}
-
-	case DeployPipeline:
-		if !checkAzureDevOpsCLI() {
-			spinner.Stop("Azure DevOps CLI required", 1)
-			tap.Box("Install with: az extension add --name azure-devops", "Setup Required", tap.BoxOptions{})
-			waitForEnter()
-			return nil
-		}
-
-		orgURL := tap.Text(ctx, tap.TextOptions{
-			Message:     "Azure DevOps Org URL:",
-			Placeholder: "https://dev.azure.com/myorg",
-		})
-		project := tap.Text(ctx, tap.TextOptions{
-			Message:     "Project name:",
-			Placeholder: "MyProject",
-		})
-
-		spinner.Start("Creating pipeline run...")
-
-		tmpYAML := fmt.Sprintf(`trigger: none
-pool:
-  vmImage: ubuntu-latest
-steps:
-- script: |
-    pip install openai
-    python3 -c "
-    from openai import AzureOpenAI
-    client = AzureOpenAI(
-        azure_endpoint='%s',
-        api_key='%s',
-        api_version='2024-02-15-preview'
-    )
-    response = client.chat.completions.create(
-        model='%s',
-        messages=[{'role': 'user', 'content': '''%s'''}]
-    )
-    print(response.choices[0].message.content)
-    "
-  displayName: 'Run AI Agent'
-`, dconfig.AIEndpoint, apiKey, dconfig.AIDeployment, dconfig.Prompt)
-
-		tmpFile := filepath.Join(os.TempDir(), "agent-pipeline.yml")
-		os.WriteFile(tmpFile, []byte(tmpYAML), 0644)
-
-		runCmd := exec.Command("az", "pipelines", "run",
-			"--org", orgURL,
-			"--project", project,
-			"--name", dconfig.AgentName,
-		)
-		output, err := runCmd.CombinedOutput()
-		if err != nil {
-			spinner.Stop("Pipeline failed: "+string(output), 1)
-		} else {
-			spinner.Stop("Pipeline started!", 0)
-		}
+	if logs := provider.LogsCommand(result.Deployment); len(logs) > 0 && tap.Confirm(ctx, tap.ConfirmOptions{Message: "Show agent logs?"}) {
+		fmt.Println("\n--- Agent Logs ---")
+		logsCmd := exec.Command(logs[0], logs[1:]...)
+		logsCmd.Stdout = os.Stdout
+		logsCmd.Stderr = os.Stderr
+		logsCmd.Run()
 	}
 
 	tap.Outro("🎉 Agent deployed!")
@@ -340,257 +318,108 @@ steps:
 	return nil
 }
 
-func (c deployAgentCmd) SetStdin(r io.Reader)  {}
-func (c deployAgentCmd) SetStdout(w io.Writer) {}
-func (c deployAgentCmd) SetStderr(w io.Writer) {}
-
-// checkAzureCLI checks if Azure CLI is installed
-func checkAzureCLI() bool {
-	cmd := exec.Command("az", "--version")
-	return cmd.Run() == nil
-}
-
-// checkAzureDevOpsCLI checks if the Azure DevOps CLI extension is installed
-func checkAzureDevOpsCLI() bool {
-	cmd := exec.Command("az", "extension", "show", "--name", "azure-devops")
-	return cmd.Run() == nil
-}
-
-func waitForEnter() {
-	fmt.Print("\nPress Enter to continue...")
-	fmt.Scanln()
-}
-
-func getAzureAIAccounts() []AzureAIAccount {
-	cmd := exec.Command("az", "cognitiveservices", "account", "list",
-		"--query", "[?kind=='OpenAI' || kind=='CognitiveServices'].{name:name, resourceGroup:resourceGroup, location:location, endpoint:properties.endpoint, kind:kind}",
-		"-o", "json",
-	)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil
-	}
-
-	var accounts []AzureAIAccount
-	lines := strings.TrimSpace(string(output))
-	if lines == "" || lines == "[]" {
-		return nil
-	}
-
-	type jsonAccount struct {
-		Name          string `json:"name"`
-		ResourceGroup string `json:"resourceGroup"`
-		Location      string `json:"location"`
-		Endpoint      string `json:"endpoint"`
-		Kind          string `json:"kind"`
-	}
-	var jsonAccounts []jsonAccount
-
-	if err := parseJSON(output, &jsonAccounts); err != nil {
-		return nil
-	}
-
-	for _, ja := range jsonAccounts {
-		accounts = append(accounts, AzureAIAccount{
-			Name:          ja.Name,
-			ResourceGroup: ja.ResourceGroup,
-			Location:      ja.Location,
-			Endpoint:      ja.Endpoint,
-			Kind:          ja.Kind,
-		})
-	}
-
-	return accounts
-}
-
-func getAzureAIDeployments(resourceGroup, accountName string) []AzureAIDeployment {
-	cmd := exec.Command("az", "cognitiveservices", "account", "deployment", "list",
-		"--resource-group", resourceGroup,
-		"--name", accountName,
-		"--query", "[].{name:name, model:properties.model.name, version:properties.model.version, sku:sku.name, capacity:sku.capacity}",
-		"-o", "json",
-	)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil
+// selectSubscription asks for a tenant when subscriptions span more than
+// one, then for a subscription in it. The remembered choice is preselected,
+// falling back to the CLI's default subscription.
+func (c *deployAgentCmd) selectSubscription(ctx context.Context, subs []deploy.Subscription) (deploy.Subscription, bool) {
+	var tenants []string
+	tenantNames := make(map[string]string)
+	for _, sub := range subs {
+		if _, ok := tenantNames[sub.TenantID]; !ok {
+			tenants = append(tenants, sub.TenantID)
+		}
+		if sub.TenantName != "" || tenantNames[sub.TenantID] == "" {
+			tenantNames[sub.TenantID] = sub.TenantName
+		}
 	}
 
-	type jsonDeployment struct {
-		Name     string `json:"name"`
-		Model    string `json:"model"`
-		Version  string `json:"version"`
-		SKU      string `json:"sku"`
-		Capacity int    `json:"capacity"`
+	tenant := tenants[0]
+	if len(tenants) > 1 {
+		tenantOptions := make([]tap.SelectOption[string], len(tenants))
+		for i, id := range tenants {
+			label := tenantNames[id]
+			if label == "" {
+				label = id
+			}
+			tenantOptions[i] = tap.SelectOption[string]{Value: id, Label: label, Hint: id}
+		}
+		opts := tap.SelectOptions[string]{Message: "Select tenant:", Options: tenantOptions}
+		if slices.Contains(tenants, c.azure.Tenant) {
+			opts.InitialValue = &c.azure.Tenant
+		}
+		if tenant = tap.Select(ctx, opts); tenant == "" {
+			return deploy.Subscription{}, false
+		}
 	}
-	var jsonDeployments []jsonDeployment
 
-	if err := parseJSON(output, &jsonDeployments); err != nil {
-		return nil
+	var options []tap.SelectOption[string]
+	byID := make(map[string]deploy.Subscription)
+	initial := ""
+	for _, sub := range subs {
+		if sub.TenantID != tenant {
+			continue
+		}
+		hint := sub.ID
+		if sub.Default {
+			hint += " (current)"
+			if initial == "" {
+				initial = sub.ID
+			}
+		}
+		if sub.ID == c.azure.Subscription {
+			initial = sub.ID
+		}
+		options = append(options, tap.SelectOption[string]{Value: sub.ID, Label: sub.Name, Hint: hint})
+		byID[sub.ID] = sub
 	}
 
-	var deployments []AzureAIDeployment
-	for _, jd := range jsonDeployments {
-		deployments = append(deployments, AzureAIDeployment{
-			Name:     jd.Name,
-			Model:    jd.Model,
-			Version:  jd.Version,
-			SKU:      jd.SKU,
-			Capacity: jd.Capacity,
-		})
+	selected := options[0].Value
+	if len(options) > 1 {
+		opts := tap.SelectOptions[string]{Message: "Select subscription:", Options: options}
+		if initial != "" {
+			opts.InitialValue = &initial
+		}
+		if selected = tap.Select(ctx, opts); selected == "" {
+			return deploy.Subscription{}, false
+		}
 	}
 
-	return deployments
+	chosen := config.AzureDeployConfig{Tenant: tenant, Subscription: selected}
+	c.azureChanged = chosen != c.azure
+	c.azure = chosen
+	return byID[selected], true
 }
 
-func getAzureAIKey(resourceGroup, accountName string) string {
-	cmd := exec.Command("az", "cognitiveservices", "account", "keys", "list",
-		"--resource-group", resourceGroup,
-		"--name", accountName,
-		"--query", "key1",
-		"-o", "tsv",
-	)
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	return strings.TrimSpace(string(output))
-}
-
-func parseJSON(data []byte, v interface{}) error {
-	return json.Unmarshal(data, v)
-}
-
-func generateAzureAgentScript(dconfig DeployConfig) string {
-	return fmt.Sprintf(`/bin/sh -c 'pip install openai && python3 -c "
-from openai import AzureOpenAI
-import os
-
-client = AzureOpenAI(
-    azure_endpoint=os.environ[\"AZURE_OPENAI_ENDPOINT\"],
-    api_key=os.environ[\"AZURE_OPENAI_API_KEY\"],
-    api_version=\"2024-02-15-preview\"
-)
-
-response = client.chat.completions.create(
-    model=os.environ[\"AZURE_OPENAI_DEPLOYMENT\"],
-    messages=[{\"role\": \"user\", \"content\": \"\"\"%s\"\"\"}]
-)
+func (c deployAgentCmd) SetStdin(r io.Reader)  {}
+func (c deployAgentCmd) SetStdout(w io.Writer) {}
+func (c deployAgentCmd) SetStderr(w io.Writer) {}
 
-print(response.choices[0].message.content)
-"'`, strings.ReplaceAll(dconfig.Prompt, `"`, `\"`))
+func waitForEnter() {
+	fmt.Print("\nPress Enter to continue...")
+	fmt.Scanln()
 }
 
-func runDeployAgent() tea.Cmd {
-	dc := &deployAgentCmd{}
+func runDeployAgent(cfg config.DeployConfig) tea.Cmd {
+	dc := &deployAgentCmd{azure: cfg.Azure}
 	return tea.Exec(dc, func(err error) tea.Msg {
-		return commandDoneMsg{
-			command: "deploy-agent",
-			tool:    "skitz",
-			success: dc.success,
+		cmds := tea.BatchMsg{func() tea.Msg {
+			return commandDoneMsg{
+				command: "deploy-agent",
+				tool:    "skitz",
+				success: dc.success,
+			}
+		}}
+		if dc.azureChanged {
+			cmds = append(cmds, func() tea.Msg { return deploySettingsMsg{azure: dc.azure} })
 		}
+		if dc.deployment != nil {
+			d := *dc.deployment
+			cmds = append(cmds, func() tea.Msg { return deploymentRecordedMsg{deployment: d} })
+		}
+		if dc.finished != nil {
+			s := *dc.finished
+			cmds = append(cmds, func() tea.Msg { return deployFinishedMsg{summary: s} })
+		}
+		return cmds
 	})
 }
-
-func deployToACIFromPalette(dconfig DeployConfig, apiKey string) (string, error) {
-	image := "python:3.11-slim"
-	envVars := []string{
-		fmt.Sprintf("AZURE_OPENAI_ENDPOINT=%s", dconfig.AIEndpoint),
-		fmt.Sprintf("AZURE_OPENAI_API_KEY=%s", apiKey),
-		fmt.Sprintf("AZURE_OPENAI_DEPLOYMENT=%s", dconfig.AIDeployment),
-		fmt.Sprintf("AGENT_PROMPT=%s", dconfig.Prompt),
-	}
-
-	script := generateAzureAgentScript(dconfig)
-
-	args := []string{
-		"container", "create",
-		"--resource-group", dconfig.ResourceGroup,
-		"--name", dconfig.AgentName,
-		"--image", image,
-		"--restart-policy", "Never",
-		"--location", dconfig.Location,
-	}
-
-	for _, env := range envVars {
-		args = append(args, "--environment-variables", env)
-	}
-
-	args = append(args, "--command-line", script)
-
-	aciCmd := exec.Command("az", args...)
-	output, err := aciCmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("deployment failed: %s", string(output))
-	}
-
-	result := fmt.Sprintf("Container '%s' deployed successfully!\n\nResource Group: %s\nLocation: %s\nModel: %s\n\nTo view logs:\naz container logs --resource-group %s --name %s --follow",
-		dconfig.AgentName,
-		dconfig.ResourceGroup,
-		dconfig.Location,
-		dconfig.AIModel,
-		dconfig.ResourceGroup,
-		dconfig.AgentName,
-	)
-
-	return result, nil
-}
-
-func deployToPipelineFromPalette(dconfig DeployConfig, apiKey string) (string, error) {
-	if !checkAzureDevOpsCLI() {
-		return "", fmt.Errorf("Azure DevOps CLI extension is required.\n\nInstall with: az extension add --name azure-devops")
-	}
-
-	pipelineYAML := fmt.Sprintf(`trigger: none
-pool:
-  vmImage: ubuntu-latest
-steps:
-- script: |
-    pip install openai
-    python3 -c "
-    from openai import AzureOpenAI
-    client = AzureOpenAI(
-        azure_endpoint='%s',
-        api_key='%s',
-        api_version='2024-02-15-preview'
-    )
-    response = client.chat.completions.create(
-        model='%s',
-        messages=[{'role': 'user', 'content': '''%s'''}]
-    )
-    print(response.choices[0].message.content)
-    "
-  displayName: 'Run AI Agent'
-`, dconfig.AIEndpoint, apiKey, dconfig.AIDeployment, strings.ReplaceAll(dconfig.Prompt, "'", "'\\''"))
-
-	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("agent-pipeline-%s.yml", dconfig.AgentName))
-	if err := os.WriteFile(tmpFile, []byte(pipelineYAML), 0644); err != nil {
-		return "", fmt.Errorf("failed to create pipeline YAML: %v", err)
-	}
-
-	result := fmt.Sprintf("Pipeline YAML created: %s\n\nTo deploy this pipeline:\n\n1. Push this YAML to your Azure DevOps repository\n2. Create a new pipeline in Azure DevOps using this YAML\n3. Set up the required service connection for Azure\n\nPipeline configuration:\n- Model: %s\n- Deployment: %s\n- Task: %s",
-		tmpFile,
-		dconfig.AIModel,
-		dconfig.AIDeployment,
-		truncate(dconfig.Prompt, 60),
-	)
-
-	return result, nil
-}
-
-func azureAIAccountsTableCommand() string {
-	return `az cognitiveservices account list --query "[?kind=='OpenAI' || kind=='CognitiveServices'].{name:name, resourceGroup:resourceGroup, location:location, kind:kind}" -o table`
-}
-
-func azureAIDeploymentsTableCommand(resourceGroup, accountName string) string {
-	return fmt.Sprintf(`az cognitiveservices account deployment list --resource-group %s --name %s --query "[].{name:name, model:properties.model.name, version:properties.model.version, sku:sku.name, capacity:sku.capacity}" -o table`,
-		shellEscape(resourceGroup),
-		shellEscape(accountName),
-	)
-}
-
-func shellEscape(value string) string {
-	if value == "" {
-		return "''"
-	}
-	return "'" + strings.ReplaceAll(value, "'", "'\\''") + "'"
-}
@@ -0,0 +1,313 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/audit"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/deploy"
+	runtimepkg "github.com/htelsiz/skitz/internal/runtime"
+)
+
+// deploymentsTab is the dashboard tab index of the Deployments tab
+const deploymentsTab = 3
+
+// deploymentRefreshInterval is how often statuses are polled while the
+// Deployments tab is visible
+const deploymentRefreshInterval = 15 * time.Second
+
+// deploymentStatus is the last status fetched for a deployment. busy is set
+// while a restart or teardown is in flight.
+type deploymentStatus struct {
+	status  deploy.Status
+	err     error
+	checked time.Time
+	busy    string
+}
+
+// deploymentStatusMsg carries a fetched deployment status
+type deploymentStatusMsg struct {
+	key    string
+	status deploy.Status
+	err    error
+}
+
+// deploymentTickMsg triggers a status refresh of the Deployments tab
+type deploymentTickMsg struct{}
+
+// deploymentActionMsg reports a finished restart or teardown
+type deploymentActionMsg struct {
+	action     string // "restart" or "teardown"
+	key        string
+	deployment config.Deployment
+	err        error
+}
+
+// deploymentKey identifies a deployment across status updates
+func deploymentKey(d config.Deployment) string {
+	return d.Provider + "/" + d.Name
+}
+
+// selectedDeployment returns the deployment under the cursor
+func (m *model) selectedDeployment() (config.Deployment, bool) {
+	if m.deployCursor < 0 || m.deployCursor >= len(m.deployments) {
+		return config.Deployment{}, false
+	}
+	return m.deployments[m.deployCursor], true
+}
+
+// fetchDeploymentStatusCmd fetches the live status of one deployment
+func fetchDeploymentStatusCmd(d config.Deployment) tea.Cmd {
+	key := deploymentKey(d)
+	return func() tea.Msg {
+		p, err := deploy.ForDeployment(d)
+		if err != nil {
+			return deploymentStatusMsg{key: key, err: err}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		status, err := p.Status(ctx, d)
+		return deploymentStatusMsg{key: key, status: status, err: err}
+	}
+}
+
+// refreshDeployments fetches the status of every deployment that is not
+// known to be deleted
+func (m *model) refreshDeployments() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, d := range m.deployments {
+		if st, ok := m.deployStatus[deploymentKey(d)]; ok && st.status.State == deploy.StateDeleted {
+			continue
+		}
+		cmds = append(cmds, fetchDeploymentStatusCmd(d))
+	}
+	return tea.Batch(cmds...)
+}
+
+// startDeploymentPolling refreshes statuses now and schedules the next
+// refresh, unless polling is already running
+func (m *model) startDeploymentPolling() tea.Cmd {
+	if m.deployPolling || len(m.deployments) == 0 {
+		return nil
+	}
+	m.deployPolling = true
+	return tea.Batch(m.refreshDeployments(), scheduleDeploymentRefreshCmd())
+}
+
+func scheduleDeploymentRefreshCmd() tea.Cmd {
+	return tea.Tick(deploymentRefreshInterval, func(time.Time) tea.Msg {
+		return deploymentTickMsg{}
+	})
+}
+
+// handleDeploymentTick polls again while the Deployments tab is visible and
+// the terminal is focused; otherwise polling stops until the tab is shown
+func (m *model) handleDeploymentTick() tea.Cmd {
+	if m.unfocused || m.currentView != viewDashboard || m.dashboardTab != deploymentsTab {
+		m.deployPolling = false
+		return nil
+	}
+	return tea.Batch(m.refreshDeployments(), scheduleDeploymentRefreshCmd())
+}
+
+// showDeploymentLogs runs the selected deployment's logs command in the
+// terminal
+func (m *model) showDeploymentLogs() tea.Cmd {
+	d, ok := m.selectedDeployment()
+	if !ok {
+		return nil
+	}
+	p, err := deploy.ForDeployment(d)
+	if err != nil {
+		return m.showNotification("⚠", err.Error(), "error")
+	}
+	logs := p.LogsCommand(d)
+	if len(logs) == 0 {
+		return m.showNotification("📜", fmt.Sprintf("No logs available for %s", d.Name), "info")
+	}
+
+	quoted := make([]string, len(logs))
+	for i, arg := range logs {
+		quoted[i] = runtimepkg.Quote(arg)
+	}
+	return m.runCommand(CommandSpec{Command: strings.Join(quoted, " "), Mode: CommandInteractive})
+}
+
+// restartDeployment runs the selected deployment again
+func (m *model) restartDeployment() tea.Cmd {
+	d, ok := m.selectedDeployment()
+	if !ok {
+		return nil
+	}
+	return m.runDeploymentAction("restart", d, func(ctx context.Context, p deploy.Provider) (config.Deployment, error) {
+		return p.Restart(ctx, d)
+	})
+}
+
+// teardownDeployment deletes the selected deployment's cloud resources. The
+// first press asks for confirmation; a second press on the same deployment
+// tears it down.
+func (m *model) teardownDeployment() tea.Cmd {
+	d, ok := m.selectedDeployment()
+	if !ok {
+		return nil
+	}
+	key := deploymentKey(d)
+	if m.confirmTeardown != key {
+		m.confirmTeardown = key
+		return m.showNotification("⚠", fmt.Sprintf("Press x again to tear down %s", d.Name), "warning")
+	}
+	m.confirmTeardown = ""
+	return m.runDeploymentAction("teardown", d, func(ctx context.Context, p deploy.Provider) (config.Deployment, error) {
+		return d, p.Teardown(ctx, d)
+	})
+}
+
+// runDeploymentAction marks the deployment busy and runs fn in the
+// background
+func (m *model) runDeploymentAction(action string, d config.Deployment, fn func(context.Context, deploy.Provider) (config.Deployment, error)) tea.Cmd {
+	key := deploymentKey(d)
+	st := m.deployStatus[key]
+	if st.busy != "" {
+		return nil
+	}
+	st.busy = action
+	m.deployStatus[key] = st
+
+	return func() tea.Msg {
+		p, err := deploy.ForDeployment(d)
+		if err != nil {
+			return deploymentActionMsg{action: action, key: key, deployment: d, err: err}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		started := time.Now()
+		updated, err := fn(ctx, p)
+		auditCall(audit.KindDeploy, action, d.Provider+"/"+d.Name, started, err)
+		return deploymentActionMsg{action: action, key: key, deployment: updated, err: err}
+	}
+}
+
+// handleDeploymentAction records the result of a restart or teardown
+func (m *model) handleDeploymentAction(msg deploymentActionMsg) tea.Cmd {
+	st := m.deployStatus[msg.key]
+	st.busy = ""
+	m.deployStatus[msg.key] = st
+
+	if msg.err != nil {
+		return m.showNotification("⚠", fmt.Sprintf("%s failed: %v", msg.action, msg.err), "error")
+	}
+
+	switch msg.action {
+	case "teardown":
+		m.deployments = config.RemoveDeployment(m.deployments, msg.deployment.Provider, msg.deployment.Name)
+		delete(m.deployStatus, msg.key)
+		if m.deployCursor >= len(m.deployments) {
+			m.deployCursor = max(len(m.deployments)-1, 0)
+		}
+		config.SaveDeployments(m.deployments)
+		return m.showNotification("🗑", fmt.Sprintf("Tore down %s", msg.deployment.Name), "success")
+	default:
+		m.deployments = config.UpsertDeployment(m.deployments, msg.deployment)
+		config.SaveDeployments(m.deployments)
+		return tea.Batch(
+			m.showNotification("🔄", fmt.Sprintf("Restarted %s", msg.deployment.Name), "success"),
+			fetchDeploymentStatusCmd(msg.deployment),
+		)
+	}
+}
+
+// deploymentStatusLabel returns the card tag and color for a deployment
+func deploymentStatusLabel(st deploymentStatus, known bool) (string, lipgloss.Color) {
+	switch {
+	case st.busy == "restart":
+		return "RESTARTING", lipgloss.Color("220")
+	case st.busy == "teardown":
+		return "TEARING DOWN", lipgloss.Color("208")
+	case !known:
+		return "…", lipgloss.Color("243")
+	case st.err != nil:
+		return "ERROR", lipgloss.Color("196")
+	}
+
+	switch st.status.State {
+	case deploy.StateRunning:
+		return "RUNNING", lipgloss.Color("220")
+	case deploy.StatePending:
+		return "PENDING", lipgloss.Color("39")
+	case deploy.StateSucceeded:
+		return "SUCCEEDED", lipgloss.Color("114")
+	case deploy.StateFailed:
+		return "FAILED", lipgloss.Color("196")
+	case deploy.StateStopped:
+		return "STOPPED", lipgloss.Color("208")
+	case deploy.StateDeleted:
+		return "DELETED", lipgloss.Color("243")
+	}
+	return "UNKNOWN", lipgloss.Color("243")
+}
+
+// renderDeploymentsTab renders recorded deployments with their live status
+func (m model) renderDeploymentsTab(width, height int) string {
+	infoStyle := lipgloss.NewStyle().
+		Foreground(subtle).
+		Italic(true)
+
+	if len(m.deployments) == 0 {
+		info := infoStyle.Render("No deployments yet. Use Deploy Agent from the palette (ctrl+k) to start one.")
+		return lipgloss.NewStyle().Padding(0, 2).Render(info)
+	}
+
+	var items []CardItem
+	for i, d := range m.deployments {
+		st, known := m.deployStatus[deploymentKey(d)]
+		tag, tagColor := deploymentStatusLabel(st, known)
+
+		scope := d.ResourceGroup
+		if scope == "" {
+			scope = d.Account
+		}
+		items = append(items, CardItem{
+			Title:       d.Name,
+			Subtitle:    fmt.Sprintf("%s · %s · %s · %s", d.Provider, d.Method, scope, formatTimeAgo(d.Timestamp)),
+			Tag:         tag,
+			TagColor:    tagColor,
+			BorderColor: dimBorder,
+			Shortcut:    i + 1,
+		})
+	}
+
+	sections := []string{CardGrid(items, width, m.deployCursor)}
+
+	// Detail line for the selected deployment
+	if d, ok := m.selectedDeployment(); ok {
+		labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
+		valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+
+		details := []string{labelStyle.Render("Model: ") + valueStyle.Render(d.Model)}
+		if d.Endpoint != "" {
+			details = append(details, labelStyle.Render("Endpoint: ")+valueStyle.Render(d.Endpoint))
+		}
+		st := m.deployStatus[deploymentKey(d)]
+		if st.err != nil {
+			details = append(details, labelStyle.Render("Error: ")+valueStyle.Render(st.err.Error()))
+		} else if st.status.Detail != "" {
+			details = append(details, labelStyle.Render("Status: ")+valueStyle.Render(st.status.Detail))
+		}
+		if !st.checked.IsZero() {
+			details = append(details, labelStyle.Render("Checked: ")+valueStyle.Render(formatTimeAgo(st.checked)))
+		}
+		sections = append(sections, "", strings.Join(details, "   "))
+	}
+
+	info := infoStyle.Render("enter/l logs, r restart, x tear down, s refresh")
+	content := lipgloss.JoinVertical(lipgloss.Left, append(sections, "", info)...)
+
+	return lipgloss.NewStyle().Padding(0, 2).Render(content)
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/notify"
+)
+
+// agentsTab is the dashboard tab index of the Agents tab
+const agentsTab = 2
+
+// notifyDesktop sends a desktop notification for a finished run when
+// desktop notifications are enabled, the run took at least the configured
+// threshold and the user was not watching it
+func (m *model) notifyDesktop(title, body string, took time.Duration, watching bool) tea.Cmd {
+	cfg := m.config.Notifications
+	if !cfg.Desktop || watching || took < cfg.Threshold() {
+		return nil
+	}
+	title = fmt.Sprintf("%s (%s)", title, took.Round(time.Second))
+	return func() tea.Msg {
+		if err := notify.Send(title, body); err != nil {
+			slog.Warn("desktop notification failed", "error", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,301 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/creack/pty"
+
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/azure"
+	"github.com/htelsiz/skitz/internal/config"
+	mcppkg "github.com/htelsiz/skitz/internal/mcp"
+)
+
+// ErrDoctorFailed is returned by the doctor subcommand when a check failed.
+var ErrDoctorFailed = errors.New("doctor found problems")
+
+// Results of a doctor check. Missing optional tools warn; a broken terminal
+// and configured MCP servers or AI providers that don't answer fail.
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// doctorTimeout bounds each check that runs a command or uses the network
+const doctorTimeout = 20 * time.Second
+
+// doctorCheck is the JSON schema for one result of the doctor subcommand.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"` // how to fix a warning or failure
+}
+
+// runDoctor checks what skitz relies on, all at once, and returns the
+// results in a fixed order
+func runDoctor(ctx context.Context, cfg config.Config) []doctorCheck {
+	checks := []func(context.Context) doctorCheck{
+		checkEditor,
+		checkPTY,
+		checkClipboard,
+		checkDocker,
+		checkAzureCLI,
+	}
+	if cfg.MCP.Enabled {
+		for _, s := range cfg.MCP.ActiveServers() {
+			checks = append(checks, func(ctx context.Context) doctorCheck { return checkMCPServer(ctx, s) })
+		}
+	}
+	checks = append(checks, aiProviderChecks(cfg.AI)...)
+
+	results := make([]doctorCheck, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, doctorTimeout)
+			defer cancel()
+			results[i] = check(ctx)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// doctorFailed reports whether any check failed
+func doctorFailed(checks []doctorCheck) bool {
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			return true
+		}
+	}
+	return false
+}
+
+func checkEditor(context.Context) doctorCheck {
+	c := doctorCheck{Name: "Editor"}
+	for _, env := range []string{"EDITOR", "VISUAL"} {
+		if editor := os.Getenv(env); editor != "" {
+			c.Status, c.Detail = doctorPass, fmt.Sprintf("$%s is %s", env, editor)
+			return c
+		}
+	}
+	c.Status, c.Hint = doctorWarn, "Set $EDITOR to the editor for resources and commands, e.g. export EDITOR=nvim"
+	for _, e := range []string{"vim", "vi", "nano"} {
+		if _, err := exec.LookPath(e); err == nil {
+			c.Detail = "$EDITOR is not set, using " + e
+			return c
+		}
+	}
+	c.Detail = "$EDITOR is not set and no vim, vi or nano was found"
+	return c
+}
+
+func checkPTY(context.Context) doctorCheck {
+	c := doctorCheck{Name: "Terminal (PTY)"}
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		c.Status, c.Detail = doctorFail, err.Error()
+		c.Hint = "Commands can't run in the embedded terminal; check that /dev/ptmx exists and is writable"
+		return c
+	}
+	ptmx.Close()
+	tty.Close()
+	c.Status, c.Detail = doctorPass, "pseudo-terminals are available"
+	return c
+}
+
+func checkClipboard(context.Context) doctorCheck {
+	c := doctorCheck{Name: "Clipboard"}
+	if clipboard.Unsupported {
+		c.Status, c.Detail = doctorWarn, "no clipboard utility found"
+		c.Hint = "Install xclip, xsel or wl-clipboard to copy commands and results"
+		if runtime.GOOS != "linux" {
+			c.Hint = "Copying commands and results is not supported on " + runtime.GOOS
+		}
+		return c
+	}
+	c.Status, c.Detail = doctorPass, "copying is supported"
+	return c
+}
+
+func checkDocker(ctx context.Context) doctorCheck {
+	c := doctorCheck{Name: "Docker"}
+	if _, err := exec.LookPath("docker"); err != nil {
+		c.Status, c.Detail = doctorWarn, "docker is not installed"
+		c.Hint = "Install Docker to run agents and the docker resource's commands"
+		return c
+	}
+	out, err := exec.CommandContext(ctx, "docker", "info", "--format", "{{.ServerVersion}}").Output()
+	if err != nil {
+		c.Status, c.Detail = doctorWarn, "the Docker daemon is not reachable"
+		c.Hint = "Start Docker, or check DOCKER_HOST and your docker context"
+		return c
+	}
+	c.Status, c.Detail = doctorPass, "server "+strings.TrimSpace(string(out))
+	return c
+}
+
+func checkAzureCLI(ctx context.Context) doctorCheck {
+	c := doctorCheck{Name: "Azure CLI"}
+	if !azure.CLIAvailable() {
+		c.Status, c.Detail = doctorWarn, "az is not installed"
+		c.Hint = "Install the Azure CLI to deploy agents and use Azure OpenAI"
+		return c
+	}
+	out, err := exec.CommandContext(ctx, "az", "account", "show", "--query", "user.name", "--output", "tsv").Output()
+	if err != nil {
+		c.Status, c.Detail = doctorWarn, "not logged in"
+		c.Hint = "Run az login"
+		return c
+	}
+	c.Status, c.Detail = doctorPass, "logged in as "+strings.TrimSpace(string(out))
+	return c
+}
+
+func checkMCPServer(ctx context.Context, s config.MCPServerConfig) doctorCheck {
+	c := doctorCheck{Name: "MCP server " + s.Name}
+	status := mcppkg.FetchServerStatus(ctx, s.Name, s.URL)
+	if !status.Connected {
+		c.Status, c.Detail = doctorFail, status.Error
+		c.Hint = fmt.Sprintf("Check that the server is running at %s, or set disabled: true for it in config.yaml", s.URL)
+		return c
+	}
+	c.Status, c.Detail = doctorPass, fmt.Sprintf("%s, %d tools", s.URL, len(status.Tools))
+	return c
+}
+
+// aiProviderChecks returns a connection test for each enabled provider,
+// or a warning when there are none
+func aiProviderChecks(cfg config.AIConfig) []func(context.Context) doctorCheck {
+	var checks []func(context.Context) doctorCheck
+	for _, p := range cfg.Providers {
+		if p.Enabled {
+			checks = append(checks, func(context.Context) doctorCheck { return checkAIProvider(p) })
+		}
+	}
+	if len(checks) == 0 {
+		checks = append(checks, func(context.Context) doctorCheck {
+			return doctorCheck{
+				Name:   "AI providers",
+				Status: doctorWarn,
+				Detail: "none configured",
+				Hint:   "Add one with Configure Providers on the dashboard to use Ask AI and agents",
+			}
+		})
+	}
+	return checks
+}
+
+func checkAIProvider(p config.ProviderConfig) doctorCheck {
+	c := doctorCheck{Name: "AI provider " + p.Name}
+	if err := ai.NewClient(p).TestConnection(); err != nil {
+		c.Status, c.Detail = doctorFail, err.Error()
+		c.Hint = "Check its API key, base URL and model with Configure Providers"
+		return c
+	}
+	c.Status, c.Detail = doctorPass, p.ProviderType
+	return c
+}
+
+// doctorIcon marks a result in the report
+func doctorIcon(status string) string {
+	switch status {
+	case doctorPass:
+		return "✓"
+	case doctorWarn:
+		return "!"
+	}
+	return "✗"
+}
+
+// doctorMarkdown returns the results for the palette
+func doctorMarkdown(checks []doctorCheck) string {
+	var b strings.Builder
+	for _, c := range checks {
+		fmt.Fprintf(&b, "- %s **%s**", doctorIcon(c.Status), c.Name)
+		if c.Detail != "" {
+			fmt.Fprintf(&b, ": %s", c.Detail)
+		}
+		if c.Hint != "" {
+			fmt.Fprintf(&b, "\n  *%s*", c.Hint)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// getDoctorPaletteItem returns the palette action that runs the checks of
+// skitz doctor
+func (m *model) getDoctorPaletteItem() PaletteItem {
+	return PaletteItem{
+		ID:       "action:doctor",
+		Icon:     "🩺",
+		Title:    "Run Diagnostics",
+		Subtitle: "Check the editor, terminal, Docker, Azure CLI, MCP servers and AI providers",
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			return m.runDoctorFromPalette()
+		},
+	}
+}
+
+// runDoctorFromPalette runs the checks in the background and shows the
+// report in the palette
+func (m *model) runDoctorFromPalette() tea.Cmd {
+	cfg := m.config
+	parent, spin := m.startPaletteExecution("Running diagnostics...")
+	return tea.Batch(spin, func() tea.Msg {
+		checks := runDoctor(parent, cfg)
+		if errors.Is(parent.Err(), context.Canceled) {
+			return nil
+		}
+		failed := doctorFailed(checks)
+		title := "Diagnostics · all good"
+		if failed {
+			title = "Diagnostics · problems found"
+		}
+		return paletteResultMsg{title: title, output: doctorMarkdown(checks), failed: failed}
+	})
+}
+
+// cliDoctor checks prerequisites and prints a report. It fails when a
+// check failed.
+func cliDoctor(args []string, opts CLIOptions) error {
+	fs := newCLIFlagSet("doctor", &opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	checks := runDoctor(context.Background(), config.Load(mcppkg.GetDefaultMCPServerURL()))
+	if opts.JSON {
+		if err := writeJSON(opts.Out, checks); err != nil {
+			return err
+		}
+	} else {
+		for _, c := range checks {
+			fmt.Fprintf(opts.Out, "%s  %-20s %s\n", doctorIcon(c.Status), c.Name, c.Detail)
+			if c.Hint != "" {
+				fmt.Fprintf(opts.Out, "   %-20s %s\n", "", c.Hint)
+			}
+		}
+	}
+
+	if doctorFailed(checks) {
+		return ErrDoctorFailed
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestCheckEditor(t *testing.T) {
+	t.Setenv("EDITOR", "")
+	t.Setenv("VISUAL", "hx")
+	if c := checkEditor(context.Background()); c.Status != doctorPass || c.Detail != "$VISUAL is hx" {
+		t.Errorf("checkEditor() = %+v, want a pass for $VISUAL", c)
+	}
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("PATH", t.TempDir())
+	if c := checkEditor(context.Background()); c.Status != doctorWarn || c.Hint == "" {
+		t.Errorf("checkEditor() = %+v, want a warning with a hint", c)
+	}
+}
+
+func TestRunDoctor(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	cfg := config.Config{}
+	cfg.MCP.Enabled = true
+	cfg.MCP.Servers = []config.MCPServerConfig{
+		{Name: "down", URL: "http://" + addr + "/mcp/"},
+		{Name: "off", URL: "http://" + addr + "/mcp/", Disabled: true},
+	}
+
+	checks := runDoctor(context.Background(), cfg)
+	var names []string
+	for _, c := range checks {
+		names = append(names, c.Name)
+	}
+	want := "Editor,Terminal (PTY),Clipboard,Docker,Azure CLI,MCP server down,AI providers"
+	if got := strings.Join(names, ","); got != want {
+		t.Fatalf("checks = %s, want %s", got, want)
+	}
+
+	if mcp := checks[5]; mcp.Status != doctorFail || !strings.Contains(mcp.Hint, addr) {
+		t.Errorf("unreachable MCP server = %+v, want a failure naming its address", mcp)
+	}
+	if ai := checks[6]; ai.Status != doctorWarn {
+		t.Errorf("no AI providers = %+v, want a warning", ai)
+	}
+	if !doctorFailed(checks) {
+		t.Error("doctorFailed() = false with a failed check")
+	}
+	if report := doctorMarkdown(checks); !strings.Contains(report, "- ✗ **MCP server down**") {
+		t.Errorf("report is missing the failed server:\n%s", report)
+	}
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// defaultDynamicTTL is how long a dynamic section's content is reused
+// before it is fetched again
+const defaultDynamicTTL = 2 * time.Minute
+
+// sectionProvider produces the markdown of a dynamic section of resource.
+// Command lines in it are listed and run like those of static sections.
+type sectionProvider func(ctx context.Context, cfg config.Config, resource string, sec section) (string, error)
+
+// sectionProviders are the providers resources can name in the dynamic
+// list of their front-matter
+var sectionProviders = map[string]sectionProvider{
+	"github.prs":    githubPullRequestsSection,
+	"github.issues": githubIssuesSection,
+	"command":       commandSection,
+}
+
+// networkSectionProviders are the providers not fetched in offline mode
+var networkSectionProviders = map[string]bool{
+	"github.prs":    true,
+	"github.issues": true,
+}
+
+// dynamicSectionMeta is a dynamic section declared in resource
+// front-matter
+type dynamicSectionMeta struct {
+	Title    string        `yaml:"title"`
+	Provider string        `yaml:"provider"`
+	TTL      time.Duration `yaml:"ttl"`
+}
+
+// dynamicSource fills a section when it is viewed
+type dynamicSource struct {
+	provider string
+	arg      string // e.g. the command of a ^dynamic: heading
+	ttl      time.Duration
+}
+
+// dynamicSectionState is the cached content of a dynamic section
+type dynamicSectionState struct {
+	content string
+	err     error
+	loading bool
+	fetched time.Time
+}
+
+// dynamicSectionMsg carries fetched dynamic section content
+type dynamicSectionMsg struct {
+	key     string
+	content string
+	err     error
+}
+
+// appendDynamicSections adds the dynamic sections declared in meta to res.
+// Unknown providers are logged and left out.
+func appendDynamicSections(res *resource, meta resourceMeta) {
+	for _, d := range meta.Dynamic {
+		if _, ok := sectionProviders[d.Provider]; !ok {
+			slog.Warn("unknown dynamic section provider", "resource", res.name, "provider", d.Provider)
+			continue
+		}
+		ttl := d.TTL
+		if ttl <= 0 {
+			ttl = defaultDynamicTTL
+		}
+		res.sections = append(res.sections, section{
+			title:   d.Title,
+			dynamic: &dynamicSource{provider: d.Provider, ttl: ttl},
+		})
+	}
+}
+
+// dynamicKey identifies a dynamic section in model.dynamicSections
+func dynamicKey(res *resource, sec *section) string {
+	return res.name + "/" + sec.title
+}
+
+// fetchDynamicSection fetches the section being viewed if it is dynamic
+// and its content is missing or older than its TTL. force ignores the TTL.
+func (m *model) fetchDynamicSection(force bool) tea.Cmd {
+	if m.currentView != viewDetail {
+		return nil
+	}
+	res, sec := m.currentResource(), m.currentSection()
+	if res == nil || sec == nil || sec.dynamic == nil {
+		return nil
+	}
+
+	if m.offline && networkSectionProviders[sec.dynamic.provider] {
+		return nil
+	}
+
+	key := dynamicKey(res, sec)
+	st := m.dynamicSections[key]
+	if st.loading || (!force && !st.fetched.IsZero() && time.Since(st.fetched) < sec.dynamic.ttl) {
+		return nil
+	}
+	st.loading = true
+	m.dynamicSections[key] = st
+	m.updateViewportContent()
+
+	provider := sectionProviders[sec.dynamic.provider]
+	cfg, name, snapshot := m.config, res.name, *sec
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		content, err := provider(ctx, cfg, name, snapshot)
+		return dynamicSectionMsg{key: key, content: content, err: err}
+	}
+}
+
+// handleDynamicSection stores fetched content and redraws the section if
+// it is still shown
+func (m *model) handleDynamicSection(msg dynamicSectionMsg) {
+	if msg.err != nil {
+		slog.Warn("dynamic section failed", "section", msg.key, "error", msg.err)
+	}
+	m.dynamicSections[msg.key] = dynamicSectionState{content: msg.content, err: msg.err, fetched: time.Now()}
+
+	res, sec := m.currentResource(), m.currentSection()
+	if m.currentView == viewDetail && res != nil && sec != nil && sec.dynamic != nil && dynamicKey(res, sec) == msg.key {
+		m.updateViewportContent()
+	}
+}
+
+// dynamicContent returns what a dynamic section shows: its cached content,
+// or a line saying it is loading or failed
+func (m model) dynamicContent(res *resource, sec *section) string {
+	st := m.dynamicSections[dynamicKey(res, sec)]
+	switch {
+	case m.offline && networkSectionProviders[sec.dynamic.provider] && st.fetched.IsZero():
+		return "✈ Offline. This section is fetched from the network."
+	case st.err != nil:
+		return fmt.Sprintf("⚠ %v\n\nPress r to retry.", st.err)
+	case st.fetched.IsZero():
+		return "Loading…"
+	}
+	return st.content
+}
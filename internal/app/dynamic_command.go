@@ -0,0 +1,116 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// maxDynamicItems caps how many output lines of a ^dynamic: command become
+// items
+const maxDynamicItems = 100
+
+// parseSectionHeading builds the section for a "## " heading of a detail
+// file. A heading ending in ^dynamic:<command>, optionally followed by
+// ^ttl:<duration>, is a section listing the command's output lines.
+func parseSectionHeading(line string) *section {
+	title := strings.TrimSpace(strings.TrimPrefix(line, "## "))
+	before, command, ok := strings.Cut(title, "^dynamic:")
+	if !ok {
+		return &section{title: title}
+	}
+
+	ttl := defaultDynamicTTL
+	if i := strings.LastIndex(command, " ^ttl:"); i >= 0 {
+		if d, err := time.ParseDuration(strings.TrimSpace(command[i+len(" ^ttl:"):])); err == nil && d > 0 {
+			ttl = d
+			command = command[:i]
+		}
+	}
+	return &section{
+		title:   strings.TrimSpace(before),
+		dynamic: &dynamicSource{provider: "command", arg: strings.TrimSpace(command), ttl: ttl},
+	}
+}
+
+// commandSection runs the command of a ^dynamic: section in the resource's
+// shell and renders each output line as an item. Template lines of the
+// section that contain {{item}} are repeated for every item; lines without
+// it are kept once.
+func commandSection(ctx context.Context, cfg config.Config, resource string, sec section) (string, error) {
+	command := sec.dynamic.arg
+	if command == "" {
+		return "", errors.New("no command for dynamic section")
+	}
+	if err := cfg.Policy.CheckCommand(command); err != nil {
+		return "", err
+	}
+
+	shell := resolveShell(cfg.Execution, resource)
+	cmd := exec.CommandContext(ctx, shell.path, shell.args(captureForTarget(command, cfg.Execution.Targets[resource]))...)
+	if len(shell.env) > 0 {
+		cmd.Env = append(os.Environ(), shell.env...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", command, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", command, err)
+	}
+
+	var items []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	if len(items) > maxDynamicItems {
+		items = items[:maxDynamicItems]
+	}
+	return expandItemTemplate(sec.content, items), nil
+}
+
+// expandItemTemplate renders items through the template lines of a
+// section, skipping its headings
+func expandItemTemplate(template string, items []string) string {
+	if len(items) == 0 {
+		return "No items."
+	}
+
+	var static, perItem []string
+	for _, line := range strings.Split(template, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+		case strings.Contains(line, "{{item}}"):
+			perItem = append(perItem, line)
+		default:
+			static = append(static, line)
+		}
+	}
+
+	var b strings.Builder
+	for _, item := range items {
+		if len(perItem) == 0 {
+			b.WriteString("- " + item + "\n")
+			continue
+		}
+		for _, line := range perItem {
+			b.WriteString(strings.ReplaceAll(line, "{{item}}", item) + "\n")
+		}
+	}
+	for _, line := range static {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
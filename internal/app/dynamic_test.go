@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestAppendDynamicSections(t *testing.T) {
+	meta, _ := parseFrontMatter("---\ndynamic:\n  - title: PRs\n    provider: github.prs\n    ttl: 30s\n  - title: Bogus\n    provider: nope\n---\n# Git\n")
+	res := resource{name: "git", sections: []section{{title: "Commands"}}}
+	appendDynamicSections(&res, meta)
+
+	if len(res.sections) != 2 {
+		t.Fatalf("got %d sections, want 2", len(res.sections))
+	}
+	d := res.sections[1].dynamic
+	if res.sections[1].title != "PRs" || d == nil || d.provider != "github.prs" || d.ttl.Seconds() != 30 {
+		t.Errorf("section = %+v, dynamic = %+v", res.sections[1], d)
+	}
+}
+
+func TestFetchDynamicSection(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir() // showing the section records a visit
+	defer func() { config.DataDir = orig }()
+
+	calls := 0
+	sectionProviders["test"] = func(ctx context.Context, cfg config.Config, resource string, sec section) (string, error) {
+		calls++
+		if calls > 1 {
+			return "", errors.New("rate limited")
+		}
+		return "`echo hi` say hi ^run\n", nil
+	}
+	defer delete(sectionProviders, "test")
+
+	m := model{
+		currentView:     viewDetail,
+		dynamicSections: make(map[string]dynamicSectionState),
+		resources: []resource{{name: "demo", sections: []section{
+			{title: "Live", dynamic: &dynamicSource{provider: "test", ttl: defaultDynamicTTL}},
+		}}},
+	}
+	m.contentView = viewport.New(80, 20)
+
+	cmd := m.fetchDynamicSection(false)
+	if cmd == nil {
+		t.Fatal("expected a fetch")
+	}
+	if m.fetchDynamicSection(true) != nil {
+		t.Error("section fetched again while loading")
+	}
+	m.handleDynamicSection(cmd().(dynamicSectionMsg))
+	if len(m.commands) != 1 || m.commands[0].cmd != "echo hi" {
+		t.Errorf("commands = %+v, want the provided command", m.commands)
+	}
+	if m.fetchDynamicSection(false) != nil {
+		t.Error("fresh section fetched again")
+	}
+
+	cmd = m.fetchDynamicSection(true)
+	if cmd == nil {
+		t.Fatal("forced refresh did not fetch")
+	}
+	m.handleDynamicSection(cmd().(dynamicSectionMsg))
+	if st := m.dynamicSections["demo/Live"]; st.err == nil || st.loading {
+		t.Errorf("state = %+v, want the provider error", st)
+	}
+}
+
+func TestParseSectionHeading(t *testing.T) {
+	tests := []struct {
+		line    string
+		title   string
+		command string
+		ttl     time.Duration
+	}{
+		{"## Core Concepts", "Core Concepts", "", 0},
+		{"## Running Containers ^dynamic:docker ps --format '{{.Names}}'", "Running Containers", "docker ps --format '{{.Names}}'", defaultDynamicTTL},
+		{"## Pods ^dynamic:kubectl get pods -o name ^ttl:10s", "Pods", "kubectl get pods -o name", 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			sec := parseSectionHeading(tt.line)
+			if sec.title != tt.title {
+				t.Errorf("title = %q, want %q", sec.title, tt.title)
+			}
+			if tt.command == "" {
+				if sec.dynamic != nil {
+					t.Errorf("dynamic = %+v, want a static section", sec.dynamic)
+				}
+				return
+			}
+			if sec.dynamic == nil || sec.dynamic.arg != tt.command || sec.dynamic.ttl != tt.ttl {
+				t.Errorf("dynamic = %+v, want %q every %s", sec.dynamic, tt.command, tt.ttl)
+			}
+		})
+	}
+}
+
+func TestCommandSection(t *testing.T) {
+	sec := *parseSectionHeading("## Running ^dynamic:printf 'web\\n\\ndb\\n'")
+	sec.content = "## Running\n\n`docker logs -f {{item}}` logs of {{item}} ^run\n`docker ps -a` all containers ^run\n"
+
+	cfg := config.Config{Execution: config.ExecutionConfig{Shell: "/bin/sh"}}
+	got, err := commandSection(context.Background(), cfg, "docker", sec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "`docker logs -f web` logs of web ^run\n`docker logs -f db` logs of db ^run\n`docker ps -a` all containers ^run\n"
+	if got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+
+	sec.dynamic.arg = "echo oops >&2; exit 3"
+	if _, err := commandSection(context.Background(), cfg, "docker", sec); err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("err = %v, want stderr in the error", err)
+	}
+}
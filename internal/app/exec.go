@@ -1,12 +1,20 @@
 package app
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/hooks"
+	"github.com/htelsiz/skitz/internal/metrics"
+	runtimepkg "github.com/htelsiz/skitz/internal/runtime"
 )
 
 // CommandMode determines how a command is executed.
@@ -15,40 +23,214 @@ type CommandMode string
 const (
 	CommandEmbedded    CommandMode = "embedded"
 	CommandInteractive CommandMode = "interactive"
+	CommandTable       CommandMode = "table"
+	CommandPager       CommandMode = "pager"
+	CommandDetached    CommandMode = "detached"
 )
 
 // CommandSpec describes a command to execute.
 type CommandSpec struct {
 	Command string
 	Mode    CommandMode
+	Env     []string // added to the command's environment, e.g. its secrets
+	OnFail  []string // follow-up commands offered when it fails, from ^onfail
+}
+
+// commandRun is a command on its way to an executor, with the resource it
+// belongs to and the shell picked for it.
+type commandRun struct {
+	command  string
+	mode     CommandMode
+	resource string
+	shell    shellSpec
+	onFail   []string
+}
+
+// hookEvent describes the run to hooks.
+func (r commandRun) hookEvent() hooks.Event {
+	return hooks.Event{Command: r.command, Resource: r.resource, Mode: string(r.mode)}
+}
+
+// preRunDoneMsg reports that a command's pre-run hooks finished; env holds
+// the variables they exported.
+type preRunDoneMsg struct {
+	run commandRun
+	env []string
+	err error
 }
 
-func resolveShell() string {
-	shell := os.Getenv("SHELL")
+// hookErrorsMsg reports post-run hooks that failed.
+type hookErrorsMsg struct {
+	errs []error
+}
+
+// shellSpec describes the shell used to run a command.
+type shellSpec struct {
+	path  string
+	login bool
+	env   []string // added to the environment, e.g. DOCKER_CONTEXT
+}
+
+// resolveShell picks the shell for a resource: the per-resource override,
+// then execution.shell, then $SHELL, then /bin/sh.
+func resolveShell(cfg config.ExecutionConfig, resource string) shellSpec {
+	shell := cfg.Resources[resource]
 	if shell == "" {
-		return "/bin/sh"
+		shell = cfg.Shell
+	}
+	if shell == "" {
+		shell = os.Getenv("SHELL")
+	}
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	spec := shellSpec{path: shell, login: cfg.LoginShell}
+	if dockerContext := cfg.Targets[resource].Context; dockerContext != "" {
+		spec.env = []string{"DOCKER_CONTEXT=" + dockerContext}
+	}
+	return spec
+}
+
+// args returns the shell arguments for running command. All supported
+// shells (sh, bash, zsh, fish, nu) accept -l for a login shell and -c.
+func (s shellSpec) args(command string) []string {
+	if s.login {
+		return []string{"-l", "-c", command}
+	}
+	return []string{"-c", command}
+}
+
+func newShellCommand(shell shellSpec, command string) *exec.Cmd {
+	cmd := exec.Command(shell.path, shell.args(command)...)
+	if len(shell.env) > 0 {
+		cmd.Env = append(os.Environ(), shell.env...)
+	}
+	return cmd
+}
+
+// wrapForTarget runs command inside the target's container with docker
+// exec. docker commands themselves run on the host. The environment
+// variables named in env are passed into the container.
+func wrapForTarget(command string, target config.ExecTarget, env ...string) string {
+	return dockerExec(command, target, "-it", env)
+}
+
+// captureForTarget is wrapForTarget without a TTY, for commands whose
+// output is captured rather than shown in a terminal
+func captureForTarget(command string, target config.ExecTarget, env ...string) string {
+	return dockerExec(command, target, "-i", env)
+}
+
+func dockerExec(command string, target config.ExecTarget, flags string, env []string) string {
+	if target.Container == "" {
+		return command
+	}
+	if fields := strings.Fields(command); len(fields) > 0 && (fields[0] == "docker" || fields[0] == "docker-compose") {
+		return command
+	}
+	for _, name := range env {
+		flags += " -e " + name
 	}
-	return shell
+	return fmt.Sprintf("docker exec %s %s sh -c %s", flags, runtimepkg.Quote(target.Container), runtimepkg.Quote(command))
 }
 
-func newShellCommand(command string) *exec.Cmd {
-	return exec.Command(resolveShell(), "-c", command)
+// shell returns the shell for the current resource.
+func (m model) shell() shellSpec {
+	name := ""
+	if res := m.currentResource(); res != nil {
+		name = res.name
+	}
+	return resolveShell(m.config.Execution, name)
 }
 
 func (m *model) runCommand(spec CommandSpec) tea.Cmd {
 	if strings.TrimSpace(spec.Command) == "" {
-		log.Println("runCommand: empty command")
+		slog.Debug("runCommand: empty command")
 		return nil
 	}
 
-	log.Printf("runCommand: mode=%s cmd=%s", spec.Mode, spec.Command)
+	if err := m.config.Policy.CheckCommand(spec.Command); err != nil {
+		slog.Warn("command blocked", "command", spec.Command, "error", err)
+		return m.showNotification("🔒", err.Error(), "error")
+	}
+
+	slog.Info("running command", "mode", spec.Mode, "command", spec.Command)
+	metrics.CommandsRun.Inc(string(spec.Mode))
+
+	name := ""
+	if res := m.currentResource(); res != nil {
+		name = res.name
+	}
+	shell := m.shell()
+	shell.env = append(slices.Clone(shell.env), spec.Env...)
+	return m.startRun(commandRun{command: spec.Command, mode: spec.Mode, resource: name, shell: shell, onFail: spec.OnFail})
+}
+
+// startRun runs the pre-run hooks that match run, if any, and then the
+// command.
+func (m *model) startRun(run commandRun) tea.Cmd {
+	preRun := m.config.Hooks.PreRun
+	ev := run.hookEvent()
+	if len(hooks.Matching(preRun, ev)) == 0 {
+		return m.execute(run)
+	}
+	return func() tea.Msg {
+		env, err := hooks.RunPre(context.Background(), preRun, ev)
+		return preRunDoneMsg{run: run, env: env, err: err}
+	}
+}
 
-	switch spec.Mode {
+// handlePreRunDone starts the command once its pre-run hooks succeeded.
+func (m *model) handlePreRunDone(msg preRunDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		slog.Warn("command stopped by hook", "command", msg.run.command, "error", msg.err)
+		return m.showNotification("🪝", msg.err.Error(), "error")
+	}
+	run := msg.run
+	run.shell.env = append(slices.Clone(run.shell.env), msg.env...)
+	return m.execute(run)
+}
+
+// execute hands run to the executor for its mode.
+func (m *model) execute(run commandRun) tea.Cmd {
+	switch run.mode {
 	case CommandInteractive:
-		log.Println("runCommand: using interactive mode")
-		return m.executeInteractive(command{cmd: spec.Command}, spec.Command)
+		return m.executeInteractive(run)
+	case CommandTable:
+		return m.executeTable(run)
+	case CommandPager:
+		return m.executePager(run)
+	case CommandDetached:
+		return m.executeDetached(run)
 	default:
-		log.Println("runCommand: using embedded mode")
-		return m.executeEmbedded(spec.Command)
+		return m.executeEmbedded(run)
+	}
+}
+
+// runPostHooks runs the post-run hooks that match a finished command in
+// the background.
+func (m *model) runPostHooks(ev hooks.Event) tea.Cmd {
+	postRun := m.config.Hooks.PostRun
+	ev.Stage = hooks.PostRun
+	if len(hooks.Matching(postRun, ev)) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		if errs := hooks.RunPost(context.Background(), postRun, ev); len(errs) > 0 {
+			return hookErrorsMsg{errs: errs}
+		}
+		return nil
+	}
+}
+
+// handleHookErrors logs failed post-run hooks and shows the first one.
+func (m *model) handleHookErrors(msg hookErrorsMsg) tea.Cmd {
+	for _, err := range msg.errs {
+		slog.Warn("post-run hook failed", "error", err)
+	}
+	text := msg.errs[0].Error()
+	if len(msg.errs) > 1 {
+		text += fmt.Sprintf(" (+%d more)", len(msg.errs)-1)
 	}
+	return m.showNotification("🪝", text, "warning")
 }
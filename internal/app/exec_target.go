@@ -0,0 +1,236 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yarlson/tap"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// execTargetResource is the resource whose commands can be pointed at a
+// Docker context and container
+const execTargetResource = "docker"
+
+// execTargetNone is the picker value for "no override"
+const execTargetNone = "-"
+
+// execTargetMsg carries the target chosen in the picker
+type execTargetMsg struct {
+	resource string
+	target   config.ExecTarget
+}
+
+// execTargetCmd implements tea.ExecCommand for picking the Docker context
+// and container a resource's commands run against
+type execTargetCmd struct {
+	resource string
+	current  config.ExecTarget
+	chosen   config.ExecTarget
+	done     bool
+}
+
+func (c *execTargetCmd) Run() error {
+	ctx := context.Background()
+
+	fmt.Print("\033[H\033[2J")
+
+	tap.Intro("🐳 Docker Target")
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		tap.Box("The docker CLI was not found in PATH.", "Docker Not Found", tap.BoxOptions{})
+		waitForEnter()
+		return nil
+	}
+
+	dockerContext, ok := c.selectContext(ctx)
+	if !ok {
+		tap.Cancel("Cancelled")
+		return nil
+	}
+
+	container, ok := c.selectContainer(ctx, dockerContext)
+	if !ok {
+		tap.Cancel("Cancelled")
+		return nil
+	}
+
+	c.chosen = config.ExecTarget{Context: dockerContext, Container: container}
+	c.done = true
+	if label := execTargetLabel(c.chosen); label != "" {
+		tap.Outro("✓ " + c.resource + " commands run in " + label)
+	} else {
+		tap.Outro("✓ " + c.resource + " commands run on the host")
+	}
+	return nil
+}
+
+// selectContext asks which Docker context to use. An empty context keeps
+// the active one.
+func (c *execTargetCmd) selectContext(ctx context.Context) (string, bool) {
+	names, err := dockerLines(ctx, "", "context", "ls", "--format", "{{.Name}}")
+	if err != nil || len(names) <= 1 {
+		// Nothing to choose from; keep any saved context
+		return c.current.Context, true
+	}
+
+	active, _ := dockerLines(ctx, "", "context", "show")
+	activeHint := "active context"
+	if len(active) > 0 {
+		activeHint = active[0]
+	}
+
+	options := []tap.SelectOption[string]{{Value: execTargetNone, Label: "Active context", Hint: activeHint}}
+	for _, name := range names {
+		options = append(options, tap.SelectOption[string]{Value: name, Label: name})
+	}
+	initial := execTargetNone
+	if c.current.Context != "" {
+		initial = c.current.Context
+	}
+
+	choice := tap.Select(ctx, tap.SelectOptions[string]{
+		Message:      "Docker context:",
+		Options:      options,
+		InitialValue: &initial,
+	})
+	switch choice {
+	case "":
+		return "", false
+	case execTargetNone:
+		return "", true
+	}
+	return choice, true
+}
+
+// selectContainer asks which running container commands are executed in.
+// An empty container runs them on the host.
+func (c *execTargetCmd) selectContainer(ctx context.Context, dockerContext string) (string, bool) {
+	spinner := tap.NewSpinner(tap.SpinnerOptions{})
+	spinner.Start("Listing running containers...")
+	lines, err := dockerLines(ctx, dockerContext, "ps", "--format", "{{.Names}}\t{{.Image}}\t{{.Status}}")
+	if err != nil {
+		spinner.Stop("Could not list containers: "+err.Error(), 1)
+	} else {
+		spinner.Stop(fmt.Sprintf("%d running containers", len(lines)), 0)
+	}
+
+	options := []tap.SelectOption[string]{{Value: execTargetNone, Label: "None", Hint: "run on the host"}}
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+		hint := ""
+		if len(fields) == 3 {
+			hint = fields[1] + " · " + fields[2]
+		}
+		options = append(options, tap.SelectOption[string]{Value: fields[0], Label: fields[0], Hint: hint})
+	}
+	initial := execTargetNone
+	if c.current.Container != "" {
+		initial = c.current.Container
+	}
+
+	choice := tap.Select(ctx, tap.SelectOptions[string]{
+		Message:      "Run commands in container:",
+		Options:      options,
+		InitialValue: &initial,
+	})
+	switch choice {
+	case "":
+		return "", false
+	case execTargetNone:
+		return "", true
+	}
+	return choice, true
+}
+
+func (c execTargetCmd) SetStdin(r io.Reader)  {}
+func (c execTargetCmd) SetStdout(w io.Writer) {}
+func (c execTargetCmd) SetStderr(w io.Writer) {}
+
+// dockerLines runs a docker command against dockerContext and returns its
+// non-empty output lines
+func dockerLines(ctx context.Context, dockerContext string, args ...string) ([]string, error) {
+	if dockerContext != "" {
+		args = append([]string{"--context", dockerContext}, args...)
+	}
+	out, err := exec.CommandContext(ctx, "docker", args...).Output()
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// execTargetLabel formats a target for display, e.g. "web @ staging"
+func execTargetLabel(t config.ExecTarget) string {
+	switch {
+	case t.Container != "" && t.Context != "":
+		return t.Container + " @ " + t.Context
+	case t.Container != "":
+		return t.Container
+	case t.Context != "":
+		return "@ " + t.Context
+	}
+	return ""
+}
+
+// execTarget returns the saved target of the current resource
+func (m model) execTarget() config.ExecTarget {
+	if res := m.currentResource(); res != nil {
+		return m.config.Execution.Targets[res.name]
+	}
+	return config.ExecTarget{}
+}
+
+// hasExecTarget reports whether res has a target picker: Docker for the
+// docker resource, kubeconfig context for Kubernetes resources
+func hasExecTarget(res *resource) bool {
+	return res != nil && (res.name == execTargetResource || isKubeResource(res))
+}
+
+// pickExecTarget opens the target picker for the current resource
+func (m *model) pickExecTarget() tea.Cmd {
+	res := m.currentResource()
+	if isKubeResource(res) {
+		return m.startKubeSwitch()
+	}
+	if res == nil || res.name != execTargetResource {
+		return nil
+	}
+	tc := &execTargetCmd{resource: res.name, current: m.execTarget()}
+	return tea.Exec(tc, func(err error) tea.Msg {
+		if err != nil || !tc.done {
+			return nil
+		}
+		return execTargetMsg{resource: tc.resource, target: tc.chosen}
+	})
+}
+
+// setExecTarget saves the target for a resource
+func (m *model) setExecTarget(msg execTargetMsg) tea.Cmd {
+	if msg.target == (config.ExecTarget{}) {
+		delete(m.config.Execution.Targets, msg.resource)
+	} else {
+		if m.config.Execution.Targets == nil {
+			m.config.Execution.Targets = make(map[string]config.ExecTarget)
+		}
+		m.config.Execution.Targets[msg.resource] = msg.target
+	}
+	if err := config.Save(m.config); err != nil {
+		return m.showNotification("⚠", "Failed to save target: "+err.Error(), "error")
+	}
+	if label := execTargetLabel(msg.target); label != "" {
+		return m.showNotification("🐳", "Commands run in "+label, "success")
+	}
+	return m.showNotification("🐳", "Commands run on the host", "info")
+}
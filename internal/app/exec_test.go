@@ -0,0 +1,84 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestWrapForTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		target  config.ExecTarget
+		want    string
+	}{
+		{"no target", "ls -la", config.ExecTarget{}, "ls -la"},
+		{"context only", "ls -la", config.ExecTarget{Context: "staging"}, "ls -la"},
+		{"container", "ls -la", config.ExecTarget{Container: "web"}, "docker exec -it web sh -c 'ls -la'"},
+		{"quotes the command", "echo 'hi'", config.ExecTarget{Container: "web"}, `docker exec -it web sh -c 'echo '\''hi'\'''`},
+		{"docker commands run on the host", "docker ps", config.ExecTarget{Container: "web"}, "docker ps"},
+		{"docker-compose commands run on the host", "docker-compose up -d", config.ExecTarget{Container: "web"}, "docker-compose up -d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapForTarget(tt.command, tt.target); got != tt.want {
+				t.Errorf("wrapForTarget() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCaptureForTarget(t *testing.T) {
+	target := config.ExecTarget{Container: "web"}
+	if got, want := captureForTarget("ps aux", target), "docker exec -i web sh -c 'ps aux'"; got != want {
+		t.Errorf("captureForTarget() = %q, want %q", got, want)
+	}
+	if got := captureForTarget("docker ps", target); got != "docker ps" {
+		t.Errorf("captureForTarget() = %q, want the docker command unchanged", got)
+	}
+	if got, want := captureForTarget("psql", target, "SKITZ_SECRET_PW"), "docker exec -i -e SKITZ_SECRET_PW web sh -c psql"; got != want {
+		t.Errorf("captureForTarget() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveShellDockerContext(t *testing.T) {
+	cfg := config.ExecutionConfig{
+		Shell:   "/bin/sh",
+		Targets: map[string]config.ExecTarget{"docker": {Context: "staging"}},
+	}
+
+	if got := resolveShell(cfg, "docker").env; !slices.Equal(got, []string{"DOCKER_CONTEXT=staging"}) {
+		t.Errorf("resolveShell(docker).env = %q", got)
+	}
+	if got := resolveShell(cfg, "git").env; len(got) != 0 {
+		t.Errorf("resolveShell(git).env = %q, want none", got)
+	}
+
+	cmd := newShellCommand(resolveShell(cfg, "docker"), "true")
+	if !slices.Contains(cmd.Env, "DOCKER_CONTEXT=staging") {
+		t.Errorf("newShellCommand() env missing DOCKER_CONTEXT")
+	}
+}
+
+func TestStartRunPreRunHooks(t *testing.T) {
+	var m model
+	m.config.Hooks.PreRun = []config.Hook{{Run: "echo AZURE_TOKEN=abc", Match: `^az\b`}}
+
+	run := commandRun{command: "az vm list", mode: CommandTable, resource: "azure", shell: shellSpec{path: "/bin/sh"}}
+	msg, ok := m.startRun(run)().(preRunDoneMsg)
+	if !ok {
+		t.Fatal("expected the pre-run hooks to run first")
+	}
+	if msg.err != nil || !slices.Equal(msg.env, []string{"AZURE_TOKEN=abc"}) {
+		t.Errorf("env = %q, err = %v", msg.env, msg.err)
+	}
+
+	// Commands the hook doesn't match run directly
+	run.command = "ls"
+	if _, ok := m.startRun(run)().(tableOutputMsg); !ok {
+		t.Error("expected ls to run without hooks")
+	}
+}
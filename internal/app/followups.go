@@ -0,0 +1,145 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/ai"
+)
+
+// A command that fails in the terminal pane is followed by a numbered list
+// of things to try next: asking AI about the error, running it again with
+// -v, and the commands its ^onfail annotations name. 1-9 picks one, so a
+// resource can lay out what to do when a step fails.
+
+// maxFollowUps is how many follow-ups the number keys reach
+const maxFollowUps = 9
+
+// followUp is an action offered after a command failed
+type followUp struct {
+	label string
+	run   func(m *model) tea.Cmd
+}
+
+// failureFollowUps returns the follow-ups for the failed command in the
+// terminal pane, or none while it runs or when it succeeded
+func (m model) failureFollowUps() []followUp {
+	run := m.term.run
+	if !m.term.exited || m.term.exitErr == nil || run.command == "" {
+		return nil
+	}
+
+	var ups []followUp
+	if m.currentView == viewDetail && !m.config.Policy.AIDisabled {
+		ups = append(ups, followUp{label: "ask AI about the error", run: (*model).askAboutFailure})
+	}
+	if verbose, ok := verboseCommand(run.command); ok {
+		ups = append(ups, followUp{label: "re-run with -v", run: func(m *model) tea.Cmd {
+			return m.rerunFailed(verbose)
+		}})
+	}
+	for _, c := range run.onFail {
+		ups = append(ups, followUp{label: "run " + c, run: func(m *model) tea.Cmd {
+			return m.runOnFail(c)
+		}})
+	}
+	if len(ups) > maxFollowUps {
+		ups = ups[:maxFollowUps]
+	}
+	return ups
+}
+
+// verboseCommand returns command with -v added, or false when it already
+// asks for verbose output or is more than one plain command, where it's
+// unclear which command the flag would reach
+func verboseCommand(command string) (string, bool) {
+	if strings.ContainsAny(command, "|;&<>()`$'\"\\") {
+		return "", false
+	}
+	fields := strings.Fields(command)
+	if len(fields) == 0 || slices.Contains(fields, "-v") || slices.Contains(fields, "--verbose") {
+		return "", false
+	}
+	return strings.Join(fields, " ") + " -v", true
+}
+
+// askAboutFailure closes the terminal and asks the AI why the command
+// failed, with its output attached
+func (m *model) askAboutFailure() tea.Cmd {
+	if cmd := m.aiUnavailable(); cmd != nil {
+		return cmd
+	}
+	if m.config.AI.DefaultProvider == "" {
+		return m.showNotification("!", "Configure a provider first", "warning")
+	}
+
+	command, exitErr := m.term.command, m.term.exitErr
+	output := m.terminalOutput()
+	m.closeTerminal()
+	m.askPanel = &AskPanel{
+		Active: true,
+		Input:  fmt.Sprintf("Why did `%s` fail (%v), and how do I fix it?", command, exitErr),
+	}
+	if strings.TrimSpace(output) != "" {
+		m.askPanel.Contexts = []ai.Attachment{
+			ai.NewAttachment("output of "+command, output, m.config.AI.ContextLimit, ai.KeepTail),
+		}
+	}
+	return m.submitAskPanel()
+}
+
+// rerunFailed runs command in place of the failed one, in the same shell
+// and environment
+func (m *model) rerunFailed(command string) tea.Cmd {
+	if err := m.config.Policy.CheckCommand(command); err != nil {
+		return m.showNotification("🔒", err.Error(), "error")
+	}
+	run := m.term.run
+	run.command = command
+	run.onFail = nil
+	m.closeTerminal()
+	return m.startRun(run)
+}
+
+// runOnFail runs an ^onfail command of the failed one, filling its
+// placeholders like any of the resource's commands
+func (m *model) runOnFail(raw string) tea.Cmd {
+	finalCmd, ok := m.resolveCommand(m.term.run.resource, command{raw: raw, cmd: raw, runnable: true}, false)
+	if !ok {
+		return nil
+	}
+	m.closeTerminal()
+	return m.withSecrets(finalCmd, func(m *model, finalCmd string, env []string) tea.Cmd {
+		mode := CommandEmbedded
+		if isInteractiveCommand(finalCmd) {
+			mode = CommandInteractive
+		}
+		return m.runCommand(CommandSpec{
+			Command: wrapForTarget(finalCmd, m.execTarget(), envNames(env)...),
+			Mode:    mode,
+			Env:     env,
+		})
+	})
+}
+
+// renderFollowUps renders the follow-ups line under a failed command's
+// terminal pane
+func (m model) renderFollowUps() string {
+	ups := m.failureFollowUps()
+	if len(ups) == 0 {
+		return ""
+	}
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	labelStyle := lipgloss.NewStyle().Foreground(subtle).Italic(true)
+
+	parts := []string{labelStyle.Render("Try:")}
+	for i, up := range ups {
+		parts = append(parts, keyStyle.Render(fmt.Sprint(i+1))+" "+textStyle.Render(truncate(up.label, 40)))
+	}
+	return " " + strings.Join(parts, "  ")
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParseCommandsOnFail(t *testing.T) {
+	cmds := parseCommands("`kubectl apply -f app.yaml` Deploy ^run ^onfail:`kubectl diff -f app.yaml` ^onfail:make-rollback ^tag:k8s")
+	if len(cmds) != 1 {
+		t.Fatalf("parseCommands() = %+v", cmds)
+	}
+	c := cmds[0]
+	if c.description != "Deploy" || len(c.onFail) != 2 || c.onFail[0] != "kubectl diff -f app.yaml" || c.onFail[1] != "make-rollback" {
+		t.Errorf("command = %+v", c)
+	}
+}
+
+func TestVerboseCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+		ok      bool
+	}{
+		{"curl https://example.com", "curl https://example.com -v", true},
+		{"git push  origin main", "git push origin main -v", true},
+		{"make -v", "", false},
+		{"ls | grep x", "", false},
+		{"docker exec -it web sh -c 'ls'", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := verboseCommand(tt.command)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("verboseCommand(%q) = %q, %v, want %q, %v", tt.command, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFailureFollowUps(t *testing.T) {
+	m := model{width: 120, height: 40, currentView: viewDetail}
+	m.term = EmbeddedTerm{
+		active:  true,
+		command: "curl https://example.com",
+		run:     commandRun{command: "curl https://example.com", onFail: []string{"ping example.com"}},
+	}
+	if ups := m.failureFollowUps(); ups != nil {
+		t.Fatalf("follow-ups while running: %+v", ups)
+	}
+
+	m.term.exited = true
+	m.term.exitErr = errors.New("exit status 6")
+	var labels []string
+	for _, up := range m.failureFollowUps() {
+		labels = append(labels, up.label)
+	}
+	if want := "ask AI about the error,re-run with -v,run ping example.com"; strings.Join(labels, ",") != want {
+		t.Errorf("follow-ups = %q, want %q", labels, want)
+	}
+	if line := ansi.Strip(m.renderFollowUps()); !strings.Contains(line, "2 re-run with -v") {
+		t.Errorf("follow-ups line = %q", line)
+	}
+
+	// Without AI only the commands are offered, and nothing after a success
+	m.config.Policy.AIDisabled = true
+	if ups := m.failureFollowUps(); len(ups) != 2 {
+		t.Errorf("%d follow-ups with AI disabled, want 2", len(ups))
+	}
+	m.term.exitErr = nil
+	if ups := m.failureFollowUps(); ups != nil {
+		t.Errorf("follow-ups after success: %+v", ups)
+	}
+}
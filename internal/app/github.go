@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/github"
+)
+
+// githubPullRequestsSection lists the user's open pull requests in the
+// current repository with commands to open and check them out
+func githubPullRequestsSection(ctx context.Context, cfg config.Config, _ string, _ section) (string, error) {
+	items, repo, err := githubSearch(ctx, cfg, github.Client.MyPullRequests)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return fmt.Sprintf("No open pull requests by you in %s.", repo), nil
+	}
+
+	var b strings.Builder
+	for _, pr := range items {
+		title := issueLineTitle(pr)
+		if pr.Draft {
+			title += " (draft)"
+		}
+		fmt.Fprintf(&b, "`gh pr view %d --web` #%d %s · %s ^run\n", pr.Number, pr.Number, title, formatTimeAgo(pr.UpdatedAt))
+		fmt.Fprintf(&b, "`gh pr checkout %d` check out #%d ^run\n", pr.Number, pr.Number)
+	}
+	return b.String(), nil
+}
+
+// githubIssuesSection lists the open issues in the current repository
+// assigned to the user
+func githubIssuesSection(ctx context.Context, cfg config.Config, _ string, _ section) (string, error) {
+	items, repo, err := githubSearch(ctx, cfg, github.Client.AssignedIssues)
+	if err != nil {
+		return "", err
+	}
+	if len(items) == 0 {
+		return fmt.Sprintf("No open issues assigned to you in %s.", repo), nil
+	}
+
+	var b strings.Builder
+	for _, issue := range items {
+		fmt.Fprintf(&b, "`gh issue view %d --web` #%d %s · %s ^run\n", issue.Number, issue.Number, issueLineTitle(issue), formatTimeAgo(issue.UpdatedAt))
+	}
+	return b.String(), nil
+}
+
+// githubSearch resolves the repository and token and runs search
+func githubSearch(ctx context.Context, cfg config.Config, search func(github.Client, context.Context, github.Repo) ([]github.Issue, error)) ([]github.Issue, github.Repo, error) {
+	repo, err := github.CurrentRepo(ctx)
+	if err != nil {
+		return nil, repo, err
+	}
+	token, err := github.Token(ctx, cfg.Integrations.GitHub.Token)
+	if err != nil {
+		return nil, repo, err
+	}
+	items, err := search(github.Client{Token: token, BaseURL: cfg.Integrations.GitHub.APIURL}, ctx, repo)
+	return items, repo, err
+}
+
+// issueLineTitle makes a title safe to use as a command description
+func issueLineTitle(issue github.Issue) string {
+	return strings.NewReplacer("`", "'", "^", "", "\n", " ").Replace(issue.Title)
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/secrets"
+)
+
+// Handing a command off quits skitz and leaves the command at the shell
+// prompt, unrun, for editing with the shell's own completions. The shell
+// function from skitz shell-init reads it from $SKITZ_HANDOFF_FILE; inside
+// tmux it is typed into the pane with send-keys.
+
+// handoffFileEnv names the file the shell-init function reads the command
+// from after skitz exits
+const handoffFileEnv = "SKITZ_HANDOFF_FILE"
+
+// errNoHandoff is shown when there is no way to reach the shell prompt
+var errNoHandoff = errors.New(`set up shell integration with eval "$(skitz shell-init zsh)" or run skitz in tmux`)
+
+// handoffAvailable reports whether a command can be left at the prompt
+func handoffAvailable() bool {
+	return os.Getenv(handoffFileEnv) != "" || os.Getenv("TMUX") != ""
+}
+
+// handOff quits skitz so command is waiting at the shell prompt. Commands
+// the organization policy blocks are refused, as are commands with
+// {{secret:NAME}} placeholders, which only skitz can resolve.
+func (m *model) handOff(command string) tea.Cmd {
+	if err := m.config.Policy.CheckCommand(command); err != nil {
+		slog.Warn("handoff blocked", "command", command, "error", err)
+		return m.showNotification("🔒", err.Error(), "error")
+	}
+	if names := secrets.Names(command); len(names) > 0 {
+		refs := make([]string, len(names))
+		for i, name := range names {
+			refs[i] = "$" + secrets.EnvName(name)
+		}
+		return m.showNotification("⚠", fmt.Sprintf("Can't hand off a command with secrets: run it from skitz, or export %s and use it in place of {{secret:%s}}", strings.Join(refs, ", "), names[0]), "warning")
+	}
+	if !handoffAvailable() {
+		return m.showNotification("⚠", "Can't reach the shell: "+errNoHandoff.Error(), "warning")
+	}
+	m.handoff = command
+	return tea.Quit
+}
+
+// handOffToShell puts command at the prompt of the shell skitz was started
+// from
+func handOffToShell(command string) error {
+	if path := os.Getenv(handoffFileEnv); path != "" {
+		if err := os.WriteFile(path, []byte(command), 0600); err != nil {
+			return fmt.Errorf("failed to hand off command: %w", err)
+		}
+		return nil
+	}
+	if os.Getenv("TMUX") != "" {
+		args := []string{"send-keys", "-l"}
+		if pane := os.Getenv("TMUX_PANE"); pane != "" {
+			args = append(args, "-t", pane)
+		}
+		if out, err := exec.Command("tmux", append(args, "--", command)...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to hand off command: tmux send-keys: %w: %s", err, out)
+		}
+		return nil
+	}
+	return errNoHandoff
+}
+
+// shellInitScripts define a skitz function that runs skitz and puts a
+// handed-off command at the prompt. zsh edits it in place; bash has no way
+// to fill the prompt, so it goes in the history for ↑.
+var shellInitScripts = map[string]string{
+	"zsh": `skitz() {
+  local handoff ret
+  handoff=$(mktemp) || return
+  SKITZ_HANDOFF_FILE=$handoff command skitz "$@"
+  ret=$?
+  [[ -s $handoff ]] && print -z -- "$(<$handoff)"
+  rm -f -- "$handoff"
+  return $ret
+}
+`,
+	"bash": `skitz() {
+  local handoff ret
+  handoff=$(mktemp) || return
+  SKITZ_HANDOFF_FILE=$handoff command skitz "$@"
+  ret=$?
+  if [[ -s $handoff ]]; then
+    history -s -- "$(<"$handoff")"
+    echo "skitz: press ↑ to edit the command"
+  fi
+  rm -f -- "$handoff"
+  return $ret
+}
+`,
+}
+
+// cliShellInit prints the shell function for handing off commands, for
+// eval in the shell's rc file.
+func cliShellInit(args []string, opts CLIOptions) error {
+	fs := newCLIFlagSet("shell-init", &opts)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	script, ok := shellInitScripts[fs.Arg(0)]
+	if fs.NArg() != 1 || !ok {
+		return errors.New("usage: skitz shell-init <zsh|bash>")
+	}
+	_, err := fmt.Fprint(opts.Out, script)
+	return err
+}
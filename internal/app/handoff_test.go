@@ -0,0 +1,77 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestHandOff(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv(handoffFileEnv, "")
+
+	m := model{}
+	if cmd := m.handOff("kubectl get pods"); m.handoff != "" || cmd == nil {
+		t.Fatal("handed off without shell integration or tmux")
+	}
+
+	path := filepath.Join(t.TempDir(), "handoff")
+	t.Setenv(handoffFileEnv, path)
+	cmd := m.handOff("kubectl get pods")
+	if _, ok := cmd().(tea.QuitMsg); !ok || m.handoff != "kubectl get pods" {
+		t.Fatalf("handoff = %q, want skitz to quit with the command", m.handoff)
+	}
+
+	if err := handOffToShell(m.handoff); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "kubectl get pods" {
+		t.Errorf("handoff file = %q, %v", data, err)
+	}
+}
+
+func TestHandOffRefused(t *testing.T) {
+	t.Setenv(handoffFileEnv, filepath.Join(t.TempDir(), "handoff"))
+	policy, err := config.ParsePolicy([]byte("commands:\n  deny: ['rm -rf']\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{}
+	m.config.Policy = policy
+
+	tests := []struct {
+		command string
+		notice  string
+	}{
+		{"rm -rf /tmp/build", "blocked by organization policy"},
+		{"curl -H 'Authorization: {{secret:TOKEN}}' api", "export $SKITZ_SECRET_TOKEN"},
+	}
+	for _, tt := range tests {
+		m.handOff(tt.command)
+		if m.handoff != "" {
+			t.Errorf("handed off %q", tt.command)
+		}
+		if n := m.notifications[len(m.notifications)-1]; !strings.Contains(n.Message, tt.notice) {
+			t.Errorf("handOff(%q) notification = %q, want %q", tt.command, n.Message, tt.notice)
+		}
+	}
+}
+
+func TestShellInit(t *testing.T) {
+	var out bytes.Buffer
+	if err := RunCLI([]string{"shell-init", "zsh"}, CLIOptions{Out: &out}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "SKITZ_HANDOFF_FILE=") || !strings.Contains(out.String(), "print -z") {
+		t.Errorf("zsh script:\n%s", out.String())
+	}
+	if err := RunCLI([]string{"shell-init", "tcsh"}, CLIOptions{Out: &out}); err == nil {
+		t.Error("shell-init accepted an unsupported shell")
+	}
+}
@@ -0,0 +1,166 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpGroup is a titled list of key bindings in the help overlay
+type helpGroup struct {
+	title    string
+	bindings []key.Binding
+}
+
+// helpOverlay lists the keys for the view it was opened from
+type helpOverlay struct {
+	groups []helpGroup
+}
+
+// helpAvailable reports whether ? opens help rather than being typed or
+// handled by a form. In the palette and Ask panel it only does while the
+// input is empty.
+func (m *model) helpAvailable() bool {
+	switch {
+	case m.palette.State != PaletteStateIdle:
+		return m.palette.State == PaletteStateSearching && m.palette.Query == ""
+	case m.askPanel != nil && m.askPanel.Active:
+		return m.askPanel.Input == "" && m.askPanel.PendingApproval == "" &&
+			m.askPanel.SectionForm == nil && m.askPanel.ContextForm == nil
+	case m.currentView == viewDetail && m.viewReady:
+		return m.cmdEditor == nil && m.kubeSwitcher == nil && m.tableView == nil
+	case m.hasActiveWizard():
+		return false
+	case m.dashboardTab == 2:
+		return m.agentViewMode == 0 && (m.savedAgentWizard == nil || m.savedAgentWizard.InputForm == nil)
+	}
+	return true
+}
+
+// openHelp shows the keys that work in the current view
+func (m *model) openHelp() {
+	m.help = &helpOverlay{groups: m.helpGroups()}
+}
+
+// helpGroups returns the bindings handled in the current view, in the
+// order the key handlers check them
+func (m *model) helpGroups() []helpGroup {
+	var groups []helpGroup
+	if m.term.active {
+		g := helpGroup{title: "Terminal", bindings: []key.Binding{terminalKeys.Focus, terminalKeys.Close}}
+		if m.term.replay != nil {
+			g.bindings = append(g.bindings, terminalKeys.Export)
+		} else {
+			g.bindings = append(g.bindings, terminalKeys.Pager)
+		}
+		if m.term.job != nil {
+			g.bindings = append(g.bindings, terminalKeys.Job)
+		}
+		if len(m.failureFollowUps()) > 0 {
+			g.bindings = append(g.bindings, terminalKeys.FollowUp)
+		}
+		groups = append(groups, g)
+	}
+
+	switch {
+	case m.pager != nil:
+		k := pagerKeys
+		groups = append(groups, helpGroup{title: "Pager", bindings: []key.Binding{k.Search, k.Next, k.Prev, k.Top, k.Bottom, k.Copy, k.Close}})
+	case m.recentSwitcher != nil:
+		k := recentKeys
+		groups = append(groups, helpGroup{title: "Recent Resources", bindings: []key.Binding{k.Up, k.Down, k.Jump, k.Number, k.Close}})
+	case m.palette.State != PaletteStateIdle:
+		k := paletteKeys
+		groups = append(groups, helpGroup{title: "Command Palette", bindings: []key.Binding{
+			k.Run, k.Up, k.Down, k.Mark, k.Copy, k.AI, k.Pin, k.Edit, k.Example, k.Close, k.Quit,
+		}})
+	case m.askPanel != nil && m.askPanel.Active:
+		k := askKeys
+		bindings := []key.Binding{k.Ask, k.Generate, k.Attach, k.Detach}
+		if m.askPanel.GeneratedCmd != "" {
+			bindings = append(bindings, k.Run, k.Handoff, k.Add)
+		}
+		groups = append(groups, helpGroup{title: "Ask AI", bindings: append(bindings, k.Close)})
+	case m.currentView == viewDetail:
+		k := detailKeys
+		groups = append(groups,
+			helpGroup{title: "Commands", bindings: []key.Binding{k.Up, k.Down, k.Count, k.Run, k.RunWith, k.Detach, k.Copy, k.Handoff, k.Edit, k.Ask, k.Target}},
+			helpGroup{title: "Sections", bindings: []key.Binding{k.NextSection, k.PrevSection, k.Cycle, k.Section, k.Tag, k.Sort, k.Refresh, k.Community, k.Upstream, k.Summary}},
+			helpGroup{title: "Sharing", bindings: []key.Binding{k.CopySection, k.CopyAll, k.Save}},
+			helpGroup{title: "Scrolling", bindings: []key.Binding{k.PageDown, k.PageUp, k.Top, k.Bottom}},
+			helpGroup{title: "Leaving", bindings: []key.Binding{k.Escape, k.Back, k.Quit}},
+		)
+	default:
+		groups = append(groups, helpGroup{
+			title:    "Dashboard · " + dashboardTabNames[m.dashboardTab],
+			bindings: dashboardKeys.forTab(m.dashboardTab),
+		})
+	}
+
+	return append(groups, helpGroup{title: "Everywhere", bindings: []key.Binding{globalKeys.Palette, globalKeys.Recent, globalKeys.Help, globalKeys.Suspend}})
+}
+
+// renderHelpOverlay renders the help overlay full screen, with groups in
+// as many columns as the height requires
+func (m model) renderHelpOverlay() string {
+	titleStyle := lipgloss.NewStyle().Foreground(primary).Bold(true)
+	groupStyle := lipgloss.NewStyle().Foreground(secondary).Bold(true)
+	keyStyle := lipgloss.NewStyle().Foreground(white).Bold(true)
+	descStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	hintStyle := lipgloss.NewStyle().Foreground(subtle)
+
+	var blocks []string
+	for _, g := range m.help.groups {
+		keyWidth := 0
+		for _, b := range g.bindings {
+			if b.Enabled() {
+				keyWidth = max(keyWidth, lipgloss.Width(b.Help().Key))
+			}
+		}
+		lines := []string{groupStyle.Render(g.title)}
+		for _, b := range g.bindings {
+			if !b.Enabled() {
+				continue
+			}
+			h := b.Help()
+			lines = append(lines, keyStyle.Width(keyWidth+2).Render(h.Key)+descStyle.Render(h.Desc))
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+
+	// Fill columns top to bottom
+	maxH := max(m.height-8, 10)
+	var columns []string
+	var column []string
+	height := 0
+	for _, block := range blocks {
+		h := lipgloss.Height(block) + 1
+		if height > 0 && height+h > maxH {
+			columns = append(columns, strings.Join(column, "\n\n"))
+			column, height = nil, 0
+		}
+		column = append(column, block)
+		height += h
+	}
+	if len(column) > 0 {
+		columns = append(columns, strings.Join(column, "\n\n"))
+	}
+	for i := range columns[:len(columns)-1] {
+		columns[i] = lipgloss.NewStyle().PaddingRight(4).Render(columns[i])
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render("Keyboard Shortcuts"),
+		"",
+		lipgloss.JoinHorizontal(lipgloss.Top, columns...),
+		"",
+		hintStyle.Render("press any key to close"),
+	)
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primary).
+		Padding(1, 3).
+		Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHelpOverlay(t *testing.T) {
+	question := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")}
+
+	m := &model{width: 120, height: 40}
+	m.handleKeyMsg(question)
+	if m.help == nil {
+		t.Fatal("? did not open help on the dashboard")
+	}
+	view := m.renderHelpOverlay()
+	for _, want := range []string{"Dashboard · Resources", "edit the resource", "command palette"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("help is missing %q", want)
+		}
+	}
+	m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.help != nil {
+		t.Fatal("help still open after a key")
+	}
+
+	// Deployments keys only show on their tab
+	m.dashboardTab = deploymentsTab
+	m.openHelp()
+	if view := m.renderHelpOverlay(); !strings.Contains(view, "tear down the deployment") || strings.Contains(view, "edit the resource") {
+		t.Error("help lists keys for another tab")
+	}
+	m.help = nil
+
+	// In the Ask panel, ? is typed once there is input
+	m.askPanel = &AskPanel{Active: true, Input: "why"}
+	m.handleKeyMsg(question)
+	if m.help != nil || m.askPanel.Input != "why?" {
+		t.Errorf("help = %v, input = %q", m.help, m.askPanel.Input)
+	}
+}
+
+func TestDashboardKeysHaveHelp(t *testing.T) {
+	for tab := range dashboardTabNames {
+		for _, b := range dashboardKeys.forTab(tab) {
+			if b.Help().Key == "" || b.Help().Desc == "" || len(b.Keys()) == 0 {
+				t.Errorf("tab %d: binding %v has no help", tab, b.Keys())
+			}
+		}
+	}
+}
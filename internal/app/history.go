@@ -0,0 +1,172 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// historyOutput returns the part of a run's output kept in its history
+// entry: the first and last half of history.output_limit bytes, with
+// secrets redacted
+func (m *model) historyOutput(output string) string {
+	limit := m.config.History.OutputLimit
+	if limit <= 0 || strings.TrimSpace(output) == "" {
+		return ""
+	}
+	return ai.NewAttachment("", m.redactSecrets(output), limit, ai.KeepEnds).Content
+}
+
+// getHistoryPaletteItems returns the action listing past runs, once there
+// are any
+func (m *model) getHistoryPaletteItems() []PaletteItem {
+	if len(m.history) == 0 {
+		return nil
+	}
+	return []PaletteItem{{
+		ID:       "action:command_history",
+		Icon:     "⏱",
+		Title:    "Command History",
+		Subtitle: "Review past runs, their exit codes and output",
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			m.showCommandHistory()
+			return nil
+		},
+	}}
+}
+
+// showCommandHistory replaces the palette list with one item per history
+// entry. Selecting one shows its details.
+func (m *model) showCommandHistory() {
+	var items []PaletteItem
+	for i, entry := range m.history {
+		icon := "✓"
+		if !entry.Success {
+			icon = "✗"
+		}
+		subtitle := formatTimeAgo(entry.Timestamp)
+		if entry.Tool != "" {
+			subtitle = entry.Tool + " · " + subtitle
+		}
+		if entry.DurationMS > 0 {
+			subtitle += " · " + formatDuration(time.Duration(entry.DurationMS)*time.Millisecond)
+		}
+		items = append(items, PaletteItem{
+			ID:       fmt.Sprintf("history:%d", i),
+			Icon:     icon,
+			Title:    entry.Command,
+			Subtitle: subtitle,
+			Category: "history",
+			Commands: []string{entry.Command},
+			Handler: func(m *model) tea.Cmd {
+				m.showPaletteResult(truncate(entry.Command, 60), m.historyDetail(entry), !entry.Success)
+				return nil
+			},
+		})
+	}
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
+}
+
+// historyDetail returns a history entry as markdown for the result pane
+func (m model) historyDetail(entry config.HistoryEntry) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", codeBlock("sh", entry.Command))
+	if entry.Tool != "" {
+		fmt.Fprintf(&b, "- **Resource:** %s\n", entry.Tool)
+	}
+	fmt.Fprintf(&b, "- **Ran:** %s\n", entry.Timestamp.Format("2006-01-02 15:04:05"))
+	switch {
+	case entry.ExitCode != 0:
+		fmt.Fprintf(&b, "- **Exit code:** %d\n", entry.ExitCode)
+	case entry.Success:
+		b.WriteString("- **Exit code:** 0\n")
+	default:
+		b.WriteString("- **Result:** failed\n")
+	}
+	if entry.DurationMS > 0 {
+		fmt.Fprintf(&b, "- **Duration:** %s\n", formatDuration(time.Duration(entry.DurationMS)*time.Millisecond))
+	}
+
+	b.WriteString("\n### Output\n\n")
+	switch {
+	case entry.Output != "":
+		b.WriteString(codeBlock("", strings.TrimRight(entry.Output, "\n")))
+	case m.config.History.OutputLimit <= 0:
+		b.WriteString("*No output was kept. Set `history.output_limit` in config.yaml to keep it for new runs.*")
+	default:
+		b.WriteString("*No output was captured for this run.*")
+	}
+	return b.String() + "\n"
+}
+
+// codeBlock fences text, with more backticks than text contains
+func codeBlock(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + text + "\n" + fence
+}
+
+// historyLoadedMsg carries the command and agent history loaded in the
+// background at startup
+type historyLoadedMsg struct {
+	history      []config.HistoryEntry
+	agentHistory []config.AgentInteraction
+}
+
+// loadHistoryCmd loads the history off the UI goroutine
+func loadHistoryCmd() tea.Cmd {
+	return func() tea.Msg {
+		return historyLoadedMsg{
+			history:      config.LoadHistory(),
+			agentHistory: config.LoadAgentHistory(),
+		}
+	}
+}
+
+// handleHistoryLoaded puts the loaded history behind any entries added
+// while it loaded, saving the merged history when there were some
+func (m *model) handleHistoryLoaded(msg historyLoadedMsg) tea.Cmd {
+	m.historyPending = false
+	if added := len(m.history); added > 0 {
+		m.history = append(m.history, msg.history...)
+		if maxItems := m.config.History.MaxItems; len(m.history) > maxItems {
+			m.history = m.history[:maxItems]
+		}
+		if m.config.History.Persist {
+			config.SaveHistory(m.history, m.config.History.MaxItems)
+		}
+	} else {
+		m.history = msg.history
+	}
+	if len(m.agentHistory) > 0 {
+		m.agentHistory = append(m.agentHistory, msg.agentHistory...)
+		m.pruneAgentHistory()
+		config.SaveAgentHistory(m.agentHistory)
+	} else {
+		m.agentHistory = msg.agentHistory
+		if m.pruneAgentHistory() {
+			config.SaveAgentHistory(m.agentHistory)
+		}
+	}
+	return m.startupLoadedCmd()
+}
+
+// startupLoadedCmd indexes the resources and history for Ask AI once both
+// have loaded
+func (m *model) startupLoadedCmd() tea.Cmd {
+	if m.resourcesPending || m.historyPending {
+		return nil
+	}
+	return m.updateRAGIndexCmd()
+}
@@ -0,0 +1,237 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yarlson/tap"
+
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/shellhistory"
+)
+
+// historyImportLimit is how many command clusters are offered for import
+const historyImportLimit = 40
+
+// historyImportDescription is used when no AI provider can describe a
+// command
+const historyImportDescription = "From shell history"
+
+// importHistoryCmd implements tea.ExecCommand for importing shell history
+// into a resource
+type importHistoryCmd struct {
+	cfg       config.Config
+	resources []resource
+	offline   bool // skip AI descriptions
+	success   bool
+}
+
+func (c *importHistoryCmd) Run() error {
+	ctx := context.Background()
+
+	fmt.Print("\033[H\033[2J")
+
+	tap.Intro("📥 Import Shell History")
+
+	sources := shellhistory.Sources()
+	if len(sources) == 0 {
+		tap.Box("No shell history found in ~/.zsh_history, ~/.bash_history or fish_history.", "No History", tap.BoxOptions{})
+		waitForEnter()
+		return nil
+	}
+
+	var commands []string
+	for _, src := range sources {
+		cmds, err := shellhistory.Read(src)
+		if err != nil {
+			slog.Warn("skipping shell history", "path", src.Path, "error", err)
+			continue
+		}
+		for _, cmd := range cmds {
+			// Backticks cannot be stored in a resource command line
+			if !strings.Contains(cmd, "`") {
+				commands = append(commands, cmd)
+			}
+		}
+	}
+
+	clusters := shellhistory.Frequent(commands, historyImportLimit)
+	if len(clusters) == 0 {
+		tap.Box("No commands found in shell history.", "No History", tap.BoxOptions{})
+		waitForEnter()
+		return nil
+	}
+
+	options := make([]tap.SelectOption[string], 0, len(clusters))
+	for _, cl := range clusters {
+		hint := fmt.Sprintf("×%d", cl.Count)
+		if cl.Variants > 1 {
+			hint += fmt.Sprintf(", %d variants", cl.Variants)
+		}
+		options = append(options, tap.SelectOption[string]{Value: cl.Command, Label: truncate(cl.Command, 70), Hint: hint})
+	}
+	selected := tap.MultiSelect(ctx, tap.MultiSelectOptions[string]{
+		Message: "Select commands to import (space to toggle):",
+		Options: options,
+	})
+	if len(selected) == 0 {
+		tap.Cancel("Cancelled")
+		return nil
+	}
+
+	res, ok := c.selectResource(ctx)
+	if !ok {
+		tap.Cancel("Cancelled")
+		return nil
+	}
+	filePath, content := resourceFileContent(res)
+
+	headingIdx, ok := selectHeading(ctx, content)
+	if !ok {
+		tap.Cancel("Cancelled")
+		return nil
+	}
+
+	descriptions := c.describe(selected)
+
+	entries := make([]string, len(selected))
+	for i, cmd := range selected {
+		entries[i] = fmt.Sprintf("`%s` %s ^run", cmd, descriptions[i])
+	}
+	tap.Box(strings.Join(entries, "\n"), "Add to "+res.name, tap.BoxOptions{})
+
+	if !tap.Confirm(ctx, tap.ConfirmOptions{Message: fmt.Sprintf("Add %d commands?", len(entries))}) {
+		tap.Cancel("Cancelled")
+		return nil
+	}
+
+	if err := os.MkdirAll(config.ResourcesDir, 0755); err != nil {
+		tap.Box(err.Error(), "Error", tap.BoxOptions{})
+		waitForEnter()
+		return nil
+	}
+	content = insertUnderHeading(content, headingIdx, strings.Join(entries, "\n"))
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		tap.Box(err.Error(), "Error", tap.BoxOptions{})
+		waitForEnter()
+		return nil
+	}
+	if res.embedded {
+		if err := copyMissingResourceFiles(resourceSourceFiles(res)); err != nil {
+			tap.Box(err.Error(), "Error", tap.BoxOptions{})
+			waitForEnter()
+			return nil
+		}
+	}
+
+	tap.Outro(fmt.Sprintf("✓ Added %d commands to %s", len(entries), res.name))
+	c.success = true
+	return nil
+}
+
+// selectResource asks which resource to add the commands to
+func (c *importHistoryCmd) selectResource(ctx context.Context) (*resource, bool) {
+	options := make([]tap.SelectOption[string], 0, len(c.resources))
+	for _, res := range c.resources {
+		hint := ""
+		if res.embedded {
+			hint = "built-in, copied to your resources"
+		}
+		options = append(options, tap.SelectOption[string]{Value: res.name, Label: res.name, Hint: hint})
+	}
+
+	name := tap.Select(ctx, tap.SelectOptions[string]{
+		Message: "Add to resource:",
+		Options: options,
+	})
+	for i := range c.resources {
+		if c.resources[i].name == name {
+			return &c.resources[i], true
+		}
+	}
+	return nil, false
+}
+
+// selectHeading asks which section of content to append to when it has
+// more than one heading. It returns -1 for a file without headings.
+func selectHeading(ctx context.Context, content string) (int, bool) {
+	headings := findHeadings(content)
+	if len(headings) <= 1 {
+		return len(headings) - 1, true
+	}
+
+	options := make([]tap.SelectOption[string], 0, len(headings))
+	for i, h := range headings {
+		label := strings.Repeat("  ", h.level-1) + strings.Repeat("#", h.level) + " " + h.title
+		options = append(options, tap.SelectOption[string]{Value: strconv.Itoa(i), Label: label})
+	}
+	last := strconv.Itoa(len(headings) - 1)
+	choice := tap.Select(ctx, tap.SelectOptions[string]{
+		Message:      "Add to section:",
+		Options:      options,
+		InitialValue: &last,
+	})
+	if choice == "" {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(choice)
+	return idx, err == nil
+}
+
+// describe returns a description for each command, generated by the
+// default AI provider when one is configured and skitz is online
+func (c *importHistoryCmd) describe(commands []string) []string {
+	descriptions := make([]string, len(commands))
+
+	client, err := ai.GetDefaultClient(c.cfg)
+	if err == nil && !c.offline {
+		spinner := tap.NewSpinner(tap.SpinnerOptions{})
+		spinner.Start("Generating descriptions...")
+		generated, err := client.DescribeCommands(commands)
+		if err != nil {
+			spinner.Stop("Could not generate descriptions: "+err.Error(), 1)
+		} else {
+			spinner.Stop("Descriptions generated", 0)
+			copy(descriptions, generated)
+		}
+	}
+
+	for i, d := range descriptions {
+		// ^ starts an annotation and would end the description early
+		d = strings.TrimSpace(strings.ReplaceAll(d, "^", ""))
+		if d == "" {
+			d = historyImportDescription
+		}
+		descriptions[i] = d
+	}
+	return descriptions
+}
+
+func (c importHistoryCmd) SetStdin(r io.Reader)  {}
+func (c importHistoryCmd) SetStdout(w io.Writer) {}
+func (c importHistoryCmd) SetStderr(w io.Writer) {}
+
+// runImportHistory starts the shell history import wizard and reloads
+// resources when it finishes
+func (m *model) runImportHistory() tea.Cmd {
+	ic := &importHistoryCmd{
+		cfg:       m.config,
+		resources: append([]resource(nil), m.resources...),
+		offline:   m.offline,
+	}
+	m.pendingResourceReload = true
+	return tea.Exec(ic, func(err error) tea.Msg {
+		return commandDoneMsg{
+			command: "import-history",
+			tool:    "skitz",
+			success: ic.success,
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/hooks"
+)
+
+func TestHistoryOutput(t *testing.T) {
+	m := model{}
+	m.config.History = config.HistoryConfig{Enabled: true, MaxItems: 10}
+
+	output := "start\n" + strings.Repeat("x", 100) + "\nend\n"
+	next, _ := m.Update(commandDoneMsg{command: "make", output: output, hook: &hooks.Event{Duration: time.Second}})
+	m = next.(model)
+	if m.history[0].Output != "" {
+		t.Errorf("output kept without history.output_limit: %q", m.history[0].Output)
+	}
+
+	m.config.History.OutputLimit = 20
+	next, _ = m.Update(commandDoneMsg{command: "make", output: output, hook: &hooks.Event{ExitCode: 2, Duration: time.Second}})
+	m = next.(model)
+	kept := m.history[0].Output
+	if !strings.HasPrefix(kept, "start") || !strings.HasSuffix(kept, "end\n") || len(kept) > 40 {
+		t.Errorf("kept output = %q, want its first and last 10 bytes", kept)
+	}
+
+	detail := m.historyDetail(m.history[0])
+	for _, want := range []string{"```sh\nmake\n```", "**Exit code:** 2", "**Duration:** 1s", "start"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail is missing %q:\n%s", want, detail)
+		}
+	}
+}
+
+func TestShowCommandHistory(t *testing.T) {
+	m := model{history: []config.HistoryEntry{
+		{Command: "git push", Tool: "git", Success: true, Timestamp: time.Now()},
+		{Command: "make test", Success: false, ExitCode: 1, Timestamp: time.Now()},
+	}}
+	if len(m.getHistoryPaletteItems()) != 1 {
+		t.Fatal("no Command History action with history")
+	}
+
+	m.showCommandHistory()
+	if len(m.palette.Filtered) != 2 || m.palette.Filtered[1].Icon != "✗" {
+		t.Fatalf("history items = %+v", m.palette.Filtered)
+	}
+	m.palette.Filtered[1].Handler(&m)
+	if m.palette.State != PaletteStateShowingResult || !m.palette.ResultFailed {
+		t.Errorf("state = %v, failed = %v after selecting a failed run", m.palette.State, m.palette.ResultFailed)
+	}
+}
+
+func TestCodeBlock(t *testing.T) {
+	if got := codeBlock("", "a ``` b"); got != "````\na ``` b\n````" {
+		t.Errorf("codeBlock() = %q", got)
+	}
+}
+
+func TestStartupLoad(t *testing.T) {
+	oldDataDir, oldResourcesDir := config.DataDir, config.ResourcesDir
+	config.DataDir, config.ResourcesDir = t.TempDir(), t.TempDir()
+	defer func() { config.DataDir, config.ResourcesDir = oldDataDir, oldResourcesDir }()
+
+	config.SaveHistory([]config.HistoryEntry{{Command: "docker ps"}}, 0)
+
+	m := model{resourcesPending: true, historyPending: true, startResource: "docker"}
+	m.config.History = config.HistoryConfig{Enabled: true, Persist: true, MaxItems: 10}
+
+	// A run finishing before the history loads is kept in front of it
+	m.addHistory(config.HistoryEntry{Command: "make"})
+	m.handleHistoryLoaded(loadHistoryCmd()().(historyLoadedMsg))
+	if len(m.history) != 2 || m.history[0].Command != "make" || m.history[1].Command != "docker ps" {
+		t.Errorf("history = %+v, want make then docker ps", m.history)
+	}
+	if saved := config.LoadHistory(); len(saved) != 2 {
+		t.Errorf("saved history = %+v, want the merged history", saved)
+	}
+
+	m.handleResourcesLoaded(loadResourcesCmd()().(resourcesLoadedMsg))
+	if m.resourcesPending || len(m.resources) == 0 {
+		t.Fatalf("resources not loaded: pending = %v, %d resources", m.resourcesPending, len(m.resources))
+	}
+	if m.currentView != viewDetail || m.resources[m.resCursor].name != "docker" {
+		t.Errorf("start resource not opened: view = %v, resource = %q", m.currentView, m.resources[m.resCursor].name)
+	}
+}
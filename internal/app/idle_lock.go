@@ -0,0 +1,217 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	overlay "github.com/rmhubbert/bubbletea-overlay"
+)
+
+// lock: idle_after in config.yaml locks skitz after that long without a
+// keypress, so an unattended terminal can't be used to run the commands in
+// its runbooks. The screen is blurred until a key is pressed and unlocking
+// is confirmed with y; with lock.command set, that command, e.g. one asking
+// for the user's password, also has to succeed. Lock Now in the palette
+// locks right away.
+
+// idleCheckMsg is sent when skitz may have been idle for lock.idle_after
+type idleCheckMsg struct{}
+
+// unlockMsg carries how the unlock command exited
+type unlockMsg struct {
+	err error
+}
+
+// idleCheckCmd checks for idleness after d
+func idleCheckCmd(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return idleCheckMsg{}
+	})
+}
+
+// initIdleCheck schedules the first idle check, for Init
+func (m model) initIdleCheck() tea.Cmd {
+	if !m.config.Lock.Enabled() {
+		return nil
+	}
+	return idleCheckCmd(m.config.Lock.IdleAfter)
+}
+
+// noteInput records a keypress and starts checking for idleness if it
+// stopped
+func (m *model) noteInput() tea.Cmd {
+	m.lastInput = time.Now()
+	return m.startIdleCheck()
+}
+
+// startIdleCheck schedules an idle check unless one is scheduled, skitz
+// is locked or the lock is off
+func (m *model) startIdleCheck() tea.Cmd {
+	if m.idleChecking || m.locked || !m.config.Lock.Enabled() {
+		return nil
+	}
+	m.idleChecking = true
+	return idleCheckCmd(m.config.Lock.IdleAfter - time.Since(m.lastInput))
+}
+
+// handleIdleCheck locks skitz once it has been idle for lock.idle_after,
+// or checks again when that will be
+func (m *model) handleIdleCheck() tea.Cmd {
+	m.idleChecking = false
+	if m.locked || !m.config.Lock.Enabled() {
+		return nil
+	}
+	idle := time.Since(m.lastInput)
+	if idle < m.config.Lock.IdleAfter {
+		m.idleChecking = true
+		return idleCheckCmd(m.config.Lock.IdleAfter - idle)
+	}
+	m.lock()
+	return nil
+}
+
+// lock hides the screen until unlocked
+func (m *model) lock() {
+	m.locked = true
+	m.lockedAt = time.Now()
+	m.unlockConfirm = false
+	m.unlockErr = ""
+	slog.Info("locked", "idle", time.Since(m.lastInput).Round(time.Second))
+}
+
+// unlock shows the screen again and restarts the idle check
+func (m *model) unlock() tea.Cmd {
+	m.locked = false
+	m.unlockConfirm = false
+	m.unlockErr = ""
+	slog.Info("unlocked", "locked_for", time.Since(m.lockedAt).Round(time.Second))
+	return m.noteInput()
+}
+
+// handleLockedKey handles a key while locked: the first key asks to
+// confirm, then y unlocks, or runs lock.command, and any other key keeps
+// skitz locked
+func (m *model) handleLockedKey(msg tea.KeyMsg) tea.Cmd {
+	if !m.unlockConfirm {
+		m.unlockConfirm = true
+		m.unlockErr = ""
+		return nil
+	}
+	m.unlockConfirm = false
+	if !strings.EqualFold(msg.String(), "y") {
+		return nil
+	}
+	if command := m.config.Lock.Command; command != "" {
+		return unlockCmd(command)
+	}
+	return m.unlock()
+}
+
+// unlockCmd suspends skitz to run command, which unlocks it by exiting 0
+func unlockCmd(command string) tea.Cmd {
+	return tea.ExecProcess(exec.Command("/bin/sh", "-c", command), func(err error) tea.Msg {
+		return unlockMsg{err: err}
+	})
+}
+
+// handleUnlock unlocks skitz when the unlock command succeeded
+func (m *model) handleUnlock(msg unlockMsg) tea.Cmd {
+	if !m.locked {
+		return nil
+	}
+	if msg.err != nil {
+		slog.Warn("unlock command failed", "command", m.config.Lock.Command, "error", msg.err)
+		m.unlockErr = "Unlock failed: " + msg.err.Error()
+		return nil
+	}
+	return m.unlock()
+}
+
+// getLockPaletteItem returns the palette action that locks skitz now
+func (m *model) getLockPaletteItem() PaletteItem {
+	return PaletteItem{
+		ID:       "action:lock",
+		Icon:     "🔒",
+		Title:    "Lock Now",
+		Subtitle: "Hide the screen until unlocked",
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			m.closePalette()
+			m.lock()
+			return nil
+		},
+	}
+}
+
+// lockPrompt is the line telling the user how to unlock
+func (m model) lockPrompt() string {
+	if !m.unlockConfirm {
+		return "Press any key to unlock"
+	}
+	if command := m.config.Lock.Command; command != "" {
+		return fmt.Sprintf("Unlock? y runs %s, any other key stays locked", command)
+	}
+	return "Unlock? y to confirm, any other key stays locked"
+}
+
+// lockStatus says when skitz locked and how long the session had run
+func (m model) lockStatus() string {
+	return fmt.Sprintf("Locked at %s · session %s",
+		m.lockedAt.Format("15:04"), m.lockedAt.Sub(m.sessionStart).Round(time.Second))
+}
+
+// renderLockScreen renders the lock box over the blurred screen
+func (m model) renderLockScreen() string {
+	if m.accessible {
+		lines := []string{"Locked", m.lockStatus(), m.lockPrompt()}
+		if m.unlockErr != "" {
+			lines = append(lines, m.unlockErr)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(primary).Render("🔒 Locked"),
+		"",
+		lipgloss.NewStyle().Foreground(subtle).Render(m.lockStatus()),
+		"",
+		m.lockPrompt(),
+	}
+	if m.unlockErr != "" {
+		lines = append(lines, "", lipgloss.NewStyle().Foreground(lipgloss.Color("203")).Render(m.unlockErr))
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primary).
+		Padding(1, 3).
+		Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
+
+	unlocked := m
+	unlocked.locked = false
+	return overlay.Composite(box, blur(unlocked.View()), overlay.Center, overlay.Center, 0, 0)
+}
+
+// blur replaces every visible character of screen with a shade, keeping
+// its shape but none of its content
+func blur(screen string) string {
+	var b strings.Builder
+	for i, line := range strings.Split(ansi.Strip(screen), "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		for _, c := range line {
+			if c == ' ' {
+				b.WriteRune(c)
+				continue
+			}
+			b.WriteString(strings.Repeat("░", ansi.StringWidth(string(c))))
+		}
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("237")).Render(b.String())
+}
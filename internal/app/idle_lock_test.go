@@ -0,0 +1,64 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestIdleLock(t *testing.T) {
+	m := model{width: 80, height: 24, resources: []resource{{name: "kubectl"}}}
+	m.config.Lock.IdleAfter = time.Minute
+	m.sessionStart = time.Now().Add(-time.Hour)
+	m.lastInput = time.Now()
+
+	if cmd := m.handleIdleCheck(); cmd == nil || m.locked {
+		t.Fatalf("locked before idle_after, locked = %v", m.locked)
+	}
+	m.lastInput = time.Now().Add(-2 * time.Minute)
+	if cmd := m.handleIdleCheck(); cmd != nil || !m.locked {
+		t.Fatalf("not locked after idle_after, locked = %v", m.locked)
+	}
+
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "Locked") || strings.Contains(view, "kubectl") {
+		t.Errorf("lock screen shows the content:\n%s", view)
+	}
+
+	key := func(s string) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+		m = next.(model)
+	}
+	key("j")
+	if !m.locked || !m.unlockConfirm {
+		t.Fatalf("first key should ask to confirm, locked = %v confirm = %v", m.locked, m.unlockConfirm)
+	}
+	key("n")
+	if !m.locked || m.unlockConfirm {
+		t.Fatalf("n should stay locked, locked = %v confirm = %v", m.locked, m.unlockConfirm)
+	}
+	key("j")
+	key("y")
+	if m.locked || time.Since(m.lastInput) > time.Second {
+		t.Fatalf("y should unlock, locked = %v", m.locked)
+	}
+
+	m.config.Lock.Command = "false"
+	m.lock()
+	m.handleLockedKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	if cmd := m.handleLockedKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}); cmd == nil || !m.locked {
+		t.Fatalf("y should run the unlock command, locked = %v", m.locked)
+	}
+	m.handleUnlock(unlockMsg{err: errors.New("exit status 1")})
+	if !m.locked || !strings.Contains(ansi.Strip(m.View()), "Unlock failed") {
+		t.Errorf("failed unlock command unlocked, locked = %v", m.locked)
+	}
+	m.handleUnlock(unlockMsg{})
+	if m.locked {
+		t.Error("successful unlock command stayed locked")
+	}
+}
@@ -0,0 +1,331 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aaronjanse/3mux/vterm"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/audit"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/jobs"
+)
+
+// & runs a command as a job: detached from skitz, with its output written
+// to a file, so that it keeps running after skitz exits. Jobs in the
+// palette follows a job's output in the terminal pane, where x stops a
+// running job or removes a finished one. Jobs left from earlier sessions
+// are announced at startup.
+
+// jobPollInterval is how often a followed job's output is read
+const jobPollInterval = 250 * time.Millisecond
+
+// jobStartedMsg reports a job started with &
+type jobStartedMsg struct {
+	job *jobs.Job
+	err error
+}
+
+// jobsFoundMsg reports the jobs found at startup
+type jobsFoundMsg struct {
+	total, running int
+}
+
+// jobDoneMsg is sent when a followed job finished and all of its output
+// was shown
+type jobDoneMsg struct {
+	id string
+}
+
+// executeDetached starts run as a job, with the secrets resolved this
+// session redacted from its output
+func (m *model) executeDetached(run commandRun) tea.Cmd {
+	redact := slices.Clone(m.secretValues)
+	return func() tea.Msg {
+		started := time.Now()
+		job, err := jobs.Start(jobs.Dir(config.DataDir), jobs.Job{
+			ID:       jobs.NewID(run.command, started),
+			Command:  run.command,
+			Resource: run.resource,
+			Shell:    run.shell.path,
+			Started:  started,
+			Redact:   redact,
+		}, run.shell.path, run.shell.args(run.command), run.shell.env)
+		return jobStartedMsg{job: job, err: err}
+	}
+}
+
+// handleJobStarted announces a started job
+func (m *model) handleJobStarted(msg jobStartedMsg) tea.Cmd {
+	if msg.err != nil {
+		slog.Error("failed to start job", "error", msg.err)
+		return m.showNotification("✗", msg.err.Error(), "error")
+	}
+	slog.Info("started job", "id", msg.job.ID, "pid", msg.job.PID)
+	audit.Record(audit.Entry{
+		Kind:   audit.KindJob,
+		Action: msg.job.Command,
+		Target: msg.job.Resource,
+		Mode:   string(CommandDetached),
+		Status: audit.StatusStarted,
+	})
+	return m.showNotification("⏳", "Running in the background: "+truncate(msg.job.Command, 40)+" · ctrl+k → Jobs", "success")
+}
+
+// jobsCheckCmd looks for jobs left from earlier sessions
+func jobsCheckCmd() tea.Cmd {
+	return func() tea.Msg {
+		list, err := jobs.List(jobs.Dir(config.DataDir))
+		if err != nil || len(list) == 0 {
+			return nil
+		}
+		msg := jobsFoundMsg{total: len(list)}
+		for _, j := range list {
+			if j.Running() {
+				msg.running++
+			}
+		}
+		return msg
+	}
+}
+
+// handleJobsFound announces the jobs found at startup
+func (m *model) handleJobsFound(msg jobsFoundMsg) tea.Cmd {
+	text := fmt.Sprintf("%d background jobs", msg.total)
+	if msg.total == 1 {
+		text = "1 background job"
+	}
+	if msg.running > 0 {
+		text += fmt.Sprintf(" (%d running)", msg.running)
+	}
+	return m.showNotification("⏳", text+" · ctrl+k → Jobs", "info")
+}
+
+// getJobsPaletteItem returns the action that lists the jobs
+func (m *model) getJobsPaletteItem() PaletteItem {
+	return PaletteItem{
+		ID:       "action:jobs",
+		Icon:     "⏳",
+		Title:    "Jobs",
+		Subtitle: "Follow, stop or remove commands run in the background with &",
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			return m.showJobs()
+		},
+	}
+}
+
+// showJobs replaces the palette list with one item per job, after one to
+// remove the finished jobs. Selecting a job follows its output.
+func (m *model) showJobs() tea.Cmd {
+	list, err := jobs.List(jobs.Dir(config.DataDir))
+	if err != nil {
+		return m.showNotification("✗", err.Error(), "error")
+	}
+	if len(list) == 0 {
+		return m.showNotification("⏳", "No jobs yet; press & on a command to run it in the background", "info")
+	}
+
+	var items, finished []PaletteItem
+	for _, j := range list {
+		item := PaletteItem{
+			ID:       "job:" + j.ID,
+			Icon:     jobIcon(j),
+			Title:    j.Command,
+			Subtitle: jobSummary(j),
+			Category: "jobs",
+			Handler: func(m *model) tea.Cmd {
+				m.closePalette()
+				return m.attachJob(j)
+			},
+		}
+		items = append(items, item)
+		if !j.Running() {
+			finished = append(finished, item)
+		}
+	}
+	if len(finished) > 0 {
+		items = append([]PaletteItem{{
+			ID:       "jobs:remove-finished",
+			Icon:     "🧹",
+			Title:    "Remove Finished Jobs",
+			Subtitle: fmt.Sprintf("Delete %d jobs that are no longer running, with their output", len(finished)),
+			Category: "jobs",
+			Handler: func(m *model) tea.Cmd {
+				return m.removeFinishedJobs(list)
+			},
+		}}, items...)
+	}
+
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
+	return nil
+}
+
+func jobIcon(j *jobs.Job) string {
+	switch {
+	case j.Running():
+		return "●"
+	case j.ExitCode == nil:
+		return "■"
+	case *j.ExitCode == 0:
+		return "✓"
+	default:
+		return "✗"
+	}
+}
+
+// jobSummary describes a job, e.g. "docker · running for 5m" or
+// "exit 1 · Jan 2 15:04"
+func jobSummary(j *jobs.Job) string {
+	var summary string
+	switch {
+	case j.Running():
+		summary = "running for " + time.Since(j.Started).Round(time.Second).String()
+	case j.ExitCode == nil:
+		summary = "stopped · started " + j.Started.Format("Jan 2 15:04")
+	default:
+		summary = fmt.Sprintf("exit %d · %s", *j.ExitCode, j.Finished.Format("Jan 2 15:04"))
+	}
+	if j.Resource != "" {
+		summary = j.Resource + " · " + summary
+	}
+	return summary
+}
+
+// removeFinishedJobs removes the jobs in list that are no longer running
+func (m *model) removeFinishedJobs(list []*jobs.Job) tea.Cmd {
+	removed := 0
+	for _, j := range list {
+		if j.Running() {
+			continue
+		}
+		if err := j.Remove(); err != nil {
+			slog.Warn("failed to remove job", "id", j.ID, "error", err)
+			continue
+		}
+		removed++
+	}
+	m.closePalette()
+	return m.showNotification("🧹", fmt.Sprintf("Removed %d finished jobs", removed), "success")
+}
+
+// attachJob follows j's output in the terminal pane. Closing the pane
+// leaves the job running.
+func (m *model) attachJob(j *jobs.Job) tea.Cmd {
+	m.closeTerminal()
+
+	termW, termH := embeddedTermSize(m.width, m.height)
+	vt := vterm.NewVTerm(&termRenderer{}, func(x, y int) {})
+	vt.Reshape(0, 0, termW, termH)
+	pr, pw := io.Pipe()
+	go vt.ProcessStdout(bufio.NewReader(pr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.term = EmbeddedTerm{
+		active:    true,
+		vt:        vt,
+		width:     termW,
+		height:    termH,
+		command:   j.Command,
+		tool:      j.Resource,
+		started:   j.Started,
+		job:       j,
+		jobCancel: cancel,
+	}
+	slog.Info("following job", "id", j.ID)
+
+	follow := *j
+	return tea.Batch(m.waitForTermOutput(), func() tea.Msg {
+		defer pw.Close()
+		if followJob(ctx, &follow, newlineWriter{pw}) {
+			return jobDoneMsg{id: j.ID}
+		}
+		return nil
+	})
+}
+
+// followJob copies j's output to w as it is written, until the job
+// finished, reporting true, or ctx is done
+func followJob(ctx context.Context, j *jobs.Job, w io.Writer) bool {
+	f, err := os.Open(j.OutputPath())
+	if err != nil {
+		slog.Warn("failed to open job output", "id", j.ID, "error", err)
+		return false
+	}
+	defer f.Close()
+
+	for {
+		// Check first so that the output written before the job
+		// finished is all copied
+		j.Refresh()
+		running := j.Running()
+		if _, err := io.Copy(w, f); err != nil {
+			return false
+		}
+		if !running {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// newlineWriter turns the bare newlines of output written to a file into
+// the CRLFs a terminal would have written
+type newlineWriter struct {
+	w io.Writer
+}
+
+func (n newlineWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(n.w, strings.ReplaceAll(string(p), "\n", "\r\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// handleJobDone marks the followed job finished
+func (m *model) handleJobDone(msg jobDoneMsg) {
+	j := m.term.job
+	if j == nil || j.ID != msg.id {
+		return
+	}
+	j.Refresh()
+	m.term.exited = true
+	switch {
+	case j.ExitCode == nil:
+		m.term.exitErr = errors.New("stopped")
+	case *j.ExitCode != 0:
+		m.term.exitErr = fmt.Errorf("exit status %d", *j.ExitCode)
+	}
+}
+
+// stopOrRemoveJob stops the followed job, or removes it once it finished
+func (m *model) stopOrRemoveJob() tea.Cmd {
+	j := m.term.job
+	if j.Running() {
+		if err := j.Stop(); err != nil {
+			return m.showNotification("✗", err.Error(), "error")
+		}
+		return m.showNotification("■", "Stopping "+truncate(j.Command, 40), "info")
+	}
+	if err := j.Remove(); err != nil {
+		return m.showNotification("✗", err.Error(), "error")
+	}
+	m.closeTerminal()
+	return m.showNotification("🧹", "Removed the job", "success")
+}
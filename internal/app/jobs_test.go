@@ -0,0 +1,53 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/jobs"
+)
+
+func TestJobs(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = orig }()
+
+	m := &model{width: 120, height: 40}
+	run := commandRun{command: "echo built; exit 2", mode: CommandDetached, resource: "make", shell: shellSpec{path: "/bin/sh"}}
+	started, ok := m.execute(run)().(jobStartedMsg)
+	if !ok || started.err != nil {
+		t.Fatalf("job not started: %+v", started)
+	}
+
+	// The followed output ends once the job finished
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var out strings.Builder
+	if !followJob(ctx, started.job, newlineWriter{&out}) || out.String() != "built\r\n" {
+		t.Fatalf("followed output = %q", out.String())
+	}
+
+	m.showJobs()
+	if len(m.palette.Items) != 2 || m.palette.Items[0].ID != "jobs:remove-finished" {
+		t.Fatalf("palette items = %+v", m.palette.Items)
+	}
+	if item := m.palette.Items[1]; item.Icon != "✗" || !strings.HasPrefix(item.Subtitle, "make · exit 2") {
+		t.Errorf("job item = %q %q", item.Icon, item.Subtitle)
+	}
+
+	list, _ := jobs.List(jobs.Dir(config.DataDir))
+	m.attachJob(list[0])
+	m.handleJobDone(jobDoneMsg{id: list[0].ID})
+	if !m.term.exited || m.term.exitErr == nil || m.term.exitErr.Error() != "exit status 2" {
+		t.Errorf("terminal exited = %v, err = %v", m.term.exited, m.term.exitErr)
+	}
+
+	// x on a finished job removes it
+	m.stopOrRemoveJob()
+	if list, _ := jobs.List(jobs.Dir(config.DataDir)); len(list) != 0 || m.term.active {
+		t.Errorf("%d jobs left, terminal active = %v", len(list), m.term.active)
+	}
+}
@@ -0,0 +1,365 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// jsonNodeKind distinguishes containers from scalar values
+type jsonNodeKind int
+
+const (
+	jsonLeaf jsonNodeKind = iota
+	jsonObject
+	jsonArray
+)
+
+// jsonNode is one key or element in a JSON document
+type jsonNode struct {
+	key      string // object key or array index; empty for the root
+	path     string // jq-style path, e.g. .items[0].name
+	kind     jsonNodeKind
+	value    string // formatted scalar for leaves
+	children []*jsonNode
+	parent   *jsonNode
+	depth    int
+	expanded bool
+}
+
+// jsonTree is the collapsible view of a JSON tool result
+type jsonTree struct {
+	root   *jsonNode
+	cursor int
+	offset int
+
+	// filter hides keys that don't contain it; filtering is set while the
+	// filter is being typed
+	filter    string
+	filtering bool
+}
+
+// jsonTreeExpandDepth is how many levels of containers start expanded
+const jsonTreeExpandDepth = 2
+
+var jsonIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseJSONTree builds a tree from text that is a JSON object or array,
+// optionally inside a ```json fence. It reports false for anything else.
+func parseJSONTree(text string) (*jsonTree, bool) {
+	text = strings.TrimSpace(text)
+	if fenced, ok := strings.CutPrefix(text, "```json"); ok {
+		text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(fenced), "```"))
+	}
+	if text == "" || (text[0] != '{' && text[0] != '[') {
+		return nil, false
+	}
+
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	root, err := decodeJSONNode(dec, nil, "", "")
+	if err != nil || dec.More() {
+		return nil, false
+	}
+	root.expanded = true
+	root.walk(func(n *jsonNode) {
+		n.expanded = n.depth < jsonTreeExpandDepth
+	})
+	return &jsonTree{root: root}, true
+}
+
+// decodeJSONNode reads one value from dec, keeping object keys in document
+// order
+func decodeJSONNode(dec *json.Decoder, parent *jsonNode, key, path string) (*jsonNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	// The root isn't shown, so its children start at depth 0
+	n := &jsonNode{key: key, path: path, parent: parent, depth: -1}
+	if parent != nil {
+		n.depth = parent.depth + 1
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			n.kind = jsonObject
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				k, _ := keyTok.(string)
+				child, err := decodeJSONNode(dec, n, k, path+jsonPathKey(k))
+				if err != nil {
+					return nil, err
+				}
+				n.children = append(n.children, child)
+			}
+		case '[':
+			n.kind = jsonArray
+			for i := 0; dec.More(); i++ {
+				child, err := decodeJSONNode(dec, n, strconv.Itoa(i), fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return nil, err
+				}
+				n.children = append(n.children, child)
+			}
+		}
+		// Closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		n.value = strconv.Quote(t)
+	case nil:
+		n.value = "null"
+	default:
+		n.value = fmt.Sprint(t)
+	}
+	return n, nil
+}
+
+// jsonPathKey formats an object key as a path segment
+func jsonPathKey(k string) string {
+	if jsonIdent.MatchString(k) {
+		return "." + k
+	}
+	return "[" + strconv.Quote(k) + "]"
+}
+
+// walk calls fn for every node below n
+func (n *jsonNode) walk(fn func(*jsonNode)) {
+	for _, c := range n.children {
+		fn(c)
+		c.walk(fn)
+	}
+}
+
+// matches reports whether the node's key contains filter, which must be
+// lower case
+func (n *jsonNode) matches(filter string) bool {
+	return strings.Contains(strings.ToLower(n.key), filter)
+}
+
+// matchesBelow reports whether n or any descendant matches filter
+func (n *jsonNode) matchesBelow(filter string) bool {
+	if n.matches(filter) {
+		return true
+	}
+	for _, c := range n.children {
+		if c.matchesBelow(filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// jqPath returns the node's path as jq accepts it, e.g. .[0].name
+func (n *jsonNode) jqPath() string {
+	if strings.HasPrefix(n.path, ".") {
+		return n.path
+	}
+	return "." + n.path
+}
+
+// visible returns the rows currently shown. While a filter is set, nodes
+// whose keys match are shown along with their ancestors, which are
+// expanded regardless of their state.
+func (t *jsonTree) visible() []*jsonNode {
+	filter := strings.ToLower(t.filter)
+	var rows []*jsonNode
+	var walk func(n *jsonNode, filtered bool)
+	walk = func(n *jsonNode, filtered bool) {
+		for _, c := range n.children {
+			if filtered && !c.matchesBelow(filter) {
+				continue
+			}
+			rows = append(rows, c)
+			switch {
+			case filtered && !c.matches(filter):
+				walk(c, true)
+			case c.expanded:
+				walk(c, false)
+			}
+		}
+	}
+	walk(t.root, filter != "")
+	return rows
+}
+
+// selected returns the node under the cursor
+func (t *jsonTree) selected() *jsonNode {
+	rows := t.visible()
+	if len(rows) == 0 {
+		return nil
+	}
+	t.cursor = min(max(t.cursor, 0), len(rows)-1)
+	return rows[t.cursor]
+}
+
+// selectNode moves the cursor to n if it is visible
+func (t *jsonTree) selectNode(n *jsonNode) {
+	for i, row := range t.visible() {
+		if row == n {
+			t.cursor = i
+			return
+		}
+	}
+}
+
+// setExpanded expands or collapses every container
+func (t *jsonTree) setExpanded(expanded bool) {
+	cur := t.selected()
+	t.root.walk(func(n *jsonNode) {
+		if n.kind != jsonLeaf {
+			n.expanded = expanded
+		}
+	})
+	// Collapsing can hide the cursor; fall back to its top-level ancestor
+	for cur != nil && cur.parent != nil && cur.parent != t.root && !expanded {
+		cur = cur.parent
+	}
+	t.selectNode(cur)
+}
+
+// handleKey applies a navigation key and reports whether it was used
+func (t *jsonTree) handleKey(key string, height int) bool {
+	if t.filtering {
+		switch key {
+		case "enter", "esc":
+			t.filtering = false
+			if key == "esc" {
+				t.filter = ""
+			}
+		case "backspace":
+			if t.filter != "" {
+				t.filter = dropLastRune(t.filter)
+			}
+		case "space":
+			t.filter += " "
+		default:
+			if len(key) == 1 && key[0] >= 32 && key[0] < 127 {
+				t.filter += key
+			}
+		}
+		t.cursor = 0
+		t.offset = 0
+		return true
+	}
+
+	rows := t.visible()
+	switch key {
+	case "j", "down":
+		t.cursor++
+	case "k", "up":
+		t.cursor--
+	case "pgdown", "ctrl+d":
+		t.cursor += max(height/2, 1)
+	case "pgup", "ctrl+u":
+		t.cursor -= max(height/2, 1)
+	case "g", "home":
+		t.cursor = 0
+	case "G", "end":
+		t.cursor = len(rows) - 1
+	case "l", "right", "enter", "space":
+		if n := t.selected(); n != nil && n.kind != jsonLeaf {
+			n.expanded = key == "l" || key == "right" || !n.expanded
+		}
+	case "h", "left":
+		if n := t.selected(); n != nil {
+			if n.kind != jsonLeaf && n.expanded {
+				n.expanded = false
+			} else if n.parent != nil && n.parent != t.root {
+				t.selectNode(n.parent)
+			}
+		}
+	case "E":
+		t.setExpanded(true)
+	case "C":
+		t.setExpanded(false)
+	case "/":
+		t.filtering = true
+	default:
+		return false
+	}
+	t.selected()
+	t.scrollTo(height)
+	return true
+}
+
+// scrollTo keeps the cursor inside a window of height rows
+func (t *jsonTree) scrollTo(height int) {
+	if t.cursor < t.offset {
+		t.offset = t.cursor
+	}
+	if t.cursor >= t.offset+height {
+		t.offset = t.cursor - height + 1
+	}
+	t.offset = max(t.offset, 0)
+}
+
+// view renders height rows of the tree
+func (t *jsonTree) view(width, height int) string {
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("75"))
+	stringStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
+	valueStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("215"))
+	summaryStyle := lipgloss.NewStyle().Foreground(subtle)
+	cursorStyle := lipgloss.NewStyle().Background(lipgloss.Color("237")).Width(width)
+
+	rows := t.visible()
+	if len(rows) == 0 {
+		return summaryStyle.Render("No keys match " + strconv.Quote(t.filter))
+	}
+
+	t.scrollTo(height)
+	end := min(t.offset+height, len(rows))
+	lines := make([]string, 0, end-t.offset)
+	for i := t.offset; i < end; i++ {
+		n := rows[i]
+		var b strings.Builder
+		b.WriteString(strings.Repeat("  ", n.depth))
+		switch {
+		case n.kind == jsonLeaf:
+			b.WriteString("  ")
+		case n.expanded || (t.filter != "" && !n.matches(strings.ToLower(t.filter))):
+			b.WriteString("▾ ")
+		default:
+			b.WriteString("▸ ")
+		}
+		if n.parent != nil && n.parent.kind == jsonArray {
+			b.WriteString(summaryStyle.Render(n.key) + " ")
+		} else {
+			b.WriteString(keyStyle.Render(n.key) + summaryStyle.Render(":") + " ")
+		}
+
+		switch n.kind {
+		case jsonObject:
+			b.WriteString(summaryStyle.Render(fmt.Sprintf("{%d}", len(n.children))))
+		case jsonArray:
+			b.WriteString(summaryStyle.Render(fmt.Sprintf("[%d]", len(n.children))))
+		default:
+			room := max(width-lipgloss.Width(b.String())-1, 8)
+			value := truncate(n.value, room)
+			if strings.HasPrefix(n.value, `"`) {
+				b.WriteString(stringStyle.Render(value))
+			} else {
+				b.WriteString(valueStyle.Render(value))
+			}
+		}
+
+		line := b.String()
+		if i == t.cursor {
+			line = cursorStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+const testJSON = `{
+  "kind": "List",
+  "items": [
+    {"name": "web", "labels": {"app.kubernetes.io/name": "web"}},
+    {"name": "db", "labels": {}}
+  ],
+  "count": 2
+}`
+
+func rowPaths(t *jsonTree) []string {
+	var paths []string
+	for _, n := range t.visible() {
+		paths = append(paths, n.jqPath())
+	}
+	return paths
+}
+
+func TestParseJSONTree(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		ok   bool
+	}{
+		{"object", testJSON, true},
+		{"array", `[1, 2]`, true},
+		{"fenced", "```json\n{\"a\": 1}\n```", true},
+		{"markdown", "# Pods\n\n- web", false},
+		{"scalar", `"just a string"`, false},
+		{"invalid", `{"a": }`, false},
+		{"trailing text", `{"a": 1} and more`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := parseJSONTree(tt.text); ok != tt.ok {
+				t.Errorf("parseJSONTree() ok = %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestJSONTreeExpandCollapse(t *testing.T) {
+	tree, ok := parseJSONTree(testJSON)
+	if !ok {
+		t.Fatal("parseJSONTree() failed")
+	}
+
+	// Top-level containers and their children start open, keys in
+	// document order
+	want := []string{".kind", ".items", ".items[0]", ".items[0].name", ".items[0].labels",
+		".items[1]", ".items[1].name", ".items[1].labels", ".count"}
+	if got := rowPaths(tree); !slices.Equal(got, want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+
+	tree.handleKey("j", 10)
+	tree.handleKey("h", 10) // collapse .items
+	if got := rowPaths(tree); !slices.Equal(got, []string{".kind", ".items", ".count"}) {
+		t.Errorf("after collapse rows = %v", got)
+	}
+
+	tree.handleKey("E", 10)
+	if got := len(tree.visible()); got != 10 {
+		t.Errorf("after expand all %d rows, want 10", got)
+	}
+	if got := tree.selected().jqPath(); got != ".items" {
+		t.Errorf("cursor = %s, want .items", got)
+	}
+
+	tree.handleKey("G", 10)
+	tree.handleKey("k", 10)
+	if got := tree.selected().jqPath(); got != `.items[1].labels` {
+		t.Errorf("cursor = %s", got)
+	}
+	tree.handleKey("C", 10)
+	if got := tree.selected().jqPath(); got != ".items" {
+		t.Errorf("after collapse all cursor = %s, want .items", got)
+	}
+}
+
+func TestJSONTreeFilter(t *testing.T) {
+	tree, _ := parseJSONTree(testJSON)
+	tree.handleKey("C", 10)
+
+	for _, k := range []string{"/", "a", "p", "p", "enter"} {
+		tree.handleKey(k, 10)
+	}
+	if tree.filtering || tree.filter != "app" {
+		t.Fatalf("filter = %q, filtering = %v", tree.filter, tree.filtering)
+	}
+
+	// Ancestors of a match are shown even though they are collapsed
+	want := []string{".items", ".items[0]", ".items[0].labels", `.items[0].labels["app.kubernetes.io/name"]`}
+	if got := rowPaths(tree); !slices.Equal(got, want) {
+		t.Errorf("rows = %v, want %v", got, want)
+	}
+}
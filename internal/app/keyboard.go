@@ -1,20 +1,30 @@
 package app
 
 import (
-	"fmt"
+	"log/slog"
 	"strings"
 
 	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 )
 
 // handleKeyMsg is the main keyboard event dispatcher
 func (m *model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	keyStr := msg.String()
+	// Any key closes the help overlay
+	if m.help != nil {
+		m.help = nil
+		return m, nil
+	}
+
+	// The pager takes every key while it is open
+	if m.pager != nil {
+		return m.handlePagerKeys(msg)
+	}
 
 	// Terminal focus toggle
-	if keyStr == "f1" && m.term.active {
+	if key.Matches(msg, terminalKeys.Focus) && m.term.active {
 		m.term.focused = !m.term.focused
 		return m, nil
 	}
@@ -24,19 +34,62 @@ func (m *model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, m.sendKeyToTerminal(msg)
 	}
 
+	// Suspend to the shell; polling pauses until skitz is resumed
+	if key.Matches(msg, globalKeys.Suspend) {
+		m.unfocused = true
+		slog.Debug("suspending, pausing polling")
+		return m, tea.Suspend
+	}
+
 	// Close terminal if not focused
-	if keyStr == "esc" && m.term.active && !m.term.focused {
+	if key.Matches(msg, terminalKeys.Close) && m.term.active && !m.term.focused {
 		m.closeTerminal()
 		return m, nil
 	}
 
+	// Page the output of a finished command
+	if key.Matches(msg, terminalKeys.Pager) && m.term.active && !m.term.focused && (m.term.exited || m.term.staticOutput != "") && m.term.replay == nil {
+		return m, m.openTerminalPager()
+	}
+
+	// Follow-ups of a failed command
+	if key.Matches(msg, terminalKeys.FollowUp) && m.term.active && !m.term.focused {
+		if ups := m.failureFollowUps(); len(ups) > 0 {
+			if n := int(msg.String()[0] - '0'); n <= len(ups) {
+				return m, ups[n-1].run(m)
+			}
+			return m, nil
+		}
+	}
+
+	// Stop or remove the job being followed
+	if key.Matches(msg, terminalKeys.Job) && m.term.job != nil && !m.term.focused {
+		return m, m.stopOrRemoveJob()
+	}
+
+	// Export the session being replayed
+	if key.Matches(msg, terminalKeys.Export) && m.term.replay != nil && !m.term.focused {
+		return m, m.exportRecording(m.term.replay)
+	}
+
+	// Help overlay for the current view
+	if key.Matches(msg, globalKeys.Help) && m.helpAvailable() {
+		m.openHelp()
+		return m, nil
+	}
+
+	// Recent resources switcher
+	if m.recentSwitcher != nil {
+		return m.handleRecentKeys(msg)
+	}
+
 	// Command palette handling
 	if m.palette.State != PaletteStateIdle {
 		return m.handlePaletteKeys(msg)
 	}
 
 	// Open palette
-	if keyStr == "ctrl+k" {
+	if key.Matches(msg, globalKeys.Palette) {
 		m.openPalette()
 		return m, nil
 	}
@@ -46,6 +99,11 @@ func (m *model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleAskPanelKeys(msg)
 	}
 
+	// Open the recent resources switcher
+	if key.Matches(msg, globalKeys.Recent) && m.recentAvailable() {
+		return m, m.openRecent()
+	}
+
 	// Detail view handling
 	if m.currentView == viewDetail && m.viewReady {
 		return m.handleDetailViewKeys(msg)
@@ -71,34 +129,46 @@ func (m *model) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.palette.State = PaletteStateSearching
 			m.palette.InputForm = nil
 			m.palette.PendingTool = nil
+			m.palette.PendingAction = nil
 			return m, nil
 		}
+		if key.Matches(msg, paletteKeys.Example) && m.cycleFormExample() {
+			return m, nil
+		}
+		if key.Matches(msg, paletteKeys.Copy) && m.palette.PendingTool != nil {
+			return m, m.copyMCPCurl()
+		}
 
 		form, cmd := m.palette.InputForm.Update(msg)
 		if f, ok := form.(*huh.Form); ok {
 			m.palette.InputForm = f
 
 			if f.State == huh.StateCompleted {
+				if m.palette.PendingAction != nil {
+					return m, m.submitCustomAction()
+				}
 				return m, m.handleParameterSubmit()
 			}
 		}
 		return m, cmd
 	}
 
+	// Handle the scrollable result pane
+	if m.palette.State == PaletteStateShowingResult {
+		return m.handlePaletteResultKeys(msg)
+	}
+
 	// Handle palette states
-	switch keyStr {
-	case "esc", "ctrl+k":
+	switch {
+	case key.Matches(msg, paletteKeys.Close):
 		switch m.palette.State {
 		case PaletteStateExecuting:
-			return m, nil
+			return m, m.cancelPaletteCall()
 		case PaletteStateAIInput:
 			m.palette.State = PaletteStateSearching
 			m.palette.PendingTool = nil
 			m.palette.Query = ""
 			return m, nil
-		case PaletteStateShowingResult:
-			m.closePalette()
-			return m, nil
 		default:
 			m.closePalette()
 			m.term.active = false
@@ -107,7 +177,7 @@ func (m *model) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-	case "enter":
+	case key.Matches(msg, paletteKeys.Run):
 		switch m.palette.State {
 		case PaletteStateExecuting:
 			return m, nil
@@ -121,38 +191,63 @@ func (m *model) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			pt := m.palette.PendingTool
 			if pt != nil {
 				pt.AITask = task
-				m.palette.State = PaletteStateExecuting
-				m.palette.LoadingText = "🤖 AI is determining parameters and executing..."
-				return m, m.executeMCPToolWithAIAgent(pt)
+				ctx, spin := m.startPaletteExecution("🤖 AI is determining parameters and executing...")
+				return m, tea.Batch(spin, m.executeMCPToolWithAIAgent(ctx, pt))
 			}
 			return m, nil
 
 		case PaletteStateSearching:
+			if m.palette.wantsBulkAction() {
+				m.showBulkActions()
+				return m, nil
+			}
 			if len(m.palette.Filtered) > 0 && m.palette.Cursor < len(m.palette.Filtered) {
 				item := m.palette.Filtered[m.palette.Cursor]
 				m.term.staticOutput = ""
 				m.term.staticTitle = ""
 
-				if item.MCPTool != nil {
-					return m, m.startMCPToolInput(item)
-				}
-
 				if item.Handler != nil {
 					cmd := item.Handler(m)
 					return m, cmd
 				}
+
+				if item.MCPTool != nil {
+					return m, m.startMCPToolInput(item)
+				}
 			}
 			return m, nil
 
-		case PaletteStateShowingResult:
-			m.closePalette()
+		default:
 			return m, nil
+		}
 
-		default:
+	case key.Matches(msg, paletteKeys.Copy):
+		if m.palette.State != PaletteStateSearching {
 			return m, nil
 		}
+		if len(m.palette.Filtered) > 0 && m.palette.Cursor < len(m.palette.Filtered) {
+			item := m.palette.Filtered[m.palette.Cursor]
+			if item.Category == "preset" {
+				text, err := presetYAML(item)
+				if err == nil {
+					err = clipboard.WriteAll(text)
+				}
+				if err != nil {
+					return m, m.showNotification("❌", "Failed to copy: "+err.Error(), "error")
+				}
+				return m, m.showNotification("📋", "Preset copied as YAML", "success")
+			}
+			if len(item.Commands) == 0 {
+				return m, m.showNotification("⚠️", "Nothing to copy", "warning")
+			}
+			if err := clipboard.WriteAll(strings.Join(item.Commands, "\n")); err != nil {
+				return m, m.showNotification("❌", "Failed to copy: "+err.Error(), "error")
+			}
+			return m, m.showNotification("📋", "Command copied to clipboard", "success")
+		}
+		return m, nil
 
-	case "ctrl+a":
+	case key.Matches(msg, paletteKeys.AI):
 		if m.palette.State != PaletteStateSearching {
 			return m, nil
 		}
@@ -160,12 +255,44 @@ func (m *model) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.palette.Filtered) > 0 && m.palette.Cursor < len(m.palette.Filtered) {
 			item := m.palette.Filtered[m.palette.Cursor]
 			if item.MCPTool != nil {
+				if cmd := m.aiUnavailable(); cmd != nil {
+					return m, cmd
+				}
 				return m, m.startMCPToolWithAI(item)
 			}
 		}
 		return m, nil
 
-	case "up", "ctrl+p":
+	case key.Matches(msg, paletteKeys.Edit):
+		if m.palette.State != PaletteStateSearching {
+			return m, nil
+		}
+		if len(m.palette.Filtered) > 0 && m.palette.Cursor < len(m.palette.Filtered) {
+			if item := m.palette.Filtered[m.palette.Cursor]; item.Category == "preset" || item.Category == mcpHistoryCategory {
+				return m, m.startMCPToolInput(item)
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, paletteKeys.Pin):
+		if m.palette.State != PaletteStateSearching {
+			return m, nil
+		}
+		if len(m.palette.Filtered) > 0 && m.palette.Cursor < len(m.palette.Filtered) {
+			return m, m.toggleMCPFavorite(m.palette.Filtered[m.palette.Cursor])
+		}
+		return m, nil
+
+	case key.Matches(msg, paletteKeys.Mark) && m.palette.State == PaletteStateSearching && (keyStr == "tab" || m.palette.Query == ""):
+		if len(m.palette.Filtered) > 0 && m.palette.Cursor < len(m.palette.Filtered) {
+			m.toggleMark(m.palette.Filtered[m.palette.Cursor])
+			if m.palette.Cursor < len(m.palette.Filtered)-1 {
+				m.palette.Cursor++
+			}
+		}
+		return m, nil
+
+	case key.Matches(msg, paletteKeys.Up):
 		if m.palette.State != PaletteStateSearching {
 			return m, nil
 		}
@@ -176,7 +303,7 @@ func (m *model) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "down", "ctrl+n":
+	case key.Matches(msg, paletteKeys.Down):
 		if m.palette.State != PaletteStateSearching {
 			return m, nil
 		}
@@ -187,20 +314,23 @@ func (m *model) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "backspace":
+	case keyStr == "backspace":
 		if m.palette.State != PaletteStateSearching && m.palette.State != PaletteStateAIInput {
 			return m, nil
 		}
 		if len(m.palette.Query) > 0 {
-			m.palette.Query = m.palette.Query[:len(m.palette.Query)-1]
+			m.palette.Query = dropLastRune(m.palette.Query)
 			if m.palette.State == PaletteStateSearching {
-				m.palette.Filtered = filterPaletteItems(m.palette.Items, m.palette.Query)
+				m.palette.Filtered = m.filterPalette(m.palette.Query)
 				m.palette.Cursor = 0
 			}
 		}
 		return m, nil
 
-	case "ctrl+c":
+	case key.Matches(msg, paletteKeys.Quit):
+		if m.palette.State == PaletteStateExecuting {
+			return m, m.cancelPaletteCall()
+		}
 		return m, tea.Quit
 
 	default:
@@ -211,13 +341,13 @@ func (m *model) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(keyStr) == 1 && keyStr[0] >= 32 && keyStr[0] < 127 {
 			m.palette.Query += keyStr
 			if m.palette.State == PaletteStateSearching {
-				m.palette.Filtered = filterPaletteItems(m.palette.Items, m.palette.Query)
+				m.palette.Filtered = m.filterPalette(m.palette.Query)
 				m.palette.Cursor = 0
 			}
 		} else if keyStr == "space" {
 			m.palette.Query += " "
 			if m.palette.State == PaletteStateSearching {
-				m.palette.Filtered = filterPaletteItems(m.palette.Items, m.palette.Query)
+				m.palette.Filtered = m.filterPalette(m.palette.Query)
 				m.palette.Cursor = 0
 			}
 		}
@@ -229,41 +359,96 @@ func (m *model) handlePaletteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *model) handleAskPanelKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	keyStr := msg.String()
 
-	switch keyStr {
-	case "esc":
+	// Section picker for adding the generated command
+	if m.askPanel.SectionForm != nil {
+		if keyStr == "esc" {
+			m.askPanel.SectionForm = nil
+			return m, nil
+		}
+
+		form, cmd := m.askPanel.SectionForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.askPanel.SectionForm = f
+			if f.State == huh.StateCompleted {
+				return m, m.addCommandToResource(m.askPanel.GeneratedCmd, m.askPanel.SectionChoice)
+			}
+		}
+		return m, cmd
+	}
+
+	// Context picker for attaching a file, output or diff
+	if m.askPanel.ContextForm != nil {
+		if keyStr == "esc" {
+			m.askPanel.ContextForm = nil
+			return m, nil
+		}
+
+		form, cmd := m.askPanel.ContextForm.Update(msg)
+		if f, ok := form.(*huh.Form); ok {
+			m.askPanel.ContextForm = f
+			if f.State == huh.StateCompleted {
+				return m, m.attachAskContext()
+			}
+		}
+		return m, cmd
+	}
+
+	// Confirmation for a generated command the approval policy asks about
+	if m.askPanel.PendingApproval != "" {
+		switch {
+		case key.Matches(msg, askKeys.Approve):
+			return m, m.resolveApproval(true)
+		case key.Matches(msg, askKeys.Reject):
+			return m, m.resolveApproval(false)
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, askKeys.Close):
 		m.askPanel = nil
 		return m, nil
-	case "enter":
+	case key.Matches(msg, askKeys.Ask):
 		if m.askPanel.Input != "" && !m.askPanel.Loading {
 			return m, m.submitAskPanel()
 		}
 		return m, nil
-	case "backspace":
+	case keyStr == "backspace":
 		if len(m.askPanel.Input) > 0 {
-			m.askPanel.Input = m.askPanel.Input[:len(m.askPanel.Input)-1]
+			m.askPanel.Input = dropLastRune(m.askPanel.Input)
 		}
 		return m, nil
-	case "ctrl+g":
+	case key.Matches(msg, askKeys.Generate):
 		// Generate command mode
 		if m.askPanel.Input != "" && !m.askPanel.Loading {
 			return m, m.submitGenerateCommand()
 		}
 		return m, nil
-	case "ctrl+r":
+	case key.Matches(msg, askKeys.Run):
 		// Run generated command
 		if m.askPanel.GeneratedCmd != "" {
-			cmd := m.askPanel.GeneratedCmd
-			m.askPanel = nil
-			return m, m.runCommand(CommandSpec{
-				Command: cmd,
-				Mode:    CommandEmbedded,
-			})
+			return m, m.runGeneratedCommand()
+		}
+		return m, nil
+	case key.Matches(msg, askKeys.Handoff):
+		if m.askPanel.GeneratedCmd != "" {
+			return m, m.handOffGeneratedCommand()
 		}
 		return m, nil
-	case "ctrl+a":
+	case key.Matches(msg, askKeys.Attach):
+		// Attach extra context
+		if !m.askPanel.Loading {
+			return m, m.startAskContextPicker()
+		}
+		return m, nil
+	case key.Matches(msg, askKeys.Detach):
+		// Remove attached context
+		m.askPanel.Contexts = nil
+		return m, nil
+	case key.Matches(msg, askKeys.Add):
 		// Add generated command to resource
 		if m.askPanel.GeneratedCmd != "" {
-			return m, m.addCommandToResource(m.askPanel.GeneratedCmd)
+			return m, m.startAddCommandToResource(m.askPanel.GeneratedCmd)
 		}
 		return m, nil
 	default:
@@ -279,26 +464,84 @@ func (m *model) handleAskPanelKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleDetailViewKeys handles keyboard input in the detail view
 func (m *model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.cmdEditor != nil {
+		return m.handleCommandEditorKeys(msg)
+	}
+	if m.kubeSwitcher != nil {
+		return m.handleKubeSwitcherKeys(msg)
+	}
+	if m.tableView != nil {
+		return m.handleTableViewKeys(msg)
+	}
+
+	if cmd, ok := m.typeCountDigit(msg); ok {
+		return m, cmd
+	}
+	n, counted := m.takeCount()
+
 	var cmds []tea.Cmd
-	keyStr := msg.String()
 
-	switch keyStr {
-	case "q":
+	switch {
+	case counted && (key.Matches(msg, detailKeys.Run) || key.Matches(msg, detailKeys.Bottom)):
+		// 12 Enter or 12G selects the twelfth command
+		if n <= len(m.commands) {
+			m.cmdCursor = n - 1
+			m.refreshCommandListDisplay()
+		}
+		return m, nil
+
+	case key.Matches(msg, detailKeys.Back):
 		m.currentView = viewDashboard
 		m.viewReady = false
 		m.secCursor = 0
+		m.tagFilter = ""
 		return m, nil
 
-	case "ctrl+c":
+	case key.Matches(msg, detailKeys.Quit):
 		return m, tea.Quit
 
-	case "esc":
+	case key.Matches(msg, detailKeys.Escape):
+		if m.tagFilter != "" {
+			m.tagFilter = ""
+			m.updateViewportContent()
+			return m, nil
+		}
 		m.currentView = viewDashboard
 		m.viewReady = false
 		m.secCursor = 0
 		return m, nil
 
-	case "tab", "shift+tab":
+	case key.Matches(msg, detailKeys.Tag):
+		if tag := m.cycleTagFilter(); tag != "" {
+			return m, m.showNotification("#", "Filtering by #"+tag, "info")
+		}
+		return m, nil
+
+	case key.Matches(msg, detailKeys.Edit):
+		return m, m.startCommandEdit()
+
+	case key.Matches(msg, detailKeys.Community):
+		return m, m.showCommunityExamples()
+
+	case key.Matches(msg, detailKeys.Summary):
+		return m, m.toggleSectionSummary()
+
+	case key.Matches(msg, detailKeys.Upstream):
+		return m, m.showUpstreamDocs()
+
+	case key.Matches(msg, detailKeys.Target):
+		return m, m.pickExecTarget()
+
+	case key.Matches(msg, detailKeys.Refresh):
+		return m, m.fetchDynamicSection(true)
+
+	case key.Matches(msg, detailKeys.Sort):
+		if m.toggleUsageSort() {
+			return m, m.showNotification("↓", "Most used commands first", "info")
+		}
+		return m, m.showNotification("↓", "Commands in document order", "info")
+
+	case key.Matches(msg, detailKeys.Cycle):
 		res := m.currentResource()
 		if res != nil {
 			if msg.String() == "tab" {
@@ -319,7 +562,7 @@ func (m *model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "left", "h":
+	case key.Matches(msg, detailKeys.PrevSection):
 		if m.secCursor > 0 {
 			m.secCursor--
 			m.cmdCursor = 0
@@ -327,7 +570,7 @@ func (m *model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "right", "l":
+	case key.Matches(msg, detailKeys.NextSection):
 		res := m.currentResource()
 		if res != nil && m.secCursor < len(res.sections)-1 {
 			m.secCursor++
@@ -336,9 +579,11 @@ func (m *model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "up", "k":
+	case key.Matches(msg, detailKeys.Up):
 		if len(m.commands) > 0 {
-			if m.cmdCursor > 0 {
+			if counted {
+				m.cmdCursor = max(m.cmdCursor-n, 0)
+			} else if m.cmdCursor > 0 {
 				m.cmdCursor--
 			} else {
 				m.cmdCursor = len(m.commands) - 1
@@ -347,9 +592,11 @@ func (m *model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "down", "j":
+	case key.Matches(msg, detailKeys.Down):
 		if len(m.commands) > 0 {
-			if m.cmdCursor < len(m.commands)-1 {
+			if counted {
+				m.cmdCursor = min(m.cmdCursor+n, len(m.commands)-1)
+			} else if m.cmdCursor < len(m.commands)-1 {
 				m.cmdCursor++
 			} else {
 				m.cmdCursor = 0
@@ -358,8 +605,11 @@ func (m *model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "a":
+	case key.Matches(msg, detailKeys.Ask):
 		// Open Ask AI panel
+		if cmd := m.aiUnavailable(); cmd != nil {
+			return m, cmd
+		}
 		if m.config.AI.DefaultProvider == "" {
 			return m, m.showNotification("!", "Configure a provider first", "warning")
 		}
@@ -369,87 +619,98 @@ func (m *model) handleDetailViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
-	case "ctrl+y":
+	case key.Matches(msg, detailKeys.Copy):
 		if len(m.commands) > 0 && m.cmdCursor < len(m.commands) {
 			cmdText := m.commands[m.cmdCursor].raw
 			if err := clipboard.WriteAll(cmdText); err != nil {
 				return m, m.showNotification("!", "Copy failed: "+err.Error(), "error")
 			}
-			displayCmd := cmdText
-			if len(displayCmd) > 25 {
-				displayCmd = displayCmd[:22] + "..."
-			}
+			displayCmd := truncate(cmdText, 25)
 			return m, m.showNotification("", "Copied: "+displayCmd, "success")
 		}
 		return m, nil
 
-	case "enter":
+	case key.Matches(msg, detailKeys.Handoff):
 		if len(m.commands) > 0 && m.cmdCursor < len(m.commands) {
 			cmd := m.commands[m.cmdCursor]
-			finalCmd := cmd.cmd
-			if cmd.inputVar != "" {
-				var inputValue string
+			if err := checkCapabilities(cmd.requires, m.config.Capabilities); err != nil {
+				return m, m.showNotification("🔒", err.Error(), "error")
+			}
+			m.recordCommandUse(cmd)
+			command := expandActionParams(cmd.raw, m.storedValues(m.currentResource().name, cmd.raw))
+			return m, m.handOff(wrapForTarget(command, m.execTarget()))
+		}
+		return m, nil
 
-				inputField := huh.NewInput().
-					Title(fmt.Sprintf("Enter %s:", cmd.inputVar)).
-					Placeholder(cmd.inputVar).
-					Value(&inputValue)
+	case key.Matches(msg, detailKeys.CopySection):
+		return m, m.copyMarkdown(exportSection)
 
-				form := huh.NewForm(huh.NewGroup(inputField)).
-					WithTheme(huh.ThemeCatppuccin())
+	case key.Matches(msg, detailKeys.CopyAll):
+		return m, m.copyMarkdown(exportResource)
 
-				if err := form.Run(); err != nil || inputValue == "" {
-					return m, nil
-				}
+	case key.Matches(msg, detailKeys.Save):
+		return m, m.startMarkdownSave()
 
-				finalCmd = strings.Replace(finalCmd, "{{INPUT}}", inputValue, -1)
-			}
+	case key.Matches(msg, detailKeys.Run):
+		return m, m.runSelectedCommand(false, false)
 
-			mode := CommandEmbedded
-			if isInteractiveCommand(finalCmd) {
-				mode = CommandInteractive
-			}
+	case key.Matches(msg, detailKeys.RunWith):
+		return m, m.runSelectedCommand(true, false)
 
-			return m, m.runCommand(CommandSpec{
-				Command: finalCmd,
-				Mode:    mode,
-			})
-		}
-		return m, nil
+	case key.Matches(msg, detailKeys.Detach):
+		return m, m.runSelectedCommand(false, true)
 
-	case "ctrl+d", "pgdown":
+	case key.Matches(msg, detailKeys.PageDown):
+		offset := m.contentView.YOffset
 		m.contentView.HalfViewDown()
+		m.redrawVisibleCommands(offset)
 		return m, nil
 
-	case "ctrl+u", "pgup":
+	case key.Matches(msg, detailKeys.PageUp):
+		offset := m.contentView.YOffset
 		m.contentView.HalfViewUp()
+		m.redrawVisibleCommands(offset)
 		return m, nil
 
-	case "g":
+	case key.Matches(msg, detailKeys.Top):
+		offset := m.contentView.YOffset
 		m.contentView.GotoTop()
+		m.redrawVisibleCommands(offset)
 		return m, nil
 
-	case "G":
+	case key.Matches(msg, detailKeys.Bottom):
+		offset := m.contentView.YOffset
 		m.contentView.GotoBottom()
+		m.redrawVisibleCommands(offset)
 		return m, nil
 
-	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-		idx := int(msg.String()[0] - '1')
-		res := m.currentResource()
-		if res != nil && idx < len(res.sections) {
-			m.secCursor = idx
-			m.cmdCursor = 0
-			m.updateViewportContent()
-		}
-		return m, nil
 	}
 
 	var cmd tea.Cmd
+	offset := m.contentView.YOffset
 	m.contentView, cmd = m.contentView.Update(msg)
+	m.redrawVisibleCommands(offset)
 	cmds = append(cmds, cmd)
 	return m, tea.Batch(cmds...)
 }
 
+// sectionCount returns the number of sections in the open resource
+func (m *model) sectionCount() int {
+	if res := m.currentResource(); res != nil {
+		return len(res.sections)
+	}
+	return 0
+}
+
+// jumpToSection shows the section at idx, if there is one
+func (m *model) jumpToSection(idx int) {
+	if idx >= 0 && idx < m.sectionCount() {
+		m.secCursor = idx
+		m.cmdCursor = 0
+		m.updateViewportContent()
+	}
+}
+
 // hasActiveWizard returns true if any wizard is currently active
 func (m *model) hasActiveWizard() bool {
 	return (m.addResourceWizard != nil && m.addResourceWizard.InputForm != nil) ||
@@ -555,12 +816,26 @@ func (m *model) handleWizardKeys(msg tea.KeyMsg) tea.Cmd {
 func (m *model) moveDashboardCursor(delta, count int) {
 	switch m.dashboardTab {
 	case 0:
-		m.resCursor += delta
-		if m.resCursor < 0 {
-			m.resCursor = 0
-		} else if m.resCursor >= count {
-			m.resCursor = count - 1
+		stops := m.resourceStops()
+		if len(stops) == 0 {
+			return
+		}
+		pos := 0
+		groups := m.resourceGroups()
+		focused := groups[m.focusedGroup(groups)]
+		for i, idx := range stops {
+			if idx == m.resCursor || (m.collapsedGroups[focused.name] && idx == focused.indices[0]) {
+				pos = i
+				break
+			}
 		}
+		pos += delta
+		if pos < 0 {
+			pos = 0
+		} else if pos >= len(stops) {
+			pos = len(stops) - 1
+		}
+		m.resCursor = stops[pos]
 	case 1:
 		m.actionCursor += delta
 		if m.actionCursor < 0 {
@@ -575,6 +850,16 @@ func (m *model) moveDashboardCursor(delta, count int) {
 		} else if m.agentCursor >= count {
 			m.agentCursor = count - 1
 		}
+	case deploymentsTab:
+		m.deployCursor += delta
+		if m.deployCursor < 0 {
+			m.deployCursor = 0
+		} else if m.deployCursor >= count {
+			m.deployCursor = count - 1
+		}
+		m.confirmTeardown = ""
+	case pluginsTab:
+		m.pluginCursor = min(max(m.pluginCursor+delta, 0), max(count-1, 0))
 	}
 }
 
@@ -587,6 +872,10 @@ func (m *model) setDashboardCursor(idx int) {
 		m.actionCursor = idx
 	case 2:
 		m.agentCursor = idx
+	case deploymentsTab:
+		m.deployCursor = idx
+	case pluginsTab:
+		m.pluginCursor = idx
 	}
 }
 
@@ -599,16 +888,52 @@ func (m *model) getDashboardItemCount() int {
 		return len(m.actionItems)
 	case 2:
 		return len(m.savedAgents) + len(m.activeAgents) + len(m.agentHistory)
+	case deploymentsTab:
+		return len(m.deployments)
+	case pluginsTab:
+		return len(m.pluginPanelList())
 	}
 	return 0
 }
 
+// openDashboardNumber opens the item numbered idx+1 on the current tab.
+// On the Resources tab cards are numbered within the focused group.
+func (m *model) openDashboardNumber(idx int) tea.Cmd {
+	if m.dashboardTab == 0 {
+		groups := m.resourceGroups()
+		if len(groups) == 0 {
+			return nil
+		}
+		g := groups[m.focusedGroup(groups)]
+		if idx < len(g.indices) {
+			delete(m.collapsedGroups, g.name)
+			m.resCursor = g.indices[idx]
+			return m.handleDashboardEnter()
+		}
+		return nil
+	}
+	if idx < m.getDashboardItemCount() {
+		m.setDashboardCursor(idx)
+		return m.handleDashboardEnter()
+	}
+	return nil
+}
+
 // handleDashboardEnter handles Enter key for current tab
 func (m *model) handleDashboardEnter() tea.Cmd {
 	switch m.dashboardTab {
-	case 0: // Resources - open detail view
+	case 0: // Resources - open detail view, or expand a collapsed group
+		res := m.currentResource()
+		if res == nil {
+			// Nothing to open yet while the resources load
+			return nil
+		}
+		if m.collapsedGroups[resourceCategory(*res)] {
+			delete(m.collapsedGroups, resourceCategory(*res))
+			return nil
+		}
 		m.currentView = viewDetail
-		m.secCursor = 0
+		m.secCursor = res.defaultSection
 		m.initViewComponents()
 		return nil
 	case 1: // Actions - execute handler
@@ -620,6 +945,10 @@ func (m *model) handleDashboardEnter() tea.Cmd {
 		return nil
 	case 2: // Agents - run or view
 		return m.handleAgentEnter()
+	case deploymentsTab: // Deployments - follow logs
+		return m.showDeploymentLogs()
+	case pluginsTab: // Plugins - refresh panel
+		return m.refreshSelectedPluginPanel()
 	}
 	return nil
 }
@@ -675,6 +1004,10 @@ func (m *model) handleAgentsTabKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "esc", "q":
 			m.agentViewMode = 0
 			return m, nil
+		case "x":
+			if m.selectedAgentIdx < len(m.activeAgents) {
+				return m, m.cancelAgent(m.activeAgents[m.selectedAgentIdx].ID)
+			}
 		}
 		return m, nil
 	}
@@ -696,6 +1029,14 @@ func (m *model) handleAgentsTabKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				return m, m.showNotification("", "Output copied to clipboard", "success")
 			}
 			return m, nil
+		case "c":
+			return m, m.copyInteraction()
+		case "s":
+			return m, m.startInteractionSave()
+		case "w":
+			return m, m.postInteraction()
+		case "p":
+			return m, m.shareInteractionGist()
 		case "j", "down":
 			m.agentDetailScroll++
 			return m, nil
@@ -740,47 +1081,104 @@ func (m *model) handleDashboardKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	count := m.getDashboardItemCount()
 
-	switch msg.String() {
-	case "q", "ctrl+c":
+	if cmd, ok := m.typeCountDigit(msg); ok {
+		return m, cmd
+	}
+	n, counted := m.takeCount()
+	if !counted {
+		n = 1
+	}
+
+	switch {
+	case key.Matches(msg, dashboardKeys.Quit):
 		return m, tea.Quit
 
-	case "tab", "shift+tab":
+	case key.Matches(msg, dashboardKeys.Tab):
 		if msg.String() == "tab" {
-			m.dashboardTab = (m.dashboardTab + 1) % 3
+			m.dashboardTab = (m.dashboardTab + 1) % len(dashboardTabNames)
 		} else {
-			m.dashboardTab = (m.dashboardTab + 2) % 3
+			m.dashboardTab = (m.dashboardTab + len(dashboardTabNames) - 1) % len(dashboardTabNames)
 		}
 		m.agentCursor = 0
 		m.agentViewMode = 0
+		m.confirmTeardown = ""
+		switch m.dashboardTab {
+		case deploymentsTab:
+			return m, m.startDeploymentPolling()
+		case pluginsTab:
+			return m, m.refreshPluginPanels()
+		}
 		return m, nil
 
-	case "up", "k":
-		m.moveDashboardCursor(-1, count)
+	case key.Matches(msg, dashboardKeys.Up):
+		m.moveDashboardCursor(-n, count)
 		return m, nil
 
-	case "down", "j":
-		m.moveDashboardCursor(1, count)
+	case key.Matches(msg, dashboardKeys.Down):
+		m.moveDashboardCursor(n, count)
 		return m, nil
 
-	case "enter":
+	case key.Matches(msg, dashboardKeys.Open):
+		if counted {
+			return m, m.openDashboardNumber(n - 1)
+		}
 		return m, m.handleDashboardEnter()
 
-	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
-		idx := int(msg.String()[0] - '1')
-		if idx < count {
-			m.setDashboardCursor(idx)
-			return m, m.handleDashboardEnter()
-		}
+	case key.Matches(msg, dashboardKeys.Logs) && m.dashboardTab == deploymentsTab:
+		return m, m.showDeploymentLogs()
 
-	case "e":
+	case key.Matches(msg, dashboardKeys.Group):
 		if m.dashboardTab == 0 {
-			return m, m.editResource()
+			groups := m.resourceGroups()
+			if len(groups) == 0 {
+				return m, nil
+			}
+			gi := m.focusedGroup(groups)
+			if msg.String() == "left" || msg.String() == "h" {
+				gi = max(gi-1, 0)
+			} else {
+				gi = min(gi+1, len(groups)-1)
+			}
+			m.resCursor = groups[gi].indices[0]
 		}
+		return m, nil
 
-	case "d":
+	case key.Matches(msg, dashboardKeys.Collapse):
 		if m.dashboardTab == 0 {
-			return m, m.startDeleteResourceWizard()
+			m.toggleFocusedGroup()
+		}
+		return m, nil
+
+	case key.Matches(msg, dashboardKeys.Edit) && m.dashboardTab == 0:
+		return m, m.editResource()
+
+	case key.Matches(msg, dashboardKeys.Delete) && m.dashboardTab == 0:
+		return m, m.startDeleteResourceWizard()
+
+	case key.Matches(msg, dashboardKeys.CancelAgent) && m.dashboardTab == 2:
+		// Cancel the selected active agent
+		activeIdx := m.agentCursor - len(m.savedAgents)
+		if activeIdx >= 0 && activeIdx < len(m.activeAgents) {
+			return m, m.cancelAgent(m.activeAgents[activeIdx].ID)
 		}
+
+	case key.Matches(msg, dashboardKeys.Teardown) && m.dashboardTab == deploymentsTab:
+		return m, m.teardownDeployment()
+
+	case key.Matches(msg, dashboardKeys.Restart) && m.dashboardTab == deploymentsTab:
+		return m, m.restartDeployment()
+
+	case key.Matches(msg, dashboardKeys.RefreshDeploy) && m.dashboardTab == deploymentsTab:
+		return m, m.refreshDeployments()
+
+	case key.Matches(msg, dashboardKeys.RefreshPanel) && m.dashboardTab == pluginsTab:
+		return m, m.refreshSelectedPluginPanel()
+
+	case key.Matches(msg, dashboardKeys.RefreshPanels) && m.dashboardTab == pluginsTab:
+		return m, m.refreshPluginPanels()
+
+	case key.Matches(msg, dashboardKeys.RefreshMCP):
+		return m, m.refreshMCPNow()
 	}
 
 	return m, nil
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestDashboardGroups(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = orig }()
+
+	// Two AI cards, three Cloud cards and one Other card
+	m := &model{width: 120, height: 40}
+	for _, r := range []struct{ name, category string }{
+		{"ai1", "AI"}, {"ai2", "AI"},
+		{"cloud1", "Cloud"}, {"cloud2", "Cloud"}, {"cloud3", "Cloud"},
+		{"misc", ""},
+	} {
+		m.resources = append(m.resources, resource{name: r.name, category: r.category, sections: []section{{title: "Commands"}}})
+	}
+
+	var names []string
+	for _, g := range m.resourceGroups() {
+		names = append(names, g.name)
+	}
+	if want := []string{"AI", "Cloud", "Other"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("groups = %v, want %v", names, want)
+	}
+
+	tests := []struct {
+		name      string
+		keys      []string
+		cursor    int
+		collapsed []string
+		stops     []int
+		detail    bool
+	}{
+		{"j moves within a group", []string{"j"}, 1, nil, []int{0, 1, 2, 3, 4, 5}, false},
+		{"j crosses into the next group", []string{"j"}, 2, nil, []int{0, 1, 2, 3, 4, 5}, false},
+		{"c collapses the focused group", []string{"j", "c"}, 2, []string{"Cloud"}, []int{0, 1, 2, 5}, false},
+		{"j skips a collapsed group's cards", []string{"j"}, 5, []string{"Cloud"}, []int{0, 1, 2, 5}, false},
+		{"k lands on a collapsed group", []string{"k"}, 2, []string{"Cloud"}, []int{0, 1, 2, 5}, false},
+		{"k leaves a collapsed group", []string{"k"}, 1, []string{"Cloud"}, []int{0, 1, 2, 5}, false},
+		{"k stops at the first card", []string{"k", "k", "k"}, 0, []string{"Cloud"}, []int{0, 1, 2, 5}, false},
+		{"l jumps to the next group", []string{"j", "l"}, 2, []string{"Cloud"}, []int{0, 1, 2, 5}, false},
+		{"l stops at the last group", []string{"l", "l"}, 5, []string{"Cloud"}, []int{0, 1, 2, 5}, false},
+		{"h jumps to the previous group", []string{"h"}, 2, []string{"Cloud"}, []int{0, 1, 2, 5}, false},
+		{"a number past the group opens nothing", []string{"4", "enter"}, 2, []string{"Cloud"}, []int{0, 1, 2, 5}, false},
+		{"a number opens a card of the focused group", []string{"3", "enter"}, 4, nil, []int{0, 1, 2, 3, 4, 5}, true},
+		{"c on an expanded group moves to its first card", []string{"c", "c"}, 2, nil, []int{0, 1, 2, 3, 4, 5}, false},
+		{"h from the first group stays", []string{"h", "h"}, 0, nil, []int{0, 1, 2, 3, 4, 5}, false},
+	}
+	for _, tt := range tests {
+		m.currentView = viewDashboard
+		typeKeys(m, tt.keys...)
+		if m.resCursor != tt.cursor {
+			t.Errorf("%s: cursor = %d, want %d", tt.name, m.resCursor, tt.cursor)
+		}
+		var collapsed []string
+		for _, g := range names {
+			if m.collapsedGroups[g] {
+				collapsed = append(collapsed, g)
+			}
+		}
+		if !reflect.DeepEqual(collapsed, tt.collapsed) {
+			t.Errorf("%s: collapsed = %v, want %v", tt.name, collapsed, tt.collapsed)
+		}
+		if stops := m.resourceStops(); !reflect.DeepEqual(stops, tt.stops) {
+			t.Errorf("%s: stops = %v, want %v", tt.name, stops, tt.stops)
+		}
+		if (m.currentView == viewDetail) != tt.detail {
+			t.Errorf("%s: view = %v", tt.name, m.currentView)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package app
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Key bindings, grouped by the view that handles them. Handlers match keys
+// against these bindings and the help overlay lists them, so the help
+// can't drift from what the keys do.
+
+type globalKeyMap struct {
+	Help    key.Binding
+	Palette key.Binding
+	Suspend key.Binding
+	Recent  key.Binding
+}
+
+var globalKeys = globalKeyMap{
+	Help:    key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help for this view")),
+	Palette: key.NewBinding(key.WithKeys("ctrl+k"), key.WithHelp("ctrl+k", "command palette")),
+	Suspend: key.NewBinding(key.WithKeys("ctrl+z"), key.WithHelp("ctrl+z", "suspend skitz (fg to resume)")),
+	Recent:  key.NewBinding(key.WithKeys("'"), key.WithHelp("'", "switch to a recent resource")),
+}
+
+type recentKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Jump   key.Binding
+	Number key.Binding
+	Close  key.Binding
+}
+
+var recentKeys = recentKeyMap{
+	Up:     key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "previous resource")),
+	Down:   key.NewBinding(key.WithKeys("down", "j", "'"), key.WithHelp("↓/j/'", "next resource")),
+	Jump:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open the resource")),
+	Number: key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("1-9", "open by number")),
+	Close:  key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc", "close")),
+}
+
+type terminalKeyMap struct {
+	Focus    key.Binding
+	Close    key.Binding
+	Export   key.Binding
+	Pager    key.Binding
+	Job      key.Binding
+	FollowUp key.Binding
+}
+
+var terminalKeys = terminalKeyMap{
+	Focus:    key.NewBinding(key.WithKeys("f1"), key.WithHelp("F1", "focus or leave the terminal")),
+	Close:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close the terminal")),
+	Export:   key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export the replay as .cast")),
+	Pager:    key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open the output in the pager")),
+	Job:      key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "stop the job, or remove it once finished")),
+	FollowUp: key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("1-9", "run a follow-up of a failed command")),
+}
+
+type pagerKeyMap struct {
+	Search key.Binding
+	Next   key.Binding
+	Prev   key.Binding
+	Top    key.Binding
+	Bottom key.Binding
+	Copy   key.Binding
+	Close  key.Binding
+}
+
+var pagerKeys = pagerKeyMap{
+	Search: key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+	Next:   key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next match")),
+	Prev:   key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "previous match")),
+	Top:    key.NewBinding(key.WithKeys("g", "home"), key.WithHelp("g", "top")),
+	Bottom: key.NewBinding(key.WithKeys("G", "end"), key.WithHelp("G", "bottom")),
+	Copy:   key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy the output")),
+	Close:  key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc/q", "close the pager")),
+}
+
+type paletteKeyMap struct {
+	Close   key.Binding
+	Run     key.Binding
+	Up      key.Binding
+	Down    key.Binding
+	Copy    key.Binding
+	AI      key.Binding
+	Pin     key.Binding
+	Edit    key.Binding
+	Mark    key.Binding
+	Example key.Binding
+	Quit    key.Binding
+}
+
+var paletteKeys = paletteKeyMap{
+	Close:   key.NewBinding(key.WithKeys("esc", "ctrl+k"), key.WithHelp("esc", "close, cancel or go back")),
+	Run:     key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "run the selected item")),
+	Up:      key.NewBinding(key.WithKeys("up", "ctrl+p"), key.WithHelp("↑/ctrl+p", "previous item")),
+	Down:    key.NewBinding(key.WithKeys("down", "ctrl+n"), key.WithHelp("↓/ctrl+n", "next item")),
+	Copy:    key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("ctrl+y", "copy the item's commands, or the tool call being filled in as curl")),
+	AI:      key.NewBinding(key.WithKeys("ctrl+a"), key.WithHelp("ctrl+a", "run an MCP tool with AI")),
+	Pin:     key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "pin or unpin an MCP tool")),
+	Edit:    key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "edit a preset's or past MCP call's arguments before running")),
+	Mark:    key.NewBinding(key.WithKeys("tab", " "), key.WithHelp("tab/space", "mark for a bulk action; space before typing")),
+	Example: key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "next example of a parameter in its form")),
+	Quit:    key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "cancel the call or quit")),
+}
+
+type askKeyMap struct {
+	Ask      key.Binding
+	Generate key.Binding
+	Run      key.Binding
+	Handoff  key.Binding
+	Add      key.Binding
+	Attach   key.Binding
+	Detach   key.Binding
+	Close    key.Binding
+	Approve  key.Binding
+	Reject   key.Binding
+}
+
+var askKeys = askKeyMap{
+	Ask:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "ask")),
+	Generate: key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "generate a command")),
+	Run:      key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "run the generated command")),
+	Handoff:  key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "quit and edit the command at your shell prompt")),
+	Add:      key.NewBinding(key.WithKeys("ctrl+a"), key.WithHelp("ctrl+a", "add the generated command to a resource")),
+	Attach:   key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "attach a file, output or diff")),
+	Detach:   key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "remove attachments")),
+	Close:    key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close")),
+	Approve:  key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "run the command")),
+	Reject:   key.NewBinding(key.WithKeys("n", "esc"), key.WithHelp("n/esc", "don't run it")),
+}
+
+type detailKeyMap struct {
+	Up          key.Binding
+	Down        key.Binding
+	Run         key.Binding
+	RunWith     key.Binding
+	Detach      key.Binding
+	Copy        key.Binding
+	Handoff     key.Binding
+	CopySection key.Binding
+	CopyAll     key.Binding
+	Save        key.Binding
+	NextSection key.Binding
+	PrevSection key.Binding
+	Cycle       key.Binding
+	Section     key.Binding
+	Count       key.Binding
+	PageDown    key.Binding
+	PageUp      key.Binding
+	Top         key.Binding
+	Bottom      key.Binding
+	Ask         key.Binding
+	Edit        key.Binding
+	Tag         key.Binding
+	Sort        key.Binding
+	Target      key.Binding
+	Community   key.Binding
+	Summary     key.Binding
+	Upstream    key.Binding
+	Refresh     key.Binding
+	Back        key.Binding
+	Escape      key.Binding
+	Quit        key.Binding
+}
+
+var detailKeys = detailKeyMap{
+	Up:          key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "previous command")),
+	Down:        key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "next command")),
+	Run:         key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "run the command")),
+	RunWith:     key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "run, entering its variables for this run")),
+	Detach:      key.NewBinding(key.WithKeys("&"), key.WithHelp("&", "run in the background as a job")),
+	Copy:        key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("ctrl+y", "copy the command")),
+	Handoff:     key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "quit and edit the command at your shell prompt")),
+	CopySection: key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copy the section as markdown")),
+	CopyAll:     key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "copy the resource as markdown")),
+	Save:        key.NewBinding(key.WithKeys("W"), key.WithHelp("W", "save the section or resource to a file")),
+	NextSection: key.NewBinding(key.WithKeys("right", "l"), key.WithHelp("→/l", "next section")),
+	PrevSection: key.NewBinding(key.WithKeys("left", "h"), key.WithHelp("←/h", "previous section")),
+	Cycle:       key.NewBinding(key.WithKeys("tab", "shift+tab"), key.WithHelp("tab/shift+tab", "cycle sections")),
+	Section:     key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("N", "jump to section N")),
+	Count:       key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("N j/k, N enter", "move N commands, select command N")),
+	PageDown:    key.NewBinding(key.WithKeys("ctrl+d", "pgdown"), key.WithHelp("ctrl+d", "scroll down")),
+	PageUp:      key.NewBinding(key.WithKeys("ctrl+u", "pgup"), key.WithHelp("ctrl+u", "scroll up")),
+	Top:         key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "top")),
+	Bottom:      key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "bottom")),
+	Ask:         key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "ask AI")),
+	Edit:        key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit the command")),
+	Tag:         key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "filter by the next tag")),
+	Sort:        key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort by usage")),
+	Target:      key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "run in a container or context")),
+	Community:   key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "community examples")),
+	Summary:     key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "summarize the section with AI")),
+	Upstream:    key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "upstream docs from the repo: README")),
+	Refresh:     key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh a dynamic section")),
+	Back:        key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "back to the dashboard")),
+	Escape:      key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear the tag filter or go back")),
+	Quit:        key.NewBinding(key.WithKeys("ctrl+c"), key.WithHelp("ctrl+c", "quit")),
+}
+
+type dashboardKeyMap struct {
+	Up            key.Binding
+	Down          key.Binding
+	Open          key.Binding
+	Number        key.Binding
+	Tab           key.Binding
+	Group         key.Binding
+	Collapse      key.Binding
+	Edit          key.Binding
+	Delete        key.Binding
+	CancelAgent   key.Binding
+	Teardown      key.Binding
+	Restart       key.Binding
+	Logs          key.Binding
+	RefreshDeploy key.Binding
+	RefreshPanel  key.Binding
+	RefreshPanels key.Binding
+	RefreshMCP    key.Binding
+	Quit          key.Binding
+}
+
+var dashboardKeys = dashboardKeyMap{
+	Up:            key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "up")),
+	Down:          key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "down")),
+	Open:          key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open")),
+	Number:        key.NewBinding(key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"), key.WithHelp("N", "open by number; N j/k moves N")),
+	Tab:           key.NewBinding(key.WithKeys("tab", "shift+tab"), key.WithHelp("tab/shift+tab", "switch tab")),
+	Group:         key.NewBinding(key.WithKeys("left", "right", "h", "l"), key.WithHelp("←/→/h/l", "previous or next group")),
+	Collapse:      key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "collapse or expand the group")),
+	Edit:          key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit the resource")),
+	Delete:        key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete the resource")),
+	CancelAgent:   key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "cancel the running agent")),
+	Teardown:      key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "tear down the deployment")),
+	Restart:       key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restart the deployment")),
+	Logs:          key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "deployment logs")),
+	RefreshDeploy: key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "refresh deployment status")),
+	RefreshPanel:  key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "refresh the plugin panel")),
+	RefreshPanels: key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "refresh all plugin panels")),
+	RefreshMCP:    key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "refresh MCP servers")),
+	Quit:          key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit")),
+}
+
+// forTab returns the dashboard bindings that do something on tab
+func (k dashboardKeyMap) forTab(tab int) []key.Binding {
+	bindings := []key.Binding{k.Up, k.Down, k.Open, k.Number, k.Tab}
+	switch tab {
+	case 0:
+		bindings = append(bindings, k.Group, k.Collapse, k.Edit, k.Delete)
+	case 2:
+		bindings = append(bindings, k.CancelAgent)
+	case deploymentsTab:
+		bindings = append(bindings, k.Logs, k.Teardown, k.Restart, k.RefreshDeploy)
+	case pluginsTab:
+		bindings = append(bindings, k.RefreshPanel, k.RefreshPanels)
+	}
+	return append(bindings, k.RefreshMCP, k.Quit)
+}
@@ -0,0 +1,209 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/kube"
+)
+
+// kubeTags are the front-matter tags that mark a Kubernetes resource
+var kubeTags = []string{"k8s", "kubernetes"}
+
+// kubeNamespacesMsg carries the cluster namespaces fetched before the
+// switcher opens
+type kubeNamespacesMsg struct {
+	context    string
+	namespaces []string
+}
+
+// kubeSwitchedMsg reports a finished context/namespace switch
+type kubeSwitchedMsg struct {
+	context   string
+	namespace string
+	err       error
+}
+
+// isKubeResource reports whether res is tagged as a Kubernetes resource
+func isKubeResource(res *resource) bool {
+	if res == nil {
+		return false
+	}
+	for _, t := range res.tags {
+		if slices.Contains(kubeTags, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// refreshKubeConfig rereads the kubeconfig when a Kubernetes resource is
+// open
+func (m *model) refreshKubeConfig() {
+	if !isKubeResource(m.currentResource()) {
+		return
+	}
+	cfg, err := kube.Load()
+	if err != nil && !errors.Is(err, kube.ErrNoConfig) {
+		// Keep the last good config rather than blanking the status bar
+		return
+	}
+	m.kubeConfig = cfg
+}
+
+// kubeLabel formats the current context for the status bar
+func (m model) kubeLabel() string {
+	cur, ok := m.kubeConfig.Current()
+	if !ok {
+		return "⎈ no context"
+	}
+	return "⎈ " + cur.Name + " › " + cur.Namespace
+}
+
+// startKubeSwitch fetches the current cluster's namespaces and then opens
+// the switcher
+func (m *model) startKubeSwitch() tea.Cmd {
+	m.refreshKubeConfig()
+	if len(m.kubeConfig.Contexts) == 0 {
+		return m.showNotification("⎈", "No contexts found in kubeconfig", "warning")
+	}
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return m.showNotification("⎈", "kubectl not found in PATH", "error")
+	}
+
+	current := m.kubeConfig.CurrentContext
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		// An unreachable cluster still leaves the kubeconfig namespaces
+		namespaces, _ := kube.ClusterNamespaces(ctx, current)
+		return kubeNamespacesMsg{context: current, namespaces: namespaces}
+	}
+}
+
+// openKubeSwitcher builds the context and namespace select form
+func (m *model) openKubeSwitcher(msg kubeNamespacesMsg) tea.Cmd {
+	if !isKubeResource(m.currentResource()) {
+		return nil
+	}
+
+	s := &KubeSwitcher{Context: m.kubeConfig.CurrentContext}
+	if cur, ok := m.kubeConfig.Current(); ok {
+		s.Namespace = cur.Namespace
+	}
+
+	contextOptions := make([]huh.Option[string], 0, len(m.kubeConfig.Contexts))
+	for _, c := range m.kubeConfig.Contexts {
+		label := c.Name
+		if c.Cluster != "" && c.Cluster != c.Name {
+			label += " (" + c.Cluster + ")"
+		}
+		contextOptions = append(contextOptions, huh.NewOption(label, c.Name))
+	}
+
+	cfg := m.kubeConfig
+	namespaceOptions := func() []huh.Option[string] {
+		namespaces := cfg.Namespaces()
+		if c, ok := cfg.Context(s.Context); ok && c.Namespace != "" && !slices.Contains(namespaces, c.Namespace) {
+			namespaces = append(namespaces, c.Namespace)
+		}
+		// Cluster namespaces are only known for the context they were
+		// fetched from
+		if s.Context == msg.context {
+			for _, ns := range msg.namespaces {
+				if !slices.Contains(namespaces, ns) {
+					namespaces = append(namespaces, ns)
+				}
+			}
+		}
+		return huh.NewOptions(namespaces...)
+	}
+
+	s.Form = huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Context").
+				Options(contextOptions...).
+				Value(&s.Context),
+			huh.NewSelect[string]().
+				Title("Namespace").
+				OptionsFunc(namespaceOptions, &s.Context).
+				Value(&s.Namespace),
+		),
+	).
+		WithWidth(80).
+		WithShowHelp(true).
+		WithTheme(huh.ThemeCatppuccin())
+	m.kubeSwitcher = s
+	return s.Form.Init()
+}
+
+// handleKubeSwitcherKeys routes keys to the switcher form
+func (m *model) handleKubeSwitcherKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" {
+		m.kubeSwitcher = nil
+		return m, nil
+	}
+
+	form, cmd := m.kubeSwitcher.Form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		m.kubeSwitcher.Form = f
+		if f.State == huh.StateCompleted {
+			return m, m.switchKubeContext()
+		}
+	}
+	return m, cmd
+}
+
+// switchKubeContext applies the selected context and namespace with
+// kubectl
+func (m *model) switchKubeContext() tea.Cmd {
+	s := m.kubeSwitcher
+	m.kubeSwitcher = nil
+	if s.Context == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err := kube.Switch(ctx, s.Context, s.Namespace)
+		return kubeSwitchedMsg{context: s.Context, namespace: s.Namespace, err: err}
+	}
+}
+
+// handleKubeSwitched rereads the kubeconfig after a switch
+func (m *model) handleKubeSwitched(msg kubeSwitchedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.showNotification("⎈", msg.err.Error(), "error")
+	}
+	m.refreshKubeConfig()
+	return m.showNotification("⎈", fmt.Sprintf("Switched to %s › %s", msg.context, msg.namespace), "success")
+}
+
+// renderKubeSwitcher renders the switcher in place of the command list
+func (m model) renderKubeSwitcher(width int) string {
+	if m.kubeSwitcher == nil || m.kubeSwitcher.Form == nil {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("213")).
+		Bold(true)
+
+	editorStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("99")).
+		Padding(1, 2).
+		Width(width - 10)
+
+	content := lipgloss.JoinVertical(lipgloss.Left, titleStyle.Render("Kubernetes context"), "", m.kubeSwitcher.Form.View())
+	return lipgloss.NewStyle().Padding(1, 2).Render(editorStyle.Render(content))
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/cheatsheet"
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// annotationRe matches the skitz annotations in resource markdown, which
+// are dropped when a section is copied or saved
+var annotationRe = regexp.MustCompile("\\s*\\^(?:(?:run(?::\\w+)?|tag:[\\w.-]+|requires:[\\w.-]+|table|pager)\\b|onfail:(?:`[^`]*`|[^\\s`]+))")
+
+// Scopes offered by the Save as Markdown form
+const (
+	exportSection  = "Section"
+	exportResource = "Resource"
+)
+
+// sectionMarkdown returns sec as markdown for pasting elsewhere: under its
+// heading, without skitz annotations. Dynamic sections give their current
+// content.
+func (m model) sectionMarkdown(res *resource, sec *section) string {
+	var body string
+	if sec.dynamic != nil {
+		body = m.dynamicContent(res, sec)
+	} else {
+		var lines []string
+		for _, line := range strings.Split(sec.content, "\n") {
+			if strings.HasPrefix(line, "#") {
+				line, _, _ = strings.Cut(line, " ^")
+			} else {
+				line = annotationRe.ReplaceAllString(line, "")
+			}
+			lines = append(lines, line)
+		}
+		body = strings.Join(lines, "\n")
+	}
+
+	body = strings.TrimSpace(body)
+	if !strings.HasPrefix(body, "#") {
+		body = "## " + sec.title + "\n\n" + body
+	}
+	return body + "\n"
+}
+
+// resourceMarkdown returns every section of res as one markdown document
+func (m model) resourceMarkdown(res *resource) string {
+	parts := make([]string, 0, len(res.sections))
+	for i := range res.sections {
+		parts = append(parts, m.sectionMarkdown(res, &res.sections[i]))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// exportMarkdown returns the current section or the whole resource as
+// markdown, and a name for it
+func (m model) exportMarkdown(scope string) (string, string, bool) {
+	res := m.currentResource()
+	if res == nil {
+		return "", "", false
+	}
+	if scope == exportResource {
+		return m.resourceMarkdown(res), res.name, true
+	}
+	sec := m.currentSection()
+	if sec == nil {
+		return "", "", false
+	}
+	return m.sectionMarkdown(res, sec), res.name + " › " + sec.title, true
+}
+
+// copyMarkdown copies the current section or resource to the clipboard
+func (m *model) copyMarkdown(scope string) tea.Cmd {
+	text, name, ok := m.exportMarkdown(scope)
+	if !ok {
+		return nil
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		return m.showNotification("!", "Copy failed: "+err.Error(), "error")
+	}
+	return m.showNotification("📋", "Copied "+truncate(name, 30)+" as markdown", "success")
+}
+
+// startMarkdownSave asks where to save the current section or resource
+func (m *model) startMarkdownSave() tea.Cmd {
+	res := m.currentResource()
+	if res == nil {
+		return nil
+	}
+	params := []config.ActionParam{
+		{Name: "scope", Title: "Save", Options: []string{exportSection, exportResource}, Default: exportSection},
+		{Name: "path", Title: "File", Default: res.name + ".md", Required: true},
+	}
+	return m.collectActionParams("Save as Markdown", "💾", params, func(m *model, values map[string]string) tea.Cmd {
+		return m.saveMarkdown(values["scope"], values["path"])
+	})
+}
+
+// saveMarkdown writes the current section or resource to path
+func (m *model) saveMarkdown(scope, path string) tea.Cmd {
+	text, name, ok := m.exportMarkdown(scope)
+	if !ok {
+		return nil
+	}
+	path = expandHome(path)
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		slog.Error("failed to save markdown", "path", path, "error", err)
+		return m.showNotification("✗", "Save failed: "+err.Error(), "error")
+	}
+	return m.showNotification("💾", fmt.Sprintf("Saved %s to %s", truncate(name, 30), path), "success")
+}
+
+// cheatSheetMsg reports a finished cheat sheet export
+type cheatSheetMsg struct {
+	path string
+	err  error
+}
+
+// cheatSheet returns res for rendering as a cheat sheet. Dynamic sections
+// are included once they have loaded.
+func (m model) cheatSheet(res *resource) cheatsheet.Sheet {
+	sheet := cheatsheet.Sheet{Title: res.name, Description: res.description}
+	for i := range res.sections {
+		sec := &res.sections[i]
+		if sec.dynamic != nil && m.dynamicSections[dynamicKey(res, sec)].fetched.IsZero() {
+			continue
+		}
+		sheet.Sections = append(sheet.Sections, cheatsheet.Section{Title: sec.title, Markdown: m.sectionMarkdown(res, sec)})
+	}
+	return sheet
+}
+
+// getCheatSheetPaletteItem returns the palette action that exports a
+// resource as an HTML or PDF cheat sheet
+func (m *model) getCheatSheetPaletteItem() PaletteItem {
+	subtitle := "Save a resource as a printable HTML page"
+	if cheatsheet.PDFAvailable() {
+		subtitle = "Save a resource as a printable HTML page or PDF"
+	}
+	return PaletteItem{
+		ID:       "action:cheat_sheet",
+		Icon:     "🖨",
+		Title:    "Export Cheat Sheet",
+		Subtitle: subtitle,
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			return m.startCheatSheetExport()
+		},
+	}
+}
+
+// startCheatSheetExport asks which resource to export and where, starting
+// with the one open or selected
+func (m *model) startCheatSheetExport() tea.Cmd {
+	if len(m.resources) == 0 {
+		m.closePalette()
+		return m.showNotification("⚠", "No resources to export", "warning")
+	}
+	names := make([]string, len(m.resources))
+	for i, res := range m.resources {
+		names[i] = res.name
+	}
+	placeholder := "<resource>.html"
+	if cheatsheet.PDFAvailable() {
+		placeholder += ", or .pdf"
+	}
+	params := []config.ActionParam{
+		{Name: "resource", Title: "Resource", Options: names, Default: m.resources[min(m.resCursor, len(names)-1)].name},
+		{Name: "path", Title: "File", Placeholder: placeholder},
+	}
+	return m.collectActionParams("Export Cheat Sheet", "🖨", params, func(m *model, values map[string]string) tea.Cmd {
+		return m.exportCheatSheet(values["resource"], values["path"])
+	})
+}
+
+// exportCheatSheet writes the named resource to path, <name>.html when it
+// is empty, in the background
+func (m *model) exportCheatSheet(name, path string) tea.Cmd {
+	var sheet cheatsheet.Sheet
+	for i := range m.resources {
+		if m.resources[i].name == name {
+			sheet = m.cheatSheet(&m.resources[i])
+		}
+	}
+	if path == "" {
+		path = name + ".html"
+	}
+	path = expandHome(path)
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		return cheatSheetMsg{path: path, err: cheatsheet.Write(ctx, sheet, path)}
+	}
+}
+
+// handleCheatSheet reports a finished export
+func (m *model) handleCheatSheet(msg cheatSheetMsg) tea.Cmd {
+	if msg.err != nil {
+		slog.Error("failed to export cheat sheet", "path", msg.path, "error", msg.err)
+		return m.showNotification("✗", "Export failed: "+msg.err.Error(), "error")
+	}
+	return m.showNotification("🖨", "Exported "+msg.path, "success")
+}
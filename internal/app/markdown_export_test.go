@@ -0,0 +1,49 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSectionMarkdown(t *testing.T) {
+	res := resource{name: "docker", sections: []section{
+		{title: "Commands", content: "# Docker\n\n`docker ps` list containers ^run ^tag:basics ^requires:docker\n`docker logs {{c}}` logs ^run:c\n"},
+		{title: "Cleanup", content: "## Cleanup\n\n`docker system prune` ^run ^table ^onfail:`docker system df`\n"},
+		{title: "Running", dynamic: &dynamicSource{provider: "command", arg: "docker ps"}},
+	}}
+	m := model{resources: []resource{res}, currentView: viewDetail, dynamicSections: map[string]dynamicSectionState{}}
+	m.dynamicSections[dynamicKey(&res, &res.sections[2])] = dynamicSectionState{content: "web\ndb", fetched: time.Now()}
+
+	tests := []struct {
+		sec  int
+		want string
+	}{
+		{0, "# Docker\n\n`docker ps` list containers\n`docker logs {{c}}` logs\n"},
+		{1, "## Cleanup\n\n`docker system prune`\n"},
+	}
+	for _, tt := range tests {
+		if got := m.sectionMarkdown(&res, &res.sections[tt.sec]); got != tt.want {
+			t.Errorf("section %d = %q, want %q", tt.sec, got, tt.want)
+		}
+	}
+
+	all := m.resourceMarkdown(&res)
+	for _, want := range []string{"# Docker", "## Cleanup", "## Running\n\nweb\ndb"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("resource markdown missing %q:\n%s", want, all)
+		}
+	}
+	if strings.Contains(all, "^") {
+		t.Errorf("resource markdown kept annotations:\n%s", all)
+	}
+
+	path := filepath.Join(t.TempDir(), "runbook.md")
+	m.secCursor = 1
+	m.saveMarkdown(exportSection, path)
+	if data, err := os.ReadFile(path); err != nil || string(data) != tests[1].want {
+		t.Errorf("saved %q, %v", data, err)
+	}
+}
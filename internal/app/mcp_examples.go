@@ -0,0 +1,109 @@
+package app
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+
+	"github.com/charmbracelet/huh"
+)
+
+// A parameter's schema can declare a default and examples. The parameter
+// form starts with the default when neither a preset nor the argument
+// history gives a value, and ctrl+x replaces the focused field's value with
+// the next of its examples.
+
+// schemaValueString returns a schema value as it's typed into the form:
+// strings as they are, other values as JSON
+func schemaValueString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// schemaDefault returns the default a parameter's schema declares, or ""
+func schemaDefault(schema map[string]any) string {
+	return schemaValueString(schema["default"])
+}
+
+// schemaExamples returns the examples a parameter's schema declares, with
+// "example" as used by OpenAPI
+func schemaExamples(schema map[string]any) []string {
+	var examples []string
+	add := func(v any) {
+		if s := schemaValueString(v); s != "" && !slices.Contains(examples, s) {
+			examples = append(examples, s)
+		}
+	}
+	if list, ok := schema["examples"].([]any); ok {
+		for _, v := range list {
+			add(v)
+		}
+	}
+	add(schema["example"])
+	return examples
+}
+
+// cycleFormExample replaces the value of the focused parameter field with
+// the example after the current one. It reports false when the field has
+// no examples.
+func (m *model) cycleFormExample() bool {
+	pt := m.palette.PendingTool
+	if pt == nil || m.palette.InputForm == nil {
+		return false
+	}
+	field := m.palette.InputForm.GetFocusedField()
+	if field == nil {
+		return false
+	}
+	name := field.GetKey()
+	schema, _ := pt.Tool.InputSchema.Properties[name].(map[string]any)
+	examples := schemaExamples(schema)
+	value := pt.FormValues[name]
+	if len(examples) == 0 || value == nil {
+		return false
+	}
+
+	next := examples[0]
+	if i := slices.Index(examples, *value); i >= 0 {
+		next = examples[(i+1)%len(examples)]
+	}
+	*value = next
+	// Setting the value again makes the field show it
+	switch f := field.(type) {
+	case *huh.Input:
+		f.Value(value)
+	case *huh.Text:
+		f.Value(value)
+	default:
+		return false
+	}
+	return true
+}
+
+// boolFormValue gives a confirm field access to a form value, which holds
+// "true" or "false" like the other fields' strings
+type boolFormValue struct {
+	value *string
+}
+
+func (a boolFormValue) Get() bool {
+	b, _ := strconv.ParseBool(*a.value)
+	return b
+}
+
+func (a boolFormValue) Set(b bool) {
+	*a.value = strconv.FormatBool(b)
+}
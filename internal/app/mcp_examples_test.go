@@ -0,0 +1,59 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestSchemaDefaultsAndExamples(t *testing.T) {
+	oldDataDir := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = oldDataDir }()
+
+	tool := mcp.Tool{Name: "search_logs", InputSchema: mcp.ToolInputSchema{
+		Properties: map[string]any{
+			"query":   map[string]any{"type": "string", "examples": []any{"status:error", "service:web"}},
+			"limit":   map[string]any{"type": "integer", "default": float64(100)},
+			"tail":    map[string]any{"type": "boolean", "default": true},
+			"columns": map[string]any{"type": "array", "default": []any{"host", "message"}},
+		},
+		Required: []string{"query"},
+	}}
+
+	var m model
+	m.palette.PendingTool = &mcpPendingTool{ServerName: "datadog", Tool: tool, FormValues: mcpFormValues(tool, m.mcpArgDefaults("datadog", tool, nil))}
+	values := m.palette.PendingTool.FormValues
+	if *values["limit"] != "100" || *values["tail"] != "true" || *values["columns"] != `["host","message"]` || *values["query"] != "" {
+		t.Errorf("form starts with limit %q, tail %q, columns %q, query %q", *values["limit"], *values["tail"], *values["columns"], *values["query"])
+	}
+
+	m.buildParameterForm()
+	m.palette.State = PaletteStateCollectingParams
+	ctrlX := tea.KeyMsg{Type: tea.KeyCtrlX}
+	for _, want := range []string{"status:error", "service:web", "status:error"} {
+		m.handlePaletteKeys(ctrlX)
+		if *values["query"] != want {
+			t.Fatalf("ctrl+x set query to %q, want %q", *values["query"], want)
+		}
+	}
+	if view := ansi.Strip(m.palette.InputForm.View()); !strings.Contains(view, "status:error") || !strings.Contains(view, "ctrl+x") {
+		t.Errorf("form doesn't show the example:\n%s", view)
+	}
+
+	// The query field is focused; the limit field has no examples
+	m.palette.InputForm.NextField()
+	if m.cycleFormExample() {
+		t.Error("cycled a field without examples")
+	}
+
+	args, err := mcpToolArgs(tool, map[string]string{"tail": *values["tail"], "limit": *values["limit"]})
+	if err != nil || args["tail"] != true || args["limit"] != 100 {
+		t.Errorf("mcpToolArgs() = %v, %v", args, err)
+	}
+}
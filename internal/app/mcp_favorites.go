@@ -0,0 +1,113 @@
+package app
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Favorite MCP tools (mcp.favorites) are pinned to the top of the palette
+// and listed on the dashboard; ctrl+f in the palette adds or removes one.
+// Aliases (mcp.aliases) add a short palette name for a tool whose
+// parameter form starts filled with the alias's args.
+
+// mcpToolRef names a tool in mcp.favorites and mcp.aliases
+func mcpToolRef(server, tool string) string {
+	return server + "." + tool
+}
+
+// refersToTool reports whether ref, server.tool or a bare tool name, names
+// tool on server
+func refersToTool(ref, server, tool string) bool {
+	return ref == mcpToolRef(server, tool) || ref == tool
+}
+
+// mcpAliasItems returns an item per alias of tool on server
+func mcpAliasItems(aliases []config.MCPAlias, server, url string, tool mcp.Tool) []PaletteItem {
+	var items []PaletteItem
+	for _, a := range aliases {
+		if a.Name == "" || !refersToTool(a.Tool, server, tool.Name) {
+			continue
+		}
+		toolCopy := tool
+		items = append(items, PaletteItem{
+			ID:           "mcp-alias:" + a.Name,
+			Icon:         "↪",
+			Title:        a.Name,
+			Subtitle:     truncate("→ "+mcpToolRef(server, tool.Name), 50),
+			Category:     "alias",
+			MCPTool:      &toolCopy,
+			MCPServer:    server,
+			MCPServerURL: url,
+			MCPArgs:      a.Args,
+		})
+	}
+	return items
+}
+
+// pinMCPItems moves favorite tools, in the favorite category, and then
+// aliases and presets to the front of items, keeping the order of the rest
+func pinMCPItems(items []PaletteItem, favorites []string) []PaletteItem {
+	var pinned, aliases, rest []PaletteItem
+	for _, item := range items {
+		switch {
+		case item.Category == "alias" || item.Category == "preset":
+			aliases = append(aliases, item)
+		case item.Category == mcpHistoryCategory:
+			rest = append(rest, item)
+		case item.MCPTool != nil && slices.ContainsFunc(favorites, func(ref string) bool {
+			return ref == mcpToolRef(item.MCPServer, item.MCPTool.Name)
+		}):
+			item.Category = "favorite"
+			pinned = append(pinned, item)
+		case item.MCPTool != nil:
+			item.Category = "mcp"
+			rest = append(rest, item)
+		default:
+			rest = append(rest, item)
+		}
+	}
+	return append(append(pinned, aliases...), rest...)
+}
+
+// toggleMCPFavorite adds the selected tool to the favorites or removes it
+func (m *model) toggleMCPFavorite(item PaletteItem) tea.Cmd {
+	if item.MCPTool == nil || item.Category == "alias" || item.Category == "preset" || item.Category == mcpHistoryCategory {
+		return m.showNotification("⚠️", "Select an MCP tool to pin", "warning")
+	}
+	ref := mcpToolRef(item.MCPServer, item.MCPTool.Name)
+
+	pinned := !slices.Contains(m.config.MCP.Favorites, ref)
+	if pinned {
+		m.config.MCP.Favorites = append(m.config.MCP.Favorites, ref)
+	} else {
+		m.config.MCP.Favorites = slices.DeleteFunc(m.config.MCP.Favorites, func(f string) bool { return f == ref })
+	}
+	if err := config.Save(m.config); err != nil {
+		return m.showNotification("⚠️", "Failed to save favorites: "+err.Error(), "error")
+	}
+
+	m.palette.Items = pinMCPItems(m.palette.Items, m.config.MCP.Favorites)
+	m.palette.Filtered = m.filterPalette(m.palette.Query)
+	if i := slices.IndexFunc(m.palette.Filtered, func(it PaletteItem) bool { return it.ID == item.ID }); i >= 0 {
+		m.palette.Cursor = i
+	}
+	if pinned {
+		return m.showNotification("⭐", "Pinned "+ref, "success")
+	}
+	return m.showNotification("☆", "Unpinned "+ref, "info")
+}
+
+// mcpFormValues returns the parameter form values of tool, filled from
+// defaults
+func mcpFormValues(tool mcp.Tool, defaults map[string]string) map[string]*string {
+	values := make(map[string]*string)
+	for name := range tool.InputSchema.Properties {
+		val := defaults[name]
+		values[name] = &val
+	}
+	return values
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestMCPAliasItems(t *testing.T) {
+	tool := mcp.Tool{Name: "search_logs"}
+	aliases := []config.MCPAlias{
+		{Name: "logs", Tool: "datadog.search_logs", Args: map[string]string{"query": "status:error"}},
+		{Name: "any", Tool: "search_logs"},
+		{Name: "other", Tool: "sentry.search_logs"},
+	}
+
+	items := mcpAliasItems(aliases, "datadog", "http://dd/mcp", tool)
+	if len(items) != 2 || items[0].Title != "logs" || items[1].Title != "any" {
+		t.Fatalf("mcpAliasItems() = %+v, want logs and any", items)
+	}
+	if items[0].MCPArgs["query"] != "status:error" || items[0].MCPServerURL != "http://dd/mcp" {
+		t.Errorf("alias item = %+v, want its args and server", items[0])
+	}
+}
+
+func TestPinMCPItems(t *testing.T) {
+	tool := func(server, name string) PaletteItem {
+		return PaletteItem{ID: server + name, MCPTool: &mcp.Tool{Name: name}, MCPServer: server, Category: "mcp"}
+	}
+	items := []PaletteItem{
+		{ID: "action", Category: "action"},
+		tool("dd", "metrics"),
+		tool("dd", "logs"),
+		{ID: "alias", Category: "alias", MCPTool: &mcp.Tool{Name: "logs"}, MCPServer: "dd"},
+	}
+
+	got := pinMCPItems(items, []string{"dd.logs"})
+	ids := []string{}
+	for _, item := range got {
+		ids = append(ids, item.ID)
+	}
+	want := []string{"ddlogs", "alias", "action", "ddmetrics"}
+	if len(ids) != len(want) {
+		t.Fatalf("pinMCPItems() = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("pinMCPItems() = %v, want %v", ids, want)
+		}
+	}
+	if got[0].Category != "favorite" {
+		t.Errorf("favorite category = %q, want favorite", got[0].Category)
+	}
+
+	// Unpinning returns the tool to the MCP tools
+	if got := pinMCPItems(got, nil); got[0].ID != "alias" || got[len(got)-1].Category != "mcp" {
+		t.Errorf("pinMCPItems() without favorites = %+v", got)
+	}
+}
+
+func TestToggleMCPFavorite(t *testing.T) {
+	oldConfigDir := config.ConfigDir
+	config.ConfigDir = t.TempDir()
+	defer func() { config.ConfigDir = oldConfigDir }()
+
+	item := PaletteItem{ID: "mcp:dd:logs", MCPTool: &mcp.Tool{Name: "logs"}, MCPServer: "dd", Category: "mcp"}
+	m := model{config: config.CreateDefault("http://localhost:8001/mcp/")}
+	m.palette.Items = []PaletteItem{{ID: "action", Category: "action"}, item}
+
+	m.toggleMCPFavorite(item)
+	if len(m.config.MCP.Favorites) != 1 || m.config.MCP.Favorites[0] != "dd.logs" {
+		t.Fatalf("favorites = %v, want [dd.logs]", m.config.MCP.Favorites)
+	}
+	if m.palette.Filtered[m.palette.Cursor].ID != item.ID || m.palette.Filtered[0].Category != "favorite" {
+		t.Errorf("pinned tool is not selected at the top: %+v", m.palette.Filtered)
+	}
+	if saved := config.Load(""); len(saved.MCP.Favorites) != 1 {
+		t.Errorf("saved favorites = %v, want [dd.logs]", saved.MCP.Favorites)
+	}
+
+	m.toggleMCPFavorite(m.palette.Filtered[m.palette.Cursor])
+	if len(m.config.MCP.Favorites) != 0 {
+		t.Errorf("favorites = %v, want none after unpinning", m.config.MCP.Favorites)
+	}
+}
+
+func TestMCPFormValues(t *testing.T) {
+	tool := mcp.Tool{InputSchema: mcp.ToolInputSchema{Properties: map[string]any{"query": map[string]any{}, "limit": map[string]any{}}}}
+	values := mcpFormValues(tool, map[string]string{"query": "status:error", "unknown": "x"})
+	if len(values) != 2 || *values["query"] != "status:error" || *values["limit"] != "" {
+		t.Errorf("mcpFormValues() = %v", values)
+	}
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Every MCP tool call made from the palette is recorded in the MCP call
+// history: the server, tool, arguments, how long it took, the size of its
+// result and whether it failed. The calls are listed in the palette under
+// MCP History, where enter makes the call again and ctrl+e opens the
+// parameter form filled with its arguments. Arguments excluded from the
+// argument history are left out and asked for again.
+
+// mcpHistoryCategory is the category of the MCP call history items
+const mcpHistoryCategory = "mcp-history"
+
+// mcpCallRecord returns the history entry of a call of tool on the server
+// at serverURL with args
+func mcpCallRecord(serverURL, tool string, args map[string]any, started time.Time, res paletteResultMsg) config.MCPCall {
+	call := config.MCPCall{
+		Server:   serverURL,
+		Tool:     tool,
+		Time:     started,
+		Duration: time.Since(started),
+		Failed:   res.failed,
+	}
+	if !res.failed {
+		call.ResultSize = len(res.output)
+	}
+	if len(args) > 0 {
+		call.Args = make(map[string]string, len(args))
+		for name, value := range args {
+			call.Args[name] = schemaValueString(value)
+		}
+	}
+	return call
+}
+
+// recordMCPCalls adds calls to the MCP call history, without the
+// arguments excluded from the argument history, and saves it
+func (m *model) recordMCPCalls(calls []config.MCPCall) {
+	if len(calls) == 0 {
+		return
+	}
+	for _, call := range calls {
+		maps.DeleteFunc(call.Args, func(name, _ string) bool {
+			return m.config.ArgHistory.Excludes(name)
+		})
+		m.mcpHistory = config.AddMCPCall(m.mcpHistory, call)
+	}
+	if err := config.SaveMCPHistory(m.mcpHistory); err != nil {
+		slog.Warn("failed to save MCP call history", "error", err)
+	}
+}
+
+// knownMCPTool is a tool listed by a server, for the history items
+type knownMCPTool struct {
+	server string
+	tool   mcp.Tool
+}
+
+// mcpHistoryItems returns an item per recorded call of a tool in known,
+// keyed by server URL and tool name, most recent first
+func (m model) mcpHistoryItems(known map[[2]string]knownMCPTool) []PaletteItem {
+	var items []PaletteItem
+	for i, call := range m.mcpHistory {
+		k, ok := known[[2]string{call.Server, call.Tool}]
+		if !ok {
+			continue
+		}
+		tool, url, args := k.tool, call.Server, call.Args
+		item := PaletteItem{
+			ID:           fmt.Sprintf("mcp-history:%d", i),
+			Icon:         "↺",
+			Title:        strings.TrimSpace(call.Tool + " " + formatPresetArgs(args)),
+			Subtitle:     truncate(formatMCPCall(call, k.server), 50),
+			Category:     mcpHistoryCategory,
+			MCPTool:      &tool,
+			MCPServer:    k.server,
+			MCPServerURL: url,
+			MCPArgs:      args,
+		}
+		item.Handler = func(m *model) tea.Cmd {
+			// Excluded arguments weren't kept, so a call that needs one
+			// asks for it again
+			for _, name := range tool.InputSchema.Required {
+				if args[name] == "" {
+					return m.startMCPToolInput(item)
+				}
+			}
+			return m.runMCPPreset(url, tool, args)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// formatMCPCall describes a recorded call on server, e.g.
+// "datadog · 5m ago · 1.2s · 3.4 KB"
+func formatMCPCall(call config.MCPCall, server string) string {
+	parts := []string{server, formatTimeAgo(call.Time), call.Duration.Round(10 * time.Millisecond).String()}
+	switch {
+	case call.Failed:
+		parts = append(parts, "failed")
+	case call.ResultSize >= 1024:
+		parts = append(parts, fmt.Sprintf("%.1f KB", float64(call.ResultSize)/1024))
+	default:
+		parts = append(parts, fmt.Sprintf("%d B", call.ResultSize))
+	}
+	return strings.Join(parts, " · ")
+}
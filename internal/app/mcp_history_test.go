@@ -0,0 +1,79 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestMCPCallHistory(t *testing.T) {
+	oldDataDir := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = oldDataDir }()
+
+	const url = "http://localhost:8001/mcp"
+	tool := mcp.Tool{Name: "search_logs", InputSchema: mcp.ToolInputSchema{
+		Properties: map[string]any{
+			"query":   map[string]any{"type": "string"},
+			"limit":   map[string]any{"type": "integer"},
+			"api_key": map[string]any{"type": "string"},
+		},
+		Required: []string{"query"},
+	}}
+
+	var m model
+	args := map[string]any{"query": "status:error", "limit": 50, "api_key": "s3cret"}
+	res := paletteResultMsg{title: "search_logs", output: strings.Repeat("x", 2048)}
+	res.calls = []config.MCPCall{mcpCallRecord(url, "search_logs", args, time.Now().Add(-1500*time.Millisecond), res)}
+	next, _ := m.Update(res)
+	m = next.(model)
+	m.recordMCPCalls([]config.MCPCall{{Server: url, Tool: "search_logs", Time: time.Now(), Failed: true}})
+
+	calls := config.LoadMCPHistory()
+	if len(calls) != 2 || !calls[0].Failed {
+		t.Fatalf("history = %+v, want the failed call first", calls)
+	}
+	call := calls[1]
+	if call.Args["query"] != "status:error" || call.Args["limit"] != "50" || call.ResultSize != 2048 || call.Duration < time.Second {
+		t.Errorf("recorded call = %+v", call)
+	}
+	if _, ok := call.Args["api_key"]; ok {
+		t.Error("api_key recorded")
+	}
+
+	known := map[[2]string]knownMCPTool{{url, "search_logs"}: {server: "datadog", tool: tool}}
+	items := m.mcpHistoryItems(known)
+	if len(items) != 2 || items[1].Title != "search_logs limit=50 query=status:error" || !strings.Contains(items[1].Subtitle, "datadog · just now · 1.5s · 2.0 KB") {
+		t.Fatalf("history items = %+v", items)
+	}
+	if len(m.mcpHistoryItems(map[[2]string]knownMCPTool{})) != 0 {
+		t.Error("listed calls of tools the servers don't have")
+	}
+
+	// Enter calls again; the failed call lacks the required query and
+	// opens the form instead
+	items[1].Handler(&m)
+	if m.palette.State != PaletteStateExecuting {
+		t.Errorf("enter didn't call the tool, state = %v", m.palette.State)
+	}
+	m.cancelPaletteExecution()
+	m.palette.State = PaletteStateSearching
+	items[0].Handler(&m)
+	if m.palette.State != PaletteStateCollectingParams || m.palette.PendingTool == nil {
+		t.Fatalf("call without its required argument didn't open the form, state = %v", m.palette.State)
+	}
+
+	// ctrl+e opens the form filled with the call's arguments
+	m.palette.State = PaletteStateSearching
+	m.palette.Filtered = items
+	m.palette.Cursor = 1
+	m.handlePaletteKeys(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if pt := m.palette.PendingTool; pt == nil || *pt.FormValues["query"] != "status:error" || *pt.FormValues["limit"] != "50" {
+		t.Errorf("ctrl+e didn't fill the form with the call's arguments")
+	}
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	mcppkg "github.com/htelsiz/skitz/internal/mcp"
+)
+
+// getMCPImportPaletteItem returns the action that imports MCP servers from
+// Claude Desktop and Cursor
+func (m *model) getMCPImportPaletteItem() PaletteItem {
+	return PaletteItem{
+		ID:       "action:import_mcp",
+		Icon:     "🔌",
+		Title:    "Import MCP Servers",
+		Subtitle: "Add servers from Claude Desktop or Cursor",
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			found := mcppkg.Discover(m.config.MCP.Servers)
+			if len(found) == 0 {
+				return m.showNotification("🔌", "No new MCP servers found in Claude Desktop or Cursor", "info")
+			}
+			m.showMCPImport(found)
+			return nil
+		},
+	}
+}
+
+// showMCPImport replaces the palette list with the servers found, after
+// an item importing all of them. Selecting a server imports it.
+func (m *model) showMCPImport(found []mcppkg.Found) {
+	var importable []mcppkg.Found
+	for _, f := range found {
+		if f.Importable() {
+			importable = append(importable, f)
+		}
+	}
+
+	var items []PaletteItem
+	if len(importable) > 1 {
+		items = append(items, PaletteItem{
+			ID:       "mcp-import:all",
+			Icon:     "+",
+			Title:    fmt.Sprintf("Import All (%d)", len(importable)),
+			Subtitle: "Add every HTTP server found",
+			Category: "import",
+			Handler: func(m *model) tea.Cmd {
+				m.closePalette()
+				return m.importMCPServers(importable)
+			},
+		})
+	}
+	for _, f := range found {
+		item := PaletteItem{
+			ID:       "mcp-import:" + f.Source + ":" + f.Server.Name,
+			Icon:     "🔌",
+			Title:    f.Server.Name,
+			Subtitle: truncate(f.Source+" · "+f.Server.URL, 60),
+			Category: "import",
+			Handler: func(m *model) tea.Cmd {
+				cmd := m.importMCPServers([]mcppkg.Found{f})
+				rest := slices.DeleteFunc(slices.Clone(found), func(o mcppkg.Found) bool { return o == f })
+				if !slices.ContainsFunc(rest, mcppkg.Found.Importable) {
+					m.closePalette()
+				} else {
+					m.showMCPImport(rest)
+				}
+				return cmd
+			},
+		}
+		if !f.Importable() {
+			item.Icon = "○"
+			item.Subtitle = truncate(f.Source+" · stdio: "+f.Command, 60)
+			item.Commands = []string{f.Command}
+			item.Handler = func(m *model) tea.Cmd {
+				return m.showNotification("⚠️", f.Server.Name+" runs over stdio; skitz connects to MCP servers over HTTP", "warning")
+			}
+		}
+		items = append(items, item)
+	}
+
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
+}
+
+// importMCPServers adds the servers of found to the config and starts
+// polling them
+func (m *model) importMCPServers(found []mcppkg.Found) tea.Cmd {
+	var names []string
+	for _, f := range found {
+		m.config.MCP.Servers = append(m.config.MCP.Servers, f.Server)
+		names = append(names, f.Server.Name)
+	}
+	if err := config.Save(m.config); err != nil {
+		return m.showNotification("!", "Failed to save MCP servers: "+err.Error(), "error")
+	}
+	return tea.Batch(m.restartMCPPolling(), m.showNotification("✓", "Imported "+strings.Join(names, ", "), "success"))
+}
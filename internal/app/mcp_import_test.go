@@ -0,0 +1,43 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+	mcppkg "github.com/htelsiz/skitz/internal/mcp"
+)
+
+func TestMCPImport(t *testing.T) {
+	oldConfigDir := config.ConfigDir
+	config.ConfigDir = t.TempDir()
+	defer func() { config.ConfigDir = oldConfigDir }()
+
+	found := []mcppkg.Found{
+		{Server: config.MCPServerConfig{Name: "linear", URL: "https://mcp.linear.app/sse"}, Source: "Claude Desktop"},
+		{Server: config.MCPServerConfig{Name: "filesystem"}, Source: "Claude Desktop", Command: "npx server-filesystem"},
+		{Server: config.MCPServerConfig{Name: "datadog", URL: "https://mcp.datadoghq.com/mcp"}, Source: "Cursor"},
+	}
+	m := model{config: config.CreateDefault("http://localhost:8001/mcp/")}
+	m.palette.State = PaletteStateSearching
+	m.showMCPImport(found)
+
+	if len(m.palette.Items) != 4 || m.palette.Items[0].Title != "Import All (2)" {
+		t.Fatalf("import list = %+v, want Import All and the three servers", m.palette.Items)
+	}
+
+	// Importing one server leaves the others listed
+	m.palette.Items[1].Handler(&m)
+	servers := config.Load("").MCP.Servers
+	if len(servers) != 2 || servers[1].Name != "linear" {
+		t.Fatalf("saved servers = %+v, want linear added", servers)
+	}
+	if len(m.palette.Items) != 2 || m.palette.Items[0].Title != "filesystem" {
+		t.Errorf("import list after importing linear = %+v", m.palette.Items)
+	}
+
+	// stdio servers are not imported
+	m.palette.Items[0].Handler(&m)
+	if len(m.config.MCP.Servers) != 2 {
+		t.Errorf("servers = %+v, want the stdio server left out", m.config.MCP.Servers)
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// A preset (mcp.presets) is a tool call saved from its parameter form.
+// Enter runs it with the saved arguments, ctrl+e opens the form filled
+// with them, and ctrl+y copies the preset as YAML to share.
+
+// mcpPresetItems returns an item per preset of tool on server
+func mcpPresetItems(presets []config.MCPPreset, server, url string, tool mcp.Tool) []PaletteItem {
+	var items []PaletteItem
+	for _, p := range presets {
+		if p.Name == "" || !refersToTool(p.Tool, server, tool.Name) {
+			continue
+		}
+		toolCopy := tool
+		args := p.Args
+		items = append(items, PaletteItem{
+			ID:           "mcp-preset:" + p.Name,
+			Icon:         "▶",
+			Title:        p.Name,
+			Subtitle:     truncate(mcpToolRef(server, tool.Name)+" "+formatPresetArgs(args), 50),
+			Category:     "preset",
+			MCPTool:      &toolCopy,
+			MCPServer:    server,
+			MCPServerURL: url,
+			MCPArgs:      args,
+			Handler: func(m *model) tea.Cmd {
+				return m.runMCPPreset(url, toolCopy, args)
+			},
+		})
+	}
+	return items
+}
+
+// formatPresetArgs lists args as name=value, sorted by name
+func formatPresetArgs(args map[string]string) string {
+	var parts []string
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		parts = append(parts, name+"="+args[name])
+	}
+	return strings.Join(parts, " ")
+}
+
+// runMCPPreset calls tool with a preset's arguments
+func (m *model) runMCPPreset(serverURL string, tool mcp.Tool, values map[string]string) tea.Cmd {
+	if m.offline {
+		return m.showNotification("✈", errMCPOffline.Error(), "warning")
+	}
+	args, err := mcpToolArgs(tool, values)
+	if err != nil {
+		return m.showNotification("⚠️", err.Error(), "warning")
+	}
+	ctx, spin := m.startPaletteExecution("Executing tool...")
+	return tea.Batch(spin, executeMCPToolWithArgs(ctx, serverURL, tool.Name, args))
+}
+
+// saveMCPPreset saves a call of the tool ref with values as the preset
+// name, replacing a preset of that name
+func (m *model) saveMCPPreset(name, ref string, values map[string]string) tea.Cmd {
+	args := make(map[string]string)
+	for k, v := range values {
+		if v != "" {
+			args[k] = v
+		}
+	}
+	preset := config.MCPPreset{Name: name, Tool: ref, Args: args}
+
+	if i := slices.IndexFunc(m.config.MCP.Presets, func(p config.MCPPreset) bool { return p.Name == name }); i >= 0 {
+		m.config.MCP.Presets[i] = preset
+	} else {
+		m.config.MCP.Presets = append(m.config.MCP.Presets, preset)
+	}
+	if err := config.Save(m.config); err != nil {
+		return m.showNotification("⚠️", "Failed to save preset: "+err.Error(), "error")
+	}
+	return m.showNotification("▶", "Saved preset "+name, "success")
+}
+
+// presetYAML returns a preset item as an mcp.presets entry
+func presetYAML(item PaletteItem) (string, error) {
+	data, err := yaml.Marshal([]config.MCPPreset{{
+		Name: item.Title,
+		Tool: mcpToolRef(item.MCPServer, item.MCPTool.Name),
+		Args: item.MCPArgs,
+	}})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode preset: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func searchLogsTool() mcp.Tool {
+	return mcp.Tool{Name: "search_logs", InputSchema: mcp.ToolInputSchema{Properties: map[string]any{
+		"query":  map[string]any{"type": "string"},
+		"limit":  map[string]any{"type": "integer"},
+		"live":   map[string]any{"type": "boolean"},
+		"fields": map[string]any{"type": "array"},
+	}}}
+}
+
+func TestMCPToolArgs(t *testing.T) {
+	args, err := mcpToolArgs(searchLogsTool(), map[string]string{
+		"query":  "status:error",
+		"limit":  "20",
+		"live":   "yes",
+		"fields": `["host"]`,
+		"from":   "",
+	})
+	if err != nil {
+		t.Fatalf("mcpToolArgs() error = %v", err)
+	}
+	if args["query"] != "status:error" || args["limit"] != 20 || args["live"] != true || len(args["fields"].([]any)) != 1 {
+		t.Errorf("mcpToolArgs() = %v", args)
+	}
+	if _, ok := args["from"]; ok {
+		t.Error("empty values should be left out")
+	}
+
+	if _, err := mcpToolArgs(searchLogsTool(), map[string]string{"limit": "many"}); err == nil {
+		t.Error("mcpToolArgs() error = nil, want an invalid integer")
+	}
+}
+
+func TestMCPPresetItems(t *testing.T) {
+	presets := []config.MCPPreset{
+		{Name: "errors", Tool: "datadog.search_logs", Args: map[string]string{"query": "status:error", "limit": "20"}},
+		{Name: "elsewhere", Tool: "sentry.search_logs"},
+	}
+	items := mcpPresetItems(presets, "datadog", "http://dd/mcp", searchLogsTool())
+	if len(items) != 1 {
+		t.Fatalf("mcpPresetItems() = %+v, want the datadog preset", items)
+	}
+	if items[0].Subtitle != "datadog.search_logs limit=20 query=status:error" || items[0].Handler == nil {
+		t.Errorf("preset item = %+v", items[0])
+	}
+
+	text, err := presetYAML(items[0])
+	if err != nil {
+		t.Fatalf("presetYAML() error = %v", err)
+	}
+	var shared []config.MCPPreset
+	if err := yaml.Unmarshal([]byte(text), &shared); err != nil {
+		t.Fatalf("presetYAML() is not valid YAML: %v", err)
+	}
+	if len(shared) != 1 || shared[0].Name != "errors" || shared[0].Tool != "datadog.search_logs" || shared[0].Args["limit"] != "20" {
+		t.Errorf("shared preset = %+v", shared)
+	}
+}
+
+func TestSaveMCPPreset(t *testing.T) {
+	oldConfigDir := config.ConfigDir
+	config.ConfigDir = t.TempDir()
+	defer func() { config.ConfigDir = oldConfigDir }()
+
+	m := model{config: config.CreateDefault("http://localhost:8001/mcp/")}
+	m.saveMCPPreset("errors", "datadog.search_logs", map[string]string{"query": "status:error", "limit": ""})
+	m.saveMCPPreset("errors", "datadog.search_logs", map[string]string{"query": "status:warn"})
+
+	presets := config.Load("").MCP.Presets
+	if len(presets) != 1 || presets[0].Args["query"] != "status:warn" {
+		t.Fatalf("saved presets = %+v, want errors replaced", presets)
+	}
+	if _, ok := presets[0].Args["limit"]; ok {
+		t.Error("empty arguments should not be saved")
+	}
+}
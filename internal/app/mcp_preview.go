@@ -0,0 +1,209 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	runtimepkg "github.com/htelsiz/skitz/internal/runtime"
+)
+
+// An MCP tool's parameter form shows the tools/call request its values
+// make, updated as they're typed, beside the form when the palette is wide
+// enough and under it otherwise. ctrl+y copies the request as curl
+// commands that open a session with the server and make the same call.
+
+// mcpPreviewMinWidth is the palette width from which the preview is shown
+// beside the form
+const mcpPreviewMinWidth = 140
+
+// mcpCallRequest is the JSON-RPC request calling an MCP tool
+type mcpCallRequest struct {
+	JSONRPC string            `json:"jsonrpc"`
+	ID      int               `json:"id"`
+	Method  string            `json:"method"`
+	Params  mcpCallParameters `json:"params"`
+}
+
+type mcpCallParameters struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// newMCPCallRequest returns the request calling tool with args
+func newMCPCallRequest(tool string, args map[string]any) mcpCallRequest {
+	if args == nil {
+		args = map[string]any{}
+	}
+	return mcpCallRequest{
+		JSONRPC: "2.0",
+		ID:      1,
+		Method:  "tools/call",
+		Params:  mcpCallParameters{Name: tool, Arguments: args},
+	}
+}
+
+// marshalJSON encodes v without escaping HTML characters, indented when
+// indent is set
+func marshalJSON(v any, indent bool) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if indent {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(v); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// previewArgs returns the arguments the form's current values convert to,
+// leaving out the values that don't, with the problems of the form:
+// invalid values and missing required parameters
+func (pt *mcpPendingTool) previewArgs() (map[string]any, []string) {
+	args := make(map[string]any)
+	var problems []string
+	names := make([]string, 0, len(pt.FormValues))
+	for name := range pt.FormValues {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		value := ""
+		if ptr := pt.FormValues[name]; ptr != nil {
+			value = strings.TrimSpace(*ptr)
+		}
+		if value == "" {
+			if slices.Contains(pt.Tool.InputSchema.Required, name) {
+				problems = append(problems, name+" is required")
+			}
+			continue
+		}
+		converted, err := mcpToolArgs(pt.Tool, map[string]string{name: value})
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		for k, v := range converted {
+			args[k] = v
+		}
+	}
+	return args, problems
+}
+
+// mcpCurlCommands returns shell commands making request to the server at
+// serverURL with curl: initialize a session, as streamable HTTP servers
+// require, then send the request in it
+func mcpCurlCommands(serverURL string, request mcpCallRequest) string {
+	initialize := marshalJSON(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      0,
+		"method":  "initialize",
+		"params": map[string]any{
+			"protocolVersion": mcp.LATEST_PROTOCOL_VERSION,
+			"capabilities":    map[string]any{},
+			"clientInfo":      map[string]any{"name": "curl", "version": "1.0"},
+		},
+	}, false)
+	initialized := `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+	headers := `-H 'Content-Type: application/json' -H 'Accept: application/json, text/event-stream'`
+	session := `${sid:+-H "Mcp-Session-Id: $sid"}`
+
+	return strings.Join([]string{
+		"url=" + runtimepkg.Quote(serverURL),
+		fmt.Sprintf(`sid=$(curl -sS -D - -o /dev/null "$url" %s -d %s | tr -d '\r' | awk 'tolower($1) == "mcp-session-id:" { print $2 }')`,
+			headers, runtimepkg.Quote(initialize)),
+		fmt.Sprintf(`curl -sS -o /dev/null "$url" %s %s -d %s`, headers, session, runtimepkg.Quote(initialized)),
+		fmt.Sprintf(`curl -sS "$url" %s %s -H 'MCP-Protocol-Version: %s' -d %s`,
+			headers, session, mcp.LATEST_PROTOCOL_VERSION, runtimepkg.Quote(marshalJSON(request, false))),
+	}, "\n")
+}
+
+// copyMCPCurl copies the pending tool call as curl commands
+func (m *model) copyMCPCurl() tea.Cmd {
+	pt := m.palette.PendingTool
+	if pt == nil {
+		return nil
+	}
+	args, problems := pt.previewArgs()
+	text := mcpCurlCommands(pt.ServerURL, newMCPCallRequest(pt.Tool.Name, args))
+	if err := clipboard.WriteAll(text); err != nil {
+		return m.showNotification("❌", "Failed to copy: "+err.Error(), "error")
+	}
+	if len(problems) > 0 {
+		return m.showNotification("📋", "Copied as curl, without: "+strings.Join(problems, "; "), "warning")
+	}
+	return m.showNotification("📋", "Tool call copied as curl", "success")
+}
+
+// paramFormWidth returns the width of the parameter form, leaving room
+// for the preview beside it
+func (m model) paramFormWidth() int {
+	width, _ := m.paletteSize()
+	if width < mcpPreviewMinWidth {
+		return 100
+	}
+	return min(100, width-m.mcpPreviewWidth()-8)
+}
+
+// mcpPreviewWidth returns the width of the preview beside the form, or 0
+// when it goes under the form
+func (m model) mcpPreviewWidth() int {
+	width, _ := m.paletteSize()
+	if width < mcpPreviewMinWidth {
+		return 0
+	}
+	return width * 2 / 5
+}
+
+// renderMCPPreview renders the request the pending tool's form makes in
+// at most width columns and height lines
+func (m model) renderMCPPreview(width, height int) string {
+	pt := m.palette.PendingTool
+	if pt == nil {
+		return ""
+	}
+	args, problems := pt.previewArgs()
+
+	titleStyle := lipgloss.NewStyle().Foreground(primary).Bold(true)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Background(lipgloss.Color("238")).
+		Padding(0, 1)
+	hintStyle := lipgloss.NewStyle().Foreground(subtle)
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	jsonStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+
+	inner := width - 4
+	lines := []string{
+		titleStyle.Render("Request") + hintStyle.Render("  ") + keyStyle.Render("ctrl+y") + hintStyle.Render(" copy as curl"),
+		"",
+	}
+	for _, problem := range problems {
+		lines = append(lines, warnStyle.Render(truncate("⚠ "+problem, inner)))
+	}
+	if len(problems) > 0 {
+		lines = append(lines, "")
+	}
+	for line := range strings.SplitSeq(marshalJSON(newMCPCallRequest(pt.Tool.Name, args), true), "\n") {
+		lines = append(lines, jsonStyle.Render(truncate(line, inner)))
+	}
+	if height > 0 && len(lines) > height {
+		lines = append(lines[:height-1], hintStyle.Render("…"))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(dimBorder).
+		Padding(0, 1).
+		Width(width - 2).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestMCPCallPreview(t *testing.T) {
+	oldDataDir := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = oldDataDir }()
+
+	tool := mcp.Tool{Name: "search_logs", InputSchema: mcp.ToolInputSchema{
+		Properties: map[string]any{
+			"query": map[string]any{"type": "string"},
+			"limit": map[string]any{"type": "integer", "maximum": float64(100)},
+			"tail":  map[string]any{"type": "boolean"},
+		},
+		Required: []string{"query"},
+	}}
+	m := model{width: 200, height: 50}
+	m.palette.State = PaletteStateCollectingParams
+	m.palette.PendingTool = &mcpPendingTool{ServerName: "datadog", ServerURL: "http://localhost:8001/mcp", Tool: tool, FormValues: mcpFormValues(tool, map[string]string{"limit": "500", "tail": "true"})}
+	m.buildParameterForm()
+
+	args, problems := m.palette.PendingTool.previewArgs()
+	if len(args) != 1 || args["tail"] != true || strings.Join(problems, "; ") != "limit must be at most 100; query is required" {
+		t.Errorf("previewArgs() = %v, %v", args, problems)
+	}
+
+	// The request follows the values as they change
+	*m.palette.PendingTool.FormValues["query"] = "status:error"
+	*m.palette.PendingTool.FormValues["limit"] = "50"
+	view := ansi.Strip(m.renderPalette())
+	for _, want := range []string{`"method": "tools/call"`, `"query": "status:error"`, `"limit": 50`, "copy as curl"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("preview lacks %s:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "⚠") {
+		t.Errorf("preview shows problems of valid values:\n%s", view)
+	}
+	// Wide palettes show it beside the form
+	for line := range strings.SplitSeq(view, "\n") {
+		if i := strings.Index(line, "Request"); i >= 0 && ansi.StringWidth(line[:i]) < m.paramFormWidth() {
+			t.Errorf("preview isn't beside the form: %q", line)
+		}
+	}
+
+	script := mcpCurlCommands("http://localhost:8001/mcp", newMCPCallRequest("search_logs", map[string]any{"query": "it's"}))
+	for _, want := range []string{
+		"url=http://localhost:8001/mcp\n",
+		`"method":"initialize"`,
+		`"method":"notifications/initialized"`,
+		`-d '{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search_logs","arguments":{"query":"it'\''s"}}}'`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("curl commands lack %s:\n%s", want, script)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// An MCP tool's parameter form checks values against the JSON Schema of
+// each parameter as they are typed: pattern, minimum and maximum, length,
+// format (uri, date-time, date, email) and, for arrays, the number and
+// schema of their items. Values that break a constraint are reported
+// under the field instead of being rejected by the server.
+
+// schemaType returns the type a schema declares, or "" when it declares
+// none or several
+func schemaType(schema map[string]any) string {
+	t, _ := schema["type"].(string)
+	return t
+}
+
+// schemaNumber returns the number a schema keyword is set to
+func schemaNumber(schema map[string]any, keyword string) (float64, bool) {
+	switch n := schema[keyword].(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// checkParamValue checks a value typed into the form for a parameter with
+// schema. Empty values are left to the required check.
+func checkParamValue(schema map[string]any, value string) error {
+	var v any
+	switch schemaType(schema) {
+	case "number", "integer":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			if schemaType(schema) == "integer" {
+				return errors.New("must be an integer")
+			}
+			return errors.New("must be a number")
+		}
+		v = n
+	case "boolean":
+		return nil
+	case "array", "object":
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return fmt.Errorf("must be JSON: %v", err)
+		}
+	default:
+		v = value
+	}
+	return checkSchemaValue(schema, v)
+}
+
+// checkSchemaValue checks a decoded JSON value against schema
+func checkSchemaValue(schema map[string]any, v any) error {
+	switch schemaType(schema) {
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		return checkString(schema, s)
+	case "number", "integer":
+		n, ok := v.(float64)
+		if !ok {
+			return errors.New("must be a number")
+		}
+		if schemaType(schema) == "integer" && n != math.Trunc(n) {
+			return errors.New("must be an integer")
+		}
+		return checkRange(schema, n)
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return errors.New("must be true or false")
+		}
+	case "array":
+		items, ok := v.([]any)
+		if !ok {
+			return errors.New("must be a JSON array")
+		}
+		return checkArray(schema, items)
+	case "object":
+		if _, ok := v.(map[string]any); !ok {
+			return errors.New("must be a JSON object")
+		}
+	}
+	return nil
+}
+
+// checkString checks the length, pattern and format of s
+func checkString(schema map[string]any, s string) error {
+	n := utf8.RuneCountInString(s)
+	if min, ok := schemaNumber(schema, "minLength"); ok && float64(n) < min {
+		return fmt.Errorf("must be at least %v characters", min)
+	}
+	if max, ok := schemaNumber(schema, "maxLength"); ok && float64(n) > max {
+		return fmt.Errorf("must be at most %v characters", max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		// Patterns Go can't compile, such as lookaheads, are left to the server
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(s) {
+			return fmt.Errorf("must match %s", pattern)
+		}
+	}
+	format, _ := schema["format"].(string)
+	return checkFormat(format, s)
+}
+
+// checkFormat checks s against the formats skitz knows
+func checkFormat(format, s string) error {
+	switch format {
+	case "uri", "url":
+		if u, err := url.Parse(s); err != nil || u.Scheme == "" {
+			return errors.New("must be a URI such as https://example.com/path")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return errors.New("must be a date-time such as 2025-01-31T09:30:00Z")
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return errors.New("must be a date such as 2025-01-31")
+		}
+	case "email":
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return errors.New("must be an email address")
+		}
+	}
+	return nil
+}
+
+// checkRange checks n against the minimum and maximum of schema
+func checkRange(schema map[string]any, n float64) error {
+	if min, ok := schemaNumber(schema, "minimum"); ok && n < min {
+		return fmt.Errorf("must be at least %v", min)
+	}
+	if max, ok := schemaNumber(schema, "maximum"); ok && n > max {
+		return fmt.Errorf("must be at most %v", max)
+	}
+	if min, ok := schemaNumber(schema, "exclusiveMinimum"); ok && n <= min {
+		return fmt.Errorf("must be greater than %v", min)
+	}
+	if max, ok := schemaNumber(schema, "exclusiveMaximum"); ok && n >= max {
+		return fmt.Errorf("must be less than %v", max)
+	}
+	return nil
+}
+
+// checkArray checks the number of items and each item against the items
+// schema
+func checkArray(schema map[string]any, items []any) error {
+	if min, ok := schemaNumber(schema, "minItems"); ok && float64(len(items)) < min {
+		return fmt.Errorf("must have at least %v items", min)
+	}
+	if max, ok := schemaNumber(schema, "maxItems"); ok && float64(len(items)) > max {
+		return fmt.Errorf("must have at most %v items", max)
+	}
+	itemSchema, ok := schema["items"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	for i, item := range items {
+		if err := checkSchemaValue(itemSchema, item); err != nil {
+			return fmt.Errorf("item %d %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// paramValidator returns the form validation of a parameter with schema
+func paramValidator(name string, schema map[string]any, required bool) func(string) error {
+	return func(s string) error {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			if required {
+				return fmt.Errorf("%s is required", name)
+			}
+			return nil
+		}
+		return checkParamValue(schema, s)
+	}
+}
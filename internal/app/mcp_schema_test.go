@@ -0,0 +1,71 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestCheckParamValue(t *testing.T) {
+	var props map[string]map[string]any
+	err := json.Unmarshal([]byte(`{
+		"name":   {"type": "string", "pattern": "^[a-z-]+$", "minLength": 3, "maxLength": 8},
+		"url":    {"type": "string", "format": "uri"},
+		"since":  {"type": "string", "format": "date-time"},
+		"limit":  {"type": "integer", "minimum": 1, "maximum": 100},
+		"ratio":  {"type": "number", "exclusiveMinimum": 0},
+		"tags":   {"type": "array", "maxItems": 2, "items": {"type": "string", "minLength": 2}},
+		"filter": {"type": "object"}
+	}`), &props)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		param, value, want string
+	}{
+		{"name", "web-api", ""},
+		{"name", "Web", "must match ^[a-z-]+$"},
+		{"name", "ab", "must be at least 3 characters"},
+		{"name", "much-too-long", "must be at most 8 characters"},
+		{"url", "https://example.com/x", ""},
+		{"url", "example.com", "must be a URI"},
+		{"since", "2025-01-31T09:30:00+01:00", ""},
+		{"since", "yesterday", "must be a date-time"},
+		{"limit", "100", ""},
+		{"limit", "0", "must be at least 1"},
+		{"limit", "2.5", "must be an integer"},
+		{"ratio", "0", "must be greater than 0"},
+		{"tags", `["ab", "cd"]`, ""},
+		{"tags", `["ab", "c"]`, "item 2 must be at least 2 characters"},
+		{"tags", `["ab", "cd", "ef"]`, "must have at most 2 items"},
+		{"tags", `["ab", 3]`, "item 2 must be a string"},
+		{"tags", `ab`, "must be JSON"},
+		{"filter", `[]`, "must be a JSON object"},
+	}
+	for _, tt := range tests {
+		err := checkParamValue(props[tt.param], tt.value)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s = %q: unexpected error %v", tt.param, tt.value, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("%s = %q: error %v, want %q", tt.param, tt.value, err, tt.want)
+		}
+	}
+
+	validate := paramValidator("name", props["name"], true)
+	if err := validate("  "); err == nil || err.Error() != "name is required" {
+		t.Errorf("validate(blank) = %v, want required", err)
+	}
+	if err := paramValidator("name", props["name"], false)(""); err != nil {
+		t.Errorf("validate(blank) of an optional parameter = %v", err)
+	}
+
+	// Preset values get the same checks as the form
+	tool := mcp.Tool{Name: "search", InputSchema: mcp.ToolInputSchema{Properties: map[string]any{"limit": map[string]any(props["limit"])}}}
+	if _, err := mcpToolArgs(tool, map[string]string{"limit": "500"}); err == nil || err.Error() != "limit must be at most 100" {
+		t.Errorf("mcpToolArgs() error = %v, want the maximum", err)
+	}
+}
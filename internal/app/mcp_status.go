@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -10,30 +11,125 @@ import (
 	mcppkg "github.com/htelsiz/skitz/internal/mcp"
 )
 
+// applyMCPRetryPolicy configures MCP retries from cfg
+func applyMCPRetryPolicy(cfg config.MCPRetryConfig) {
+	mcppkg.SetRetryPolicy(mcppkg.RetryPolicy{
+		Attempts:  cfg.Attempts,
+		BaseDelay: cfg.BaseDelay,
+		MaxDelay:  cfg.MaxDelay,
+	})
+}
+
+// mcpStatusTimeout bounds the status fetch of one server
+const mcpStatusTimeout = 5 * time.Second
+
+// fetchMCPStatusCmd fetches the status of cfg's servers that are not
+// disabled. Servers are fetched concurrently, each reporting its status as
+// soon as it has it, so a slow server doesn't hold up the others.
 func fetchMCPStatusCmd(cfg config.MCPConfig) tea.Cmd {
-	return func() tea.Msg {
-		if !cfg.Enabled || len(cfg.Servers) == 0 {
+	servers := cfg.ActiveServers()
+	if !cfg.Enabled || len(servers) == 0 {
+		return func() tea.Msg {
 			return mcpStatusMsg{Statuses: nil}
 		}
+	}
 
-		statuses := make([]mcppkg.ServerStatus, 0, len(cfg.Servers))
-		for _, server := range cfg.Servers {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			status := mcppkg.FetchServerStatus(ctx, server.Name, server.URL)
-			cancel()
-			statuses = append(statuses, status)
-		}
+	cmds := make([]tea.Cmd, 0, len(servers))
+	for _, server := range servers {
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), mcpStatusTimeout)
+			defer cancel()
+			return mcpStatusMsg{Statuses: []mcppkg.ServerStatus{mcppkg.FetchServerStatus(ctx, server.Name, server.URL)}}
+		})
+	}
+	return tea.Batch(cmds...)
+}
 
-		return mcpStatusMsg{Statuses: statuses}
+// mergeMCPStatus settles fetched statuses against prev and returns the
+// status of each of cfg's active servers in config order. Servers missing
+// from fetched, such as those still being fetched, keep their previous
+// status.
+func mergeMCPStatus(cfg config.MCPConfig, prev, fetched []mcppkg.ServerStatus) []mcppkg.ServerStatus {
+	if !cfg.Enabled {
+		return nil
+	}
+	settled := mcppkg.SettleAll(prev, fetched)
+	var merged []mcppkg.ServerStatus
+	for _, server := range cfg.ActiveServers() {
+		for _, list := range [][]mcppkg.ServerStatus{settled, prev} {
+			if i := slices.IndexFunc(list, func(st mcppkg.ServerStatus) bool {
+				return st.Name == server.Name && st.URL == server.URL
+			}); i >= 0 {
+				merged = append(merged, list[i])
+				break
+			}
+		}
 	}
+	return merged
 }
 
-func scheduleMCPRefreshCmd(seconds int) tea.Cmd {
-	if seconds <= 0 {
+// scheduleMCPRefreshCmd refreshes server after its refresh interval. Ticks
+// carry the polling generation so restarting polling drops older ones.
+func scheduleMCPRefreshCmd(cfg config.MCPConfig, server config.MCPServerConfig, gen int) tea.Cmd {
+	interval := cfg.RefreshInterval(server)
+	if !cfg.Enabled || interval <= 0 {
 		return nil
 	}
 
-	return tea.Tick(time.Duration(seconds)*time.Second, func(time.Time) tea.Msg {
-		return mcpRefreshTickMsg{}
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return mcpRefreshTickMsg{server: server.Name, gen: gen}
 	})
 }
+
+// scheduleMCPRefresh schedules the next refresh of every active server
+func (m model) scheduleMCPRefresh() tea.Cmd {
+	cfg := m.config.EffectiveMCP()
+	var cmds []tea.Cmd
+	for _, server := range cfg.ActiveServers() {
+		cmds = append(cmds, scheduleMCPRefreshCmd(cfg, server, m.mcpRefreshGen))
+	}
+	return tea.Batch(cmds...)
+}
+
+// restartMCPPolling refreshes every active server now and restarts their
+// refresh schedules, e.g. after a manual refresh or a config change
+func (m *model) restartMCPPolling() tea.Cmd {
+	m.mcpRefreshGen++
+	m.mcpRefreshPending = false
+	return tea.Batch(fetchMCPStatusCmd(m.config.EffectiveMCP()), m.scheduleMCPRefresh())
+}
+
+// handleMCPRefreshTick refreshes the server of msg and schedules its next
+// refresh. Polling waits while the terminal is unfocused or skitz is
+// offline, and stops for servers since removed or disabled.
+func (m *model) handleMCPRefreshTick(msg mcpRefreshTickMsg) tea.Cmd {
+	if msg.gen != m.mcpRefreshGen {
+		return nil
+	}
+	if m.unfocused || m.offline {
+		m.mcpRefreshPending = true
+		return nil
+	}
+
+	cfg := m.config.EffectiveMCP()
+	for _, server := range cfg.ActiveServers() {
+		if server.Name == msg.server {
+			one := cfg
+			one.Servers = []config.MCPServerConfig{server}
+			return tea.Batch(fetchMCPStatusCmd(one), scheduleMCPRefreshCmd(cfg, server, m.mcpRefreshGen))
+		}
+	}
+	return nil
+}
+
+// refreshMCPNow is the dashboard's manual refresh of every MCP server
+func (m *model) refreshMCPNow() tea.Cmd {
+	cfg := m.config.EffectiveMCP()
+	switch {
+	case !cfg.Enabled || len(cfg.ActiveServers()) == 0:
+		return m.showNotification("!", "No MCP servers to refresh", "warning")
+	case m.offline:
+		return m.showNotification("✈", errMCPOffline.Error(), "warning")
+	}
+	return tea.Batch(m.restartMCPPolling(), m.showNotification("↻", "Refreshing MCP servers", "info"))
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	mcppkg "github.com/htelsiz/skitz/internal/mcp"
+)
+
+func TestMergeMCPStatus(t *testing.T) {
+	cfg := config.MCPConfig{
+		Enabled:        true,
+		RefreshSeconds: 60,
+		Servers: []config.MCPServerConfig{
+			{Name: "a", URL: "http://a"},
+			{Name: "off", URL: "http://off", Disabled: true},
+			{Name: "b", URL: "http://b", RefreshSeconds: 5},
+		},
+	}
+	prev := []mcppkg.ServerStatus{
+		{Name: "a", URL: "http://a", Connected: true, Tools: []string{"old"}},
+		{Name: "b", URL: "http://b", Connected: true},
+	}
+	fetched := []mcppkg.ServerStatus{{Name: "b", URL: "http://b", Connected: true, Tools: []string{"new"}}}
+
+	got := mergeMCPStatus(cfg, prev, fetched)
+	if len(got) != 2 || got[0].Name != "a" || got[0].Tools[0] != "old" || got[1].Name != "b" || got[1].Tools[0] != "new" {
+		t.Errorf("mergeMCPStatus() = %+v", got)
+	}
+
+	if d := cfg.RefreshInterval(cfg.Servers[0]); d != time.Minute {
+		t.Errorf("RefreshInterval(a) = %v, want the global interval", d)
+	}
+	if d := cfg.RefreshInterval(cfg.Servers[2]); d != 5*time.Second {
+		t.Errorf("RefreshInterval(b) = %v, want its own interval", d)
+	}
+}
+
+func TestMCPRefreshTick(t *testing.T) {
+	m := model{config: config.Config{MCP: config.MCPConfig{
+		Enabled:        true,
+		RefreshSeconds: 60,
+		Servers: []config.MCPServerConfig{
+			{Name: "a", URL: "http://a"},
+			{Name: "off", URL: "http://off", Disabled: true},
+		},
+	}}}
+
+	if m.handleMCPRefreshTick(mcpRefreshTickMsg{server: "a"}) == nil {
+		t.Error("tick of an active server did not refresh it")
+	}
+	if m.handleMCPRefreshTick(mcpRefreshTickMsg{server: "off"}) != nil {
+		t.Error("disabled server was refreshed")
+	}
+
+	m.restartMCPPolling()
+	if m.handleMCPRefreshTick(mcpRefreshTickMsg{server: "a"}) != nil {
+		t.Error("tick from before the restart was not dropped")
+	}
+}
+
+func TestFetchMCPStatusPerServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	closed := "http://" + ln.Addr().String() + "/mcp/"
+	ln.Close()
+
+	cfg := config.MCPConfig{Enabled: true, Servers: []config.MCPServerConfig{
+		{Name: "a", URL: closed},
+		{Name: "off", URL: closed, Disabled: true},
+		{Name: "b", URL: closed},
+	}}
+	batch, ok := fetchMCPStatusCmd(cfg)().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("fetchMCPStatusCmd() = %v, want one command per active server", batch)
+	}
+	for i, cmd := range batch {
+		msg := cmd().(mcpStatusMsg)
+		if len(msg.Statuses) != 1 || msg.Statuses[0].Name != cfg.ActiveServers()[i].Name || msg.Statuses[0].Connected {
+			t.Errorf("server %d status = %+v", i, msg.Statuses)
+		}
+	}
+}
@@ -2,14 +2,19 @@ package app
 
 import (
 	"bufio"
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aaronjanse/3mux/ecma48"
 	"github.com/aaronjanse/3mux/vterm"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/harmonica"
@@ -17,8 +22,19 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	overlay "github.com/rmhubbert/bubbletea-overlay"
 
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/audit"
 	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/features"
+	"github.com/htelsiz/skitz/internal/hooks"
+	"github.com/htelsiz/skitz/internal/jobs"
+	"github.com/htelsiz/skitz/internal/kube"
+	"github.com/htelsiz/skitz/internal/logging"
 	mcppkg "github.com/htelsiz/skitz/internal/mcp"
+	"github.com/htelsiz/skitz/internal/metrics"
+	pluginspkg "github.com/htelsiz/skitz/internal/plugins"
+	"github.com/htelsiz/skitz/internal/rag"
+	"github.com/htelsiz/skitz/internal/recording"
 )
 
 type model struct {
@@ -31,7 +47,7 @@ type model struct {
 	currentView int // viewDashboard or viewDetail
 
 	// Dashboard tabs
-	dashboardTab          int                   // 0=Resources, 1=Actions
+	dashboardTab          int                   // 0=Resources, 1=Actions, 2=Agents, 3=Deployments, 4=Plugins
 	actionItems           []DashboardAction     // Available actions
 	actionCursor          int                   // Selected action
 	addResourceWizard     *AddResourceWizard    // Add Resource wizard state
@@ -41,6 +57,7 @@ type model struct {
 	runAgentWizard        *RunAgentWizard       // Run Agent wizard state
 	pendingResourceReload bool                  // Reload resources after editor closes
 	pendingConfigReload   bool                  // Reload config after editor closes
+	collapsedGroups       map[string]bool       // Resource categories folded to their header
 
 	// View components (bubbles)
 	contentView viewport.Model
@@ -49,18 +66,48 @@ type model struct {
 	// Command execution state
 	commands  []command // Parsed commands from current section
 	cmdCursor int       // Currently selected command (0-based)
+	tagFilter string    // Only show commands with this tag (empty = all)
+
+	// Command run counts; sortByUsage lists the most used commands first
+	cmdStats    config.CommandStats
+	variables   config.Variables
+	sortByUsage bool
 
 	// Cached rendered markdown for non-command content (avoids re-rendering on cursor change)
 	cachedMarkdownContext string
 
+	// Where the command list's rows go at the content view's width, and
+	// the rows drawn so far
+	cmdLayout   commandListLayout
+	cmdRowCache map[cmdRowKey][]string
+
 	// Animation state
 	quotePos    float64          // Current character position (animated)
 	quoteVel    float64          // Velocity for spring
 	quoteTarget float64          // Target position (full quote length)
 	spring      harmonica.Spring // Spring for smooth animation
 
+	// Dashboard header art and quote, from banner: in the config
+	banner dashboardBanner
+
+	// Resources and history load in the background after the first frame;
+	// until the resources are in, the dashboard shows a skeleton.
+	// startResource is opened once they are.
+	resourcesPending bool
+	historyPending   bool
+	startResource    string
+
+	// Focus state: while the terminal is unfocused or skitz is suspended
+	// the animation tick and MCP polling stop rescheduling themselves and
+	// resume on focus
+	unfocused         bool
+	ticking           bool // a tickMsg is scheduled
+	mcpRefreshPending bool
+	mcpRefreshGen     int // generation of the scheduled MCP refresh ticks
+
 	// Config
 	config       config.Config
+	features     *features.Flags
 	history      []config.HistoryEntry
 	agentHistory []config.AgentInteraction
 	favorites    map[string]bool
@@ -74,12 +121,41 @@ type model struct {
 	agentDetailScroll  int                       // Scroll offset for detail view
 	savedAgentWizard   *SavedAgentWizard         // Wizard for running saved agent
 
-	// Notification/Toast
-	notification *Notification
+	// agentCancels stops running agents, keyed by agent ID
+	agentCancels map[string]context.CancelFunc
+
+	// Deployments tab state
+	deployments     []config.Deployment
+	deployCursor    int
+	deployStatus    map[string]deploymentStatus // keyed by deploymentKey
+	deployPolling   bool                        // a deploymentTickMsg is scheduled
+	confirmTeardown string                      // key of the deployment awaiting a second x
+
+	// Plugins discovered at startup and the Plugins tab state
+	plugins      []pluginspkg.Plugin
+	pluginPanels map[string]pluginPanelState // keyed by pluginPanel.key
+	pluginCursor int
+
+	// Content of dynamic resource sections, keyed by dynamicKey
+	dynamicSections map[string]dynamicSectionState
+
+	// AI summaries of sections, keyed by the contentHash of the section
+	// they summarize, and the hash of the section being viewed
+	summaries   map[string]sectionSummary
+	sectionHash string
+
+	// Notification/Toast: active toasts, oldest first, and the log shown
+	// from the palette
+	notifications   []Notification
+	notificationLog []Notification
+	notifySeq       int
 
 	// Command Palette (cmd+k)
 	palette Palette
 
+	// Help overlay opened with ?
+	help *helpOverlay
+
 	// MCP status
 	mcpStatus []mcppkg.ServerStatus
 
@@ -88,6 +164,87 @@ type model struct {
 
 	// AI Ask panel state
 	askPanel *AskPanel
+
+	// Quick-edit form for the selected command
+	cmdEditor *CommandEditor
+
+	// Kubeconfig shown for k8s-tagged resources, and its switcher form
+	kubeConfig   kube.Config
+	kubeSwitcher *KubeSwitcher
+
+	// Captured output of a ^table command
+	tableView *TableView
+
+	// Output opened in the pager, from o or a ^pager command
+	pager *PagerView
+
+	// Output of the last embedded or ^table command, for Ask AI context
+	lastOutput *commandOutput
+
+	// Values of the secrets resolved for commands, redacted from output
+	secretValues []string
+
+	// Index of resources and history Ask AI retrieves context from, nil
+	// unless ai.rag is enabled
+	ragIndex *rag.Index
+
+	// Provider that served the last AI request, shown in the status bar,
+	// and whether it was a failover
+	lastAIProvider   string
+	lastAIFailedOver bool
+
+	// Offline mode pauses MCP polling and disables AI actions; offlineManual
+	// is set when it was toggled from the palette rather than detected
+	offline       bool
+	offlineManual bool
+
+	// latestRelease is a newer release found by the update check
+	latestRelease string
+
+	// packUpdates are installed resource packs with a newer version in
+	// the registry
+	packUpdates []string
+
+	// detectedProviders are AI credentials found at startup, offered for
+	// import while no provider is configured
+	detectedProviders []ai.Found
+
+	// accessible renders plain screens for screen readers (see a11y.go)
+	accessible bool
+
+	// How the last command run from skitz ended, for the status bar
+	lastRun *commandResult
+
+	// handoff is put at the shell prompt when skitz exits
+	handoff string
+
+	// Digits typed ahead of a motion or Enter (see count.go), and the
+	// generation of the digit's timeout
+	count    string
+	countGen int
+
+	// Recently visited resources, most recent first, and the ' switcher
+	recent         []config.RecentVisit
+	recentSwitcher *recentSwitcher
+
+	// Values submitted for MCP tool parameters and placeholders (see
+	// arg_history.go)
+	argHistory config.ArgHistory
+
+	// MCP tool calls made from the palette, most recent first (see
+	// mcp_history.go)
+	mcpHistory []config.MCPCall
+
+	// Idle lock (see idle_lock.go): when the session started, the last
+	// keypress, and while locked, whether unlocking awaits a y and why the
+	// unlock command failed
+	sessionStart  time.Time
+	lastInput     time.Time
+	idleChecking  bool // an idleCheckMsg is scheduled
+	locked        bool
+	lockedAt      time.Time
+	unlockConfirm bool
+	unlockErr     string
 }
 
 // AskPanel holds state for the AI ask feature
@@ -98,6 +255,24 @@ type AskPanel struct {
 	Loading      bool
 	Error        string
 	GeneratedCmd string // If AI generated a runnable command
+	// PendingApproval is why the generated command needs confirming before
+	// it runs, while waiting for y or n, and pendingAction what y or n do
+	PendingApproval string
+	pendingAction   func(m *model, approved bool) tea.Cmd
+	// Subject and Attachment replace the resource name and content when
+	// asking about something else, such as a tool result
+	Subject    string
+	Attachment string
+	// Section picker shown when adding the generated command to a resource
+	SectionForm   *huh.Form
+	SectionChoice int
+	// Extra context attached with the context picker
+	Contexts      []ai.Attachment
+	ContextForm   *huh.Form
+	ContextChoice string
+	ContextPath   string
+	// Index sources retrieved for the last answer
+	Sources []string
 }
 
 // EmbeddedTerm holds the state for the embedded terminal pane
@@ -111,9 +286,18 @@ type EmbeddedTerm struct {
 	exitErr error
 	exited  bool
 	command string // The command that was executed
+	tool    string // resource the command belongs to
+	started time.Time
+	run     commandRun // how the command was started, for its follow-ups
 	// Static output mode (for MCP tools, etc.)
 	staticOutput string
 	staticTitle  string
+	// Replay of a recorded session instead of a running command
+	replay       *recording.Recording
+	replayCancel context.CancelFunc
+	// Output of a job being followed instead of a running command
+	job       *jobs.Job
+	jobCancel context.CancelFunc
 }
 
 type tickMsg time.Time
@@ -122,7 +306,11 @@ type mcpStatusMsg struct {
 	Statuses []mcppkg.ServerStatus
 }
 
-type mcpRefreshTickMsg struct{}
+// mcpRefreshTickMsg refreshes the status of one MCP server
+type mcpRefreshTickMsg struct {
+	server string
+	gen    int
+}
 
 // Terminal messages
 type termOutputMsg struct{}
@@ -138,6 +326,9 @@ type staticOutputMsg struct {
 type aiResponseMsg struct {
 	response     string
 	generatedCmd string
+	sources      []string     // indexed chunks the answer was given
+	provider     string       // provider that answered
+	failedOver   []ai.Failure // providers that failed first
 	err          error
 }
 
@@ -157,44 +348,100 @@ type agentCompletedMsg struct {
 	success  bool
 	output   string
 	duration int64
+	status   string // config.AgentStatus*
 }
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(time.Second/60, func(t time.Time) tea.Msg {
+// dashboardQuote is typed out on the dashboard by the quote animation
+const dashboardQuote = `"It is with us and in control"`
+
+// Tick intervals: animation frames, and the refresh of running agents'
+// elapsed time once nothing is animating
+const (
+	frameInterval     = time.Second / 60
+	agentTickInterval = time.Second
+)
+
+func tickCmd(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
+// animating reports whether the dashboard quote is still being typed out.
+// Accessibility mode has no animations.
+func (m model) animating() bool {
+	if m.currentView != viewDashboard || m.accessible {
+		return false
+	}
+	return math.Abs(m.quoteTarget-m.quotePos) > 0.01 || math.Abs(m.quoteVel) > 0.01
+}
+
+// nextTick schedules the next tick, or returns nil to let the tick stop
+// when nothing animates or counts up, so an idle skitz uses no CPU
+func (m *model) nextTick() tea.Cmd {
+	m.ticking = !m.unfocused && (m.animating() || len(m.activeAgents) > 0)
+	switch {
+	case !m.ticking:
+		return nil
+	case m.animating():
+		return tickCmd(frameInterval)
+	default:
+		return tickCmd(agentTickInterval)
+	}
+}
+
+// ensureTick starts the tick if it has stopped and something needs it,
+// e.g. after a view change or when an agent starts
+func (m *model) ensureTick() tea.Cmd {
+	if m.ticking {
+		return nil
+	}
+	return m.nextTick()
+}
+
 func newModel(startResource string) model {
 	cfg := config.Load(mcppkg.GetDefaultMCPServerURL())
-	history := config.LoadHistory()
-	agentHistory := config.LoadAgentHistory()
+	applyMCPRetryPolicy(cfg.MCP.Retry)
+	applyBranding(cfg.Branding)
+	configureAudit(cfg)
 
 	favorites := make(map[string]bool)
 	for _, f := range cfg.Favorites {
 		favorites[f] = true
 	}
+	banner := loadBanner(cfg.Banner)
 
 	m := model{
-		spring:       harmonica.NewSpring(harmonica.FPS(60), 6.0, 0.7),
-		config:       cfg,
-		history:      history,
-		agentHistory: agentHistory,
-		favorites:    favorites,
-		savedAgents:  config.GetAllSavedAgents(cfg),
-	}
-	m.loadResources()
-	m.actionItems = m.buildDashboardActions()
-
-	if startResource != "" {
-		for i, r := range m.resources {
-			if r.name == startResource {
-				m.resCursor = i
-				m.currentView = viewDetail
-				break
-			}
-		}
+		spring:           harmonica.NewSpring(harmonica.FPS(60), 6.0, 0.7),
+		banner:           banner,
+		quoteTarget:      float64(utf8.RuneCountInString(banner.quoteText())),
+		ticking:          true, // Init starts the tick
+		accessible:       cfg.Accessibility,
+		config:           cfg,
+		resourcesPending: true,
+		historyPending:   true,
+		startResource:    startResource,
+		favorites:        favorites,
+		features:         features.New(cfg.Features),
+		savedAgents:      config.GetAllSavedAgents(cfg),
+		deployments:      config.LoadDeployments(),
+		deployStatus:     make(map[string]deploymentStatus),
+		pluginPanels:     make(map[string]pluginPanelState),
+		dynamicSections:  make(map[string]dynamicSectionState),
+		cmdStats:         config.LoadCommandStats(),
+		variables:        config.LoadVariables(),
+		argHistory:       config.LoadArgHistory(),
+		mcpHistory:       config.LoadMCPHistory(),
+		recent:           config.LoadRecent(),
+		sessionStart:     time.Now(),
+		lastInput:        time.Now(),
+		idleChecking:     cfg.Lock.Enabled(), // Init starts the idle check
+	}
+	if !cfg.Banner.Animated() {
+		m.quotePos = m.quoteTarget
 	}
+	m.ragIndex = openRAGIndex(cfg)
+	m.actionItems = m.buildDashboardActions()
 
 	return m
 }
@@ -220,6 +467,15 @@ func (m *model) buildDashboardActions() []DashboardAction {
 				return m.startRunAgentWizard()
 			},
 		},
+		{
+			ID:          "import_history",
+			Name:        "Import Shell History",
+			Icon:        "📥",
+			Description: "Add frequent shell commands to a resource",
+			Handler: func(m *model) tea.Cmd {
+				return m.runImportHistory()
+			},
+		},
 		{
 			ID:          "providers",
 			Name:        "Configure Providers",
@@ -255,15 +511,41 @@ func (m *model) buildDashboardActions() []DashboardAction {
 
 func (m model) Init() tea.Cmd {
 	return tea.Batch(
-		tickCmd(),
-		fetchMCPStatusCmd(m.config.MCP),
-		scheduleMCPRefreshCmd(m.config.MCP.RefreshSeconds),
+		tickCmd(frameInterval),
+		loadResourcesCmd(),
+		loadHistoryCmd(),
+		fetchMCPStatusCmd(m.config.EffectiveMCP()),
+		m.scheduleMCPRefresh(),
+		loadPluginsCmd(),
+		startOfflineDetection(m.config.Offline),
+		updateCheckCmd(m.config.Updates),
+		discoverProvidersCmd(m.config),
+		packUpdateCheckCmd(m.config),
+		jobsCheckCmd(),
+		m.initIdleCheck(),
 	)
 }
 
+// Update handles msg, then restarts the tick if it stopped and the new
+// state needs it
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	switch nm := next.(type) {
+	case model:
+		return nm, tea.Batch(cmd, nm.ensureTick())
+	case *model:
+		return nm, tea.Batch(cmd, nm.ensureTick())
+	}
+	return next, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
+	if logging.DebugEnabled() {
+		slog.Debug("update", "msg", fmt.Sprintf("%T", msg))
+	}
+
 	// Forward non-key messages to palette form
 	if m.palette.State == PaletteStateCollectingParams && m.palette.InputForm != nil {
 		if _, isKey := msg.(tea.KeyMsg); !isKey {
@@ -277,6 +559,64 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Forward non-key messages to the kube context switcher
+	if m.kubeSwitcher != nil {
+		if _, isKey := msg.(tea.KeyMsg); !isKey {
+			form, cmd := m.kubeSwitcher.Form.Update(msg)
+			if f, ok := form.(*huh.Form); ok {
+				m.kubeSwitcher.Form = f
+			}
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+
+	// Forward non-key messages to the command quick-edit form
+	if m.cmdEditor != nil {
+		if _, isKey := msg.(tea.KeyMsg); !isKey {
+			form, cmd := m.cmdEditor.Form.Update(msg)
+			if f, ok := form.(*huh.Form); ok {
+				m.cmdEditor.Form = f
+			}
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+
+	// Forward non-key messages to ask panel context picker
+	if m.askPanel != nil && m.askPanel.ContextForm != nil {
+		if _, isKey := msg.(tea.KeyMsg); !isKey {
+			form, cmd := m.askPanel.ContextForm.Update(msg)
+			if f, ok := form.(*huh.Form); ok {
+				m.askPanel.ContextForm = f
+				if f.State == huh.StateCompleted {
+					return m, m.attachAskContext()
+				}
+			}
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+
+	// Forward non-key messages to ask panel section picker
+	if m.askPanel != nil && m.askPanel.SectionForm != nil {
+		if _, isKey := msg.(tea.KeyMsg); !isKey {
+			form, cmd := m.askPanel.SectionForm.Update(msg)
+			if f, ok := form.(*huh.Form); ok {
+				m.askPanel.SectionForm = f
+				if f.State == huh.StateCompleted {
+					return m, m.addCommandToResource(m.askPanel.GeneratedCmd, m.askPanel.SectionChoice)
+				}
+			}
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+
 	// Forward non-key messages to add resource wizard form
 	if m.addResourceWizard != nil && m.addResourceWizard.InputForm != nil {
 		if _, isKey := msg.(tea.KeyMsg); !isKey {
@@ -376,31 +716,50 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case clearNotificationMsg:
-		m.notification = nil
+		m.clearNotification(msg.id)
 		return m, nil
 
 	case mcpStatusMsg:
-		m.mcpStatus = msg.Statuses
+		m.mcpStatus = mergeMCPStatus(m.config.EffectiveMCP(), m.mcpStatus, msg.Statuses)
 		return m, nil
 
+	case offlineProbeMsg:
+		return m, m.handleOfflineProbe(msg)
+
+	case offlineProbeTickMsg:
+		return m, probeConnectivityCmd(m.config.Offline.Probe())
+
 	case mcpRefreshTickMsg:
-		return m, tea.Batch(
-			fetchMCPStatusCmd(m.config.MCP),
-			scheduleMCPRefreshCmd(m.config.MCP.RefreshSeconds),
-		)
+		return m, m.handleMCPRefreshTick(msg)
+
+	case preRunDoneMsg:
+		return m, m.handlePreRunDone(msg)
+
+	case hookErrorsMsg:
+		return m, m.handleHookErrors(msg)
 
 	case commandDoneMsg:
-		if msg.command != "" && m.config.History.Enabled {
+		if !msg.success {
+			metrics.Errors.Inc("command")
+		}
+		var hookCmd tea.Cmd
+		if msg.hook != nil {
+			auditCommand(*msg.hook)
+			hookCmd = m.runPostHooks(*msg.hook)
+		}
+		if msg.command != "" {
 			entry := config.HistoryEntry{
 				Command:   msg.command,
 				Tool:      msg.tool,
 				Timestamp: time.Now(),
 				Success:   msg.success,
+				Output:    m.historyOutput(msg.output),
 			}
-			m.history = config.AddToHistory(m.history, entry, m.config.History.MaxItems)
-			if m.config.History.Persist {
-				config.SaveHistory(m.history)
+			if msg.hook != nil {
+				m.lastRun = &commandResult{exitCode: msg.hook.ExitCode, elapsed: msg.hook.Duration}
+				entry.ExitCode, entry.DurationMS = msg.hook.ExitCode, msg.hook.Duration.Milliseconds()
 			}
+			m.addHistory(entry)
 		}
 		// Reload resources if we were editing
 		if m.pendingResourceReload {
@@ -411,16 +770,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.pendingConfigReload {
 			m.pendingConfigReload = false
 			m.config = config.Load(mcppkg.GetDefaultMCPServerURL())
+			m.features.SetConfig(m.config.Features)
+			applyMCPRetryPolicy(m.config.MCP.Retry)
+			configureAudit(m.config)
+			hookCmd = tea.Batch(hookCmd, m.startIdleCheck())
+			m.loadResources()
 			// Update favorites map
 			m.favorites = make(map[string]bool)
 			for _, f := range m.config.Favorites {
 				m.favorites[f] = true
 			}
 		}
-		return m, nil
+		return m, hookCmd
 
 	case termStartMsg:
-		log.Printf("termStartMsg received: command=%s", msg.command)
+		slog.Debug("embedded terminal started", "command", msg.command)
 		m.term = EmbeddedTerm{
 			active:  true,
 			focused: true,
@@ -429,28 +793,48 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			width:   msg.width,
 			height:  msg.height,
 			command: msg.command,
+			tool:    msg.tool,
+			started: time.Now(),
+			run:     msg.run,
 		}
 
+		// Output is copied to the recording, if any, as the terminal reads it
+		var output io.Reader = msg.pty
+		if msg.rec != nil {
+			output = io.TeeReader(msg.pty, msg.rec)
+		}
+		outputDone := make(chan struct{})
 		go func() {
-			// Redirect vterm debug logs to file instead of stdout
-			logPath := filepath.Join(config.DataDir, "terminal.log")
-			os.MkdirAll(config.DataDir, 0755)
-			if logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644); err == nil {
-				log.SetOutput(logFile)
-				defer logFile.Close()
-				defer log.SetOutput(os.Stderr)
-			}
-			reader := bufio.NewReader(msg.pty)
+			defer close(outputDone)
+			reader := bufio.NewReader(output)
 			msg.vt.ProcessStdout(reader)
 		}()
 
+		keep := m.config.Recording.Limit()
 		waitCmd := func() tea.Msg {
 			err := msg.cmd.Wait()
+			if msg.rec != nil {
+				finishRecording(msg.rec, err, outputDone, keep)
+			}
 			return termExitMsg{err: err}
 		}
 
 		return m, tea.Batch(m.waitForTermOutput(), waitCmd)
 
+	case replayDoneMsg:
+		m.handleReplayDone(msg)
+		return m, nil
+
+	case jobStartedMsg:
+		return m, m.handleJobStarted(msg)
+
+	case jobsFoundMsg:
+		return m, m.handleJobsFound(msg)
+
+	case jobDoneMsg:
+		m.handleJobDone(msg)
+		return m, nil
+
 	case termOutputMsg:
 		if m.term.active && !m.term.exited {
 			return m, m.waitForTermOutput()
@@ -458,21 +842,118 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case termExitMsg:
+		var output string
+		if m.term.vt != nil && m.term.command != "" {
+			output = m.redactSecrets(terminalText(m.term.vt))
+			m.lastOutput = &commandOutput{command: m.term.command, text: output}
+		}
 		m.term.exited = true
 		m.term.exitErr = msg.err
 		m.term.focused = false
-		return m, nil
+		title := "Command finished"
+		if msg.err != nil {
+			title = "Command failed"
+		}
+		var hookCmd tea.Cmd
+		if m.term.command != "" {
+			elapsed := time.Since(m.term.started)
+			m.lastRun = &commandResult{exitCode: hooks.ExitCode(msg.err), elapsed: elapsed}
+			m.addHistory(config.HistoryEntry{
+				Command:    m.term.command,
+				Tool:       m.term.tool,
+				Timestamp:  m.term.started,
+				Success:    msg.err == nil,
+				ExitCode:   m.lastRun.exitCode,
+				DurationMS: elapsed.Milliseconds(),
+				Output:     m.historyOutput(output),
+			})
+			ev := hooks.Event{
+				Command:  m.term.command,
+				Resource: m.term.tool,
+				Mode:     string(CommandEmbedded),
+				ExitCode: hooks.ExitCode(msg.err),
+				Duration: time.Since(m.term.started),
+			}
+			auditCommand(ev)
+			hookCmd = m.runPostHooks(ev)
+		}
+		return m, tea.Batch(
+			m.notifyDesktop(title, m.term.command, time.Since(m.term.started), !m.unfocused),
+			hookCmd,
+		)
+
+	case resourcesLoadedMsg:
+		return m, m.handleResourcesLoaded(msg)
+
+	case historyLoadedMsg:
+		return m, m.handleHistoryLoaded(msg)
 
 	case agentInteractionMsg:
-		m.agentHistory = config.AddAgentInteraction(m.agentHistory, msg.interaction, 20)
-		config.SaveAgentHistory(m.agentHistory)
+		m.addAgentInteraction(msg.interaction)
+		return m, nil
+
+	case deploySettingsMsg:
+		m.config.Deploy.Azure = msg.azure
+		config.Save(m.config)
+		return m, nil
+
+	case deploymentRecordedMsg:
+		m.deployments = config.UpsertDeployment(m.deployments, msg.deployment)
+		config.SaveDeployments(m.deployments)
+		return m, fetchDeploymentStatusCmd(msg.deployment)
+
+	case deploymentStatusMsg:
+		st := m.deployStatus[msg.key]
+		st.status, st.err, st.checked = msg.status, msg.err, time.Now()
+		m.deployStatus[msg.key] = st
+		return m, nil
+
+	case deploymentTickMsg:
+		return m, m.handleDeploymentTick()
+
+	case deploymentActionMsg:
+		return m, m.handleDeploymentAction(msg)
+
+	case communityExamplesMsg:
+		return m, m.handleCommunityExamples(msg)
+
+	case upstreamDocsMsg:
+		return m, m.handleUpstreamDocs(msg)
+
+	case execTargetMsg:
+		return m, m.setExecTarget(msg)
+
+	case kubeNamespacesMsg:
+		return m, m.openKubeSwitcher(msg)
+
+	case kubeSwitchedMsg:
+		return m, m.handleKubeSwitched(msg)
+
+	case tableOutputMsg:
+		return m, m.handleTableOutput(msg)
+
+	case pagerOutputMsg:
+		return m, m.handlePagerOutput(msg)
+
+	case askContextMsg:
+		return m, m.handleAskContext(msg)
+
+	case ragIndexedMsg:
+		m.handleRAGIndexed(msg)
 		return m, nil
 
 	case agentStartedMsg:
+		for _, agent := range m.activeAgents {
+			if agent.ID == msg.agent.ID {
+				return m, nil
+			}
+		}
 		m.activeAgents = append(m.activeAgents, msg.agent)
 		return m, nil
 
 	case agentCompletedMsg:
+		delete(m.agentCancels, msg.agentID)
+		var notifyCmd tea.Cmd
 		// Find and remove the agent from active list
 		for i, agent := range m.activeAgents {
 			if agent.ID == msg.agentID {
@@ -488,21 +969,82 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					Runtime:   agent.Runtime,
 					Provider:  agent.Provider,
 					Duration:  msg.duration,
+					Status:    msg.status,
 				}
-				m.agentHistory = config.AddAgentInteraction(m.agentHistory, interaction, 50)
-				config.SaveAgentHistory(m.agentHistory)
+				m.addAgentInteraction(interaction)
+
+				title := "Agent finished"
+				if !msg.success {
+					title = "Agent failed"
+				}
+				watching := !m.unfocused && m.currentView == viewDashboard && m.dashboardTab == agentsTab
+				notifyCmd = tea.Batch(
+					m.notifyDesktop(title, agent.Name+": "+agent.Task, time.Since(agent.StartTime), watching),
+					m.postWebhooks(agentSummary(agent, msg)),
+				)
 
 				// Remove from active agents
 				m.activeAgents = append(m.activeAgents[:i], m.activeAgents[i+1:]...)
 				break
 			}
 		}
-		return m, nil
+		return m, notifyCmd
 
 	case aiAgentResultMsg:
-		m.palette.State = PaletteStateShowingResult
-		m.palette.ResultTitle = msg.title
-		m.palette.ResultText = msg.output
+		m.showPaletteResult(msg.title, msg.output, msg.err != nil)
+		return m, nil
+
+	case deployFinishedMsg:
+		return m, m.postWebhooks(msg.summary)
+
+	case webhookErrorMsg:
+		return m, m.handleWebhookError(msg)
+
+	case agentSharedMsg:
+		return m, m.handleAgentShared(msg)
+
+	case pluginsLoadedMsg:
+		return m, m.handlePluginsLoaded(msg)
+
+	case secretsResolvedMsg:
+		return m, m.handleSecretsResolved(msg)
+
+	case pluginPanelMsg:
+		m.handlePluginPanel(msg)
+		return m, nil
+
+	case updateAvailableMsg:
+		return m, m.handleUpdateAvailable(msg)
+
+	case cheatSheetMsg:
+		return m, m.handleCheatSheet(msg)
+
+	case providersFoundMsg:
+		return m, m.handleProvidersFound(msg)
+
+	case packUpdatesMsg:
+		return m, m.handlePackUpdates(msg)
+
+	case packIndexMsg:
+		if m.palette.State == PaletteStateExecuting {
+			m.handlePackIndex(msg)
+		}
+		return m, nil
+
+	case packInstalledMsg:
+		if m.palette.State == PaletteStateExecuting {
+			return m, m.handlePackInstalled(msg)
+		}
+		return m, nil
+
+	case countTimeoutMsg:
+		return m, m.handleCountTimeout(msg)
+
+	case paletteResultMsg:
+		m.recordMCPCalls(msg.calls)
+		if m.palette.State == PaletteStateExecuting {
+			m.showPaletteResult(msg.title, msg.output, msg.failed)
+		}
 		return m, nil
 
 	case aiPrefilledParamsMsg:
@@ -528,6 +1070,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case aiResponseMsg:
+		var failoverCmd tea.Cmd
+		if msg.provider != "" {
+			m.lastAIProvider, m.lastAIFailedOver = msg.provider, len(msg.failedOver) > 0
+		}
+		if len(msg.failedOver) > 0 {
+			var names []string
+			for _, f := range msg.failedOver {
+				slog.Warn("ai provider failed over", "provider", f.Provider, "error", f.Err, "served_by", msg.provider)
+				names = append(names, f.Provider)
+			}
+			failoverCmd = m.showNotification("↪", fmt.Sprintf("%s failed; answered by %s", strings.Join(names, ", "), msg.provider), "warning")
+		}
 		if m.askPanel != nil {
 			m.askPanel.Loading = false
 			if msg.err != nil {
@@ -535,9 +1089,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				m.askPanel.Response = msg.response
 				m.askPanel.GeneratedCmd = msg.generatedCmd
+				m.askPanel.Sources = msg.sources
 			}
 		}
-		return m, nil
+		return m, failoverCmd
 
 	case providerTestMsg:
 		if m.providersWizard != nil {
@@ -567,21 +1122,74 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		if m.currentView == viewDetail {
-			m.viewReady = false
+		if m.currentView == viewDetail && m.viewReady {
+			m.resizeViewComponents()
+		} else if m.currentView == viewDetail {
 			m.initViewComponents()
 		}
+		if m.palette.State == PaletteStateShowingResult {
+			m.layoutPaletteResult()
+		}
+		m.layoutTableView()
+		m.layoutPager()
+		m.resizeTerminal()
+
+	case tea.BlurMsg:
+		m.unfocused = true
+		slog.Debug("terminal unfocused, pausing polling")
+		return m, tea.Batch(cmds...)
+
+	case tea.FocusMsg, tea.ResumeMsg:
+		m.unfocused = false
+		slog.Debug("terminal focused, resuming polling")
+		if m.mcpRefreshPending && !m.offline {
+			cmds = append(cmds, m.restartMCPPolling())
+		}
+		if m.currentView == viewDashboard && m.dashboardTab == deploymentsTab {
+			cmds = append(cmds, m.startDeploymentPolling())
+		}
+		return m, tea.Batch(cmds...)
+
+	case spinner.TickMsg:
+		if m.palette.State != PaletteStateExecuting {
+			// Let the spinner stop once the call finishes
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.palette.Spinner, cmd = m.palette.Spinner.Update(msg)
+		return m, cmd
 
 	case tickMsg:
-		if m.currentView == viewDashboard {
-			quote := `"It is with us and in control"`
-			m.quoteTarget = float64(len(quote))
+		if m.animating() {
 			m.quotePos, m.quoteVel = m.spring.Update(m.quotePos, m.quoteVel, m.quoteTarget)
+			if !m.animating() {
+				m.quotePos, m.quoteVel = m.quoteTarget, 0
+			}
 		}
-		return m, tickCmd()
+		return m, m.nextTick()
+
+	case dynamicSectionMsg:
+		m.handleDynamicSection(msg)
+		return m, nil
+
+	case summaryMsg:
+		m.handleSummary(msg)
+		return m, nil
+
+	case idleCheckMsg:
+		return m, m.handleIdleCheck()
+
+	case unlockMsg:
+		return m, m.handleUnlock(msg)
 
 	case tea.KeyMsg:
-		return m.handleKeyMsg(msg)
+		if m.locked {
+			return m, m.handleLockedKey(msg)
+		}
+		idleCmd := m.noteInput()
+		next, cmd := m.handleKeyMsg(msg)
+		// Keys that open a dynamic section start loading it
+		return next, tea.Batch(cmd, m.fetchDynamicSection(false), idleCmd)
 	}
 
 	return m, tea.Batch(cmds...)
@@ -646,6 +1254,12 @@ func (m *model) sendKeyToTerminal(msg tea.KeyMsg) tea.Cmd {
 }
 
 func (m *model) closeTerminal() {
+	if m.term.replayCancel != nil {
+		m.term.replayCancel()
+	}
+	if m.term.jobCancel != nil {
+		m.term.jobCancel()
+	}
 	if m.term.pty != nil {
 		m.term.pty.Close()
 	}
@@ -670,6 +1284,19 @@ func (m model) View() string {
 	if m.width == 0 {
 		return ""
 	}
+	defer metrics.RenderSeconds.Since(time.Now())
+
+	if m.locked {
+		return m.renderLockScreen()
+	}
+
+	if m.accessible {
+		return m.renderAccessible()
+	}
+
+	if m.help != nil {
+		return m.renderHelpOverlay()
+	}
 
 	// If embedded terminal is active, show it regardless of view
 	if m.term.active {
@@ -690,13 +1317,23 @@ func (m model) View() string {
 	status := m.renderStatusBar()
 	background := lipgloss.JoinVertical(lipgloss.Left, content, status)
 
+	// The detail view lays the Ask AI panel out itself
+	if m.askPanel != nil && m.askPanel.Active && m.currentView != viewDetail {
+		width, _ := m.paletteSize()
+		background = overlay.Composite(m.renderAskPanel(width), background, overlay.Center, overlay.Center, 0, 0)
+	}
+
+	if m.recentSwitcher != nil {
+		background = overlay.Composite(m.renderRecentSwitcher(), background, overlay.Center, overlay.Center, 0, 0)
+	}
+
 	if m.palette.State != PaletteStateIdle {
 		palette := m.renderPalette()
 		background = overlay.Composite(palette, background, overlay.Center, overlay.Center, 0, 0)
 	}
 
-	if m.notification != nil {
-		toast := m.renderNotification()
+	if len(m.notifications) > 0 {
+		toast := m.renderNotifications()
 		toastW := lipgloss.Width(toast)
 		offsetX := m.width - toastW - 4
 		if offsetX < 0 {
@@ -708,10 +1345,45 @@ func (m model) View() string {
 	return background
 }
 
+// telemetryInterval is how often metrics are pushed to the endpoint the
+// organization policy sets
+const telemetryInterval = 5 * time.Minute
+
+// RunOptions are the command line flags that affect the TUI
+type RunOptions struct {
+	Accessible bool // --a11y, in addition to the accessibility setting
+}
+
 // Run is the public entry point for the TUI application.
-func Run(startResource string) error {
-	_, err := tea.NewProgram(newModel(startResource), tea.WithAltScreen()).Run()
-	return err
+func Run(startResource string, opts RunOptions) error {
+	m := newModel(startResource)
+	defer audit.Close()
+	m.accessible = m.accessible || opts.Accessible
+	if addr := m.config.Metrics.Listen; addr != "" {
+		stop, err := metrics.Serve(addr)
+		if err != nil {
+			slog.Warn("metrics endpoint disabled", "error", err)
+		} else {
+			defer stop()
+		}
+	}
+	if endpoint := m.config.Policy.Telemetry.Endpoint; endpoint != "" {
+		defer metrics.StartPush(endpoint, telemetryInterval)()
+	}
+	final, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithReportFocus()).Run()
+	if err != nil {
+		return err
+	}
+	switch fm := final.(type) {
+	case model:
+		m = fm
+	case *model:
+		m = *fm
+	}
+	if m.handoff != "" {
+		return handOffToShell(m.handoff)
+	}
+	return nil
 }
 
 // providerTestMsg is sent when provider test completes
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/harmonica"
+)
+
+// tick delivers a tickMsg and reports whether another tick was scheduled
+func tick(t *testing.T, m model) (model, bool) {
+	t.Helper()
+	next, cmd := m.Update(tickMsg(time.Now()))
+	return next.(model), cmd != nil
+}
+
+func TestTickStopsWhenIdle(t *testing.T) {
+	m := model{
+		spring:      harmonica.NewSpring(harmonica.FPS(60), 6.0, 0.7),
+		quoteTarget: float64(len(dashboardQuote)),
+		ticking:     true,
+	}
+
+	// The quote types out, then the tick stops
+	frames := 0
+	for scheduled := true; scheduled; frames++ {
+		if frames > 600 {
+			t.Fatal("tick still running after 10 seconds of frames")
+		}
+		m, scheduled = tick(t, m)
+	}
+	if m.ticking {
+		t.Error("ticking still set after the tick stopped")
+	}
+	if int(m.quotePos) != len(dashboardQuote) {
+		t.Errorf("quote stopped at %v of %d characters", m.quotePos, len(dashboardQuote))
+	}
+
+	// Any message restarts it once an agent is running, at the slow rate
+	m.activeAgents = []ActiveAgent{{ID: "a1", StartTime: time.Now()}}
+	next, cmd := m.Update(tea.FocusMsg{})
+	m = next.(model)
+	if cmd == nil || !m.ticking {
+		t.Fatal("tick not restarted for a running agent")
+	}
+	if m.animating() {
+		t.Error("settled quote still animating")
+	}
+
+	// It stops while the terminal is unfocused
+	next, _ = m.Update(tea.BlurMsg{})
+	m = next.(model)
+	if m, scheduled := tick(t, m); scheduled || m.ticking {
+		t.Error("tick kept running while unfocused")
+	}
+}
@@ -1,10 +1,14 @@
 package app
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/metrics"
 )
 
 // Notification represents a toast message
@@ -12,32 +16,84 @@ type Notification struct {
 	Message string
 	Icon    string
 	Style   string // "success", "info", "warning", "error"
+
+	id   int
+	time time.Time
+}
+
+// maxToasts is how many notifications are shown at once; older ones are
+// dropped from the screen but kept in the log.
+const maxToasts = 3
+
+// notificationLogSize is how many notifications the palette log keeps
+const notificationLogSize = 50
+
+// notificationDuration returns how long a toast of style stays on screen
+func notificationDuration(style string) time.Duration {
+	switch style {
+	case "error":
+		return 6 * time.Second
+	case "warning":
+		return 4 * time.Second
+	}
+	return 2 * time.Second
 }
 
-// clearNotificationMsg clears the current notification
-type clearNotificationMsg struct{}
+// clearNotificationMsg clears the notification with the given id
+type clearNotificationMsg struct {
+	id int
+}
 
-// showNotification sets a notification and returns a command to clear it after delay
+// showNotification adds a toast and returns a command to clear it after a
+// delay that depends on its style
 func (m *model) showNotification(icon, message, style string) tea.Cmd {
-	m.notification = &Notification{
+	if style == "error" {
+		metrics.Errors.Inc("notification")
+	}
+	m.notifySeq++
+	n := Notification{
 		Message: message,
 		Icon:    icon,
 		Style:   style,
+		id:      m.notifySeq,
+		time:    time.Now(),
+	}
+
+	m.notifications = append(m.notifications, n)
+	if len(m.notifications) > maxToasts {
+		m.notifications = m.notifications[len(m.notifications)-maxToasts:]
+	}
+	m.notificationLog = append(m.notificationLog, n)
+	if len(m.notificationLog) > notificationLogSize {
+		m.notificationLog = m.notificationLog[len(m.notificationLog)-notificationLogSize:]
 	}
-	return tea.Tick(2*time.Second, func(t time.Time) tea.Msg {
-		return clearNotificationMsg{}
+
+	return tea.Tick(notificationDuration(style), func(t time.Time) tea.Msg {
+		return clearNotificationMsg{id: n.id}
+	})
+}
+
+// clearNotification removes a toast from the screen
+func (m *model) clearNotification(id int) {
+	m.notifications = slices.DeleteFunc(m.notifications, func(n Notification) bool {
+		return n.id == id
 	})
 }
 
-// renderNotification renders a toast notification
-func (m model) renderNotification() string {
-	if m.notification == nil {
-		return ""
+// renderNotifications renders the active toasts, newest first
+func (m model) renderNotifications() string {
+	toasts := make([]string, 0, len(m.notifications))
+	for i := len(m.notifications) - 1; i >= 0; i-- {
+		toasts = append(toasts, renderToast(m.notifications[i]))
 	}
+	return lipgloss.JoinVertical(lipgloss.Right, toasts...)
+}
 
+// renderToast renders a single toast notification
+func renderToast(n Notification) string {
 	// Choose colors based on style
 	var bgColor, fgColor lipgloss.Color
-	switch m.notification.Style {
+	switch n.Style {
 	case "success":
 		bgColor = lipgloss.Color("42")  // Green
 		fgColor = lipgloss.Color("255") // White
@@ -58,5 +114,38 @@ func (m model) renderNotification() string {
 		Padding(0, 2).
 		Bold(true)
 
-	return toastStyle.Render(m.notification.Icon + "  " + m.notification.Message)
+	return toastStyle.Render(n.Icon + "  " + n.Message)
+}
+
+// showNotificationLog replaces the palette list with past notifications,
+// newest first
+func (m *model) showNotificationLog() {
+	var items []PaletteItem
+	for i := len(m.notificationLog) - 1; i >= 0; i-- {
+		n := m.notificationLog[i]
+		style := n.Style
+		if style == "" {
+			style = "info"
+		}
+		items = append(items, PaletteItem{
+			ID:       fmt.Sprintf("notification:%d", n.id),
+			Icon:     n.Icon,
+			Title:    n.Message,
+			Subtitle: fmt.Sprintf("%s · %s", style, formatTimeAgo(n.time)),
+			Category: "notification",
+		})
+	}
+	if len(items) == 0 {
+		items = append(items, PaletteItem{
+			ID:       "notification:none",
+			Icon:     "🔔",
+			Title:    "No notifications yet",
+			Category: "notification",
+		})
+	}
+
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
 }
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestShowNotificationStacksAndLogs(t *testing.T) {
+	var m model
+	for i := range notificationLogSize + 5 {
+		m.showNotification("i", fmt.Sprintf("message %d", i), "info")
+	}
+
+	if len(m.notifications) != maxToasts {
+		t.Fatalf("len(notifications) = %d, want %d", len(m.notifications), maxToasts)
+	}
+	if got := m.notifications[maxToasts-1].Message; got != fmt.Sprintf("message %d", notificationLogSize+4) {
+		t.Errorf("newest toast = %q", got)
+	}
+	if len(m.notificationLog) != notificationLogSize {
+		t.Fatalf("len(notificationLog) = %d, want %d", len(m.notificationLog), notificationLogSize)
+	}
+	if got := m.notificationLog[0].Message; got != "message 5" {
+		t.Errorf("oldest logged = %q, want message 5", got)
+	}
+
+	// Clearing one toast leaves the others on screen and in the log
+	m.clearNotification(m.notifications[1].id)
+	if len(m.notifications) != maxToasts-1 {
+		t.Errorf("len(notifications) after clear = %d, want %d", len(m.notifications), maxToasts-1)
+	}
+	if len(m.notificationLog) != notificationLogSize {
+		t.Errorf("clear removed a logged notification")
+	}
+}
+
+func TestNotificationDuration(t *testing.T) {
+	if !(notificationDuration("error") > notificationDuration("warning") &&
+		notificationDuration("warning") > notificationDuration("info") &&
+		notificationDuration("info") == notificationDuration("success")) {
+		t.Error("errors should stay longest, then warnings, then info and success")
+	}
+}
+
+func TestNotifyDesktop(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.NotificationsConfig
+		took     time.Duration
+		watching bool
+		want     bool
+	}{
+		{"disabled", config.NotificationsConfig{}, time.Minute, false, false},
+		{"long run while away", config.NotificationsConfig{Desktop: true}, time.Minute, false, true},
+		{"short run", config.NotificationsConfig{Desktop: true}, 10 * time.Second, false, false},
+		{"watching", config.NotificationsConfig{Desktop: true}, time.Minute, true, false},
+		{"custom threshold", config.NotificationsConfig{Desktop: true, MinDuration: 5 * time.Second}, 10 * time.Second, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := model{config: config.Config{Notifications: tt.cfg}}
+			if got := m.notifyDesktop("done", "ls", tt.took, tt.watching) != nil; got != tt.want {
+				t.Errorf("notifyDesktop() returned cmd = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Errors shown for actions that need the network in offline mode
+var (
+	errAIOffline       = errors.New("AI is unavailable offline")
+	errMCPOffline      = errors.New("MCP tools are unavailable offline")
+	errUpdateOffline   = errors.New("update checks are unavailable offline")
+	errRegistryOffline = errors.New("the resource pack registry is unavailable offline")
+)
+
+// offlineProbeMsg reports whether the probe address could be reached
+type offlineProbeMsg struct {
+	online bool
+}
+
+// offlineProbeTickMsg schedules the next connectivity check
+type offlineProbeTickMsg struct{}
+
+// probeConnectivityCmd dials addr to check whether the network is up
+func probeConnectivityCmd(addr string) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := net.DialTimeout("tcp", addr, 3*time.Second)
+		if err != nil {
+			slog.Debug("connectivity probe failed", "address", addr, "error", err)
+			return offlineProbeMsg{online: false}
+		}
+		conn.Close()
+		return offlineProbeMsg{online: true}
+	}
+}
+
+// startOfflineDetection checks connectivity now and then every
+// offline.probe_interval, unless auto-detection is off
+func startOfflineDetection(cfg config.OfflineConfig) tea.Cmd {
+	if !cfg.Detect() {
+		return nil
+	}
+	return probeConnectivityCmd(cfg.Probe())
+}
+
+// handleOfflineProbe enters or leaves offline mode with the probe result.
+// While offline mode was toggled from the palette the result is ignored.
+func (m *model) handleOfflineProbe(msg offlineProbeMsg) tea.Cmd {
+	next := tea.Tick(m.config.Offline.Interval(), func(time.Time) tea.Msg {
+		return offlineProbeTickMsg{}
+	})
+	if m.offlineManual || msg.online != m.offline {
+		return next
+	}
+	return tea.Batch(next, m.setOffline(!msg.online))
+}
+
+// toggleOffline switches offline mode from the palette. Connectivity
+// checks leave it alone until it is toggled off again.
+func (m *model) toggleOffline() tea.Cmd {
+	m.offlineManual = !m.offline
+	return m.setOffline(!m.offline)
+}
+
+// setOffline enters or leaves offline mode. Leaving it resumes the MCP
+// polling and refreshes that were skipped.
+func (m *model) setOffline(offline bool) tea.Cmd {
+	m.offline = offline
+	slog.Info("offline mode changed", "offline", offline, "manual", m.offlineManual)
+	if offline {
+		return m.showNotification("✈", "Offline: MCP polling paused, AI unavailable", "warning")
+	}
+
+	cmds := []tea.Cmd{m.showNotification("✓", "Back online", "success")}
+	if m.mcpRefreshPending && !m.unfocused {
+		cmds = append(cmds, m.restartMCPPolling())
+	}
+	cmds = append(cmds, m.updateRAGIndexCmd(), m.fetchDynamicSection(false))
+	return tea.Batch(cmds...)
+}
+
+// aiUnavailable returns a notification when AI actions can't run because
+// policy disables them or skitz is offline, and nil otherwise
+func (m *model) aiUnavailable() tea.Cmd {
+	if m.config.Policy.AIDisabled {
+		return m.showNotification("🔒", config.ErrAIDisabled.Error(), "warning")
+	}
+	if m.offline {
+		return m.showNotification("✈", errAIOffline.Error(), "warning")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"net"
+	"testing"
+)
+
+func TestOfflineMode(t *testing.T) {
+	m := model{}
+	if m.aiUnavailable() != nil {
+		t.Fatal("AI unavailable while online")
+	}
+
+	m.handleOfflineProbe(offlineProbeMsg{online: false})
+	if !m.offline || m.aiUnavailable() == nil {
+		t.Fatal("failed probe did not enter offline mode")
+	}
+
+	// MCP refreshes wait until the network is back
+	next, _ := m.Update(mcpRefreshTickMsg{})
+	m = next.(model)
+	if !m.mcpRefreshPending {
+		t.Error("MCP refresh ran while offline")
+	}
+	m.handleOfflineProbe(offlineProbeMsg{online: true})
+	if m.offline || m.mcpRefreshPending {
+		t.Errorf("offline = %v, refresh pending = %v after reconnecting", m.offline, m.mcpRefreshPending)
+	}
+
+	// A manual toggle wins over connectivity checks until toggled back
+	m.toggleOffline()
+	m.handleOfflineProbe(offlineProbeMsg{online: true})
+	if !m.offline {
+		t.Error("probe overrode offline mode set from the palette")
+	}
+	m.toggleOffline()
+	if m.offline || m.offlineManual {
+		t.Errorf("offline = %v, manual = %v after toggling back", m.offline, m.offlineManual)
+	}
+}
+
+func TestProbeConnectivity(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	addr := ln.Addr().String()
+	if msg := probeConnectivityCmd(addr)().(offlineProbeMsg); !msg.online {
+		t.Error("probe of a listening address failed")
+	}
+	ln.Close()
+	if msg := probeConnectivityCmd(addr)().(offlineProbeMsg); msg.online {
+		t.Error("probe of a closed address succeeded")
+	}
+}
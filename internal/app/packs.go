@@ -0,0 +1,200 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/packs"
+)
+
+// Resource packs come from the registry in registry.url. Browse Resource
+// Packs lists them; selecting one installs it, or updates it, into
+// resources/packs/<name>, where it loads after the user's own resources.
+// At startup the installed packs are checked against the registry.
+
+// packIndexMsg carries the registry's index to the Browse Resource Packs list
+type packIndexMsg struct {
+	index packs.Index
+	err   error
+}
+
+// packInstalledMsg reports the result of installing a pack
+type packInstalledMsg struct {
+	pack packs.Pack
+	err  error
+}
+
+// packUpdatesMsg reports the installed packs with a newer version
+type packUpdatesMsg struct {
+	names []string
+}
+
+// packUpdateCheckCmd checks the installed packs against the registry at
+// startup when update checks are enabled
+func packUpdateCheckCmd(cfg config.Config) tea.Cmd {
+	if !cfg.Updates.Enabled() {
+		return nil
+	}
+	indexURL := cfg.Registry.IndexURL()
+	return func() tea.Msg {
+		installed := packs.LoadInstalled(packs.InstalledPath(config.DataDir))
+		if len(installed) == 0 {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		index, err := packs.FetchIndex(ctx, indexURL)
+		if err != nil {
+			slog.Debug("pack update check failed", "error", err)
+			return nil
+		}
+		var names []string
+		for _, p := range packs.Updates(index, installed) {
+			names = append(names, p.Name)
+		}
+		if len(names) == 0 {
+			return nil
+		}
+		return packUpdatesMsg{names: names}
+	}
+}
+
+// handlePackUpdates remembers the packs to update for the palette and
+// announces them
+func (m *model) handlePackUpdates(msg packUpdatesMsg) tea.Cmd {
+	m.packUpdates = msg.names
+	text := fmt.Sprintf("%d resource pack updates", len(msg.names))
+	if len(msg.names) == 1 {
+		text = "Update for the " + msg.names[0] + " resource pack"
+	}
+	return m.showNotification("📦", text+" · ctrl+k → Browse Resource Packs", "info")
+}
+
+// getPacksPaletteItem returns the action that lists the registry's packs
+func (m *model) getPacksPaletteItem() PaletteItem {
+	subtitle := "Install community resources from the registry"
+	if len(m.packUpdates) > 0 {
+		subtitle = "Updates available: " + strings.Join(m.packUpdates, ", ")
+	}
+	return PaletteItem{
+		ID:       "action:packs",
+		Icon:     "📦",
+		Title:    "Browse Resource Packs",
+		Subtitle: subtitle,
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			return m.browsePacks()
+		},
+	}
+}
+
+// browsePacks fetches the registry's index
+func (m *model) browsePacks() tea.Cmd {
+	if m.offline {
+		return m.showNotification("✈", errRegistryOffline.Error(), "warning")
+	}
+	indexURL := m.config.Registry.IndexURL()
+	parent, spin := m.startPaletteExecution("Fetching resource packs...")
+	return tea.Batch(spin, func() tea.Msg {
+		index, err := packs.FetchIndex(parent, indexURL)
+		if errors.Is(parent.Err(), context.Canceled) {
+			return nil
+		}
+		return packIndexMsg{index: index, err: err}
+	})
+}
+
+// handlePackIndex lists the packs of the fetched index
+func (m *model) handlePackIndex(msg packIndexMsg) {
+	if msg.err != nil {
+		m.showPaletteResult("Resource Packs", "Error: "+msg.err.Error(), true)
+		return
+	}
+	if len(msg.index.Packs) == 0 {
+		m.showPaletteResult("Resource Packs", "The registry has no packs yet.", false)
+		return
+	}
+	m.cancelPaletteExecution()
+	m.palette.State = PaletteStateSearching
+	m.palette.LoadingText = ""
+	m.showPacks(msg.index)
+}
+
+// showPacks replaces the palette list with an item per pack, marking the
+// installed ones and those with an update. Selecting a pack installs it.
+func (m *model) showPacks(index packs.Index) {
+	installed := packs.LoadInstalled(packs.InstalledPath(config.DataDir))
+	var items []PaletteItem
+	for _, p := range index.Packs {
+		icon, status := "📦", "v"+p.Version
+		if inst, ok := installed[p.Name]; ok {
+			if inst.Version == p.Version {
+				icon, status = "✓", "installed v"+p.Version
+			} else {
+				icon, status = "⬆", fmt.Sprintf("update v%s → v%s", inst.Version, p.Version)
+			}
+		}
+		items = append(items, PaletteItem{
+			ID:       "pack:" + p.Name,
+			Icon:     icon,
+			Title:    p.Name,
+			Subtitle: truncate(status+" · "+p.Description, 60),
+			Category: "packs",
+			Handler: func(m *model) tea.Cmd {
+				return m.installPack(p)
+			},
+		})
+	}
+
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
+}
+
+// installPack downloads p into the packs directory
+func (m *model) installPack(p packs.Pack) tea.Cmd {
+	if m.offline {
+		return m.showNotification("✈", errRegistryOffline.Error(), "warning")
+	}
+	indexURL := m.config.Registry.IndexURL()
+	parent, spin := m.startPaletteExecution("Installing " + p.Name + "...")
+	return tea.Batch(spin, func() tea.Msg {
+		err := packs.Install(parent, p, indexURL, packs.Dir(config.ResourcesDir))
+		if errors.Is(parent.Err(), context.Canceled) {
+			return nil
+		}
+		return packInstalledMsg{pack: p, err: err}
+	})
+}
+
+// handlePackInstalled records an installed pack's version and reloads the
+// resources
+func (m *model) handlePackInstalled(msg packInstalledMsg) tea.Cmd {
+	title := "Install " + msg.pack.Name
+	if msg.err != nil {
+		slog.Error("failed to install resource pack", "pack", msg.pack.Name, "error", msg.err)
+		m.showPaletteResult(title, "Error: "+msg.err.Error(), true)
+		return nil
+	}
+
+	path := packs.InstalledPath(config.DataDir)
+	installed := packs.LoadInstalled(path)
+	installed[msg.pack.Name] = packs.InstalledPack{Version: msg.pack.Version, InstalledAt: time.Now()}
+	if err := installed.Save(path); err != nil {
+		slog.Warn("failed to save installed packs", "error", err)
+	}
+	m.packUpdates = slices.DeleteFunc(m.packUpdates, func(name string) bool { return name == msg.pack.Name })
+	m.loadResources()
+
+	m.closePalette()
+	return m.showNotification("📦", fmt.Sprintf("Installed %s v%s", msg.pack.Name, msg.pack.Version), "success")
+}
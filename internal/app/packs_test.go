@@ -0,0 +1,56 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/packs"
+)
+
+func TestPackInstalled(t *testing.T) {
+	oldDataDir, oldResourcesDir := config.DataDir, config.ResourcesDir
+	config.DataDir, config.ResourcesDir = t.TempDir(), t.TempDir()
+	defer func() { config.DataDir, config.ResourcesDir = oldDataDir, oldResourcesDir }()
+
+	pack := packs.Pack{Name: "terraform", Description: "Terraform CLI", Version: "1.1.0"}
+	dir := filepath.Join(packs.Dir(config.ResourcesDir), pack.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "terraform.md"), []byte("# Terraform\n\n## Commands\n\n`terraform plan` plan\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := model{config: config.CreateDefault("http://localhost:8001/mcp/"), packUpdates: []string{"terraform"}}
+	m.palette.State = PaletteStateExecuting
+	m.handlePackInstalled(packInstalledMsg{pack: pack})
+
+	if got := packs.LoadInstalled(packs.InstalledPath(config.DataDir))["terraform"].Version; got != "1.1.0" {
+		t.Errorf("installed version = %q, want 1.1.0", got)
+	}
+	if len(m.packUpdates) != 0 {
+		t.Errorf("packUpdates = %v, want the installed pack removed", m.packUpdates)
+	}
+	found := false
+	for _, res := range m.resources {
+		found = found || res.name == "terraform"
+	}
+	if !found {
+		t.Error("installed pack's resource was not loaded")
+	}
+
+	// The list marks the installed pack and offers the update
+	pack.Version = "1.2.0"
+	m.showPacks(packs.Index{Packs: []packs.Pack{pack, {Name: "kubectl", Version: "0.1.0"}}})
+	if len(m.palette.Items) != 2 {
+		t.Fatalf("pack list = %+v, want two packs", m.palette.Items)
+	}
+	if item := m.palette.Items[0]; item.Icon != "⬆" || item.Subtitle != "update v1.1.0 → v1.2.0 · Terraform CLI" {
+		t.Errorf("terraform item = %q %q, want an update", item.Icon, item.Subtitle)
+	}
+	if item := m.palette.Items[1]; item.Icon != "📦" {
+		t.Errorf("kubectl item icon = %q, want not installed", item.Icon)
+	}
+}
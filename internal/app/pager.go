@@ -0,0 +1,369 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/htelsiz/skitz/internal/hooks"
+)
+
+// Long output is read in a pager rather than the small terminal pane: o
+// opens a finished command's output, and ^pager commands open theirs as
+// soon as they finish. The pager is skitz's own view with / search, or
+// $PAGER when execution.pager is external.
+
+// defaultPager is run when execution.pager is external and $PAGER is unset
+const defaultPager = "less -R"
+
+// pagerOutputMsg carries the captured output of a ^pager command
+type pagerOutputMsg struct {
+	command string
+	tool    string
+	output  string
+	err     error
+	elapsed time.Duration
+}
+
+// executePager runs a command without a terminal and captures its output,
+// stderr included, for the pager
+func (m *model) executePager(run commandRun) tea.Cmd {
+	return func() tea.Msg {
+		started := time.Now()
+		out, err := newShellCommand(run.shell, run.command).CombinedOutput()
+		return pagerOutputMsg{
+			command: run.command,
+			tool:    run.resource,
+			output:  string(out),
+			err:     err,
+			elapsed: time.Since(started),
+		}
+	}
+}
+
+// handlePagerOutput opens the output of a ^pager command in the pager
+func (m *model) handlePagerOutput(msg pagerOutputMsg) tea.Cmd {
+	output := m.redactSecrets(msg.output)
+	done := func() tea.Msg {
+		return commandDoneMsg{
+			command: msg.command,
+			tool:    msg.tool,
+			success: msg.err == nil,
+			output:  output,
+			hook: &hooks.Event{
+				Command:  msg.command,
+				Resource: msg.tool,
+				Mode:     string(CommandPager),
+				ExitCode: hooks.ExitCode(msg.err),
+				Duration: msg.elapsed,
+			},
+		}
+	}
+	if strings.TrimSpace(output) == "" {
+		if msg.err != nil {
+			return tea.Batch(done, m.showNotification("✗", msg.err.Error(), "error"))
+		}
+		return tea.Batch(done, m.showNotification("✓", "The command printed nothing", "info"))
+	}
+	m.lastOutput = &commandOutput{command: msg.command, text: output}
+
+	title := msg.command
+	if msg.err != nil {
+		title += " · " + msg.err.Error()
+	}
+	return tea.Batch(done, m.openPager(title, output))
+}
+
+// terminalOutput returns the text of the terminal pane: the static output,
+// or what the command printed
+func (m model) terminalOutput() string {
+	if m.term.staticOutput != "" {
+		return m.term.staticOutput
+	}
+	if m.term.vt == nil {
+		return ""
+	}
+	return m.redactSecrets(terminalText(m.term.vt))
+}
+
+// openTerminalPager opens the terminal pane's output in the pager
+func (m *model) openTerminalPager() tea.Cmd {
+	output := m.terminalOutput()
+	if strings.TrimSpace(output) == "" {
+		return m.showNotification("📄", "No output to page", "info")
+	}
+	title := m.term.command
+	if m.term.staticOutput != "" {
+		title = m.term.staticTitle
+	}
+	if title == "" {
+		title = "Output"
+	}
+	return m.openPager(title, output)
+}
+
+// openPager shows text in the pager, or in $PAGER when execution.pager is
+// external
+func (m *model) openPager(title, text string) tea.Cmd {
+	if m.config.Execution.ExternalPager() {
+		return externalPagerCmd(text)
+	}
+	m.pager = &PagerView{
+		Title: title,
+		Lines: strings.Split(strings.TrimRight(ansi.Strip(text), "\n"), "\n"),
+		View:  viewport.New(0, 0),
+	}
+	m.layoutPager()
+	return nil
+}
+
+// externalPagerCmd suspends skitz and pipes text into $PAGER
+func externalPagerCmd(text string) tea.Cmd {
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = defaultPager
+	}
+	cmd := exec.Command("/bin/sh", "-c", pager)
+	cmd.Stdin = strings.NewReader(text)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			slog.Warn("pager failed", "pager", pager, "error", err)
+		}
+		return nil
+	})
+}
+
+// layoutPager sizes the pager to the window and renders its lines, with
+// the matches of the last search highlighted
+func (m *model) layoutPager() {
+	p := m.pager
+	if p == nil {
+		return
+	}
+	// Border, padding and the title and hint lines around the output
+	p.View.Width = max(m.width-12, 20)
+	p.View.Height = max(m.height-14, 5)
+
+	if p.Query == "" {
+		p.View.SetContent(strings.Join(p.Lines, "\n"))
+		return
+	}
+	match := lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("220"))
+	current := -1
+	if len(p.Matches) > 0 {
+		current = p.Matches[p.Match]
+	}
+	lines := make([]string, len(p.Lines))
+	for i, line := range p.Lines {
+		style := match
+		if i == current {
+			style = style.Background(lipgloss.Color("208")).Bold(true)
+		}
+		lines[i] = highlightMatches(line, p.Query, style)
+	}
+	p.View.SetContent(strings.Join(lines, "\n"))
+}
+
+// highlightMatches renders the case-insensitive occurrences of query in
+// line with style
+func highlightMatches(line, query string, style lipgloss.Style) string {
+	lower, q := strings.ToLower(line), strings.ToLower(query)
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 || len(lower) != len(line) {
+			b.WriteString(line)
+			return b.String()
+		}
+		b.WriteString(line[:i])
+		b.WriteString(style.Render(line[i : i+len(q)]))
+		line, lower = line[i+len(q):], lower[i+len(q):]
+	}
+}
+
+// searchPager finds the lines containing query and moves to the first
+// match at or below the top of the view
+func (m *model) searchPager(query string) {
+	p := m.pager
+	p.Query = query
+	p.Matches, p.Match = nil, 0
+	if query == "" {
+		m.layoutPager()
+		return
+	}
+	q := strings.ToLower(query)
+	for i, line := range p.Lines {
+		if strings.Contains(strings.ToLower(line), q) {
+			p.Matches = append(p.Matches, i)
+		}
+	}
+	for i, line := range p.Matches {
+		if line >= p.View.YOffset {
+			p.Match = i
+			break
+		}
+	}
+	m.layoutPager()
+	m.showPagerMatch()
+}
+
+// nextPagerMatch moves to the next match, or the previous one when delta
+// is -1, wrapping around
+func (m *model) nextPagerMatch(delta int) {
+	p := m.pager
+	if len(p.Matches) == 0 {
+		return
+	}
+	p.Match = (p.Match + delta + len(p.Matches)) % len(p.Matches)
+	m.layoutPager()
+	m.showPagerMatch()
+}
+
+// showPagerMatch scrolls the current match into view, a few lines below
+// the top
+func (m *model) showPagerMatch() {
+	p := m.pager
+	if len(p.Matches) == 0 {
+		return
+	}
+	p.View.SetYOffset(max(p.Matches[p.Match]-2, 0))
+}
+
+// handlePagerKeys routes keys to the pager
+func (m *model) handlePagerKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.pager
+
+	// The search query takes every key while it is being typed
+	if p.Searching {
+		switch msg.Type {
+		case tea.KeyEsc:
+			p.Searching = false
+		case tea.KeyEnter:
+			p.Searching = false
+			m.searchPager(p.Input)
+			if p.Query != "" && len(p.Matches) == 0 {
+				return m, m.showNotification("🔍", "No matches for "+p.Query, "info")
+			}
+		case tea.KeyBackspace:
+			p.Input = dropLastRune(p.Input)
+		case tea.KeyRunes, tea.KeySpace:
+			p.Input += string(msg.Runes)
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, pagerKeys.Close):
+		m.pager = nil
+		return m, nil
+
+	case msg.String() == "ctrl+c":
+		return m, tea.Quit
+
+	case key.Matches(msg, globalKeys.Help):
+		m.openHelp()
+		return m, nil
+
+	case key.Matches(msg, pagerKeys.Search):
+		p.Searching = true
+		p.Input = ""
+		return m, nil
+
+	case key.Matches(msg, pagerKeys.Next):
+		m.nextPagerMatch(1)
+		return m, nil
+
+	case key.Matches(msg, pagerKeys.Prev):
+		m.nextPagerMatch(-1)
+		return m, nil
+
+	case key.Matches(msg, pagerKeys.Top):
+		p.View.GotoTop()
+		return m, nil
+
+	case key.Matches(msg, pagerKeys.Bottom):
+		p.View.GotoBottom()
+		return m, nil
+
+	case key.Matches(msg, pagerKeys.Copy):
+		if err := clipboard.WriteAll(strings.Join(p.Lines, "\n")); err != nil {
+			return m, m.showNotification("!", "Copy failed: "+err.Error(), "error")
+		}
+		return m, m.showNotification("📋", "Output copied to clipboard", "success")
+	}
+
+	var cmd tea.Cmd
+	p.View, cmd = p.View.Update(msg)
+	return m, cmd
+}
+
+// renderPager renders the pager in place of the command list
+func (m model) renderPager(width int) string {
+	p := m.pager
+	if p == nil {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("213")).
+		Bold(true)
+	countStyle := lipgloss.NewStyle().Foreground(subtle)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(subtle).
+		Italic(true)
+	keyHintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("39"))
+
+	status := countStyle.Render(formatPagerPosition(p))
+	title := titleStyle.Render("📄 "+truncate(p.Title, max(width-40, 10))) + "  " + status
+
+	var hint string
+	switch {
+	case p.Searching:
+		hint = keyHintStyle.Render("/") + p.Input + "█  " +
+			keyHintStyle.Render("enter") + hintStyle.Render(" search  ") +
+			keyHintStyle.Render("esc") + hintStyle.Render(" cancel")
+	default:
+		hint = keyHintStyle.Render("↑↓") + hintStyle.Render(" scroll  ") +
+			keyHintStyle.Render("/") + hintStyle.Render(" search  ") +
+			keyHintStyle.Render("n/N") + hintStyle.Render(" next/prev match  ") +
+			keyHintStyle.Render("y") + hintStyle.Render(" copy  ") +
+			keyHintStyle.Render("esc") + hintStyle.Render(" close")
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("99")).
+		Padding(0, 1).
+		Width(width - 6)
+
+	content := lipgloss.JoinVertical(lipgloss.Left, title, "", p.View.View(), "", hint)
+	return lipgloss.NewStyle().Padding(1, 2).Render(boxStyle.Render(content))
+}
+
+// formatPagerPosition describes the scroll position and the current match,
+// e.g. "120 lines · 40% · match 2/5"
+func formatPagerPosition(p *PagerView) string {
+	parts := []string{fmt.Sprintf("%d lines", len(p.Lines))}
+	if p.View.TotalLineCount() > p.View.Height {
+		parts = append(parts, fmt.Sprintf("%.f%%", p.View.ScrollPercent()*100))
+	}
+	switch {
+	case p.Query == "":
+	case len(p.Matches) == 0:
+		parts = append(parts, "no matches")
+	default:
+		parts = append(parts, fmt.Sprintf("match %d/%d", p.Match+1, len(p.Matches)))
+	}
+	return strings.Join(parts, " · ")
+}
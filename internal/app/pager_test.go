@@ -0,0 +1,69 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestParseCommandsPager(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		pager bool
+		desc  string
+	}{
+		{"before ^run", "`journalctl -u nginx` Logs ^pager ^run", true, "Logs"},
+		{"after ^run", "`kubectl describe pod web` Describe ^run ^pager", true, "Describe"},
+		{"plain", "`journalctl -u nginx` Logs ^run", false, "Logs"},
+		{"not a word boundary", "`ls` Files ^run ^pagers", false, "Files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds := parseCommands(tt.line)
+			if len(cmds) != 1 {
+				t.Fatalf("parseCommands() = %+v", cmds)
+			}
+			if cmds[0].pager != tt.pager || cmds[0].description != tt.desc {
+				t.Errorf("pager = %v, description = %q, want %v, %q", cmds[0].pager, cmds[0].description, tt.pager, tt.desc)
+			}
+		})
+	}
+}
+
+func TestPagerSearch(t *testing.T) {
+	m := model{width: 120, height: 30}
+	m.handlePagerOutput(pagerOutputMsg{
+		command: "journalctl -u nginx",
+		output:  "start\nERROR one\nok\nok\nerror two\nstop\n",
+	})
+	if m.pager == nil {
+		t.Fatal("pager not opened")
+	}
+	if m.lastOutput == nil || m.lastOutput.command != "journalctl -u nginx" {
+		t.Errorf("lastOutput = %+v, want the paged output", m.lastOutput)
+	}
+
+	m.searchPager("error")
+	if got := m.pager.Matches; len(got) != 2 || got[0] != 1 || got[1] != 4 {
+		t.Fatalf("matches = %v, want lines 1 and 4", got)
+	}
+	m.nextPagerMatch(1)
+	if m.pager.Match != 1 {
+		t.Errorf("match after n = %d, want 1", m.pager.Match)
+	}
+	m.nextPagerMatch(1)
+	if m.pager.Match != 0 {
+		t.Errorf("match after n at the last match = %d, want to wrap to 0", m.pager.Match)
+	}
+	m.nextPagerMatch(-1)
+	if m.pager.Match != 1 {
+		t.Errorf("match after N at the first match = %d, want to wrap to 1", m.pager.Match)
+	}
+	if got := formatPagerPosition(m.pager); got != "6 lines · match 2/2" {
+		t.Errorf("position = %q", got)
+	}
+
+	m.searchPager("missing")
+	if len(m.pager.Matches) != 0 || formatPagerPosition(m.pager) != "6 lines · no matches" {
+		t.Errorf("position without matches = %q", formatPagerPosition(m.pager))
+	}
+}
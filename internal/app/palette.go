@@ -3,19 +3,25 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mark3labs/mcp-go/mcp"
 	openai "github.com/sashabaranov/go-openai"
 
+	"github.com/htelsiz/skitz/internal/audit"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/features"
 	mcppkg "github.com/htelsiz/skitz/internal/mcp"
 )
 
@@ -29,9 +35,13 @@ type PaletteItem struct {
 	Shortcut    string
 	Handler     func(m *model) tea.Cmd
 	ResourceIdx int
+	Tags        []string
+	Commands    []string // shell commands the item runs, shown in the preview
+	Command     *command // the resource command of a command item
 	MCPTool      *mcp.Tool
 	MCPServer    string
 	MCPServerURL string
+	MCPArgs      map[string]string // pre-fill the tool's parameter form
 }
 
 // PaletteState represents the current state of the command palette
@@ -59,6 +69,28 @@ type Palette struct {
 	LoadingText string
 	ResultTitle string
 	ResultText  string
+
+	// Spinner animates PaletteStateExecuting; StartedAt times the call
+	Spinner   spinner.Model
+	StartedAt time.Time
+
+	// Cancel aborts the call running in PaletteStateExecuting
+	Cancel context.CancelFunc
+
+	// PendingAction is a custom or plugin action collecting its parameters
+	PendingAction *pendingCustomAction
+
+	// ResultView scrolls ResultText in PaletteStateShowingResult;
+	// ResultTree replaces it when the result is JSON
+	ResultView   viewport.Model
+	ResultTree   *jsonTree
+	ResultFailed bool
+
+	// Marked are the items marked for a bulk action, in the order marked
+	Marked []PaletteItem
+
+	// index speeds up filtering Items (see palette_index.go)
+	index *paletteIndex
 }
 
 type mcpPendingTool struct {
@@ -68,11 +100,223 @@ type mcpPendingTool struct {
 	Args       map[string]any
 	FormValues map[string]*string
 	AITask     string
+	PresetName string // saves the arguments as a preset when set
 }
 
-
 func (m *model) buildPaletteItems() []PaletteItem {
-	return m.getMCPToolItems()
+	items := m.getActionPaletteItems()
+	items = append(items, m.getCustomActionItems()...)
+	items = append(items, m.getPluginItems()...)
+	items = append(items, m.getCommandPaletteItems()...)
+	items = append(items, m.getMCPToolItems()...)
+	return pinMCPItems(items, m.config.MCP.Favorites)
+}
+
+// getCommandPaletteItems returns every runnable command across resources.
+// They only appear once the user starts typing (see filterPaletteItems).
+func (m *model) getCommandPaletteItems() []PaletteItem {
+	var items []PaletteItem
+	for resIdx, res := range m.resources {
+		for secIdx, sec := range res.sections {
+			for _, c := range parseCommands(sec.content) {
+				resIdx, secIdx, lineNum := resIdx, secIdx, c.lineNum
+				subtitle := res.name
+				if c.description != "" {
+					subtitle += " · " + c.description
+				}
+				items = append(items, PaletteItem{
+					ID:          fmt.Sprintf("cmd:%s:%d:%d", res.name, secIdx, lineNum),
+					Icon:        "▸",
+					Title:       c.raw,
+					Subtitle:    subtitle,
+					Category:    "command",
+					ResourceIdx: resIdx,
+					Tags:        c.tags,
+					Commands:    []string{c.cmd},
+					Command:     &c,
+					Handler: func(m *model) tea.Cmd {
+						m.closePalette()
+						m.jumpToCommand(resIdx, secIdx, lineNum)
+						return nil
+					},
+				})
+			}
+		}
+	}
+	return items
+}
+
+// jumpToCommand opens a resource section in the detail view with the
+// command on lineNum selected.
+func (m *model) jumpToCommand(resIdx, secIdx, lineNum int) {
+	m.resCursor = resIdx
+	m.secCursor = secIdx
+	m.tagFilter = ""
+	m.currentView = viewDetail
+	m.initViewComponents()
+	for i, c := range m.commands {
+		if c.lineNum == lineNum {
+			m.cmdCursor = i
+			m.refreshCommandListDisplay()
+			break
+		}
+	}
+}
+
+// getActionPaletteItems returns built-in skitz actions available from the palette
+func (m *model) getActionPaletteItems() []PaletteItem {
+	var items []PaletteItem
+	if len(m.history) > 0 {
+		items = append(items, PaletteItem{
+			ID:       "action:repeat_last",
+			Icon:     "⚡",
+			Title:    "Repeat Last",
+			Subtitle: "Run the most recent command again",
+			Category: "action",
+			Commands: []string{m.history[0].Command},
+			Handler: func(m *model) tea.Cmd {
+				m.closePalette()
+				cmd, _ := actionRepeatLast(m)
+				return cmd
+			},
+		})
+	}
+	items = append(items, []PaletteItem{
+		{
+			ID:       "action:open_log",
+			Icon:     "📜",
+			Title:    "Open Log",
+			Subtitle: "View the current skitz log in $PAGER",
+			Category: "action",
+			Commands: []string{openLogCommand()},
+			Handler: func(m *model) tea.Cmd {
+				m.closePalette()
+				cmd, _ := actionOpenLog(m)
+				return cmd
+			},
+		},
+		{
+			ID:       "action:deploy_agent",
+			Icon:     "🚀",
+			Title:    "Deploy Agent",
+			Subtitle: "Run an agent on Azure, AWS or Google Cloud",
+			Category: "action",
+			Handler: func(m *model) tea.Cmd {
+				m.closePalette()
+				return runDeployAgent(m.config.Deploy)
+			},
+		},
+		{
+			ID:       "action:import_history",
+			Icon:     "📥",
+			Title:    "Import Shell History",
+			Subtitle: "Add frequent commands from your shell history to a resource",
+			Category: "action",
+			Handler: func(m *model) tea.Cmd {
+				m.closePalette()
+				return m.runImportHistory()
+			},
+		},
+		{
+			ID:       "action:notifications",
+			Icon:     "🔔",
+			Title:    "Notification History",
+			Subtitle: fmt.Sprintf("The last %d notifications", notificationLogSize),
+			Category: "action",
+			Handler: func(m *model) tea.Cmd {
+				m.showNotificationLog()
+				return nil
+			},
+		},
+		{
+			ID:       "action:recordings",
+			Icon:     "🎬",
+			Title:    "Recordings",
+			Subtitle: "Replay or export recorded terminal sessions",
+			Category: "action",
+			Handler: func(m *model) tea.Cmd {
+				return m.showRecordings()
+			},
+		},
+	}...)
+	items = append(items, m.getUpdatePaletteItems()...)
+	items = append(items, m.getCheatSheetPaletteItem())
+	items = append(items, m.getDoctorPaletteItem())
+	items = append(items, m.getProviderImportPaletteItems()...)
+	items = append(items, m.getMCPImportPaletteItem())
+	items = append(items, m.getPacksPaletteItem())
+	items = append(items, m.getJobsPaletteItem())
+	items = append(items, m.getReviewPaletteItems()...)
+	items = append(items, m.getHistoryPaletteItems()...)
+	items = append(items, m.getAgentArchivePaletteItems()...)
+	items = append(items, m.getVariablesPaletteItem())
+	items = append(items, m.getLockPaletteItem())
+	items = append(items, PaletteItem{
+		ID:       "action:offline",
+		Icon:     "✈",
+		Title:    "Toggle Offline Mode",
+		Subtitle: "Pause MCP polling and AI calls",
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			m.closePalette()
+			return m.toggleOffline()
+		},
+	})
+	return append(items, getFeatureFlagsPaletteItems()...)
+}
+
+// getFeatureFlagsPaletteItems returns the Feature Flags action, when there
+// are flags to toggle
+func getFeatureFlagsPaletteItems() []PaletteItem {
+	if len(features.Known) == 0 {
+		return nil
+	}
+	return []PaletteItem{{
+		ID:       "action:feature_flags",
+		Icon:     "🚩",
+		Title:    "Feature Flags",
+		Subtitle: "View and toggle experimental features",
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			m.showFeatureFlags()
+			return nil
+		},
+	}}
+}
+
+// showFeatureFlags replaces the palette list with one item per feature flag.
+// Selecting a flag toggles it for the current session.
+func (m *model) showFeatureFlags() {
+	m.palette.Query = ""
+	m.palette.Items = m.getFeatureFlagItems()
+	m.palette.Filtered = m.palette.Items
+	if m.palette.Cursor >= len(m.palette.Items) {
+		m.palette.Cursor = 0
+	}
+}
+
+func (m *model) getFeatureFlagItems() []PaletteItem {
+	var items []PaletteItem
+	for _, st := range m.features.States() {
+		name := st.Name
+		icon := "○"
+		if st.Enabled {
+			icon = "●"
+		}
+		items = append(items, PaletteItem{
+			ID:       "feature:" + name,
+			Icon:     icon,
+			Title:    name,
+			Subtitle: fmt.Sprintf("%s (%s)", st.Description, st.Source),
+			Category: "feature",
+			Handler: func(m *model) tea.Cmd {
+				enabled := m.features.Toggle(name)
+				m.showFeatureFlags()
+				return m.showNotification("🚩", fmt.Sprintf("%s %s for this session", name, boolToOnOff(enabled)), "info")
+			},
+		})
+	}
+	return items
 }
 
 func (m *model) getMCPToolItems() []PaletteItem {
@@ -80,13 +324,41 @@ func (m *model) getMCPToolItems() []PaletteItem {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	for _, server := range m.config.MCP.Servers {
-		tools, err := mcppkg.FetchTools(ctx, server.URL)
-		if err != nil {
-			continue
+	mcpCfg := m.config.EffectiveMCP()
+	if !mcpCfg.Enabled {
+		return nil
+	}
+
+	// Servers that can't be reached, and every server while offline, list
+	// the tools they had last time
+	cachePath := mcppkg.ToolCachePath(config.DataDir)
+	cache := mcppkg.LoadToolCache(cachePath)
+	updated := false
+	known := make(map[[2]string]knownMCPTool)
+	for _, server := range mcpCfg.ActiveServers() {
+		tools, cached := cache[server.URL], true
+		if !m.offline {
+			if fetched, err := mcppkg.FetchTools(ctx, server.URL); err == nil {
+				tools, cached = fetched, false
+				cache[server.URL] = fetched
+				updated = true
+			}
 		}
 		for _, tool := range tools {
-			items = append(items, m.mcpToolToPaletteItem(server.Name, server.URL, tool))
+			item := m.mcpToolToPaletteItem(server.Name, server.URL, tool)
+			if cached {
+				item.Subtitle = truncate("cached · "+tool.Description, 50)
+			}
+			items = append(items, item)
+			items = append(items, mcpAliasItems(m.config.MCP.Aliases, server.Name, server.URL, tool)...)
+			items = append(items, mcpPresetItems(m.config.MCP.Presets, server.Name, server.URL, tool)...)
+			known[[2]string{server.URL, tool.Name}] = knownMCPTool{server: server.Name, tool: tool}
+		}
+	}
+	items = append(items, m.mcpHistoryItems(known)...)
+	if updated {
+		if err := cache.Save(cachePath); err != nil {
+			slog.Warn("failed to save MCP tool cache", "error", err)
 		}
 	}
 	return items
@@ -106,44 +378,63 @@ func (m *model) mcpToolToPaletteItem(serverName string, serverURL string, tool m
 	}
 }
 
-func executeMCPToolWithArgs(serverURL string, toolName string, args map[string]any) tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+func executeMCPToolWithArgs(parent context.Context, serverURL string, toolName string, args map[string]any) tea.Cmd {
+	return func() (msg tea.Msg) {
+		ctx, cancel := context.WithTimeout(parent, 120*time.Second)
 		defer cancel()
+		// A cancelled call has already returned the palette to search
+		defer func() {
+			if errors.Is(parent.Err(), context.Canceled) {
+				msg = nil
+			}
+		}()
+		requested := time.Now()
+		defer func() {
+			if res, ok := msg.(paletteResultMsg); ok {
+				res.calls = []config.MCPCall{mcpCallRecord(serverURL, toolName, args, requested, res)}
+				msg = res
+			}
+		}()
 
 		client, err := mcppkg.NewClient(serverURL)
 		if err != nil {
-			return staticOutputMsg{
+			return paletteResultMsg{
 				title:  toolName,
+				failed: true,
 				output: fmt.Sprintf("Error: Failed to create client: %v", err),
 			}
 		}
 
 		if err := client.Connect(ctx); err != nil {
-			return staticOutputMsg{
+			return paletteResultMsg{
 				title:  toolName,
+				failed: true,
 				output: fmt.Sprintf("Error: Failed to connect: %v", err),
 			}
 		}
 		defer client.Close()
 
+		started := time.Now()
 		result, err := client.CallTool(ctx, toolName, args)
+		auditCall(audit.KindMCP, toolName, serverURL, started, err)
 		if err != nil {
-			return staticOutputMsg{
+			return paletteResultMsg{
 				title:  toolName,
+				failed: true,
 				output: fmt.Sprintf("Error: %v", err),
 			}
 		}
 
 		output, err := extractTextFromResult(result)
 		if err != nil {
-			return staticOutputMsg{
+			return paletteResultMsg{
 				title:  toolName,
+				failed: true,
 				output: fmt.Sprintf("Error parsing result: %v", err),
 			}
 		}
 
-		return staticOutputMsg{
+		return paletteResultMsg{
 			title:  toolName,
 			output: output,
 		}
@@ -161,7 +452,7 @@ func (m *model) startMCPToolWithAI(item PaletteItem) tea.Cmd {
 		ServerURL:  item.MCPServerURL,
 		Tool:       *tool,
 		Args:       make(map[string]any),
-		FormValues: make(map[string]*string),
+		FormValues: mcpFormValues(*tool, m.mcpArgDefaults(item.MCPServer, *tool, item.MCPArgs)),
 	}
 
 	m.palette.State = PaletteStateAIInput
@@ -175,16 +466,13 @@ func (m *model) startMCPToolInput(item PaletteItem) tea.Cmd {
 	if tool == nil {
 		return nil
 	}
-
-	if len(tool.InputSchema.Properties) == 0 {
-		return executeMCPToolWithArgs(item.MCPServerURL, tool.Name, nil)
+	if m.offline {
+		return m.showNotification("✈", errMCPOffline.Error(), "warning")
 	}
 
-	formValues := make(map[string]*string)
-
-	for paramName := range tool.InputSchema.Properties {
-		val := ""
-		formValues[paramName] = &val
+	if len(tool.InputSchema.Properties) == 0 {
+		ctx, spin := m.startPaletteExecution("Executing tool...")
+		return tea.Batch(spin, executeMCPToolWithArgs(ctx, item.MCPServerURL, tool.Name, nil))
 	}
 
 	m.palette.PendingTool = &mcpPendingTool{
@@ -192,7 +480,7 @@ func (m *model) startMCPToolInput(item PaletteItem) tea.Cmd {
 		ServerURL:  item.MCPServerURL,
 		Tool:       *tool,
 		Args:       make(map[string]any),
-		FormValues: formValues,
+		FormValues: mcpFormValues(*tool, m.mcpArgDefaults(item.MCPServer, *tool, item.MCPArgs)),
 	}
 
 	return m.buildParameterForm()
@@ -278,9 +566,13 @@ func (m *model) buildParameterForm() tea.Cmd {
 	if len(fields) == 0 {
 		return nil
 	}
+	fields = append(fields, huh.NewInput().
+		Title("Save as preset").
+		Description("Name to re-run these arguments from the palette; leave empty to run once").
+		Value(&pt.PresetName))
 
 	m.palette.InputForm = huh.NewForm(huh.NewGroup(fields...)).
-		WithWidth(100).
+		WithWidth(m.paramFormWidth()).
 		WithShowHelp(true).
 		WithShowErrors(true).
 		WithTheme(huh.ThemeCatppuccin())
@@ -311,7 +603,12 @@ func (m *model) createFormField(paramName string, paramMap map[string]interface{
 		title = paramName + " *"
 	}
 
+	if len(schemaExamples(paramMap)) > 0 {
+		description = strings.TrimSpace(description + " (ctrl+x: next example)")
+	}
+
 	valuePtr := pt.FormValues[paramName]
+	suggestions := m.argSuggestions(mcpArgKey(mcpToolRef(pt.ServerName, pt.Tool.Name), paramName), paramName)
 
 	if enumVal, ok := paramMap["enum"].([]interface{}); ok && len(enumVal) > 0 {
 		options := make([]huh.Option[string], len(enumVal))
@@ -320,6 +617,7 @@ func (m *model) createFormField(paramName string, paramMap map[string]interface{
 			options[i] = huh.NewOption(str, str)
 		}
 		return huh.NewSelect[string]().
+			Key(paramName).
 			Title(title).
 			Description(description).
 			Options(options...).
@@ -327,11 +625,11 @@ func (m *model) createFormField(paramName string, paramMap map[string]interface{
 	}
 
 	if paramType == "boolean" {
-		boolPtr := new(bool)
 		return huh.NewConfirm().
+			Key(paramName).
 			Title(title).
 			Description(description).
-			Value(boolPtr)
+			Accessor(boolFormValue{valuePtr})
 	}
 
 	if paramType == "string" {
@@ -353,63 +651,45 @@ func (m *model) createFormField(paramName string, paramMap map[string]interface{
 
 		if useLongText {
 			return huh.NewText().
+				Key(paramName).
 				Title(title).
 				Description(description).
 				Placeholder(placeholder).
 				CharLimit(maxLength).
+				Validate(paramValidator(paramName, paramMap, isRequired)).
 				Value(valuePtr)
 		}
 
 		input := huh.NewInput().
+			Key(paramName).
 			Title(title).
 			Description(description).
 			Placeholder(placeholder).
+			Suggestions(suggestions).
+			Validate(paramValidator(paramName, paramMap, isRequired)).
 			Value(valuePtr)
 
-		if isRequired {
-			input = input.Validate(func(s string) error {
-				if strings.TrimSpace(s) == "" {
-					return fmt.Errorf("%s is required", paramName)
-				}
-				return nil
-			})
-		}
-
 		return input
 	}
 
 	if paramType == "number" || paramType == "integer" {
 		input := huh.NewInput().
+			Key(paramName).
 			Title(title).
 			Description(description).
 			Placeholder("Enter a number").
+			Suggestions(suggestions).
+			Validate(paramValidator(paramName, paramMap, isRequired)).
 			Value(valuePtr)
 
-		input = input.Validate(func(s string) error {
-			if s == "" && !isRequired {
-				return nil
-			}
-			if s == "" && isRequired {
-				return fmt.Errorf("%s is required", paramName)
-			}
-			if paramType == "integer" {
-				if _, err := fmt.Sscanf(s, "%d", new(int)); err != nil {
-					return fmt.Errorf("must be an integer")
-				}
-			} else {
-				if _, err := fmt.Sscanf(s, "%f", new(float64)); err != nil {
-					return fmt.Errorf("must be a number")
-				}
-			}
-			return nil
-		})
-
 		return input
 	}
 
 	return huh.NewInput().
+		Key(paramName).
 		Title(title).
 		Description(description).
+		Validate(paramValidator(paramName, paramMap, isRequired)).
 		Value(valuePtr)
 }
 
@@ -419,23 +699,44 @@ func (m *model) handleParameterSubmit() tea.Cmd {
 		return m.showNotification("⚠️", "No pending tool found", "error")
 	}
 
-	required := make(map[string]bool)
-	for _, r := range pt.Tool.InputSchema.Required {
-		required[r] = true
-	}
-
+	values := make(map[string]string)
 	for paramName, valuePtr := range pt.FormValues {
-		if valuePtr == nil {
-			continue
+		if valuePtr != nil {
+			values[paramName] = strings.TrimSpace(*valuePtr)
 		}
+	}
+	args, err := mcpToolArgs(pt.Tool, values)
+	if err != nil {
+		return m.showNotification("⚠️", err.Error(), "warning")
+	}
+	pt.Args = args
+	ref := mcpToolRef(pt.ServerName, pt.Tool.Name)
+	m.rememberArgs(values, func(name string) string { return mcpArgKey(ref, name) })
 
-		value := strings.TrimSpace(*valuePtr)
+	var saved tea.Cmd
+	if name := strings.TrimSpace(pt.PresetName); name != "" {
+		saved = m.saveMCPPreset(name, ref, values)
+	}
 
-		if value == "" && !required[paramName] {
+	m.palette.InputForm = nil
+	serverURL := pt.ServerURL
+	toolName := pt.Tool.Name
+	m.palette.PendingTool = nil
+
+	ctx, spin := m.startPaletteExecution("Executing tool...")
+	return tea.Batch(spin, executeMCPToolWithArgs(ctx, serverURL, toolName, args), saved)
+}
+
+// mcpToolArgs converts the form values of tool's parameters to the types
+// its input schema declares. Empty values are left out.
+func mcpToolArgs(tool mcp.Tool, values map[string]string) (map[string]any, error) {
+	args := make(map[string]any)
+	for paramName, value := range values {
+		if value == "" {
 			continue
 		}
 
-		paramDef := pt.Tool.InputSchema.Properties[paramName]
+		paramDef := tool.InputSchema.Properties[paramName]
 		paramMap, ok := paramDef.(map[string]interface{})
 		if !ok {
 			continue
@@ -445,69 +746,54 @@ func (m *model) handleParameterSubmit() tea.Cmd {
 		if t, ok := paramMap["type"].(string); ok {
 			paramType = t
 		}
+		if err := checkParamValue(paramMap, value); err != nil {
+			return nil, fmt.Errorf("%s %w", paramName, err)
+		}
 
 		switch paramType {
 		case "boolean":
 			boolVal := value == "true" || value == "yes" || value == "1"
-			pt.Args[paramName] = boolVal
+			args[paramName] = boolVal
 
 		case "number":
 			var floatVal float64
-			if _, err := fmt.Sscanf(value, "%f", &floatVal); err == nil {
-				pt.Args[paramName] = floatVal
-			} else if value != "" {
-				return m.showNotification("⚠️", fmt.Sprintf("Invalid number for %s", paramName), "warning")
+			if _, err := fmt.Sscanf(value, "%f", &floatVal); err != nil {
+				return nil, fmt.Errorf("invalid number for %s", paramName)
 			}
+			args[paramName] = floatVal
 
 		case "integer":
 			var intVal int
-			if _, err := fmt.Sscanf(value, "%d", &intVal); err == nil {
-				pt.Args[paramName] = intVal
-			} else if value != "" {
-				return m.showNotification("⚠️", fmt.Sprintf("Invalid integer for %s", paramName), "warning")
+			if _, err := fmt.Sscanf(value, "%d", &intVal); err != nil {
+				return nil, fmt.Errorf("invalid integer for %s", paramName)
 			}
+			args[paramName] = intVal
 
 		case "array", "object":
-			if value != "" {
-				var jsonValue interface{}
-				if err := json.Unmarshal([]byte(value), &jsonValue); err == nil {
-					pt.Args[paramName] = jsonValue
-				} else {
-					return m.showNotification("⚠️", fmt.Sprintf("Invalid JSON for %s: %v", paramName, err), "warning")
-				}
+			var jsonValue interface{}
+			if err := json.Unmarshal([]byte(value), &jsonValue); err != nil {
+				return nil, fmt.Errorf("invalid JSON for %s: %v", paramName, err)
 			}
+			args[paramName] = jsonValue
 
 		default:
-			if value != "" {
-				pt.Args[paramName] = value
-			}
+			args[paramName] = value
 		}
 	}
-
-	m.palette.InputForm = nil
-	serverURL := pt.ServerURL
-	toolName := pt.Tool.Name
-	args := pt.Args
-	m.palette.PendingTool = nil
-
-	m.palette.State = PaletteStateExecuting
-	m.palette.LoadingText = "Executing tool..."
-
-	return executeMCPToolWithArgs(serverURL, toolName, args)
+	return args, nil
 }
 
+// filterPaletteItems matches items against query. Words starting with '#'
+// are tag facets that an item must carry; the remaining text is matched
+// against title, subtitle and category, and an MCP tool's full description.
+// Resource commands are only listed once there is a query, to keep the idle
+// palette short.
 func filterPaletteItems(items []PaletteItem, query string) []PaletteItem {
-	if query == "" {
-		return items
-	}
+	tags, text := parsePaletteQuery(query)
 
-	query = strings.ToLower(query)
 	var filtered []PaletteItem
-
 	for _, item := range items {
-		if strings.Contains(strings.ToLower(item.Title), query) ||
-			strings.Contains(strings.ToLower(item.Subtitle), query) ||
-			strings.Contains(strings.ToLower(item.Category), query) {
+		if paletteItemAllowed(item, query, tags) && strings.Contains(paletteItemText(item), text) {
 			filtered = append(filtered, item)
 		}
 	}
@@ -515,44 +801,138 @@ func filterPaletteItems(items []PaletteItem, query string) []PaletteItem {
 	return filtered
 }
 
+// parsePaletteQuery splits query into its tag facets and the text to match
+func parsePaletteQuery(query string) ([]string, string) {
+	var tags, words []string
+	for _, w := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(w, "#") && len(w) > 1 {
+			tags = append(tags, w[1:])
+		} else {
+			words = append(words, w)
+		}
+	}
+	return tags, strings.Join(words, " ")
+}
+
+// paletteItemAllowed reports whether item may be listed for query, before
+// its text is matched
+func paletteItemAllowed(item PaletteItem, query string, tags []string) bool {
+	if query == "" && item.Category == "command" {
+		return false
+	}
+	return itemHasTags(item, tags)
+}
+
+// paletteItemText returns the lowercased text item is matched on, a line
+// per field
+func paletteItemText(item PaletteItem) string {
+	fields := []string{item.Title, item.Subtitle, item.Category}
+	if item.MCPTool != nil {
+		fields = append(fields, item.MCPTool.Description)
+	}
+	return strings.ToLower(strings.Join(fields, "\n"))
+}
+
+func itemHasTags(item PaletteItem, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, t := range item.Tags {
+			if strings.EqualFold(t, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func (m *model) openPalette() {
 	m.palette.State = PaletteStateSearching
 	m.palette.Query = ""
 	m.palette.Items = m.buildPaletteItems()
-	m.palette.Filtered = m.palette.Items
+	m.palette.Filtered = filterPaletteItems(m.palette.Items, "")
+	m.palette.Cursor = 0
+	m.palette.Marked = nil
+}
+
+// paletteElapsedAfter is how long a palette call runs before its elapsed
+// time is shown
+const paletteElapsedAfter = 3 * time.Second
+
+// startPaletteExecution switches the palette to the executing state and
+// starts the spinner. The returned context is cancelled by
+// cancelPaletteExecution.
+func (m *model) startPaletteExecution(loadingText string) (context.Context, tea.Cmd) {
+	m.cancelPaletteExecution()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.palette.Cancel = cancel
+	m.palette.State = PaletteStateExecuting
+	m.palette.LoadingText = loadingText
+	m.palette.StartedAt = time.Now()
+	m.palette.Spinner = spinner.New(
+		spinner.WithSpinner(spinner.MiniDot),
+		spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("114"))),
+	)
+	return ctx, m.palette.Spinner.Tick
+}
+
+// cancelPaletteExecution aborts the running palette call, if any
+func (m *model) cancelPaletteExecution() {
+	if m.palette.Cancel != nil {
+		m.palette.Cancel()
+		m.palette.Cancel = nil
+	}
+}
+
+// paletteElapsed returns the running call's elapsed time, or "" while it
+// is shorter than paletteElapsedAfter
+func (m model) paletteElapsed() string {
+	if m.palette.StartedAt.IsZero() {
+		return ""
+	}
+	elapsed := time.Since(m.palette.StartedAt)
+	if elapsed < paletteElapsedAfter {
+		return ""
+	}
+	return elapsed.Truncate(time.Second).String()
+}
+
+// cancelPaletteCall aborts the running MCP or AI call and returns to the
+// search list
+func (m *model) cancelPaletteCall() tea.Cmd {
+	m.cancelPaletteExecution()
+	m.palette.State = PaletteStateSearching
+	m.palette.PendingTool = nil
+	m.palette.LoadingText = ""
+	m.palette.Query = ""
+	m.palette.Filtered = filterPaletteItems(m.palette.Items, "")
 	m.palette.Cursor = 0
+	return m.showNotification("⏹", "Cancelled", "info")
 }
 
 func (m *model) closePalette() {
+	m.cancelPaletteExecution()
 	m.palette.State = PaletteStateIdle
 	m.palette.Query = ""
 	m.palette.Cursor = 0
 	m.palette.InputForm = nil
 	m.palette.PendingTool = nil
+	m.palette.PendingAction = nil
 	m.palette.LoadingText = ""
 	m.palette.ResultTitle = ""
 	m.palette.ResultText = ""
-}
-
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
+	m.palette.ResultTree = nil
+	m.palette.ResultFailed = false
+	m.palette.Marked = nil
 }
 
 func (m model) renderPalette() string {
-	paletteWidth := int(float64(m.width) * 0.85)
-	paletteHeight := int(float64(m.height) * 0.80)
-
-	if paletteWidth < 100 {
-		paletteWidth = 100
-	}
-	if paletteHeight < 30 {
-		paletteHeight = 30
-	}
+	paletteWidth, paletteHeight := m.paletteSize()
 
-	accentColor := lipgloss.Color("99")
+	accentColor := primary
 
 	var lines []string
 
@@ -600,6 +980,12 @@ func (m model) renderPalette() string {
 				keyStyle.Render("tab") + textStyle.Render(" next  ") +
 				keyStyle.Render("enter") + textStyle.Render(" submit  ") +
 				keyStyle.Render("esc") + textStyle.Render(" cancel")
+		} else if pa := m.palette.PendingAction; pa != nil {
+			headerContent = lipgloss.NewStyle().Foreground(accentColor).Bold(true).Render(pa.Icon+" "+pa.Name) +
+				textStyle.Render("  ") +
+				keyStyle.Render("tab") + textStyle.Render(" next  ") +
+				keyStyle.Render("enter") + textStyle.Render(" run  ") +
+				keyStyle.Render("esc") + textStyle.Render(" cancel")
 		}
 
 		infoBar := lipgloss.NewStyle().
@@ -624,6 +1010,15 @@ func (m model) renderPalette() string {
 		lines = append(lines, "")
 
 		formView := m.palette.InputForm.View()
+		if m.palette.PendingTool != nil {
+			if previewWidth := m.mcpPreviewWidth(); previewWidth > 0 {
+				formView = lipgloss.JoinHorizontal(lipgloss.Top,
+					lipgloss.NewStyle().Width(m.paramFormWidth()+2).Render(formView),
+					m.renderMCPPreview(previewWidth, paletteHeight-8))
+			} else {
+				formView = lipgloss.JoinVertical(lipgloss.Left, formView, "", m.renderMCPPreview(paletteWidth-4, 14))
+			}
+		}
 		lines = append(lines, formView)
 
 		if m.term.active {
@@ -658,30 +1053,61 @@ func (m model) renderPaletteResult(paletteWidth, paletteHeight int, accentColor
 		Width(paletteWidth - 4).
 		Padding(0, 1)
 
-	lines = append(lines, headerStyle.Render("✓ "+m.palette.ResultTitle))
+	icon := "✓ "
+	if m.palette.ResultFailed {
+		icon = "✗ "
+	}
+	lines = append(lines, headerStyle.Render(icon+m.palette.ResultTitle))
 	lines = append(lines, "")
-
-	r, err := glamour.NewTermRenderer(
-		glamour.WithStylesFromJSONBytes([]byte(customStyleJSON)),
-		glamour.WithWordWrap(paletteWidth-8),
-	)
-
-	var renderedOutput string
-	if err == nil {
-		renderedOutput, _ = r.Render(m.palette.ResultText)
+	if tree := m.palette.ResultTree; tree != nil {
+		body := tree.view(m.palette.ResultView.Width, m.palette.ResultView.Height)
+		lines = append(lines, lipgloss.NewStyle().Height(m.palette.ResultView.Height).Render(body))
 	} else {
-		renderedOutput = m.palette.ResultText
+		lines = append(lines, m.palette.ResultView.View())
 	}
-
-	lines = append(lines, renderedOutput)
 	lines = append(lines, "")
 
+	textStyle := lipgloss.NewStyle().Foreground(subtle)
+	keyStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("252")).
+		Background(lipgloss.Color("238")).
+		Padding(0, 1)
+	var hint string
+	switch tree := m.palette.ResultTree; {
+	case tree != nil && tree.filtering:
+		hint = keyStyle.Render("/") + textStyle.Render(" "+tree.filter) +
+			lipgloss.NewStyle().Foreground(secondary).Render("▌") + textStyle.Render("  ") +
+			keyStyle.Render("enter") + textStyle.Render(" apply  ") +
+			keyStyle.Render("esc") + textStyle.Render(" clear")
+	case tree != nil:
+		hint = keyStyle.Render("↑↓") + textStyle.Render(" move  ") +
+			keyStyle.Render("←→") + textStyle.Render(" fold  ") +
+			keyStyle.Render("E/C") + textStyle.Render(" all  ") +
+			keyStyle.Render("/") + textStyle.Render(" filter  ") +
+			keyStyle.Render("p") + textStyle.Render(" path  ") +
+			keyStyle.Render("s") + textStyle.Render(" save  ") +
+			keyStyle.Render("y") + textStyle.Render(" copy  ") +
+			keyStyle.Render("a") + textStyle.Render(" ask AI  ") +
+			keyStyle.Render("esc") + textStyle.Render(" close")
+		if tree.filter != "" {
+			hint += textStyle.Render("  filter: " + tree.filter)
+		}
+	default:
+		hint = keyStyle.Render("↑↓") + textStyle.Render(" scroll  ") +
+			keyStyle.Render("s") + textStyle.Render(" save  ") +
+			keyStyle.Render("y") + textStyle.Render(" copy  ") +
+			keyStyle.Render("a") + textStyle.Render(" ask AI  ") +
+			keyStyle.Render("esc") + textStyle.Render(" close")
+		if scroll := m.paletteResultScroll(); scroll != "" {
+			hint += textStyle.Render("  " + scroll)
+		}
+	}
+
 	hintStyle := lipgloss.NewStyle().
 		Background(lipgloss.Color("234")).
-		Foreground(subtle).
 		Width(paletteWidth - 4).
 		Padding(0, 1)
-	lines = append(lines, hintStyle.Render("Press Enter or Esc to close"))
+	lines = append(lines, hintStyle.Render(hint))
 
 	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
 
@@ -735,7 +1161,11 @@ func (m model) renderPaletteList(width, height int, accentColor lipgloss.Color)
 			toolName = m.palette.PendingTool.Tool.Name
 		}
 		infoContent = lipgloss.NewStyle().Foreground(lipgloss.Color("114")).Bold(true).Render("🤖 " + toolName) +
-			textStyle.Render("  Executing...")
+			textStyle.Render("  Executing ") + m.palette.Spinner.View()
+		if elapsed := m.paletteElapsed(); elapsed != "" {
+			infoContent += textStyle.Render(" " + elapsed)
+		}
+		infoContent += textStyle.Render("  ") + keyStyle.Render("esc") + textStyle.Render(" cancel")
 
 	case PaletteStateAIInput:
 		toolName := "AI Agent"
@@ -752,7 +1182,19 @@ func (m model) renderPaletteList(width, height int, accentColor lipgloss.Color)
 			textStyle.Render(" commands  ") +
 			keyStyle.Render("↑↓") + textStyle.Render(" select  ") +
 			keyStyle.Render("enter") + textStyle.Render(" run  ") +
-			keyStyle.Render("ctrl+a") + textStyle.Render(" AI agent")
+			keyStyle.Render("ctrl+a") + textStyle.Render(" AI agent  ") +
+			keyStyle.Render("ctrl+f") + textStyle.Render(" pin  ") +
+			keyStyle.Render("tab") + textStyle.Render(" mark")
+		if n := len(m.palette.Marked); n > 0 {
+			infoContent = countStyle.Render(fmt.Sprintf(" %d", n)) +
+				textStyle.Render(" marked  ") +
+				keyStyle.Render("tab") + textStyle.Render(" mark  ") +
+				keyStyle.Render("enter") + textStyle.Render(" bulk actions  ") +
+				keyStyle.Render("esc") + textStyle.Render(" close")
+		}
+		if m.config.Branding.Name != "" {
+			infoContent = countStyle.Render(" "+m.brandName()) + textStyle.Render(" ·") + infoContent
+		}
 	}
 
 	infoBar := lipgloss.NewStyle().
@@ -781,7 +1223,7 @@ func (m model) renderPaletteList(width, height int, accentColor lipgloss.Color)
 
 	default:
 		if m.palette.Query == "" {
-			queryDisplay = lipgloss.NewStyle().Foreground(subtle).Italic(true).Render("Type to filter...")
+			queryDisplay = lipgloss.NewStyle().Foreground(subtle).Italic(true).Render("Type to filter, > to run a shell command, @ to jump to a resource...")
 		} else {
 			queryDisplay = lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Render(m.palette.Query) +
 				lipgloss.NewStyle().Foreground(secondary).Render("▌")
@@ -808,10 +1250,8 @@ func (m model) renderPaletteList(width, height int, accentColor lipgloss.Color)
 			Width(width - 4).
 			Align(lipgloss.Center)
 
-		spinner := "⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏"
-		frame := spinner[0:1]
 		lines = append(lines, "")
-		lines = append(lines, loadingStyle.Render(frame+" "+m.palette.LoadingText))
+		lines = append(lines, loadingStyle.Render(m.palette.Spinner.View()+" "+m.palette.LoadingText))
 		lines = append(lines, "")
 
 		infoStyle := lipgloss.NewStyle().
@@ -820,7 +1260,11 @@ func (m model) renderPaletteList(width, height int, accentColor lipgloss.Color)
 			Padding(1, 1).
 			Width(width - 4).
 			Align(lipgloss.Center)
-		lines = append(lines, infoStyle.Render("Please wait..."))
+		wait := "Please wait..."
+		if elapsed := m.paletteElapsed(); elapsed != "" {
+			wait = "Please wait... " + elapsed
+		}
+		lines = append(lines, infoStyle.Render(wait))
 
 	case PaletteStateAIInput:
 		if m.palette.PendingTool != nil {
@@ -883,12 +1327,39 @@ func (m model) renderPaletteList(width, height int, accentColor lipgloss.Color)
 			case "mcp":
 				catIcon = "🔌"
 				catName = "MCP Tools"
+			case "plugin":
+				catIcon = "🧩"
+				catName = "Plugins"
 			case "history":
 				catIcon = "🕐"
 				catName = "Recent"
 			case "favorite":
 				catIcon = "⭐"
 				catName = "Favorites"
+			case "alias":
+				catIcon = "↪"
+				catName = "Aliases"
+			case "preset":
+				catIcon = "▶"
+				catName = "Presets"
+			case "quick":
+				catIcon = "✦"
+				catName = "Quick Actions"
+			case "feature":
+				catIcon = "🚩"
+				catName = "Feature Flags"
+			case "notification":
+				catIcon = "🔔"
+				catName = "Notifications"
+			case "command":
+				catIcon = "▸"
+				catName = "Commands"
+			case bulkCategory:
+				catIcon = "☰"
+				catName = "Bulk Actions"
+			case mcpHistoryCategory:
+				catIcon = "↺"
+				catName = "MCP History"
 			}
 
 			catHeader := lipgloss.NewStyle().
@@ -907,14 +1378,16 @@ func (m model) renderPaletteList(width, height int, accentColor lipgloss.Color)
 
 				title := item.Title
 				maxTitleLen := width - 10
-				if len(title) > maxTitleLen {
-					title = title[:maxTitleLen-3] + "..."
-				}
+				title = truncate(title, maxTitleLen)
 
 				icon := item.Icon
 				if icon == "" {
 					icon = "•"
 				}
+				mark := " "
+				if m.palette.markIndex(item.ID) >= 0 {
+					mark = lipgloss.NewStyle().Foreground(accentColor).Bold(true).Render("●")
+				}
 
 				if isSelected {
 					itemLine := lipgloss.NewStyle().
@@ -926,13 +1399,13 @@ func (m model) renderPaletteList(width, height int, accentColor lipgloss.Color)
 						Render(fmt.Sprintf("%s %s", icon, title))
 
 					indicator := lipgloss.NewStyle().Foreground(accentColor).Bold(true).Render("▶")
-					lines = append(lines, " "+indicator+" "+itemLine)
+					lines = append(lines, mark+indicator+" "+itemLine)
 				} else {
 					itemLine := lipgloss.NewStyle().
 						Foreground(lipgloss.Color("252")).
 						Padding(0, 1).
 						Render(fmt.Sprintf(" %s %s", icon, title))
-					lines = append(lines, "    "+itemLine)
+					lines = append(lines, mark+"   "+itemLine)
 				}
 
 				currentIndex++
@@ -1048,6 +1521,14 @@ func (m model) renderPalettePreview(width, height int, accentColor lipgloss.Colo
 		lines = append(lines, badgeStyle.Render(strings.ToUpper(selectedItem.Category)))
 	}
 
+	if len(selectedItem.Tags) > 0 {
+		var chips []string
+		for _, t := range selectedItem.Tags {
+			chips = append(chips, renderTagChip(t))
+		}
+		lines = append(lines, lipgloss.NewStyle().Padding(0, 1).Render(strings.Join(chips, " ")))
+	}
+
 	divider := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("238")).
 		Padding(0, 1).
@@ -1071,6 +1552,10 @@ func (m model) renderPalettePreview(width, height int, accentColor lipgloss.Colo
 				Padding(1, 1)
 			lines = append(lines, shortcutStyle.Render(fmt.Sprintf("Shortcut: %s", selectedItem.Shortcut)))
 		}
+
+		if len(selectedItem.Commands) > 0 {
+			lines = append(lines, renderCommandPreview(selectedItem.Commands, width)...)
+		}
 	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
@@ -1083,6 +1568,30 @@ func (m model) renderPalettePreview(width, height int, accentColor lipgloss.Colo
 	return panel.Render(content)
 }
 
+// renderCommandPreview lists the shell commands a palette item will run so
+// they can be audited (and copied with ctrl+y) before execution.
+func renderCommandPreview(commands []string, width int) []string {
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("245")).
+		Bold(true).
+		Padding(1, 1, 0, 1)
+	cmdStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("235")).
+		Padding(0, 1).
+		MarginLeft(1).
+		Width(width - 4)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(subtle).
+		Italic(true).
+		Padding(1, 1, 0, 1)
+
+	lines := []string{labelStyle.Render("Runs")}
+	for _, c := range commands {
+		lines = append(lines, cmdStyle.Render("$ "+highlightShellCommand(c)))
+	}
+	return append(lines, hintStyle.Render("ctrl+y to copy"))
+}
+
 func (m model) renderMCPToolPreview(tool *mcp.Tool, width int) []string {
 	var lines []string
 
@@ -1163,9 +1672,7 @@ func (m model) renderMCPToolPreview(tool *mcp.Tool, width int) []string {
 					Padding(0, 1).
 					MarginLeft(4).
 					Width(width - 8)
-				if len(desc) > 80 {
-					desc = desc[:77] + "..."
-				}
+				desc = truncate(desc, 80)
 				lines = append(lines, descStyle.Render(desc))
 			}
 
@@ -1214,11 +1721,30 @@ type aiPrefilledParamsMsg struct {
 	params map[string]interface{}
 }
 
-func (m *model) executeMCPToolWithAIAgent(pt *mcpPendingTool) tea.Cmd {
-	return func() tea.Msg {
+func (m *model) executeMCPToolWithAIAgent(parent context.Context, pt *mcpPendingTool) tea.Cmd {
+	return func() (msg tea.Msg) {
+		// A cancelled call has already returned the palette to search
+		defer func() {
+			if errors.Is(parent.Err(), context.Canceled) {
+				msg = nil
+			}
+		}()
 		time.Sleep(100 * time.Millisecond)
 
-		apiKey := m.config.AI.OpenAIAPIKey
+		if m.config.Policy.AIDisabled {
+			return staticOutputMsg{
+				title:  "AI Agent",
+				output: config.ErrAIDisabled.Error(),
+			}
+		}
+		if m.offline {
+			return staticOutputMsg{
+				title:  "AI Agent",
+				output: errAIOffline.Error(),
+			}
+		}
+
+		apiKey := m.config.AI.OpenAIKey()
 		if apiKey == "" {
 			apiKey = os.Getenv("OPENAI_API_KEY")
 		}
@@ -1226,12 +1752,12 @@ func (m *model) executeMCPToolWithAIAgent(pt *mcpPendingTool) tea.Cmd {
 		if apiKey == "" {
 			return aiAgentResultMsg{
 				title:  "🤖 AI Agent Not Available",
-				output: "OpenAI API key not configured.\n\nAdd it to `~/.config/skitz/config.yaml`:\n\n```yaml\nai:\n  openai_api_key: \"sk-proj-...\"\n```\n\nOr set the OPENAI_API_KEY environment variable.\n\nTry entering parameters manually by pressing Enter on the tool.",
+				output: "OpenAI API key not configured.\n\nAdd an OpenAI provider via **Actions > Configure Providers**\n\nOr set the OPENAI_API_KEY environment variable.\n\nTry entering parameters manually by pressing Enter on the tool.",
 				err:    fmt.Errorf("no API key"),
 			}
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+		ctx, cancel := context.WithTimeout(parent, 120*time.Second)
 		defer cancel()
 
 		prompt := fmt.Sprintf(`You are helping execute an MCP tool. Based on the user's request, determine the appropriate parameter values.
@@ -0,0 +1,302 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Tab, or space before anything is typed, marks the highlighted palette
+// item. With items marked, enter offers actions on all of them instead of
+// running the highlighted one: run them one after another, add them to
+// favorites or export them as markdown. Marks are kept while the query
+// changes, so the results of several searches can be combined.
+
+// bulkCategory is the category of the bulk action items
+const bulkCategory = "bulk"
+
+// toggleMark marks item, or unmarks it when marked
+func (m *model) toggleMark(item PaletteItem) {
+	if item.ID == "" || item.Category == bulkCategory {
+		return
+	}
+	if i := m.palette.markIndex(item.ID); i >= 0 {
+		m.palette.Marked = slices.Delete(m.palette.Marked, i, i+1)
+		return
+	}
+	m.palette.Marked = append(m.palette.Marked, item)
+}
+
+// markIndex returns the position of the item with id among the marked
+// items, or -1
+func (p Palette) markIndex(id string) int {
+	return slices.IndexFunc(p.Marked, func(it PaletteItem) bool { return it.ID == id })
+}
+
+// wantsBulkAction reports whether enter should offer the bulk actions:
+// items are marked and the actions aren't shown yet
+func (p Palette) wantsBulkAction() bool {
+	return len(p.Marked) > 0 && (len(p.Items) == 0 || p.Items[0].Category != bulkCategory)
+}
+
+// showBulkActions replaces the palette list with the actions on the
+// marked items
+func (m *model) showBulkActions() {
+	n := len(m.palette.Marked)
+	items := []PaletteItem{
+		{
+			ID:       "bulk:run",
+			Icon:     "▶",
+			Title:    fmt.Sprintf("Run %d in Order", n),
+			Subtitle: "Run the commands one after another, stopping at the first that fails, or call the MCP tools",
+			Category: bulkCategory,
+			Handler: func(m *model) tea.Cmd {
+				return m.runMarked()
+			},
+		},
+		{
+			ID:       "bulk:favorite",
+			Icon:     "⭐",
+			Title:    fmt.Sprintf("Add %d to Favorites", n),
+			Subtitle: "Favorite the commands and pin the MCP tools",
+			Category: bulkCategory,
+			Handler: func(m *model) tea.Cmd {
+				return m.favoriteMarked()
+			},
+		},
+		{
+			ID:       "bulk:export",
+			Icon:     "💾",
+			Title:    fmt.Sprintf("Export %d as Markdown", n),
+			Subtitle: "Save the items and their commands to a file",
+			Category: bulkCategory,
+			Handler: func(m *model) tea.Cmd {
+				return m.startMarkedExport()
+			},
+		},
+		{
+			ID:       "bulk:clear",
+			Icon:     "✕",
+			Title:    "Clear Marks",
+			Subtitle: "Unmark every item",
+			Category: bulkCategory,
+			Handler: func(m *model) tea.Cmd {
+				m.palette.Marked = nil
+				m.openPalette()
+				return nil
+			},
+		},
+	}
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
+}
+
+// runnableCommand reports whether item runs a shell command that can be
+// run with others: a resource command or a history entry
+func runnableCommand(item PaletteItem) bool {
+	return len(item.Commands) > 0 && (item.Command != nil || item.Category == "history")
+}
+
+// runMarked runs the marked commands, or calls the marked MCP tools
+func (m *model) runMarked() tea.Cmd {
+	var commands, tools []PaletteItem
+	for _, item := range m.palette.Marked {
+		switch {
+		case item.MCPTool != nil:
+			tools = append(tools, item)
+		case runnableCommand(item):
+			commands = append(commands, item)
+		default:
+			return m.showNotification("⚠️", item.Title+" can't be run with other items", "warning")
+		}
+	}
+	if len(commands) > 0 && len(tools) > 0 {
+		return m.showNotification("⚠️", "Mark either commands or MCP tools to run them together", "warning")
+	}
+	if len(tools) > 0 {
+		return m.callMarkedTools(tools)
+	}
+	return m.runMarkedCommands(commands)
+}
+
+// runMarkedCommands runs commands in the embedded terminal as one command
+// line, each once the previous succeeded. Placeholders are filled and
+// capabilities checked as for a command run on its own.
+func (m *model) runMarkedCommands(items []PaletteItem) tea.Cmd {
+	var lines []string
+	for _, item := range items {
+		line := item.Commands[0]
+		if c := item.Command; c != nil {
+			if err := checkCapabilities(c.requires, m.config.Capabilities); err != nil {
+				return m.showNotification("🔒", err.Error(), "error")
+			}
+			resName := m.resources[item.ResourceIdx].name
+			resolved, ok := m.resolveCommand(resName, *c, false)
+			if !ok {
+				return nil
+			}
+			line = resolved
+			if m.config.Policy.CheckCommand(line) == nil {
+				m.cmdStats.Record(config.CommandKey(resName, c.cmd), time.Now())
+			}
+		}
+		lines = append(lines, line)
+	}
+	config.SaveCommandStats(m.cmdStats)
+
+	m.closePalette()
+	return m.withSecrets(strings.Join(lines, " && "), func(m *model, command string, env []string) tea.Cmd {
+		return m.runCommand(CommandSpec{
+			Command: wrapForTarget(command, m.execTarget(), envNames(env)...),
+			Mode:    CommandEmbedded,
+			Env:     env,
+		})
+	})
+}
+
+// bulkToolCall is a marked MCP tool with its arguments, or why it can't
+// be called
+type bulkToolCall struct {
+	serverURL string
+	name      string
+	args      map[string]any
+	err       error
+}
+
+// callMarkedTools calls tools one after another with their preset
+// arguments and shows their results together. A tool with required
+// parameters that aren't preset is skipped.
+func (m *model) callMarkedTools(items []PaletteItem) tea.Cmd {
+	if m.offline {
+		return m.showNotification("✈", errMCPOffline.Error(), "warning")
+	}
+	calls := make([]bulkToolCall, 0, len(items))
+	for _, item := range items {
+		tool := *item.MCPTool
+		call := bulkToolCall{serverURL: item.MCPServerURL, name: tool.Name}
+		var missing []string
+		for _, name := range tool.InputSchema.Required {
+			if item.MCPArgs[name] == "" {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			call.err = fmt.Errorf("needs %s: run it on its own to fill them in", strings.Join(missing, ", "))
+		} else {
+			call.args, call.err = mcpToolArgs(tool, item.MCPArgs)
+		}
+		calls = append(calls, call)
+	}
+
+	ctx, spin := m.startPaletteExecution(fmt.Sprintf("Calling %d tools...", len(calls)))
+	return tea.Batch(spin, func() tea.Msg {
+		var b strings.Builder
+		var records []config.MCPCall
+		failed := false
+		for _, call := range calls {
+			fmt.Fprintf(&b, "## %s\n\n", call.name)
+			if call.err != nil {
+				failed = true
+				fmt.Fprintf(&b, "Error: %v\n\n", call.err)
+				continue
+			}
+			res, ok := executeMCPToolWithArgs(ctx, call.serverURL, call.name, call.args)().(paletteResultMsg)
+			if !ok {
+				// Cancelled
+				return nil
+			}
+			failed = failed || res.failed
+			records = append(records, res.calls...)
+			b.WriteString(strings.TrimSpace(res.output) + "\n\n")
+		}
+		return paletteResultMsg{
+			title:  fmt.Sprintf("%d tools", len(calls)),
+			output: strings.TrimSpace(b.String()),
+			failed: failed,
+			calls:  records,
+		}
+	})
+}
+
+// favoriteMarked favorites the marked commands and pins the marked MCP
+// tools
+func (m *model) favoriteMarked() tea.Cmd {
+	added, skipped := 0, 0
+	for _, item := range m.palette.Marked {
+		switch {
+		case item.MCPTool != nil && item.Category != "alias" && item.Category != "preset" && item.Category != mcpHistoryCategory:
+			ref := mcpToolRef(item.MCPServer, item.MCPTool.Name)
+			if !slices.Contains(m.config.MCP.Favorites, ref) {
+				m.config.MCP.Favorites = append(m.config.MCP.Favorites, ref)
+				added++
+			}
+		case runnableCommand(item):
+			cmdText := item.Commands[0]
+			if !m.favorites[cmdText] {
+				m.favorites[cmdText] = true
+				config.SetFavorite(&m.config, cmdText, true, time.Now())
+				added++
+			}
+		default:
+			skipped++
+		}
+	}
+	m.closePalette()
+	if err := config.Save(m.config); err != nil {
+		return m.showNotification("⚠️", "Failed to save favorites: "+err.Error(), "error")
+	}
+	msg := fmt.Sprintf("Added %d to favorites", added)
+	if skipped > 0 {
+		msg += fmt.Sprintf(", skipped %d that can't be favorites", skipped)
+	}
+	return m.showNotification("⭐", msg, "success")
+}
+
+// startMarkedExport asks where to save the marked items as markdown
+func (m *model) startMarkedExport() tea.Cmd {
+	items := slices.Clone(m.palette.Marked)
+	params := []config.ActionParam{
+		{Name: "path", Title: "File", Default: "palette-items.md", Required: true},
+	}
+	return m.collectActionParams("Export as Markdown", "💾", params, func(m *model, values map[string]string) tea.Cmd {
+		path := expandHome(values["path"])
+		if err := os.WriteFile(path, []byte(markedMarkdown(items)), 0644); err != nil {
+			slog.Error("failed to export palette items", "path", path, "error", err)
+			return m.showNotification("✗", "Export failed: "+err.Error(), "error")
+		}
+		return m.showNotification("💾", fmt.Sprintf("Exported %d items to %s", len(items), path), "success")
+	})
+}
+
+// markedMarkdown returns items as markdown: a heading per item, with its
+// commands, or its MCP tool and arguments
+func markedMarkdown(items []PaletteItem) string {
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		var b strings.Builder
+		fmt.Fprintf(&b, "## %s\n\n", item.Title)
+		if item.Subtitle != "" {
+			fmt.Fprintf(&b, "%s\n\n", item.Subtitle)
+		}
+		switch {
+		case len(item.Commands) > 0:
+			b.WriteString(codeBlock("sh", strings.Join(item.Commands, "\n")) + "\n")
+		case item.MCPTool != nil:
+			fmt.Fprintf(&b, "MCP tool `%s` on %s\n", item.MCPTool.Name, item.MCPServer)
+			if len(item.MCPArgs) > 0 {
+				fmt.Fprintf(&b, "\nArguments: `%s`\n", formatPresetArgs(item.MCPArgs))
+			}
+		}
+		parts = append(parts, b.String())
+	}
+	return strings.Join(parts, "\n")
+}
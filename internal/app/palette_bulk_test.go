@@ -0,0 +1,74 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestPaletteBulkActions(t *testing.T) {
+	oldConfigDir := config.ConfigDir
+	config.ConfigDir = t.TempDir()
+	defer func() { config.ConfigDir = oldConfigDir }()
+
+	m := model{config: config.CreateDefault("http://localhost:8001/mcp/"), favorites: map[string]bool{}}
+	c := command{cmd: "kubectl get pods", raw: "kubectl get pods"}
+	items := []PaletteItem{
+		{ID: "cmd:kubectl:0:1", Title: "kubectl get pods", Category: "command", Commands: []string{c.cmd}, Command: &c},
+		{ID: "history:0", Title: "make test", Category: "history", Commands: []string{"make test"}},
+		{ID: "mcp:dd:logs", Title: "logs", Category: "mcp", MCPTool: &mcp.Tool{Name: "logs"}, MCPServer: "dd"},
+	}
+	m.palette.State = PaletteStateSearching
+	m.palette.Items = items
+	m.palette.Filtered = items
+
+	press := func(k tea.KeyMsg) {
+		m.handlePaletteKeys(k)
+	}
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+	press(tab)
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	press(tab)
+	if len(m.palette.Marked) != 3 || m.palette.Query != "" {
+		t.Fatalf("marked %d items with query %q, want 3 and none", len(m.palette.Marked), m.palette.Query)
+	}
+	m.palette.Cursor = 1
+	press(tab)
+	if len(m.palette.Marked) != 2 || m.palette.markIndex("history:0") >= 0 {
+		t.Fatalf("tab didn't unmark the history entry: %+v", m.palette.Marked)
+	}
+
+	// Space types once a query is started
+	m.palette.Query = "kube"
+	press(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if m.palette.Query != "kube " || len(m.palette.Marked) != 2 {
+		t.Errorf("space marked while typing: query %q", m.palette.Query)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.palette.Items) != 4 || m.palette.Items[0].Category != bulkCategory {
+		t.Fatalf("enter didn't offer bulk actions: %+v", m.palette.Items)
+	}
+
+	m.runMarked()
+	if n := len(m.notifications); n != 1 || !strings.Contains(m.notifications[0].Message, "either commands or MCP tools") {
+		t.Errorf("mixed run notifications = %+v", m.notifications)
+	}
+
+	md := markedMarkdown(m.palette.Marked)
+	if !strings.Contains(md, "## kubectl get pods\n\n```sh\nkubectl get pods\n```") || !strings.Contains(md, "MCP tool `logs` on dd") {
+		t.Errorf("markedMarkdown() =\n%s", md)
+	}
+
+	m.favoriteMarked()
+	if !m.favorites["kubectl get pods"] || len(m.config.MCP.Favorites) != 1 || m.config.MCP.Favorites[0] != "dd.logs" {
+		t.Errorf("favorites = %v, MCP favorites = %v", m.favorites, m.config.MCP.Favorites)
+	}
+	if m.palette.State != PaletteStateIdle || len(m.palette.Marked) != 0 {
+		t.Errorf("palette still open with %d marked", len(m.palette.Marked))
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"strings"
+)
+
+// Servers with hundreds of MCP tools fill the palette with thousands of
+// items, and MCP has no request for searching a server's tools, so the
+// palette filters them itself. Scanning every item on each keystroke is
+// replaced by a trigram index built when the list changes: a query is only
+// compared with the items that have the rarest of its trigrams.
+
+// paletteIndex is a trigram index over the text palette items are
+// matched on
+type paletteIndex struct {
+	items []PaletteItem
+	text  []string           // paletteItemText of each item
+	grams map[string][]int32 // trigram to the items whose text has it, in order
+}
+
+// newPaletteIndex indexes items
+func newPaletteIndex(items []PaletteItem) *paletteIndex {
+	idx := &paletteIndex{
+		items: items,
+		text:  make([]string, len(items)),
+		grams: make(map[string][]int32),
+	}
+	for i, item := range items {
+		text := paletteItemText(item)
+		idx.text[i] = text
+		for j := 0; j+3 <= len(text); j++ {
+			gram := text[j : j+3]
+			postings := idx.grams[gram]
+			if n := len(postings); n > 0 && postings[n-1] == int32(i) {
+				continue
+			}
+			idx.grams[gram] = append(postings, int32(i))
+		}
+	}
+	return idx
+}
+
+// covers reports whether idx was built for items
+func (idx *paletteIndex) covers(items []PaletteItem) bool {
+	if len(idx.items) != len(items) {
+		return false
+	}
+	return len(items) == 0 || &idx.items[0] == &items[0]
+}
+
+// candidates returns the items whose text may contain text: those with its
+// rarest trigram, or every item when text is too short to have one
+func (idx *paletteIndex) candidates(text string) []int32 {
+	if len(text) < 3 {
+		all := make([]int32, len(idx.items))
+		for i := range all {
+			all[i] = int32(i)
+		}
+		return all
+	}
+	var rarest []int32
+	for j := 0; j+3 <= len(text); j++ {
+		postings, ok := idx.grams[text[j:j+3]]
+		if !ok {
+			return nil
+		}
+		if rarest == nil || len(postings) < len(rarest) {
+			rarest = postings
+		}
+	}
+	return rarest
+}
+
+// filter returns the items matching query, like filterPaletteItems
+func (idx *paletteIndex) filter(query string) []PaletteItem {
+	tags, text := parsePaletteQuery(query)
+	var filtered []PaletteItem
+	for _, i := range idx.candidates(text) {
+		item := idx.items[i]
+		if paletteItemAllowed(item, query, tags) && strings.Contains(idx.text[i], text) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// paletteIndex returns the index of the palette's items, building it when
+// the items changed
+func (m *model) paletteIndex() *paletteIndex {
+	if idx := m.palette.index; idx != nil && idx.covers(m.palette.Items) {
+		return idx
+	}
+	m.palette.index = newPaletteIndex(m.palette.Items)
+	return m.palette.index
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPaletteIndex(t *testing.T) {
+	var items []PaletteItem
+	for i := range 1500 {
+		tool := mcp.Tool{Name: fmt.Sprintf("tool_%d", i), Description: fmt.Sprintf("Lists the widgets of project %d, with their owners and status", i)}
+		items = append(items, PaletteItem{ID: "mcp:" + tool.Name, Title: tool.Name, Subtitle: truncate(tool.Description, 20), Category: "mcp", MCPTool: &tool})
+	}
+	items = append(items,
+		PaletteItem{ID: "action:doctor", Title: "Run Diagnostics", Category: "action"},
+		PaletteItem{ID: "cmd:k8s", Title: "kubectl get pods", Category: "command", Tags: []string{"k8s"}},
+	)
+
+	idx := newPaletteIndex(items)
+	ids := func(items []PaletteItem) []string {
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+	for _, query := range []string{"", "t", "to", "tool_14", "TOOL_149", "owners", "project 1499,", "diag", "#k8s", "#k8s pods", "nothing like it", "mcp"} {
+		want := ids(filterPaletteItems(items, query))
+		if got := ids(idx.filter(query)); !slices.Equal(got, want) {
+			t.Errorf("filter(%q) = %d items, want %d", query, len(got), len(want))
+		}
+	}
+
+	// The description is matched beyond the truncated subtitle
+	if got := ids(idx.filter("1499, with their owners")); len(got) != 1 || got[0] != "mcp:tool_1499" {
+		t.Errorf("filter(description) = %v, want tool_1499", got)
+	}
+
+	m := model{}
+	m.palette.Items = items
+	first := m.paletteIndex()
+	if m.paletteIndex() != first {
+		t.Error("index rebuilt for the same items")
+	}
+	m.palette.Items = items[:10]
+	if m.paletteIndex() == first || len(m.filterPalette("tool")) != 10 {
+		t.Error("index not rebuilt for new items")
+	}
+}
+
+func TestFilterPaletteTags(t *testing.T) {
+	items := []PaletteItem{
+		{ID: "action:doctor", Title: "Run Diagnostics", Category: "action"},
+		{ID: "cmd:pods", Title: "kubectl get pods", Category: "command", Tags: []string{"k8s", "safe"}},
+		{ID: "cmd:delete", Title: "kubectl delete pod", Category: "command", Tags: []string{"K8s", "dangerous"}},
+	}
+	ids := func(items []PaletteItem) []string {
+		var ids []string
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"action:doctor"}},
+		{"kubectl", []string{"cmd:pods", "cmd:delete"}},
+		{"#k8s", []string{"cmd:pods", "cmd:delete"}},
+		{"#k8s #SAFE", []string{"cmd:pods"}},
+		{"#k8s delete", []string{"cmd:delete"}},
+		{"#prod", nil},
+		{"#", nil},
+	}
+	for _, tt := range tests {
+		if got := ids(filterPaletteItems(items, tt.query)); !slices.Equal(got, tt.want) {
+			t.Errorf("filterPaletteItems(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
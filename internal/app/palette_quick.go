@@ -0,0 +1,123 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/calc"
+)
+
+// The palette doubles as a launcher. A query starting with > runs the rest
+// as a shell one-liner, one starting with @ lists only the resources to
+// jump to, and arithmetic or a unit conversion shows its result above the
+// matching items, ready to copy.
+
+const (
+	shellPrefix    = ">"
+	resourcePrefix = "@"
+)
+
+// filterPalette returns the palette's items for query
+func (m *model) filterPalette(query string) []PaletteItem {
+	if rest, ok := strings.CutPrefix(query, shellPrefix); ok {
+		return shellPaletteItems(strings.TrimSpace(rest))
+	}
+	if rest, ok := strings.CutPrefix(query, resourcePrefix); ok {
+		return m.resourcePaletteItems(strings.TrimSpace(rest))
+	}
+	items := m.paletteIndex().filter(query)
+	if item, ok := calcPaletteItem(query); ok {
+		items = append([]PaletteItem{item}, items...)
+	}
+	return items
+}
+
+// shellPaletteItems returns the item that runs command
+func shellPaletteItems(command string) []PaletteItem {
+	if command == "" {
+		return nil
+	}
+	return []PaletteItem{{
+		ID:       "quick:shell",
+		Icon:     "$",
+		Title:    command,
+		Subtitle: "Run in a shell",
+		Category: "quick",
+		Commands: []string{command},
+		Handler: func(m *model) tea.Cmd {
+			m.closePalette()
+			mode := CommandEmbedded
+			if isInteractiveCommand(command) {
+				mode = CommandInteractive
+			}
+			return m.runCommand(CommandSpec{Command: command, Mode: mode})
+		},
+	}}
+}
+
+// resourcePaletteItems returns an item per resource whose name or
+// description contains query
+func (m *model) resourcePaletteItems(query string) []PaletteItem {
+	query = strings.ToLower(query)
+	var items []PaletteItem
+	for i, res := range m.resources {
+		if !strings.Contains(strings.ToLower(res.name), query) && !strings.Contains(strings.ToLower(res.description), query) {
+			continue
+		}
+		items = append(items, PaletteItem{
+			ID:          "quick:resource:" + res.name,
+			Icon:        "→",
+			Title:       res.name,
+			Subtitle:    truncate(res.description, 50),
+			Category:    "quick",
+			ResourceIdx: i,
+			Handler: func(m *model) tea.Cmd {
+				m.closePalette()
+				m.openResource(i)
+				return nil
+			},
+		})
+	}
+	return items
+}
+
+// calcPaletteItem returns the result of query when it is arithmetic or a
+// unit conversion. Selecting it copies the result.
+func calcPaletteItem(query string) (PaletteItem, bool) {
+	var result string
+	if v, unit, ok := calc.Convert(query); ok {
+		result = calc.Format(v) + " " + unit
+	} else if v, err := calc.Eval(query); err == nil {
+		result = calc.Format(v)
+	} else {
+		return PaletteItem{}, false
+	}
+
+	return PaletteItem{
+		ID:       "quick:calc",
+		Icon:     "=",
+		Title:    result,
+		Subtitle: fmt.Sprintf("%s · enter to copy", strings.TrimSpace(query)),
+		Category: "quick",
+		Handler: func(m *model) tea.Cmd {
+			if err := clipboard.WriteAll(result); err != nil {
+				return m.showNotification("❌", "Failed to copy: "+err.Error(), "error")
+			}
+			m.closePalette()
+			return m.showNotification("📋", "Copied "+result, "success")
+		},
+	}, true
+}
+
+// openResource opens the resource at index i on its default section
+func (m *model) openResource(i int) {
+	m.resCursor = i
+	m.secCursor = m.resources[i].defaultSection
+	m.tagFilter = ""
+	m.cmdCursor = 0
+	m.currentView = viewDetail
+	m.initViewComponents()
+}
@@ -0,0 +1,37 @@
+package app
+
+import "testing"
+
+func TestFilterPaletteQuickActions(t *testing.T) {
+	m := model{resources: []resource{
+		{name: "docker", description: "Containers"},
+		{name: "git", description: "Version control"},
+	}}
+	m.palette.Items = []PaletteItem{{ID: "action:doctor", Title: "Run Diagnostics", Category: "action"}}
+
+	if got := m.filterPalette(">ls -la"); len(got) != 1 || got[0].Commands[0] != "ls -la" {
+		t.Errorf("filterPalette(>) = %+v, want the shell command", got)
+	}
+	if got := m.filterPalette(">"); len(got) != 0 {
+		t.Errorf("filterPalette(>) without a command = %+v, want nothing", got)
+	}
+
+	got := m.filterPalette("@vers")
+	if len(got) != 1 || got[0].Title != "git" || got[0].ResourceIdx != 1 {
+		t.Fatalf("filterPalette(@vers) = %+v, want git", got)
+	}
+	got[0].Handler(&m)
+	if m.currentView != viewDetail || m.resCursor != 1 {
+		t.Errorf("selecting @git did not open it: view %v, resource %d", m.currentView, m.resCursor)
+	}
+
+	if got := m.filterPalette("2 * (3 + 4)"); len(got) == 0 || got[0].Title != "14" {
+		t.Errorf("filterPalette(arithmetic) = %+v, want 14 first", got)
+	}
+	if got := m.filterPalette("1.5 GiB to MiB"); len(got) == 0 || got[0].Title != "1536 MiB" {
+		t.Errorf("filterPalette(conversion) = %+v, want 1536 MiB first", got)
+	}
+	if got := m.filterPalette("diag"); len(got) != 1 || got[0].ID != "action:doctor" {
+		t.Errorf("filterPalette(diag) = %+v, want only the action", got)
+	}
+}
@@ -0,0 +1,181 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// paletteResultMsg carries the output of an MCP tool run from the palette
+type paletteResultMsg struct {
+	title  string
+	output string
+	failed bool
+	calls  []config.MCPCall // the MCP tool calls made, for the call history
+}
+
+// paletteSize returns the outer width and height of the palette
+func (m model) paletteSize() (int, int) {
+	width := max(int(float64(m.width)*0.85), 100)
+	height := max(int(float64(m.height)*0.80), 30)
+	return width, height
+}
+
+// showPaletteResult switches the palette to the scrollable result pane
+func (m *model) showPaletteResult(title, output string, failed bool) {
+	m.cancelPaletteExecution()
+	m.palette.State = PaletteStateShowingResult
+	m.palette.ResultTitle = title
+	m.palette.ResultText = output
+	m.palette.ResultFailed = failed
+	m.palette.ResultTree = nil
+	if !failed {
+		m.palette.ResultTree, _ = parseJSONTree(output)
+	}
+	m.palette.ResultView = viewport.New(0, 0)
+	m.layoutPaletteResult()
+}
+
+// layoutPaletteResult sizes the result viewport to the palette and renders
+// the result into it. JSON results are drawn by ResultTree at the same
+// size.
+func (m *model) layoutPaletteResult() {
+	width, height := m.paletteSize()
+	// Border and padding take 4 columns; the header, hint and their blank
+	// lines take 4 rows plus 2 of padding
+	m.palette.ResultView.Width = width - 4
+	m.palette.ResultView.Height = max(height-6, 1)
+	if m.palette.ResultTree != nil {
+		return
+	}
+
+	rendered := m.palette.ResultText
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStylesFromJSONBytes([]byte(customStyleJSON)),
+		glamour.WithWordWrap(width-8),
+	)
+	if err == nil {
+		if out, err := r.Render(m.palette.ResultText); err == nil {
+			rendered = out
+		}
+	}
+	m.palette.ResultView.SetContent(rendered)
+}
+
+// handlePaletteResultKeys scrolls the result pane and acts on the result
+func (m *model) handlePaletteResultKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	keyStr := msg.String()
+	tree := m.palette.ResultTree
+
+	// The tree filter takes every key while it is being typed
+	if tree != nil && tree.filtering {
+		tree.handleKey(keyStr, m.palette.ResultView.Height)
+		return m, nil
+	}
+
+	switch keyStr {
+	case "esc":
+		if tree != nil && tree.filter != "" {
+			tree.filter = ""
+			tree.cursor = 0
+			return m, nil
+		}
+		m.closePalette()
+		return m, nil
+
+	case "enter":
+		if tree != nil {
+			break
+		}
+		m.closePalette()
+		return m, nil
+
+	case "q", "ctrl+k":
+		m.closePalette()
+		return m, nil
+
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "s":
+		path, err := config.SaveResult(m.palette.ResultTitle, m.palette.ResultText)
+		if err != nil {
+			return m, m.showNotification("❌", err.Error(), "error")
+		}
+		return m, m.showNotification("💾", "Saved to "+path, "success")
+
+	case "y":
+		if err := clipboard.WriteAll(m.palette.ResultText); err != nil {
+			return m, m.showNotification("❌", "Failed to copy: "+err.Error(), "error")
+		}
+		return m, m.showNotification("📋", "Result copied to clipboard", "success")
+
+	case "a":
+		return m, m.askAboutPaletteResult()
+
+	case "p":
+		if tree == nil {
+			return m, nil
+		}
+		n := tree.selected()
+		if n == nil {
+			return m, nil
+		}
+		if err := clipboard.WriteAll(n.jqPath()); err != nil {
+			return m, m.showNotification("❌", "Failed to copy: "+err.Error(), "error")
+		}
+		return m, m.showNotification("📋", "Copied "+n.jqPath(), "success")
+	}
+
+	if tree != nil {
+		tree.handleKey(keyStr, m.palette.ResultView.Height)
+		return m, nil
+	}
+
+	switch keyStr {
+	case "g", "home":
+		m.palette.ResultView.GotoTop()
+		return m, nil
+
+	case "G", "end":
+		m.palette.ResultView.GotoBottom()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.palette.ResultView, cmd = m.palette.ResultView.Update(msg)
+	return m, cmd
+}
+
+// askAboutPaletteResult closes the palette and asks the AI to summarize the
+// result in the Ask AI panel
+func (m *model) askAboutPaletteResult() tea.Cmd {
+	if cmd := m.aiUnavailable(); cmd != nil {
+		return cmd
+	}
+	if m.config.AI.DefaultProvider == "" {
+		return m.showNotification("!", "Configure a provider first", "warning")
+	}
+
+	m.askPanel = &AskPanel{
+		Active:     true,
+		Input:      "Summarize this result",
+		Subject:    m.palette.ResultTitle,
+		Attachment: m.palette.ResultText,
+	}
+	m.closePalette()
+	return m.submitAskPanel()
+}
+
+// paletteResultScroll formats how far the result pane is scrolled
+func (m model) paletteResultScroll() string {
+	if m.palette.ResultView.TotalLineCount() <= m.palette.ResultView.Height {
+		return ""
+	}
+	return fmt.Sprintf("%3.f%%", m.palette.ResultView.ScrollPercent()*100)
+}
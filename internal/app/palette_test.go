@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestPaletteSpinner(t *testing.T) {
+	m := model{width: 120, height: 40}
+	_, tick := m.startPaletteExecution("Executing tool...")
+	if m.palette.State != PaletteStateExecuting || tick == nil {
+		t.Fatalf("state = %v, tick %v", m.palette.State, tick)
+	}
+
+	// Each tick draws the next frame and asks for another while the call runs
+	first := m.palette.Spinner.View()
+	updated, cmd := m.Update(spinner.TickMsg{ID: m.palette.Spinner.ID()})
+	m = updated.(model)
+	if cmd == nil || m.palette.Spinner.View() == first {
+		t.Errorf("spinner didn't advance: %q -> %q", first, m.palette.Spinner.View())
+	}
+
+	list := ansi.Strip(m.renderPaletteList(100, 30, lipgloss.Color("99")))
+	if !strings.Contains(list, "Please wait...") || strings.Contains(list, "Please wait... 0s") {
+		t.Errorf("list before %s = %q", paletteElapsedAfter, list)
+	}
+	m.palette.StartedAt = time.Now().Add(-5 * time.Second)
+	if got := m.paletteElapsed(); got != "5s" {
+		t.Errorf("paletteElapsed() = %q, want 5s", got)
+	}
+	if list := ansi.Strip(m.renderPaletteList(100, 30, lipgloss.Color("99"))); !strings.Contains(list, "Please wait... 5s") {
+		t.Errorf("list doesn't show the elapsed time: %q", list)
+	}
+
+	// The spinner stops once the call finished
+	m.palette.State = PaletteStateSearching
+	if _, cmd := m.Update(spinner.TickMsg{ID: m.palette.Spinner.ID()}); cmd != nil {
+		t.Error("spinner still ticking after the call finished")
+	}
+}
+
+func TestCancelPaletteCall(t *testing.T) {
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyEsc}, {Type: tea.KeyCtrlC}} {
+		m := model{}
+		m.palette.Items = []PaletteItem{{ID: "action:doctor", Title: "Run Diagnostics", Category: "action"}}
+		m.palette.PendingTool = &mcpPendingTool{}
+		ctx, _ := m.startPaletteExecution("Executing tool...")
+
+		// ctrl+c cancels the call rather than quitting skitz
+		m.handlePaletteKeys(key)
+		if ctx.Err() != context.Canceled {
+			t.Errorf("%s: call context = %v, want cancelled", key, ctx.Err())
+		}
+		if m.palette.State != PaletteStateSearching || m.palette.PendingTool != nil || len(m.palette.Filtered) != 1 {
+			t.Errorf("%s: palette = %+v, want the search list", key, m.palette)
+		}
+		if msg := m.notifications[len(m.notifications)-1].Message; msg != "Cancelled" {
+			t.Errorf("%s: notification = %q", key, msg)
+		}
+	}
+
+	// A cancelled call reports nothing, so its result can't replace the list
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if msg := executeMCPToolWithArgs(ctx, "http://127.0.0.1:1/mcp", "logs", nil)(); msg != nil {
+		t.Errorf("cancelled call = %#v, want nil", msg)
+	}
+}
@@ -0,0 +1,290 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/audit"
+	"github.com/htelsiz/skitz/internal/config"
+	pluginspkg "github.com/htelsiz/skitz/internal/plugins"
+)
+
+// pluginsTab is the dashboard tab index of the Plugins tab
+const pluginsTab = 4
+
+// pluginTimeout bounds a single plugin command or panel call
+const pluginTimeout = 60 * time.Second
+
+// pluginsLoadedMsg carries the plugins discovered at startup
+type pluginsLoadedMsg struct {
+	plugins []pluginspkg.Plugin
+	errs    []error
+}
+
+// pluginPanel is a dashboard panel provided by a plugin
+type pluginPanel struct {
+	plugin pluginspkg.Plugin
+	panel  pluginspkg.Panel
+}
+
+// key identifies the panel in model.pluginPanels
+func (p pluginPanel) key() string {
+	return p.plugin.Name + "/" + p.panel.ID
+}
+
+// pluginPanelState is the last output fetched for a panel
+type pluginPanelState struct {
+	output  string
+	err     error
+	loading bool
+	fetched time.Time
+}
+
+// pluginPanelMsg carries the output of a panel call
+type pluginPanelMsg struct {
+	key    string
+	output string
+	err    error
+}
+
+// loadPluginsCmd describes the executables in the plugins directory
+func loadPluginsCmd() tea.Cmd {
+	return func() tea.Msg {
+		found, errs := pluginspkg.Discover(context.Background(), pluginspkg.Dir(config.ConfigDir))
+		return pluginsLoadedMsg{plugins: found, errs: errs}
+	}
+}
+
+// handlePluginsLoaded stores the discovered plugins; broken plugins are
+// logged and skipped
+func (m *model) handlePluginsLoaded(msg pluginsLoadedMsg) tea.Cmd {
+	for _, err := range msg.errs {
+		slog.Warn("plugin skipped", "error", err)
+	}
+	m.plugins = msg.plugins
+	m.pluginPanels = make(map[string]pluginPanelState)
+	if m.currentView == viewDashboard && m.dashboardTab == pluginsTab {
+		return m.refreshPluginPanels()
+	}
+	return nil
+}
+
+// getPluginItems returns the palette items for plugin commands
+func (m *model) getPluginItems() []PaletteItem {
+	var items []PaletteItem
+	for _, p := range m.plugins {
+		for _, c := range p.Commands {
+			icon := c.Icon
+			if icon == "" {
+				icon = "🧩"
+			}
+			subtitle := p.Name
+			if c.Description != "" {
+				subtitle += " · " + c.Description
+			}
+			items = append(items, PaletteItem{
+				ID:       "plugin:" + p.Name + ":" + c.ID,
+				Icon:     icon,
+				Title:    c.Title,
+				Subtitle: subtitle,
+				Category: "plugin",
+				Handler: func(m *model) tea.Cmd {
+					return m.startPluginCommand(p, c)
+				},
+			})
+		}
+	}
+	return items
+}
+
+// startPluginCommand runs a plugin command, asking for its parameters
+// first if it has any
+func (m *model) startPluginCommand(p pluginspkg.Plugin, c pluginspkg.Command) tea.Cmd {
+	if len(c.Params) == 0 {
+		return m.runPluginCommand(p, c, nil)
+	}
+
+	params := make([]config.ActionParam, len(c.Params))
+	for i, pp := range c.Params {
+		params[i] = config.ActionParam(pp)
+	}
+	icon := c.Icon
+	if icon == "" {
+		icon = "🧩"
+	}
+	return m.collectActionParams(c.Title, icon, params, func(m *model, values map[string]string) tea.Cmd {
+		return m.runPluginCommand(p, c, values)
+	})
+}
+
+// runPluginCommand invokes a plugin command and shows its output in the
+// palette result pane
+func (m *model) runPluginCommand(p pluginspkg.Plugin, c pluginspkg.Command, values map[string]string) tea.Cmd {
+	req := pluginspkg.Request{Kind: pluginspkg.KindCommand, ID: c.ID, Params: values}
+	if m.currentView == viewDetail {
+		if res := m.currentResource(); res != nil {
+			req.Resource = res.name
+		}
+	}
+
+	title := c.Title
+	parent, spin := m.startPaletteExecution("Running " + p.Name + "...")
+	return tea.Batch(spin, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(parent, pluginTimeout)
+		defer cancel()
+		started := time.Now()
+		resp, err := p.Invoke(ctx, req)
+		auditCall(audit.KindPlugin, req.Kind+" "+req.ID, p.Path, started, err)
+		if errors.Is(parent.Err(), context.Canceled) {
+			// A cancelled call has already returned the palette to search
+			return nil
+		}
+		if err != nil {
+			return paletteResultMsg{title: title, output: "Error: " + err.Error(), failed: true}
+		}
+		return paletteResultMsg{title: title, output: resp.Output}
+	})
+}
+
+// pluginPanelList returns every panel across plugins, in plugin order
+func (m model) pluginPanelList() []pluginPanel {
+	var panels []pluginPanel
+	for _, p := range m.plugins {
+		for _, panel := range p.Panels {
+			panels = append(panels, pluginPanel{plugin: p, panel: panel})
+		}
+	}
+	return panels
+}
+
+// refreshPluginPanels fetches every panel that isn't already loading
+func (m *model) refreshPluginPanels() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, p := range m.pluginPanelList() {
+		cmds = append(cmds, m.fetchPluginPanel(p))
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshSelectedPluginPanel fetches the panel under the cursor
+func (m *model) refreshSelectedPluginPanel() tea.Cmd {
+	panels := m.pluginPanelList()
+	if m.pluginCursor < 0 || m.pluginCursor >= len(panels) {
+		return nil
+	}
+	return m.fetchPluginPanel(panels[m.pluginCursor])
+}
+
+// fetchPluginPanel marks the panel loading and asks the plugin for its
+// content
+func (m *model) fetchPluginPanel(p pluginPanel) tea.Cmd {
+	key := p.key()
+	st := m.pluginPanels[key]
+	if st.loading {
+		return nil
+	}
+	st.loading = true
+	m.pluginPanels[key] = st
+
+	req := pluginspkg.Request{Kind: pluginspkg.KindPanel, ID: p.panel.ID, Width: max(m.width-40, 40)}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+		defer cancel()
+		started := time.Now()
+		resp, err := p.plugin.Invoke(ctx, req)
+		auditCall(audit.KindPlugin, req.Kind+" "+req.ID, p.plugin.Path, started, err)
+		return pluginPanelMsg{key: key, output: resp.Output, err: err}
+	}
+}
+
+// handlePluginPanel stores a fetched panel
+func (m *model) handlePluginPanel(msg pluginPanelMsg) {
+	m.pluginPanels[msg.key] = pluginPanelState{
+		output:  strings.TrimRight(msg.output, "\n"),
+		err:     msg.err,
+		fetched: time.Now(),
+	}
+}
+
+// renderPluginsTab renders plugin panels stacked, sharing the height
+// between them
+func (m model) renderPluginsTab(width, height int) string {
+	infoStyle := lipgloss.NewStyle().
+		Foreground(subtle).
+		Italic(true)
+
+	panels := m.pluginPanelList()
+	if len(panels) == 0 {
+		var info string
+		if len(m.plugins) == 0 {
+			info = fmt.Sprintf("No plugins installed. Put executables in %s; see the README for the protocol.",
+				pluginspkg.Dir(config.ConfigDir))
+		} else {
+			info = fmt.Sprintf("%d plugins loaded; none of them provide dashboard panels. Their commands are in the palette (ctrl+k).",
+				len(m.plugins))
+		}
+		return lipgloss.NewStyle().Padding(0, 2).Render(infoStyle.Render(info))
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("213")).
+		Bold(true)
+	metaStyle := lipgloss.NewStyle().Foreground(subtle)
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	// Each box has a border and a title line around its body
+	bodyH := max((height-2)/len(panels)-3, 1)
+	boxes := make([]string, 0, len(panels)+2)
+	for i, p := range panels {
+		st, known := m.pluginPanels[p.key()]
+
+		title := p.panel.Title
+		if title == "" {
+			title = p.panel.ID
+		}
+		header := titleStyle.Render("🧩 "+title) + metaStyle.Render("  "+p.plugin.Name)
+		switch {
+		case st.loading:
+			header += metaStyle.Render("  loading…")
+		case !st.fetched.IsZero():
+			header += metaStyle.Render("  " + formatTimeAgo(st.fetched))
+		}
+
+		var body string
+		switch {
+		case !known || (st.loading && st.fetched.IsZero()):
+			body = metaStyle.Render("…")
+		case st.err != nil:
+			body = errStyle.Render(st.err.Error())
+		default:
+			lines := strings.Split(st.output, "\n")
+			if len(lines) > bodyH {
+				lines = append(lines[:bodyH-1], metaStyle.Render(fmt.Sprintf("… %d more lines", len(lines)-bodyH+1)))
+			}
+			body = strings.Join(lines, "\n")
+		}
+
+		border := dimBorder
+		if i == m.pluginCursor {
+			border = primary
+		}
+		box := lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(border).
+			Padding(0, 1).
+			Width(width - 6).
+			MaxHeight(bodyH + 3).
+			Render(header + "\n" + body)
+		boxes = append(boxes, box)
+	}
+
+	boxes = append(boxes, infoStyle.Render("enter/s refresh panel, S refresh all"))
+	return lipgloss.NewStyle().Padding(0, 2).Render(lipgloss.JoinVertical(lipgloss.Left, boxes...))
+}
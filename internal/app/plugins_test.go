@@ -0,0 +1,59 @@
+package app
+
+import (
+	"testing"
+
+	pluginspkg "github.com/htelsiz/skitz/internal/plugins"
+)
+
+func TestPluginItemsAndPanels(t *testing.T) {
+	m := model{
+		plugins: []pluginspkg.Plugin{{
+			Path: "/bin/true",
+			Manifest: pluginspkg.Manifest{
+				Name: "k8s",
+				Commands: []pluginspkg.Command{
+					{ID: "pods", Title: "List pods"},
+					{ID: "logs", Title: "Logs", Icon: "📜", Description: "Tail logs", Params: []pluginspkg.Param{{Name: "pod", Default: "web-0"}}},
+				},
+				Panels: []pluginspkg.Panel{{ID: "nodes", Title: "Nodes"}},
+			},
+		}},
+		pluginPanels: make(map[string]pluginPanelState),
+	}
+
+	items := m.getPluginItems()
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+	if items[0].ID != "plugin:k8s:pods" || items[0].Icon != "🧩" || items[0].Subtitle != "k8s" {
+		t.Errorf("item = %+v", items[0])
+	}
+	if items[1].Icon != "📜" || items[1].Subtitle != "k8s · Tail logs" {
+		t.Errorf("item = %+v", items[1])
+	}
+
+	m.palette.State = PaletteStateSearching
+	items[1].Handler(&m)
+	if m.palette.State != PaletteStateCollectingParams || m.palette.PendingAction == nil {
+		t.Fatalf("state = %v, want parameter form", m.palette.State)
+	}
+	if got := *m.palette.PendingAction.Values["pod"]; got != "web-0" {
+		t.Errorf("default value = %q, want web-0", got)
+	}
+
+	panels := m.pluginPanelList()
+	if len(panels) != 1 || panels[0].key() != "k8s/nodes" {
+		t.Fatalf("panels = %+v", panels)
+	}
+	if cmd := m.fetchPluginPanel(panels[0]); cmd == nil {
+		t.Fatal("expected a fetch")
+	}
+	if cmd := m.fetchPluginPanel(panels[0]); cmd != nil {
+		t.Error("panel fetched again while loading")
+	}
+	m.handlePluginPanel(pluginPanelMsg{key: "k8s/nodes", output: "3 ready\n"})
+	if st := m.pluginPanels["k8s/nodes"]; st.loading || st.output != "3 ready" {
+		t.Errorf("panel state = %+v", st)
+	}
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// providersFoundMsg reports provider credentials found at startup
+type providersFoundMsg struct {
+	found []ai.Found
+}
+
+// discoverProvidersCmd looks for provider credentials on the machine when
+// no provider is configured yet
+func discoverProvidersCmd(cfg config.Config) tea.Cmd {
+	if len(cfg.AI.Providers) > 0 || cfg.Policy.AIDisabled {
+		return nil
+	}
+	return func() tea.Msg {
+		return providersFoundMsg{found: ai.Discover(nil)}
+	}
+}
+
+// handleProvidersFound offers to import the credentials found at startup
+func (m *model) handleProvidersFound(msg providersFoundMsg) tea.Cmd {
+	m.detectedProviders = msg.found
+	if len(msg.found) == 0 {
+		return nil
+	}
+	var sources []string
+	for _, f := range msg.found {
+		if !slices.Contains(sources, f.Source) {
+			sources = append(sources, f.Source)
+		}
+	}
+	return m.showNotification("◈", "Found AI credentials in "+strings.Join(sources, ", ")+": Import AI Providers in the palette adds them", "info")
+}
+
+// getProviderImportPaletteItems returns the import action while
+// credentials found at startup are waiting to be imported
+func (m *model) getProviderImportPaletteItems() []PaletteItem {
+	if len(m.detectedProviders) == 0 {
+		return nil
+	}
+	return []PaletteItem{{
+		ID:       "action:import_providers",
+		Icon:     "◈",
+		Title:    "Import AI Providers",
+		Subtitle: fmt.Sprintf("Add the %d providers found in your environment and mods, llm or aichat", len(m.detectedProviders)),
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			m.closePalette()
+			return m.startProviderImport()
+		},
+	}}
+}
+
+// startProviderImport opens the import step of the providers wizard
+func (m *model) startProviderImport() tea.Cmd {
+	if m.config.Policy.AIDisabled {
+		return m.showNotification("🔒", config.ErrAIDisabled.Error(), "warning")
+	}
+	found := ai.Discover(m.config.AI.Providers)
+	if len(found) == 0 {
+		m.detectedProviders = nil
+		return m.showNotification("◈", "No new provider credentials found", "info")
+	}
+	m.providersWizard = &ProvidersWizard{Step: 6, Action: "import", Enabled: true, Detected: found}
+	return m.buildProvidersForm()
+}
+
+// importProviders adds found to the config, making the first the default
+// when there is none
+func (m *model) importProviders(found []ai.Found) tea.Cmd {
+	if len(found) == 0 {
+		return nil
+	}
+	names := make([]string, len(found))
+	for i, f := range found {
+		m.config.AI.Providers = append(m.config.AI.Providers, f.Provider)
+		names[i] = f.Provider.Name
+	}
+	if m.config.AI.DefaultProvider == "" {
+		m.config.AI.DefaultProvider = names[0]
+	}
+	m.detectedProviders = nil
+
+	if err := config.Save(m.config); err != nil {
+		return m.showNotification("!", "Failed to save providers: "+err.Error(), "error")
+	}
+	return m.showNotification("✓", "Imported "+strings.Join(names, ", "), "success")
+}
@@ -0,0 +1,36 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestImportProviders(t *testing.T) {
+	oldConfigDir := config.ConfigDir
+	config.ConfigDir = t.TempDir()
+	defer func() { config.ConfigDir = oldConfigDir }()
+
+	found := []ai.Found{
+		{Provider: config.ProviderConfig{Name: "anthropic", ProviderType: "anthropic", APIKey: "sk-ant-x", Enabled: true}, Source: "$ANTHROPIC_API_KEY"},
+		{Provider: config.ProviderConfig{Name: "openai", ProviderType: "openai", APIKey: "sk-x", Enabled: true}, Source: "llm"},
+	}
+
+	m := model{}
+	m.handleProvidersFound(providersFoundMsg{found: found})
+	if items := m.getProviderImportPaletteItems(); len(items) != 1 {
+		t.Fatalf("%d import palette items, want 1", len(items))
+	}
+
+	m.importProviders(found)
+	if len(m.config.AI.Providers) != 2 || m.config.AI.DefaultProvider != "anthropic" {
+		t.Errorf("providers = %+v, default = %q", m.config.AI.Providers, m.config.AI.DefaultProvider)
+	}
+	if items := m.getProviderImportPaletteItems(); len(items) != 0 {
+		t.Error("import action still offered after importing")
+	}
+	if saved := config.Load(""); len(saved.AI.Providers) != 2 {
+		t.Errorf("saved %d providers, want 2", len(saved.AI.Providers))
+	}
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// providerPromptFeatures are the features whose system prompt the Advanced
+// settings step edits, in form order
+var providerPromptFeatures = []struct {
+	feature string
+	title   string
+}{
+	{config.FeatureAsk, "Ask AI system prompt"},
+	{config.FeatureGenerateCommand, "Generate command system prompt"},
+	{config.FeatureAgent, "Agent system prompt"},
+}
+
+// loadProviderSettings fills the wizard's Advanced settings from p
+func loadProviderSettings(w *ProvidersWizard, p config.ProviderConfig) {
+	w.Temperature, w.MaxTokens, w.Timeout = "", "", ""
+	if p.Temperature != nil {
+		w.Temperature = strconv.FormatFloat(*p.Temperature, 'f', -1, 64)
+	}
+	if p.MaxTokens > 0 {
+		w.MaxTokens = strconv.Itoa(p.MaxTokens)
+	}
+	if p.Timeout > 0 {
+		w.Timeout = p.Timeout.String()
+	}
+	w.SystemPrompts = make(map[string]*string)
+	for _, f := range providerPromptFeatures {
+		prompt := p.Features[f.feature].SystemPrompt
+		w.SystemPrompts[f.feature] = &prompt
+	}
+	w.Features = p.Features
+}
+
+// applyProviderSettings copies the wizard's Advanced settings to p. Feature
+// settings not shown in the wizard, such as a feature's temperature, are
+// kept.
+func applyProviderSettings(w *ProvidersWizard, p *config.ProviderConfig) {
+	if t, err := strconv.ParseFloat(strings.TrimSpace(w.Temperature), 64); err == nil {
+		p.Temperature = &t
+	}
+	p.MaxTokens, _ = strconv.Atoi(strings.TrimSpace(w.MaxTokens))
+	p.Timeout, _ = time.ParseDuration(strings.TrimSpace(w.Timeout))
+
+	features := make(map[string]config.AISettings)
+	for name, s := range w.Features {
+		features[name] = s
+	}
+	for _, f := range providerPromptFeatures {
+		s := features[f.feature]
+		if prompt := w.SystemPrompts[f.feature]; prompt != nil {
+			s.SystemPrompt = strings.TrimSpace(*prompt)
+		}
+		if s == (config.AISettings{}) {
+			delete(features, f.feature)
+		} else {
+			features[f.feature] = s
+		}
+	}
+	if len(features) == 0 {
+		features = nil
+	}
+	p.Features = features
+}
+
+// buildProviderSettingsForm is the Advanced settings step of the providers
+// wizard
+func buildProviderSettingsForm(w *ProvidersWizard) *huh.Form {
+	if w.SystemPrompts == nil {
+		loadProviderSettings(w, config.ProviderConfig{})
+	}
+
+	var prompts []huh.Field
+	for _, f := range providerPromptFeatures {
+		prompts = append(prompts, huh.NewText().
+			Title(f.title).
+			Description("Replaces the built-in prompt; leave empty to keep it").
+			Lines(3).
+			Value(w.SystemPrompts[f.feature]))
+	}
+
+	return huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title("Temperature").
+				Description("0 to 2; empty for the provider default").
+				Placeholder("0.2").
+				Value(&w.Temperature).
+				Validate(optional(func(s string) error {
+					t, err := strconv.ParseFloat(s, 64)
+					if err != nil || t < 0 || t > 2 {
+						return fmt.Errorf("enter a number from 0 to 2")
+					}
+					return nil
+				})),
+			huh.NewInput().
+				Title("Max Tokens").
+				Description("Longest response; empty for the default").
+				Placeholder("2048").
+				Value(&w.MaxTokens).
+				Validate(optional(func(s string) error {
+					if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+						return fmt.Errorf("enter a positive whole number")
+					}
+					return nil
+				})),
+			huh.NewInput().
+				Title("Timeout").
+				Description("How long to wait for a response, e.g. 2m").
+				Placeholder("60s").
+				Value(&w.Timeout).
+				Validate(optional(func(s string) error {
+					if d, err := time.ParseDuration(s); err != nil || d <= 0 {
+						return fmt.Errorf("enter a duration such as 90s or 2m")
+					}
+					return nil
+				})),
+		),
+		huh.NewGroup(prompts...),
+	).
+		WithWidth(80).
+		WithShowHelp(true).
+		WithTheme(huh.ThemeCatppuccin())
+}
+
+// optional skips validate for empty input
+func optional(validate func(string) error) func(string) error {
+	return func(s string) error {
+		if s = strings.TrimSpace(s); s == "" {
+			return nil
+		}
+		return validate(s)
+	}
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/rag"
+)
+
+// ragTimeout bounds indexing and retrieval for one request
+const ragTimeout = 2 * time.Minute
+
+// ragIndexedMsg reports a background index update
+type ragIndexedMsg struct {
+	embedded int
+	err      error
+}
+
+// ragDocuments returns the text the index covers: every static resource
+// section and the distinct commands in history
+func ragDocuments(resources []resource, history []config.HistoryEntry) []rag.Document {
+	var docs []rag.Document
+	for _, res := range resources {
+		for _, sec := range res.sections {
+			if sec.dynamic != nil || strings.TrimSpace(sec.content) == "" {
+				continue
+			}
+			docs = append(docs, rag.Document{Source: res.name + " › " + sec.title, Text: sec.content})
+		}
+	}
+
+	seen := make(map[string]bool)
+	var b strings.Builder
+	for _, h := range history {
+		if h.Command == "" || seen[h.Command] {
+			continue
+		}
+		seen[h.Command] = true
+		fmt.Fprintf(&b, "`%s` (%s)\n", h.Command, h.Tool)
+	}
+	if b.Len() > 0 {
+		docs = append(docs, rag.Document{Source: "history", Text: b.String()})
+	}
+	return docs
+}
+
+// ragEmbedder returns the embedding function and model for cfg
+func ragEmbedder(cfg config.Config) (rag.EmbedFunc, string, error) {
+	client, model, err := ai.GetEmbeddingClient(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	return func(ctx context.Context, texts []string) ([][]float32, error) {
+		return client.Embed(ctx, model, texts)
+	}, model, nil
+}
+
+// openRAGIndex loads the index for the configured embedding model. It
+// returns nil when retrieval is off or not possible.
+func openRAGIndex(cfg config.Config) *rag.Index {
+	if !cfg.AI.RAG.Enabled {
+		return nil
+	}
+	_, model, err := ragEmbedder(cfg)
+	if err != nil {
+		slog.Warn("rag index disabled", "error", err)
+		return nil
+	}
+	ix, err := rag.Open(rag.Path(config.DataDir), model)
+	if err != nil {
+		slog.Warn("rag index reset", "error", err)
+	}
+	return ix
+}
+
+// updateRAGIndexCmd brings the index up to date with resources and history
+// in the background
+func (m *model) updateRAGIndexCmd() tea.Cmd {
+	if m.ragIndex == nil || m.offline {
+		return nil
+	}
+	ix, cfg, docs := m.ragIndex, m.config, ragDocuments(m.resources, m.history)
+	return func() tea.Msg {
+		embed, _, err := ragEmbedder(cfg)
+		if err != nil {
+			return ragIndexedMsg{err: err}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), ragTimeout)
+		defer cancel()
+		n, err := ix.Update(ctx, embed, docs)
+		return ragIndexedMsg{embedded: n, err: err}
+	}
+}
+
+// handleRAGIndexed logs the result of an index update
+func (m *model) handleRAGIndexed(msg ragIndexedMsg) {
+	if msg.err != nil {
+		slog.Warn("rag index update failed", "error", msg.err)
+		return
+	}
+	if msg.embedded > 0 {
+		slog.Info("rag index updated", "embedded", msg.embedded, "chunks", m.ragIndex.Len())
+	}
+}
+
+// retrieveContext updates ix with docs and returns the chunks most relevant
+// to question as context, and their sources
+func retrieveContext(cfg config.Config, ix *rag.Index, docs []rag.Document, question string) (string, []string, error) {
+	embed, _, err := ragEmbedder(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ragTimeout)
+	defer cancel()
+	if _, err := ix.Update(ctx, embed, docs); err != nil {
+		return "", nil, err
+	}
+	results, err := ix.Search(ctx, embed, question, cfg.AI.RAG.TopK)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var parts, sources []string
+	seen := make(map[string]bool)
+	for _, r := range results {
+		parts = append(parts, "--- "+r.Source+" ---\n"+r.Text)
+		if !seen[r.Source] {
+			seen[r.Source] = true
+			sources = append(sources, r.Source)
+		}
+	}
+	return strings.Join(parts, "\n\n"), sources, nil
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// recentSwitcher is the overlay opened with ' that lists recently visited
+// resources for jumping straight back to one
+type recentSwitcher struct {
+	cursor int
+}
+
+// recordVisit puts the section shown in the detail view at the top of the
+// recent resources
+func (m *model) recordVisit(res *resource, sec *section) {
+	if len(m.recent) > 0 && m.recent[0].Resource == res.name && m.recent[0].Section == sec.title {
+		return
+	}
+	m.recent = config.AddRecent(m.recent, config.RecentVisit{Resource: res.name, Section: sec.title, Visited: time.Now()})
+	if err := config.SaveRecent(m.recent); err != nil {
+		slog.Warn("failed to save recent resources", "error", err)
+	}
+}
+
+// recentAvailable reports whether ' opens the switcher rather than going
+// to a form or an input
+func (m *model) recentAvailable() bool {
+	if m.currentView == viewDetail {
+		return m.cmdEditor == nil && m.kubeSwitcher == nil && m.tableView == nil
+	}
+	return !m.hasActiveWizard()
+}
+
+// openRecent opens the switcher on the most recent resource other than the
+// one open
+func (m *model) openRecent() tea.Cmd {
+	if len(m.recent) == 0 {
+		return m.showNotification("'", "No recent resources yet", "info")
+	}
+	m.recentSwitcher = &recentSwitcher{}
+	if res := m.currentResource(); m.currentView == viewDetail && res != nil && m.recent[0].Resource == res.name && len(m.recent) > 1 {
+		m.recentSwitcher.cursor = 1
+	}
+	return nil
+}
+
+// handleRecentKeys handles keys while the switcher is open
+func (m *model) handleRecentKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := m.recentSwitcher
+	switch {
+	case key.Matches(msg, recentKeys.Close):
+		m.recentSwitcher = nil
+	case key.Matches(msg, recentKeys.Up):
+		s.cursor = (s.cursor + len(m.recent) - 1) % len(m.recent)
+	case key.Matches(msg, recentKeys.Down):
+		s.cursor = (s.cursor + 1) % len(m.recent)
+	case key.Matches(msg, recentKeys.Jump):
+		return m, m.jumpToRecent(s.cursor)
+	case key.Matches(msg, recentKeys.Number):
+		if n := int(msg.Runes[0] - '1'); n < len(m.recent) {
+			return m, m.jumpToRecent(n)
+		}
+	}
+	return m, nil
+}
+
+// jumpToRecent closes the switcher and opens the i-th recent resource on
+// the section it was last on
+func (m *model) jumpToRecent(i int) tea.Cmd {
+	m.recentSwitcher = nil
+	visit := m.recent[i]
+	for ri, res := range m.resources {
+		if res.name != visit.Resource {
+			continue
+		}
+		m.resCursor = ri
+		m.secCursor = res.defaultSection
+		for si, sec := range res.sections {
+			if sec.title == visit.Section {
+				m.secCursor = si
+				break
+			}
+		}
+		m.tagFilter = ""
+		m.cmdCursor = 0
+		m.currentView = viewDetail
+		m.initViewComponents()
+		return nil
+	}
+	return m.showNotification("⚠", visit.Resource+" is no longer available", "warning")
+}
+
+// renderRecentSwitcher renders the switcher overlay
+func (m model) renderRecentSwitcher() string {
+	titleStyle := lipgloss.NewStyle().Foreground(primary).Bold(true)
+	numStyle := lipgloss.NewStyle().Foreground(subtle)
+	nameStyle := lipgloss.NewStyle().Foreground(white).Bold(true)
+	secStyle := lipgloss.NewStyle().Foreground(secondary)
+	timeStyle := lipgloss.NewStyle().Foreground(subtle).Italic(true)
+	selectedStyle := lipgloss.NewStyle().Background(lipgloss.Color("236"))
+
+	width := min(max(m.width/2, 40), 70)
+	lines := []string{titleStyle.Render("Recent resources"), ""}
+	for i, v := range m.recent {
+		when := formatTimeAgo(v.Visited)
+		label := fmt.Sprintf("%s  %s %s", numStyle.Render(fmt.Sprintf("%d", i+1)), nameStyle.Render(strings.ToUpper(v.Resource)), secStyle.Render(v.Section))
+		label = truncate(label, width-lipgloss.Width(when)-6)
+		gap := max(width-4-lipgloss.Width(label)-lipgloss.Width(when), 1)
+		line := label + strings.Repeat(" ", gap) + timeStyle.Render(when)
+		if i == m.recentSwitcher.cursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", numStyle.Render("' or ↓ next · enter or 1-9 open · esc close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primary).
+		Padding(0, 1).
+		Width(width).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestRecentSwitcher(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = orig }()
+
+	quote := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("'")}
+	m := &model{width: 120, height: 40, resources: []resource{
+		{name: "docker", sections: []section{{title: "Commands"}, {title: "Cleanup"}}},
+		{name: "git", sections: []section{{title: "Commands"}}},
+	}}
+
+	m.handleKeyMsg(quote)
+	if m.recentSwitcher != nil {
+		t.Fatal("switcher opened with nothing visited")
+	}
+
+	// Visit docker's second section, then git
+	m.jumpToCommand(0, 1, 0)
+	m.jumpToCommand(1, 0, 0)
+	if got := config.LoadRecent(); len(got) != 2 || got[0].Resource != "git" || got[1].Section != "Cleanup" {
+		t.Fatalf("saved visits = %+v", got)
+	}
+
+	// The switcher starts on the previous resource, so ' enter goes back
+	m.handleKeyMsg(quote)
+	if m.recentSwitcher == nil || m.recentSwitcher.cursor != 1 {
+		t.Fatalf("switcher = %+v, want the cursor on docker", m.recentSwitcher)
+	}
+	view := ansi.Strip(m.renderRecentSwitcher())
+	if !strings.Contains(view, "DOCKER Cleanup") || !strings.Contains(view, "GIT Commands") {
+		t.Errorf("switcher missing visits:\n%s", view)
+	}
+	m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.recentSwitcher != nil || m.resCursor != 0 || m.secCursor != 1 || m.currentView != viewDetail {
+		t.Errorf("jumped to resource %d section %d", m.resCursor, m.secCursor)
+	}
+
+	// Number keys jump directly
+	m.handleKeyMsg(quote)
+	m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	if m.resCursor != 1 {
+		t.Errorf("2 opened resource %d, want git", m.resCursor)
+	}
+}
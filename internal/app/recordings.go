@@ -0,0 +1,200 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aaronjanse/3mux/vterm"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/hooks"
+	"github.com/htelsiz/skitz/internal/recording"
+)
+
+// replayIdleLimit shortens pauses when replaying or exporting a recording
+const replayIdleLimit = 2 * time.Second
+
+// replayDoneMsg is sent when a replay has shown all of its output
+type replayDoneMsg struct {
+	id string
+}
+
+// startRecording starts recording an embedded run, or returns nil when the
+// recording can't be created
+func startRecording(run commandRun, width, height int) *recording.Recorder {
+	started := time.Now()
+	rec, err := recording.Start(recording.Dir(config.DataDir), recording.NewID(run.command, started), recording.Header{
+		Command:  run.command,
+		Resource: run.resource,
+		Shell:    run.shell.path,
+		Started:  started,
+		Width:    width,
+		Height:   height,
+	})
+	if err != nil {
+		slog.Warn("failed to start recording", "command", run.command, "error", err)
+		return nil
+	}
+	slog.Debug("recording session", "path", rec.Path())
+	return rec
+}
+
+// finishRecording closes rec once the terminal has read the rest of the
+// output, and drops recordings beyond keep
+func finishRecording(rec *recording.Recorder, runErr error, outputDone <-chan struct{}, keep int) {
+	select {
+	case <-outputDone:
+	case <-time.After(time.Second):
+	}
+	if err := rec.Close(hooks.ExitCode(runErr)); err != nil {
+		slog.Warn("failed to save recording", "path", rec.Path(), "error", err)
+	}
+	if err := recording.Prune(filepath.Dir(rec.Path()), keep); err != nil {
+		slog.Warn("failed to prune recordings", "error", err)
+	}
+}
+
+// showRecordings replaces the palette list with one item per recorded
+// session. Selecting one replays it.
+func (m *model) showRecordings() tea.Cmd {
+	recs, err := recording.List(recording.Dir(config.DataDir))
+	if err != nil {
+		return m.showNotification("✗", err.Error(), "error")
+	}
+	if len(recs) == 0 {
+		hint := "No recordings yet"
+		if !m.config.Recording.Enabled {
+			hint += "; set recording.enabled in config.yaml"
+		}
+		return m.showNotification("🎬", hint, "info")
+	}
+
+	var items []PaletteItem
+	for _, rec := range recs {
+		items = append(items, PaletteItem{
+			ID:       "recording:" + rec.ID,
+			Icon:     recordingIcon(rec),
+			Title:    rec.Command,
+			Subtitle: recordingSummary(rec),
+			Category: "recording",
+			Handler: func(m *model) tea.Cmd {
+				m.closePalette()
+				return m.startReplay(rec)
+			},
+		})
+	}
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
+	return nil
+}
+
+func recordingIcon(rec *recording.Recording) string {
+	switch {
+	case rec.ExitCode == nil:
+		return "○"
+	case *rec.ExitCode == 0:
+		return "✓"
+	default:
+		return "✗"
+	}
+}
+
+// recordingSummary describes a recording, e.g. "docker · Jan 2 15:04 · 12s"
+func recordingSummary(rec *recording.Recording) string {
+	summary := rec.Started.Format("Jan 2 15:04") + " · " + rec.Duration.Round(time.Second).String()
+	if rec.Resource != "" {
+		summary = rec.Resource + " · " + summary
+	}
+	if rec.ExitCode != nil && *rec.ExitCode != 0 {
+		summary += fmt.Sprintf(" · exit %d", *rec.ExitCode)
+	}
+	return summary
+}
+
+// startReplay plays rec in the terminal pane with its original timing
+func (m *model) startReplay(rec *recording.Recording) tea.Cmd {
+	m.closeTerminal()
+
+	vt := vterm.NewVTerm(&termRenderer{}, func(x, y int) {})
+	vt.Reshape(0, 0, rec.Width, rec.Height)
+	pr, pw := io.Pipe()
+	go vt.ProcessStdout(bufio.NewReader(pr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.term = EmbeddedTerm{
+		active:       true,
+		vt:           vt,
+		width:        rec.Width,
+		height:       rec.Height,
+		command:      rec.Command,
+		tool:         rec.Resource,
+		started:      time.Now(),
+		replay:       rec,
+		replayCancel: cancel,
+	}
+	slog.Info("replaying recording", "id", rec.ID)
+
+	play := func() tea.Msg {
+		defer pw.Close()
+		playEvents(ctx, rec.Events, pw, replayIdleLimit)
+		return replayDoneMsg{id: rec.ID}
+	}
+	return tea.Batch(m.waitForTermOutput(), play)
+}
+
+// playEvents writes each event's output to w at its time, with pauses no
+// longer than idleLimit, until ctx is done
+func playEvents(ctx context.Context, events []recording.Event, w io.Writer, idleLimit time.Duration) {
+	var prev float64
+	for _, e := range events {
+		delay := time.Duration((e.Time - prev) * float64(time.Second))
+		prev = e.Time
+		if delay > idleLimit {
+			delay = idleLimit
+		}
+		if delay > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+		if _, err := io.WriteString(w, e.Output); err != nil {
+			return
+		}
+	}
+}
+
+// handleReplayDone marks the replay finished unless another one started
+func (m *model) handleReplayDone(msg replayDoneMsg) {
+	if m.term.replay != nil && m.term.replay.ID == msg.id {
+		m.term.exited = true
+	}
+}
+
+// exportRecording writes rec as an asciinema .cast file in the current
+// directory
+func (m *model) exportRecording(rec *recording.Recording) tea.Cmd {
+	path := rec.ID + ".cast"
+	f, err := os.Create(path)
+	if err == nil {
+		err = recording.WriteCast(f, rec, replayIdleLimit)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		slog.Error("failed to export recording", "id", rec.ID, "error", err)
+		return m.showNotification("✗", "Export failed: "+err.Error(), "error")
+	}
+	return m.showNotification("🎬", "Exported "+path, "success")
+}
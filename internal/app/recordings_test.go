@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/recording"
+)
+
+func TestPlayEvents(t *testing.T) {
+	events := []recording.Event{{Time: 0, Output: "a"}, {Time: 60, Output: "b"}, {Time: 120, Output: "c"}}
+
+	// Minute-long pauses are cut to the idle limit
+	var out strings.Builder
+	start := time.Now()
+	playEvents(context.Background(), events, &out, time.Millisecond)
+	if out.String() != "abc" {
+		t.Errorf("output = %q, want abc", out.String())
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("replay took %v", time.Since(start))
+	}
+
+	// Closing the terminal stops the replay
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out.Reset()
+	playEvents(ctx, events, &out, time.Minute)
+	if out.String() != "a" {
+		t.Errorf("output after cancel = %q, want a", out.String())
+	}
+}
+
+func TestHandleReplayDone(t *testing.T) {
+	m := &model{}
+	m.term = EmbeddedTerm{active: true, replay: &recording.Recording{ID: "new"}}
+
+	m.handleReplayDone(replayDoneMsg{id: "old"})
+	if m.term.exited {
+		t.Error("an earlier replay finished the current one")
+	}
+	m.handleReplayDone(replayDoneMsg{id: "new"})
+	if !m.term.exited {
+		t.Error("replay not marked finished")
+	}
+}
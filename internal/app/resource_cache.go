@@ -0,0 +1,80 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// Resources are reloaded after every edit, install and command that
+// changes them. Parsed resources are kept in parsedResources and only the
+// files whose modification time or size changed are parsed again.
+
+// parsedResources caches the parsed resources of every load
+var parsedResources = resourceCache{entries: make(map[string]cachedResource)}
+
+// resourceStamp identifies the files a resource was parsed from and their
+// state when it was parsed
+type resourceStamp struct {
+	dir                    string // "" for embedded resources
+	modTime, detailModTime int64
+	size, detailSize       int64
+}
+
+// stampResource stamps the resource resName in dir. A missing detail file
+// has a zero time and size.
+func stampResource(dir, resName string) resourceStamp {
+	stamp := resourceStamp{dir: dir}
+	if info, err := os.Stat(filepath.Join(dir, resName+".md")); err == nil {
+		stamp.modTime, stamp.size = info.ModTime().UnixNano(), info.Size()
+	}
+	if info, err := os.Stat(filepath.Join(dir, resName+"-detail.md")); err == nil {
+		stamp.detailModTime, stamp.detailSize = info.ModTime().UnixNano(), info.Size()
+	}
+	return stamp
+}
+
+type cachedResource struct {
+	stamp resourceStamp
+	res   resource
+	ok    bool
+}
+
+// resourceCache maps resource names to their last parse
+type resourceCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResource
+}
+
+// load returns the cached resource resName when stamp matches, and parses
+// and caches it otherwise
+func (c *resourceCache) load(resName string, stamp resourceStamp, parse resourceLoader) (resource, bool) {
+	c.mu.Lock()
+	entry, hit := c.entries[resName]
+	c.mu.Unlock()
+
+	if !hit || entry.stamp != stamp {
+		entry.stamp = stamp
+		entry.res, entry.ok = parse()
+		c.mu.Lock()
+		c.entries[resName] = entry
+		c.mu.Unlock()
+	}
+
+	// Callers reorder and hide sections; keep the cached ones intact
+	res := entry.res
+	res.sections = slices.Clone(res.sections)
+	return res, entry.ok
+}
+
+// retain drops the resources not in names, e.g. deleted files
+func (c *resourceCache) retain(names map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range c.entries {
+		if !names[name] {
+			delete(c.entries, name)
+		}
+	}
+}
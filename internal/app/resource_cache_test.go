@@ -0,0 +1,62 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestResourceCache(t *testing.T) {
+	orig := config.ResourcesDir
+	config.ResourcesDir = t.TempDir()
+	defer func() { config.ResourcesDir = orig }()
+
+	path := filepath.Join(config.ResourcesDir, "notes.md")
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	content := func() string {
+		t.Helper()
+		for _, res := range loadAllResources() {
+			if res.name == "notes" {
+				return res.sections[0].content
+			}
+		}
+		t.Fatal("notes not loaded")
+		return ""
+	}
+
+	modTime := time.Now().Add(-time.Hour)
+	write("`echo one`\n", modTime)
+	if got := content(); !strings.Contains(got, "one") {
+		t.Fatalf("content = %q, want the first version", got)
+	}
+
+	// Same time and size: the cached parse is used
+	write("`echo two`\n", modTime)
+	if got := content(); !strings.Contains(got, "one") {
+		t.Errorf("content = %q, want the cached version", got)
+	}
+
+	write("`echo three`\n", modTime.Add(time.Second))
+	if got := content(); !strings.Contains(got, "three") {
+		t.Errorf("content = %q, want the changed file parsed again", got)
+	}
+
+	// Deleted resources leave the cache
+	os.Remove(path)
+	loadAllResources()
+	if _, ok := parsedResources.entries["notes"]; ok {
+		t.Error("deleted resource still cached")
+	}
+}
@@ -3,19 +3,43 @@ package app
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"gopkg.in/yaml.v3"
 
 	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/packs"
 	"github.com/htelsiz/skitz/internal/resources"
 )
 
 func (m *model) loadResources() {
-	m.resources = nil
+	m.setResources(loadAllResources())
+}
+
+// setResources sorts loaded resources into dashboard order and applies the
+// configured section layouts
+func (m *model) setResources(loaded []resource) {
+	m.resources = loaded
+	sortResourcesByCategory(m.resources)
+	for i := range m.resources {
+		res := &m.resources[i]
+		applySectionLayout(res, mergeSectionLayout(res.layout, m.config.Sections[res.name]))
+	}
+}
+
+// loadAllResources reads user resources first, then installed packs, then
+// embedded defaults not overridden by either. The files are parsed in
+// parallel, and only when they changed since the last load.
+func loadAllResources() []resource {
 	seen := make(map[string]bool)
 
 	descriptions := map[string]string{
@@ -33,113 +57,392 @@ func (m *model) loadResources() {
 		"go":         "Go programming language",
 		"rust":       "Rust programming language",
 		"tailscale":  "Mesh VPN & network management",
+		"kubectl":    "Kubernetes cluster management",
 	}
 
-	userDir := config.ResourcesDir
-	if files, err := os.ReadDir(userDir); err == nil {
-		for _, f := range files {
-			name := f.Name()
-			if strings.HasSuffix(name, ".md") && !strings.HasSuffix(name, "-detail.md") {
-				resName := strings.TrimSuffix(name, ".md")
-				content, _ := os.ReadFile(filepath.Join(userDir, name))
+	loaders := resourceDirLoaders(config.ResourcesDir, descriptions, seen)
 
-				res := resource{
-					name:        resName,
-					description: descriptions[resName],
-					content:     string(content),
-					embedded:    false,
-				}
-				res.sections = append(res.sections, section{
-					title:   "Commands",
-					content: string(content),
-				})
+	// Installed resource packs, after the user's own files
+	packsDir := packs.Dir(config.ResourcesDir)
+	if dirs, err := os.ReadDir(packsDir); err == nil {
+		for _, d := range dirs {
+			if d.IsDir() && !strings.HasPrefix(d.Name(), ".") {
+				loaders = append(loaders, resourceDirLoaders(filepath.Join(packsDir, d.Name()), descriptions, seen)...)
+			}
+		}
+	}
 
-				detailPath := filepath.Join(userDir, resName+"-detail.md")
-				if file, err := os.Open(detailPath); err == nil {
-					var cur *section
-					var buf strings.Builder
-					scanner := bufio.NewScanner(file)
-					for scanner.Scan() {
-						line := scanner.Text()
-						if strings.HasPrefix(line, "## ") {
-							if cur != nil {
-								cur.content = buf.String()
-								res.sections = append(res.sections, *cur)
-							}
-							cur = &section{title: strings.TrimPrefix(line, "## ")}
-							buf.Reset()
-							buf.WriteString(line + "\n")
-						} else if cur != nil {
-							buf.WriteString(line + "\n")
-						}
-					}
-					if cur != nil {
-						cur.content = buf.String()
-						res.sections = append(res.sections, *cur)
-					}
-					file.Close()
-				}
+	loaders = append(loaders, embeddedResourceLoaders(descriptions, seen)...)
+	loaded := runResourceLoaders(loaders)
+	parsedResources.retain(seen)
+	return loaded
+}
 
-				m.resources = append(m.resources, res)
-				seen[resName] = true
-			}
+// resourceLoader reads and parses one resource; false skips it
+type resourceLoader func() (resource, bool)
+
+// runResourceLoaders runs loaders in parallel and returns their resources
+// in the loaders' order
+func runResourceLoaders(loaders []resourceLoader) []resource {
+	results := make([]resource, len(loaders))
+	ok := make([]bool, len(loaders))
+	var wg sync.WaitGroup
+	for i, load := range loaders {
+		wg.Go(func() {
+			results[i], ok[i] = load()
+		})
+	}
+	wg.Wait()
+
+	var loaded []resource
+	for i, res := range results {
+		if ok[i] {
+			loaded = append(loaded, res)
 		}
 	}
+	return loaded
+}
 
+// embeddedResourceLoaders returns a loader per embedded resource not in
+// seen
+func embeddedResourceLoaders(descriptions map[string]string, seen map[string]bool) []resourceLoader {
 	entries, err := resources.Default.ReadDir(".")
-	if err == nil {
-		for _, e := range entries {
-			name := e.Name()
-			if strings.HasSuffix(name, ".md") && !strings.HasSuffix(name, "-detail.md") {
-				resName := strings.TrimSuffix(name, ".md")
-				if seen[resName] {
-					continue
-				}
+	if err != nil {
+		return nil
+	}
+	var loaders []resourceLoader
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasSuffix(name, ".md") && !strings.HasSuffix(name, "-detail.md") {
+			resName := strings.TrimSuffix(name, ".md")
+			if seen[resName] {
+				continue
+			}
+			seen[resName] = true
+			loaders = append(loaders, func() (resource, bool) {
+				return parsedResources.load(resName, resourceStamp{}, func() (resource, bool) {
+					return loadEmbeddedResource(name, resName, descriptions[resName])
+				})
+			})
+		}
+	}
+	return loaders
+}
 
-				content, readErr := resources.Default.ReadFile(name)
-				if readErr != nil {
-					continue
-				}
+// loadEmbeddedResource parses the embedded resource file name
+func loadEmbeddedResource(name, resName, description string) (resource, bool) {
+	content, readErr := resources.Default.ReadFile(name)
+	if readErr != nil {
+		return resource{}, false
+	}
 
-				res := resource{
-					name:        resName,
-					description: descriptions[resName],
-					content:     string(content),
-					embedded:    true,
+	meta, body := parseFrontMatter(string(content))
+	res := resource{
+		name:        resName,
+		description: description,
+		content:     string(content),
+		embedded:    true,
+		category:    meta.Category,
+		layout:      meta.Sections,
+		tags:        meta.Tags,
+		repo:        meta.Repo,
+	}
+	res.sections = append(res.sections, section{
+		title:   "Commands",
+		content: body,
+		file:    resName + ".md",
+		line:    strings.Count(string(content[:len(content)-len(body)]), "\n"),
+	})
+
+	detailName := resName + "-detail.md"
+	if detailContent, err := resources.Default.ReadFile(detailName); err == nil {
+		var cur *section
+		var buf strings.Builder
+		for i, line := range strings.Split(string(detailContent), "\n") {
+			if strings.HasPrefix(line, "## ") {
+				if cur != nil {
+					cur.content = buf.String()
+					res.sections = append(res.sections, *cur)
 				}
-				res.sections = append(res.sections, section{
-					title:   "Commands",
-					content: string(content),
+				cur = parseSectionHeading(line)
+				cur.file, cur.line = detailName, i
+				buf.Reset()
+				buf.WriteString(line + "\n")
+			} else if cur != nil {
+				buf.WriteString(line + "\n")
+			}
+		}
+		if cur != nil {
+			cur.content = buf.String()
+			res.sections = append(res.sections, *cur)
+		}
+	}
+
+	appendDynamicSections(&res, meta)
+	return res, true
+}
+
+// resourceDirLoaders returns a loader per resource file in dir not in seen
+func resourceDirLoaders(dir string, descriptions map[string]string, seen map[string]bool) []resourceLoader {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var loaders []resourceLoader
+	for _, f := range files {
+		name := f.Name()
+		if strings.HasSuffix(name, ".md") && !strings.HasSuffix(name, "-detail.md") {
+			resName := strings.TrimSuffix(name, ".md")
+			if seen[resName] {
+				continue
+			}
+			seen[resName] = true
+			loaders = append(loaders, func() (resource, bool) {
+				return parsedResources.load(resName, stampResource(dir, resName), func() (resource, bool) {
+					return loadResourceFile(dir, resName, descriptions[resName]), true
 				})
+			})
+		}
+	}
+	return loaders
+}
 
-				detailName := resName + "-detail.md"
-				if detailContent, err := resources.Default.ReadFile(detailName); err == nil {
-					var cur *section
-					var buf strings.Builder
-					for _, line := range strings.Split(string(detailContent), "\n") {
-						if strings.HasPrefix(line, "## ") {
-							if cur != nil {
-								cur.content = buf.String()
-								res.sections = append(res.sections, *cur)
-							}
-							cur = &section{title: strings.TrimPrefix(line, "## ")}
-							buf.Reset()
-							buf.WriteString(line + "\n")
-						} else if cur != nil {
-							buf.WriteString(line + "\n")
-						}
-					}
-					if cur != nil {
-						cur.content = buf.String()
-						res.sections = append(res.sections, *cur)
-					}
+// loadResourceFile parses the resource resName in dir
+func loadResourceFile(dir, resName, description string) resource {
+	content, _ := os.ReadFile(filepath.Join(dir, resName+".md"))
+
+	meta, body := parseFrontMatter(string(content))
+	res := resource{
+		name:        resName,
+		description: description,
+		content:     string(content),
+		embedded:    false,
+		category:    meta.Category,
+		layout:      meta.Sections,
+		tags:        meta.Tags,
+		repo:        meta.Repo,
+	}
+	res.sections = append(res.sections, section{
+		title:   "Commands",
+		content: body,
+		file:    resName + ".md",
+		line:    strings.Count(string(content[:len(content)-len(body)]), "\n"),
+	})
+
+	detailPath := filepath.Join(dir, resName+"-detail.md")
+	if file, err := os.Open(detailPath); err == nil {
+		var cur *section
+		var buf strings.Builder
+		scanner := bufio.NewScanner(file)
+		for i := 0; scanner.Scan(); i++ {
+			line := scanner.Text()
+			if strings.HasPrefix(line, "## ") {
+				if cur != nil {
+					cur.content = buf.String()
+					res.sections = append(res.sections, *cur)
 				}
+				cur = parseSectionHeading(line)
+				cur.file, cur.line = resName+"-detail.md", i
+				buf.Reset()
+				buf.WriteString(line + "\n")
+			} else if cur != nil {
+				buf.WriteString(line + "\n")
+			}
+		}
+		if cur != nil {
+			cur.content = buf.String()
+			res.sections = append(res.sections, *cur)
+		}
+		file.Close()
+	}
 
-				m.resources = append(m.resources, res)
-				seen[resName] = true
+	appendDynamicSections(&res, meta)
+	return res
+}
+
+// resourceMeta is the optional YAML front-matter of a resource file.
+type resourceMeta struct {
+	Category string               `yaml:"category"`
+	Sections config.SectionLayout `yaml:"sections"`
+	Tags     []string             `yaml:"tags"`
+	Dynamic  []dynamicSectionMeta `yaml:"dynamic"`
+	Repo     string               `yaml:"repo"`
+}
+
+// parseFrontMatter splits a leading "---" delimited YAML block off content.
+// Content without front-matter, or with front-matter that fails to parse,
+// is returned unchanged.
+func parseFrontMatter(content string) (resourceMeta, string) {
+	if !strings.HasPrefix(content, "---\n") {
+		return resourceMeta{}, content
+	}
+	end := strings.Index(content[4:], "\n---")
+	if end < 0 {
+		return resourceMeta{}, content
+	}
+	raw := content[4 : 4+end]
+	body := strings.TrimPrefix(content[4+end+len("\n---"):], "\n")
+
+	var meta resourceMeta
+	if err := yaml.Unmarshal([]byte(raw), &meta); err != nil {
+		slog.Warn("invalid resource front-matter", "error", err)
+		return resourceMeta{}, content
+	}
+	return meta, body
+}
+
+// resourceCategory returns the dashboard group of res: its front-matter
+// category, then the built-in tool category, then "Other".
+func resourceCategory(res resource) string {
+	if res.category != "" {
+		return res.category
+	}
+	if cat := toolMetadata[res.name].category; cat != "" {
+		return cat
+	}
+	return "Other"
+}
+
+// categoryRank orders dashboard groups: known categories first in
+// categoryOrder, then unknown ones alphabetically, then "Other".
+func categoryRank(category string) (int, string) {
+	for i, c := range categoryOrder {
+		if c == category {
+			return i, ""
+		}
+	}
+	if category == "Other" {
+		return len(categoryOrder) + 1, ""
+	}
+	return len(categoryOrder), category
+}
+
+// sortResourcesByCategory stably sorts resources so each category is
+// contiguous, keeping file order within a category.
+func sortResourcesByCategory(resources []resource) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		ri, ni := categoryRank(resourceCategory(resources[i]))
+		rj, nj := categoryRank(resourceCategory(resources[j]))
+		if ri != rj {
+			return ri < rj
+		}
+		return ni < nj
+	})
+}
+
+// resourceGroup is a run of m.resources sharing a category.
+type resourceGroup struct {
+	name    string
+	indices []int // indices into m.resources
+}
+
+// resourceGroups splits m.resources into category groups. It relies on
+// loadResources having sorted them by category.
+func (m model) resourceGroups() []resourceGroup {
+	var groups []resourceGroup
+	for i, res := range m.resources {
+		cat := resourceCategory(res)
+		if len(groups) == 0 || groups[len(groups)-1].name != cat {
+			groups = append(groups, resourceGroup{name: cat})
+		}
+		g := &groups[len(groups)-1]
+		g.indices = append(g.indices, i)
+	}
+	return groups
+}
+
+// focusedGroup returns the index of the group containing resCursor.
+func (m model) focusedGroup(groups []resourceGroup) int {
+	for gi, g := range groups {
+		for _, idx := range g.indices {
+			if idx == m.resCursor {
+				return gi
 			}
 		}
 	}
+	return 0
+}
+
+// resourceStops lists the resource indices the cursor can land on: every
+// card in an expanded group and the first card of a collapsed one.
+func (m model) resourceStops() []int {
+	var stops []int
+	for _, g := range m.resourceGroups() {
+		if m.collapsedGroups[g.name] {
+			stops = append(stops, g.indices[0])
+			continue
+		}
+		stops = append(stops, g.indices...)
+	}
+	return stops
+}
+
+// toggleFocusedGroup collapses or expands the group under the cursor,
+// moving the cursor to its first card.
+func (m *model) toggleFocusedGroup() {
+	groups := m.resourceGroups()
+	if len(groups) == 0 {
+		return
+	}
+	g := groups[m.focusedGroup(groups)]
+	if m.collapsedGroups == nil {
+		m.collapsedGroups = make(map[string]bool)
+	}
+	m.collapsedGroups[g.name] = !m.collapsedGroups[g.name]
+	m.resCursor = g.indices[0]
+}
+
+// mergeSectionLayout applies a user override on top of a resource's own
+// layout: Order and Default replace, Hidden is added.
+func mergeSectionLayout(base, override config.SectionLayout) config.SectionLayout {
+	merged := base
+	if len(override.Order) > 0 {
+		merged.Order = override.Order
+	}
+	if override.Default != "" {
+		merged.Default = override.Default
+	}
+	merged.Hidden = append(append([]string(nil), base.Hidden...), override.Hidden...)
+	return merged
+}
+
+// applySectionLayout reorders and hides res.sections according to layout
+// and records the default section. Hiding every section is ignored.
+func applySectionLayout(res *resource, layout config.SectionLayout) {
+	key := func(title string) string { return strings.ToLower(strings.TrimSpace(title)) }
+
+	hidden := make(map[string]bool)
+	for _, title := range layout.Hidden {
+		hidden[key(title)] = true
+	}
+
+	var visible []section
+	placed := make(map[string]bool)
+	for _, title := range layout.Order {
+		for _, sec := range res.sections {
+			k := key(sec.title)
+			if k == key(title) && !placed[k] && !hidden[k] {
+				visible = append(visible, sec)
+				placed[k] = true
+			}
+		}
+	}
+	for _, sec := range res.sections {
+		if k := key(sec.title); !placed[k] && !hidden[k] {
+			visible = append(visible, sec)
+		}
+	}
+	if len(visible) > 0 {
+		res.sections = visible
+	}
+
+	res.defaultSection = 0
+	for i, sec := range res.sections {
+		if layout.Default != "" && key(sec.title) == key(layout.Default) {
+			res.defaultSection = i
+			break
+		}
+	}
 }
 
 func (m model) currentResource() *resource {
@@ -201,32 +504,71 @@ func (m *model) editResource() tea.Cmd {
 	})
 }
 
-func (m *model) addCommandToResource(cmd string) tea.Cmd {
+// resourceFileContent returns the path of the user copy of a resource and
+// its current content, falling back to the embedded content.
+func resourceFileContent(res *resource) (string, string) {
+	filePath := filepath.Join(config.ResourcesDir, res.name+".md")
+	if res.embedded {
+		return filePath, res.content
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return filePath, res.content
+	}
+	return filePath, string(data)
+}
+
+// startAddCommandToResource appends cmd to the current resource, first
+// asking which section to use when the file has more than one heading.
+func (m *model) startAddCommandToResource(cmd string) tea.Cmd {
 	res := m.currentResource()
 	if res == nil {
 		return m.showNotification("!", "No resource selected", "error")
 	}
 
-	if err := os.MkdirAll(config.ResourcesDir, 0755); err != nil {
-		return m.showNotification("!", "Failed to create directory: "+err.Error(), "error")
+	_, content := resourceFileContent(res)
+	headings := findHeadings(content)
+	if len(headings) <= 1 || m.askPanel == nil {
+		return m.addCommandToResource(cmd, len(headings)-1)
 	}
 
-	filePath := filepath.Join(config.ResourcesDir, res.name+".md")
+	var options []huh.Option[int]
+	for i, h := range headings {
+		label := strings.Repeat("  ", h.level-1) + strings.Repeat("#", h.level) + " " + h.title
+		options = append(options, huh.NewOption(label, i))
+	}
 
-	var content string
-	if res.embedded {
-		content = res.content
-	} else {
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			content = res.content
-		} else {
-			content = string(data)
-		}
+	m.askPanel.SectionChoice = len(headings) - 1
+	m.askPanel.SectionForm = huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("Add to section").
+				Description("Choose where to append the command").
+				Options(options...).
+				Value(&m.askPanel.SectionChoice),
+		),
+	).
+		WithWidth(60).
+		WithShowHelp(true).
+		WithTheme(huh.ThemeCatppuccin())
+	return m.askPanel.SectionForm.Init()
+}
+
+// addCommandToResource writes cmd under the heading at headingIdx, or
+// under a new "## Commands" heading when headingIdx is negative.
+func (m *model) addCommandToResource(cmd string, headingIdx int) tea.Cmd {
+	res := m.currentResource()
+	if res == nil {
+		return m.showNotification("!", "No resource selected", "error")
 	}
 
-	newLine := fmt.Sprintf("\n`%s` AI generated ^run\n", cmd)
-	content += newLine
+	if err := os.MkdirAll(config.ResourcesDir, 0755); err != nil {
+		return m.showNotification("!", "Failed to create directory: "+err.Error(), "error")
+	}
+
+	filePath, content := resourceFileContent(res)
+	entry := fmt.Sprintf("`%s` AI generated ^run", cmd)
+	content = insertUnderHeading(content, headingIdx, entry)
 
 	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
 		return m.showNotification("!", "Failed to save: "+err.Error(), "error")
@@ -239,3 +581,134 @@ func (m *model) addCommandToResource(cmd string) tea.Cmd {
 
 	return m.showNotification("✓", "Command added to resource", "success")
 }
+
+// markdownHeading is an ATX heading found in a resource file.
+type markdownHeading struct {
+	level int
+	title string
+	line  int // 0-based line index
+}
+
+// findHeadings returns the headings in content, ignoring fenced code blocks
+// and the "#" comments of a leading front-matter block.
+func findHeadings(content string) []markdownHeading {
+	var headings []markdownHeading
+	inFence := false
+	lines := strings.Split(content, "\n")
+	body := 0
+	if lines[0] == "---" {
+		for j := 1; j < len(lines); j++ {
+			if strings.HasPrefix(lines[j], "---") {
+				body = j + 1
+				break
+			}
+		}
+	}
+	for i := body; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		level := len(line) - len(strings.TrimLeft(line, "#"))
+		if level > 6 || (len(line) > level && line[level] != ' ') {
+			continue
+		}
+		headings = append(headings, markdownHeading{
+			level: level,
+			title: strings.TrimSpace(line[level:]),
+			line:  i,
+		})
+	}
+	return headings
+}
+
+// insertUnderHeading inserts entry after the last non-blank line directly
+// under the heading at headingIdx (before any sub-heading), keeping the
+// blank lines and trailing newline that follow. A negative or out of range
+// headingIdx appends a new "## Commands" section.
+func insertUnderHeading(content string, headingIdx int, entry string) string {
+	headings := findHeadings(content)
+	if headingIdx < 0 || headingIdx >= len(headings) {
+		trimmed := strings.TrimRight(content, "\n")
+		if trimmed == "" {
+			return "## Commands\n\n" + entry + "\n"
+		}
+		return trimmed + "\n\n## Commands\n\n" + entry + "\n"
+	}
+
+	lines := strings.Split(content, "\n")
+	h := headings[headingIdx]
+	end := len(lines)
+	if headingIdx+1 < len(headings) {
+		end = headings[headingIdx+1].line
+	}
+
+	last := h.line
+	for j := h.line + 1; j < end; j++ {
+		if strings.TrimSpace(lines[j]) != "" {
+			last = j
+		}
+	}
+
+	block := []string{entry}
+	if last == h.line {
+		// Empty section: separate the entry from its heading like the
+		// rest of the file, and from a heading that directly follows.
+		block = []string{"", entry}
+		if last+1 < len(lines) && strings.TrimSpace(lines[last+1]) != "" {
+			block = append(block, "")
+		}
+	}
+
+	out := make([]string, 0, len(lines)+len(block))
+	out = append(out, lines[:last+1]...)
+	out = append(out, block...)
+	out = append(out, lines[last+1:]...)
+	return strings.Join(out, "\n")
+}
+
+// resourcesLoadedMsg carries the resources loaded in the background at
+// startup
+type resourcesLoadedMsg struct {
+	resources []resource
+	elapsed   time.Duration
+}
+
+// loadResourcesCmd loads the resources off the UI goroutine so the first
+// frame doesn't wait for them
+func loadResourcesCmd() tea.Cmd {
+	return func() tea.Msg {
+		started := time.Now()
+		loaded := loadAllResources()
+		return resourcesLoadedMsg{resources: loaded, elapsed: time.Since(started)}
+	}
+}
+
+// handleResourcesLoaded replaces the dashboard skeleton with the resources
+// and opens the resource named on the command line
+func (m *model) handleResourcesLoaded(msg resourcesLoadedMsg) tea.Cmd {
+	m.setResources(msg.resources)
+	m.resourcesPending = false
+	slog.Debug("loaded resources", "count", len(m.resources), "elapsed", msg.elapsed)
+
+	if m.startResource != "" {
+		for i, r := range m.resources {
+			if r.name == m.startResource {
+				m.resCursor = i
+				m.secCursor = r.defaultSection
+				m.currentView = viewDetail
+				if m.width > 0 {
+					m.initViewComponents()
+				}
+				break
+			}
+		}
+		m.startResource = ""
+	}
+	return m.startupLoadedCmd()
+}
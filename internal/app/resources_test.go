@@ -0,0 +1,150 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+const frontMatterResource = `---
+# resource settings
+category: Cloud
+---
+# kubectl
+
+## Pods
+
+` + "`kubectl get pods`" + ` list pods
+`
+
+func TestFindHeadings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []markdownHeading
+	}{
+		{"no headings", "just text\n#hashtag\n", nil},
+		{
+			"nested headings",
+			"# git\n\n## Branches\n\n### Remote\n\n```sh\n# not a heading\n```\n## Tags\n",
+			[]markdownHeading{{1, "git", 0}, {2, "Branches", 2}, {3, "Remote", 4}, {2, "Tags", 9}},
+		},
+		{
+			"front matter",
+			frontMatterResource,
+			[]markdownHeading{{1, "kubectl", 4}, {2, "Pods", 6}},
+		},
+		{"unclosed front matter", "---\n# title\n", []markdownHeading{{1, "title", 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findHeadings(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findHeadings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertUnderHeading(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		heading int
+		want    string
+	}{
+		{"no headings", "", -1, "## Commands\n\n`ls` list\n"},
+		{"no headings with text", "notes\n", 0, "notes\n\n## Commands\n\n`ls` list\n"},
+		{
+			"before a sub-heading",
+			"# git\n\n## Branches\n\n`git branch` list\n\n### Remote\n\n`git branch -r` remote\n",
+			1,
+			"# git\n\n## Branches\n\n`git branch` list\n`ls` list\n\n### Remote\n\n`git branch -r` remote\n",
+		},
+		{
+			"empty section followed by a heading",
+			"# git\n## Tags\n",
+			0,
+			"# git\n\n`ls` list\n\n## Tags\n",
+		},
+		{
+			"end of file",
+			"# git\n\n## Tags\n\n`git tag` list",
+			1,
+			"# git\n\n## Tags\n\n`git tag` list\n`ls` list",
+		},
+		{
+			"front matter",
+			frontMatterResource,
+			1,
+			frontMatterResource[:len(frontMatterResource)-1] + "\n`ls` list\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := insertUnderHeading(tt.content, tt.heading, "`ls` list"); got != tt.want {
+				t.Errorf("insertUnderHeading() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFrontMatterSections(t *testing.T) {
+	meta, body := parseFrontMatter("---\nsections:\n  order: [Commands, Core Concepts]\n  hidden: [Overview]\n  default: commands\n---\n# Docker\n")
+	want := config.SectionLayout{Order: []string{"Commands", "Core Concepts"}, Hidden: []string{"Overview"}, Default: "commands"}
+	if !reflect.DeepEqual(meta.Sections, want) || body != "# Docker\n" {
+		t.Errorf("parseFrontMatter() = %+v, %q", meta.Sections, body)
+	}
+
+	for _, content := range []string{"# Docker\n", "---\nsections: [\n---\n# Docker\n", "---\nsections:\n"} {
+		if meta, body := parseFrontMatter(content); !reflect.DeepEqual(meta, resourceMeta{}) || body != content {
+			t.Errorf("parseFrontMatter(%q) = %+v, %q, want it unchanged", content, meta, body)
+		}
+	}
+}
+
+func TestApplySectionLayout(t *testing.T) {
+	titles := func(res resource) []string {
+		var titles []string
+		for _, sec := range res.sections {
+			titles = append(titles, sec.title)
+		}
+		return titles
+	}
+	base := config.SectionLayout{Order: []string{"Core Concepts"}, Hidden: []string{"overview"}, Default: "Commands"}
+
+	tests := []struct {
+		name        string
+		layout      config.SectionLayout
+		want        []string
+		wantDefault int
+	}{
+		{"no layout", config.SectionLayout{}, []string{"Commands", "Overview", "Core Concepts", "Tips"}, 0},
+		{"front matter", base, []string{"Core Concepts", "Commands", "Tips"}, 1},
+		{
+			"config override",
+			mergeSectionLayout(base, config.SectionLayout{Order: []string{"tips", "Missing"}, Hidden: []string{"Core Concepts"}, Default: "Tips"}),
+			[]string{"Tips", "Commands"},
+			0,
+		},
+		{"everything hidden", config.SectionLayout{Hidden: []string{"Commands", "Overview", "Core Concepts", "Tips"}}, []string{"Commands", "Overview", "Core Concepts", "Tips"}, 0},
+		{"hidden default", config.SectionLayout{Hidden: []string{"Tips"}, Default: "Tips"}, []string{"Commands", "Overview", "Core Concepts"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resource{name: "docker", sections: []section{{title: "Commands"}, {title: "Overview"}, {title: "Core Concepts"}, {title: "Tips"}}}
+			applySectionLayout(&res, tt.layout)
+			if got := titles(res); !reflect.DeepEqual(got, tt.want) || res.defaultSection != tt.wantDefault {
+				t.Errorf("sections = %v, default %d; want %v, default %d", got, res.defaultSection, tt.want, tt.wantDefault)
+			}
+		})
+	}
+
+	// The override doesn't change the resource's own layout
+	if len(base.Hidden) != 1 {
+		t.Errorf("mergeSectionLayout() changed the base layout: %+v", base)
+	}
+}
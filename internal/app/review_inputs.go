@@ -0,0 +1,232 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/github"
+)
+
+// Code for a BIA review comes from a paste, a file, the working tree's git
+// diff, the files of a directory matching a glob, or a GitHub pull request.
+// Each file is reviewed on its own, in chunks of at most reviewChunkSize
+// bytes, and the feedback is grouped under a heading per file.
+
+// reviewChunkSize bounds the code sent to bia_junior_agent in one call
+const reviewChunkSize = 24 * 1024
+
+// reviewMaxFiles bounds the files a directory or diff review sends
+const reviewMaxFiles = 50
+
+// reviewSkipDirs are not walked when reviewing a directory
+var reviewSkipDirs = []string{".git", "node_modules", "vendor"}
+
+// reviewFile is the code of one file to review. file is empty for pasted
+// code.
+type reviewFile struct {
+	file string
+	code string
+}
+
+// reviewChunk is one call's worth of a file's code
+type reviewChunk struct {
+	file  string
+	part  int // 1-based
+	parts int
+	code  string
+}
+
+// chunkFiles splits each file into chunks of at most size bytes
+func chunkFiles(files []reviewFile, size int) []reviewChunk {
+	var chunks []reviewChunk
+	for _, f := range files {
+		parts := chunkCode(f.code, size)
+		for i, code := range parts {
+			chunks = append(chunks, reviewChunk{file: f.file, part: i + 1, parts: len(parts), code: code})
+		}
+	}
+	return chunks
+}
+
+// chunkLabel names a chunk in progress messages
+func chunkLabel(c reviewChunk) string {
+	name := c.file
+	if name == "" {
+		name = "code"
+	}
+	if c.parts > 1 {
+		return fmt.Sprintf("%s, part %d of %d", name, c.part, c.parts)
+	}
+	return name
+}
+
+// chunkCode splits code at line boundaries into pieces of at most size
+// bytes. A longer line is a piece of its own.
+func chunkCode(code string, size int) []string {
+	var chunks []string
+	var cur strings.Builder
+	for _, line := range strings.SplitAfter(code, "\n") {
+		if cur.Len() > 0 && cur.Len()+len(line) > size {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+		cur.WriteString(line)
+	}
+	if strings.TrimSpace(cur.String()) != "" {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
+
+// splitDiff splits a unified git diff into the diff of each file
+func splitDiff(diff string) []reviewFile {
+	var files []reviewFile
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if rest, ok := strings.CutPrefix(line, "diff --git "); ok {
+			_, name, _ := strings.Cut(strings.TrimRight(rest, "\n"), " b/")
+			files = append(files, reviewFile{file: name})
+		}
+		if len(files) > 0 {
+			files[len(files)-1].code += line
+		}
+	}
+	return files
+}
+
+// gitDiffFiles returns the working tree's staged or unstaged changes, one
+// diff per file
+func gitDiffFiles(ctx context.Context, staged bool) ([]reviewFile, error) {
+	args := []string{"diff", "--no-color", "--no-ext-diff"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("failed to run git diff: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to run git diff: %w", err)
+	}
+	files := splitDiff(string(out))
+	if len(files) == 0 {
+		if staged {
+			return nil, errors.New("no staged changes")
+		}
+		return nil, errors.New("no unstaged changes")
+	}
+	return files, nil
+}
+
+// dirFiles returns the text files under root whose names match glob,
+// skipping hidden and dependency directories
+func dirFiles(root, glob string) ([]reviewFile, error) {
+	if glob == "" {
+		glob = "*"
+	}
+	if _, err := filepath.Match(glob, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", glob, err)
+	}
+
+	var files []reviewFile
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || slices.Contains(reviewSkipDirs, d.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ok, _ := filepath.Match(glob, d.Name()); !ok || !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 || len(bytes.TrimSpace(data)) == 0 {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		files = append(files, reviewFile{file: rel, code: string(data)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", root, err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files in %s match %s", root, glob)
+	}
+	return files, nil
+}
+
+// pullRequestFiles returns the diff of the pull request at url, one diff
+// per file. Public pull requests need no token.
+func pullRequestFiles(ctx context.Context, cfg config.GitHubConfig, url string) ([]reviewFile, error) {
+	repo, number, err := github.ParsePullRequestURL(url)
+	if err != nil {
+		return nil, err
+	}
+	token, err := github.Token(ctx, cfg.Token)
+	if err != nil && !errors.Is(err, github.ErrNoToken) {
+		return nil, err
+	}
+	diff, err := github.Client{Token: token, BaseURL: cfg.APIURL}.PullRequestDiff(ctx, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s#%d: %w", repo, number, err)
+	}
+	files := splitDiff(diff)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s#%d has no changes", repo, number)
+	}
+	return files, nil
+}
+
+// checkReviewFiles refuses reviews of more than reviewMaxFiles files
+func checkReviewFiles(files []reviewFile) error {
+	if len(files) > reviewMaxFiles {
+		return fmt.Errorf("%d files to review, more than %d; narrow the pattern or review fewer changes", len(files), reviewMaxFiles)
+	}
+	return nil
+}
+
+// reviewResult is the feedback on one chunk
+type reviewResult struct {
+	chunk    reviewChunk
+	feedback string
+	err      error
+}
+
+// reviewMarkdown groups the feedback under a heading per file, with a
+// subheading per part of files reviewed in several chunks
+func reviewMarkdown(results []reviewResult) string {
+	var b strings.Builder
+	for _, r := range results {
+		if r.chunk.file != "" && r.chunk.part == 1 {
+			fmt.Fprintf(&b, "## %s\n\n", r.chunk.file)
+		}
+		if r.chunk.parts > 1 {
+			fmt.Fprintf(&b, "### Part %d of %d\n\n", r.chunk.part, r.chunk.parts)
+		}
+		if r.err != nil {
+			fmt.Fprintf(&b, "**Review failed:** %v\n\n", r.err)
+			continue
+		}
+		fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(r.feedback))
+	}
+	return b.String()
+}
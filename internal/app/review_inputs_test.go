@@ -0,0 +1,95 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestChunkCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		size int
+		want []string
+	}{
+		{name: "fits", code: "a\nb\n", size: 10, want: []string{"a\nb\n"}},
+		{name: "splits at lines", code: "aaa\nbbb\nccc\n", size: 8, want: []string{"aaa\nbbb\n", "ccc\n"}},
+		{name: "long line alone", code: "a\nbbbbbbbbbb\nc", size: 4, want: []string{"a\n", "bbbbbbbbbb\n", "c"}},
+		{name: "blank", code: "\n\n", size: 10, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkCode(tt.code, tt.size); !slices.Equal(got, tt.want) {
+				t.Errorf("chunkCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitDiff(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n+x\ndiff --git a/docs/a b.md b/docs/a b.md\n-y\n"
+	files := splitDiff(diff)
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	if files[0].file != "main.go" || files[0].code != "diff --git a/main.go b/main.go\n+x\n" {
+		t.Errorf("files[0] = %+v", files[0])
+	}
+	if files[1].file != "docs/a b.md" || !strings.HasSuffix(files[1].code, "-y\n") {
+		t.Errorf("files[1] = %+v", files[1])
+	}
+	if files := splitDiff(""); len(files) != 0 {
+		t.Errorf("splitDiff(\"\") = %+v", files)
+	}
+}
+
+func TestDirFiles(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n")
+	write("pkg/util.go", "package pkg\n")
+	write("README.md", "# readme\n")
+	write("vendor/dep/dep.go", "package dep\n")
+	write(".git/hooks/x.go", "package x\n")
+	write("bin.go", "\x00\x01")
+
+	files, err := dirFiles(root, "*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.file)
+	}
+	if want := []string{"main.go", filepath.Join("pkg", "util.go")}; !slices.Equal(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+
+	if _, err := dirFiles(root, "*.py"); err == nil {
+		t.Error("expected an error when nothing matches")
+	}
+	if _, err := dirFiles(root, "["); err == nil {
+		t.Error("expected an error for a bad pattern")
+	}
+}
+
+func TestReviewMarkdown(t *testing.T) {
+	results := []reviewResult{
+		{chunk: reviewChunk{file: "a.go", part: 1, parts: 2}, feedback: "first\n"},
+		{chunk: reviewChunk{file: "a.go", part: 2, parts: 2}, err: os.ErrDeadlineExceeded},
+		{chunk: reviewChunk{file: "b.go", part: 1, parts: 1}, feedback: "looks fine"},
+	}
+	want := "## a.go\n\n### Part 1 of 2\n\nfirst\n\n### Part 2 of 2\n\n**Review failed:** i/o timeout\n\n## b.go\n\nlooks fine\n\n"
+	if got := reviewMarkdown(results); got != want {
+		t.Errorf("reviewMarkdown() =\n%q\nwant\n%q", got, want)
+	}
+}
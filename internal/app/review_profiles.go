@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// reviewProfile is a code review the review wizard runs: the BIA Junior
+// agent over MCP, or a profile from review.profiles answered by an AI
+// provider
+type reviewProfile struct {
+	name    string
+	agent   string // recorded as the interaction's agent
+	command string // recorded in the history
+	mcp     bool   // reviews through the MCP server
+	include []string
+	exclude []string
+	review  func(ctx context.Context, code string) (string, error)
+}
+
+// biaReviewProfile reviews with the bia_junior_agent MCP tool
+func biaReviewProfile() reviewProfile {
+	return reviewProfile{
+		name:    "BIA Code Review",
+		agent:   "BIA Junior",
+		command: "bia-review",
+		mcp:     true,
+		review:  ReviewCodeWithBIA,
+	}
+}
+
+// configReviewProfile reviews with p's prompt and provider
+func configReviewProfile(cfg config.Config, p config.ReviewProfile) (reviewProfile, error) {
+	client, err := reviewClient(cfg, p.Provider)
+	if err != nil {
+		return reviewProfile{}, err
+	}
+	return reviewProfile{
+		name:    p.Name,
+		agent:   p.Name,
+		command: "review: " + p.Name,
+		include: p.Include,
+		exclude: p.Exclude,
+		review: func(_ context.Context, code string) (string, error) {
+			resp := client.Review(p.Prompt, code)
+			return resp.Content, resp.Error
+		},
+	}, nil
+}
+
+// reviewClient returns the client for the provider named name, or for the
+// default provider when name is empty
+func reviewClient(cfg config.Config, name string) (*ai.Client, error) {
+	if name == "" {
+		return ai.GetDefaultClient(cfg)
+	}
+	if cfg.Policy.AIDisabled {
+		return nil, config.ErrAIDisabled
+	}
+	for _, p := range cfg.AI.Providers {
+		if p.Name == name && p.Enabled {
+			return ai.NewClient(p), nil
+		}
+	}
+	return nil, fmt.Errorf("provider '%s' not found or disabled", name)
+}
+
+// filter returns the files the profile reviews: those whose names match
+// an include pattern, if there are any, and no exclude pattern. Pasted
+// code is always reviewed.
+func (p reviewProfile) filter(files []reviewFile) []reviewFile {
+	var kept []reviewFile
+	for _, f := range files {
+		if f.file == "" || ((len(p.include) == 0 || matchesAny(p.include, f.file)) && !matchesAny(p.exclude, f.file)) {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// matchesAny reports whether the base name of path matches one of globs
+func matchesAny(globs []string, path string) bool {
+	name := filepath.Base(path)
+	for _, g := range globs {
+		if ok, _ := filepath.Match(g, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBIAReview reports whether a connected MCP server offers the
+// bia_junior_agent tool
+func (m *model) hasBIAReview() bool {
+	for _, s := range m.mcpStatus {
+		if s.Connected && slices.Contains(s.Tools, "bia_junior_agent") {
+			return true
+		}
+	}
+	return false
+}
+
+// getReviewPaletteItems returns an action per review profile: BIA Code
+// Review when its tool is available, then the profiles in config.yaml
+func (m *model) getReviewPaletteItems() []PaletteItem {
+	var items []PaletteItem
+	if m.hasBIAReview() {
+		items = append(items, PaletteItem{
+			ID:       "action:bia_review",
+			Icon:     "🔍",
+			Title:    "BIA Code Review",
+			Subtitle: "Review pasted code, a file, a directory, the git diff or a pull request",
+			Category: "action",
+			Handler: func(m *model) tea.Cmd {
+				m.closePalette()
+				return runCodeReview(biaReviewProfile(), m.config.Integrations.GitHub)
+			},
+		})
+	}
+	if m.config.Policy.AIDisabled {
+		return items
+	}
+
+	for _, p := range m.config.Review.Profiles {
+		if p.Name == "" {
+			continue
+		}
+		provider := p.Provider
+		if provider == "" {
+			provider = m.config.AI.DefaultProvider
+		}
+		items = append(items, PaletteItem{
+			ID:       "action:review:" + p.Name,
+			Icon:     "🔍",
+			Title:    p.Name,
+			Subtitle: "Code review with " + provider,
+			Category: "action",
+			Handler: func(m *model) tea.Cmd {
+				profile, err := configReviewProfile(m.config, p)
+				if err != nil {
+					return m.showNotification("⚠", fmt.Sprintf("%s: %v", p.Name, err), "warning")
+				}
+				m.closePalette()
+				return runCodeReview(profile, m.config.Integrations.GitHub)
+			},
+		})
+	}
+	return items
+}
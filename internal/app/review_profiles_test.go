@@ -0,0 +1,68 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+	mcppkg "github.com/htelsiz/skitz/internal/mcp"
+)
+
+func TestReviewProfileFilter(t *testing.T) {
+	files := []reviewFile{{file: "db/query.sql"}, {file: "main.go"}, {file: "main_test.go"}, {}}
+	tests := []struct {
+		name    string
+		profile reviewProfile
+		want    []string
+	}{
+		{name: "no patterns", profile: reviewProfile{}, want: []string{"db/query.sql", "main.go", "main_test.go", ""}},
+		{name: "include", profile: reviewProfile{include: []string{"*.sql"}}, want: []string{"db/query.sql", ""}},
+		{name: "exclude", profile: reviewProfile{include: []string{"*.go"}, exclude: []string{"*_test.go"}}, want: []string{"main.go", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, f := range tt.profile.filter(files) {
+				got = append(got, f.file)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("filter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewPaletteItems(t *testing.T) {
+	m := model{}
+	m.config.AI = config.AIConfig{
+		DefaultProvider: "openai",
+		Providers:       []config.ProviderConfig{{Name: "openai", ProviderType: "openai", Enabled: true}},
+	}
+	m.config.Review.Profiles = []config.ReviewProfile{
+		{Name: "Security Review", Prompt: "Find vulnerabilities."},
+		{Name: "SQL Review", Provider: "missing", Include: []string{"*.sql"}},
+		{Prompt: "unnamed"},
+	}
+
+	items := m.getReviewPaletteItems()
+	if len(items) != 2 || items[0].Title != "Security Review" || items[0].Subtitle != "Code review with openai" {
+		t.Fatalf("items = %+v", items)
+	}
+	if _, err := configReviewProfile(m.config, m.config.Review.Profiles[1]); err == nil {
+		t.Error("expected an error for a missing provider")
+	}
+	profile, err := configReviewProfile(m.config, m.config.Review.Profiles[0])
+	if err != nil || profile.mcp || profile.name != "Security Review" {
+		t.Errorf("profile = %+v, err = %v", profile, err)
+	}
+
+	m.mcpStatus = []mcppkg.ServerStatus{{Connected: true, Tools: []string{"bia_junior_agent"}}}
+	if items := m.getReviewPaletteItems(); len(items) != 3 || items[0].Title != "BIA Code Review" {
+		t.Errorf("items with BIA = %+v", items)
+	}
+
+	m.config.Policy.AIDisabled = true
+	if items := m.getReviewPaletteItems(); len(items) != 1 {
+		t.Errorf("%d items with AI disabled, want only BIA", len(items))
+	}
+}
@@ -4,14 +4,20 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/aaronjanse/3mux/ecma48"
 	"github.com/aaronjanse/3mux/vterm"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/creack/pty"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/hooks"
+	"github.com/htelsiz/skitz/internal/recording"
 )
 
 // commandDoneMsg signals that command execution is complete
@@ -19,6 +25,51 @@ type commandDoneMsg struct {
 	command string
 	tool    string
 	success bool
+	output  string // captured output, for history
+
+	// hook is set for commands started with runCommand; the post-run hooks
+	// are run for it
+	hook *hooks.Event
+}
+
+// commandResult is how the last command run from skitz ended
+type commandResult struct {
+	exitCode int
+	elapsed  time.Duration
+}
+
+// label returns the result for the status bar, e.g. "✓ 0 · 1.2s"
+func (r commandResult) label() string {
+	icon := "✓"
+	if r.exitCode != 0 {
+		icon = "✗"
+	}
+	return fmt.Sprintf("%s %d · %s", icon, r.exitCode, formatDuration(r.elapsed))
+}
+
+// formatDuration rounds d for display: milliseconds under a second, tenths
+// of a second under a minute, whole seconds above
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	default:
+		return d.Round(time.Second).String()
+	}
+}
+
+// addHistory records a finished command when history is enabled
+func (m *model) addHistory(entry config.HistoryEntry) {
+	if !m.config.History.Enabled {
+		return
+	}
+	m.history = config.AddToHistory(m.history, entry, m.config.History.MaxItems)
+	// handleHistoryLoaded saves the merged history
+	if m.config.History.Persist && !m.historyPending {
+		config.SaveHistory(m.history, m.config.History.MaxItems)
+	}
 }
 
 // interactiveCmd implements tea.ExecCommand for interactive execution
@@ -29,6 +80,23 @@ type interactiveCmd struct {
 	tool       string
 	finalCmd   string
 	success    bool
+	shell      shellSpec
+	mode       CommandMode
+
+	// err and elapsed describe the finished command for post-run hooks
+	err     error
+	elapsed time.Duration
+}
+
+// hookEvent describes the finished command to post-run hooks
+func (c *interactiveCmd) hookEvent() *hooks.Event {
+	return &hooks.Event{
+		Command:  c.finalCmd,
+		Resource: c.tool,
+		Mode:     string(c.mode),
+		ExitCode: hooks.ExitCode(c.err),
+		Duration: c.elapsed,
+	}
 }
 
 func (c *interactiveCmd) Run() error {
@@ -38,11 +106,13 @@ func (c *interactiveCmd) Run() error {
 
 	c.finalCmd = finalCmd
 
-	cmd := newShellCommand(finalCmd)
+	cmd := newShellCommand(c.shell, finalCmd)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
+	started := time.Now()
 	err := cmd.Run()
+	c.err, c.elapsed = err, time.Since(started)
 
 	fmt.Println()
 	if err != nil {
@@ -85,23 +155,21 @@ func isInteractiveCommand(cmd string) bool {
 }
 
 // executeInteractive runs a command with full terminal control using tea.Exec
-func (m *model) executeInteractive(cmd command, finalCmd string) tea.Cmd {
-	toolName := ""
-	if res := m.currentResource(); res != nil {
-		toolName = res.name
-	}
-
+func (m *model) executeInteractive(run commandRun) tea.Cmd {
 	ic := &interactiveCmd{
-		cmd:        finalCmd,
+		cmd:        run.command,
 		needsInput: false,
 		inputVar:   "",
-		tool:       toolName,
+		tool:       run.resource,
+		shell:      run.shell,
+		mode:       run.mode,
 	}
 	return tea.Exec(ic, func(err error) tea.Msg {
 		return commandDoneMsg{
 			command: ic.finalCmd,
 			tool:    ic.tool,
 			success: ic.success,
+			hook:    ic.hookEvent(),
 		}
 	})
 }
@@ -114,26 +182,35 @@ type termStartMsg struct {
 	width   int
 	height  int
 	command string // The command string that was executed
+	tool    string // resource the command belongs to
+	rec     *recording.Recorder
+	run     commandRun
 }
 
-// executeEmbedded runs a command in an embedded terminal pane
-func (m *model) executeEmbedded(cmdStr string) tea.Cmd {
-	termW := m.width - 6
-	termH := 20
+// embeddedTermSize returns the size of the embedded terminal in a window
+// of width x height: the window's width less the border and padding, and
+// up to 20 rows
+func embeddedTermSize(width, height int) (int, int) {
+	termW := width - 6
+	termH := min(height-4, 20)
 	if termW < 40 {
 		termW = 40
 	}
 	if termH < 10 {
 		termH = 10
 	}
+	return termW, termH
+}
 
-	return func() tea.Msg {
-		oldLogOutput := log.Writer()
-		log.SetOutput(io.Discard)
-		defer log.SetOutput(oldLogOutput)
+// executeEmbedded runs a command in an embedded terminal pane
+func (m *model) executeEmbedded(run commandRun) tea.Cmd {
+	termW, termH := embeddedTermSize(m.width, m.height)
 
-		c := newShellCommand(cmdStr)
-		c.Env = append(os.Environ(),
+	cmdStr := run.command
+	recCfg := m.config.Recording
+	return func() tea.Msg {
+		c := newShellCommand(run.shell, cmdStr)
+		c.Env = append(c.Environ(),
 			"TERM=xterm-256color",
 			fmt.Sprintf("COLUMNS=%d", termW),
 			fmt.Sprintf("LINES=%d", termH),
@@ -151,6 +228,11 @@ func (m *model) executeEmbedded(cmdStr string) tea.Cmd {
 		vt := vterm.NewVTerm(renderer, func(x, y int) {})
 		vt.Reshape(0, 0, termW, termH)
 
+		var rec *recording.Recorder
+		if recCfg.Enabled {
+			rec = startRecording(run, termW, termH)
+		}
+
 		return termStartMsg{
 			vt:      vt,
 			pty:     ptmx,
@@ -158,6 +240,38 @@ func (m *model) executeEmbedded(cmdStr string) tea.Cmd {
 			width:   termW,
 			height:  termH,
 			command: cmdStr,
+			tool:    run.resource,
+			rec:     rec,
+			run:     run,
+		}
+	}
+}
+
+// resizeTerminal fits the embedded terminal to the window: the screen is
+// reshaped and a running command's pty gets the new size, so full-screen
+// programs redraw and new output wraps at the new width. Replays keep the
+// recorded size.
+func (m *model) resizeTerminal() {
+	if !m.term.active || m.term.vt == nil || m.term.replay != nil {
+		return
+	}
+	termW, termH := embeddedTermSize(m.width, m.height)
+	if termW == m.term.width && termH == m.term.height {
+		return
+	}
+
+	// Reshape moves rows above a shorter screen to the scrollback but
+	// doesn't add rows to a taller one
+	vt := m.term.vt
+	for len(vt.Screen) < termH {
+		vt.Screen = append(vt.Screen, make([]ecma48.StyledChar, 0, termW+1))
+	}
+	vt.Reshape(0, 0, termW, termH)
+	m.term.width, m.term.height = termW, termH
+
+	if m.term.pty != nil && !m.term.exited {
+		if err := pty.Setsize(m.term.pty, &pty.Winsize{Rows: uint16(termH), Cols: uint16(termW)}); err != nil {
+			slog.Warn("failed to resize terminal", "error", err)
 		}
 	}
 }
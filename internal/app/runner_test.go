@@ -0,0 +1,86 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aaronjanse/3mux/vterm"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/creack/pty"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/hooks"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{340400 * time.Microsecond, "340ms"},
+		{1234 * time.Millisecond, "1.2s"},
+		{35 * time.Second, "35s"},
+		{125400 * time.Millisecond, "2m5s"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestCommandResultInStatusBar(t *testing.T) {
+	m := model{width: 200}
+	m.config.History = config.HistoryConfig{Enabled: true, MaxItems: 10}
+
+	next, _ := m.Update(commandDoneMsg{
+		command: "make test",
+		tool:    "make",
+		hook:    &hooks.Event{ExitCode: 2, Duration: 1234 * time.Millisecond},
+	})
+	m = next.(model)
+
+	if bar := ansi.Strip(m.renderStatusBar()); !strings.Contains(bar, "✗ 2 · 1.2s") {
+		t.Errorf("status bar %q is missing the result", bar)
+	}
+	if len(m.history) != 1 || m.history[0].ExitCode != 2 || m.history[0].DurationMS != 1234 {
+		t.Errorf("history = %+v, want exit code 2 after 1234ms", m.history)
+	}
+}
+
+func TestResizeTerminal(t *testing.T) {
+	ptmx, tty, err := pty.Open()
+	if err != nil {
+		t.Skip("no pty:", err)
+	}
+	defer ptmx.Close()
+	defer tty.Close()
+
+	w, h := embeddedTermSize(120, 40)
+	vt := vterm.NewVTerm(&termRenderer{}, func(x, y int) {})
+	vt.Reshape(0, 0, w, h)
+	m := model{width: 120, height: 40, term: EmbeddedTerm{active: true, vt: vt, pty: ptmx, width: w, height: h}}
+
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	m = next.(model)
+	if m.term.width != 74 || m.term.height != 16 || len(vt.Screen) != 16 {
+		t.Errorf("terminal = %dx%d with %d rows, want 74x16", m.term.width, m.term.height, len(vt.Screen))
+	}
+	if rows, cols, err := pty.Getsize(tty); err != nil || rows != 16 || cols != 74 {
+		t.Errorf("pty size = %dx%d (%v), want 74x16", cols, rows, err)
+	}
+
+	// Growing adds rows to the screen
+	next, _ = m.Update(tea.WindowSizeMsg{Width: 200, Height: 60})
+	m = next.(model)
+	if m.term.width != 194 || m.term.height != 20 || len(vt.Screen) != 20 {
+		t.Errorf("terminal = %dx%d with %d rows, want 194x20", m.term.width, m.term.height, len(vt.Screen))
+	}
+	for i, row := range vt.Screen {
+		if len(row) < 194 {
+			t.Fatalf("row %d has %d columns, want 194", i, len(row))
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/secrets"
+)
+
+// secretsRun continues with a command whose {{secret:NAME}} placeholders
+// were replaced with references to the environment entries env passes
+type secretsRun func(m *model, command string, env []string) tea.Cmd
+
+// secretsResolvedMsg carries the secrets a command refers to, read in the
+// background
+type secretsResolvedMsg struct {
+	command string
+	env     []string
+	values  []string
+	err     error
+	run     secretsRun
+}
+
+// withSecrets reads the secrets command's placeholders refer to and calls
+// run with the placeholders replaced. Reading a secret can wait on a
+// password manager or keychain prompt, so it happens in the background
+// and run is called when handleSecretsResolved gets the values. Commands
+// without secrets are run straight away.
+func (m *model) withSecrets(command string, run secretsRun) tea.Cmd {
+	ref := secrets.Reference(command, referenceShell(command, m.shell(), m.execTarget()))
+	if len(secrets.Names(command)) == 0 {
+		return run(m, ref, nil)
+	}
+	sources := m.config.Secrets
+	return tea.Batch(
+		m.showNotification("🔑", "Reading secrets...", "info"),
+		func() tea.Msg {
+			env, values, err := secrets.Env(context.Background(), command, sources)
+			return secretsResolvedMsg{command: ref, env: env, values: values, err: err, run: run}
+		},
+	)
+}
+
+// handleSecretsResolved runs the command the secrets were read for. The
+// values are remembered so that output kept in the history and for Ask AI
+// is redacted.
+func (m *model) handleSecretsResolved(msg secretsResolvedMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.showNotification("🔑", msg.err.Error(), "error")
+	}
+	for _, v := range msg.values {
+		if !slices.Contains(m.secretValues, v) {
+			m.secretValues = append(m.secretValues, v)
+		}
+	}
+	return msg.run(m, msg.command, msg.env)
+}
+
+// referenceShell is the shell that expands the secret references in
+// command: sh inside the target's container, shell otherwise
+func referenceShell(command string, shell shellSpec, target config.ExecTarget) string {
+	if dockerExec(command, target, "", nil) != command {
+		return "sh"
+	}
+	return shell.path
+}
+
+// envNames returns the names of NAME=value environment entries
+func envNames(env []string) []string {
+	names := make([]string, len(env))
+	for i, e := range env {
+		names[i], _, _ = strings.Cut(e, "=")
+	}
+	return names
+}
+
+// redactSecrets hides the values of secrets resolved this session in text
+func (m *model) redactSecrets(text string) string {
+	return secrets.Redact(text, m.secretValues)
+}
+
+// redactAPIKeys hides the AI providers' API keys, and the secrets resolved
+// this session, in text
+func (m *model) redactAPIKeys(text string) string {
+	for _, p := range m.config.AI.Providers {
+		text = secrets.Redact(text, []string{p.APIKey})
+	}
+	return m.redactSecrets(text)
+}
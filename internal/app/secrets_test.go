@@ -0,0 +1,98 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// resolveSecrets runs withSecrets to the end and returns what run got
+func resolveSecrets(t *testing.T, m *model, command string) (string, []string, bool) {
+	t.Helper()
+	var got string
+	var gotEnv []string
+	ran := false
+	run := func(m *model, command string, env []string) tea.Cmd {
+		got, gotEnv, ran = command, env, true
+		return nil
+	}
+
+	cmd := m.withSecrets(command, run)
+	if ran {
+		return got, gotEnv, ran
+	}
+	// The batch is the notification, then the read
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("withSecrets() = %T, want the notification and the read", cmd())
+	}
+	m.handleSecretsResolved(batch[1]().(secretsResolvedMsg))
+	return got, gotEnv, ran
+}
+
+func TestWithSecrets(t *testing.T) {
+	t.Setenv("SHELL", "/bin/bash")
+	m := model{config: config.Config{
+		Secrets: map[string]config.SecretSource{"TOKEN": {Command: "echo s3cr3t"}},
+		History: config.HistoryConfig{OutputLimit: 1024},
+	}}
+
+	// Reading secrets doesn't block the key handler
+	var ran bool
+	m.withSecrets("echo {{secret:TOKEN}}", func(*model, string, []string) tea.Cmd { ran = true; return nil })
+	if ran {
+		t.Error("withSecrets() ran the command before reading its secrets")
+	}
+
+	command, env, _ := resolveSecrets(t, &m, `curl -H "Authorization: {{secret:TOKEN}}" api`)
+	if want := `curl -H "Authorization: $SKITZ_SECRET_TOKEN" api`; command != want {
+		t.Errorf("command = %q, want %q", command, want)
+	}
+	if len(env) != 1 || env[0] != "SKITZ_SECRET_TOKEN=s3cr3t" {
+		t.Errorf("env = %v, want [SKITZ_SECRET_TOKEN=s3cr3t]", env)
+	}
+	if got := envNames(env); len(got) != 1 || got[0] != "SKITZ_SECRET_TOKEN" {
+		t.Errorf("envNames() = %v", got)
+	}
+
+	if out := m.historyOutput("token is s3cr3t\n"); strings.Contains(out, "s3cr3t") {
+		t.Errorf("historyOutput() = %q, want the secret redacted", out)
+	}
+
+	// nu has its own syntax for environment variables
+	m.config.Execution.Shell = "/usr/bin/nu"
+	if command, _, _ := resolveSecrets(t, &m, "psql -p {{secret:TOKEN}}"); command != "psql -p $env.SKITZ_SECRET_TOKEN" {
+		t.Errorf("nu command = %q", command)
+	}
+
+	// Commands without secrets run straight away
+	if command, env, ran := resolveSecrets(t, &m, "ls"); !ran || command != "ls" || env != nil {
+		t.Errorf("withSecrets(ls) = %q, %v, ran %v", command, env, ran)
+	}
+}
+
+func TestWithSecretsError(t *testing.T) {
+	m := model{config: config.Config{
+		Secrets: map[string]config.SecretSource{"TOKEN": {Command: "exit 1"}},
+	}}
+	if _, _, ran := resolveSecrets(t, &m, "echo {{secret:TOKEN}}"); ran {
+		t.Error("ran the command when its secret couldn't be read")
+	}
+	if n := m.notifications[len(m.notifications)-1]; n.Style != "error" {
+		t.Errorf("notification = %+v, want the failed source", n)
+	}
+}
+
+func TestReferenceShell(t *testing.T) {
+	nu := shellSpec{path: "/usr/bin/nu"}
+	target := config.ExecTarget{Container: "web"}
+	if got := referenceShell("env", nu, target); got != "sh" {
+		t.Errorf("referenceShell() in a container = %q, want sh", got)
+	}
+	if got := referenceShell("docker ps", nu, target); got != nu.path {
+		t.Errorf("referenceShell() for docker on the host = %q, want %q", got, nu.path)
+	}
+}
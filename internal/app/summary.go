@@ -0,0 +1,185 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// S in the detail view asks the AI for a five-bullet summary of the
+// section and shows it above the section. Summaries are saved in the data
+// directory under the SHA-256 of the section's content, so they show at
+// once next time and are only generated again after the section changes.
+
+// summaryQuestion is what the AI is asked about the section
+const summaryQuestion = `Summarize this section in exactly 5 short markdown bullet points, each starting with "- ". Output only the bullets.`
+
+// sectionSummary is the summary of one version of a section's content
+type sectionSummary struct {
+	text    string
+	err     error
+	loading bool
+	shown   bool
+}
+
+// summaryMsg carries a generated summary
+type summaryMsg struct {
+	hash string
+	text string
+	err  error
+}
+
+// contentHash identifies a version of a section's content
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// summaryPath is where the summary of the content with hash is saved
+func summaryPath(hash string) string {
+	return filepath.Join(config.DataDir, "summaries", hash+".md")
+}
+
+// loadSummary returns the saved summary of the content with hash
+func loadSummary(hash string) (string, bool) {
+	data, err := os.ReadFile(summaryPath(hash))
+	if err != nil || len(data) == 0 {
+		return "", false
+	}
+	return string(data), true
+}
+
+// saveSummary saves the summary of the content with hash
+func saveSummary(hash, text string) error {
+	path := summaryPath(hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create summaries directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return fmt.Errorf("failed to save summary: %w", err)
+	}
+	return nil
+}
+
+// toggleSectionSummary shows the current section's summary, generating it
+// if it isn't saved, or hides it. A failed summary is retried.
+func (m *model) toggleSectionSummary() tea.Cmd {
+	sec := m.currentSection()
+	if sec == nil || m.sectionHash == "" {
+		return nil
+	}
+	if sec.dynamic != nil && m.dynamicSections[dynamicKey(m.currentResource(), sec)].fetched.IsZero() {
+		return m.showNotification("!", "Wait for the section to load", "warning")
+	}
+
+	hash := m.sectionHash
+	s := m.summaries[hash]
+	if s.shown && s.err == nil {
+		s.shown = false
+		m.summaries[hash] = s
+		m.updateViewportContent()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	if s.text == "" && !s.loading {
+		if text, ok := loadSummary(hash); ok {
+			s.text = text
+		} else {
+			if cmd := m.aiUnavailable(); cmd != nil {
+				return cmd
+			}
+			if m.config.AI.DefaultProvider == "" {
+				return m.showNotification("!", "Configure a provider first", "warning")
+			}
+			s.loading, s.err = true, nil
+			cmd = summarizeSection(m.config, hash, m.currentSectionContent())
+		}
+	}
+	s.shown = true
+	if m.summaries == nil {
+		m.summaries = make(map[string]sectionSummary)
+	}
+	m.summaries[hash] = s
+	m.updateViewportContent()
+	return cmd
+}
+
+// summarizeSection asks the AI to summarize content
+func summarizeSection(cfg config.Config, hash, content string) tea.Cmd {
+	return func() tea.Msg {
+		router, err := ai.NewRouter(cfg, config.FeatureAsk)
+		if err != nil {
+			return summaryMsg{hash: hash, err: err}
+		}
+		resp := router.Ask(summaryQuestion, content)
+		if resp.Error == nil && strings.TrimSpace(resp.Content) == "" {
+			resp.Error = errors.New("the AI returned an empty summary")
+		}
+		return summaryMsg{hash: hash, text: strings.TrimSpace(resp.Content), err: resp.Error}
+	}
+}
+
+// handleSummary stores and saves a generated summary and redraws the
+// section if it is still shown
+func (m *model) handleSummary(msg summaryMsg) {
+	s := m.summaries[msg.hash]
+	s.loading = false
+	s.text, s.err = msg.text, msg.err
+	if msg.err == nil {
+		if err := saveSummary(msg.hash, msg.text); err != nil {
+			slog.Warn("failed to save section summary", "error", err)
+		}
+	}
+	m.summaries[msg.hash] = s
+
+	if m.currentView == viewDetail && m.sectionHash == msg.hash {
+		m.updateViewportContent()
+	}
+}
+
+// currentSectionContent returns the markdown the current section shows
+func (m model) currentSectionContent() string {
+	sec := m.currentSection()
+	if sec == nil {
+		return ""
+	}
+	if sec.dynamic != nil {
+		return m.dynamicContent(m.currentResource(), sec)
+	}
+	return sec.content
+}
+
+// renderSectionSummary returns the current section's summary block, or ""
+// when it isn't shown
+func (m model) renderSectionSummary(width int) string {
+	s := m.summaries[m.sectionHash]
+	if !s.shown {
+		return ""
+	}
+
+	var body string
+	switch {
+	case s.loading:
+		body = lipgloss.NewStyle().Foreground(subtle).Italic(true).Render("Summarizing…")
+	case s.err != nil:
+		body = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Render(wrapMarkdown(fmt.Sprintf("⚠ %v. Press S to retry.", s.err), width-4))
+	default:
+		body = wrapMarkdown(s.text, width-4)
+	}
+	header := lipgloss.NewStyle().Foreground(lipgloss.Color("141")).Bold(true).Render("SUMMARY")
+	return lipgloss.NewStyle().PaddingLeft(2).MarginBottom(1).Render(
+		lipgloss.JoinVertical(lipgloss.Left, header, body),
+	)
+}
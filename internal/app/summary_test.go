@@ -0,0 +1,78 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestSectionSummary(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = orig }()
+
+	content := "`echo hi` say hi ^run\n"
+	m := model{
+		currentView: viewDetail,
+		resources:   []resource{{name: "demo", sections: []section{{title: "Usage", content: content}}}},
+	}
+	m.contentView = viewport.New(80, 30)
+	m.updateViewportContent()
+
+	hash := contentHash(content)
+	if m.sectionHash != hash {
+		t.Fatalf("sectionHash = %q, want the hash of the section", m.sectionHash)
+	}
+	if err := saveSummary(hash, "- says hi"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A saved summary shows without asking the AI
+	if cmd := m.toggleSectionSummary(); cmd != nil {
+		t.Error("asked the AI for a saved summary")
+	}
+	view := ansi.Strip(m.contentView.View())
+	if !strings.Contains(view, "SUMMARY") || !strings.Contains(view, "- says hi") {
+		t.Fatalf("summary not shown:\n%s", view)
+	}
+	lines := strings.Split(view, "\n")
+	if start := m.cmdLayout.starts[0]; !strings.Contains(lines[start], "echo hi") {
+		t.Errorf("command row not on line %d below the summary:\n%s", start, view)
+	}
+
+	m.toggleSectionSummary()
+	if strings.Contains(ansi.Strip(m.contentView.View()), "SUMMARY") {
+		t.Error("summary still shown after toggling it off")
+	}
+}
+
+func TestHandleSummary(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = orig }()
+
+	m := model{summaries: map[string]sectionSummary{
+		"a": {loading: true, shown: true},
+		"b": {loading: true, shown: true},
+	}}
+	m.handleSummary(summaryMsg{hash: "a", text: "- one"})
+	m.handleSummary(summaryMsg{hash: "b", err: errors.New("rate limited")})
+
+	if s := m.summaries["a"]; s.loading || s.text != "- one" {
+		t.Errorf("summary a = %+v", s)
+	}
+	if text, ok := loadSummary("a"); !ok || text != "- one" {
+		t.Errorf("saved summary = %q, %v", text, ok)
+	}
+	if _, ok := loadSummary("b"); ok {
+		t.Error("failed summary was saved")
+	}
+	if s := m.summaries["b"]; s.err == nil || s.loading {
+		t.Errorf("summary b = %+v", s)
+	}
+}
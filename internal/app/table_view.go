@@ -0,0 +1,256 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/hooks"
+	"github.com/htelsiz/skitz/internal/tabular"
+)
+
+// Column width limits; the maximum keeps one long cell from pushing the
+// other columns off screen
+const (
+	tableColumnMaxWidth = 50
+	tableColumnMinWidth = 6
+)
+
+// tableOutputMsg carries the captured output of a ^table command
+type tableOutputMsg struct {
+	command string
+	tool    string
+	output  string
+	err     error
+	elapsed time.Duration
+}
+
+// executeTable runs a command without a terminal and captures its output
+// for the table view
+func (m *model) executeTable(run commandRun) tea.Cmd {
+	return func() tea.Msg {
+		started := time.Now()
+		out, err := newShellCommand(run.shell, run.command).Output()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return tableOutputMsg{
+			command: run.command,
+			tool:    run.resource,
+			output:  string(out),
+			err:     err,
+			elapsed: time.Since(started),
+		}
+	}
+}
+
+// handleTableOutput opens the table view, or shows the raw output when it
+// has no columns
+func (m *model) handleTableOutput(msg tableOutputMsg) tea.Cmd {
+	done := func() tea.Msg {
+		return commandDoneMsg{
+			command: msg.command,
+			tool:    msg.tool,
+			success: msg.err == nil,
+			output:  msg.output,
+			hook: &hooks.Event{
+				Command:  msg.command,
+				Resource: msg.tool,
+				Mode:     string(CommandTable),
+				ExitCode: hooks.ExitCode(msg.err),
+				Duration: msg.elapsed,
+			},
+		}
+	}
+	if strings.TrimSpace(msg.output) != "" {
+		m.lastOutput = &commandOutput{command: msg.command, text: m.redactSecrets(msg.output)}
+	}
+	if msg.err != nil {
+		return tea.Batch(done, m.showNotification("✗", msg.err.Error(), "error"))
+	}
+
+	data, err := tabular.Parse(msg.output)
+	if err != nil {
+		m.term = EmbeddedTerm{
+			active:       true,
+			staticOutput: msg.output,
+			staticTitle:  msg.command,
+			exited:       true,
+		}
+		return tea.Batch(done, m.showNotification("▦", "Output has no columns; showing it as text", "info"))
+	}
+
+	m.tableView = &TableView{Command: msg.command, Data: data}
+	m.layoutTableView()
+	return done
+}
+
+// layoutTableView sizes the columns to the window and loads the rows in
+// their current order
+func (m *model) layoutTableView() {
+	tv := m.tableView
+	if tv == nil {
+		return
+	}
+
+	// Border, padding and the title and hint lines around the table
+	width := max(m.width-12, 20)
+	height := max(m.height-14, 5)
+
+	widths := make([]int, len(tv.Data.Headers))
+	for i, h := range tv.Data.Headers {
+		widths[i] = lipgloss.Width(h) + 2 // room for the sort arrow
+	}
+	for _, row := range tv.Data.Rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+	total := 0
+	for i := range widths {
+		widths[i] = min(widths[i], tableColumnMaxWidth)
+		// bubbles/table pads each cell by one column on both sides
+		total += widths[i] + 2
+	}
+	// Shrink the widest column until the table fits
+	for total > width {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= tableColumnMinWidth {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+
+	columns := make([]table.Column, len(tv.Data.Headers))
+	for i, h := range tv.Data.Headers {
+		switch {
+		case i+1 != tv.SortCol:
+		case tv.SortDesc:
+			h += " ▼"
+		default:
+			h += " ▲"
+		}
+		columns[i] = table.Column{Title: h, Width: widths[i]}
+	}
+	rows := make([]table.Row, len(tv.Data.Rows))
+	for i, r := range tv.Data.Rows {
+		rows[i] = table.Row(r)
+	}
+
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(true)
+	styles.Selected = styles.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57"))
+
+	cursor := tv.Table.Cursor()
+	tv.Table = table.New(
+		table.WithColumns(columns),
+		table.WithRows(rows),
+		table.WithFocused(true),
+		table.WithHeight(height),
+		table.WithStyles(styles),
+	)
+	if len(rows) > 0 {
+		tv.Table.SetCursor(cursor)
+	}
+}
+
+// sortTableView sorts by column col (1-based); sorting by the same column
+// again reverses the order
+func (m *model) sortTableView(col int) {
+	tv := m.tableView
+	if col > len(tv.Data.Headers) {
+		return
+	}
+	if tv.SortCol == col {
+		tv.SortDesc = !tv.SortDesc
+	} else {
+		tv.SortCol, tv.SortDesc = col, false
+	}
+	tv.Data.Sort(col-1, tv.SortDesc)
+	m.layoutTableView()
+}
+
+// handleTableViewKeys routes keys to the table view
+func (m *model) handleTableViewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	tv := m.tableView
+	switch keyStr := msg.String(); keyStr {
+	case "esc", "q":
+		m.tableView = nil
+		return m, nil
+
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		m.sortTableView(int(keyStr[0] - '0'))
+		return m, nil
+
+	case "y", "ctrl+y":
+		i := tv.Table.Cursor()
+		if i < 0 || i >= len(tv.Data.Lines) {
+			return m, nil
+		}
+		if err := clipboard.WriteAll(tv.Data.Lines[i]); err != nil {
+			return m, m.showNotification("!", "Copy failed: "+err.Error(), "error")
+		}
+		return m, m.showNotification("📋", "Row copied to clipboard", "success")
+	}
+
+	var cmd tea.Cmd
+	tv.Table, cmd = tv.Table.Update(msg)
+	return m, cmd
+}
+
+// renderTableView renders the table in place of the command list
+func (m model) renderTableView(width int) string {
+	tv := m.tableView
+	if tv == nil {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("213")).
+		Bold(true)
+	countStyle := lipgloss.NewStyle().Foreground(subtle)
+	hintStyle := lipgloss.NewStyle().
+		Foreground(subtle).
+		Italic(true)
+	keyHintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("39"))
+
+	title := titleStyle.Render("▦ "+truncate(tv.Command, max(width-30, 10))) +
+		countStyle.Render(fmt.Sprintf("  %d rows", len(tv.Data.Rows)))
+	hint := keyHintStyle.Render("↑↓") + hintStyle.Render(" move  ") +
+		keyHintStyle.Render("1-9") + hintStyle.Render(" sort by column  ") +
+		keyHintStyle.Render("y") + hintStyle.Render(" copy row  ") +
+		keyHintStyle.Render("esc") + hintStyle.Render(" close")
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("99")).
+		Padding(0, 1).
+		Width(width - 6)
+
+	content := lipgloss.JoinVertical(lipgloss.Left, title, "", tv.Table.View(), "", hint)
+	return lipgloss.NewStyle().Padding(1, 2).Render(boxStyle.Render(content))
+}
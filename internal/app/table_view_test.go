@@ -0,0 +1,81 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseCommandsTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		table bool
+		desc  string
+	}{
+		{"before ^run", "`docker ps` Containers ^table ^run", true, "Containers"},
+		{"after ^run", "`kubectl get pods` Pods ^run ^table", true, "Pods"},
+		{"with tags", "`az vm list -o table` VMs ^tag:azure ^table ^run", true, "VMs"},
+		{"plain", "`docker ps` Containers ^run", false, "Containers"},
+		{"not a word boundary", "`ls` Files ^run ^tables", false, "Files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds := parseCommands(tt.line)
+			if len(cmds) != 1 {
+				t.Fatalf("parseCommands() = %+v", cmds)
+			}
+			if cmds[0].table != tt.table || cmds[0].description != tt.desc {
+				t.Errorf("table = %v, description = %q, want %v, %q", cmds[0].table, cmds[0].description, tt.table, tt.desc)
+			}
+		})
+	}
+}
+
+func TestTableViewSort(t *testing.T) {
+	m := model{width: 120, height: 40}
+	m.handleTableOutput(tableOutputMsg{
+		command: "kubectl get pods",
+		output:  "NAME   RESTARTS\nweb    10\napi    2\n",
+	})
+	if m.tableView == nil {
+		t.Fatal("table view not opened")
+	}
+
+	names := func() []string {
+		var out []string
+		for _, row := range m.tableView.Table.Rows() {
+			out = append(out, row[0])
+		}
+		return out
+	}
+
+	m.sortTableView(2)
+	if got := names(); !slices.Equal(got, []string{"api", "web"}) {
+		t.Errorf("ascending = %v", got)
+	}
+	if got := m.tableView.Table.Columns()[1].Title; got != "RESTARTS ▲" {
+		t.Errorf("sorted header = %q", got)
+	}
+
+	m.sortTableView(2)
+	if got := names(); !slices.Equal(got, []string{"web", "api"}) {
+		t.Errorf("descending = %v", got)
+	}
+
+	// Columns past the last one are ignored
+	m.sortTableView(9)
+	if m.tableView.SortCol != 2 {
+		t.Errorf("SortCol = %d, want 2", m.tableView.SortCol)
+	}
+}
+
+func TestTableOutputNotTabular(t *testing.T) {
+	m := model{width: 120, height: 40}
+	m.handleTableOutput(tableOutputMsg{command: "echo hi", output: "hi\n"})
+	if m.tableView != nil {
+		t.Error("table view opened for output without columns")
+	}
+	if !m.term.active || m.term.staticOutput != "hi\n" {
+		t.Errorf("raw output not shown: %+v", m.term)
+	}
+}
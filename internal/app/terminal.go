@@ -43,6 +43,10 @@ func (m model) renderTerminalPane() string {
 		// Convert vterm screen to styled string
 		var lines []string
 		for _, row := range screen {
+			// Rows keep their characters when the terminal narrows
+			if m.term.width > 0 && len(row) > m.term.width {
+				row = row[:m.term.width]
+			}
 			var line strings.Builder
 			for _, ch := range row {
 				if ch.Rune == 0 {
@@ -103,6 +107,16 @@ func (m model) renderTerminalPane() string {
 			title = "Output"
 		}
 		statusParts = append(statusParts, textStyle.Render(title))
+	} else if m.term.replay != nil {
+		label := "▶ Replaying"
+		if m.term.exited {
+			label = "■ Replay finished"
+		}
+		statusParts = append(statusParts, textStyle.Render(label))
+		statusParts = append(statusParts, textStyle.Copy().Foreground(lipgloss.Color("245")).Render(m.term.command))
+	} else if m.term.job != nil && !m.term.exited {
+		statusParts = append(statusParts, textStyle.Render("● Job running"))
+		statusParts = append(statusParts, textStyle.Copy().Foreground(lipgloss.Color("245")).Render(m.term.command))
 	} else if m.term.exited {
 		if m.term.exitErr != nil {
 			statusParts = append(statusParts, textStyle.Copy().Background(lipgloss.Color("52")).Render("✗ Failed"))
@@ -122,8 +136,23 @@ func (m model) renderTerminalPane() string {
 	}
 
 	// Add key hints
-	if m.term.exited || m.term.staticOutput != "" {
-		statusParts = append(statusParts, keyStyle.Render("esc")+" "+textStyle.Render("close"))
+	if m.term.replay != nil && !m.term.focused {
+		statusParts = append(statusParts,
+			keyStyle.Render("e")+" "+textStyle.Render("export"),
+			keyStyle.Render("esc")+" "+textStyle.Render("close"))
+	} else if m.term.job != nil && !m.term.exited {
+		statusParts = append(statusParts,
+			keyStyle.Render("x")+" "+textStyle.Render("stop"),
+			keyStyle.Render("esc")+" "+textStyle.Render("detach"))
+	} else if m.term.job != nil {
+		statusParts = append(statusParts,
+			keyStyle.Render("o")+" "+textStyle.Render("pager"),
+			keyStyle.Render("x")+" "+textStyle.Render("remove"),
+			keyStyle.Render("esc")+" "+textStyle.Render("close"))
+	} else if m.term.exited || m.term.staticOutput != "" {
+		statusParts = append(statusParts,
+			keyStyle.Render("o")+" "+textStyle.Render("pager"),
+			keyStyle.Render("esc")+" "+textStyle.Render("close"))
 	} else if m.term.focused {
 		statusParts = append(statusParts, keyStyle.Render("F1")+" "+textStyle.Render("return"))
 	} else {
@@ -140,5 +169,8 @@ func (m model) renderTerminalPane() string {
 
 	termPane := termStyle.Render(content)
 
+	if followUps := m.renderFollowUps(); followUps != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, termPane, status, followUps)
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, termPane, status)
 }
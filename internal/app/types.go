@@ -6,9 +6,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/ai"
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/tabular"
 )
 
 // ActiveAgent represents a currently running agent
@@ -18,8 +24,11 @@ type ActiveAgent struct {
 	Provider  string
 	Runtime   string    // "docker", "e2b"
 	StartTime time.Time
-	Status    string    // "running", "completed", "failed"
+	Status    string    // "running", "building", "cancelling"
 	Task      string    // The prompt/task
+	Container string        // docker container removed on cancel or timeout
+	Timeout   time.Duration // 0 = no limit
+	Log       *agentLog     // output so far, for runtimes that stream it
 }
 
 // DashboardAction represents an action available in the Actions tab
@@ -35,14 +44,14 @@ type DashboardAction struct {
 type AddResourceWizard struct {
 	Step      int       // 0=name, 1=template, 2=confirm
 	Name      string
-	Template  string    // "blank", "commands", "detailed"
+	Template  string    // "blank", "commands", "detailed", "community"
 	InputForm *huh.Form
 }
 
 // PreferencesWizard holds state for the Preferences wizard
 type PreferencesWizard struct {
 	Step      int       // 0=menu, 1+=subsections
-	Section   string    // "history", "mcp", "editor"
+	Section   string    // "history", "mcp", "execution", "editor"
 	InputForm *huh.Form
 	// History settings
 	HistoryEnabled      bool
@@ -53,26 +62,46 @@ type PreferencesWizard struct {
 	MCPAction  string // "add", "remove", "edit"
 	MCPName    string
 	MCPURL     string
+	MCPRefresh string // seconds, empty for mcp.refresh_seconds
+	MCPPoll    bool   // false disables the server
+	// Execution settings
+	Shell      string
+	LoginShell bool
 	// Editor setting
 	Editor string
 }
 
 // ProvidersWizard holds state for the Configure Providers wizard
 type ProvidersWizard struct {
-	Step         int       // 0=menu, 1=type select, 2=details form, 3=test, 4=set default
-	Action       string    // "add", "edit:name", "remove:name", "default"
+	Step         int       // 0=menu, 1=type select, 2=details form, 3=test, 4=set default, 5=advanced settings, 6=import
+	Action       string    // "add", "import", "edit:name", "remove:name", "default"
 	InputForm    *huh.Form
 	// Provider fields
-	ProviderType string // "openai", "anthropic", "ollama", "openai-compatible"
+	ProviderType string // "openai", "anthropic", "ollama", "openai-compatible", "gemini", "vertex", "bedrock"
 	Name         string
 	APIKey       string
 	BaseURL      string
 	DefaultModel string
 	Enabled      bool
+	// Cloud provider fields for Vertex AI and Bedrock
+	Region          string
+	Project         string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Advanced settings, shown when Advanced is chosen in the details form
+	Advanced      bool
+	Temperature   string
+	MaxTokens     string
+	Timeout       string
+	SystemPrompts map[string]*string           // keyed by AI feature
+	Features      map[string]config.AISettings // the provider's feature settings when editing
 	// Test connection state
 	Testing    bool
 	TestResult string
 	TestError  string
+	// Credentials found on the machine, and the indexes chosen for import
+	Detected []ai.Found
+	Import   []int
 }
 
 // DeleteResourceWizard holds state for delete confirmation
@@ -91,6 +120,10 @@ type RunAgentWizard struct {
 	AgentName string
 	Task      string
 	Image     string
+	Timeout   string // duration string, empty = no limit
+	Template  string // E2B sandbox template
+	Workspace string // directory uploaded to the E2B sandbox, or mounted in the container
+	Mount     string // container mount of Workspace: "" (none), "ro" or "rw"
 	Confirmed bool
 	InputForm *huh.Form
 }
@@ -109,10 +142,53 @@ type SavedAgentWizard struct {
 	InputForm *huh.Form
 }
 
+// KubeSwitcher holds state for the kubeconfig context/namespace switcher
+type KubeSwitcher struct {
+	Context   string
+	Namespace string
+	Form      *huh.Form
+}
+
+// TableView shows the output of a ^table command as a sortable table
+type TableView struct {
+	Command  string
+	Data     tabular.Table
+	Table    table.Model
+	SortCol  int // 1-based column; 0 keeps the output order
+	SortDesc bool
+}
+
+// PagerView shows long output full size, with search
+type PagerView struct {
+	Title     string
+	Lines     []string // the output without escape sequences, for search
+	View      viewport.Model
+	Searching bool   // a search is being typed into Input
+	Input     string // the search being typed
+	Query     string // the last search
+	Matches   []int  // lines containing Query
+	Match     int    // index into Matches of the current match
+}
+
+// CommandEditor holds state for quick-editing the selected command
+type CommandEditor struct {
+	Target      command // command being edited, as parsed from the section
+	Command     string
+	Description string
+	Form        *huh.Form
+}
+
 // section represents a documentation section within a resource
 type section struct {
 	title   string
 	content string
+	dynamic *dynamicSource // set when content comes from a provider
+
+	// file is the resource file the section was read from, e.g.
+	// "docker-detail.md", and line the index of its first line there.
+	// Sections not read from a file have no file.
+	file string
+	line int
 }
 
 // resource represents a tool/documentation resource
@@ -121,7 +197,14 @@ type resource struct {
 	description string // First line of content
 	content     string
 	sections    []section
-	embedded    bool // true if loaded from embedded FS (not user dir)
+	embedded    bool   // true if loaded from embedded FS (not user dir)
+	category    string // front-matter category, overrides toolMetadata
+
+	layout         config.SectionLayout // front-matter section layout
+	defaultSection int                  // index into sections to open on
+
+	tags []string // front-matter tags, e.g. k8s
+	repo string   // front-matter GitHub repository, e.g. cli/cli
 }
 
 // command represents a parsed command from markdown
@@ -132,6 +215,23 @@ type command struct {
 	runnable    bool
 	inputVar    string
 	description string
+	tags        []string // from ^tag:name annotations
+
+	// table captures the output and shows it as a sortable table, from
+	// the ^table annotation
+	table bool
+
+	// pager captures the output and opens it in the pager, from the
+	// ^pager annotation
+	pager bool
+
+	// onFail are the commands offered when this one fails, from
+	// ^onfail:command or ^onfail:`command with spaces` annotations
+	onFail []string
+
+	// requires are the capabilities the user must declare to run it, from
+	// ^requires annotations
+	requires []string
 }
 
 // toolMeta contains metadata for enhanced card rendering
@@ -146,6 +246,30 @@ type toolMeta struct {
 	topCommands []string
 }
 
+// categoryOrder is the display order of dashboard resource groups
+var categoryOrder = []string{"AI", "AI Agent", "Cloud", "Containers", "VCS", "Protocol", "Sandbox", "Language", "System"}
+
+// categoryColors maps resource categories to their accent color
+var categoryColors = map[string]lipgloss.Color{
+	"AI":         lipgloss.Color("212"),
+	"AI Agent":   lipgloss.Color("141"),
+	"Cloud":      lipgloss.Color("39"),
+	"Containers": lipgloss.Color("33"),
+	"VCS":        lipgloss.Color("208"),
+	"Protocol":   lipgloss.Color("43"),
+	"Sandbox":    lipgloss.Color("178"),
+	"Language":   lipgloss.Color("81"),
+	"System":     lipgloss.Color("110"),
+}
+
+// categoryColor returns the accent color for a category
+func categoryColor(category string) lipgloss.Color {
+	if c, ok := categoryColors[category]; ok {
+		return c
+	}
+	return lipgloss.Color("245")
+}
+
 // toolMetadata maps tool names to their metadata
 var toolMetadata = map[string]toolMeta{
 	"azure": {
@@ -196,6 +320,18 @@ var toolMetadata = map[string]toolMeta{
 		lastUsed:    "5m ago",
 		topCommands: []string{"docker ps", "docker compose up", "docker build"},
 	},
+	"kubectl": {
+		icon: "⎈",
+		asciiArt: `╭───╮
+│ ⎈ │
+╰───╯`,
+		color:       lipgloss.Color("33"),
+		category:    "Containers",
+		status:      "active",
+		cmdCount:    24,
+		lastUsed:    "",
+		topCommands: []string{"kubectl get pods", "kubectl logs", "kubectl apply"},
+	},
 	"git": {
 		icon: "⎇",
 		asciiArt: `╭───╮
@@ -307,6 +443,59 @@ var toolMetadata = map[string]toolMeta{
 }
 
 // parseCommands parses commands from markdown content looking for ^run annotations
+var tagRe = regexp.MustCompile(`\s*\^tag:([\w.-]+)`)
+
+// extractTags removes ^tag:name annotations from line and returns them.
+func extractTags(line string) (string, []string) {
+	var tags []string
+	for _, m := range tagRe.FindAllStringSubmatch(line, -1) {
+		tags = append(tags, m[1])
+	}
+	if tags == nil {
+		return line, nil
+	}
+	return tagRe.ReplaceAllString(line, ""), tags
+}
+
+// tableRe matches the ^table annotation
+var tableRe = regexp.MustCompile(`\s*\^table\b`)
+
+// pagerRe matches the ^pager annotation
+var pagerRe = regexp.MustCompile(`\s*\^pager\b`)
+
+// onFailRe matches an ^onfail annotation, with the command in backticks
+// or up to the next space
+var onFailRe = regexp.MustCompile("\\s*\\^onfail:(?:`([^`]+)`|([^\\s`]+))")
+
+// extractOnFail returns line without its ^onfail annotations, and their
+// commands
+func extractOnFail(line string) (string, []string) {
+	var commands []string
+	for _, m := range onFailRe.FindAllStringSubmatch(line, -1) {
+		commands = append(commands, strings.TrimSpace(m[1]+m[2]))
+	}
+	if commands == nil {
+		return line, nil
+	}
+	return onFailRe.ReplaceAllString(line, ""), commands
+}
+
+// requiresRe matches a ^requires:capability annotation
+var requiresRe = regexp.MustCompile(`\s*\^requires:([\w.-]+)`)
+
+// extractRequires removes ^requires annotations from line and returns
+// their capabilities
+func extractRequires(line string) (string, []string) {
+	var caps []string
+	for _, m := range requiresRe.FindAllStringSubmatch(line, -1) {
+		caps = append(caps, m[1])
+	}
+	if caps == nil {
+		return line, nil
+	}
+	return requiresRe.ReplaceAllString(line, ""), caps
+}
+
 func parseCommands(content string) []command {
 	var commands []command
 	lines := strings.Split(content, "\n")
@@ -314,6 +503,13 @@ func parseCommands(content string) []command {
 	cmdRe := regexp.MustCompile("`" + `([^` + "`" + `]+)` + "`" + `\s*([^^]*)\s*\^run(?::(\w+))?`)
 
 	for i, line := range lines {
+		line, tags := extractTags(line)
+		line, onFail := extractOnFail(line)
+		line, requires := extractRequires(line)
+		asTable := tableRe.MatchString(line)
+		line = tableRe.ReplaceAllString(line, "")
+		inPager := pagerRe.MatchString(line)
+		line = pagerRe.ReplaceAllString(line, "")
 		matches := cmdRe.FindStringSubmatch(line)
 		if matches == nil {
 			continue
@@ -339,12 +535,63 @@ func parseCommands(content string) []command {
 			runnable:    true,
 			inputVar:    inputVar,
 			description: desc,
+			tags:        tags,
+			table:       asTable,
+			pager:       inPager,
+			onFail:      onFail,
+			requires:    requires,
 		})
 	}
 
 	return commands
 }
 
+// commandHasTag reports whether c carries tag.
+func commandHasTag(c command, tag string) bool {
+	for _, t := range c.tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandTags returns the distinct tags used by commands, in first-seen order.
+func commandTags(commands []command) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, c := range commands {
+		for _, t := range c.tags {
+			key := strings.ToLower(t)
+			if !seen[key] {
+				seen[key] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	return tags
+}
+
+// renderTagChip renders a tag as a small colored chip. Tags that flag risk
+// are shown in red so they stand out in long lists.
+func renderTagChip(tag string) string {
+	color := lipgloss.Color("245")
+	switch strings.ToLower(tag) {
+	case "dangerous", "destructive", "prod", "production":
+		color = lipgloss.Color("203")
+	case "safe", "readonly", "read-only":
+		color = lipgloss.Color("114")
+	default:
+		palette := []lipgloss.Color{"75", "141", "179", "117", "213"}
+		sum := 0
+		for _, r := range tag {
+			sum += int(r)
+		}
+		color = palette[sum%len(palette)]
+	}
+	return lipgloss.NewStyle().Foreground(color).Render("#" + tag)
+}
+
 // CardItem represents a single card in a CardGrid
 type CardItem struct {
 	Title       string
@@ -352,7 +599,8 @@ type CardItem struct {
 	Tag         string
 	TagColor    lipgloss.Color
 	BorderColor lipgloss.Color
-	Shortcut    int // 1-based index for [N] display
+	AccentColor lipgloss.Color // tag and selected border; defaults to TagColor
+	Shortcut    int            // 1-based index for [N] display
 }
 
 // CardGrid renders a responsive grid of cards
@@ -361,6 +609,30 @@ func CardGrid(items []CardItem, width int, selectedIdx int) string {
 		return ""
 	}
 
+	cardW := cardWidth(width)
+	cards := renderCards(items, cardW, selectedIdx)
+
+	// Arrange in rows
+	cardsPerRow := width / cardW
+	if cardsPerRow < 1 {
+		cardsPerRow = 1
+	}
+
+	var rows []string
+	for i := 0; i < len(cards); i += cardsPerRow {
+		end := i + cardsPerRow
+		if end > len(cards) {
+			end = len(cards)
+		}
+		row := lipgloss.JoinHorizontal(lipgloss.Top, cards[i:end]...)
+		rows = append(rows, row)
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// cardWidth returns the card width CardGrid uses for a grid of the given width
+func cardWidth(width int) int {
 	// Calculate card width - more compact layout
 	cardW := (width - 3) / 4 // Try 4 cards per row first
 	if cardW < 22 {
@@ -372,7 +644,14 @@ func CardGrid(items []CardItem, width int, selectedIdx int) string {
 	if cardW < 22 {
 		cardW = width - 2
 	}
+	if cardW < 1 {
+		cardW = 1
+	}
+	return cardW
+}
 
+// renderCards renders each item as a card of width cardW
+func renderCards(items []CardItem, cardW int, selectedIdx int) []string {
 	var cards []string
 	for i, item := range items {
 		isSelected := i == selectedIdx
@@ -391,14 +670,19 @@ func CardGrid(items []CardItem, width int, selectedIdx int) string {
 		// Truncate subtitle if too long
 		subtitle := item.Subtitle
 		maxSubLen := cardW - 6
-		if len(subtitle) > maxSubLen && maxSubLen > 3 {
-			subtitle = subtitle[:maxSubLen-3] + "..."
+		if maxSubLen > 3 {
+			subtitle = truncate(subtitle, maxSubLen)
+		}
+
+		accent := item.AccentColor
+		if accent == "" {
+			accent = item.TagColor
 		}
 
 		var cardContent string
 		if item.Tag != "" {
 			tagStyle := lipgloss.NewStyle().
-				Foreground(item.TagColor).
+				Foreground(accent).
 				Background(lipgloss.Color("236")).
 				Padding(0, 1)
 			cardContent = lipgloss.JoinVertical(lipgloss.Left,
@@ -418,8 +702,8 @@ func CardGrid(items []CardItem, width int, selectedIdx int) string {
 			borderColor = dimBorder
 		}
 		if isSelected {
-			if item.TagColor != "" {
-				borderColor = item.TagColor
+			if accent != "" {
+				borderColor = accent
 			} else {
 				borderColor = primary
 			}
@@ -433,24 +717,7 @@ func CardGrid(items []CardItem, width int, selectedIdx int) string {
 
 		cards = append(cards, cardStyle.Render(cardContent))
 	}
-
-	// Arrange in rows
-	cardsPerRow := width / cardW
-	if cardsPerRow < 1 {
-		cardsPerRow = 1
-	}
-
-	var rows []string
-	for i := 0; i < len(cards); i += cardsPerRow {
-		end := i + cardsPerRow
-		if end > len(cards) {
-			end = len(cards)
-		}
-		row := lipgloss.JoinHorizontal(lipgloss.Top, cards[i:end]...)
-		rows = append(rows, row)
-	}
-
-	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+	return cards
 }
 
 // highlightShellCommand applies syntax highlighting to shell commands
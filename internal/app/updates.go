@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/update"
+)
+
+// updateCheckTimeout bounds the startup check so a slow network doesn't
+// leave it running for the whole session
+const updateCheckTimeout = 10 * time.Second
+
+// updateAvailableMsg reports a release newer than the running version
+type updateAvailableMsg struct {
+	tag string
+}
+
+// updateCheckCmd looks for a newer release at startup, at most once per
+// updates.interval. Between checks it reports the release the last check
+// found.
+func updateCheckCmd(cfg config.UpdatesConfig) tea.Cmd {
+	if !cfg.Enabled() {
+		return nil
+	}
+	current := update.Current()
+	return func() tea.Msg {
+		path := update.StatePath(config.DataDir)
+		st := update.LoadState(path)
+		if !st.Due(cfg.Every(), time.Now()) {
+			if update.Newer(st.Latest, current) {
+				return updateAvailableMsg{tag: st.Latest}
+			}
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		releases, err := update.Releases(ctx, "")
+		if err != nil {
+			slog.Debug("update check failed", "error", err)
+			return nil
+		}
+		st = update.State{LastChecked: time.Now()}
+		if len(releases) > 0 {
+			st.Latest = releases[0].Tag
+		}
+		if err := st.Save(path); err != nil {
+			slog.Warn("failed to save update check", "error", err)
+		}
+		slog.Debug("checked for updates", "current", current, "latest", st.Latest)
+		if update.Newer(st.Latest, current) {
+			return updateAvailableMsg{tag: st.Latest}
+		}
+		return nil
+	}
+}
+
+// handleUpdateAvailable remembers the new release for the palette and
+// announces it
+func (m *model) handleUpdateAvailable(msg updateAvailableMsg) tea.Cmd {
+	m.latestRelease = msg.tag
+	return m.showNotification("⬆", fmt.Sprintf("skitz %s is available · ctrl+k → What's New", msg.tag), "info")
+}
+
+// getUpdatePaletteItems returns the What's New action, and the update
+// action once a newer release was found and this binary can replace itself
+func (m *model) getUpdatePaletteItems() []PaletteItem {
+	items := []PaletteItem{{
+		ID:       "action:whats_new",
+		Icon:     "📰",
+		Title:    "What's New",
+		Subtitle: "Release notes for versions newer than " + update.Current(),
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			return m.showChangelog()
+		},
+	}}
+	if m.latestRelease != "" && update.Installable() {
+		tag := m.latestRelease
+		items = append(items, PaletteItem{
+			ID:       "action:self_update",
+			Icon:     "⬆",
+			Title:    "Update skitz to " + tag,
+			Subtitle: "Download the release for " + runtime.GOOS + "/" + runtime.GOARCH + " and replace this binary",
+			Category: "action",
+			Handler: func(m *model) tea.Cmd {
+				return m.installUpdate(tag)
+			},
+		})
+	}
+	return items
+}
+
+// showChangelog fetches the releases and shows the notes of those newer
+// than the running version, or of the latest when it is up to date
+func (m *model) showChangelog() tea.Cmd {
+	if m.offline {
+		return m.showNotification("✈", errUpdateOffline.Error(), "warning")
+	}
+	current := update.Current()
+	parent, spin := m.startPaletteExecution("Fetching releases...")
+	return tea.Batch(spin, func() tea.Msg {
+		releases, err := update.Releases(parent, "")
+		if errors.Is(parent.Err(), context.Canceled) {
+			return nil
+		}
+		if err != nil {
+			return paletteResultMsg{title: "What's New", output: "Error: " + err.Error(), failed: true}
+		}
+		if len(releases) == 0 {
+			return paletteResultMsg{title: "What's New", output: "No releases have been published yet."}
+		}
+		newer := update.Since(releases, current)
+		if len(newer) == 0 {
+			title := fmt.Sprintf("What's New · %s is up to date", current)
+			return paletteResultMsg{title: title, output: update.Changelog(releases[:1])}
+		}
+		return paletteResultMsg{title: "What's New since " + current, output: update.Changelog(newer)}
+	})
+}
+
+// installUpdate replaces the running binary with the release tag
+func (m *model) installUpdate(tag string) tea.Cmd {
+	if m.offline {
+		return m.showNotification("✈", errUpdateOffline.Error(), "warning")
+	}
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		return m.showNotification("✗", "Can't find the skitz binary: "+err.Error(), "error")
+	}
+
+	title := "Update to " + tag
+	parent, spin := m.startPaletteExecution("Downloading skitz " + tag + "...")
+	return tea.Batch(spin, func() tea.Msg {
+		err := installRelease(parent, tag, exe)
+		if errors.Is(parent.Err(), context.Canceled) {
+			return nil
+		}
+		if err != nil {
+			slog.Error("self-update failed", "tag", tag, "error", err)
+			return paletteResultMsg{title: title, output: "Error: " + err.Error(), failed: true}
+		}
+		slog.Info("updated skitz", "tag", tag, "path", exe)
+		return paletteResultMsg{
+			title:  title,
+			output: fmt.Sprintf("Installed skitz %s at `%s`. Restart skitz to use it.", tag, exe),
+		}
+	})
+}
+
+// installRelease downloads the release tag and installs it over exe
+func installRelease(ctx context.Context, tag, exe string) error {
+	releases, err := update.Releases(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, r := range releases {
+		if r.Tag == tag {
+			return update.Install(ctx, r, runtime.GOOS, runtime.GOARCH, exe)
+		}
+	}
+	return fmt.Errorf("release %s not found", tag)
+}
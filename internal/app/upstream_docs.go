@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/github"
+)
+
+// U in the detail view of a resource with a repo: in its front-matter
+// shows the README of that GitHub repository as an Upstream Docs section.
+// READMEs are saved in the data directory and fetched again once a day;
+// offline, or when the fetch fails, the saved copy is shown.
+
+// upstreamDocsSectionTitle is the title of the section a README is shown in
+const upstreamDocsSectionTitle = "Upstream Docs"
+
+// readmeMaxAge is how long a saved README is shown before fetching it again
+const readmeMaxAge = 24 * time.Hour
+
+// upstreamDocsMsg carries the README fetched for a resource. stale is set
+// when the fetch failed and a saved copy is shown instead.
+type upstreamDocsMsg struct {
+	resource string
+	repo     github.Repo
+	readme   string
+	err      error
+	stale    bool
+}
+
+// readmePath is where the README of repo is saved
+func readmePath(repo github.Repo) string {
+	return filepath.Join(config.DataDir, "readmes", config.Slug(repo.Owner+"-"+repo.Name, "readme")+".md")
+}
+
+// loadReadme returns the saved README of repo and when it was saved
+func loadReadme(repo github.Repo) (string, time.Time, bool) {
+	path := readmePath(repo)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return "", time.Time{}, false
+	}
+	return string(data), info.ModTime(), true
+}
+
+// saveReadme saves the README of repo
+func saveReadme(repo github.Repo, readme string) error {
+	path := readmePath(repo)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create readmes directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(readme), 0644); err != nil {
+		return fmt.Errorf("failed to save README: %w", err)
+	}
+	return nil
+}
+
+// fetchUpstreamDocsCmd fetches the README of repo, falling back to the
+// saved copy when that fails. The configured token is only sent when it
+// is for github.com rather than GitHub Enterprise.
+func fetchUpstreamDocsCmd(cfg config.GitHubConfig, resName string, repo github.Repo) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var client github.Client
+		if cfg.APIURL == "" {
+			client.Token, _ = github.Token(ctx, cfg.Token)
+		}
+		readme, err := client.Readme(ctx, repo)
+		if err != nil {
+			if saved, _, ok := loadReadme(repo); ok {
+				slog.Warn("failed to fetch README, showing the saved copy", "repo", repo, "error", err)
+				return upstreamDocsMsg{resource: resName, repo: repo, readme: saved, stale: true}
+			}
+			return upstreamDocsMsg{resource: resName, repo: repo, err: err}
+		}
+		if err := saveReadme(repo, readme); err != nil {
+			slog.Warn("failed to save README", "repo", repo, "error", err)
+		}
+		return upstreamDocsMsg{resource: resName, repo: repo, readme: readme}
+	}
+}
+
+// showUpstreamDocs opens the Upstream Docs section of the current
+// resource, fetching the README unless a recent copy is saved
+func (m *model) showUpstreamDocs() tea.Cmd {
+	res := m.currentResource()
+	if res == nil {
+		return nil
+	}
+	for i, sec := range res.sections {
+		if sec.title == upstreamDocsSectionTitle {
+			m.secCursor = i
+			m.cmdCursor = 0
+			m.updateViewportContent()
+			return nil
+		}
+	}
+	if res.repo == "" {
+		return m.showNotification("⚠", "Add repo: owner/name to the front-matter of "+res.name+" to view its upstream docs", "warning")
+	}
+	repo, err := github.ParseRepo(res.repo)
+	if err != nil {
+		return m.showNotification("!", err.Error(), "error")
+	}
+
+	readme, saved, ok := loadReadme(repo)
+	if ok && (m.offline || time.Since(saved) < readmeMaxAge) {
+		return m.handleUpstreamDocs(upstreamDocsMsg{resource: res.name, repo: repo, readme: readme, stale: m.offline})
+	}
+	if m.offline {
+		return m.showNotification("✈", "Offline. The README of "+repo.String()+" hasn't been fetched yet.", "warning")
+	}
+	return tea.Batch(
+		m.showNotification("📖", "Fetching the README of "+repo.String(), "info"),
+		fetchUpstreamDocsCmd(m.config.Integrations.GitHub, res.name, repo),
+	)
+}
+
+// handleUpstreamDocs adds a README as a section of its resource
+func (m *model) handleUpstreamDocs(msg upstreamDocsMsg) tea.Cmd {
+	if msg.err != nil {
+		return m.showNotification("!", msg.err.Error(), "error")
+	}
+
+	for i := range m.resources {
+		res := &m.resources[i]
+		if res.name != msg.resource {
+			continue
+		}
+		res.sections = append(res.sections, section{title: upstreamDocsSectionTitle, content: msg.readme})
+		if m.currentView == viewDetail && m.resCursor == i {
+			m.secCursor = len(res.sections) - 1
+			m.cmdCursor = 0
+			m.updateViewportContent()
+		}
+		break
+	}
+	if msg.stale {
+		return m.showNotification("📖", "Showing the saved README of "+msg.repo.String(), "info")
+	}
+	return m.showNotification("📖", "README of "+msg.repo.String(), "success")
+}
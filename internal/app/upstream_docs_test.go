@@ -0,0 +1,59 @@
+package app
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/github"
+)
+
+func TestUpstreamDocs(t *testing.T) {
+	orig := config.DataDir
+	config.DataDir = t.TempDir()
+	defer func() { config.DataDir = orig }()
+
+	m := model{
+		currentView: viewDetail,
+		offline:     true,
+		resources: []resource{{
+			name:     "gh",
+			repo:     "https://github.com/cli/cli",
+			sections: []section{{title: "Commands", content: "`gh pr list` list PRs ^run\n"}},
+		}},
+	}
+	m.contentView = viewport.New(80, 30)
+	m.updateViewportContent()
+
+	// Offline without a saved README there is nothing to show
+	m.showUpstreamDocs()
+	if len(m.resources[0].sections) != 1 {
+		t.Fatal("added a section without a README")
+	}
+
+	repo := github.Repo{Owner: "cli", Name: "cli"}
+	if err := saveReadme(repo, "# GitHub CLI\n"); err != nil {
+		t.Fatal(err)
+	}
+	// A stale copy is still shown offline
+	old := time.Now().Add(-2 * readmeMaxAge)
+	os.Chtimes(readmePath(repo), old, old)
+
+	m.showUpstreamDocs()
+	secs := m.resources[0].sections
+	if len(secs) != 2 || secs[1].title != upstreamDocsSectionTitle || secs[1].content != "# GitHub CLI\n" {
+		t.Fatalf("sections = %+v, want the README added", secs)
+	}
+	if m.secCursor != 1 {
+		t.Errorf("secCursor = %d, want the README section", m.secCursor)
+	}
+
+	// Opening it again jumps to the section instead of adding another
+	m.secCursor = 0
+	if cmd := m.showUpstreamDocs(); cmd != nil || len(m.resources[0].sections) != 2 || m.secCursor != 1 {
+		t.Errorf("sections = %d, secCursor = %d", len(m.resources[0].sections), m.secCursor)
+	}
+}
@@ -0,0 +1,259 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// A command's {{NAME}} placeholders are filled from the variables store:
+// the resource's own value, then the global one. Enter asks only for
+// placeholders without a value and can remember the answers; v asks for
+// all of them, prefilled, for that run only. The Variables palette action
+// lists and edits the store.
+
+// commandPlaceholders returns the distinct placeholder names in command,
+// in order
+func commandPlaceholders(command string) []string {
+	var names []string
+	for _, ph := range placeholderRe.FindAllStringSubmatch(command, -1) {
+		if !slices.Contains(names, ph[1]) {
+			names = append(names, ph[1])
+		}
+	}
+	return names
+}
+
+// storedValues returns the stored values of command's placeholders in
+// resource
+func (m model) storedValues(resource, command string) map[string]string {
+	values := make(map[string]string)
+	for _, name := range commandPlaceholders(command) {
+		if v, ok := m.variables.Lookup(resource, name); ok {
+			values[name] = v
+		}
+	}
+	return values
+}
+
+// resolveCommand fills the placeholders of cmd, asking for those without a
+// stored value, or for all of them when override is set. It reports false
+// when the form is cancelled or a value left empty.
+func (m *model) resolveCommand(resource string, cmd command, override bool) (string, bool) {
+	values := m.storedValues(resource, cmd.raw)
+	inputs := make(map[string]*string)
+	var fields []huh.Field
+	for _, name := range commandPlaceholders(cmd.raw) {
+		if _, ok := values[name]; ok && !override {
+			continue
+		}
+		value, ok := values[name]
+		if !ok {
+			value = m.lastArg(placeholderArgKey(name), name)
+		}
+		inputs[name] = &value
+		fields = append(fields, huh.NewInput().
+			Title(fmt.Sprintf("Enter %s:", name)).
+			Placeholder(name).
+			Suggestions(m.argSuggestions(placeholderArgKey(name), name)).
+			Value(&value))
+	}
+	if len(fields) == 0 {
+		return expandActionParams(cmd.raw, values), true
+	}
+
+	remember := false
+	if !override {
+		fields = append(fields, huh.NewConfirm().
+			Title(fmt.Sprintf("Remember for %s?", resource)).
+			Value(&remember))
+	}
+	form := huh.NewForm(huh.NewGroup(fields...)).
+		WithTheme(huh.ThemeCatppuccin())
+	if err := form.Run(); err != nil {
+		return "", false
+	}
+
+	typed := make(map[string]string, len(inputs))
+	for name, v := range inputs {
+		if *v == "" {
+			return "", false
+		}
+		values[name] = *v
+		typed[name] = *v
+		if remember {
+			m.variables.Set(resource, name, *v)
+		}
+	}
+	m.rememberArgs(typed, placeholderArgKey)
+	if remember {
+		if err := config.SaveVariables(m.variables); err != nil {
+			slog.Warn("failed to save variables", "error", err)
+		}
+	}
+	return expandActionParams(cmd.raw, values), true
+}
+
+// runSelectedCommand runs the selected command of the detail view once its
+// placeholders are filled, as a job when detach is set
+func (m *model) runSelectedCommand(override, detach bool) tea.Cmd {
+	res := m.currentResource()
+	if res == nil || len(m.commands) == 0 || m.cmdCursor >= len(m.commands) {
+		return nil
+	}
+	cmd := m.commands[m.cmdCursor]
+	if err := checkCapabilities(cmd.requires, m.config.Capabilities); err != nil {
+		return m.showNotification("🔒", err.Error(), "error")
+	}
+	finalCmd, ok := m.resolveCommand(res.name, cmd, override)
+	if !ok {
+		return nil
+	}
+	return m.withSecrets(finalCmd, func(m *model, finalCmd string, env []string) tea.Cmd {
+		mode := CommandEmbedded
+		wrapped := wrapForTarget(finalCmd, m.execTarget(), envNames(env)...)
+		if detach {
+			mode = CommandDetached
+			wrapped = captureForTarget(finalCmd, m.execTarget(), envNames(env)...)
+		} else if cmd.table {
+			mode = CommandTable
+			wrapped = captureForTarget(finalCmd, m.execTarget(), envNames(env)...)
+		} else if cmd.pager {
+			mode = CommandPager
+			wrapped = captureForTarget(finalCmd, m.execTarget(), envNames(env)...)
+		} else if isInteractiveCommand(finalCmd) {
+			mode = CommandInteractive
+		}
+
+		if m.config.Policy.CheckCommand(finalCmd) == nil {
+			m.recordCommandUse(cmd)
+		}
+
+		return m.runCommand(CommandSpec{
+			Command: wrapped,
+			Mode:    mode,
+			Env:     env,
+			OnFail:  cmd.onFail,
+		})
+	})
+}
+
+// variableNameRe matches the names placeholders can use
+var variableNameRe = regexp.MustCompile(`^\w+$`)
+
+// variableScopeGlobal is the scope option for variables of every resource
+const variableScopeGlobal = "global"
+
+// getVariablesPaletteItem returns the action that lists the variables store
+func (m *model) getVariablesPaletteItem() PaletteItem {
+	return PaletteItem{
+		ID:       "action:variables",
+		Icon:     "$",
+		Title:    "Variables",
+		Subtitle: "View and edit the values {{VARIABLE}} placeholders resolve from",
+		Category: "action",
+		Handler: func(m *model) tea.Cmd {
+			m.showVariables()
+			return nil
+		},
+	}
+}
+
+// showVariables replaces the palette list with an item per variable, after
+// one to add a variable. Selecting a variable edits it.
+func (m *model) showVariables() {
+	items := []PaletteItem{{
+		ID:       "variable:new",
+		Icon:     "+",
+		Title:    "New Variable",
+		Subtitle: "Set a value for every resource or for one",
+		Category: "variables",
+		Handler: func(m *model) tea.Cmd {
+			return m.startNewVariable()
+		},
+	}}
+	add := func(resource string, vars map[string]string) {
+		scope := resource
+		if scope == "" {
+			scope = variableScopeGlobal
+		}
+		for _, name := range slices.Sorted(maps.Keys(vars)) {
+			value := vars[name]
+			items = append(items, PaletteItem{
+				ID:       "variable:" + scope + ":" + name,
+				Icon:     "$",
+				Title:    name,
+				Subtitle: scope + " · " + truncate(value, 40),
+				Category: "variables",
+				Handler: func(m *model) tea.Cmd {
+					return m.startVariableEdit(resource, name, value)
+				},
+			})
+		}
+	}
+	add("", m.variables.Global)
+	for _, resource := range slices.Sorted(maps.Keys(m.variables.Resources)) {
+		add(resource, m.variables.Resources[resource])
+	}
+
+	m.palette.Query = ""
+	m.palette.Items = items
+	m.palette.Filtered = items
+	m.palette.Cursor = 0
+}
+
+// startNewVariable asks for a new variable's scope, name and value
+func (m *model) startNewVariable() tea.Cmd {
+	scopes := []string{variableScopeGlobal}
+	for _, res := range m.resources {
+		scopes = append(scopes, res.name)
+	}
+	params := []config.ActionParam{
+		{Name: "scope", Title: "Scope", Options: scopes},
+		{Name: "name", Title: "Name", Placeholder: "e.g. SUBSCRIPTION_ID", Required: true},
+		{Name: "value", Title: "Value", Required: true},
+	}
+	return m.collectActionParams("New Variable", "$", params, func(m *model, values map[string]string) tea.Cmd {
+		name := values["name"]
+		if !variableNameRe.MatchString(name) {
+			return m.showNotification("⚠️", fmt.Sprintf("%q is not a valid variable name; use letters, digits and _", name), "warning")
+		}
+		resource := values["scope"]
+		if resource == variableScopeGlobal {
+			resource = ""
+		}
+		m.variables.Set(resource, name, values["value"])
+		return m.saveVariables("Set " + name)
+	})
+}
+
+// startVariableEdit asks for a new value for a variable. An empty value
+// deletes it.
+func (m *model) startVariableEdit(resource, name, value string) tea.Cmd {
+	params := []config.ActionParam{
+		{Name: "value", Title: name + " (empty to delete)", Default: value},
+	}
+	return m.collectActionParams(name, "$", params, func(m *model, values map[string]string) tea.Cmd {
+		if values["value"] == "" {
+			m.variables.Delete(resource, name)
+			return m.saveVariables("Deleted " + name)
+		}
+		m.variables.Set(resource, name, values["value"])
+		return m.saveVariables("Set " + name)
+	})
+}
+
+// saveVariables saves the store and notifies with done
+func (m *model) saveVariables(done string) tea.Cmd {
+	if err := config.SaveVariables(m.variables); err != nil {
+		return m.showNotification("⚠️", "Failed to save variables: "+err.Error(), "error")
+	}
+	return m.showNotification("$", done, "success")
+}
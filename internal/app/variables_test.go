@@ -0,0 +1,59 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestCommandPlaceholders(t *testing.T) {
+	got := commandPlaceholders("az aks get-credentials -g {{RESOURCE_GROUP}} -n {{CLUSTER}} --subscription {{SUBSCRIPTION_ID}} # {{CLUSTER}}")
+	if want := []string{"RESOURCE_GROUP", "CLUSTER", "SUBSCRIPTION_ID"}; !slices.Equal(got, want) {
+		t.Errorf("commandPlaceholders() = %v, want %v", got, want)
+	}
+}
+
+func TestResolveCommandFromStore(t *testing.T) {
+	m := model{}
+	m.variables.Set("", "CLUSTER", "dev")
+	m.variables.Set("kubectl", "CLUSTER", "prod")
+	m.variables.Set("", "NS", "web")
+
+	cmd := parseCommands("`kubectl --context {{CLUSTER}} get pods -n {{NS}}` list pods ^run:NS")[0]
+	tests := []struct {
+		resource string
+		want     string
+	}{
+		{"kubectl", "kubectl --context prod get pods -n web"},
+		{"helm", "kubectl --context dev get pods -n web"},
+	}
+	for _, tt := range tests {
+		got, ok := m.resolveCommand(tt.resource, cmd, false)
+		if !ok || got != tt.want {
+			t.Errorf("resolveCommand(%q) = %q, %v, want %q", tt.resource, got, ok, tt.want)
+		}
+	}
+}
+
+func TestShowVariables(t *testing.T) {
+	m := model{}
+	m.variables = config.Variables{
+		Global:    map[string]string{"SUBSCRIPTION_ID": "sub-1"},
+		Resources: map[string]map[string]string{"kubectl": {"CLUSTER": "prod"}},
+	}
+	m.showVariables()
+
+	var got []string
+	for _, item := range m.palette.Items {
+		got = append(got, item.Title+" "+item.Subtitle)
+	}
+	want := []string{
+		"New Variable Set a value for every resource or for one",
+		"SUBSCRIPTION_ID global · sub-1",
+		"CLUSTER kubectl · prod",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("items = %q, want %q", got, want)
+	}
+}
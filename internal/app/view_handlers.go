@@ -2,10 +2,14 @@ package app
 
 import (
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/config"
 )
 
 func (m *model) initViewComponents() {
@@ -14,46 +18,72 @@ func (m *model) initViewComponents() {
 		return
 	}
 
-	contentW := m.width - 4
-	contentH := m.height - 8
-
-	if contentW < 60 {
-		contentW = 60
-	}
-	if contentH < 10 {
-		contentH = 10
-	}
-
-	m.contentView = viewport.New(contentW, contentH)
+	m.contentView = viewport.New(m.contentViewSize())
 	m.contentView.Style = lipgloss.NewStyle()
 
+	m.refreshKubeConfig()
 	m.updateViewportContent()
 	m.viewReady = true
 }
 
+// contentViewSize returns the size of the detail view's content viewport
+// for the current window
+func (m model) contentViewSize() (int, int) {
+	return max(m.width-4, 60), max(m.height-8, 10)
+}
+
+// resizeViewComponents fits the content viewport to a resized window and
+// reflows its content, keeping the selected command in view
+func (m *model) resizeViewComponents() {
+	m.contentView.Width, m.contentView.Height = m.contentViewSize()
+	if len(m.commands) == 0 {
+		m.updateViewportContent()
+		return
+	}
+	m.refreshCommandListDisplay()
+}
+
 func (m *model) updateViewportContent() {
 	sec := m.currentSection()
 	if sec == nil {
 		m.contentView.SetContent("No content")
 		m.cachedMarkdownContext = ""
+		m.sectionHash = ""
 		return
 	}
 
 	res := m.currentResource()
+	m.recordVisit(res, sec)
 	meta := toolMetadata[res.name]
+	content := sec.content
+	if sec.dynamic != nil {
+		content = m.dynamicContent(res, sec)
+	}
+	m.sectionHash = contentHash(content)
 
-	m.commands = parseCommands(sec.content)
+	m.commands = parseCommands(content)
+	if m.tagFilter != "" {
+		var filtered []command
+		for _, c := range m.commands {
+			if commandHasTag(c, m.tagFilter) {
+				filtered = append(filtered, c)
+			}
+		}
+		m.commands = filtered
+	}
+	if m.sortByUsage {
+		m.sortCommandsByUsage(res.name)
+	}
 	if m.cmdCursor >= len(m.commands) {
 		m.cmdCursor = 0
 	}
 
-	commandList := m.renderCommandList(m.contentView.Width, meta.color)
-
 	m.cachedMarkdownContext = ""
-	lines := strings.Split(sec.content, "\n")
+	lines := strings.Split(content, "\n")
 	cmdRunRe := regexp.MustCompile("`[^`]+`\\s*[^^]*\\s*\\^run")
 	var contextLines []string
 	for _, line := range lines {
+		line, _ := extractTags(line)
 		trimmed := strings.TrimSpace(line)
 		if trimmed == "" || strings.HasPrefix(trimmed, "#") || cmdRunRe.MatchString(line) {
 			continue
@@ -64,36 +94,213 @@ func (m *model) updateViewportContent() {
 		m.cachedMarkdownContext = strings.Join(contextLines, "\n")
 	}
 
+	m.invalidateCommandRows()
+	m.contentView.YOffset = 0
+	m.setDetailContent(meta.color)
+}
+
+// commandListHeaderLines is the height of the command list's header and
+// the margins around it
+const commandListHeaderLines = 4
+
+// commandListLayout places the command list's rows at a width
+type commandListLayout struct {
+	width       int
+	cmdW, descW int
+	starts      []int  // line each command's row starts on
+	heights     []int  // lines each command's row wraps to
+	lines       int    // lines in the whole list
+	context     string // the section's markdown context wrapped to width
+	summary     string // the section's AI summary, shown above the list
+}
+
+// cmdRowKey identifies a rendered command row in cmdRowCache
+type cmdRowKey struct {
+	index    int
+	selected bool
+	width    int
+}
+
+// invalidateCommandRows drops the command list layout, and with it the
+// cached rows, after the commands, their order or their run counts change
+func (m *model) invalidateCommandRows() {
+	m.cmdLayout = commandListLayout{}
+}
+
+// layoutCommandList works out the command list's columns and where each
+// row goes at width, unless it is already laid out for it, and empties the
+// row cache. Commands get the room they need, up to three fifths, and
+// descriptions the rest.
+func (m *model) layoutCommandList(width int) {
+	if m.cmdLayout.width == width && len(m.cmdLayout.starts) == len(m.commands) {
+		return
+	}
+
+	l := commandListLayout{width: width}
+	m.cmdRowCache = make(map[cmdRowKey][]string)
+	prefixW := 8 // " ▶  1  " or "     1  "
+	sepW := 3    // " │ "
+	availableW := width - prefixW - sepW - 4
+	longest := 0
+	for _, cmd := range m.commands {
+		longest = max(longest, lipgloss.Width(cmd.raw))
+	}
+	l.cmdW = min(longest, availableW*3/5)
+	if l.cmdW < 28 {
+		l.cmdW = 28
+	}
+	l.descW = availableW - l.cmdW
+	if l.descW < 12 {
+		l.descW = 12
+	}
+
+	l.summary = m.renderSectionSummary(width)
+	line := commandListHeaderLines
+	if l.summary != "" {
+		line += lipgloss.Height(l.summary)
+	}
+	for _, cmd := range m.commands {
+		h := max(len(wrapLines(cmd.raw, l.cmdW)), len(wrapLines(cmd.description, l.descW)))
+		l.starts = append(l.starts, line)
+		l.heights = append(l.heights, h)
+		line += h
+	}
+	l.lines = line
 	if m.cachedMarkdownContext != "" {
-		m.contentView.SetContent(commandList + "\n\n" + m.cachedMarkdownContext)
+		l.context = wrapMarkdown(m.cachedMarkdownContext, width-2)
+	}
+	m.cmdLayout = l
+}
+
+// setDetailContent renders the command list and the section's markdown
+// context into the content view. Only the rows within a screen of the
+// current scroll position are drawn.
+func (m *model) setDetailContent(accentColor lipgloss.Color) {
+	m.layoutCommandList(m.contentView.Width)
+	page := max(m.contentView.Height, 1)
+	top := m.contentView.YOffset - page
+	commandList := m.renderCommandList(accentColor, top, top+3*page)
+	if m.cmdLayout.summary != "" {
+		commandList = m.cmdLayout.summary + "\n" + commandList
+	}
+	if m.cmdLayout.context != "" {
+		m.contentView.SetContent(commandList + "\n\n" + m.cmdLayout.context)
 	} else {
 		m.contentView.SetContent(commandList)
 	}
-	m.contentView.GotoTop()
 }
 
-func (m *model) refreshCommandListDisplay() {
-	res := m.currentResource()
-	if res == nil || len(m.commands) == 0 {
+// redrawVisibleCommands draws the rows scrolled into view since the
+// content was last set
+func (m *model) redrawVisibleCommands(prevOffset int) {
+	if m.contentView.YOffset == prevOffset || len(m.commands) == 0 {
 		return
 	}
-	meta := toolMetadata[res.name]
-	commandList := m.renderCommandList(m.contentView.Width, meta.color)
+	if res := m.currentResource(); res != nil {
+		m.setDetailContent(toolMetadata[res.name].color)
+	}
+}
 
-	if m.cachedMarkdownContext != "" {
-		m.contentView.SetContent(commandList + "\n\n" + m.cachedMarkdownContext)
+// cycleTagFilter advances the tag filter through the tags used in the
+// current section, wrapping back to "all".
+func (m *model) cycleTagFilter() string {
+	sec := m.currentSection()
+	if sec == nil {
+		return ""
+	}
+	tags := commandTags(parseCommands(sec.content))
+
+	next := ""
+	if m.tagFilter == "" {
+		if len(tags) > 0 {
+			next = tags[0]
+		}
 	} else {
-		m.contentView.SetContent(commandList)
+		for i, t := range tags {
+			if strings.EqualFold(t, m.tagFilter) && i+1 < len(tags) {
+				next = tags[i+1]
+				break
+			}
+		}
 	}
 
-	headerLines := 4
-	selectedLine := headerLines + m.cmdCursor
-	viewTop := m.contentView.YOffset
-	viewBottom := viewTop + m.contentView.Height
+	m.tagFilter = next
+	m.cmdCursor = 0
+	m.updateViewportContent()
+	return next
+}
+
+// commandUses returns how many times cmd has been run from the current
+// resource.
+func (m model) commandUses(cmd command) int {
+	res := m.currentResource()
+	if res == nil {
+		return 0
+	}
+	return m.cmdStats.Count(config.CommandKey(res.name, cmd.cmd))
+}
 
-	if selectedLine < viewTop {
-		m.contentView.SetYOffset(selectedLine)
-	} else if selectedLine >= viewBottom {
-		m.contentView.SetYOffset(selectedLine - m.contentView.Height + 1)
+// sortCommandsByUsage orders the command list by run count, most used
+// first, keeping document order for ties.
+func (m *model) sortCommandsByUsage(resource string) {
+	sort.SliceStable(m.commands, func(i, j int) bool {
+		return m.cmdStats.Count(config.CommandKey(resource, m.commands[i].cmd)) >
+			m.cmdStats.Count(config.CommandKey(resource, m.commands[j].cmd))
+	})
+}
+
+// toggleUsageSort switches the command list between document order and
+// most used first, keeping the selected command selected.
+func (m *model) toggleUsageSort() bool {
+	selected := -1
+	if m.cmdCursor < len(m.commands) {
+		selected = m.commands[m.cmdCursor].lineNum
+	}
+
+	m.sortByUsage = !m.sortByUsage
+	m.updateViewportContent()
+
+	for i, c := range m.commands {
+		if c.lineNum == selected {
+			m.cmdCursor = i
+			m.refreshCommandListDisplay()
+			break
+		}
+	}
+	return m.sortByUsage
+}
+
+// recordCommandUse counts a run of cmd from the current resource.
+func (m *model) recordCommandUse(cmd command) {
+	res := m.currentResource()
+	if res == nil {
+		return
+	}
+	m.cmdStats.Record(config.CommandKey(res.name, cmd.cmd), time.Now())
+	config.SaveCommandStats(m.cmdStats)
+	m.invalidateCommandRows()
+	m.refreshCommandListDisplay()
+}
+
+func (m *model) refreshCommandListDisplay() {
+	res := m.currentResource()
+	if res == nil || len(m.commands) == 0 {
+		return
+	}
+	m.layoutCommandList(m.contentView.Width)
+	if m.cmdCursor < len(m.cmdLayout.starts) {
+		// Scroll every line of the selected row into view before drawing,
+		// so the rows around it are the ones drawn
+		firstLine := m.cmdLayout.starts[m.cmdCursor]
+		lastLine := firstLine + m.cmdLayout.heights[m.cmdCursor] - 1
+		viewTop := m.contentView.YOffset
+		viewBottom := viewTop + m.contentView.Height
+
+		if firstLine < viewTop {
+			m.contentView.YOffset = firstLine
+		} else if lastLine >= viewBottom {
+			m.contentView.YOffset = min(firstLine, lastLine-m.contentView.Height+1)
+		}
 	}
+	m.setDetailContent(toolMetadata[res.name].color)
 }
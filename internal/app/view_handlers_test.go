@@ -0,0 +1,117 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRenderCommandListWraps(t *testing.T) {
+	m := &model{commands: []command{
+		{raw: "kubectl get pods", description: strings.Repeat("lists every pod in the namespace ", 4)},
+		{raw: "kubectl logs -f deploy/api", description: "follow logs"},
+	}}
+
+	for _, width := range []int{60, 100, 160} {
+		m.layoutCommandList(width)
+		list := m.renderCommandList(lipgloss.Color("99"), 0, 1000)
+		lines := strings.Split(list, "\n")
+		for i, line := range lines {
+			if w := ansi.StringWidth(line); w > width {
+				t.Errorf("width %d: line %d is %d columns wide", width, i, w)
+			}
+		}
+		starts := m.cmdLayout.starts
+		if len(starts) != 2 {
+			t.Fatalf("width %d: %d row starts, want 2", width, len(starts))
+		}
+		if !strings.Contains(ansi.Strip(lines[starts[1]]), "kubectl logs") {
+			t.Errorf("width %d: row 2 doesn't start on line %d: %q", width, starts[1], lines[starts[1]])
+		}
+		if width == 60 && m.cmdLayout.heights[0] < 2 {
+			t.Errorf("long description didn't wrap at width %d", width)
+		}
+	}
+}
+
+func TestCommandListDrawsVisibleRows(t *testing.T) {
+	m := &model{resources: []resource{{name: "big"}}}
+	for i := range 300 {
+		m.commands = append(m.commands, command{raw: fmt.Sprintf("echo command-%03d", i), description: "prints a number"})
+	}
+	m.contentView.Width, m.contentView.Height = 100, 20
+	m.contentView.YOffset = 0
+	m.setDetailContent(lipgloss.Color("99"))
+
+	if got := len(m.cmdRowCache); got == 0 || got > 3*m.contentView.Height {
+		t.Fatalf("drew %d rows, want only those near the top", got)
+	}
+	content := ansi.Strip(m.contentView.View())
+	if !strings.Contains(content, "command-000") {
+		t.Errorf("first command not drawn:\n%s", content)
+	}
+
+	// Moving down the list draws the rows it scrolls into view, and only
+	// re-renders the rows whose selection changed
+	for range 150 {
+		m.cmdCursor++
+		m.refreshCommandListDisplay()
+	}
+	content = ansi.Strip(m.contentView.View())
+	if !strings.Contains(content, "command-150") {
+		t.Errorf("selected command not in view:\n%s", content)
+	}
+	if strings.Contains(content, "command-000") {
+		t.Error("view didn't scroll with the selection")
+	}
+	for _, idx := range []int{0, 149} {
+		if _, ok := m.cmdRowCache[cmdRowKey{index: idx, selected: true, width: 100}]; !ok {
+			t.Errorf("selected row %d wasn't cached", idx)
+		}
+	}
+	if _, ok := m.cmdRowCache[cmdRowKey{index: 299, width: 100}]; ok {
+		t.Error("row far below the view was drawn")
+	}
+
+	// The total height matches the layout, so scrolling reaches every row
+	if lines := m.contentView.TotalLineCount(); lines != m.cmdLayout.lines {
+		t.Errorf("content is %d lines, layout says %d", lines, m.cmdLayout.lines)
+	}
+
+	// Jumping to the bottom draws the last rows
+	offset := m.contentView.YOffset
+	m.contentView.GotoBottom()
+	m.redrawVisibleCommands(offset)
+	if content := ansi.Strip(m.contentView.View()); !strings.Contains(content, "command-299") {
+		t.Errorf("last command not drawn after jumping to the bottom:\n%s", content)
+	}
+}
+
+func TestCycleTagFilter(t *testing.T) {
+	content := "`kubectl get pods` list ^run ^tag:k8s ^tag:safe\n" +
+		"`kubectl delete pod {{pod}}` delete ^run:pod ^tag:K8S ^tag:dangerous\n" +
+		"`ls` list files ^run\n"
+	m := &model{resources: []resource{{name: "kubectl", sections: []section{{title: "Pods", content: content}}}}}
+	m.contentView.Width, m.contentView.Height = 100, 20
+
+	cmds := parseCommands(content)
+	if len(cmds) != 3 || cmds[0].raw != "kubectl get pods" || cmds[0].description != "list" ||
+		!slices.Equal(cmds[1].tags, []string{"K8S", "dangerous"}) || cmds[2].tags != nil {
+		t.Fatalf("parseCommands() = %+v", cmds)
+	}
+
+	// Tags differing only in case are one filter
+	for _, want := range []string{"k8s", "safe", "dangerous", ""} {
+		if got := m.cycleTagFilter(); got != want {
+			t.Fatalf("cycleTagFilter() = %q, want %q", got, want)
+		}
+		wantCommands := map[string]int{"k8s": 2, "safe": 1, "dangerous": 1, "": 3}[want]
+		if len(m.commands) != wantCommands {
+			t.Errorf("filter %q shows %d commands, want %d", want, len(m.commands), wantCommands)
+		}
+	}
+}
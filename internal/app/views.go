@@ -6,15 +6,20 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/htelsiz/skitz/internal/config"
 )
 
-// renderDashboardTabs renders the tab bar for Resources/Actions/Agents
-func (m model) renderDashboardTabs(width int) string {
-	tabs := []string{"RESOURCES", "ACTIONS", "AGENTS"}
+// dashboardTabNames names the dashboard tabs in order; the index is
+// model.dashboardTab
+var dashboardTabNames = []string{"Resources", "Actions", "Agents", "Deployments", "Plugins"}
 
+// renderDashboardTabs renders the dashboard tab bar
+func (m model) renderDashboardTabs(width int) string {
 	var tabParts []string
 
-	for i, title := range tabs {
+	for i, name := range dashboardTabNames {
+		title := strings.ToUpper(name)
 		if i == m.dashboardTab {
 			// Active tab - highlighted text with underline
 			tabStyle := lipgloss.NewStyle().
@@ -37,6 +42,111 @@ func (m model) renderDashboardTabs(width int) string {
 	return lipgloss.NewStyle().PaddingLeft(1).PaddingBottom(1).Render(tabRow)
 }
 
+// renderResourceGroups renders resource cards grouped under category
+// headers. Small groups share a row; collapsed groups show only their
+// header. Card shortcuts number from 1 within each group.
+func (m model) renderResourceGroups(width int) string {
+	if m.resourcesPending {
+		return m.renderResourceSkeleton(width)
+	}
+	groups := m.resourceGroups()
+	focused := m.focusedGroup(groups)
+
+	cardW := cardWidth(width)
+	perRow := max(width/cardW, 1)
+
+	var rows, line []string
+	used := 0
+	flush := func() {
+		if len(line) > 0 {
+			rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, line...))
+			line, used = nil, 0
+		}
+	}
+
+	for gi, g := range groups {
+		accent := categoryColor(g.name)
+		collapsed := m.collapsedGroups[g.name]
+
+		span := min(len(g.indices), perRow)
+		if collapsed {
+			span = 1
+		}
+		if used+span > perRow {
+			flush()
+		}
+
+		arrow := "▾"
+		if collapsed {
+			arrow = "▸"
+		}
+		headerStyle := lipgloss.NewStyle().Foreground(accent).Bold(gi == focused)
+		if gi == focused && collapsed {
+			headerStyle = headerStyle.Reverse(true)
+		}
+		header := " " + headerStyle.Render(fmt.Sprintf("%s %s %d", arrow, strings.ToUpper(g.name), len(g.indices)))
+		if ruleW := span*cardW - lipgloss.Width(header) - 2; ruleW > 0 {
+			header += " " + lipgloss.NewStyle().Foreground(dimBorder).Render(strings.Repeat("─", ruleW))
+		}
+		block := lipgloss.NewStyle().Width(span * cardW).Render(header)
+
+		if !collapsed {
+			var items []CardItem
+			selected := -1
+			for i, idx := range g.indices {
+				res := m.resources[idx]
+				meta := toolMetadata[res.name]
+				borderColor := dimBorder
+				if meta.status == "coming_soon" {
+					borderColor = lipgloss.Color("238")
+				}
+				if idx == m.resCursor {
+					selected = i
+				}
+				items = append(items, CardItem{
+					Title:       strings.ToUpper(res.name),
+					Subtitle:    res.description,
+					Tag:         g.name,
+					TagColor:    meta.color,
+					AccentColor: accent,
+					BorderColor: borderColor,
+					Shortcut:    i + 1,
+				})
+			}
+			cards := renderCards(items, cardW, selected)
+			var cardRows []string
+			for i := 0; i < len(cards); i += perRow {
+				cardRows = append(cardRows, lipgloss.JoinHorizontal(lipgloss.Top, cards[i:min(i+perRow, len(cards))]...))
+			}
+			block = lipgloss.JoinVertical(lipgloss.Left, append([]string{block}, cardRows...)...)
+		}
+
+		line = append(line, block)
+		used += span
+	}
+	flush()
+
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// renderResourceSkeleton renders a row of placeholder cards while the
+// resources load
+func (m model) renderResourceSkeleton(width int) string {
+	cardW := cardWidth(width)
+	items := make([]CardItem, min(max(width/cardW, 1), 3))
+	for i := range items {
+		items[i] = CardItem{
+			Title:       strings.Repeat("░", 8),
+			Subtitle:    "Loading resources…",
+			TagColor:    dimBorder,
+			BorderColor: dimBorder,
+			Shortcut:    i + 1,
+		}
+	}
+	header := " " + lipgloss.NewStyle().Foreground(subtle).Render("▾ RESOURCES")
+	return lipgloss.JoinVertical(lipgloss.Left, header, lipgloss.JoinHorizontal(lipgloss.Top, renderCards(items, cardW, -1)...))
+}
+
 // renderActionsTab renders the list of available actions
 func (m model) renderActionsTab(width, height int) string {
 	// If add resource wizard is active, show wizard form
@@ -149,6 +259,10 @@ func (m model) renderActionsTab(width, height int) string {
 			title = "Test Connection"
 		case 4:
 			title = "Set Default Provider"
+		case 5:
+			title = "Advanced Settings"
+		case 6:
+			title = "Import Providers"
 		}
 
 		header := lipgloss.NewStyle().
@@ -341,10 +455,18 @@ func (m model) renderAgentsTab(width, height int) string {
 		var items []CardItem
 		for _, agent := range m.activeAgents {
 			elapsed := time.Since(agent.StartTime).Round(time.Second)
+			subtitle := fmt.Sprintf("%s | %s | %s", agent.Provider, agent.Runtime, elapsed)
+			if agent.Timeout > 0 {
+				subtitle += " / " + agent.Timeout.String()
+			}
+			tag := "RUNNING"
+			if agent.Status == "cancelling" {
+				tag = "CANCELLING"
+			}
 			items = append(items, CardItem{
 				Title:       "⚡ " + agent.Name,
-				Subtitle:    fmt.Sprintf("%s | %s | %s", agent.Provider, agent.Runtime, elapsed),
-				Tag:         "RUNNING",
+				Subtitle:    subtitle,
+				Tag:         tag,
 				TagColor:    lipgloss.Color("220"),
 				BorderColor: dimBorder,
 				Shortcut:    shortcut,
@@ -365,12 +487,7 @@ func (m model) renderAgentsTab(width, height int) string {
 	if len(m.agentHistory) > 0 {
 		var items []CardItem
 		for _, entry := range m.agentHistory {
-			tag := "OK"
-			tagColor := lipgloss.Color("114")
-			if !entry.Success {
-				tag = "FAIL"
-				tagColor = lipgloss.Color("196")
-			}
+			tag, tagColor := agentStatusLabel(entry)
 
 			subtitle := formatTimeAgo(entry.Timestamp)
 			if entry.Duration > 0 {
@@ -402,6 +519,9 @@ func (m model) renderAgentsTab(width, height int) string {
 		Italic(true)
 
 	info := infoStyle.Render("Select an agent and press Enter to run/view")
+	if len(m.activeAgents) > 0 {
+		info = infoStyle.Render("Select an agent and press Enter to run/view, x to cancel a running agent")
+	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left, append(sections, "", info)...)
 
@@ -433,6 +553,19 @@ func (m model) renderSavedAgentWizard(width, height int) string {
 	return lipgloss.NewStyle().Padding(1, 2).Render(wizardStyle.Render(content))
 }
 
+// agentStatusLabel returns the history card tag and color for an agent run
+func agentStatusLabel(entry config.AgentInteraction) (string, lipgloss.Color) {
+	switch {
+	case entry.Status == config.AgentStatusCancelled:
+		return "CANCELLED", lipgloss.Color("208")
+	case entry.Status == config.AgentStatusTimedOut:
+		return "TIMEOUT", lipgloss.Color("208")
+	case !entry.Success:
+		return "FAIL", lipgloss.Color("196")
+	}
+	return "OK", lipgloss.Color("114")
+}
+
 // renderActiveAgentDetail renders the detail view for a running agent
 func (m model) renderActiveAgentDetail(width, height int) string {
 	if m.selectedAgentIdx >= len(m.activeAgents) {
@@ -455,7 +588,11 @@ func (m model) renderActiveAgentDetail(width, height int) string {
 	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("220")).Bold(true)
 
 	// Header
-	header := titleStyle.Render("⚡ "+agent.Name) + "  " + statusStyle.Render("● RUNNING")
+	status := "● RUNNING"
+	if agent.Status == "cancelling" {
+		status = "■ CANCELLING"
+	}
+	header := titleStyle.Render("⚡ "+agent.Name) + "  " + statusStyle.Render(status)
 
 	// Metadata
 	elapsed := time.Since(agent.StartTime).Round(time.Second)
@@ -465,13 +602,26 @@ func (m model) renderActiveAgentDetail(width, height int) string {
 		labelStyle.Render("Runtime:  ") + valueStyle.Render(agent.Runtime),
 		labelStyle.Render("Started:  ") + valueStyle.Render(agent.StartTime.Format("15:04:05")),
 		labelStyle.Render("Elapsed:  ") + valueStyle.Render(elapsed.String()),
+	}
+	if agent.Timeout > 0 {
+		metadata = append(metadata, labelStyle.Render("Timeout:  ")+valueStyle.Render(agent.Timeout.String()))
+	}
+	metadata = append(metadata,
 		"",
 		labelStyle.Render("Task:"),
-		"  " + valueStyle.Render(agent.Task),
+		"  "+valueStyle.Render(agent.Task),
+	)
+	if agent.Log != nil {
+		// Streamed output, as much of its end as fits
+		outputStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+		metadata = append(metadata, "", labelStyle.Render("Output:"))
+		for _, line := range agent.Log.tail(max(height-len(metadata)-8, 3)) {
+			metadata = append(metadata, "  "+outputStyle.Render(truncate(line, max(width-8, 10))))
+		}
 	}
 
 	helpStyle := lipgloss.NewStyle().Foreground(subtle).Italic(true)
-	help := helpStyle.Render("Press esc to return | Agent is still running...")
+	help := helpStyle.Render("Press esc to return | x to cancel | Agent is still running...")
 
 	content := lipgloss.JoinVertical(lipgloss.Left,
 		"",
@@ -512,7 +662,12 @@ func (m model) renderAgentDetail(width, height int) string {
 	statusIcon := "+"
 	statusColor := lipgloss.Color("114")
 	statusText := "Success"
-	if !entry.Success {
+	switch {
+	case entry.Status == config.AgentStatusCancelled:
+		statusIcon, statusColor, statusText = "■", lipgloss.Color("208"), "Cancelled"
+	case entry.Status == config.AgentStatusTimedOut:
+		statusIcon, statusColor, statusText = "⏱", lipgloss.Color("208"), "Timed out"
+	case !entry.Success:
 		statusIcon = "x"
 		statusColor = lipgloss.Color("196")
 		statusText = "Failed"
@@ -550,9 +705,7 @@ func (m model) renderAgentDetail(width, height int) string {
 	} else {
 		outputLines := strings.Split(entry.Output, "\n")
 		for _, line := range outputLines {
-			if len(line) > width-14 {
-				line = line[:width-17] + "..."
-			}
+			line = truncate(line, width-14)
 			allLines = append(allLines, "  "+valueStyle.Render(line))
 		}
 	}
@@ -607,7 +760,11 @@ func (m model) renderAgentDetail(width, height int) string {
 	hints := hintStyle.Render(
 		keyStyle.Render("j/k") + dimStyle.Render(" scroll  ") +
 			keyStyle.Render("esc") + dimStyle.Render(" back  ") +
-			keyStyle.Render("ctrl+y") + dimStyle.Render(" copy") + scrollInfo,
+			keyStyle.Render("ctrl+y") + dimStyle.Render(" copy output  ") +
+			keyStyle.Render("c") + dimStyle.Render(" copy markdown  ") +
+			keyStyle.Render("s") + dimStyle.Render(" save  ") +
+			keyStyle.Render("w") + dimStyle.Render(" webhook  ") +
+			keyStyle.Render("p") + dimStyle.Render(" gist") + scrollInfo,
 	)
 
 	visibleLines = append(visibleLines, "", hints)
@@ -630,43 +787,41 @@ func (m model) renderDashboard() string {
 	// Logo style
 	logoStyle := lipgloss.NewStyle().Foreground(primary)
 
-	// Crane with BIA bar underneath
+	// Crane with BIA bar underneath, or the configured logo
 	craneStyle := lipgloss.NewStyle().Foreground(primary)
 	biaYellow := lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
 	biaBlack := lipgloss.NewStyle().Foreground(lipgloss.Color("232")).Background(lipgloss.Color("220"))
 
-	crane := craneStyle.Render(`⣿⣿⣿⣿⣿⣿⣿⣿⣿⡿⠿⠿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿
-⣿⣿⣿⣿⣿⣿⡿⠟⠋⣁⡄⠀⢠⣄⣉⡙⠛⠿⢿⣿⣿⣿⣿⣿
-⣿⣿⣿⣿⠿⠛⣁⣤⣶⣿⠇⣤⠈⣿⣿⣿⣿⣶⣦⣄⣉⠙⠛⠿
-⣿⣿⣯⣤⣴⣿⣿⣿⣿⣿⣤⣿⣤⣽⣿⣿⣿⣿⣿⣿⣿⣿⣷⣦
-⣿⡇⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⠀⢸⣿
-⣿⣿⣿⡟⠛⠛⠛⣿⣿⣿⣿⡟⠛⢻⡟⠛⢻⣿⣿⣿⣿⣿⣿⣿
-⣿⣿⣿⣷⣶⣶⣶⣿⣿⣿⣿⣇⣀⣸⣇⣀⣼⣿⣿⣿⣿⣿⣿⣿
-⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⡏⠉⢹⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿
-⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⡇⠀⢸⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿
-⣿⣿⣿⣿⣿⣿⣿⣿⣿⣿⠿⡇⠀⢸⡿⣿⣿⣿⣿⠀⠀⠀⢸⣿
-⣿⣿⣿⣿⣿⣿⣿⡿⠋⣁⣴⡇⠀⢸⣷⣌⠙⢿⣿⣿⣿⣿⣿⣿
-⣿⣿⣿⣿⣿⣿⣿⣷⣾⣿⣿⣷⣤⣼⣿⣿⣿⣶⣿⣿⣿⣿⣿⣿`)
-
-	biaBar := biaYellow.Render("▟") + biaBlack.Bold(true).Render(" B I A ") + biaYellow.Render("▙")
-
-	biaLogo := lipgloss.JoinVertical(lipgloss.Center, crane, biaBar)
+	var biaLogo string
+	switch {
+	case m.banner.hideLogo:
+	case m.banner.logo != "":
+		biaLogo = craneStyle.Render(m.banner.logo)
+	default:
+		biaBar := biaYellow.Render("▟") + biaBlack.Bold(true).Render(" "+m.bannerBarText()+" ") + biaYellow.Render("▙")
+		biaLogo = lipgloss.JoinVertical(lipgloss.Center, craneStyle.Render(defaultLogo), biaBar)
+	}
 
 	// Clean block title
-	titleArt := logoStyle.Render(`█▀ █▄▀ █ ▀█▀ ▀█
-▄█ █ █ █  █  █▄`)
+	titleArt := defaultTitle
+	if m.banner.title != "" {
+		titleArt = m.banner.title
+	} else if m.config.Branding.Name != "" {
+		titleArt = m.brandName()
+		logoStyle = logoStyle.Bold(true)
+	}
 
 	// Styles
 	versionStyle := lipgloss.NewStyle().Foreground(subtle)
 	descStyle := lipgloss.NewStyle().Foreground(secondary).Italic(true)
 
 	// Animated quote with typewriter effect
-	quoteText := `"It is with us and in control"`
+	quoteText := []rune(m.banner.quoteText())
 	visibleChars := int(m.quotePos)
 	if visibleChars > len(quoteText) {
 		visibleChars = len(quoteText)
 	}
-	revealedQuote := quoteText[:visibleChars]
+	revealedQuote := string(quoteText[:visibleChars])
 
 	var paddedQuote string
 	if visibleChars < len(quoteText) {
@@ -687,16 +842,21 @@ func (m model) renderDashboard() string {
 		headerW = 60
 	}
 
+	var titleLines []string
+	if !m.banner.hideTitle {
+		titleLines = append(titleLines, logoStyle.Render(titleArt))
+	}
 	titleBlock := lipgloss.JoinVertical(lipgloss.Left,
-		titleArt,
-		versionStyle.Render("v0.1")+" "+descStyle.Render("Command Center"),
+		append(titleLines, versionStyle.Render("v0.1")+" "+descStyle.Render("Command Center"))...,
 	)
 
-	headerTop := lipgloss.JoinHorizontal(lipgloss.Center, biaLogo, "    ", titleBlock)
+	headerTop := titleBlock
+	if biaLogo != "" {
+		headerTop = lipgloss.JoinHorizontal(lipgloss.Center, biaLogo, "    ", titleBlock)
+	}
 
-	quoteBox := quoteStyle.Render(fmt.Sprintf(`╭──────────────────────────────────╮
-│  %s  │
-╰──────────────────────────────────╯`, paddedQuote))
+	quoteRule := strings.Repeat("─", lipgloss.Width(string(quoteText))+4)
+	quoteBox := quoteStyle.Render(fmt.Sprintf("╭%s╮\n│  %s  │\n╰%s╯", quoteRule, paddedQuote, quoteRule))
 
 	borderStyle := lipgloss.NewStyle().Foreground(dimBorder)
 
@@ -753,17 +913,20 @@ func (m model) renderDashboard() string {
 		paletteDescStyle.Render("  ctrl+k to open"),
 	)
 
-	if len(m.config.Favorites) > 0 {
+	var favorites []string
+	for _, ref := range m.config.MCP.Favorites {
+		favorites = append(favorites, "⚡"+ref)
+	}
+	favorites = append(favorites, m.config.Favorites...)
+	if len(favorites) > 0 {
 		sidebarLines = append(sidebarLines, "", actionsTitleStyle.Render("⭐ Favorites"))
-		for i, fav := range m.config.Favorites {
+		for i, fav := range favorites {
 			if i >= 3 {
-				sidebarLines = append(sidebarLines, actionDimStyle.Render(fmt.Sprintf("  +%d more", len(m.config.Favorites)-3)))
+				sidebarLines = append(sidebarLines, actionDimStyle.Render(fmt.Sprintf("  +%d more", len(favorites)-3)))
 				break
 			}
 			favDisplay := fav
-			if len(favDisplay) > 18 {
-				favDisplay = favDisplay[:15] + "..."
-			}
+			favDisplay = truncate(favDisplay, 18)
 			sidebarLines = append(sidebarLines, actionItemStyle.Render("  "+favDisplay))
 		}
 	}
@@ -788,9 +951,7 @@ func (m model) renderDashboard() string {
 			}
 
 			name := p.Name
-			if len(name) > 16 {
-				name = name[:13] + "..."
-			}
+			name = truncate(name, 16)
 			sidebarLines = append(sidebarLines, statusStyle.Render(fmt.Sprintf("  %s %s", icon, name)))
 		}
 
@@ -817,9 +978,7 @@ func (m model) renderDashboard() string {
 			entry := m.agentHistory[i]
 			timeAgo := formatTimeAgo(entry.Timestamp)
 			actionDisplay := entry.Action
-			if len(actionDisplay) > 12 {
-				actionDisplay = actionDisplay[:10] + ".."
-			}
+			actionDisplay = truncateWith(actionDisplay, 12, "..")
 
 			statusIcon := "✓"
 			if !entry.Success {
@@ -832,7 +991,7 @@ func (m model) renderDashboard() string {
 		}
 	}
 
-	if m.config.MCP.Enabled {
+	if m.config.EffectiveMCP().Enabled {
 		sidebarLines = append(sidebarLines, "", actionsTitleStyle.Render("🧩 MCP Connections"))
 		if len(m.mcpStatus) == 0 {
 			sidebarLines = append(sidebarLines, actionDimStyle.Render("  No MCP data"))
@@ -872,10 +1031,15 @@ func (m model) renderDashboard() string {
 				statusIcon := "✗"
 				statusColor := lipgloss.Color("196")
 				statusLabel := "disconnected"
-				if status.Connected {
+				switch {
+				case status.Connected:
 					statusIcon = "✓"
 					statusColor = lipgloss.Color("114")
 					statusLabel = "connected"
+				case status.Degraded:
+					statusIcon = "◐"
+					statusColor = lipgloss.Color("214")
+					statusLabel = "degraded"
 				}
 
 				statusStyle := lipgloss.NewStyle().Foreground(statusColor)
@@ -885,9 +1049,15 @@ func (m model) renderDashboard() string {
 				}
 
 				if status.Error != "" {
-					errLine := truncate(status.Error, maxLineLen-6)
-					sidebarLines = append(sidebarLines, actionDimStyle.Render("    "+errLine))
-					continue
+					errLine := status.Error
+					if status.ErrorKind != "" {
+						errLine = string(status.ErrorKind) + " error: " + errLine
+					}
+					sidebarLines = append(sidebarLines, actionDimStyle.Render("    "+truncate(errLine, maxLineLen-6)))
+					// A degraded server still shows its last known lists
+					if !status.Degraded {
+						continue
+					}
 				}
 
 				appendList("Tools", status.Tools, status.ToolsError)
@@ -896,6 +1066,11 @@ func (m model) renderDashboard() string {
 				appendList("Templates", status.ResourceTemplates, status.ResourceTemplatesError)
 			}
 		}
+		for _, server := range m.config.EffectiveMCP().Servers {
+			if server.Disabled {
+				sidebarLines = append(sidebarLines, actionDimStyle.Render("  ○ "+truncate(server.Name, maxLineLen-6)+" disabled"))
+			}
+		}
 	}
 
 	sidebarLines = append(sidebarLines, "", actionsTitleStyle.Render("⏱ Recent"))
@@ -911,9 +1086,7 @@ func (m model) renderDashboard() string {
 		for i := 0; i < displayCount; i++ {
 			entry := m.history[i]
 			cmdDisplay := entry.Command
-			if len(cmdDisplay) > 18 {
-				cmdDisplay = cmdDisplay[:15] + "..."
-			}
+			cmdDisplay = truncate(cmdDisplay, 18)
 			if entry.Tool != "" {
 				sidebarLines = append(sidebarLines, actionDimStyle.Render(fmt.Sprintf("  [%s] %s", entry.Tool[:1], cmdDisplay)))
 			} else {
@@ -953,25 +1126,7 @@ func (m model) renderDashboard() string {
 		bottomBorder,
 	)
 
-	// Convert resources to CardItems
-	var resourceItems []CardItem
-	for i, res := range m.resources {
-		meta := toolMetadata[res.name]
-		borderColor := dimBorder
-		if meta.status == "coming_soon" {
-			borderColor = lipgloss.Color("238")
-		}
-		resourceItems = append(resourceItems, CardItem{
-			Title:       strings.ToUpper(res.name),
-			Subtitle:    res.description,
-			Tag:         meta.category,
-			TagColor:    meta.color,
-			BorderColor: borderColor,
-			Shortcut:    i + 1,
-		})
-	}
-
-	cardGrid := CardGrid(resourceItems, mainAreaW, m.resCursor)
+	cardGrid := m.renderResourceGroups(mainAreaW)
 
 	// Render tab bar
 	tabBar := m.renderDashboardTabs(mainAreaW)
@@ -989,6 +1144,12 @@ func (m model) renderDashboard() string {
 	case 2:
 		// Agents tab - show agent history and active agents
 		tabContent = m.renderAgentsTab(mainAreaW, remainingH)
+	case deploymentsTab:
+		// Deployments tab - show tracked cloud deployments
+		tabContent = m.renderDeploymentsTab(mainAreaW, remainingH)
+	case pluginsTab:
+		// Plugins tab - show plugin panels
+		tabContent = m.renderPluginsTab(mainAreaW, remainingH)
 	}
 
 	rightContent := lipgloss.JoinVertical(lipgloss.Left, header, tabBar, tabContent)
@@ -1033,8 +1194,18 @@ func (m model) renderDashboard() string {
 	return body
 }
 
-// renderCommandList renders an interactive command list with selection highlighting.
-func (m model) renderCommandList(width int, accentColor lipgloss.Color) string {
+// renderCommandList renders an interactive command list with selection
+// highlighting, laid out by layoutCommandList. Only rows on lines top to
+// bottom are drawn; the rest are blank lines of the same height, so long
+// lists stay fast to redraw. Drawn rows are cached in cmdRowCache.
+func (m model) renderCommandList(accentColor lipgloss.Color, top, bottom int) string {
+	if len(m.commands) == 0 && m.tagFilter != "" {
+		return lipgloss.NewStyle().
+			Foreground(subtle).
+			Italic(true).
+			Padding(2, 4).
+			Render("No commands tagged #" + m.tagFilter + " in this section (t to change filter)")
+	}
 	if len(m.commands) == 0 {
 		return lipgloss.NewStyle().
 			Foreground(subtle).
@@ -1042,61 +1213,125 @@ func (m model) renderCommandList(width int, accentColor lipgloss.Color) string {
 			Padding(2, 4).
 			Render("No runnable commands in this section")
 	}
+	l := m.cmdLayout
 
 	// Header block
 	headerLabel := lipgloss.NewStyle().Foreground(accentColor).Bold(true).Render("COMMANDS")
 	headerCount := lipgloss.NewStyle().Foreground(subtle).Render(fmt.Sprintf("  %d available", len(m.commands)))
-	divider := lipgloss.NewStyle().Foreground(lipgloss.Color("238")).Render(strings.Repeat("─", width-6))
+	if m.tagFilter != "" {
+		headerCount += lipgloss.NewStyle().Foreground(subtle).Render("  filtered by ") + renderTagChip(m.tagFilter)
+	}
+	if m.sortByUsage {
+		headerCount += lipgloss.NewStyle().Foreground(subtle).Render("  most used first")
+	}
+	divider := lipgloss.NewStyle().Foreground(lipgloss.Color("238")).Render(strings.Repeat("─", l.width-6))
 	header := lipgloss.NewStyle().PaddingLeft(2).MarginBottom(1).Render(
 		lipgloss.JoinVertical(lipgloss.Left, headerLabel+headerCount, divider),
 	)
 
-	// Column widths
-	prefixW := 8 // " ▶  1  " or "     1  "
-	sepW := 3    // " │ "
-	availableW := width - prefixW - sepW - 4
-	cmdW := (availableW * 55) / 100
-	descW := availableW - cmdW
-	if cmdW < 28 {
-		cmdW = 28
+	// Command rows
+	var rows []string
+	for i := range m.commands {
+		start, height := l.starts[i], l.heights[i]
+		if start+height <= top || start >= bottom {
+			rows = append(rows, make([]string, height)...)
+			continue
+		}
+		key := cmdRowKey{index: i, selected: i == m.cmdCursor, width: l.width}
+		lines, ok := m.cmdRowCache[key]
+		if !ok {
+			lines = m.renderCommandRow(i, key.selected, accentColor)
+			if m.cmdRowCache != nil {
+				m.cmdRowCache[key] = lines
+			}
+		}
+		rows = append(rows, lines...)
 	}
-	if descW < 12 {
-		descW = 12
+
+	commandBlock := lipgloss.NewStyle().MarginTop(1).Render(strings.Join(rows, "\n"))
+
+	return lipgloss.JoinVertical(lipgloss.Left, header, commandBlock)
+}
+
+// renderCommandRow renders command i as its layout height in lines, one
+// per wrapped line of the command or description
+func (m model) renderCommandRow(i int, isSelected bool, accentColor lipgloss.Color) []string {
+	l := m.cmdLayout
+	width := l.width
+	cmd := m.commands[i]
+
+	locked := m.commandLocked(cmd)
+	cmdLines := wrapLines(highlightShellCommand(cmd.raw), l.cmdW)
+	if locked {
+		cmdLines = wrapLines(cmd.raw, l.cmdW)
 	}
+	descLines := wrapLines(cmd.description, l.descW)
 
-	// Command rows
-	var rows []string
-	for i, cmd := range m.commands {
-		isSelected := i == m.cmdCursor
+	var inputBadge string
+	if cmd.inputVar != "" {
+		inputBadge = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("213")).
+			Render(" {{" + cmd.inputVar + "}}")
+	}
 
-		cmdText := cmd.raw
-		if len(cmdText) > cmdW-2 {
-			cmdText = cmdText[:cmdW-5] + "..."
-		}
-		descText := cmd.description
-		if len(descText) > descW-2 {
-			descText = descText[:descW-5] + "..."
+	var usageBadge string
+	if uses := m.commandUses(cmd); uses > 0 {
+		usageBadge = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("243")).
+			Render(fmt.Sprintf(" ×%d", uses))
+	}
+	badges := inputBadge + usageBadge
+
+	var tagChips string
+	for _, t := range cmd.tags {
+		tagChips += " " + renderTagChip(t)
+	}
+	if locked {
+		tagChips += " " + renderRequiresChip(cmd, m.config.Capabilities)
+	}
+
+	// Commands the user lacks the capabilities for are dimmed
+	dim := func(s lipgloss.Style) lipgloss.Style {
+		if locked {
+			return s.Foreground(lipgloss.Color("240"))
 		}
+		return s
+	}
 
-		highlighted := highlightShellCommand(cmdText)
-		cmdPad := max(0, cmdW-lipgloss.Width(highlighted))
+	n := l.heights[i]
+	rows := make([]string, 0, n)
+	for j := range n {
+		var cmdText, descText string
+		if j < len(cmdLines) {
+			cmdText = cmdLines[j]
+		}
+		if j < len(descLines) {
+			descText = descLines[j]
+		}
+		cmdPad := max(0, l.cmdW-lipgloss.Width(cmdText))
 
-		var inputBadge string
-		if cmd.inputVar != "" {
-			inputBadge = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("213")).
-				Render(" {{" + cmd.inputVar + "}}")
+		// Continuation lines keep the description in its column
+		rowBadges := badges
+		if j > 0 {
+			rowBadges = strings.Repeat(" ", lipgloss.Width(badges))
+		}
+		chips := ""
+		if j == n-1 {
+			chips = tagChips
 		}
 
 		if isSelected {
 			arrow := lipgloss.NewStyle().Foreground(accentColor).Bold(true).Render(" ▶ ")
 			num := lipgloss.NewStyle().Foreground(accentColor).Bold(true).Render(fmt.Sprintf("%-3d", i+1))
+			if j > 0 {
+				arrow, num = "   ", "   "
+			}
 			sep := lipgloss.NewStyle().Foreground(accentColor).Render(" │ ")
-			cmdStyled := lipgloss.NewStyle().Background(lipgloss.Color("239")).Bold(true).
-				Render(" " + highlighted + strings.Repeat(" ", cmdPad) + " ")
-			desc := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true).Render(descText)
+			cmdStyled := dim(lipgloss.NewStyle().Background(lipgloss.Color("239")).Bold(true)).
+				Render(" " + cmdText + strings.Repeat(" ", cmdPad) + " ")
+			desc := dim(lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true)).Render(descText)
 
-			row := arrow + num + sep + cmdStyled + inputBadge + "  " + desc
+			row := arrow + num + sep + cmdStyled + rowBadges + "  " + desc + chips
 			rowW := lipgloss.Width(row)
 			if rowW < width-3 {
 				row += strings.Repeat(" ", width-3-rowW)
@@ -1106,18 +1341,18 @@ func (m model) renderCommandList(width int, accentColor lipgloss.Color) string {
 			rows = append(rows, bar+lipgloss.NewStyle().Background(lipgloss.Color("236")).Render(row))
 		} else {
 			num := lipgloss.NewStyle().Foreground(subtle).Render(fmt.Sprintf("     %-3d", i+1))
+			if j > 0 {
+				num = strings.Repeat(" ", 8)
+			}
 			sep := lipgloss.NewStyle().Foreground(lipgloss.Color("238")).Render(" │ ")
-			cmdStyled := lipgloss.NewStyle().Background(lipgloss.Color("235")).
-				Render(" " + highlighted + strings.Repeat(" ", cmdPad) + " ")
-			desc := lipgloss.NewStyle().Foreground(subtle).Render(descText)
+			cmdStyled := dim(lipgloss.NewStyle().Background(lipgloss.Color("235"))).
+				Render(" " + cmdText + strings.Repeat(" ", cmdPad) + " ")
+			desc := dim(lipgloss.NewStyle().Foreground(subtle)).Render(descText)
 
-			rows = append(rows, " "+num+sep+cmdStyled+inputBadge+"  "+desc)
+			rows = append(rows, " "+num+sep+cmdStyled+rowBadges+"  "+desc+chips)
 		}
 	}
-
-	commandBlock := lipgloss.NewStyle().MarginTop(1).Render(strings.Join(rows, "\n"))
-
-	return lipgloss.JoinVertical(lipgloss.Left, header, commandBlock)
+	return rows
 }
 
 // renderResourceView renders the full-screen resource view
@@ -1135,9 +1370,7 @@ func (m model) renderResourceView() string {
 
 	for i, s := range res.sections {
 		title := s.title
-		if len(title) > 14 {
-			title = title[:12] + ".."
-		}
+		title = truncateWith(title, 14, "..")
 
 		var label string
 		if i < 9 {
@@ -1145,7 +1378,7 @@ func (m model) renderResourceView() string {
 		} else {
 			label = fmt.Sprintf("  %s  ", title)
 		}
-		labelW := len(label)
+		labelW := lipgloss.Width(label)
 
 		if i == m.secCursor {
 			topBorder := lipgloss.NewStyle().
@@ -1239,6 +1472,7 @@ func (m model) renderResourceView() string {
 			textStyle.Render(" commands  ") +
 			keyStyle.Render("↑↓") + textStyle.Render(" select  ") +
 			keyStyle.Render("enter") + textStyle.Render(" run  ") +
+			keyStyle.Render("e") + textStyle.Render(" edit  ") +
 			keyStyle.Render("ctrl+y") + textStyle.Render(" copy")
 
 		infoBar = infoBg.Width(viewW).Padding(0, 1).Render(infoContent)
@@ -1273,6 +1507,34 @@ func (m model) renderResourceView() string {
 			infoBar,
 			askPanelView,
 		)
+	} else if m.cmdEditor != nil {
+		view = lipgloss.JoinVertical(lipgloss.Left,
+			tabBar,
+			accentLine,
+			infoBar,
+			m.renderCommandEditor(viewW),
+		)
+	} else if m.pager != nil {
+		view = lipgloss.JoinVertical(lipgloss.Left,
+			tabBar,
+			accentLine,
+			infoBar,
+			m.renderPager(viewW),
+		)
+	} else if m.tableView != nil {
+		view = lipgloss.JoinVertical(lipgloss.Left,
+			tabBar,
+			accentLine,
+			infoBar,
+			m.renderTableView(viewW),
+		)
+	} else if m.kubeSwitcher != nil {
+		view = lipgloss.JoinVertical(lipgloss.Left,
+			tabBar,
+			accentLine,
+			infoBar,
+			m.renderKubeSwitcher(viewW),
+		)
 	} else if m.term.active {
 		termPane := m.renderTerminalPane()
 		view = lipgloss.JoinVertical(lipgloss.Left,
@@ -1328,9 +1590,23 @@ func (m model) renderAskPanel(width int) string {
 	var lines []string
 
 	// Title
-	lines = append(lines, titleStyle.Render("◈ Ask AI about "+m.currentResource().name))
+	subject := m.askPanel.Subject
+	if subject == "" {
+		if res := m.currentResource(); res != nil {
+			subject = res.name
+		}
+	}
+	lines = append(lines, titleStyle.Render("◈ Ask AI about "+subject))
+	for _, a := range m.askPanel.Contexts {
+		lines = append(lines, hintStyle.Render("📎 "+a.Label+" ("+formatAttachmentSize(a)+")"))
+	}
 	lines = append(lines, "")
 
+	if m.askPanel.ContextForm != nil {
+		lines = append(lines, m.askPanel.ContextForm.View())
+		return panelStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+
 	// Input field
 	inputContent := m.askPanel.Input
 	if m.askPanel.Loading {
@@ -1354,6 +1630,9 @@ func (m model) renderAskPanel(width int) string {
 			Foreground(lipgloss.Color("252")).
 			Width(width - 12)
 		lines = append(lines, responseStyle.Render(m.askPanel.Response))
+		if len(m.askPanel.Sources) > 0 {
+			lines = append(lines, hintStyle.Render("from "+strings.Join(m.askPanel.Sources, ", ")))
+		}
 
 		// Show generated command if available
 		if m.askPanel.GeneratedCmd != "" {
@@ -1365,9 +1644,20 @@ func (m model) renderAskPanel(width int) string {
 				Padding(0, 1)
 			lines = append(lines, cmdStyle.Render("$ "+m.askPanel.GeneratedCmd))
 			lines = append(lines, "")
-			lines = append(lines,
-				keyHintStyle.Render("ctrl+r")+hintStyle.Render(" run  ")+
-					keyHintStyle.Render("ctrl+a")+hintStyle.Render(" add to resource"))
+			if m.askPanel.SectionForm != nil {
+				lines = append(lines, m.askPanel.SectionForm.View())
+			} else if m.askPanel.PendingApproval != "" {
+				warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+				lines = append(lines,
+					warnStyle.Render("⚠ Needs approval: "+m.askPanel.PendingApproval),
+					keyHintStyle.Render("y")+hintStyle.Render(" approve  ")+
+						keyHintStyle.Render("n")+hintStyle.Render(" cancel"))
+			} else {
+				lines = append(lines,
+					keyHintStyle.Render("ctrl+r")+hintStyle.Render(" run  ")+
+						keyHintStyle.Render("ctrl+t")+hintStyle.Render(" edit in shell  ")+
+						keyHintStyle.Render("ctrl+a")+hintStyle.Render(" add to resource"))
+			}
 		}
 	}
 
@@ -1377,6 +1667,7 @@ func (m model) renderAskPanel(width int) string {
 	lines = append(lines,
 		keyHintStyle.Render("enter")+hintStyle.Render(" ask  ")+
 			keyHintStyle.Render("ctrl+g")+hintStyle.Render(" generate cmd  ")+
+			keyHintStyle.Render("ctrl+o")+hintStyle.Render(" attach  ")+
 			keyHintStyle.Render("esc")+hintStyle.Render(" close"))
 
 	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
@@ -1396,7 +1687,7 @@ func (m model) renderStatusBar() string {
 		Background(lipgloss.Color("236")).
 		Foreground(lipgloss.Color("240"))
 	brandStyleSB := lipgloss.NewStyle().
-		Background(lipgloss.Color("99")).
+		Background(primary).
 		Foreground(lipgloss.Color("255")).
 		Bold(true).
 		Padding(0, 1)
@@ -1410,17 +1701,20 @@ func (m model) renderStatusBar() string {
 	var leftContent, rightContent string
 
 	if m.currentView == viewDashboard {
-		tabNames := []string{"Resources", "Actions", "Agents"}
-		tabName := tabNames[m.dashboardTab]
-		leftContent = brandStyleSB.Render("SKITZ") + bgStyle.Render("  ") +
+		tabName := dashboardTabNames[m.dashboardTab]
+		leftContent = brandStyleSB.Render(m.brandName()) + bgStyle.Render("  ") +
 			contextStyle.Render("Dashboard › "+tabName)
 
 		rightContent = keyStyle.Render("tab") + descStyle.Render(" switch") + sep +
 			keyStyle.Render("ctrl+k") + descStyle.Render(" palette") + sep +
 			keyStyle.Render("↑↓") + descStyle.Render(" nav") + sep +
+			keyStyle.Render("←→") + descStyle.Render(" group") + sep +
+			keyStyle.Render("c") + descStyle.Render(" collapse") + sep +
 			keyStyle.Render("e") + descStyle.Render(" edit") + sep +
 			keyStyle.Render("d") + descStyle.Render(" delete") + sep +
+			keyStyle.Render("R") + descStyle.Render(" refresh MCP") + sep +
 			keyStyle.Render("enter") + descStyle.Render(" open") + sep +
+			keyStyle.Render("?") + descStyle.Render(" help") + sep +
 			keyStyle.Render("q") + descStyle.Render(" quit")
 	} else {
 		res := m.currentResource()
@@ -1437,6 +1731,12 @@ func (m model) renderStatusBar() string {
 			if sec != nil {
 				breadcrumb += bgStyle.Render("  ") + contextStyle.Render(sec.title)
 			}
+			if target := execTargetLabel(m.execTarget()); target != "" {
+				breadcrumb += bgStyle.Render("  ") + contextStyle.Render("🐳 "+target)
+			}
+			if isKubeResource(res) {
+				breadcrumb += bgStyle.Render("  ") + contextStyle.Render(m.kubeLabel())
+			}
 		}
 
 		leftContent = breadcrumb
@@ -1444,9 +1744,37 @@ func (m model) renderStatusBar() string {
 		rightContent = keyStyle.Render("a") + descStyle.Render(" ask AI") + sep +
 			keyStyle.Render("↑↓") + descStyle.Render(" select") + sep +
 			keyStyle.Render("enter") + descStyle.Render(" run") + sep +
+			keyStyle.Render("t") + descStyle.Render(" tags") + sep +
+			keyStyle.Render("s") + descStyle.Render(" most used") + sep +
+			keyStyle.Render("c") + descStyle.Render(" examples") + sep
+		if hasExecTarget(res) {
+			rightContent += keyStyle.Render("x") + descStyle.Render(" target") + sep
+		}
+		rightContent += keyStyle.Render("?") + descStyle.Render(" help") + sep +
 			keyStyle.Render("esc") + descStyle.Render(" back")
 	}
 
+	if m.offline {
+		leftContent += bgStyle.Render("  ") + contextStyle.Render("✈ offline")
+	}
+	if m.count != "" {
+		leftContent += bgStyle.Render("  ") + keyStyle.Render(m.count)
+	}
+	if m.lastRun != nil {
+		resultColor := lipgloss.Color("114")
+		if m.lastRun.exitCode != 0 {
+			resultColor = lipgloss.Color("196")
+		}
+		leftContent += bgStyle.Render("  ") + bgStyle.Foreground(resultColor).Render(m.lastRun.label())
+	}
+	if m.lastAIProvider != "" {
+		label := "◈ " + m.lastAIProvider
+		if m.lastAIFailedOver {
+			label += " ↪"
+		}
+		leftContent += bgStyle.Render("  ") + contextStyle.Render(label)
+	}
+
 	leftW := lipgloss.Width(leftContent)
 	rightW := lipgloss.Width(rightContent)
 	padW := m.width - leftW - rightW - 2
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/webhook"
+)
+
+// webhookErrorMsg reports a webhook that could not be posted to
+type webhookErrorMsg struct {
+	name string
+	err  error
+}
+
+// deployFinishedMsg carries the summary of a deployment attempted in the
+// Deploy Agent wizard
+type deployFinishedMsg struct {
+	summary webhook.Summary
+}
+
+// agentSummary describes a finished agent run for webhooks
+func agentSummary(agent ActiveAgent, msg agentCompletedMsg) webhook.Summary {
+	status := strings.ReplaceAll(msg.status, "_", " ")
+	if status == "" {
+		status = "failed"
+		if msg.success {
+			status = "succeeded"
+		}
+	}
+	return webhook.Summary{
+		Event:    webhook.EventAgent,
+		Title:    "Agent " + agent.Name,
+		Task:     agent.Task,
+		Success:  msg.success,
+		Status:   status,
+		Duration: time.Duration(msg.duration) * time.Millisecond,
+		Output:   msg.output,
+		Details: []webhook.Detail{
+			{Name: "Runtime", Value: agent.Runtime},
+			{Name: "Provider", Value: agent.Provider},
+		},
+	}
+}
+
+// postWebhooks posts s to every configured webhook that wants it, in the
+// background
+func (m *model) postWebhooks(s webhook.Summary) tea.Cmd {
+	s.User = webhook.CurrentUser()
+
+	var cmds []tea.Cmd
+	for i, hook := range m.config.Integrations.Webhooks {
+		if !webhook.Wants(hook, s) {
+			continue
+		}
+		name := hook.Name
+		if name == "" {
+			name = fmt.Sprintf("webhook %d", i+1)
+		}
+		cmds = append(cmds, func() tea.Msg {
+			if err := webhook.Post(context.Background(), hook, s); err != nil {
+				return webhookErrorMsg{name: name, err: err}
+			}
+			slog.Debug("webhook posted", "webhook", name, "event", s.Event)
+			return nil
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleWebhookError logs a failed post and tells the user
+func (m *model) handleWebhookError(msg webhookErrorMsg) tea.Cmd {
+	slog.Warn("webhook failed", "webhook", msg.name, "error", msg.err)
+	return m.showNotification("📡", fmt.Sprintf("%s: %v", msg.name, msg.err), "warning")
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestPostWebhooksForAgent(t *testing.T) {
+	var posts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+	}))
+	defer srv.Close()
+
+	var m model
+	m.config.Integrations.Webhooks = []config.WebhookConfig{
+		{Name: "on-call", URL: srv.URL, Events: []string{"agent"}},
+		{Name: "deploys", URL: srv.URL, Events: []string{"deploy"}},
+		{Name: "failures", URL: srv.URL, OnlyFailures: true},
+	}
+
+	agent := ActiveAgent{Name: "reviewer", Task: "Review PR", Runtime: "docker"}
+	s := agentSummary(agent, agentCompletedMsg{success: true, duration: 1500, status: config.AgentStatusCompleted})
+	if s.Status != "completed" || s.Duration.Seconds() != 1.5 {
+		t.Errorf("summary = %+v", s)
+	}
+
+	cmd := m.postWebhooks(s)
+	if cmd == nil {
+		t.Fatal("expected a post")
+	}
+	// A single matching webhook is returned as is rather than batched
+	if msg := cmd(); msg != nil {
+		if _, ok := msg.(tea.BatchMsg); ok {
+			t.Fatalf("posted to %d webhooks, want 1", len(msg.(tea.BatchMsg)))
+		}
+		t.Fatalf("unexpected message %#v", msg)
+	}
+	if posts.Load() != 1 {
+		t.Errorf("got %d posts, want 1", posts.Load())
+	}
+}
@@ -1,11 +1,14 @@
 package app
 
 import (
+	"cmp"
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +20,8 @@ import (
 
 	"github.com/htelsiz/skitz/internal/ai"
 	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/features"
+	runtimepkg "github.com/htelsiz/skitz/internal/runtime"
 )
 
 func boolToOnOff(b bool) string {
@@ -68,6 +73,7 @@ func (m *model) buildAddResourceForm() tea.Cmd {
 						huh.NewOption("Blank - Empty resource file", "blank"),
 						huh.NewOption("Commands - Basic command structure", "commands"),
 						huh.NewOption("Detailed - Full sections layout", "detailed"),
+						huh.NewOption("Community - Examples from tldr or cheat.sh", "community"),
 					).
 					Value(&wizard.Template),
 			),
@@ -123,6 +129,15 @@ func (m *model) createResourceFile() tea.Cmd {
 	name = strings.ToLower(name)
 	name = strings.ReplaceAll(name, " ", "-")
 
+	if wizard.Template == "community" {
+		m.addResourceWizard = nil
+		m.dashboardTab = 0
+		return tea.Batch(
+			m.showNotification("🌐", "Fetching community examples for "+name, "info"),
+			fetchCommunityExamplesCmd(name, true),
+		)
+	}
+
 	var content string
 	switch wizard.Template {
 	case "commands":
@@ -158,11 +173,43 @@ func (m *model) editPreferences() tea.Cmd {
 		HistoryMaxItems:     fmt.Sprintf("%d", m.config.History.MaxItems),
 		HistoryDisplayCount: fmt.Sprintf("%d", m.config.History.DisplayCount),
 		MCPEnabled:          m.config.MCP.Enabled,
+		Shell:               m.config.Execution.Shell,
+		LoginShell:          m.config.Execution.LoginShell,
 		Editor:              os.Getenv("EDITOR"),
 	}
 	return m.buildPreferencesForm()
 }
 
+// policyLockOptions lists the settings the organization policy enforces as
+// preference options, which explain the lock when selected
+func (m *model) policyLockOptions() []huh.Option[string] {
+	p := m.config.Policy
+	var options []huh.Option[string]
+	locked := func(label, setting string) {
+		options = append(options, huh.NewOption("🔒 "+label+" (locked by policy)", "locked:"+setting))
+	}
+	if p.Locked("ai") {
+		locked("AI off", "ai")
+	}
+	if p.Locked("mcp.enabled") {
+		locked("MCP "+boolToOnOff(m.config.EffectiveMCP().Enabled), "mcp.enabled")
+	}
+	if p.Locked("mcp.servers") {
+		locked("MCP servers limited to "+strings.Join(p.MCP.AllowedServers, ", "), "mcp.servers")
+	}
+	if p.Locked("telemetry.endpoint") {
+		locked("Telemetry sent to "+p.Telemetry.Endpoint, "telemetry.endpoint")
+	}
+	if p.Locked("audit") {
+		label := "Audit log " + boolToOnOff(p.Audit.Enabled)
+		if p.Audit.Enabled && p.Audit.Sink != "" {
+			label += " (" + p.Audit.Sink + ")"
+		}
+		locked(label, "audit")
+	}
+	return options
+}
+
 func (m *model) buildPreferencesForm() tea.Cmd {
 	wizard := m.preferencesWizard
 	if wizard == nil {
@@ -171,16 +218,22 @@ func (m *model) buildPreferencesForm() tea.Cmd {
 
 	switch wizard.Step {
 	case 0:
+		description := "What would you like to configure?"
+		if m.config.Policy.Active() {
+			description += "\n🔒 Some settings are managed by " + config.PolicyPath
+		}
+		options := []huh.Option[string]{
+			huh.NewOption("History Settings", "history"),
+			huh.NewOption("MCP Servers", "mcp"),
+			huh.NewOption("Execution Shell", "execution"),
+			huh.NewOption("Edit Config File", "editor"),
+		}
 		wizard.InputForm = huh.NewForm(
 			huh.NewGroup(
 				huh.NewSelect[string]().
 					Title("Preferences").
-					Description("What would you like to configure?").
-					Options(
-						huh.NewOption("History Settings", "history"),
-						huh.NewOption("MCP Servers", "mcp"),
-						huh.NewOption("Edit Config File", "editor"),
-					).
+					Description(description).
+					Options(append(options, m.policyLockOptions()...)...).
 					Value(&wizard.Section),
 			),
 		).
@@ -215,12 +268,24 @@ func (m *model) buildPreferencesForm() tea.Cmd {
 
 		case "mcp":
 			var serverOptions []huh.Option[string]
-			serverOptions = append(serverOptions, huh.NewOption("Add New Server", "add"))
+			if m.config.Policy.Locked("mcp.servers") {
+				serverOptions = append(serverOptions, huh.NewOption("Add New Server (🔒 only "+strings.Join(m.config.Policy.MCP.AllowedServers, ", ")+")", "add"))
+			} else {
+				serverOptions = append(serverOptions, huh.NewOption("Add New Server", "add"))
+			}
 			for _, srv := range m.config.MCP.Servers {
-				serverOptions = append(serverOptions, huh.NewOption("Edit: "+srv.Name, "edit:"+srv.Name))
-				serverOptions = append(serverOptions, huh.NewOption("Remove: "+srv.Name, "remove:"+srv.Name))
+				label := srv.Name
+				if !m.config.Policy.MCPServerAllowed(srv.URL) {
+					label += " (🔒 blocked by policy)"
+				}
+				serverOptions = append(serverOptions, huh.NewOption("Edit: "+label, "edit:"+srv.Name))
+				serverOptions = append(serverOptions, huh.NewOption("Remove: "+label, "remove:"+srv.Name))
+			}
+			if m.config.Policy.Locked("mcp.enabled") {
+				serverOptions = append(serverOptions, huh.NewOption("🔒 MCP "+boolToOnOff(m.config.EffectiveMCP().Enabled)+" (locked by policy)", "locked"))
+			} else {
+				serverOptions = append(serverOptions, huh.NewOption("Toggle MCP (currently "+boolToOnOff(wizard.MCPEnabled)+")", "toggle"))
 			}
-			serverOptions = append(serverOptions, huh.NewOption("Toggle MCP (currently "+boolToOnOff(wizard.MCPEnabled)+")", "toggle"))
 
 			wizard.InputForm = huh.NewForm(
 				huh.NewGroup(
@@ -236,6 +301,25 @@ func (m *model) buildPreferencesForm() tea.Cmd {
 				WithTheme(huh.ThemeCatppuccin())
 			return wizard.InputForm.Init()
 
+		case "execution":
+			wizard.InputForm = huh.NewForm(
+				huh.NewGroup(
+					huh.NewInput().
+						Title("Shell").
+						Description("Shell for ^run commands (empty uses $SHELL)").
+						Placeholder(os.Getenv("SHELL")).
+						Value(&wizard.Shell),
+					huh.NewConfirm().
+						Title("Login Shell").
+						Description("Load rc files so PATH changes and aliases are available").
+						Value(&wizard.LoginShell),
+				),
+			).
+				WithWidth(80).
+				WithShowHelp(true).
+				WithTheme(huh.ThemeCatppuccin())
+			return wizard.InputForm.Init()
+
 		case "editor":
 			m.preferencesWizard = nil
 			return m.openConfigInEditor()
@@ -254,6 +338,21 @@ func (m *model) buildPreferencesForm() tea.Cmd {
 					Description("The MCP server endpoint").
 					Placeholder("http://localhost:8001/mcp/").
 					Value(&wizard.MCPURL),
+				huh.NewInput().
+					Title("Refresh Interval").
+					Description("Seconds between status refreshes; empty for the global setting").
+					Placeholder(strconv.Itoa(m.config.MCP.RefreshSeconds)).
+					Value(&wizard.MCPRefresh).
+					Validate(optional(func(s string) error {
+						if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+							return fmt.Errorf("enter a positive number of seconds")
+						}
+						return nil
+					})),
+				huh.NewConfirm().
+					Title("Enabled").
+					Description("Disabled servers are kept but not polled").
+					Value(&wizard.MCPPoll),
 			),
 		).
 			WithWidth(80).
@@ -273,6 +372,10 @@ func (m *model) nextPreferencesStep() tea.Cmd {
 
 	switch wizard.Step {
 	case 0:
+		if setting, ok := strings.CutPrefix(wizard.Section, "locked:"); ok {
+			m.preferencesWizard = nil
+			return m.showNotification("🔒", setting+" is managed by "+config.PolicyPath, "info")
+		}
 		wizard.Step = 1
 		return m.buildPreferencesForm()
 
@@ -290,8 +393,18 @@ func (m *model) nextPreferencesStep() tea.Cmd {
 			m.preferencesWizard = nil
 			return m.showNotification("✓", "History settings saved", "success")
 
+		case "execution":
+			m.config.Execution.Shell = strings.TrimSpace(wizard.Shell)
+			m.config.Execution.LoginShell = wizard.LoginShell
+			config.Save(m.config)
+			m.preferencesWizard = nil
+			return m.showNotification("✓", "Execution settings saved", "success")
+
 		case "mcp":
-			if wizard.MCPAction == "toggle" {
+			if wizard.MCPAction == "locked" {
+				m.preferencesWizard = nil
+				return m.showNotification("🔒", "MCP is managed by "+config.PolicyPath, "info")
+			} else if wizard.MCPAction == "toggle" {
 				wizard.MCPEnabled = !wizard.MCPEnabled
 				m.config.MCP.Enabled = wizard.MCPEnabled
 				config.Save(m.config)
@@ -300,10 +413,12 @@ func (m *model) nextPreferencesStep() tea.Cmd {
 				if wizard.MCPEnabled {
 					status = "enabled"
 				}
-				return m.showNotification("✓", "MCP "+status, "success")
+				return tea.Batch(m.restartMCPPolling(), m.showNotification("✓", "MCP "+status, "success"))
 			} else if wizard.MCPAction == "add" {
 				wizard.MCPName = ""
 				wizard.MCPURL = ""
+				wizard.MCPRefresh = ""
+				wizard.MCPPoll = true
 				wizard.Step = 2
 				return m.buildPreferencesForm()
 			} else if strings.HasPrefix(wizard.MCPAction, "edit:") {
@@ -312,6 +427,11 @@ func (m *model) nextPreferencesStep() tea.Cmd {
 					if srv.Name == serverName {
 						wizard.MCPName = srv.Name
 						wizard.MCPURL = srv.URL
+						wizard.MCPRefresh = ""
+						if srv.RefreshSeconds > 0 {
+							wizard.MCPRefresh = strconv.Itoa(srv.RefreshSeconds)
+						}
+						wizard.MCPPoll = !srv.Disabled
 						break
 					}
 				}
@@ -328,7 +448,7 @@ func (m *model) nextPreferencesStep() tea.Cmd {
 				m.config.MCP.Servers = newServers
 				config.Save(m.config)
 				m.preferencesWizard = nil
-				return m.showNotification("✓", "Removed "+serverName, "success")
+				return tea.Batch(m.restartMCPPolling(), m.showNotification("✓", "Removed "+serverName, "success"))
 			}
 		}
 
@@ -338,30 +458,38 @@ func (m *model) nextPreferencesStep() tea.Cmd {
 			return m.showNotification("!", "Name and URL are required", "error")
 		}
 
+		server := config.MCPServerConfig{
+			Name:     wizard.MCPName,
+			URL:      wizard.MCPURL,
+			Disabled: !wizard.MCPPoll,
+		}
+		server.RefreshSeconds, _ = strconv.Atoi(strings.TrimSpace(wizard.MCPRefresh))
 		if strings.HasPrefix(wizard.MCPAction, "edit:") {
 			oldName := strings.TrimPrefix(wizard.MCPAction, "edit:")
 			for i, srv := range m.config.MCP.Servers {
 				if srv.Name == oldName {
-					m.config.MCP.Servers[i].Name = wizard.MCPName
-					m.config.MCP.Servers[i].URL = wizard.MCPURL
+					m.config.MCP.Servers[i] = server
 					break
 				}
 			}
 		} else {
-			m.config.MCP.Servers = append(m.config.MCP.Servers, config.MCPServerConfig{
-				Name: wizard.MCPName,
-				URL:  wizard.MCPURL,
-			})
+			m.config.MCP.Servers = append(m.config.MCP.Servers, server)
 		}
 		config.Save(m.config)
 		m.preferencesWizard = nil
-		return m.showNotification("✓", "MCP server saved", "success")
+		if !m.config.Policy.MCPServerAllowed(wizard.MCPURL) {
+			return m.showNotification("🔒", "Server saved, but blocked by "+config.PolicyPath, "warning")
+		}
+		return tea.Batch(m.restartMCPPolling(), m.showNotification("✓", "MCP server saved", "success"))
 	}
 
 	return nil
 }
 
 func (m *model) startProvidersWizard() tea.Cmd {
+	if m.config.Policy.AIDisabled {
+		return m.showNotification("🔒", config.ErrAIDisabled.Error(), "warning")
+	}
 	m.providersWizard = &ProvidersWizard{
 		Step:    0,
 		Enabled: true,
@@ -379,6 +507,10 @@ func (m *model) buildProvidersForm() tea.Cmd {
 	case 0:
 		var options []huh.Option[string]
 		options = append(options, huh.NewOption("Add Provider", "add"))
+		wizard.Detected = ai.Discover(m.config.AI.Providers)
+		if len(wizard.Detected) > 0 {
+			options = append(options, huh.NewOption(fmt.Sprintf("Import Detected (%d)", len(wizard.Detected)), "import"))
+		}
 
 		for _, p := range m.config.AI.Providers {
 			status := "disabled"
@@ -428,6 +560,9 @@ func (m *model) buildProvidersForm() tea.Cmd {
 						huh.NewOption("OpenAI", "openai"),
 						huh.NewOption("Anthropic (Claude)", "anthropic"),
 						huh.NewOption("Ollama (Local)", "ollama"),
+						huh.NewOption("Google Gemini (AI Studio)", "gemini"),
+						huh.NewOption("Google Vertex AI", "vertex"),
+						huh.NewOption("AWS Bedrock", "bedrock"),
 						huh.NewOption("OpenAI Compatible", "openai-compatible"),
 					).
 					Value(&wizard.ProviderType),
@@ -449,12 +584,43 @@ func (m *model) buildProvidersForm() tea.Cmd {
 				Value(&wizard.Name),
 		)
 
-		if wizard.ProviderType != "ollama" {
+		switch wizard.ProviderType {
+		case "vertex":
+			fields = append(fields,
+				huh.NewInput().
+					Title("Project").
+					Description("Google Cloud project; empty for GOOGLE_CLOUD_PROJECT").
+					Value(&wizard.Project),
+				huh.NewSelect[string]().
+					Title("Location").
+					Options(regionOptions(vertexLocations, wizard.Region)...).
+					Value(&wizard.Region),
+			)
+		case "bedrock":
+			fields = append(fields,
+				huh.NewSelect[string]().
+					Title("Region").
+					Options(regionOptions(bedrockRegions, wizard.Region)...).
+					Value(&wizard.Region),
+				huh.NewInput().
+					Title("Access Key ID").
+					Description("Empty to use the AWS environment or CLI profile").
+					Value(&wizard.AccessKeyID),
+				huh.NewInput().
+					Title("Secret Access Key").
+					EchoMode(huh.EchoModePassword).
+					Value(&wizard.SecretAccessKey),
+			)
+		}
+
+		if wizard.ProviderType != "ollama" && wizard.ProviderType != "vertex" && wizard.ProviderType != "bedrock" {
 			keyDesc := "Your API key (stored locally)"
 			if wizard.ProviderType == "anthropic" {
 				keyDesc = "Anthropic API key (starts with sk-ant-)"
 			} else if wizard.ProviderType == "openai" {
 				keyDesc = "OpenAI API key (starts with sk-)"
+			} else if wizard.ProviderType == "gemini" {
+				keyDesc = "Google AI Studio API key (starts with AIza)"
 			}
 			fields = append(fields,
 				huh.NewInput().
@@ -485,6 +651,10 @@ func (m *model) buildProvidersForm() tea.Cmd {
 			modelPlaceholder = "claude-sonnet-4-20250514"
 		case "ollama":
 			modelPlaceholder = "llama3"
+		case "gemini", "vertex":
+			modelPlaceholder = "gemini-2.5-flash"
+		case "bedrock":
+			modelPlaceholder = "anthropic.claude-3-5-sonnet-20240620-v1:0"
 		}
 		fields = append(fields,
 			huh.NewInput().
@@ -499,6 +669,10 @@ func (m *model) buildProvidersForm() tea.Cmd {
 				Title("Enabled").
 				Description("Enable this provider").
 				Value(&wizard.Enabled),
+			huh.NewConfirm().
+				Title("Advanced settings").
+				Description("Set temperature, max tokens, timeout and system prompts").
+				Value(&wizard.Advanced),
 		)
 
 		wizard.InputForm = huh.NewForm(huh.NewGroup(fields...)).
@@ -512,6 +686,30 @@ func (m *model) buildProvidersForm() tea.Cmd {
 		wizard.Testing = true
 		return m.testProviderConnection()
 
+	case 5:
+		wizard.InputForm = buildProviderSettingsForm(wizard)
+		return wizard.InputForm.Init()
+
+	case 6:
+		options := make([]huh.Option[int], len(wizard.Detected))
+		for i, f := range wizard.Detected {
+			label := fmt.Sprintf("%s [%s] from %s", f.Provider.Name, f.Provider.ProviderType, f.Source)
+			options[i] = huh.NewOption(label, i).Selected(true)
+		}
+		wizard.InputForm = huh.NewForm(
+			huh.NewGroup(
+				huh.NewMultiSelect[int]().
+					Title("Import Providers").
+					Description("Credentials found on this machine; space toggles, enter imports").
+					Options(options...).
+					Value(&wizard.Import),
+			),
+		).
+			WithWidth(80).
+			WithShowHelp(true).
+			WithTheme(huh.ThemeCatppuccin())
+		return wizard.InputForm.Init()
+
 	case 4:
 		var options []huh.Option[string]
 		for _, p := range m.config.AI.Providers {
@@ -547,6 +745,35 @@ func (m *model) buildProvidersForm() tea.Cmd {
 	return nil
 }
 
+// bedrockRegions and vertexLocations are the regions offered in the
+// providers wizard
+var (
+	bedrockRegions  = []string{"us-east-1", "us-west-2", "eu-central-1", "eu-west-1", "eu-west-3", "ap-northeast-1", "ap-southeast-2"}
+	vertexLocations = []string{"us-central1", "us-east4", "europe-west1", "europe-west4", "asia-northeast1", "global"}
+)
+
+// regionOptions lists regions, adding current when it is not one of them
+func regionOptions(regions []string, current string) []huh.Option[string] {
+	if current != "" && !slices.Contains(regions, current) {
+		regions = append([]string{current}, regions...)
+	}
+	return huh.NewOptions(regions...)
+}
+
+// applyProviderCloudSettings copies the wizard's Vertex AI and Bedrock
+// fields to p
+func applyProviderCloudSettings(w *ProvidersWizard, p *config.ProviderConfig) {
+	switch w.ProviderType {
+	case "vertex":
+		p.Region = w.Region
+		p.Project = strings.TrimSpace(w.Project)
+	case "bedrock":
+		p.Region = w.Region
+		p.AccessKeyID = strings.TrimSpace(w.AccessKeyID)
+		p.SecretAccessKey = strings.TrimSpace(w.SecretAccessKey)
+	}
+}
+
 func (m *model) testProviderConnection() tea.Cmd {
 	wizard := m.providersWizard
 	if wizard == nil {
@@ -562,6 +789,7 @@ func (m *model) testProviderConnection() tea.Cmd {
 			DefaultModel: wizard.DefaultModel,
 			Enabled:      true,
 		}
+		applyProviderCloudSettings(wizard, &provider)
 
 		client := ai.NewClient(provider)
 		err := client.TestConnection()
@@ -584,6 +812,9 @@ func (m *model) nextProvidersStep() tea.Cmd {
 		if wizard.Action == "add" {
 			wizard.Step = 1
 			return m.buildProvidersForm()
+		} else if wizard.Action == "import" {
+			wizard.Step = 6
+			return m.buildProvidersForm()
 		} else if wizard.Action == "default" {
 			wizard.Step = 4
 			return m.buildProvidersForm()
@@ -596,10 +827,15 @@ func (m *model) nextProvidersStep() tea.Cmd {
 					wizard.BaseURL = p.BaseURL
 					wizard.DefaultModel = p.DefaultModel
 					wizard.Enabled = p.Enabled
+					wizard.Region = p.Region
+					wizard.Project = p.Project
+					wizard.AccessKeyID = p.AccessKeyID
+					wizard.SecretAccessKey = p.SecretAccessKey
 					wizard.ProviderType = p.ProviderType
 					if wizard.ProviderType == "" {
 						wizard.ProviderType = ai.DetectProviderType(p.APIKey, p.BaseURL, p.Name)
 					}
+					loadProviderSettings(wizard, p)
 					break
 				}
 			}
@@ -642,6 +878,20 @@ func (m *model) nextProvidersStep() tea.Cmd {
 			if wizard.DefaultModel == "" {
 				wizard.DefaultModel = "gpt-4"
 			}
+		case "gemini", "vertex":
+			if wizard.DefaultModel == "" {
+				wizard.DefaultModel = "gemini-2.5-flash"
+			}
+		case "bedrock":
+			if wizard.DefaultModel == "" {
+				wizard.DefaultModel = "anthropic.claude-3-5-sonnet-20240620-v1:0"
+			}
+			if wizard.Region == "" {
+				wizard.Region = cmp.Or(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1")
+			}
+		}
+		if wizard.ProviderType == "vertex" && wizard.Region == "" {
+			wizard.Region = "us-central1"
 		}
 		wizard.Step = 2
 		return m.buildProvidersForm()
@@ -657,6 +907,14 @@ func (m *model) nextProvidersStep() tea.Cmd {
 		if wizard.ProviderType == "openai" && wizard.APIKey != "" && !strings.HasPrefix(wizard.APIKey, "sk-") {
 			return m.showNotification("!", "OpenAI keys start with sk-", "warning")
 		}
+		if wizard.ProviderType == "bedrock" && (wizard.AccessKeyID == "") != (wizard.SecretAccessKey == "") {
+			return m.showNotification("!", "Enter both AWS keys, or neither", "warning")
+		}
+
+		if wizard.Advanced {
+			wizard.Step = 5
+			return m.buildProvidersForm()
+		}
 
 		wizard.Step = 3
 		wizard.Testing = true
@@ -667,11 +925,26 @@ func (m *model) nextProvidersStep() tea.Cmd {
 	case 3:
 		return nil
 
+	case 5:
+		wizard.Step = 3
+		wizard.Testing = true
+		wizard.TestResult = ""
+		wizard.TestError = ""
+		return m.buildProvidersForm()
+
 	case 4:
 		m.config.AI.DefaultProvider = wizard.Name
 		config.Save(m.config)
 		m.providersWizard = nil
 		return m.showNotification("✓", "Default provider: "+wizard.Name, "success")
+
+	case 6:
+		var chosen []ai.Found
+		for _, i := range wizard.Import {
+			chosen = append(chosen, wizard.Detected[i])
+		}
+		m.providersWizard = nil
+		return m.importProviders(chosen)
 	}
 
 	m.providersWizard = nil
@@ -692,6 +965,8 @@ func (m *model) saveProvider() tea.Cmd {
 		DefaultModel: wizard.DefaultModel,
 		Enabled:      wizard.Enabled,
 	}
+	applyProviderCloudSettings(wizard, &newProvider)
+	applyProviderSettings(wizard, &newProvider)
 
 	isEdit := strings.HasPrefix(wizard.Action, "edit:")
 	if isEdit {
@@ -821,6 +1096,9 @@ func (m *model) confirmDeleteResource() tea.Cmd {
 // Run Agent Wizard
 
 func (m *model) startRunAgentWizard() tea.Cmd {
+	if cmd := m.aiUnavailable(); cmd != nil {
+		return cmd
+	}
 	// Check if any providers are configured
 	var enabledProviders []config.ProviderConfig
 	for _, p := range m.config.AI.Providers {
@@ -833,11 +1111,16 @@ func (m *model) startRunAgentWizard() tea.Cmd {
 		return m.showNotification("!", "No providers configured. Go to Configure Providers first.", "error")
 	}
 
+	defaultRuntime := "docker"
+	if rt, err := runtimepkg.Detect(); err == nil {
+		defaultRuntime = rt.Name()
+	}
+
 	m.runAgentWizard = &RunAgentWizard{
 		Step:     0,
 		Provider: m.config.AI.DefaultProvider,
-		Runtime:  "docker",
-		Image:    "skitz-fastagent",
+		Runtime:  defaultRuntime,
+		Image:    runtimepkg.FastAgentImage,
 	}
 	return m.buildRunAgentForm()
 }
@@ -884,10 +1167,7 @@ func (m *model) buildRunAgentForm() tea.Cmd {
 				huh.NewSelect[string]().
 					Title("Runtime").
 					Description("Where should the agent run?").
-					Options(
-						huh.NewOption("Docker - Local container", "docker"),
-						huh.NewOption("E2B - Cloud sandbox", "e2b"),
-					).
+					Options(runtimeOptions(m.features.Enabled(features.E2B))...).
 					Value(&wizard.Runtime),
 			),
 		).
@@ -915,14 +1195,40 @@ func (m *model) buildRunAgentForm() tea.Cmd {
 				Value(&wizard.Task),
 		)
 
-		if wizard.Runtime == "docker" {
+		if wizard.Runtime != "e2b" {
 			fields = append(fields,
 				huh.NewInput().
-					Title("Docker Image").
-					Description("Image with fast-agent (build with: docker build -t skitz-fastagent docker/fastagent)").
-					Placeholder("skitz-fastagent").
+					Title("Image").
+					Description(fmt.Sprintf("Image with fast-agent (%s is built from %s if missing)", runtimepkg.FastAgentImage, runtimepkg.FastAgentBuildDir)).
+					Placeholder(runtimepkg.FastAgentImage).
 					Value(&wizard.Image),
+				huh.NewSelect[string]().
+					Title("Project Directory").
+					Description(fmt.Sprintf("Mount it at %s so the agent can read your code", runtimepkg.WorkspaceMount)).
+					Options(
+						huh.NewOption("Don't mount", ""),
+						huh.NewOption("Mount read-only", "ro"),
+						huh.NewOption("Mount read-write", "rw"),
+					).
+					Value(&wizard.Mount),
+				huh.NewInput().
+					Title("Directory").
+					Description("Directory to mount (empty = current directory)").
+					Placeholder(currentDir()).
+					Value(&wizard.Workspace).
+					Validate(validateAgentWorkspace),
+				huh.NewInput().
+					Title("Timeout").
+					Description("Stop the run after this long, e.g. 10m (empty = no limit)").
+					Placeholder("10m").
+					Value(&wizard.Timeout).
+					Validate(func(s string) error {
+						_, err := parseAgentTimeout(s)
+						return err
+					}),
 			)
+		} else {
+			fields = append(fields, m.e2bAgentFields(wizard)...)
 		}
 
 		wizard.InputForm = huh.NewForm(huh.NewGroup(fields...)).
@@ -933,12 +1239,33 @@ func (m *model) buildRunAgentForm() tea.Cmd {
 		return wizard.InputForm.Init()
 
 	case 3:
-		// Step 3: Confirm
+		// Step 3: Confirm, showing the exact command for docker runs
+		description := fmt.Sprintf("Run '%s' with %s using %s?", wizard.AgentName, wizard.Provider, wizard.Runtime)
+		if rt, err := runtimepkg.Get(wizard.Runtime); err == nil {
+			for _, p := range m.config.AI.Providers {
+				if p.Name == wizard.Provider {
+					name, task := wizard.AgentName, wizard.Task
+					if name == "" {
+						name = "skitz-agent"
+					}
+					if task == "" {
+						task = "Say hello and introduce yourself."
+					}
+					if wizard.Timeout != "" {
+						description += " Times out after " + strings.TrimSpace(wizard.Timeout) + "."
+					}
+					description += "\n\n$ " + agentRunCommand(rt, p, name, task, wizard.Image, "<api key>", wizard.agentMount())
+					break
+				}
+			}
+		} else if wizard.Runtime == "e2b" {
+			description += "\n\n" + m.e2bRunSummary(wizard)
+		}
 		wizard.InputForm = huh.NewForm(
 			huh.NewGroup(
 				huh.NewConfirm().
 					Title("Run Agent?").
-					Description(fmt.Sprintf("Run '%s' with %s using %s?", wizard.AgentName, wizard.Provider, wizard.Runtime)).
+					Description(description).
 					Affirmative("Run").
 					Negative("Cancel").
 					Value(&wizard.Confirmed),
@@ -954,6 +1281,189 @@ func (m *model) buildRunAgentForm() tea.Cmd {
 	return nil
 }
 
+// agentModelEnv returns the fast-agent model name and the API key
+// environment variable for a provider.
+func agentModelEnv(provider config.ProviderConfig) (string, string) {
+	model := provider.DefaultModel
+
+	// Map common model names to fast-agent compatible names
+	modelMap := map[string]string{
+		"claude-sonnet-4-20250514": "sonnet",
+		"claude-3-5-sonnet":        "sonnet",
+		"claude-3-sonnet":          "sonnet",
+		"claude-3-haiku":           "haiku",
+	}
+	if mapped, ok := modelMap[model]; ok {
+		model = mapped
+	}
+
+	switch provider.ProviderType {
+	case "anthropic":
+		if model == "" {
+			model = "sonnet"
+		}
+		return model, "ANTHROPIC_API_KEY"
+	default:
+		if model == "" {
+			model = "gpt-5"
+		}
+		return model, "OPENAI_API_KEY"
+	}
+}
+
+// agentRunCommand builds the container run command for a fast-agent run,
+// building the fastagent image first when it is missing and its Dockerfile
+// is available. Pass a placeholder as apiKey to render it for display.
+func agentRunCommand(rt runtimepkg.Runtime, provider config.ProviderConfig, agentName, task, image, apiKey string, mount agentMount) string {
+	if image == "" {
+		image = "astral/uv:python3.12-bookworm-slim"
+	}
+	opts := runtimepkg.RunOptions{
+		Name:   agentName,
+		Image:  image,
+		Remove: true,
+		Env:    agentEnv(provider, task+mount.promptNote(), apiKey),
+	}
+	if mount.Dir != "" {
+		opts.Volumes = []string{mount.volume()}
+		opts.Env["AGENT_WORKSPACE"] = runtimepkg.WorkspaceMount
+	}
+	return withImageBuild(rt, image, "", rt.RunCommand(opts))
+}
+
+// agentMount is a project directory mounted into an agent's container at
+// runtimepkg.WorkspaceMount. The zero value mounts nothing.
+type agentMount struct {
+	Dir      string // absolute path on the host
+	ReadOnly bool
+}
+
+// agentMount returns the wizard's mount: its directory, or the current
+// one, when a mount mode was chosen
+func (w *RunAgentWizard) agentMount() agentMount {
+	if w.Mount == "" {
+		return agentMount{}
+	}
+	dir := strings.TrimSpace(w.Workspace)
+	if dir == "" {
+		dir = currentDir()
+	}
+	if abs, err := filepath.Abs(expandHome(dir)); err == nil {
+		dir = abs
+	}
+	return agentMount{Dir: dir, ReadOnly: w.Mount == "ro"}
+}
+
+// volume returns the mount as a container volume
+func (a agentMount) volume() string {
+	v := a.Dir + ":" + runtimepkg.WorkspaceMount
+	if a.ReadOnly {
+		v += ":ro"
+	}
+	return v
+}
+
+// promptNote tells the agent where the mounted project is, appended to
+// its prompt
+func (a agentMount) promptNote() string {
+	if a.Dir == "" {
+		return ""
+	}
+	access := "read-write"
+	if a.ReadOnly {
+		access = "read-only"
+	}
+	return fmt.Sprintf("\n\nThe project directory %s is mounted %s at %s.", filepath.Base(a.Dir), access, runtimepkg.WorkspaceMount)
+}
+
+// agentEnv returns the env vars that carry the prompt, model and API key
+// into a fast-agent run.
+func agentEnv(provider config.ProviderConfig, task, apiKey string) map[string]string {
+	model, envVar := agentModelEnv(provider)
+	env := map[string]string{
+		envVar:         apiKey,
+		"AGENT_MODEL":  model,
+		"AGENT_PROMPT": task,
+	}
+	if instruction := provider.SettingsFor(config.FeatureAgent).SystemPrompt; instruction != "" {
+		env["AGENT_INSTRUCTION"] = instruction
+	}
+	return env
+}
+
+// withImageBuild prefixes cmd with an image build. Saved agents with a
+// buildPath always rebuild so source changes are picked up; otherwise only
+// a missing fastagent image is built, from its Dockerfile directory.
+func withImageBuild(rt runtimepkg.Runtime, image, buildPath, cmd string) string {
+	if buildPath != "" {
+		return rt.BuildCommand(image, buildPath) + " && " + cmd
+	}
+	if image != runtimepkg.FastAgentImage {
+		return cmd
+	}
+	dir, ok := runtimepkg.FastAgentDir()
+	if !ok {
+		return cmd
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if exists, err := rt.ImageExists(ctx, image); err != nil || exists {
+		return cmd
+	}
+	return rt.BuildCommand(image, dir) + " && " + cmd
+}
+
+// runtimeOptions lists the agent runtimes for the Run Agent wizard, with
+// container engines that are not installed marked as such. E2B is offered
+// when its feature flag is on.
+func runtimeOptions(e2b bool) []huh.Option[string] {
+	var options []huh.Option[string]
+	for _, rt := range runtimepkg.All() {
+		label := rt.Label() + " - Local container"
+		if !rt.Available() {
+			label += " (not installed)"
+		}
+		options = append(options, huh.NewOption(label, rt.Name()))
+	}
+	if e2b {
+		options = append(options, huh.NewOption("E2B - Cloud sandbox", "e2b"))
+	}
+	return options
+}
+
+// validateAgentWorkspace accepts an existing directory, or nothing.
+func validateAgentWorkspace(s string) error {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	if info, err := os.Stat(expandHome(s)); err != nil || !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", s)
+	}
+	return nil
+}
+
+// currentDir returns the working directory, or "." when it can't be read.
+func currentDir() string {
+	if cwd, err := os.Getwd(); err == nil {
+		return cwd
+	}
+	return "."
+}
+
+// parseAgentTimeout parses a Run Agent timeout; empty means no limit.
+func parseAgentTimeout(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("enter a duration like 30s, 10m or 1h")
+	}
+	return d, nil
+}
+
 func (m *model) nextRunAgentStep() tea.Cmd {
 	wizard := m.runAgentWizard
 	if wizard == nil {
@@ -971,14 +1481,14 @@ func (m *model) nextRunAgentStep() tea.Cmd {
 func (m *model) executeRunAgent() tea.Cmd {
 	wizard := m.runAgentWizard
 	if wizard == nil {
-		log.Println("executeRunAgent: wizard is nil")
+		slog.Debug("executeRunAgent: wizard is nil")
 		return nil
 	}
 
-	log.Printf("executeRunAgent: confirmed=%v runtime=%s agent=%s provider=%s", wizard.Confirmed, wizard.Runtime, wizard.AgentName, wizard.Provider)
+	slog.Debug("executeRunAgent", "confirmed", wizard.Confirmed, "runtime", wizard.Runtime, "agent", wizard.AgentName, "provider", wizard.Provider)
 
 	if !wizard.Confirmed {
-		log.Println("executeRunAgent: not confirmed, cancelling")
+		slog.Debug("executeRunAgent: not confirmed, cancelling")
 		m.runAgentWizard = nil
 		return nil
 	}
@@ -1008,7 +1518,15 @@ func (m *model) executeRunAgent() tea.Cmd {
 	}
 
 	runtime := wizard.Runtime
+	if runtime == "e2b" && !m.features.Enabled(features.E2B) {
+		m.runAgentWizard = nil
+		return m.showNotification("🚩", "E2B sandboxes are experimental: turn on the e2b feature flag to use them", "warning")
+	}
 	providerName := wizard.Provider
+	timeout, _ := parseAgentTimeout(wizard.Timeout)
+	if timeout == 0 {
+		timeout = provider.SettingsFor(config.FeatureAgent).Timeout
+	}
 	m.runAgentWizard = nil
 
 	// Generate unique ID for this agent run
@@ -1023,63 +1541,28 @@ func (m *model) executeRunAgent() tea.Cmd {
 		StartTime: time.Now(),
 		Status:    "running",
 		Task:      task,
+		Container: agentName,
+		Timeout:   timeout,
 	}
 
 	// Add to active agents immediately
 	m.activeAgents = append(m.activeAgents, activeAgent)
 
-	if runtime == "docker" {
-		if _, err := exec.LookPath("docker"); err != nil {
+	if runtime != "e2b" {
+		rt, err := runtimepkg.Get(runtime)
+		if err == nil && !rt.Available() {
+			err = fmt.Errorf("%s not found on PATH", rt.Label())
+		}
+		if err != nil {
 			// Remove from active agents on error
 			m.removeActiveAgent(agentID)
-			return m.showNotification("!", "Docker not found. Install from https://docs.docker.com/get-docker/", "error")
+			return m.showNotification("!", err.Error(), "error")
 		}
 
-		image := wizard.Image
-		if image == "" {
-			image = "astral/uv:python3.12-bookworm-slim"
-		}
+		model, _ := agentModelEnv(*provider)
+		slog.Info("starting agent", "provider", provider.Name, "type", provider.ProviderType, "model", model, "runtime", runtime, "agent_id", agentID)
 
-		// Determine model and env var based on provider type
-		model := provider.DefaultModel
-		envVar := ""
-		apiKeyValue := provider.APIKey
-
-		// Map common model names to fast-agent compatible names
-		modelMap := map[string]string{
-			"claude-sonnet-4-20250514": "sonnet",
-			"claude-3-5-sonnet":        "sonnet",
-			"claude-3-sonnet":          "sonnet",
-			"claude-3-haiku":           "haiku",
-		}
-		if mapped, ok := modelMap[model]; ok {
-			model = mapped
-		}
-
-		switch provider.ProviderType {
-		case "openai":
-			if model == "" {
-				model = "gpt-5"
-			}
-			envVar = "OPENAI_API_KEY"
-		case "anthropic":
-			if model == "" {
-				model = "sonnet"
-			}
-			envVar = "ANTHROPIC_API_KEY"
-		default:
-			if model == "" {
-				model = "gpt-5"
-			}
-			envVar = "OPENAI_API_KEY"
-		}
-
-		log.Printf("executeRunAgent: using provider=%s type=%s model=%s agentID=%s", provider.Name, provider.ProviderType, model, agentID)
-
-		// Use skitz-fastagent image with env vars for prompt and model
-		cmd := fmt.Sprintf(`docker run --rm --name %s -e %s=%s -e AGENT_MODEL=%s -e AGENT_PROMPT=%q %s`,
-			agentName, envVar, apiKeyValue, model, task, image)
-		log.Printf("executeRunAgent: running docker command (key redacted)")
+		cmd := agentRunCommand(rt, *provider, agentName, task, wizard.Image, provider.APIKey, wizard.agentMount())
 
 		// Return both the agent started message and the run command
 		return tea.Batch(
@@ -1089,28 +1572,11 @@ func (m *model) executeRunAgent() tea.Cmd {
 			m.runAgentCommand(CommandSpec{
 				Command: cmd,
 				Mode:    CommandEmbedded,
-			}, agentID),
+			}, activeAgent),
 		)
 	}
 
-	// E2B runtime
-	if _, err := exec.LookPath("e2b"); err != nil {
-		m.removeActiveAgent(agentID)
-		return m.showNotification("!", "E2B CLI not found. Install with: npm install -g @e2b/cli", "error")
-	}
-
-	// For E2B, mark as completed immediately since it's just preparation
-	return tea.Batch(
-		func() tea.Msg {
-			return agentCompletedMsg{
-				agentID:  agentID,
-				success:  true,
-				output:   "E2B sandbox ready. Use e2b CLI to interact.",
-				duration: 0,
-			}
-		},
-		m.showNotification("✓", fmt.Sprintf("E2B agent '%s' ready. Use e2b CLI to spawn sandbox.", agentName), "success"),
-	)
+	return m.startE2BAgent(*provider, activeAgent, wizard)
 }
 
 // removeActiveAgent removes an agent from the active list
@@ -1123,32 +1589,95 @@ func (m *model) removeActiveAgent(agentID string) {
 	}
 }
 
-// runAgentCommand runs a command and tracks agent completion
-func (m *model) runAgentCommand(spec CommandSpec, agentID string) tea.Cmd {
-	// Find the active agent to get start time
-	var startTime time.Time
-	for _, agent := range m.activeAgents {
-		if agent.ID == agentID {
-			startTime = agent.StartTime
-			break
+// runAgentCommand runs a command and tracks agent completion. The run stops
+// when cancelAgent is called or agent.Timeout passes; the agent's docker
+// container, if any, is then removed. A command the organization policy
+// blocks fails the run without being started. Runs are recorded in the
+// audit log with API keys redacted.
+func (m *model) runAgentCommand(spec CommandSpec, agent ActiveAgent) tea.Cmd {
+	action := m.redactAPIKeys(spec.Command)
+	if err := m.config.Policy.CheckCommand(spec.Command); err != nil {
+		auditAgent(agent, action, config.AgentStatusFailed)
+		return func() tea.Msg {
+			return agentCompletedMsg{
+				agentID: agent.ID,
+				output:  err.Error(),
+				status:  config.AgentStatusFailed,
+			}
 		}
 	}
 
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if agent.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), agent.Timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	if m.agentCancels == nil {
+		m.agentCancels = make(map[string]context.CancelFunc)
+	}
+	m.agentCancels[agent.ID] = cancel
+
 	return func() tea.Msg {
+		defer cancel()
+
 		// Run the command and capture output
-		cmd := exec.Command("sh", "-c", spec.Command)
+		cmd := exec.CommandContext(ctx, "sh", "-c", spec.Command)
+		cmd.WaitDelay = 5 * time.Second
 		output, err := cmd.CombinedOutput()
 
-		duration := time.Since(startTime).Milliseconds()
-		success := err == nil
+		status := config.AgentStatusCompleted
+		if err != nil {
+			switch ctx.Err() {
+			case context.DeadlineExceeded:
+				status = config.AgentStatusTimedOut
+			case context.Canceled:
+				status = config.AgentStatusCancelled
+			default:
+				status = config.AgentStatusFailed
+			}
+		}
+
+		if (status == config.AgentStatusTimedOut || status == config.AgentStatusCancelled) && agent.Container != "" {
+			if rt, rtErr := runtimepkg.Get(agent.Runtime); rtErr == nil {
+				rmCtx, rmCancel := context.WithTimeout(context.Background(), 30*time.Second)
+				if rmErr := rt.Remove(rmCtx, agent.Container); rmErr != nil {
+					slog.Warn("failed to remove agent container", "container", agent.Container, "error", rmErr)
+				}
+				rmCancel()
+			}
+		}
 
+		auditAgent(agent, action, status)
 		return agentCompletedMsg{
-			agentID:  agentID,
-			success:  success,
+			agentID:  agent.ID,
+			success:  status == config.AgentStatusCompleted,
 			output:   string(output),
-			duration: duration,
+			duration: time.Since(agent.StartTime).Milliseconds(),
+			status:   status,
+		}
+	}
+}
+
+// cancelAgent stops a running agent. Its agentCompletedMsg records the
+// cancelled status in agent history.
+func (m *model) cancelAgent(agentID string) tea.Cmd {
+	cancel, ok := m.agentCancels[agentID]
+	if !ok {
+		return m.showNotification("!", "This agent can't be cancelled", "warning")
+	}
+	cancel()
+	delete(m.agentCancels, agentID)
+
+	for i := range m.activeAgents {
+		if m.activeAgents[i].ID == agentID {
+			m.activeAgents[i].Status = "cancelling"
+			slog.Info("cancelling agent", "agent_id", agentID, "name", m.activeAgents[i].Name)
+			break
 		}
 	}
+	return m.showNotification("■", "Cancelling agent...", "info")
 }
 
 // openConfigInEditor opens the config file in the user's editor
@@ -1188,6 +1717,9 @@ func (m *model) openConfigInEditor() tea.Cmd {
 // Saved Agent Wizard
 
 func (m *model) startSavedAgentWizard(agent config.SavedAgentConfig) tea.Cmd {
+	if cmd := m.aiUnavailable(); cmd != nil {
+		return cmd
+	}
 	// Check if any providers are configured
 	var enabledProviders []config.ProviderConfig
 	for _, p := range m.config.AI.Providers {
@@ -1349,10 +1881,11 @@ func (m *model) executeSavedAgent() tea.Cmd {
 		return m.showNotification("!", "Selected provider not found or disabled", "error")
 	}
 
-	// Check Docker is available
-	if _, err := exec.LookPath("docker"); err != nil {
+	// Saved agents run on the first available container runtime
+	rt, err := runtimepkg.Detect()
+	if err != nil {
 		m.savedAgentWizard = nil
-		return m.showNotification("!", "Docker not found. Install from https://docs.docker.com/get-docker/", "error")
+		return m.showNotification("!", err.Error(), "error")
 	}
 
 	agentName := wizard.AgentName
@@ -1380,23 +1913,33 @@ func (m *model) executeSavedAgent() tea.Cmd {
 		ID:        containerName,
 		Name:      agentName,
 		Provider:  provider.Name,
-		Runtime:   "docker",
+		Runtime:   rt.Name(),
 		StartTime: time.Now(),
 		Status:    "building",
 		Task:      prompt,
+		Container: containerName,
+		Timeout:   provider.SettingsFor(config.FeatureAgent).Timeout,
 	}
 
-	// Build and run docker command
-	var cmd string
+	opts := runtimepkg.RunOptions{
+		Name:  containerName,
+		Image: image,
+		Env: map[string]string{
+			envVar:           provider.APIKey,
+			"AGENT_RESOURCE": resource,
+			"AGENT_PROMPT":   prompt,
+		},
+	}
+	if instruction := provider.SettingsFor(config.FeatureAgent).SystemPrompt; instruction != "" {
+		opts.Env["AGENT_INSTRUCTION"] = instruction
+	}
 	if buildPath != "" {
-		// Build image first, then run with repo mounted read-only
-		cmd = fmt.Sprintf(`docker build -t %s %s && docker run --name %s -v "$(pwd):/skitz:ro" -e %s=%s -e AGENT_RESOURCE=%q -e AGENT_PROMPT=%q %s`,
-			image, buildPath, containerName, envVar, provider.APIKey, resource, prompt, image)
-	} else {
-		// Just run (image should exist)
-		cmd = fmt.Sprintf(`docker run --name %s -e %s=%s -e AGENT_RESOURCE=%q -e AGENT_PROMPT=%q %s`,
-			containerName, envVar, provider.APIKey, resource, prompt, image)
+		// Mount the repo read-only for agents built from it
+		if cwd, err := os.Getwd(); err == nil {
+			opts.Volumes = []string{cwd + ":/skitz:ro"}
+		}
 	}
+	cmd := withImageBuild(rt, image, buildPath, rt.RunCommand(opts))
 
 	return tea.Batch(
 		func() tea.Msg {
@@ -1405,6 +1948,6 @@ func (m *model) executeSavedAgent() tea.Cmd {
 		m.runAgentCommand(CommandSpec{
 			Command: cmd,
 			Mode:    CommandEmbedded,
-		}, agentID),
+		}, activeAgent),
 	)
 }
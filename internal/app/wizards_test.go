@@ -0,0 +1,185 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/features"
+	runtimepkg "github.com/htelsiz/skitz/internal/runtime"
+)
+
+func TestAgentRunCommandMount(t *testing.T) {
+	rt, err := runtimepkg.Get("docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := config.ProviderConfig{ProviderType: "openai", DefaultModel: "gpt-5"}
+	dir := t.TempDir()
+
+	wizard := &RunAgentWizard{Workspace: dir}
+	if cmd := agentRunCommand(rt, provider, "a1", "analyze this repo", "img", "<api key>", wizard.agentMount()); strings.Contains(cmd, " -v ") || strings.Contains(cmd, "AGENT_WORKSPACE") {
+		t.Errorf("mounted without a mount mode: %s", cmd)
+	}
+
+	wizard.Mount = "ro"
+	cmd := agentRunCommand(rt, provider, "a1", "analyze this repo", "img", "<api key>", wizard.agentMount())
+	for _, want := range []string{" -v " + dir + ":/workspace:ro ", "-e AGENT_WORKSPACE=/workspace", "mounted read-only at /workspace"} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("command lacks %q: %s", want, cmd)
+		}
+	}
+
+	wizard.Mount, wizard.Workspace = "rw", ""
+	if m := wizard.agentMount(); m.Dir != currentDir() || m.volume() != currentDir()+":/workspace" {
+		t.Errorf("read-write mount of the current directory = %+v", m)
+	}
+
+	if validateAgentWorkspace(dir) != nil || validateAgentWorkspace("") != nil || validateAgentWorkspace(dir+"/missing") == nil {
+		t.Error("validateAgentWorkspace() accepts the wrong directories")
+	}
+}
+
+func TestRunAgentCommandPolicy(t *testing.T) {
+	policy, err := config.ParsePolicy([]byte("commands:\n  deny: ['docker run']\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{}
+	m.config.Policy = policy
+	msg := m.runAgentCommand(CommandSpec{Command: "docker run --rm img"}, ActiveAgent{ID: "a1"})().(agentCompletedMsg)
+	if msg.status != config.AgentStatusFailed || !strings.Contains(msg.output, "blocked by organization policy") {
+		t.Errorf("blocked agent run = %+v", msg)
+	}
+}
+
+func TestRunAgentCommandTimeoutAndCancel(t *testing.T) {
+	m := model{}
+
+	// A run past its timeout is stopped
+	started := time.Now()
+	run := m.runAgentCommand(CommandSpec{Command: "exec sleep 30"}, ActiveAgent{ID: "a1", StartTime: started, Timeout: 100 * time.Millisecond})
+	msg := run().(agentCompletedMsg)
+	if msg.status != config.AgentStatusTimedOut || msg.success {
+		t.Errorf("timed out run = %+v", msg)
+	}
+	if time.Since(started) > 4*time.Second {
+		t.Errorf("timed out run took %s", time.Since(started))
+	}
+
+	// A cancelled run stops with the cancelled status
+	m.activeAgents = []ActiveAgent{{ID: "a2", Status: "running"}}
+	run = m.runAgentCommand(CommandSpec{Command: "exec sleep 30"}, m.activeAgents[0])
+	done := make(chan agentCompletedMsg, 1)
+	go func() { done <- run().(agentCompletedMsg) }()
+	time.Sleep(100 * time.Millisecond)
+	m.cancelAgent("a2")
+	if m.activeAgents[0].Status != "cancelling" {
+		t.Errorf("status while cancelling = %q", m.activeAgents[0].Status)
+	}
+	if _, ok := m.agentCancels["a2"]; ok {
+		t.Error("cancel kept after cancelling")
+	}
+	select {
+	case msg := <-done:
+		if msg.status != config.AgentStatusCancelled || msg.agentID != "a2" {
+			t.Errorf("cancelled run = %+v", msg)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("cancelled run still running")
+	}
+
+	// Agents without a run can't be cancelled
+	m.cancelAgent("a3")
+	if n := m.notifications[len(m.notifications)-1]; n.Message != "This agent can't be cancelled" {
+		t.Errorf("notification = %q", n.Message)
+	}
+}
+
+func TestPreferencesPolicyLocks(t *testing.T) {
+	policy, err := config.ParsePolicy([]byte(`ai_disabled: true
+mcp:
+  enabled: false
+  allowed_servers: [https://mcp.corp.example/]
+telemetry:
+  endpoint: https://telemetry.corp.example/skitz
+audit:
+  enabled: true
+  sink: syslog
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{}
+	m.config.Policy = policy
+	m.editPreferences()
+
+	view := m.preferencesWizard.InputForm.View()
+	for _, want := range []string{
+		"Some settings are managed by",
+		"🔒 AI off (locked by policy)",
+		"🔒 MCP off (locked by policy)",
+		"🔒 MCP servers limited to https://mcp.corp.example/",
+		"🔒 Telemetry sent to https://telemetry.corp.example/skitz",
+		"🔒 Audit log on (syslog) (locked by policy)",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("preferences missing %q:\n%s", want, view)
+		}
+	}
+
+	// Locked settings can't be edited
+	m.preferencesWizard.Section = "locked:telemetry.endpoint"
+	m.nextPreferencesStep()
+	if m.preferencesWizard != nil {
+		t.Error("preferences opened a locked setting")
+	}
+	if n := m.notifications[len(m.notifications)-1]; !strings.Contains(n.Message, "telemetry.endpoint is managed by") {
+		t.Errorf("notification = %q", n.Message)
+	}
+
+	// Without a policy nothing is locked
+	m = model{}
+	m.editPreferences()
+	if view := m.preferencesWizard.InputForm.View(); strings.Contains(view, "🔒") {
+		t.Errorf("preferences without a policy show locks:\n%s", view)
+	}
+}
+
+func TestE2BFeatureFlag(t *testing.T) {
+	t.Setenv(features.EnvVar(features.E2B), "")
+	m := model{features: features.New(nil)}
+	m.config.AI.Providers = []config.ProviderConfig{{Name: "openai"}}
+
+	hasE2B := func() bool {
+		for _, o := range runtimeOptions(m.features.Enabled(features.E2B)) {
+			if o.Value == "e2b" {
+				return true
+			}
+		}
+		return false
+	}
+	if hasE2B() {
+		t.Error("E2B offered with its flag off")
+	}
+
+	// A run asking for E2B anyway is refused
+	m.runAgentWizard = &RunAgentWizard{Provider: "openai", Runtime: "e2b", Confirmed: true}
+	m.executeRunAgent()
+	if len(m.activeAgents) != 0 || m.runAgentWizard != nil {
+		t.Errorf("started an E2B agent with the flag off: %+v", m.activeAgents)
+	}
+	if n := m.notifications[len(m.notifications)-1]; !strings.Contains(n.Message, "e2b feature flag") {
+		t.Errorf("notification = %q", n.Message)
+	}
+
+	// Turning the flag on for the session offers it
+	if len(getFeatureFlagsPaletteItems()) == 0 {
+		t.Fatal("no Feature Flags palette entry")
+	}
+	m.features.Toggle(features.E2B)
+	if !hasE2B() {
+		t.Error("E2B not offered with its flag on")
+	}
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// truncate shortens s to at most width display columns, ending it with
+// "..." when it is cut. Wide characters such as CJK and emoji take two
+// columns and are never split.
+func truncate(s string, width int) string {
+	return truncateWith(s, width, "...")
+}
+
+// truncateWith is truncate with tail in place of "..."
+func truncateWith(s string, width int, tail string) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	return ansi.Truncate(s, width, tail)
+}
+
+// dropLastRune removes the last character of s, for backspace in text
+// inputs
+func dropLastRune(s string) string {
+	_, size := utf8.DecodeLastRuneInString(s)
+	return s[:len(s)-size]
+}
+
+// wrapBreakpoints are where long words such as URLs and paths may break
+// besides spaces
+const wrapBreakpoints = "/"
+
+// wrapLines soft-wraps s, which may be styled, into lines of at most width
+// columns. Words longer than a line are broken.
+func wrapLines(s string, width int) []string {
+	if s == "" {
+		return []string{""}
+	}
+	if width < 1 {
+		width = 1
+	}
+	return strings.Split(ansi.Wrap(s, width, wrapBreakpoints), "\n")
+}
+
+// hangingIndentRe matches the indentation and list or quote marker that
+// wrapped lines of a markdown line line up under
+var hangingIndentRe = regexp.MustCompile(`^\s*(?:[-*+>]|\d+[.)])?\s*`)
+
+// wrapMarkdown soft-wraps each line of markdown text to width, indenting
+// continuation lines under the text of list items and quotes
+func wrapMarkdown(text string, width int) string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if ansi.StringWidth(line) <= width {
+			out = append(out, line)
+			continue
+		}
+		indent := hangingIndentRe.FindString(line)
+		if ansi.StringWidth(indent) > width/2 {
+			indent = ""
+		}
+		pad := strings.Repeat(" ", ansi.StringWidth(indent))
+		for i, l := range wrapLines(strings.TrimPrefix(line, indent), width-len(pad)) {
+			if i == 0 {
+				out = append(out, indent+l)
+			} else {
+				out = append(out, pad+l)
+			}
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{"fits", "kubectl", 10, "kubectl"},
+		{"ascii", "kubectl get pods", 10, "kubectl..."},
+		{"accents", "café crème brûlée", 10, "café cr..."},
+		{"cjk", "日本語のテキスト", 9, "日本語..."},
+		{"cjk odd width", "日本語のテキスト", 10, "日本語..."},
+		{"emoji", "🚀🚀🚀🚀🚀🚀", 8, "🚀🚀..."},
+		{"exact", "日本", 4, "日本"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.width)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+			}
+			if w := lipgloss.Width(got); w > tt.width {
+				t.Errorf("truncate(%q, %d) is %d columns wide", tt.s, tt.width, w)
+			}
+		})
+	}
+
+	styled := lipgloss.NewStyle().Bold(true).Render("a styled command line")
+	if got := truncate(styled, 10); lipgloss.Width(got) != 10 || ansi.Strip(got) != "a style..." {
+		t.Errorf("truncate(styled) = %q", got)
+	}
+}
+
+func TestDropLastRune(t *testing.T) {
+	for s, want := range map[string]string{"": "", "ab": "a", "café": "caf", "日本": "日", "go🚀": "go"} {
+		if got := dropLastRune(s); got != want {
+			t.Errorf("dropLastRune(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestWrapMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{"fits", "short line", 20, "short line"},
+		{"paragraph", "the quick brown fox jumps", 10, "the quick\nbrown fox\njumps"},
+		{"list item", "- the quick brown fox", 12, "- the quick\n  brown fox"},
+		{"numbered", "  1. alpha beta gamma", 14, "  1. alpha\n     beta\n     gamma"},
+		{"breakpoint", "see https://example.com/a/b", 16, "see https://\nexample.com/a/b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapMarkdown(tt.text, tt.width); got != tt.want {
+				t.Errorf("wrapMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package approval decides whether a command an AI generated may run
+// without asking. The decisions are recorded in the audit log.
+package approval
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Decision is what happens to a command.
+type Decision string
+
+const (
+	Allow Decision = "allow" // run without asking
+	Ask   Decision = "ask"   // run once the user approves
+	Deny  Decision = "deny"  // never run
+)
+
+// Categories are the built-in kinds of command that are always asked
+// about, even when an allow pattern matches.
+var Categories = map[string]*regexp.Regexp{
+	"destructive":   regexp.MustCompile(`\brm\s+-\w*[rf]|\b(mkfs|dd|shred|truncate)\b|\b(delete|destroy|drop|prune|purge)\b|\bgit\s+(push\s+.*(-f\b|--force)|reset\s+--hard|clean\s+-\w*f)`),
+	"privileged":    regexp.MustCompile(`\b(sudo|su|doas)\b|\bchmod\s+(-R\s+)?[0-7]*7[0-7]{0,2}\b|\bchown\b`),
+	"remote_script": regexp.MustCompile(`\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z)?sh\b`),
+	"cloud":         regexp.MustCompile(`^\s*(az|aws|gcloud)\s|\b(kubectl|helm)\s+(apply|create|delete|install|upgrade|uninstall|scale|rollout)\b|\bterraform\s+(apply|destroy|import)\b`),
+}
+
+// Result is the decision for a command and why it was made.
+type Result struct {
+	Decision Decision
+	Reason   string
+}
+
+// Engine evaluates commands against an approval policy.
+type Engine struct {
+	allow     []*regexp.Regexp
+	deny      []*regexp.Regexp
+	alwaysAsk []string
+	fallback  Decision
+}
+
+// New compiles cfg. AlwaysAsk defaults to every category.
+func New(cfg config.ApprovalConfig) (*Engine, error) {
+	e := &Engine{alwaysAsk: cfg.AlwaysAsk, fallback: Ask}
+	if cfg.AlwaysAsk == nil {
+		for name := range Categories {
+			e.alwaysAsk = append(e.alwaysAsk, name)
+		}
+		sort.Strings(e.alwaysAsk)
+	}
+	for _, name := range e.alwaysAsk {
+		if _, ok := Categories[name]; !ok {
+			return nil, fmt.Errorf("unknown approval category %q", name)
+		}
+	}
+
+	switch Decision(cfg.Default) {
+	case "", Ask:
+	case Allow:
+		e.fallback = Allow
+	default:
+		return nil, fmt.Errorf("invalid approval default %q: use ask or allow", cfg.Default)
+	}
+
+	var err error
+	if e.allow, err = compile(cfg.Allow); err != nil {
+		return nil, err
+	}
+	if e.deny, err = compile(cfg.Deny); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Evaluate decides whether command may run.
+func (e *Engine) Evaluate(command string) Result {
+	for _, re := range e.deny {
+		if re.MatchString(command) {
+			return Result{Decision: Deny, Reason: fmt.Sprintf("matches deny pattern %q", re.String())}
+		}
+	}
+	for _, name := range e.alwaysAsk {
+		if Categories[name].MatchString(command) {
+			return Result{Decision: Ask, Reason: "looks " + name}
+		}
+	}
+	for _, re := range e.allow {
+		if re.MatchString(command) {
+			return Result{Decision: Allow, Reason: fmt.Sprintf("matches allow pattern %q", re.String())}
+		}
+	}
+	if e.fallback == Allow {
+		return Result{Decision: Allow, Reason: "allowed by default"}
+	}
+	return Result{Decision: Ask, Reason: "not on the allow list"}
+}
+
+func compile(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile approval pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// Outcomes recorded in the audit log.
+const (
+	Allowed  = "allowed"  // ran without asking
+	Approved = "approved" // the user approved it
+	Rejected = "rejected" // the user declined it
+	Denied   = "denied"   // the policy refused it
+)
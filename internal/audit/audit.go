@@ -0,0 +1,248 @@
+// Package audit keeps an append-only log of the commands, MCP tool calls,
+// agent runs, plugin calls and deploy actions run from skitz, and of the
+// approval decisions about AI-generated commands, for compliance. Unlike
+// history it can't be trimmed or turned off from skitz, and records who
+// ran what, where: the user, host and working directory of every entry.
+//
+// Entries go to a JSONL file, syslog or a webhook, chosen by the audit
+// settings passed to Configure. Record is safe to call from any goroutine.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"log/syslog"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Kinds of entry.
+const (
+	KindCommand = "command"
+	KindJob     = "job"
+	KindMCP     = "mcp"
+	KindDeploy  = "deploy"
+	KindAgent   = "agent"
+	KindPlugin  = "plugin"
+
+	// KindApproval is a decision about a command an AI generated, whose
+	// status is one of the approval package's outcomes
+	KindApproval = "approval"
+)
+
+// Statuses of entry.
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusStarted = "started" // a job, whose exit isn't waited for
+)
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	Kind       string    `json:"kind"`
+	Action     string    `json:"action"`           // command line, tool name, plugin call or deploy action
+	Target     string    `json:"target,omitempty"` // resource, MCP server, AI provider, plugin or deployment
+	Mode       string    `json:"mode,omitempty"`   // how a command ran, e.g. embedded
+	Status     string    `json:"status"`
+	ExitCode   *int      `json:"exit_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Reason     string    `json:"reason,omitempty"` // why an approval decision was made
+	DurationMS int64     `json:"duration_ms,omitempty"`
+	User       string    `json:"user"`
+	Host       string    `json:"host"`
+	Cwd        string    `json:"cwd"`
+}
+
+// Sink writes entries somewhere.
+type Sink interface {
+	Write(e Entry) error
+}
+
+// Path returns the default audit log in dataDir.
+func Path(dataDir string) string {
+	return filepath.Join(dataDir, "audit.jsonl")
+}
+
+// NewSink returns the sink cfg names.
+func NewSink(cfg config.AuditConfig, dataDir string) (Sink, error) {
+	switch cfg.Sink {
+	case "", config.AuditSinkFile:
+		path := cfg.Path
+		if path == "" {
+			path = Path(dataDir)
+		}
+		return FileSink{Path: path}, nil
+	case config.AuditSinkSyslog:
+		w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_AUTH, "skitz")
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		return syslogSink{w: w}, nil
+	case config.AuditSinkWebhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("audit sink %q needs a url", cfg.Sink)
+		}
+		return WebhookSink{URL: cfg.URL}, nil
+	}
+	return nil, fmt.Errorf("unknown audit sink %q: use file, syslog or webhook", cfg.Sink)
+}
+
+// FileSink appends entries to a JSONL file.
+type FileSink struct {
+	Path string
+}
+
+func (s FileSink) Write(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+func (s syslogSink) Write(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	return s.w.Info(string(data))
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// WebhookSink posts each entry as JSON to URL.
+type WebhookSink struct {
+	URL string
+}
+
+func (s WebhookSink) Write(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create audit request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post audit entry: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("audit webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// flushTimeout bounds how long Close waits for entries still being written
+const flushTimeout = 5 * time.Second
+
+var (
+	mu      sync.Mutex
+	sink    Sink
+	pending sync.WaitGroup
+	writeMu sync.Mutex
+
+	// identity is the user and host recorded with every entry
+	identity = sync.OnceValues(func() (string, string) {
+		name := os.Getenv("USER")
+		if u, err := user.Current(); err == nil {
+			name = u.Username
+		}
+		host, _ := os.Hostname()
+		return name, host
+	})
+)
+
+// Configure starts recording to the sink cfg names, or stops recording
+// when cfg isn't enabled.
+func Configure(cfg config.AuditConfig, dataDir string) error {
+	var s Sink
+	if cfg.Enabled {
+		var err error
+		if s, err = NewSink(cfg, dataDir); err != nil {
+			return err
+		}
+	}
+	mu.Lock()
+	sink = s
+	mu.Unlock()
+	return nil
+}
+
+// Enabled reports whether entries are recorded.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return sink != nil
+}
+
+// Record adds the time, user, host and working directory to e and writes
+// it in the background. Failures are logged; they never stop what is being
+// recorded.
+func Record(e Entry) {
+	mu.Lock()
+	s := sink
+	mu.Unlock()
+	if s == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.User, e.Host = identity()
+	e.Cwd, _ = os.Getwd()
+
+	pending.Go(func() {
+		// One write at a time keeps a slow webhook from being flooded
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := s.Write(e); err != nil {
+			slog.Warn("failed to record audit entry", "kind", e.Kind, "action", e.Action, "error", err)
+		}
+	})
+}
+
+// Close waits for entries still being written, for up to five seconds.
+func Close() {
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(flushTimeout):
+		slog.Warn("audit entries still being written at exit")
+	}
+}
@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestRecord(t *testing.T) {
+	dir := t.TempDir()
+	if err := Configure(config.AuditConfig{Enabled: true}, dir); err != nil {
+		t.Fatal(err)
+	}
+	defer Configure(config.AuditConfig{}, dir)
+
+	code := 2
+	Record(Entry{Kind: KindCommand, Action: "make test", Target: "go", Status: StatusFailed, ExitCode: &code})
+	Record(Entry{Kind: KindMCP, Action: "search", Target: "http://localhost:8001/mcp/", Status: StatusOK})
+	Close()
+
+	f, err := os.Open(Path(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []Entry
+	for scanner := bufio.NewScanner(f); scanner.Scan(); {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(entries))
+	}
+	cwd, _ := os.Getwd()
+	for _, e := range entries {
+		if e.Time.IsZero() || e.User == "" || e.Cwd != cwd {
+			t.Errorf("entry = %+v, want time, user and cwd", e)
+		}
+	}
+
+	// Disabled, nothing more is written
+	Configure(config.AuditConfig{}, dir)
+	if Enabled() {
+		t.Error("Enabled() after disabling")
+	}
+	Record(Entry{Kind: KindCommand, Action: "ls"})
+	Close()
+	data, _ := os.ReadFile(Path(dir))
+	if n := bytes.Count(data, []byte("\n")); n != 2 {
+		t.Errorf("%d lines after disabling, want 2", n)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Entry
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	sink, err := NewSink(config.AuditConfig{Sink: config.AuditSinkWebhook, URL: srv.URL}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(Entry{Kind: KindDeploy, Action: "teardown", Target: "azure/agent"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Kind != KindDeploy || got.Action != "teardown" {
+		t.Errorf("posted %+v", got)
+	}
+}
+
+func TestNewSinkErrors(t *testing.T) {
+	for _, cfg := range []config.AuditConfig{
+		{Sink: config.AuditSinkWebhook},
+		{Sink: "kafka"},
+	} {
+		if _, err := NewSink(cfg, t.TempDir()); err == nil {
+			t.Errorf("NewSink(%+v) succeeded", cfg)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package azure talks to Azure Resource Manager for the deploy wizard. It
+// uses the Azure SDK for Go when its credentials resolve, and falls back to
+// the az CLI otherwise.
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+)
+
+// Backend names reported by Client.Backend.
+const (
+	BackendSDK = "sdk"
+	BackendCLI = "cli"
+)
+
+// Sentinel errors returned by New and Client methods.
+var (
+	// ErrNoCredentials is returned when neither the SDK nor the az CLI can
+	// be used.
+	ErrNoCredentials = errors.New("no Azure credentials found (run az login or set AZURE_CLIENT_ID, AZURE_TENANT_ID and AZURE_CLIENT_SECRET)")
+	// ErrNotFound is returned when a container group does not exist.
+	ErrNotFound = errors.New("resource not found")
+)
+
+// Client is the subset of Azure management operations skitz needs. All
+// calls except Subscriptions are scoped to the client's subscription.
+type Client interface {
+	// Backend is BackendSDK or BackendCLI.
+	Backend() string
+	Subscriptions(ctx context.Context) ([]Subscription, error)
+	// Accounts lists Azure OpenAI and AI Services accounts.
+	Accounts(ctx context.Context) ([]Account, error)
+	Deployments(ctx context.Context, resourceGroup, account string) ([]Deployment, error)
+	// Key returns the account's primary API key.
+	Key(ctx context.Context, resourceGroup, account string) (string, error)
+	// CreateContainer creates a container group running one container and
+	// returns once it is provisioned.
+	CreateContainer(ctx context.Context, c Container) error
+	// ContainerState returns a container group's state, such as Running,
+	// Succeeded or Failed.
+	ContainerState(ctx context.Context, resourceGroup, name string) (string, error)
+	// StartContainer starts a stopped container group again.
+	StartContainer(ctx context.Context, resourceGroup, name string) error
+	DeleteContainer(ctx context.Context, resourceGroup, name string) error
+}
+
+// Subscription is an enabled Azure subscription.
+type Subscription struct {
+	ID         string
+	Name       string
+	TenantID   string
+	TenantName string
+	Default    bool
+}
+
+// Account is an Azure OpenAI or AI Services account.
+type Account struct {
+	Name          string
+	Kind          string
+	Location      string
+	ResourceGroup string
+	Endpoint      string
+}
+
+// Deployment is a model deployed to an account.
+type Deployment struct {
+	Name    string
+	Model   string
+	Version string
+}
+
+// Container describes a single-container group that runs once.
+type Container struct {
+	ResourceGroup string
+	Name          string
+	Location      string
+	Image         string
+	Command       []string
+	Env           map[string]string
+	// SecureEnv values are not shown in the portal or API responses.
+	SecureEnv map[string]string
+}
+
+// newSDKClient creates the SDK client; tests replace it.
+var newSDKClient = newSDK
+
+// New returns a client for subscription, or for the default subscription
+// when it is empty. The SDK is preferred; the az CLI is used only when the
+// SDK has no credentials.
+func New(ctx context.Context, subscription string) (Client, error) {
+	c, err := newSDKClient(ctx, subscription)
+	if err == nil {
+		return c, nil
+	}
+	slog.Debug("azure SDK credentials unavailable, using az CLI", "error", err)
+	if !CLIAvailable() {
+		return nil, fmt.Errorf("%w: %v", ErrNoCredentials, err)
+	}
+	return &cliClient{subscription: subscription}, nil
+}
+
+// CLIAvailable reports whether az is on PATH.
+func CLIAvailable() bool {
+	_, err := exec.LookPath("az")
+	return err == nil
+}
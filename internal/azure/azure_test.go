@@ -0,0 +1,54 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommandLine(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"/bin/sh", "-c", "echo hi"}, `'/bin/sh' '-c' 'echo hi'`},
+		{[]string{"python3", "-c", "print('x')"}, `'python3' '-c' 'print('\''x'\'')'`},
+	}
+
+	for _, tt := range tests {
+		if got := commandLine(tt.args); got != tt.want {
+			t.Errorf("commandLine(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	defer func(orig func(context.Context, string) (Client, error)) { newSDKClient = orig }(newSDKClient)
+	sdkErr := errors.New("DefaultAzureCredential: failed to acquire a token")
+	newSDKClient = func(context.Context, string) (Client, error) { return nil, sdkErr }
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	if _, err := New(ctx, ""); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("New() without credentials error = %v, want ErrNoCredentials", err)
+	}
+
+	// The CLI is used when the SDK has no credentials
+	if err := os.WriteFile(filepath.Join(dir, "az"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := New(ctx, "sub"); err != nil || c.Backend() != BackendCLI {
+		t.Errorf("New() with az = %v, %v; want the CLI", c, err)
+	}
+
+	// and the SDK whenever its credentials resolve
+	newSDKClient = func(_ context.Context, subscription string) (Client, error) {
+		return &sdkClient{cred: fakeCredential{}, subscription: subscription}, nil
+	}
+	if c, err := New(ctx, "sub"); err != nil || c.Backend() != BackendSDK {
+		t.Errorf("New() with SDK credentials = %v, %v; want the SDK", c, err)
+	}
+}
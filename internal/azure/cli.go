@@ -0,0 +1,216 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// cliClient implements Client by running az. With no subscription set, az
+// uses the CLI's current one.
+type cliClient struct {
+	subscription string
+}
+
+func (*cliClient) Backend() string { return BackendCLI }
+
+// args appends --subscription to az arguments when a subscription is set.
+func (c *cliClient) args(args ...string) []string {
+	if c.subscription != "" {
+		args = append(args, "--subscription", c.subscription)
+	}
+	return args
+}
+
+func (*cliClient) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	var subs []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		TenantID   string `json:"tenantId"`
+		TenantName string `json:"tenantName"`
+		IsDefault  bool   `json:"isDefault"`
+	}
+	if err := azJSON(ctx, &subs, "account", "list", "--all",
+		"--query", "[?state=='Enabled'].{id:id, name:name, tenantId:tenantId, tenantName:tenantDisplayName, isDefault:isDefault}",
+	); err != nil {
+		return nil, fmt.Errorf("failed to list Azure subscriptions: %w", err)
+	}
+
+	result := make([]Subscription, 0, len(subs))
+	for _, s := range subs {
+		result = append(result, Subscription{
+			ID:         s.ID,
+			Name:       s.Name,
+			TenantID:   s.TenantID,
+			TenantName: s.TenantName,
+			Default:    s.IsDefault,
+		})
+	}
+	return result, nil
+}
+
+func (c *cliClient) Accounts(ctx context.Context) ([]Account, error) {
+	var accounts []struct {
+		Name          string `json:"name"`
+		Kind          string `json:"kind"`
+		Location      string `json:"location"`
+		ResourceGroup string `json:"resourceGroup"`
+		Endpoint      string `json:"endpoint"`
+	}
+	if err := azJSON(ctx, &accounts, c.args("cognitiveservices", "account", "list",
+		"--query", "[?kind=='OpenAI' || kind=='AIServices' || kind=='CognitiveServices'].{name:name, resourceGroup:resourceGroup, location:location, endpoint:properties.endpoint, kind:kind}",
+	)...); err != nil {
+		return nil, fmt.Errorf("failed to list Azure AI accounts: %w", err)
+	}
+
+	result := make([]Account, 0, len(accounts))
+	for _, a := range accounts {
+		result = append(result, Account(a))
+	}
+	return result, nil
+}
+
+func (c *cliClient) Deployments(ctx context.Context, resourceGroup, account string) ([]Deployment, error) {
+	var deployments []struct {
+		Name    string `json:"name"`
+		Model   string `json:"model"`
+		Version string `json:"version"`
+	}
+	if err := azJSON(ctx, &deployments, c.args("cognitiveservices", "account", "deployment", "list",
+		"--resource-group", resourceGroup,
+		"--name", account,
+		"--query", "[].{name:name, model:properties.model.name, version:properties.model.version}",
+	)...); err != nil {
+		return nil, fmt.Errorf("failed to list model deployments: %w", err)
+	}
+
+	result := make([]Deployment, 0, len(deployments))
+	for _, d := range deployments {
+		result = append(result, Deployment(d))
+	}
+	return result, nil
+}
+
+func (c *cliClient) Key(ctx context.Context, resourceGroup, account string) (string, error) {
+	out, err := az(ctx, c.args("cognitiveservices", "account", "keys", "list",
+		"--resource-group", resourceGroup,
+		"--name", account,
+		"--query", "key1",
+		"-o", "tsv",
+	)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get API key: %w", err)
+	}
+	if len(out) == 0 {
+		return "", errors.New("failed to get API key: account has no keys")
+	}
+	return string(out), nil
+}
+
+func (c *cliClient) CreateContainer(ctx context.Context, ct Container) error {
+	args := []string{
+		"container", "create",
+		"--resource-group", ct.ResourceGroup,
+		"--name", ct.Name,
+		"--image", ct.Image,
+		"--restart-policy", "Never",
+		"--location", ct.Location,
+		"--command-line", commandLine(ct.Command),
+	}
+	if len(ct.Env) > 0 {
+		args = append(append(args, "--environment-variables"), envPairs(ct.Env)...)
+	}
+	if len(ct.SecureEnv) > 0 {
+		args = append(append(args, "--secure-environment-variables"), envPairs(ct.SecureEnv)...)
+	}
+
+	if _, err := az(ctx, c.args(args...)...); err != nil {
+		return fmt.Errorf("failed to create container: %w", err)
+	}
+	return nil
+}
+
+func (c *cliClient) ContainerState(ctx context.Context, resourceGroup, name string) (string, error) {
+	out, err := az(ctx, c.args("container", "show",
+		"--resource-group", resourceGroup,
+		"--name", name,
+		"--query", "instanceView.state",
+		"-o", "tsv",
+	)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get container state: %w", cliNotFound(err))
+	}
+	return string(out), nil
+}
+
+func (c *cliClient) StartContainer(ctx context.Context, resourceGroup, name string) error {
+	if _, err := az(ctx, c.args("container", "start", "--resource-group", resourceGroup, "--name", name)...); err != nil {
+		return fmt.Errorf("failed to start container: %w", cliNotFound(err))
+	}
+	return nil
+}
+
+func (c *cliClient) DeleteContainer(ctx context.Context, resourceGroup, name string) error {
+	if _, err := az(ctx, c.args("container", "delete", "--resource-group", resourceGroup, "--name", name, "--yes")...); err != nil {
+		return fmt.Errorf("failed to delete container: %w", cliNotFound(err))
+	}
+	return nil
+}
+
+// cliNotFound maps az's ResourceNotFound error to ErrNotFound.
+func cliNotFound(err error) error {
+	if strings.Contains(err.Error(), "ResourceNotFound") {
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	return err
+}
+
+// az runs the CLI and returns its trimmed stdout, including stderr in the
+// error when it fails.
+func az(ctx context.Context, args ...string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, "az", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	return []byte(strings.TrimSpace(string(out))), nil
+}
+
+// azJSON runs the CLI with JSON output and decodes it into v.
+func azJSON(ctx context.Context, v any, args ...string) error {
+	out, err := az(ctx, append(args, "-o", "json")...)
+	if err != nil {
+		return err
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return json.Unmarshal(out, v)
+}
+
+// envPairs returns env as sorted KEY=VALUE arguments.
+func envPairs(env map[string]string) []string {
+	pairs := make([]string, 0, len(env))
+	for k, v := range env {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// commandLine joins args into the shell-style string az container create
+// splits back into the container command.
+func commandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,326 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/cognitiveservices/armcognitiveservices"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerinstance/armcontainerinstance/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+)
+
+// managementScope is the token scope for Azure Resource Manager.
+const managementScope = "https://management.azure.com/.default"
+
+var errNoSubscription = errors.New("no Azure subscription selected (pick one or set AZURE_SUBSCRIPTION_ID)")
+
+// sdkClient implements Client with the Azure SDK for Go.
+type sdkClient struct {
+	cred         azcore.TokenCredential
+	subscription string
+	options      *arm.ClientOptions // nil for the SDK defaults
+}
+
+// newSDK resolves DefaultAzureCredential (environment, workload identity,
+// managed identity, az login, ...) and fails if it cannot get a token, so
+// New can fall back to the CLI.
+func newSDK(ctx context.Context, subscription string) (Client, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
+	}
+
+	tokenCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if _, err := cred.GetToken(tokenCtx, policy.TokenRequestOptions{Scopes: []string{managementScope}}); err != nil {
+		return nil, fmt.Errorf("failed to get Azure token: %w", err)
+	}
+
+	if subscription == "" {
+		subscription = os.Getenv("AZURE_SUBSCRIPTION_ID")
+	}
+	return &sdkClient{cred: cred, subscription: subscription}, nil
+}
+
+func (*sdkClient) Backend() string { return BackendSDK }
+
+func (c *sdkClient) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	tenantNames, err := c.tenantNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := armsubscriptions.NewClient(c.cred, c.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscriptions client: %w", err)
+	}
+
+	var result []Subscription
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Azure subscriptions: %w", err)
+		}
+		for _, s := range page.Value {
+			if s.State == nil || *s.State != armsubscriptions.SubscriptionStateEnabled {
+				continue
+			}
+			sub := Subscription{
+				ID:       deref(s.SubscriptionID),
+				Name:     deref(s.DisplayName),
+				TenantID: deref(s.TenantID),
+			}
+			sub.TenantName = tenantNames[sub.TenantID]
+			sub.Default = sub.ID == c.subscription
+			result = append(result, sub)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// tenantNames maps the IDs of the tenants the credential can see to their
+// display names.
+func (c *sdkClient) tenantNames(ctx context.Context) (map[string]string, error) {
+	client, err := armsubscriptions.NewTenantsClient(c.cred, c.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tenants client: %w", err)
+	}
+
+	names := make(map[string]string)
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Azure tenants: %w", err)
+		}
+		for _, t := range page.Value {
+			names[deref(t.TenantID)] = deref(t.DisplayName)
+		}
+	}
+	return names, nil
+}
+
+func (c *sdkClient) Accounts(ctx context.Context) ([]Account, error) {
+	if c.subscription == "" {
+		return nil, errNoSubscription
+	}
+	client, err := armcognitiveservices.NewAccountsClient(c.subscription, c.cred, c.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create accounts client: %w", err)
+	}
+
+	var result []Account
+	pager := client.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Azure AI accounts: %w", err)
+		}
+		for _, a := range page.Value {
+			switch deref(a.Kind) {
+			case "OpenAI", "AIServices", "CognitiveServices":
+			default:
+				continue
+			}
+			account := Account{
+				Name:     deref(a.Name),
+				Kind:     deref(a.Kind),
+				Location: deref(a.Location),
+			}
+			if id, err := arm.ParseResourceID(deref(a.ID)); err == nil {
+				account.ResourceGroup = id.ResourceGroupName
+			}
+			if a.Properties != nil {
+				account.Endpoint = deref(a.Properties.Endpoint)
+			}
+			result = append(result, account)
+		}
+	}
+	return result, nil
+}
+
+func (c *sdkClient) Deployments(ctx context.Context, resourceGroup, account string) ([]Deployment, error) {
+	if c.subscription == "" {
+		return nil, errNoSubscription
+	}
+	client, err := armcognitiveservices.NewDeploymentsClient(c.subscription, c.cred, c.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deployments client: %w", err)
+	}
+
+	var result []Deployment
+	pager := client.NewListPager(resourceGroup, account, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list model deployments: %w", err)
+		}
+		for _, d := range page.Value {
+			deployment := Deployment{Name: deref(d.Name)}
+			if d.Properties != nil && d.Properties.Model != nil {
+				deployment.Model = deref(d.Properties.Model.Name)
+				deployment.Version = deref(d.Properties.Model.Version)
+			}
+			result = append(result, deployment)
+		}
+	}
+	return result, nil
+}
+
+func (c *sdkClient) Key(ctx context.Context, resourceGroup, account string) (string, error) {
+	if c.subscription == "" {
+		return "", errNoSubscription
+	}
+	client, err := armcognitiveservices.NewAccountsClient(c.subscription, c.cred, c.options)
+	if err != nil {
+		return "", fmt.Errorf("failed to create accounts client: %w", err)
+	}
+
+	resp, err := client.ListKeys(ctx, resourceGroup, account, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get API key: %w", err)
+	}
+	if deref(resp.Key1) == "" {
+		return "", errors.New("failed to get API key: account has no keys")
+	}
+	return *resp.Key1, nil
+}
+
+func (c *sdkClient) CreateContainer(ctx context.Context, ct Container) error {
+	client, err := c.containerGroups()
+	if err != nil {
+		return err
+	}
+
+	var env []*armcontainerinstance.EnvironmentVariable
+	for _, pair := range envPairs(ct.Env) {
+		name, value, _ := strings.Cut(pair, "=")
+		env = append(env, &armcontainerinstance.EnvironmentVariable{Name: to.Ptr(name), Value: to.Ptr(value)})
+	}
+	for _, pair := range envPairs(ct.SecureEnv) {
+		name, value, _ := strings.Cut(pair, "=")
+		env = append(env, &armcontainerinstance.EnvironmentVariable{Name: to.Ptr(name), SecureValue: to.Ptr(value)})
+	}
+
+	command := make([]*string, len(ct.Command))
+	for i, arg := range ct.Command {
+		command[i] = to.Ptr(arg)
+	}
+
+	group := armcontainerinstance.ContainerGroup{
+		Location: to.Ptr(ct.Location),
+		Properties: &armcontainerinstance.ContainerGroupPropertiesProperties{
+			OSType:        to.Ptr(armcontainerinstance.OperatingSystemTypesLinux),
+			RestartPolicy: to.Ptr(armcontainerinstance.ContainerGroupRestartPolicyNever),
+			Containers: []*armcontainerinstance.Container{{
+				Name: to.Ptr(ct.Name),
+				Properties: &armcontainerinstance.ContainerProperties{
+					Image:                to.Ptr(ct.Image),
+					Command:              command,
+					EnvironmentVariables: env,
+					Resources: &armcontainerinstance.ResourceRequirements{
+						Requests: &armcontainerinstance.ResourceRequests{
+							CPU:        to.Ptr(1.0),
+							MemoryInGB: to.Ptr(1.5),
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	poller, err := client.BeginCreateOrUpdate(ctx, ct.ResourceGroup, ct.Name, group, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create container: %w", err)
+	}
+	if _, err := poller.PollUntilDone(ctx, nil); err != nil {
+		return fmt.Errorf("failed to create container: %w", err)
+	}
+	return nil
+}
+
+func (c *sdkClient) containerGroups() (*armcontainerinstance.ContainerGroupsClient, error) {
+	if c.subscription == "" {
+		return nil, errNoSubscription
+	}
+	client, err := armcontainerinstance.NewContainerGroupsClient(c.subscription, c.cred, c.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create container groups client: %w", err)
+	}
+	return client, nil
+}
+
+func (c *sdkClient) ContainerState(ctx context.Context, resourceGroup, name string) (string, error) {
+	client, err := c.containerGroups()
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Get(ctx, resourceGroup, name, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get container state: %w", sdkNotFound(err))
+	}
+	if resp.Properties == nil || resp.Properties.InstanceView == nil {
+		return "", nil
+	}
+	return deref(resp.Properties.InstanceView.State), nil
+}
+
+func (c *sdkClient) StartContainer(ctx context.Context, resourceGroup, name string) error {
+	client, err := c.containerGroups()
+	if err != nil {
+		return err
+	}
+	poller, err := client.BeginStart(ctx, resourceGroup, name, nil)
+	if err == nil {
+		_, err = poller.PollUntilDone(ctx, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start container: %w", sdkNotFound(err))
+	}
+	return nil
+}
+
+func (c *sdkClient) DeleteContainer(ctx context.Context, resourceGroup, name string) error {
+	client, err := c.containerGroups()
+	if err != nil {
+		return err
+	}
+	poller, err := client.BeginDelete(ctx, resourceGroup, name, nil)
+	if err == nil {
+		_, err = poller.PollUntilDone(ctx, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete container: %w", sdkNotFound(err))
+	}
+	return nil
+}
+
+// sdkNotFound maps a 404 response to ErrNotFound.
+func sdkNotFound(err error) error {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	return err
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
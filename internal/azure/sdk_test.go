@@ -0,0 +1,119 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// fakeCredential hands out a fixed token.
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// fakeTransport answers requests by URL path and records the paths it saw.
+type fakeTransport struct {
+	responses map[string]string // path -> JSON body; other paths are 404s
+	paths     []string
+}
+
+func (f *fakeTransport) Do(req *http.Request) (*http.Response, error) {
+	f.paths = append(f.paths, req.URL.Path)
+	status, body := http.StatusOK, f.responses[req.URL.Path]
+	if body == "" {
+		status, body = http.StatusNotFound, `{"error":{"code":"ResourceNotFound","message":"not found"}}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func newFakeSDK(subscription string, responses map[string]string) (*sdkClient, *fakeTransport) {
+	transport := &fakeTransport{responses: responses}
+	options := &arm.ClientOptions{ClientOptions: policy.ClientOptions{
+		Transport: transport,
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	}}
+	return &sdkClient{cred: fakeCredential{}, subscription: subscription, options: options}, transport
+}
+
+func TestSDKSubscriptions(t *testing.T) {
+	c, _ := newFakeSDK("sub-b", map[string]string{
+		"/tenants": `{"value":[{"tenantId":"t1","displayName":"Contoso"}]}`,
+		"/subscriptions": `{"value":[
+			{"subscriptionId":"sub-b","displayName":"Beta","tenantId":"t1","state":"Enabled"},
+			{"subscriptionId":"sub-c","displayName":"Gamma","tenantId":"t1","state":"Disabled"},
+			{"subscriptionId":"sub-a","displayName":"Alpha","tenantId":"t2","state":"Enabled"}
+		]}`,
+	})
+
+	subs, err := c.Subscriptions(context.Background())
+	if err != nil {
+		t.Fatalf("Subscriptions() error = %v", err)
+	}
+	want := []Subscription{
+		{ID: "sub-a", Name: "Alpha", TenantID: "t2"},
+		{ID: "sub-b", Name: "Beta", TenantID: "t1", TenantName: "Contoso", Default: true},
+	}
+	if len(subs) != len(want) {
+		t.Fatalf("Subscriptions() = %+v, want %+v", subs, want)
+	}
+	for i := range want {
+		if subs[i] != want[i] {
+			t.Errorf("Subscriptions()[%d] = %+v, want %+v", i, subs[i], want[i])
+		}
+	}
+}
+
+func TestSDKAccounts(t *testing.T) {
+	c, _ := newFakeSDK("sub", map[string]string{
+		"/subscriptions/sub/providers/Microsoft.CognitiveServices/accounts": `{"value":[
+			{"id":"/subscriptions/sub/resourceGroups/rg1/providers/Microsoft.CognitiveServices/accounts/oai","name":"oai","kind":"OpenAI","location":"eastus","properties":{"endpoint":"https://oai.openai.azure.com/"}},
+			{"id":"/subscriptions/sub/resourceGroups/rg1/providers/Microsoft.CognitiveServices/accounts/speech","name":"speech","kind":"SpeechServices","location":"eastus"}
+		]}`,
+		"/subscriptions/sub/resourceGroups/rg1/providers/Microsoft.CognitiveServices/accounts/oai/listKeys": `{"key1":"secret"}`,
+	})
+	ctx := context.Background()
+
+	accounts, err := c.Accounts(ctx)
+	if err != nil {
+		t.Fatalf("Accounts() error = %v", err)
+	}
+	want := Account{Name: "oai", Kind: "OpenAI", Location: "eastus", ResourceGroup: "rg1", Endpoint: "https://oai.openai.azure.com/"}
+	if len(accounts) != 1 || accounts[0] != want {
+		t.Errorf("Accounts() = %+v, want [%+v]", accounts, want)
+	}
+
+	if key, err := c.Key(ctx, "rg1", "oai"); err != nil || key != "secret" {
+		t.Errorf("Key() = %q, %v", key, err)
+	}
+}
+
+func TestSDKContainerNotFound(t *testing.T) {
+	c, transport := newFakeSDK("sub", nil)
+
+	_, err := c.ContainerState(context.Background(), "rg", "agent")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("ContainerState() error = %v, want ErrNotFound", err)
+	}
+	if want := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.ContainerInstance/containerGroups/agent"; len(transport.paths) != 1 || transport.paths[0] != want {
+		t.Errorf("requested %q, want [%s]", transport.paths, want)
+	}
+
+	if _, err := (&sdkClient{cred: fakeCredential{}}).Accounts(context.Background()); !errors.Is(err, errNoSubscription) {
+		t.Errorf("Accounts() without subscription error = %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Package calc evaluates the arithmetic and unit conversions typed into the
+// command palette.
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotExpression is returned for input that isn't arithmetic, such as a
+// lone number or a word
+var ErrNotExpression = errors.New("not an expression")
+
+// Eval evaluates an arithmetic expression of numbers, + - * / % ^ and
+// parentheses. Input without an operator is not an expression.
+func Eval(expr string) (float64, error) {
+	if !strings.ContainsAny(expr, "+-*/%^") {
+		return 0, ErrNotExpression
+	}
+	p := &parser{s: expr}
+	v, err := p.sum()
+	if err != nil {
+		return 0, err
+	}
+	if p.skipSpace(); p.pos < len(p.s) {
+		return 0, fmt.Errorf("unexpected %q", p.s[p.pos:])
+	}
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, errors.New("division by zero")
+	}
+	return v, nil
+}
+
+// parser is a recursive descent parser over s. ^ binds tightest and is
+// right associative.
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// next returns the next non-space byte, or 0 at the end
+func (p *parser) next() byte {
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) sum() (float64, error) {
+	v, err := p.product()
+	for err == nil {
+		op := p.next()
+		if op != '+' && op != '-' {
+			break
+		}
+		p.pos++
+		var r float64
+		if r, err = p.product(); op == '+' {
+			v += r
+		} else {
+			v -= r
+		}
+	}
+	return v, err
+}
+
+func (p *parser) product() (float64, error) {
+	v, err := p.power()
+	for err == nil {
+		op := p.next()
+		if op != '*' && op != '/' && op != '%' {
+			break
+		}
+		p.pos++
+		var r float64
+		r, err = p.power()
+		switch op {
+		case '*':
+			v *= r
+		case '/':
+			v /= r
+		case '%':
+			v = math.Mod(v, r)
+		}
+	}
+	return v, err
+}
+
+func (p *parser) power() (float64, error) {
+	v, err := p.unary()
+	if err != nil || p.next() != '^' {
+		return v, err
+	}
+	p.pos++
+	exp, err := p.power()
+	return math.Pow(v, exp), err
+}
+
+func (p *parser) unary() (float64, error) {
+	switch p.next() {
+	case '-':
+		p.pos++
+		v, err := p.unary()
+		return -v, err
+	case '+':
+		p.pos++
+		return p.unary()
+	case '(':
+		p.pos++
+		v, err := p.sum()
+		if err != nil {
+			return 0, err
+		}
+		if p.next() != ')' {
+			return 0, errors.New("missing )")
+		}
+		p.pos++
+		return v, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] >= '0' && p.s[p.pos] <= '9' || p.s[p.pos] == '.') {
+		p.pos++
+	}
+	if start == p.pos {
+		if start == len(p.s) {
+			return 0, errors.New("unexpected end")
+		}
+		return 0, fmt.Errorf("unexpected %q", p.s[start:])
+	}
+	return strconv.ParseFloat(p.s[start:p.pos], 64)
+}
+
+// unit is a unit of a dimension, in multiples of the dimension's base unit
+type unit struct {
+	dimension string
+	factor    float64
+}
+
+var units = map[string]unit{
+	"mm": {"length", 0.001}, "cm": {"length", 0.01}, "m": {"length", 1}, "km": {"length", 1000},
+	"in": {"length", 0.0254}, "ft": {"length", 0.3048}, "yd": {"length", 0.9144}, "mi": {"length", 1609.344},
+
+	"mg": {"mass", 0.001}, "g": {"mass", 1}, "kg": {"mass", 1000}, "oz": {"mass", 28.349523125}, "lb": {"mass", 453.59237},
+
+	"b": {"data", 1}, "kb": {"data", 1e3}, "mb": {"data", 1e6}, "gb": {"data", 1e9}, "tb": {"data", 1e12},
+	"kib": {"data", 1 << 10}, "mib": {"data", 1 << 20}, "gib": {"data", 1 << 30}, "tib": {"data", 1 << 40},
+
+	"ms": {"time", 0.001}, "s": {"time", 1}, "sec": {"time", 1}, "min": {"time", 60},
+	"h": {"time", 3600}, "hr": {"time", 3600}, "d": {"time", 86400}, "day": {"time", 86400}, "w": {"time", 604800},
+
+	"c": {"temperature", 0}, "f": {"temperature", 0},
+}
+
+var conversionRe = regexp.MustCompile(`^\s*(\S+?)\s*([a-zA-Z]*)\s+(?:to|in)\s+([a-zA-Z]+)\s*$`)
+
+// Convert converts a quantity to another unit of length, mass, data, time
+// or temperature: "5 km to mi", "2GiB in MB", "90m to h", "1h30m in min",
+// "20 C to F". It reports false for input that isn't a conversion.
+func Convert(query string) (float64, string, bool) {
+	m := conversionRe.FindStringSubmatch(query)
+	if m == nil {
+		return 0, "", false
+	}
+	amount, from, to := m[1], strings.ToLower(m[2]), strings.ToLower(m[3])
+	target, ok := units[to]
+	if !ok {
+		return 0, "", false
+	}
+
+	// Durations such as 90m or 1h30m are time.ParseDuration's
+	if target.dimension == "time" {
+		if d, err := time.ParseDuration(amount + from); err == nil {
+			return d.Seconds() / target.factor, m[3], true
+		}
+	}
+
+	source, ok := units[from]
+	if !ok || source.dimension != target.dimension {
+		return 0, "", false
+	}
+	v, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	if source.dimension == "temperature" {
+		switch {
+		case from == "c" && to == "f":
+			v = v*9/5 + 32
+		case from == "f" && to == "c":
+			v = (v - 32) * 5 / 9
+		}
+		return v, strings.ToUpper(to), true
+	}
+	return v * source.factor / target.factor, m[3], true
+}
+
+// Format formats a result without float noise, e.g. 0.3 for 0.1+0.2
+func Format(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'g', 12, 64)
+}
@@ -0,0 +1,76 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"1+2*3", "7"},
+		{"(1 + 2) * 3", "9"},
+		{"0.1 + 0.2", "0.3"},
+		{"2^3^2", "512"},
+		{"-4 + 10 / 4", "-1.5"},
+		{"17 % 5", "2"},
+		{"1024*1024*8", "8388608"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			v, err := Eval(tt.expr)
+			if err != nil {
+				t.Fatalf("Eval() error = %v", err)
+			}
+			if got := Format(v); got != tt.want {
+				t.Errorf("Eval() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalRejects(t *testing.T) {
+	for _, expr := range []string{"42", "deploy", "git-status", "1 +", "(2*3", "1/0"} {
+		if _, err := Eval(expr); err == nil {
+			t.Errorf("Eval(%q) error = nil", expr)
+		}
+	}
+	if _, err := Eval("docker"); !errors.Is(err, ErrNotExpression) {
+		t.Errorf("Eval() error = %v, want ErrNotExpression", err)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		unit  string
+	}{
+		{"5 km to mi", "3.10685596119", "mi"},
+		{"2GiB in MB", "2147.483648", "MB"},
+		{"90m to h", "1.5", "h"},
+		{"1h30m in min", "90", "min"},
+		{"1.5 d to h", "36", "h"},
+		{"100 C to F", "212", "F"},
+		{"3 ft to cm", "91.44", "cm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			v, unit, ok := Convert(tt.query)
+			if !ok {
+				t.Fatal("Convert() ok = false")
+			}
+			if got := Format(v); got != tt.want || unit != tt.unit {
+				t.Errorf("Convert() = %s %s, want %s %s", got, unit, tt.want, tt.unit)
+			}
+		})
+	}
+
+	for _, query := range []string{"5 km to kg", "deploy to prod", "logs in staging", "5 parsecs to m"} {
+		if _, _, ok := Convert(query); ok {
+			t.Errorf("Convert(%q) ok = true", query)
+		}
+	}
+}
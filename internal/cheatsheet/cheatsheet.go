@@ -0,0 +1,163 @@
+// Package cheatsheet renders a resource as a standalone HTML page for
+// printing or sharing, and converts it to PDF with wkhtmltopdf when that
+// is installed.
+package cheatsheet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// ErrNoPDF is returned when a PDF is asked for and wkhtmltopdf is not
+// installed.
+var ErrNoPDF = errors.New("wkhtmltopdf not found; install it or export HTML")
+
+// pdfTool converts HTML to PDF
+const pdfTool = "wkhtmltopdf"
+
+// Sheet is a resource to render.
+type Sheet struct {
+	Title       string
+	Description string
+	Sections    []Section
+}
+
+// Section is a part of a sheet with its content as markdown.
+type Section struct {
+	Title    string
+	Markdown string
+}
+
+// Resource files list one command per line, so line breaks are kept
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(html.WithHardWraps()),
+)
+
+var page = template.Must(template.New("sheet").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} cheat sheet</title>
+<style>
+body { font: 14px/1.45 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2328; max-width: 1100px; margin: 2em auto; padding: 0 1.5em; }
+header { border-bottom: 3px solid #7c3aed; margin-bottom: 1.5em; }
+header h1 { margin: 0; font-size: 2em; text-transform: uppercase; letter-spacing: .05em; color: #7c3aed; }
+header p { margin: .3em 0 .8em; color: #59636e; }
+main { columns: 2 420px; column-gap: 2.5em; }
+section { break-inside: avoid-column; margin-bottom: 1.5em; }
+h1, h2, h3 { line-height: 1.2; margin: .8em 0 .4em; }
+section > :first-child { margin-top: 0; }
+h1 { font-size: 1.4em; } h2 { font-size: 1.2em; color: #7c3aed; } h3 { font-size: 1.05em; }
+code { font: 12.5px/1.4 ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; background: #f3f0ff; border-radius: 4px; padding: .1em .35em; }
+pre { background: #f6f8fa; border-radius: 6px; padding: .8em 1em; overflow-x: auto; }
+pre code { background: none; padding: 0; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d1d9e0; padding: .3em .6em; text-align: left; vertical-align: top; }
+ul, ol { padding-left: 1.4em; }
+footer { margin-top: 2em; font-size: .85em; color: #818b98; }
+@media print { body { margin: 0; max-width: none; } pre { white-space: pre-wrap; } }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+</header>
+<main>
+{{range .Sections}}<section>
+{{.}}</section>
+{{end}}</main>
+<footer>Exported from skitz on {{.Date}}</footer>
+</body>
+</html>
+`))
+
+// HTML renders s as a standalone page with its styles inline. Raw HTML in
+// the markdown is left out, and so is a first heading repeating the title.
+func HTML(s Sheet) ([]byte, error) {
+	data := struct {
+		Title, Description, Date string
+		Sections                 []template.HTML
+	}{Title: s.Title, Description: s.Description, Date: time.Now().Format("Jan 2, 2006")}
+
+	for _, sec := range s.Sections {
+		md := strings.TrimSpace(sec.Markdown)
+		if first, rest, _ := strings.Cut(md, "\n"); strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(first, "# ")), s.Title) {
+			md = strings.TrimSpace(rest) // the header already shows the title
+		}
+		if md == "" {
+			continue
+		}
+		if !strings.HasPrefix(md, "#") && sec.Title != "" {
+			md = "## " + sec.Title + "\n\n" + md
+		}
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(md), &buf); err != nil {
+			return nil, fmt.Errorf("failed to render section %s: %w", sec.Title, err)
+		}
+		data.Sections = append(data.Sections, template.HTML(buf.String()))
+	}
+
+	var out bytes.Buffer
+	if err := page.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to render cheat sheet: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// PDFAvailable reports whether wkhtmltopdf is installed.
+func PDFAvailable() bool {
+	_, err := exec.LookPath(pdfTool)
+	return err == nil
+}
+
+// Write renders s to path, as a PDF when path ends in .pdf and as HTML
+// otherwise.
+func Write(ctx context.Context, s Sheet, path string) error {
+	doc, err := HTML(s)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".pdf") {
+		if err := os.WriteFile(path, doc, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return nil
+	}
+
+	if !PDFAvailable() {
+		return ErrNoPDF
+	}
+	tmp, err := os.CreateTemp("", "skitz-*.html")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(doc)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, pdfTool, "--quiet", "--enable-local-file-access", tmp.Name(), path).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to convert to PDF: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
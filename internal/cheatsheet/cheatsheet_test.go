@@ -0,0 +1,70 @@
+package cheatsheet
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	sheet := Sheet{
+		Title:       "docker",
+		Description: "Container management",
+		Sections: []Section{
+			{Title: "Commands", Markdown: "# Docker\n\n`docker ps` list containers\n`docker images` list images\n"},
+			{Title: "Cleanup", Markdown: "## Cleanup\n\n| Command | Frees |\n|---|---|\n| `docker system prune` | everything |\n"},
+			{Title: "Notes", Markdown: "Avoid <script>alert(1)</script> in notes"},
+			{Title: "Empty", Markdown: "# docker\n"},
+		},
+	}
+
+	doc, err := HTML(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(doc)
+	for _, want := range []string{
+		"<title>docker cheat sheet</title>",
+		"<p>Container management</p>",
+		"<code>docker ps</code> list containers<br>",
+		"<h2>Cleanup</h2>",
+		"<td><code>docker system prune</code></td>",
+		"<h2>Notes</h2>",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<script>") {
+		t.Error("raw HTML from markdown was kept")
+	}
+	if strings.Contains(page, "<h1>Docker</h1>") {
+		t.Error("heading repeating the title was kept")
+	}
+	if n := strings.Count(page, "<section>"); n != 3 {
+		t.Errorf("%d sections, want 3 without the empty one", n)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	sheet := Sheet{Title: "git", Sections: []Section{{Title: "Basics", Markdown: "`git status`"}}}
+
+	path := filepath.Join(dir, "git.html")
+	if err := Write(context.Background(), sheet, path); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), "<code>git status</code>") {
+		t.Errorf("wrote %q, %v", data, err)
+	}
+
+	if PDFAvailable() {
+		t.Skip("wkhtmltopdf is installed")
+	}
+	if err := Write(context.Background(), sheet, filepath.Join(dir, "git.pdf")); !errors.Is(err, ErrNoPDF) {
+		t.Errorf("Write(.pdf) = %v, want ErrNoPDF", err)
+	}
+}
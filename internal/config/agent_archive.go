@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultAgentHistoryItems is how many agent runs the history keeps
+// unless agent_history.max_items is set.
+const DefaultAgentHistoryItems = 50
+
+// AgentHistoryConfig controls how many agent runs the Agents tab history
+// keeps. Runs beyond MaxItems or older than MaxAge are moved to monthly
+// archives in the data directory, unless Archive is false.
+type AgentHistoryConfig struct {
+	MaxItems int           `yaml:"max_items,omitempty"` // defaults to DefaultAgentHistoryItems
+	MaxAge   time.Duration `yaml:"max_age,omitempty"`   // e.g. 720h; runs of any age are kept when zero
+	Archive  *bool         `yaml:"archive,omitempty"`   // default true; false drops pruned runs
+}
+
+// Limit returns the number of runs kept.
+func (c AgentHistoryConfig) Limit() int {
+	if c.MaxItems > 0 {
+		return c.MaxItems
+	}
+	return DefaultAgentHistoryItems
+}
+
+// Archives reports whether pruned runs are archived.
+func (c AgentHistoryConfig) Archives() bool {
+	return c.Archive == nil || *c.Archive
+}
+
+// PruneAgentHistory splits history, most recent first, into the runs kept
+// and those beyond the limits of c at now.
+func PruneAgentHistory(history []AgentInteraction, c AgentHistoryConfig, now time.Time) (kept, pruned []AgentInteraction) {
+	for i, entry := range history {
+		if i >= c.Limit() || (c.MaxAge > 0 && now.Sub(entry.Timestamp) > c.MaxAge) {
+			pruned = append(pruned, entry)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	return kept, pruned
+}
+
+// AgentArchiveDir returns the directory of the agent history archives.
+func AgentArchiveDir() string {
+	return filepath.Join(DataDir, "agent_archive")
+}
+
+// ArchiveAgentInteractions appends entries to the archive of the month
+// each ran in, e.g. agent_archive/2025-01.jsonl.gz: gzip-compressed JSON
+// lines, a gzip member per call.
+func ArchiveAgentInteractions(entries []AgentInteraction) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(AgentArchiveDir(), 0755); err != nil {
+		return err
+	}
+
+	byMonth := make(map[string][]AgentInteraction)
+	for _, entry := range entries {
+		month := entry.Timestamp.Format("2006-01")
+		byMonth[month] = append(byMonth[month], entry)
+	}
+	for month, monthEntries := range byMonth {
+		if err := appendAgentArchive(filepath.Join(AgentArchiveDir(), month+".jsonl.gz"), monthEntries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendAgentArchive appends entries to the archive at path as a new gzip
+// member
+func appendAgentArchive(path string, entries []AgentInteraction) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	enc := json.NewEncoder(zw)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// AgentArchive is an archive of the agent runs of a month.
+type AgentArchive struct {
+	Month string // e.g. "2025-01"
+	Path  string
+	Size  int64 // compressed bytes
+}
+
+// AgentArchives returns the agent history archives, most recent first.
+func AgentArchives() []AgentArchive {
+	files, err := os.ReadDir(AgentArchiveDir())
+	if err != nil {
+		return nil
+	}
+	var archives []AgentArchive
+	for _, file := range files {
+		month, ok := strings.CutSuffix(file.Name(), ".jsonl.gz")
+		if !ok || file.IsDir() {
+			continue
+		}
+		archive := AgentArchive{Month: month, Path: filepath.Join(AgentArchiveDir(), file.Name())}
+		if info, err := file.Info(); err == nil {
+			archive.Size = info.Size()
+		}
+		archives = append(archives, archive)
+	}
+	slices.Reverse(archives)
+	return archives
+}
+
+// LoadAgentArchive returns the runs in the archive at path, most recent
+// first.
+func LoadAgentArchive(path string) ([]AgentInteraction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var entries []AgentInteraction
+	dec := json.NewDecoder(bufio.NewReader(zr))
+	for {
+		var entry AgentInteraction
+		if err := dec.Decode(&entry); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+	slices.SortStableFunc(entries, func(a, b AgentInteraction) int {
+		return b.Timestamp.Compare(a.Timestamp)
+	})
+	return entries, nil
+}
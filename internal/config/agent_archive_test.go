@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestAgentArchive(t *testing.T) {
+	orig := DataDir
+	DataDir = t.TempDir()
+	defer func() { DataDir = orig }()
+
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	var history []AgentInteraction
+	for i := range 6 {
+		history = append(history, AgentInteraction{ID: fmt.Sprint(i), Agent: "reviewer", Timestamp: now.Add(-time.Duration(i) * 10 * 24 * time.Hour)})
+	}
+
+	kept, pruned := PruneAgentHistory(history, AgentHistoryConfig{}, now)
+	if len(kept) != 6 || len(pruned) != 0 {
+		t.Fatalf("default limits kept %d, pruned %d", len(kept), len(pruned))
+	}
+	kept, pruned = PruneAgentHistory(history, AgentHistoryConfig{MaxItems: 4, MaxAge: 25 * 24 * time.Hour}, now)
+	if len(kept) != 3 || len(pruned) != 3 || pruned[0].ID != "3" {
+		t.Fatalf("kept %d, pruned %+v", len(kept), pruned)
+	}
+	off := false
+	if (AgentHistoryConfig{Archive: &off}).Archives() || !(AgentHistoryConfig{}).Archives() {
+		t.Error("Archives() ignores archive setting")
+	}
+
+	// Two calls append to the same monthly archives
+	if err := ArchiveAgentInteractions(pruned[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := ArchiveAgentInteractions(pruned[2:]); err != nil {
+		t.Fatal(err)
+	}
+	archives := AgentArchives()
+	if len(archives) != 2 || archives[0].Month != "2025-02" || archives[1].Month != "2025-01" || archives[0].Size == 0 {
+		t.Fatalf("AgentArchives() = %+v", archives)
+	}
+	entries, err := LoadAgentArchive(archives[1].Path)
+	if err != nil || len(entries) != 2 || entries[0].ID != "4" || entries[1].ID != "5" {
+		t.Fatalf("LoadAgentArchive() = %+v, %v", entries, err)
+	}
+
+	if err := os.WriteFile(archives[1].Path, []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAgentArchive(archives[1].Path); err == nil {
+		t.Error("LoadAgentArchive() of a corrupt archive succeeded")
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSettingsFor(t *testing.T) {
+	var p ProviderConfig
+	err := yaml.Unmarshal([]byte(`
+name: openai
+temperature: 0.7
+max_tokens: 1000
+timeout: 2m
+features:
+  generate_command:
+    temperature: 0
+    system_prompt: Output only the command.
+`), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ask := p.SettingsFor(FeatureAsk)
+	if ask.Temperature == nil || *ask.Temperature != 0.7 || ask.MaxTokens != 1000 || ask.Timeout != 2*time.Minute || ask.SystemPrompt != "" {
+		t.Errorf("ask settings = %+v", ask)
+	}
+
+	gen := p.SettingsFor(FeatureGenerateCommand)
+	if gen.Temperature == nil || *gen.Temperature != 0 || gen.MaxTokens != 1000 || gen.SystemPrompt != "Output only the command." {
+		t.Errorf("generate_command settings = %+v", gen)
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MaxArgValues is how many values are remembered per argument.
+const MaxArgValues = 10
+
+// DefaultArgHistoryExclude are the argument names never remembered unless
+// arg_history.exclude is set.
+var DefaultArgHistoryExclude = []string{"*password*", "*passwd*", "*secret*", "*token*", "*api_key*", "*apikey*", "*credential*"}
+
+// ArgHistoryConfig controls which submitted MCP tool arguments and
+// placeholder values are remembered.
+type ArgHistoryConfig struct {
+	Disabled bool     `yaml:"disabled,omitempty"`
+	Exclude  []string `yaml:"exclude,omitempty"` // name patterns such as "*token*"; defaults to DefaultArgHistoryExclude
+}
+
+// Remembers reports whether values of the argument or placeholder name are
+// remembered.
+func (c ArgHistoryConfig) Remembers(name string) bool {
+	return !c.Disabled && !c.Excludes(name)
+}
+
+// Excludes reports whether name matches the exclude patterns: values of
+// the argument are never written to disk, by the MCP call history either.
+func (c ArgHistoryConfig) Excludes(name string) bool {
+	exclude := c.Exclude
+	if len(exclude) == 0 {
+		exclude = DefaultArgHistoryExclude
+	}
+	name = strings.ToLower(name)
+	for _, pattern := range exclude {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ArgHistory holds the values submitted for each argument, most recent
+// first, keyed by argument, e.g. "mcp:datadog.search_logs:query".
+type ArgHistory map[string][]string
+
+// LoadArgHistory loads the argument history from disk.
+func LoadArgHistory() ArgHistory {
+	data, err := os.ReadFile(filepath.Join(DataDir, "arg_history.json"))
+	if err != nil {
+		return ArgHistory{}
+	}
+
+	var h ArgHistory
+	if err := json.Unmarshal(data, &h); err != nil || h == nil {
+		return ArgHistory{}
+	}
+	return h
+}
+
+// SaveArgHistory saves the argument history to disk.
+func SaveArgHistory(h ArgHistory) error {
+	if err := os.MkdirAll(DataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(DataDir, "arg_history.json"), data, 0600)
+}
+
+// Add moves value to the front of key's values, keeping at most
+// MaxArgValues. Empty values are ignored.
+func (h ArgHistory) Add(key, value string) {
+	if value == "" {
+		return
+	}
+	values := slices.DeleteFunc(slices.Clone(h[key]), func(v string) bool { return v == value })
+	values = append([]string{value}, values...)
+	h[key] = values[:min(len(values), MaxArgValues)]
+}
+
+// Last returns the most recent value of key.
+func (h ArgHistory) Last(key string) (string, bool) {
+	if values := h[key]; len(values) > 0 {
+		return values[0], true
+	}
+	return "", false
+}
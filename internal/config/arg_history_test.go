@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestArgHistory(t *testing.T) {
+	orig := DataDir
+	DataDir = t.TempDir()
+	defer func() { DataDir = orig }()
+
+	h := ArgHistory{}
+	for i := range MaxArgValues + 2 {
+		h.Add("var:CLUSTER", fmt.Sprintf("c%d", i))
+	}
+	h.Add("var:CLUSTER", "c5")
+	h.Add("var:CLUSTER", "")
+	if values := h["var:CLUSTER"]; len(values) != MaxArgValues || values[0] != "c5" || values[1] != "c11" {
+		t.Fatalf("values = %v", values)
+	}
+
+	if err := SaveArgHistory(h); err != nil {
+		t.Fatal(err)
+	}
+	if last, ok := LoadArgHistory().Last("var:CLUSTER"); !ok || last != "c5" {
+		t.Errorf("Last() = %q, %v after reload", last, ok)
+	}
+
+	var cfg ArgHistoryConfig
+	if !cfg.Remembers("query") || cfg.Remembers("GITHUB_TOKEN") || cfg.Remembers("db_password") {
+		t.Error("default excludes not applied")
+	}
+	cfg.Exclude = []string{"query"}
+	if cfg.Remembers("Query") || !cfg.Remembers("token") {
+		t.Error("exclude doesn't replace the defaults")
+	}
+	cfg.Disabled = true
+	if cfg.Remembers("cluster") {
+		t.Error("disabled history remembers")
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
@@ -32,6 +33,410 @@ type Config struct {
 	AI           AIConfig           `yaml:"ai,omitempty"`
 	MCP          MCPConfig          `yaml:"mcp"`
 	SavedAgents  []SavedAgentConfig `yaml:"saved_agents,omitempty"`
+	Features     map[string]bool    `yaml:"features,omitempty"`
+	Execution    ExecutionConfig    `yaml:"execution,omitempty"`
+
+	// FavoriteMarks records when each favorite was last added or removed so
+	// that Save merges favorites changed elsewhere instead of overwriting
+	// them (see MergeFavorites). Removals are forgotten after
+	// FavoriteMarkTTL.
+	FavoriteMarks map[string]FavoriteMark `yaml:"favorite_marks,omitempty"`
+
+	// Sections adjusts a resource's section layout, keyed by resource name.
+	// Order and Default replace the resource's front-matter; Hidden adds to it.
+	Sections map[string]SectionLayout `yaml:"sections,omitempty"`
+
+	// Deploy remembers choices made in the Deploy Agent wizard.
+	Deploy DeployConfig `yaml:"deploy,omitempty"`
+
+	// E2B configures agent runs in E2B cloud sandboxes.
+	E2B E2BConfig `yaml:"e2b,omitempty"`
+
+	// Metrics configures the optional self-metrics endpoint.
+	Metrics MetricsConfig `yaml:"metrics,omitempty"`
+
+	// Notifications configures desktop notifications for long runs.
+	Notifications NotificationsConfig `yaml:"notifications,omitempty"`
+
+	// Hooks run before and after every ^run command.
+	Hooks HooksConfig `yaml:"hooks,omitempty"`
+
+	// Integrations posts agent and deploy results to chat webhooks.
+	Integrations IntegrationsConfig `yaml:"integrations,omitempty"`
+
+	// Offline configures how skitz detects that the network is down.
+	Offline OfflineConfig `yaml:"offline,omitempty"`
+
+	// Approval decides which AI-generated commands run without asking.
+	Approval ApprovalConfig `yaml:"approval,omitempty"`
+
+	// Recording saves embedded terminal sessions for replay and export.
+	Recording RecordingConfig `yaml:"recording,omitempty"`
+
+	// Updates controls the check for new skitz releases.
+	Updates UpdatesConfig `yaml:"updates,omitempty"`
+
+	// Registry is the index of community resource packs.
+	Registry RegistryConfig `yaml:"registry,omitempty"`
+
+	// Banner replaces the dashboard header's logo, title and quote.
+	Banner BannerConfig `yaml:"banner,omitempty"`
+
+	// Audit keeps an append-only log of every command, MCP tool call and
+	// deploy action, independent of history.
+	Audit AuditConfig `yaml:"audit,omitempty"`
+
+	// Branding replaces skitz's name and accent color, for teams running
+	// it as their own command center.
+	Branding BrandingConfig `yaml:"branding,omitempty"`
+
+	// Review defines the code review profiles offered in the palette.
+	Review ReviewConfig `yaml:"review,omitempty"`
+
+	// Secrets says where {{secret:NAME}} placeholders are read from, keyed
+	// by NAME. Other names come from $NAME, then the keychain.
+	Secrets map[string]SecretSource `yaml:"secrets,omitempty"`
+
+	// ArgHistory controls remembering the values typed into MCP parameter
+	// forms and for {{NAME}} placeholders.
+	ArgHistory ArgHistoryConfig `yaml:"arg_history,omitempty"`
+
+	// AgentHistory limits the agent runs kept in the Agents tab; older
+	// runs are archived.
+	AgentHistory AgentHistoryConfig `yaml:"agent_history,omitempty"`
+
+	// Capabilities are what the user may run: commands annotated
+	// ^requires:NAME only run when NAME is listed, e.g. prod-access.
+	Capabilities []string `yaml:"capabilities,omitempty"`
+
+	// Lock hides the screen after a period without input until the user
+	// confirms, or passes the unlock command.
+	Lock LockConfig `yaml:"lock,omitempty"`
+
+	// Accessibility renders plain text for screen readers: no borders,
+	// animations or emoji, and a status line for every change. The --a11y
+	// flag turns it on for one run.
+	Accessibility bool `yaml:"accessibility,omitempty"`
+
+	// Policy is loaded from PolicyPath and never written back.
+	Policy Policy `yaml:"-"`
+}
+
+// DefaultNotifyAfter is the default minimum run time before a desktop
+// notification is sent.
+const DefaultNotifyAfter = 30 * time.Second
+
+// NotificationsConfig controls desktop notifications for embedded commands
+// and agent runs that finish while skitz is unfocused or showing another
+// view.
+type NotificationsConfig struct {
+	Desktop     bool          `yaml:"desktop,omitempty"`
+	MinDuration time.Duration `yaml:"min_duration,omitempty"` // e.g. 30s; defaults to DefaultNotifyAfter
+}
+
+// Threshold returns the minimum run time that triggers a notification.
+func (c NotificationsConfig) Threshold() time.Duration {
+	if c.MinDuration <= 0 {
+		return DefaultNotifyAfter
+	}
+	return c.MinDuration
+}
+
+// MetricsConfig controls the Prometheus self-metrics endpoint. It is off
+// unless Listen is set.
+type MetricsConfig struct {
+	Listen string `yaml:"listen,omitempty"` // e.g. 127.0.0.1:9464
+}
+
+// DefaultProbeAddress is dialed to check connectivity unless
+// offline.probe_address is set.
+const DefaultProbeAddress = "1.1.1.1:443"
+
+// DefaultProbeInterval is how often connectivity is checked.
+const DefaultProbeInterval = 30 * time.Second
+
+// OfflineConfig configures offline detection. Offline mode can also be
+// toggled from the palette.
+type OfflineConfig struct {
+	AutoDetect    *bool         `yaml:"auto_detect,omitempty"`    // default true
+	ProbeAddress  string        `yaml:"probe_address,omitempty"`  // host:port
+	ProbeInterval time.Duration `yaml:"probe_interval,omitempty"` // e.g. 1m
+}
+
+// Detect reports whether connectivity is checked automatically.
+func (c OfflineConfig) Detect() bool {
+	return c.AutoDetect == nil || *c.AutoDetect
+}
+
+// Probe returns the address dialed to check connectivity.
+func (c OfflineConfig) Probe() string {
+	if c.ProbeAddress == "" {
+		return DefaultProbeAddress
+	}
+	return c.ProbeAddress
+}
+
+// Interval returns how often connectivity is checked.
+func (c OfflineConfig) Interval() time.Duration {
+	if c.ProbeInterval <= 0 {
+		return DefaultProbeInterval
+	}
+	return c.ProbeInterval
+}
+
+// DefaultUpdateInterval is the least time between update checks at startup.
+const DefaultUpdateInterval = 24 * time.Hour
+
+// UpdatesConfig controls the startup check for new releases. The palette's
+// What's New action works either way.
+type UpdatesConfig struct {
+	Check    *bool         `yaml:"check,omitempty"`    // default true
+	Interval time.Duration `yaml:"interval,omitempty"` // e.g. 72h; defaults to DefaultUpdateInterval
+}
+
+// Enabled reports whether skitz checks for updates at startup.
+func (c UpdatesConfig) Enabled() bool {
+	return c.Check == nil || *c.Check
+}
+
+// Every returns the least time between startup checks.
+func (c UpdatesConfig) Every() time.Duration {
+	if c.Interval <= 0 {
+		return DefaultUpdateInterval
+	}
+	return c.Interval
+}
+
+// BannerNone hides the logo or title of the dashboard header.
+const BannerNone = "none"
+
+// BannerConfig customizes the dashboard header. Logo and Title are ASCII
+// art, the name of a .txt file in the banners directory, or BannerNone.
+// One of Quotes is shown per start.
+type BannerConfig struct {
+	Logo    string   `yaml:"logo,omitempty"`
+	Title   string   `yaml:"title,omitempty"`
+	Quotes  []string `yaml:"quotes,omitempty"`
+	Animate *bool    `yaml:"animate,omitempty"` // type the quote out; default true
+}
+
+// Animated reports whether the quote is typed out at startup.
+func (c BannerConfig) Animated() bool {
+	return c.Animate == nil || *c.Animate
+}
+
+// Audit log sinks.
+const (
+	AuditSinkFile    = "file"
+	AuditSinkSyslog  = "syslog"
+	AuditSinkWebhook = "webhook"
+)
+
+// AuditConfig turns on the audit log. Sink is AuditSinkFile (the default),
+// AuditSinkSyslog or AuditSinkWebhook. Path replaces audit.jsonl in the
+// data directory for the file sink; URL receives each entry as a JSON POST
+// for the webhook sink.
+type AuditConfig struct {
+	Enabled bool   `yaml:"enabled,omitempty"`
+	Sink    string `yaml:"sink,omitempty"`
+	Path    string `yaml:"path,omitempty"`
+	URL     string `yaml:"url,omitempty"`
+}
+
+// BrandingConfig names the app in the status bar, palette and dashboard.
+// Accent is an ANSI 256 color number or a hex color such as "#ff6600";
+// Banner is the text on the bar under the dashboard logo.
+type BrandingConfig struct {
+	Name   string `yaml:"name,omitempty"`
+	Accent string `yaml:"accent,omitempty"`
+	Banner string `yaml:"banner,omitempty"`
+}
+
+// LockConfig locks skitz after IdleAfter without a keypress. Unlocking
+// takes a keypress and a confirmation; with Command set, the command also
+// has to exit 0, e.g. "sudo -k true" to ask for the user's password.
+type LockConfig struct {
+	IdleAfter time.Duration `yaml:"idle_after,omitempty"` // e.g. 15m; off when zero
+	Command   string        `yaml:"command,omitempty"`
+}
+
+// Enabled reports whether skitz locks when idle.
+func (c LockConfig) Enabled() bool {
+	return c.IdleAfter > 0
+}
+
+// BannersDir returns the directory banner art files are read from.
+func BannersDir() string {
+	return filepath.Join(ConfigDir, "banners")
+}
+
+// DefaultRegistryURL is the resource pack index used without registry.url.
+const DefaultRegistryURL = "https://raw.githubusercontent.com/htelsiz/skitz-packs/main/index.json"
+
+// RegistryConfig points at a resource pack index: JSON served over HTTPS.
+type RegistryConfig struct {
+	URL string `yaml:"url,omitempty"`
+}
+
+// IndexURL returns the index's URL, or DefaultRegistryURL.
+func (c RegistryConfig) IndexURL() string {
+	if c.URL == "" {
+		return DefaultRegistryURL
+	}
+	return c.URL
+}
+
+// IntegrationsConfig holds connections to external services.
+type IntegrationsConfig struct {
+	Webhooks []WebhookConfig `yaml:"webhooks,omitempty"`
+	GitHub   GitHubConfig    `yaml:"github,omitempty"`
+}
+
+// GitHubConfig configures the GitHub sections of the git resource. Without
+// a token, $GITHUB_TOKEN, $GH_TOKEN and gh's stored login are tried.
+type GitHubConfig struct {
+	Token  string `yaml:"token,omitempty"`
+	APIURL string `yaml:"api_url,omitempty"` // GitHub Enterprise, e.g. https://github.example.com/api/v3
+}
+
+// WebhookConfig is an incoming webhook that finished agent runs and
+// deployments are posted to.
+type WebhookConfig struct {
+	Name         string   `yaml:"name,omitempty"`
+	URL          string   `yaml:"url"`
+	Type         string   `yaml:"type,omitempty"`          // slack (default), teams or json
+	Events       []string `yaml:"events,omitempty"`        // agent, deploy; empty posts both
+	OnlyFailures bool     `yaml:"only_failures,omitempty"` // skip successful runs
+}
+
+// HooksConfig lists the hooks run around ^run commands.
+type HooksConfig struct {
+	PreRun  []Hook `yaml:"pre_run,omitempty"`
+	PostRun []Hook `yaml:"post_run,omitempty"`
+}
+
+// Hook is a shell snippet or executable run around a command. Match and
+// Resources limit it to some commands; a hook without either runs for
+// every command.
+type Hook struct {
+	Name      string        `yaml:"name,omitempty"`
+	Run       string        `yaml:"run"`
+	Match     string        `yaml:"match,omitempty"`      // regular expression matched against the command
+	Resources []string      `yaml:"resources,omitempty"`  // resource names
+	OnFailure bool          `yaml:"on_failure,omitempty"` // post-run only: skip commands that succeeded
+	Timeout   time.Duration `yaml:"timeout,omitempty"`    // defaults to hooks.DefaultTimeout
+}
+
+// DefaultRecordingKeep is how many session recordings are kept unless
+// recording.keep is set.
+const DefaultRecordingKeep = 50
+
+// RecordingConfig controls recording of embedded terminal sessions.
+type RecordingConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	Keep    int  `yaml:"keep,omitempty"` // newest recordings kept; defaults to DefaultRecordingKeep
+}
+
+// SecretSource is where a secret is read from. The first field set is
+// used: Command, then Keychain, then Env.
+type SecretSource struct {
+	Command  string `yaml:"command,omitempty"`  // prints the secret, e.g. op read op://vault/item/field
+	Keychain string `yaml:"keychain,omitempty"` // keychain service; the account is the secret's name
+	Env      string `yaml:"env,omitempty"`      // environment variable
+}
+
+// ReviewConfig lists code review profiles. Each is a palette action that
+// reviews code with its own prompt and AI provider.
+type ReviewConfig struct {
+	Profiles []ReviewProfile `yaml:"profiles,omitempty"`
+}
+
+// ReviewProfile is a kind of code review, such as a security or SQL review.
+type ReviewProfile struct {
+	Name     string   `yaml:"name"`
+	Prompt   string   `yaml:"prompt,omitempty"`   // system prompt; defaults to a general review
+	Provider string   `yaml:"provider,omitempty"` // defaults to ai.default_provider
+	Include  []string `yaml:"include,omitempty"`  // file name globs to review; defaults to all
+	Exclude  []string `yaml:"exclude,omitempty"`  // file name globs to skip
+}
+
+// Limit returns how many recordings are kept.
+func (c RecordingConfig) Limit() int {
+	if c.Keep <= 0 {
+		return DefaultRecordingKeep
+	}
+	return c.Keep
+}
+
+// ApprovalConfig is the policy for commands an AI generated. Deny wins,
+// then the always-ask categories, then Allow; other commands get Default.
+type ApprovalConfig struct {
+	Allow     []string `yaml:"allow,omitempty"`      // regular expressions run without asking
+	Deny      []string `yaml:"deny,omitempty"`       // regular expressions never run
+	AlwaysAsk []string `yaml:"always_ask,omitempty"` // categories, e.g. destructive; defaults to all of them
+	Default   string   `yaml:"default,omitempty"`    // "ask" (default) or "allow"
+}
+
+// ExecutionConfig controls how ^run commands are handed to the shell.
+type ExecutionConfig struct {
+	Shell      string            `yaml:"shell,omitempty"`       // defaults to $SHELL, then /bin/sh
+	LoginShell bool              `yaml:"login_shell,omitempty"` // start a login shell so rc-file PATH changes apply
+	Resources  map[string]string `yaml:"resources,omitempty"`   // per-resource shell override, keyed by resource name
+
+	// Targets sets the Docker context and container a resource's commands
+	// run against, keyed by resource name.
+	Targets map[string]ExecTarget `yaml:"targets,omitempty"`
+
+	// Pager is where long output is read: "internal" (the default) for
+	// skitz's own pager, or "external" for $PAGER, falling back to less -R.
+	Pager string `yaml:"pager,omitempty"`
+}
+
+// ExternalPager reports whether output opens in $PAGER.
+func (c ExecutionConfig) ExternalPager() bool {
+	return c.Pager == "external"
+}
+
+// ExecTarget is where a resource's commands run. Context is passed to
+// docker as DOCKER_CONTEXT; commands other than docker itself are run
+// inside Container with docker exec.
+type ExecTarget struct {
+	Context   string `yaml:"context,omitempty"`
+	Container string `yaml:"container,omitempty"`
+}
+
+// DeployConfig holds per-provider defaults for the Deploy Agent wizard.
+type DeployConfig struct {
+	Azure AzureDeployConfig `yaml:"azure,omitempty"`
+}
+
+// AzureDeployConfig is the tenant and subscription last picked for Azure
+// deployments. Both are IDs as shown by az account list.
+type AzureDeployConfig struct {
+	Tenant       string `yaml:"tenant,omitempty"`
+	Subscription string `yaml:"subscription,omitempty"`
+}
+
+// E2BConfig configures the E2B runtime of the Run Agent wizard. Template
+// and Workspace are the choices last made in the wizard; MaxDuration and
+// MaxCost bound every run, the shorter limit winning.
+type E2BConfig struct {
+	APIKey      string        `yaml:"api_key,omitempty"`       // defaults to $E2B_API_KEY
+	APIURL      string        `yaml:"api_url,omitempty"`       // defaults to https://api.e2b.dev
+	Domain      string        `yaml:"domain,omitempty"`        // sandbox domain, defaults to e2b.app
+	Template    string        `yaml:"template,omitempty"`      // sandbox template, defaults to base
+	Workspace   string        `yaml:"workspace,omitempty"`     // directory uploaded into the sandbox
+	MaxDuration time.Duration `yaml:"max_duration,omitempty"`  // e.g. 15m; defaults to 10m
+	MaxCost     float64       `yaml:"max_cost,omitempty"`      // USD per run; 0 = no cost limit
+	CostPerHour float64       `yaml:"cost_per_hour,omitempty"` // sandbox rate in USD, defaults to 0.10
+}
+
+// SectionLayout controls which detail sections of a resource are shown and
+// in what order. Section titles are matched case-insensitively.
+type SectionLayout struct {
+	Order   []string `yaml:"order,omitempty"`   // listed sections first, the rest keep file order
+	Hidden  []string `yaml:"hidden,omitempty"`  // sections to leave out of the detail view
+	Default string   `yaml:"default,omitempty"` // section to open the resource on
 }
 
 type QuickActionsConfig struct {
@@ -51,39 +456,170 @@ type CustomActionConfig struct {
 	Icon     string       `yaml:"icon"`
 	Shortcut string       `yaml:"shortcut"`
 	Action   CustomAction `yaml:"action"`
+
+	// Description is shown under the name in the command palette
+	Description string `yaml:"description,omitempty"`
+	// Params are asked for before the action runs and fill {{name}}
+	// placeholders in a shell command
+	Params []ActionParam `yaml:"params,omitempty"`
 }
 
+// CustomAction is what a custom action runs: a shell command, or with type
+// "skitz" the ID of a built-in action such as refresh or open_log.
 type CustomAction struct {
 	Type    string `yaml:"type"`
 	Command string `yaml:"command"`
 }
 
+// ActionParam is a value asked for before a custom action runs. Options
+// turn the input into a select.
+type ActionParam struct {
+	Name        string   `yaml:"name"`
+	Title       string   `yaml:"title,omitempty"`
+	Placeholder string   `yaml:"placeholder,omitempty"`
+	Default     string   `yaml:"default,omitempty"`
+	Options     []string `yaml:"options,omitempty"`
+	Required    bool     `yaml:"required,omitempty"`
+}
+
 type HistoryConfig struct {
 	Enabled      bool `yaml:"enabled"`
 	MaxItems     int  `yaml:"max_items"`
 	DisplayCount int  `yaml:"display_count"`
 	Persist      bool `yaml:"persist"`
+	OutputLimit  int  `yaml:"output_limit,omitempty"` // bytes of output kept per run, first and last half; 0 keeps none
 }
 
 type AIConfig struct {
 	OpenAIAPIKey    string           `yaml:"openai_api_key,omitempty"` // deprecated, use Providers
 	DefaultProvider string           `yaml:"default_provider,omitempty"`
 	Providers       []ProviderConfig `yaml:"providers,omitempty"`
+	ContextLimit    int              `yaml:"context_limit,omitempty"` // bytes per Ask AI attachment
+	RAG             RAGConfig        `yaml:"rag,omitempty"`
+
+	// Routing lists the providers to try in order per AI feature, e.g.
+	// ask: [anthropic, openai]. FailoverOn is the error classes that move
+	// to the next one: rate_limit, server_error, timeout, network, auth,
+	// bad_request.
+	Routing    map[string][]string `yaml:"routing,omitempty"`
+	FailoverOn []string            `yaml:"failover_on,omitempty"`
+}
+
+// RAGConfig configures the local index Ask AI retrieves context from.
+type RAGConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Provider string `yaml:"provider,omitempty"` // defaults to default_provider
+	Model    string `yaml:"model,omitempty"`    // embedding model
+	TopK     int    `yaml:"top_k,omitempty"`    // chunks per question, default 5
+}
+
+// OpenAIKey returns the API key of the first enabled OpenAI provider,
+// falling back to the deprecated openai_api_key field.
+func (a AIConfig) OpenAIKey() string {
+	for _, p := range a.Providers {
+		if p.Enabled && p.ProviderType == "openai" && p.APIKey != "" {
+			return p.APIKey
+		}
+	}
+	return a.OpenAIAPIKey
 }
 
 type ProviderConfig struct {
 	Name         string `yaml:"name"`
-	ProviderType string `yaml:"provider_type,omitempty"` // "openai", "anthropic", "ollama", "openai-compatible"
+	ProviderType string `yaml:"provider_type,omitempty"` // "openai", "anthropic", "ollama", "openai-compatible", "gemini", "vertex", "bedrock"
 	APIKey       string `yaml:"api_key,omitempty"`
 	BaseURL      string `yaml:"base_url,omitempty"` // for custom endpoints
 	DefaultModel string `yaml:"default_model,omitempty"`
 	Enabled      bool   `yaml:"enabled"`
+
+	// Cloud provider settings: the Bedrock region or Vertex AI location, the
+	// Vertex AI project, and AWS keys for Bedrock. Empty values fall back to
+	// the AWS and Google Cloud environment and CLIs.
+	Region          string `yaml:"region,omitempty"`
+	Project         string `yaml:"project,omitempty"`
+	AccessKeyID     string `yaml:"access_key_id,omitempty"`
+	SecretAccessKey string `yaml:"secret_access_key,omitempty"`
+
+	// Request settings for every feature, and overrides per feature
+	AISettings `yaml:",inline"`
+	Features   map[string]AISettings `yaml:"features,omitempty"` // keyed by AI feature, e.g. ask
+}
+
+// AI features whose requests can be tuned per provider.
+const (
+	FeatureAsk             = "ask"
+	FeatureGenerateCommand = "generate_command"
+	FeatureAgent           = "agent"
+	FeatureReview          = "review"
+)
+
+// AISettings tunes requests to a provider. Zero values keep the built-in
+// defaults.
+type AISettings struct {
+	SystemPrompt string        `yaml:"system_prompt,omitempty"` // replaces the built-in prompt
+	Temperature  *float64      `yaml:"temperature,omitempty"`
+	MaxTokens    int           `yaml:"max_tokens,omitempty"`
+	Timeout      time.Duration `yaml:"timeout,omitempty"`
+}
+
+// SettingsFor returns the provider's settings for feature, with the
+// feature's own settings taking precedence.
+func (p ProviderConfig) SettingsFor(feature string) AISettings {
+	s := p.AISettings
+	f, ok := p.Features[feature]
+	if !ok {
+		return s
+	}
+	if f.SystemPrompt != "" {
+		s.SystemPrompt = f.SystemPrompt
+	}
+	if f.Temperature != nil {
+		s.Temperature = f.Temperature
+	}
+	if f.MaxTokens > 0 {
+		s.MaxTokens = f.MaxTokens
+	}
+	if f.Timeout > 0 {
+		s.Timeout = f.Timeout
+	}
+	return s
 }
 
 type MCPConfig struct {
 	Enabled        bool              `yaml:"enabled"`
 	RefreshSeconds int               `yaml:"refresh_seconds"`
 	Servers        []MCPServerConfig `yaml:"servers"`
+	Retry          MCPRetryConfig    `yaml:"retry,omitempty"`
+
+	// Favorites are tools pinned to the top of the palette and listed on
+	// the dashboard, as server.tool
+	Favorites []string    `yaml:"favorites,omitempty"`
+	Aliases   []MCPAlias  `yaml:"aliases,omitempty"`
+	Presets   []MCPPreset `yaml:"presets,omitempty"`
+}
+
+// MCPAlias is a short palette name for an MCP tool. Args pre-fill the
+// tool's parameter form.
+type MCPAlias struct {
+	Name string            `yaml:"name"`
+	Tool string            `yaml:"tool"` // server.tool, or a tool name on any server
+	Args map[string]string `yaml:"args,omitempty"`
+}
+
+// MCPPreset is a saved call of an MCP tool: a palette item that runs Tool
+// with exactly Args.
+type MCPPreset struct {
+	Name string            `yaml:"name"`
+	Tool string            `yaml:"tool"` // server.tool, or a tool name on any server
+	Args map[string]string `yaml:"args,omitempty"`
+}
+
+// MCPRetryConfig controls retries of MCP connections and tool calls after
+// transient network errors. Zero values use the built-in defaults.
+type MCPRetryConfig struct {
+	Attempts  int           `yaml:"attempts,omitempty"`   // total attempts, including the first
+	BaseDelay time.Duration `yaml:"base_delay,omitempty"` // e.g. 200ms, doubled for each retry
+	MaxDelay  time.Duration `yaml:"max_delay,omitempty"`
 }
 
 // SavedAgentConfig represents a saved/configured agent
@@ -101,14 +637,43 @@ type SavedAgentConfig struct {
 type MCPServerConfig struct {
 	Name string `yaml:"name"`
 	URL  string `yaml:"url"`
+
+	// RefreshSeconds overrides mcp.refresh_seconds for this server.
+	RefreshSeconds int `yaml:"refresh_seconds,omitempty"`
+	// Disabled servers stay in the config but are not polled or listed.
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// ActiveServers returns the servers that are not disabled.
+func (c MCPConfig) ActiveServers() []MCPServerConfig {
+	var servers []MCPServerConfig
+	for _, s := range c.Servers {
+		if !s.Disabled {
+			servers = append(servers, s)
+		}
+	}
+	return servers
+}
+
+// RefreshInterval returns how often the status of s is refreshed: its own
+// refresh_seconds, or mcp.refresh_seconds. Zero means never.
+func (c MCPConfig) RefreshInterval(s MCPServerConfig) time.Duration {
+	seconds := s.RefreshSeconds
+	if seconds <= 0 {
+		seconds = c.RefreshSeconds
+	}
+	return time.Duration(max(seconds, 0)) * time.Second
 }
 
 // HistoryEntry for tracking executed commands
 type HistoryEntry struct {
-	Command   string    `json:"command"`
-	Tool      string    `json:"tool"`
-	Timestamp time.Time `json:"timestamp"`
-	Success   bool      `json:"success"`
+	Command    string    `json:"command"`
+	Tool       string    `json:"tool"`
+	Timestamp  time.Time `json:"timestamp"`
+	Success    bool      `json:"success"`
+	ExitCode   int       `json:"exit_code,omitempty"`
+	DurationMS int64     `json:"duration_ms,omitempty"` // wall-clock run time
+	Output     string    `json:"output,omitempty"`      // with history.output_limit
 }
 
 // AgentInteraction tracks interactions with AI agents
@@ -123,11 +688,32 @@ type AgentInteraction struct {
 	Runtime   string    `json:"runtime"`      // "docker", "e2b"
 	Provider  string    `json:"provider"`     // provider name
 	Duration  int64     `json:"duration_ms"`  // execution time in milliseconds
+	Status    string    `json:"status,omitempty"` // one of the AgentStatus values; empty in older entries
 }
 
+// Agent run statuses recorded in AgentInteraction.Status.
+const (
+	AgentStatusCompleted = "completed"
+	AgentStatusFailed    = "failed"
+	AgentStatusCancelled = "cancelled"
+	AgentStatusTimedOut  = "timed_out"
+)
+
 // Load loads the configuration from disk. defaultMCPURL is used when
 // creating the default MCP server entry.
 func Load(defaultMCPURL string) Config {
+	cfg := loadUserConfig(defaultMCPURL)
+	if policy, err := LoadPolicy(); err == nil {
+		cfg.Policy = policy
+	} else {
+		// A policy that can't be enforced denies everything
+		slog.Error("organization policy can't be loaded, denying all", "path", PolicyPath, "error", err)
+		cfg.Policy = DenyAllPolicy(err)
+	}
+	return cfg
+}
+
+func loadUserConfig(defaultMCPURL string) Config {
 	configPath := filepath.Join(ConfigDir, "config.yaml")
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -141,18 +727,19 @@ func Load(defaultMCPURL string) Config {
 		return CreateDefault(defaultMCPURL)
 	}
 
+	migrated, result, err := migrateFile(configPath, data, defaultMCPURL, false)
+	if err != nil {
+		slog.Warn("config migration failed", "path", configPath, "error", err)
+	}
+	if len(result.Applied) > 0 {
+		data = migrated
+	}
+
 	var cfg Config
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return CreateDefault(defaultMCPURL)
 	}
 
-	if cfg.Version < 2 {
-		cfg.Version = 2
-		if len(cfg.MCP.Servers) == 0 {
-			cfg.MCP = defaultMCPConfig(defaultMCPURL)
-		}
-	}
-
 	if cfg.MCP.Enabled && len(cfg.MCP.Servers) == 0 {
 		cfg.MCP.Servers = defaultMCPConfig(defaultMCPURL).Servers
 	}
@@ -160,24 +747,28 @@ func Load(defaultMCPURL string) Config {
 	return cfg
 }
 
-// Save saves the configuration to disk.
+// Save saves the configuration to disk. Favorites added or removed in the
+// file since cfg was loaded, by another skitz or a file sync tool, are
+// merged in rather than lost.
 func Save(cfg Config) error {
 	if err := os.MkdirAll(ConfigDir, 0755); err != nil {
 		return err
 	}
 
+	path := filepath.Join(ConfigDir, "config.yaml")
+	mergeSavedFavorites(&cfg, path, time.Now())
 	data, err := yaml.Marshal(cfg)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(ConfigDir, "config.yaml"), data, 0644)
+	return os.WriteFile(path, data, 0644)
 }
 
 // CreateDefault creates the default configuration.
 func CreateDefault(defaultMCPURL string) Config {
 	return Config{
-		Version: 2,
+		Version: CurrentVersion,
 		QuickActions: QuickActionsConfig{
 			Enabled: true,
 			Builtin: []BuiltinActionConfig{
@@ -234,18 +825,21 @@ func LoadHistory() []HistoryEntry {
 	return history
 }
 
-// SaveHistory saves command history to disk.
-func SaveHistory(history []HistoryEntry) error {
+// SaveHistory saves command history to disk, merged with the entries
+// already saved there and capped at maxItems (0 for no cap).
+func SaveHistory(history []HistoryEntry, maxItems int) error {
 	if err := os.MkdirAll(DataDir, 0755); err != nil {
 		return err
 	}
 
+	path := filepath.Join(DataDir, "history.json")
+	history = mergeSavedHistory(history, path, maxItems)
 	data, err := json.MarshalIndent(history, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(filepath.Join(DataDir, "history.json"), data, 0644)
+	return os.WriteFile(path, data, 0644)
 }
 
 // AddToHistory adds an entry to history and maintains max size.
@@ -290,17 +884,6 @@ func SaveAgentHistory(history []AgentInteraction) error {
 	return os.WriteFile(filepath.Join(DataDir, "agent_history.json"), data, 0644)
 }
 
-// AddAgentInteraction adds an interaction to history and maintains max size.
-func AddAgentInteraction(history []AgentInteraction, entry AgentInteraction, maxItems int) []AgentInteraction {
-	history = append([]AgentInteraction{entry}, history...)
-
-	if len(history) > maxItems {
-		history = history[:maxItems]
-	}
-
-	return history
-}
-
 // BuiltinAgents returns the list of built-in agents bundled with skitz
 func BuiltinAgents() []SavedAgentConfig {
 	return []SavedAgentConfig{
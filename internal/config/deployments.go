@@ -0,0 +1,76 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Deployment records an agent started by the Deploy Agent wizard so its
+// status can be tracked after the wizard closes.
+type Deployment struct {
+	Name          string            `json:"name"`
+	Provider      string            `json:"provider"` // "azure", "aws", "gcp"
+	Method        string            `json:"method"`   // provider deploy method, e.g. "aci"
+	Subscription  string            `json:"subscription,omitempty"`
+	Account       string            `json:"account"`
+	ResourceGroup string            `json:"resource_group,omitempty"`
+	Location      string            `json:"location,omitempty"`
+	Endpoint      string            `json:"endpoint,omitempty"`
+	Model         string            `json:"model"`
+	Ref           string            `json:"ref,omitempty"`    // provider run ID: task ARN, pipeline run ID
+	Values        map[string]string `json:"values,omitempty"` // method field values
+	Timestamp     time.Time         `json:"timestamp"`
+}
+
+// LoadDeployments loads recorded deployments from disk, newest first.
+func LoadDeployments() []Deployment {
+	data, err := os.ReadFile(filepath.Join(DataDir, "deployments.json"))
+	if err != nil {
+		return []Deployment{}
+	}
+
+	var deployments []Deployment
+	if err := json.Unmarshal(data, &deployments); err != nil {
+		return []Deployment{}
+	}
+
+	return deployments
+}
+
+// SaveDeployments saves recorded deployments to disk.
+func SaveDeployments(deployments []Deployment) error {
+	if err := os.MkdirAll(DataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(deployments, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(DataDir, "deployments.json"), data, 0644)
+}
+
+// UpsertDeployment replaces the deployment with the same provider and name,
+// or prepends it when it is new.
+func UpsertDeployment(deployments []Deployment, d Deployment) []Deployment {
+	for i, existing := range deployments {
+		if existing.Provider == d.Provider && existing.Name == d.Name {
+			deployments[i] = d
+			return deployments
+		}
+	}
+	return append([]Deployment{d}, deployments...)
+}
+
+// RemoveDeployment removes the deployment with the given provider and name.
+func RemoveDeployment(deployments []Deployment, provider, name string) []Deployment {
+	for i, existing := range deployments {
+		if existing.Provider == provider && existing.Name == name {
+			return append(deployments[:i:i], deployments[i+1:]...)
+		}
+	}
+	return deployments
+}
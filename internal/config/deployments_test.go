@@ -0,0 +1,63 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpsertDeployment(t *testing.T) {
+	a := Deployment{Name: "a", Provider: "azure"}
+	b := Deployment{Name: "b", Provider: "aws"}
+
+	tests := []struct {
+		name string
+		list []Deployment
+		d    Deployment
+		want []Deployment
+	}{
+		{
+			name: "new deployment is prepended",
+			list: []Deployment{a},
+			d:    b,
+			want: []Deployment{b, a},
+		},
+		{
+			name: "existing deployment is replaced in place",
+			list: []Deployment{b, a},
+			d:    Deployment{Name: "a", Provider: "azure", Ref: "2"},
+			want: []Deployment{b, {Name: "a", Provider: "azure", Ref: "2"}},
+		},
+		{
+			name: "same name on another provider is separate",
+			list: []Deployment{a},
+			d:    Deployment{Name: "a", Provider: "gcp"},
+			want: []Deployment{{Name: "a", Provider: "gcp"}, a},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UpsertDeployment(tt.list, tt.d)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UpsertDeployment() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveDeployment(t *testing.T) {
+	a := Deployment{Name: "a", Provider: "azure"}
+	b := Deployment{Name: "b", Provider: "aws"}
+	list := []Deployment{a, b}
+
+	got := RemoveDeployment(list, "azure", "a")
+	if !reflect.DeepEqual(got, []Deployment{b}) {
+		t.Errorf("RemoveDeployment() = %v, want [b]", got)
+	}
+	if !reflect.DeepEqual(list, []Deployment{a, b}) {
+		t.Errorf("RemoveDeployment() modified its input: %v", list)
+	}
+	if got := RemoveDeployment(list, "gcp", "a"); len(got) != 2 {
+		t.Errorf("RemoveDeployment() of unknown deployment = %v", got)
+	}
+}
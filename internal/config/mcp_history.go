@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaxMCPCalls is how many calls the MCP call history keeps.
+const MaxMCPCalls = 200
+
+// MCPCall is an MCP tool call in the call history.
+type MCPCall struct {
+	Server     string            `json:"server"` // URL of the server
+	Tool       string            `json:"tool"`
+	Args       map[string]string `json:"args,omitempty"` // as typed in the parameter form; excluded arguments are left out
+	Time       time.Time         `json:"time"`
+	Duration   time.Duration     `json:"duration"`
+	ResultSize int               `json:"result_size"` // bytes of output
+	Failed     bool              `json:"failed,omitempty"`
+}
+
+// LoadMCPHistory loads the MCP call history from disk, most recent first.
+func LoadMCPHistory() []MCPCall {
+	data, err := os.ReadFile(filepath.Join(DataDir, "mcp_history.json"))
+	if err != nil {
+		return nil
+	}
+
+	var calls []MCPCall
+	if err := json.Unmarshal(data, &calls); err != nil {
+		return nil
+	}
+	return calls
+}
+
+// SaveMCPHistory saves the MCP call history to disk.
+func SaveMCPHistory(calls []MCPCall) error {
+	if err := os.MkdirAll(DataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(calls, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(DataDir, "mcp_history.json"), data, 0600)
+}
+
+// AddMCPCall returns calls with call in front, keeping at most
+// MaxMCPCalls.
+func AddMCPCall(calls []MCPCall, call MCPCall) []MCPCall {
+	calls = append([]MCPCall{call}, calls...)
+	return calls[:min(len(calls), MaxMCPCalls)]
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMCPHistory(t *testing.T) {
+	orig := DataDir
+	DataDir = t.TempDir()
+	defer func() { DataDir = orig }()
+
+	if calls := LoadMCPHistory(); calls != nil {
+		t.Fatalf("LoadMCPHistory() without a file = %v", calls)
+	}
+
+	var calls []MCPCall
+	for i := range MaxMCPCalls + 5 {
+		calls = AddMCPCall(calls, MCPCall{Server: "http://localhost:8001/mcp", Tool: "search_logs", Duration: time.Duration(i) * time.Millisecond})
+	}
+	if len(calls) != MaxMCPCalls || calls[0].Duration != (MaxMCPCalls+4)*time.Millisecond {
+		t.Fatalf("kept %d calls, newest %v", len(calls), calls[0].Duration)
+	}
+
+	calls[0].Args = map[string]string{"query": "status:error"}
+	if err := SaveMCPHistory(calls); err != nil {
+		t.Fatal(err)
+	}
+	loaded := LoadMCPHistory()
+	if len(loaded) != MaxMCPCalls || loaded[0].Args["query"] != "status:error" || loaded[0].Duration != calls[0].Duration {
+		t.Errorf("reloaded %d calls, newest %+v", len(loaded), loaded[0])
+	}
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FavoriteMark is the last add/remove of a favorite. Removals are kept as
+// tombstones so a merge can tell "never favorited" from "unfavorited".
+type FavoriteMark struct {
+	UpdatedAt time.Time `yaml:"updated_at"`
+	Removed   bool      `yaml:"removed,omitempty"`
+}
+
+// SetFavorite adds or removes cmd from the favorites and records the change.
+func SetFavorite(cfg *Config, cmd string, favorite bool, now time.Time) {
+	favs := make([]string, 0, len(cfg.Favorites)+1)
+	for _, f := range cfg.Favorites {
+		if f != cmd {
+			favs = append(favs, f)
+		}
+	}
+	if favorite {
+		favs = append(favs, cmd)
+	}
+	cfg.Favorites = favs
+
+	if cfg.FavoriteMarks == nil {
+		cfg.FavoriteMarks = make(map[string]FavoriteMark)
+	}
+	cfg.FavoriteMarks[cmd] = FavoriteMark{UpdatedAt: now, Removed: !favorite}
+}
+
+// MergeFavorites combines favorites from two machines without conflicts.
+// Each command resolves independently: the most recent add or remove wins,
+// and an add wins a tie. Favorites without a mark (written before marks
+// existed) count as added at the zero time. Local ordering is kept, with
+// remote-only favorites appended.
+func MergeFavorites(local, remote Config) ([]string, map[string]FavoriteMark) {
+	markOf := func(cfg Config, present map[string]bool, cmd string) (FavoriteMark, bool) {
+		if mk, ok := cfg.FavoriteMarks[cmd]; ok {
+			return mk, true
+		}
+		if present[cmd] {
+			return FavoriteMark{}, true
+		}
+		return FavoriteMark{}, false
+	}
+
+	localSet := toSet(local.Favorites)
+	remoteSet := toSet(remote.Favorites)
+
+	var order []string
+	seen := make(map[string]bool)
+	add := func(cmds ...string) {
+		for _, c := range cmds {
+			if !seen[c] {
+				seen[c] = true
+				order = append(order, c)
+			}
+		}
+	}
+	add(local.Favorites...)
+	add(remote.Favorites...)
+	add(sortedKeys(local.FavoriteMarks)...)
+	add(sortedKeys(remote.FavoriteMarks)...)
+
+	favorites := []string{}
+	marks := make(map[string]FavoriteMark)
+	for _, cmd := range order {
+		lm, lok := markOf(local, localSet, cmd)
+		rm, rok := markOf(remote, remoteSet, cmd)
+
+		winner := lm
+		switch {
+		case !lok:
+			winner = rm
+		case !rok:
+			winner = lm
+		case rm.UpdatedAt.After(lm.UpdatedAt):
+			winner = rm
+		case rm.UpdatedAt.Equal(lm.UpdatedAt) && lm.Removed && !rm.Removed:
+			winner = rm
+		}
+
+		if !winner.UpdatedAt.IsZero() || winner.Removed {
+			marks[cmd] = winner
+		}
+		if !winner.Removed {
+			favorites = append(favorites, cmd)
+		}
+	}
+	return favorites, marks
+}
+
+// FavoriteMarkTTL is how long the mark of a removed favorite is kept: long
+// enough for every machine sharing the config to have seen the removal.
+const FavoriteMarkTTL = 90 * 24 * time.Hour
+
+// mergeSavedFavorites merges cfg's favorites with those in the config file
+// at path, which another skitz or a file sync tool may have changed since
+// cfg was loaded, and drops the marks of favorites removed more than
+// FavoriteMarkTTL before now.
+func mergeSavedFavorites(cfg *Config, path string, now time.Time) {
+	if data, err := os.ReadFile(path); err == nil {
+		var saved Config
+		if err := yaml.Unmarshal(data, &saved); err == nil {
+			cfg.Favorites, cfg.FavoriteMarks = MergeFavorites(*cfg, saved)
+		}
+	}
+	for cmd, mk := range cfg.FavoriteMarks {
+		if mk.Removed && now.Sub(mk.UpdatedAt) > FavoriteMarkTTL {
+			delete(cfg.FavoriteMarks, cmd)
+		}
+	}
+}
+
+// MergeHistory unions two histories, dropping entries present in both.
+// Entries are identified by command, tool and timestamp; when the same
+// entry appears on both sides with different outcomes, the local copy
+// wins. The result is sorted newest first and capped at maxItems.
+func MergeHistory(local, remote []HistoryEntry, maxItems int) []HistoryEntry {
+	type key struct {
+		command string
+		tool    string
+		at      int64
+	}
+
+	merged := make(map[key]HistoryEntry, len(local)+len(remote))
+	for _, list := range [][]HistoryEntry{remote, local} {
+		for _, e := range list {
+			merged[key{e.Command, e.Tool, e.Timestamp.UnixNano()}] = e
+		}
+	}
+
+	out := make([]HistoryEntry, 0, len(merged))
+	for _, e := range merged {
+		out = append(out, e)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Timestamp.Equal(out[j].Timestamp) {
+			return out[i].Command < out[j].Command
+		}
+		return out[i].Timestamp.After(out[j].Timestamp)
+	})
+
+	if maxItems > 0 && len(out) > maxItems {
+		out = out[:maxItems]
+	}
+	return out
+}
+
+// mergeSavedHistory merges history with the history file at path, which
+// another skitz or a file sync tool may have changed since it was loaded.
+func mergeSavedHistory(history []HistoryEntry, path string, maxItems int) []HistoryEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return history
+	}
+	var saved []HistoryEntry
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return history
+	}
+	return MergeHistory(history, saved, maxItems)
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, it := range items {
+		set[it] = true
+	}
+	return set
+}
+
+func sortedKeys(m map[string]FavoriteMark) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
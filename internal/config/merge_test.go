@@ -0,0 +1,188 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMergeFavorites(t *testing.T) {
+	t1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		local  Config
+		remote Config
+		want   []string
+	}{
+		{
+			name:   "union of unmarked favorites",
+			local:  Config{Favorites: []string{"a", "b"}},
+			remote: Config{Favorites: []string{"b", "c"}},
+			want:   []string{"a", "b", "c"},
+		},
+		{
+			name:  "newer remote removal wins",
+			local: Config{Favorites: []string{"a"}, FavoriteMarks: map[string]FavoriteMark{"a": {UpdatedAt: t1}}},
+			remote: Config{FavoriteMarks: map[string]FavoriteMark{
+				"a": {UpdatedAt: t2, Removed: true},
+			}},
+			want: []string{},
+		},
+		{
+			name: "newer local add beats older remote removal",
+			local: Config{Favorites: []string{"a"}, FavoriteMarks: map[string]FavoriteMark{
+				"a": {UpdatedAt: t2},
+			}},
+			remote: Config{FavoriteMarks: map[string]FavoriteMark{"a": {UpdatedAt: t1, Removed: true}}},
+			want:   []string{"a"},
+		},
+		{
+			name:   "add wins a tie",
+			local:  Config{FavoriteMarks: map[string]FavoriteMark{"a": {UpdatedAt: t1, Removed: true}}},
+			remote: Config{Favorites: []string{"a"}, FavoriteMarks: map[string]FavoriteMark{"a": {UpdatedAt: t1}}},
+			want:   []string{"a"},
+		},
+		{
+			name:   "removal beats unmarked favorite",
+			local:  Config{Favorites: []string{"a"}},
+			remote: Config{FavoriteMarks: map[string]FavoriteMark{"a": {UpdatedAt: t1, Removed: true}}},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, marks := MergeFavorites(tt.local, tt.remote)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeFavorites() = %v, want %v", got, tt.want)
+			}
+			// Merging must be order-independent, apart from the order of
+			// the favorites
+			rev, revMarks := MergeFavorites(tt.remote, tt.local)
+			slices.Sort(got)
+			slices.Sort(rev)
+			if !reflect.DeepEqual(rev, got) || !reflect.DeepEqual(revMarks, marks) {
+				t.Errorf("MergeFavorites(reversed) = %v, %v, want %v, %v", rev, revMarks, got, marks)
+			}
+		})
+	}
+}
+
+func TestSaveMergesFavorites(t *testing.T) {
+	oldConfigDir := ConfigDir
+	ConfigDir = t.TempDir()
+	defer func() { ConfigDir = oldConfigDir }()
+
+	now := time.Now()
+	var local Config
+	SetFavorite(&local, "make", true, now.Add(-time.Hour))
+	SetFavorite(&local, "ls", true, now.Add(-time.Hour))
+	if err := Save(local); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another machine removes ls and adds git status, and the file syncs
+	path := filepath.Join(ConfigDir, "config.yaml")
+	var remote Config
+	data, _ := os.ReadFile(path)
+	yaml.Unmarshal(data, &remote)
+	SetFavorite(&remote, "ls", false, now.Add(-time.Minute))
+	SetFavorite(&remote, "git status", true, now.Add(-time.Minute))
+	// A removal synced long ago is forgotten
+	remote.FavoriteMarks["old"] = FavoriteMark{UpdatedAt: now.Add(-2 * FavoriteMarkTTL), Removed: true}
+	data, _ = yaml.Marshal(remote)
+	os.WriteFile(path, data, 0644)
+
+	// Saving the stale local config keeps both changes
+	SetFavorite(&local, "docker ps", true, now)
+	if err := Save(local); err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	data, _ = os.ReadFile(path)
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"make", "docker ps", "git status"}; !reflect.DeepEqual(saved.Favorites, want) {
+		t.Errorf("saved favorites = %v, want %v", saved.Favorites, want)
+	}
+	if _, ok := saved.FavoriteMarks["old"]; ok || !saved.FavoriteMarks["ls"].Removed {
+		t.Errorf("saved marks = %+v", saved.FavoriteMarks)
+	}
+}
+
+func TestMergeHistory(t *testing.T) {
+	t1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	t3 := t2.Add(time.Minute)
+	local := []HistoryEntry{
+		{Command: "git status", Tool: "git", Timestamp: t2, Success: true},
+		{Command: "docker ps", Tool: "docker", Timestamp: t1, Success: true},
+	}
+	remote := []HistoryEntry{
+		{Command: "ls", Timestamp: t3, Success: true},
+		{Command: "git status", Tool: "git", Timestamp: t2, Success: false},
+	}
+
+	got := MergeHistory(local, remote, 10)
+	var commands []string
+	for _, e := range got {
+		commands = append(commands, e.Command)
+	}
+	if want := []string{"ls", "git status", "docker ps"}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("MergeHistory() = %v, want %v", commands, want)
+	}
+	if !got[1].Success {
+		t.Error("MergeHistory() kept the remote copy of a shared entry")
+	}
+
+	if capped := MergeHistory(local, remote, 2); len(capped) != 2 {
+		t.Errorf("MergeHistory(max 2) returned %d entries", len(capped))
+	}
+}
+
+func TestSaveHistoryMerges(t *testing.T) {
+	oldDataDir := DataDir
+	DataDir = t.TempDir()
+	defer func() { DataDir = oldDataDir }()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	shared := HistoryEntry{Command: "make", Timestamp: start, Success: true}
+	if err := SaveHistory([]HistoryEntry{shared}, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two machines each run a command from the same history, and the
+	// other machine's history syncs first
+	remote := AddToHistory(LoadHistory(), HistoryEntry{Command: "ls", Timestamp: start.Add(time.Minute)}, 10)
+	data, _ := json.Marshal(remote)
+	os.WriteFile(filepath.Join(DataDir, "history.json"), data, 0644)
+
+	local := AddToHistory([]HistoryEntry{shared}, HistoryEntry{Command: "docker ps", Timestamp: start.Add(2 * time.Minute)}, 10)
+	if err := SaveHistory(local, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	var commands []string
+	for _, e := range LoadHistory() {
+		commands = append(commands, e.Command)
+	}
+	if want := []string{"docker ps", "ls", "make"}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("saved history = %v, want %v", commands, want)
+	}
+
+	if err := SaveHistory(local, 2); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(LoadHistory()); n != 2 {
+		t.Errorf("saved %d entries, want 2", n)
+	}
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the config schema version written by this build.
+const CurrentVersion = 3
+
+// ErrConfigTooNew is returned when the config file was written by a newer
+// skitz with a schema this build does not know.
+var ErrConfigTooNew = errors.New("config was written by a newer version of skitz")
+
+// migration upgrades a config document from version to version+1. Migrations
+// work on the YAML node tree so they can read keys the Config struct no
+// longer has, and so comments and key order survive.
+type migration struct {
+	version     int
+	description string
+	apply       func(root *yaml.Node, defaultMCPURL string) error
+}
+
+var migrations = []migration{
+	{1, "add the default MCP server when none is configured", migrateAddDefaultMCP},
+	{2, `move ai.openai_api_key into an "openai" provider`, migrateOpenAIKey},
+}
+
+// MigrationResult describes the migrations applied (or, for a dry run, that
+// would be applied) to a config file.
+type MigrationResult struct {
+	Path    string   `json:"path"`
+	From    int      `json:"from"`
+	To      int      `json:"to"`
+	Applied []string `json:"applied"`
+	Backup  string   `json:"backup,omitempty"`
+
+	// Before and After hold the config before and after migrating, both
+	// encoded the same way so they can be diffed.
+	Before string `json:"-"`
+	After  string `json:"-"`
+}
+
+// MigrateData upgrades raw config YAML to CurrentVersion. When no migration
+// applies the result has no Applied entries and data is returned unchanged.
+func MigrateData(data []byte, defaultMCPURL string) ([]byte, MigrationResult, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return data, MigrationResult{}, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, MigrationResult{}, errors.New("failed to parse config: not a YAML mapping")
+	}
+	root := doc.Content[0]
+
+	version := 1
+	if v := mappingValue(root, "version"); v != nil {
+		if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+			version = n
+		}
+	}
+	result := MigrationResult{From: version, To: version}
+	if version > CurrentVersion {
+		return data, result, fmt.Errorf("%w: version %d, this build supports %d", ErrConfigTooNew, version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return data, result, nil
+	}
+
+	before, err := yaml.Marshal(&doc)
+	if err != nil {
+		return data, result, fmt.Errorf("failed to encode config: %w", err)
+	}
+	result.Before = string(before)
+
+	for _, m := range migrations {
+		if m.version < version {
+			continue
+		}
+		if err := m.apply(root, defaultMCPURL); err != nil {
+			return data, result, fmt.Errorf("failed to migrate config from version %d: %w", m.version, err)
+		}
+		setMappingValue(root, "version", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(m.version + 1)})
+		result.Applied = append(result.Applied, fmt.Sprintf("v%d → v%d: %s", m.version, m.version+1, m.description))
+		result.To = m.version + 1
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return data, result, fmt.Errorf("failed to encode config: %w", err)
+	}
+	result.After = string(out)
+	return out, result, nil
+}
+
+// Migrate upgrades the config file on disk to CurrentVersion. Unless dryRun
+// is set, the original file is kept as config.yaml.v<N>.bak before the
+// migrated config is written.
+func Migrate(defaultMCPURL string, dryRun bool) (MigrationResult, error) {
+	path := filepath.Join(ConfigDir, "config.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MigrationResult{Path: path}, fmt.Errorf("failed to read config: %w", err)
+	}
+	_, result, err := migrateFile(path, data, defaultMCPURL, dryRun)
+	return result, err
+}
+
+// migrateFile migrates data read from path and, unless dryRun, backs up and
+// rewrites the file. The migrated data is returned even if writing fails.
+func migrateFile(path string, data []byte, defaultMCPURL string, dryRun bool) ([]byte, MigrationResult, error) {
+	out, result, err := MigrateData(data, defaultMCPURL)
+	result.Path = path
+	if err != nil || dryRun || len(result.Applied) == 0 {
+		return out, result, err
+	}
+
+	result.Backup = fmt.Sprintf("%s.v%d.bak", path, result.From)
+	if err := os.WriteFile(result.Backup, data, 0644); err != nil {
+		result.Backup = ""
+		return out, result, fmt.Errorf("failed to back up config: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return out, result, fmt.Errorf("failed to write migrated config: %w", err)
+	}
+	slog.Info("migrated config", "path", path, "from", result.From, "to", result.To, "backup", result.Backup)
+	return out, result, nil
+}
+
+// migrateAddDefaultMCP mirrors the original v1 upgrade: configs without MCP
+// servers get the default local server.
+func migrateAddDefaultMCP(root *yaml.Node, defaultMCPURL string) error {
+	if mcp := mappingValue(root, "mcp"); mcp != nil {
+		if servers := mappingValue(mcp, "servers"); servers != nil && len(servers.Content) > 0 {
+			return nil
+		}
+	}
+
+	var node yaml.Node
+	if err := node.Encode(defaultMCPConfig(defaultMCPURL)); err != nil {
+		return err
+	}
+	setMappingValue(root, "mcp", &node)
+	return nil
+}
+
+// migrateOpenAIKey replaces the deprecated ai.openai_api_key with a provider
+// entry, making it the default provider when none is set.
+func migrateOpenAIKey(root *yaml.Node, _ string) error {
+	ai := mappingValue(root, "ai")
+	if ai == nil {
+		return nil
+	}
+	key := mappingValue(ai, "openai_api_key")
+	if key == nil {
+		return nil
+	}
+	deleteMappingKey(ai, "openai_api_key")
+	if key.Value == "" {
+		return nil
+	}
+
+	providers := mappingValue(ai, "providers")
+	if providers == nil {
+		providers = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		setMappingValue(ai, "providers", providers)
+	}
+	for _, p := range providers.Content {
+		if k := mappingValue(p, "api_key"); k != nil && k.Value == key.Value {
+			return nil
+		}
+	}
+
+	var provider yaml.Node
+	if err := provider.Encode(ProviderConfig{
+		Name:         "openai",
+		ProviderType: "openai",
+		APIKey:       key.Value,
+		Enabled:      true,
+	}); err != nil {
+		return err
+	}
+	providers.Content = append(providers.Content, &provider)
+
+	if d := mappingValue(ai, "default_provider"); d == nil || d.Value == "" {
+		setMappingValue(ai, "default_provider", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "openai"})
+	}
+	return nil
+}
+
+// mappingValue returns the value node for key in mapping node m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue replaces the value for key in mapping node m, appending
+// the key when it is missing.
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		value,
+	)
+}
+
+// deleteMappingKey removes key and its value from mapping node m.
+func deleteMappingKey(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigrateData(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		applied int
+		wantErr error
+		check   func(t *testing.T, cfg Config)
+	}{
+		{
+			name:    "v1 without servers gets default MCP server",
+			input:   "history:\n  enabled: true\n",
+			applied: 2,
+			check: func(t *testing.T, cfg Config) {
+				if len(cfg.MCP.Servers) != 1 || cfg.MCP.Servers[0].URL != "http://mcp.test/" {
+					t.Errorf("MCP.Servers = %v, want default server", cfg.MCP.Servers)
+				}
+			},
+		},
+		{
+			name:    "v2 openai key becomes provider",
+			input:   "version: 2\nai:\n  openai_api_key: sk-test\n",
+			applied: 1,
+			check: func(t *testing.T, cfg Config) {
+				if cfg.AI.OpenAIAPIKey != "" {
+					t.Error("openai_api_key should be removed")
+				}
+				if cfg.AI.DefaultProvider != "openai" || cfg.AI.OpenAIKey() != "sk-test" {
+					t.Errorf("AI = %+v, want openai provider with key", cfg.AI)
+				}
+			},
+		},
+		{
+			name:    "v2 key already in providers is not duplicated",
+			input:   "version: 2\nai:\n  openai_api_key: sk-test\n  default_provider: mine\n  providers:\n    - name: mine\n      provider_type: openai\n      api_key: sk-test\n      enabled: true\n",
+			applied: 1,
+			check: func(t *testing.T, cfg Config) {
+				if len(cfg.AI.Providers) != 1 || cfg.AI.DefaultProvider != "mine" {
+					t.Errorf("AI = %+v, want the existing provider only", cfg.AI)
+				}
+			},
+		},
+		{
+			name:  "current version is untouched",
+			input: "version: 3\n",
+		},
+		{
+			name:    "newer version is rejected",
+			input:   "version: 99\n",
+			wantErr: ErrConfigTooNew,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, result, err := MigrateData([]byte(tt.input), "http://mcp.test/")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("MigrateData() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(result.Applied) != tt.applied {
+				t.Errorf("applied %v, want %d migrations", result.Applied, tt.applied)
+			}
+			if tt.applied == 0 && string(out) != tt.input {
+				t.Errorf("data changed without migrations: %q", out)
+			}
+
+			var cfg Config
+			if err := yaml.Unmarshal(out, &cfg); err != nil {
+				t.Fatalf("migrated config does not parse: %v", err)
+			}
+			if tt.applied > 0 && cfg.Version != CurrentVersion {
+				t.Errorf("Version = %d, want %d", cfg.Version, CurrentVersion)
+			}
+			if tt.check != nil {
+				tt.check(t, cfg)
+			}
+		})
+	}
+}
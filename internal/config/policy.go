@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyPath is the read-only, system-wide policy file for managed
+// deployments. Values set here override the user's config.yaml.
+var PolicyPath = "/etc/skitz/policy.yaml"
+
+// Sentinel errors for actions blocked by policy.
+var (
+	ErrAIDisabled     = errors.New("AI features are disabled by organization policy")
+	ErrCommandBlocked = errors.New("command blocked by organization policy")
+)
+
+// Policy holds settings enforced by the organization.
+type Policy struct {
+	AIDisabled bool            `yaml:"ai_disabled,omitempty"`
+	MCP        PolicyMCP       `yaml:"mcp,omitempty"`
+	Commands   PolicyCommands  `yaml:"commands,omitempty"`
+	Telemetry  PolicyTelemetry `yaml:"telemetry,omitempty"`
+	Audit      *AuditConfig    `yaml:"audit,omitempty"` // replaces the user's audit settings
+
+	deny    []*regexp.Regexp
+	loadErr error // why the policy file couldn't be loaded; everything is denied
+}
+
+// PolicyMCP restricts MCP usage.
+type PolicyMCP struct {
+	Enabled        *bool    `yaml:"enabled,omitempty"`         // force MCP on or off
+	AllowedServers []string `yaml:"allowed_servers,omitempty"` // URL prefixes; empty allows all
+}
+
+// PolicyCommands holds command guardrails.
+type PolicyCommands struct {
+	Deny []string `yaml:"deny,omitempty"` // regular expressions matched against the full command
+}
+
+// PolicyTelemetry pins where usage telemetry is reported.
+type PolicyTelemetry struct {
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+// LoadPolicy reads the policy file. A missing file yields an empty policy.
+func LoadPolicy() (Policy, error) {
+	data, err := os.ReadFile(PolicyPath)
+	if os.IsNotExist(err) {
+		return Policy{}, nil
+	}
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to read policy: %w", err)
+	}
+	return ParsePolicy(data)
+}
+
+// DenyAllPolicy is the policy enforced when the policy file exists but
+// can't be read or parsed: AI and MCP are off and every command is blocked,
+// so a broken policy fails closed rather than open.
+func DenyAllPolicy(err error) Policy {
+	off := false
+	return Policy{AIDisabled: true, MCP: PolicyMCP{Enabled: &off}, loadErr: err}
+}
+
+// LoadErr returns why the policy file couldn't be loaded, if it couldn't.
+func (p Policy) LoadErr() error {
+	return p.loadErr
+}
+
+// ParsePolicy parses policy YAML and compiles its command guardrails.
+func ParsePolicy(data []byte) (Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return Policy{}, fmt.Errorf("failed to parse policy: %w", err)
+	}
+	for _, pattern := range p.Commands.Deny {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return Policy{}, fmt.Errorf("failed to compile deny pattern %q: %w", pattern, err)
+		}
+		p.deny = append(p.deny, re)
+	}
+	return p, nil
+}
+
+// Active reports whether the policy enforces anything.
+func (p Policy) Active() bool {
+	return p.loadErr != nil || p.AIDisabled || p.MCP.Enabled != nil || len(p.MCP.AllowedServers) > 0 ||
+		len(p.Commands.Deny) > 0 || p.Telemetry.Endpoint != "" || p.Audit != nil
+}
+
+// Locked reports whether a setting is enforced by policy. Known settings
+// are "ai", "mcp.enabled", "mcp.servers", "telemetry.endpoint" and
+// "audit".
+func (p Policy) Locked(setting string) bool {
+	switch setting {
+	case "ai":
+		return p.AIDisabled
+	case "mcp.enabled":
+		return p.MCP.Enabled != nil
+	case "mcp.servers":
+		return len(p.MCP.AllowedServers) > 0
+	case "telemetry.endpoint":
+		return p.Telemetry.Endpoint != ""
+	case "audit":
+		return p.Audit != nil
+	}
+	return false
+}
+
+// MCPServerAllowed reports whether rawURL may be contacted: it must have
+// the scheme and host of an allowed server, and a path under its path.
+func (p Policy) MCPServerAllowed(rawURL string) bool {
+	if p.loadErr != nil {
+		return false
+	}
+	if len(p.MCP.AllowedServers) == 0 {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, allowed := range p.MCP.AllowedServers {
+		a, err := url.Parse(allowed)
+		if err != nil || a.Host == "" {
+			continue
+		}
+		if !strings.EqualFold(u.Scheme, a.Scheme) || !strings.EqualFold(u.Host, a.Host) {
+			continue
+		}
+		if pathUnder(u.Path, a.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathUnder reports whether path is prefix or below it, matching whole
+// segments: /mcp is under /mcp but /mcpx isn't
+func pathUnder(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// CheckCommand returns ErrCommandBlocked if cmd matches a deny pattern, or
+// for any command when the policy file couldn't be loaded.
+func (p Policy) CheckCommand(cmd string) error {
+	if p.loadErr != nil {
+		return fmt.Errorf("%w: the policy at %s can't be loaded: %v", ErrCommandBlocked, PolicyPath, p.loadErr)
+	}
+	for _, re := range p.deny {
+		if re.MatchString(cmd) {
+			return fmt.Errorf("%w: matches %q", ErrCommandBlocked, re.String())
+		}
+	}
+	return nil
+}
+
+// EffectiveMCP returns the MCP settings with policy applied: MCP may be
+// forced on or off, and servers outside the allow list are dropped.
+func (c Config) EffectiveMCP() MCPConfig {
+	mcp := c.MCP
+	if c.Policy.MCP.Enabled != nil {
+		mcp.Enabled = *c.Policy.MCP.Enabled
+	}
+	if len(c.Policy.MCP.AllowedServers) > 0 {
+		mcp.Servers = nil
+		for _, s := range c.MCP.Servers {
+			if c.Policy.MCPServerAllowed(s.URL) {
+				mcp.Servers = append(mcp.Servers, s)
+			}
+		}
+	}
+	return mcp
+}
+
+// EffectiveAudit returns the audit settings in force: the policy's, when
+// it sets any, so that users can't turn an enforced audit log off.
+func (c Config) EffectiveAudit() AuditConfig {
+	if c.Policy.Audit != nil {
+		return *c.Policy.Audit
+	}
+	return c.Audit
+}
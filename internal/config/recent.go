@@ -0,0 +1,64 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MaxRecent is how many recently visited resources are remembered.
+const MaxRecent = 9
+
+// RecentVisit is a resource opened in the detail view and the section it
+// was last on.
+type RecentVisit struct {
+	Resource string    `json:"resource"`
+	Section  string    `json:"section"`
+	Visited  time.Time `json:"visited"`
+}
+
+// LoadRecent loads recently visited resources from disk, most recent first.
+func LoadRecent() []RecentVisit {
+	data, err := os.ReadFile(filepath.Join(DataDir, "recent.json"))
+	if err != nil {
+		return nil
+	}
+
+	var visits []RecentVisit
+	if err := json.Unmarshal(data, &visits); err != nil {
+		return nil
+	}
+
+	return visits
+}
+
+// SaveRecent saves recently visited resources to disk.
+func SaveRecent(visits []RecentVisit) error {
+	if err := os.MkdirAll(DataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(visits, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(DataDir, "recent.json"), data, 0644)
+}
+
+// AddRecent moves v's resource to the front of visits, replacing its
+// earlier visit, and keeps at most MaxRecent.
+func AddRecent(visits []RecentVisit, v RecentVisit) []RecentVisit {
+	out := make([]RecentVisit, 0, min(len(visits)+1, MaxRecent))
+	out = append(out, v)
+	for _, old := range visits {
+		if len(out) == MaxRecent {
+			break
+		}
+		if old.Resource != v.Resource {
+			out = append(out, old)
+		}
+	}
+	return out
+}
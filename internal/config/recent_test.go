@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRecent(t *testing.T) {
+	orig := DataDir
+	DataDir = t.TempDir()
+	defer func() { DataDir = orig }()
+
+	var visits []RecentVisit
+	for i := range MaxRecent + 3 {
+		visits = AddRecent(visits, RecentVisit{Resource: fmt.Sprintf("res%d", i)})
+	}
+	if len(visits) != MaxRecent {
+		t.Fatalf("%d visits kept, want %d", len(visits), MaxRecent)
+	}
+
+	// Revisiting a resource moves it to the front with its new section
+	visits = AddRecent(visits, RecentVisit{Resource: "res5", Section: "Logs"})
+	if len(visits) != MaxRecent || visits[0].Section != "Logs" || visits[1].Resource != "res11" {
+		t.Errorf("after revisit: %+v", visits[:2])
+	}
+	for _, v := range visits[1:] {
+		if v.Resource == "res5" {
+			t.Error("res5 listed twice")
+		}
+	}
+
+	if err := SaveRecent(visits); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadRecent(); len(got) != MaxRecent || got[0] != visits[0] {
+		t.Errorf("LoadRecent() = %+v", got)
+	}
+}
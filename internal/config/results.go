@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ResultsDir holds tool results saved from the command palette.
+func ResultsDir() string {
+	return filepath.Join(DataDir, "results")
+}
+
+// ResultFileName returns a timestamped file name for a saved result, e.g.
+// "20260101-150405-list-pods.md".
+func ResultFileName(title string, at time.Time) string {
+	return at.Format("20060102-150405") + "-" + Slug(title, "result") + ".md"
+}
+
+// Slug lowercases s and joins its words with dashes for use in a file
+// name, or returns fallback when s has no letters or digits.
+func Slug(s, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return fallback
+	}
+	return slug
+}
+
+// SaveResult writes a raw tool result to ResultsDir and returns its path.
+func SaveResult(title, text string) (string, error) {
+	dir := ResultsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create results directory: %w", err)
+	}
+
+	path := filepath.Join(dir, ResultFileName(title, time.Now()))
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		return "", fmt.Errorf("failed to write result: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResultFileName(t *testing.T) {
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"list_pods", "20260102-150405-list_pods.md"},
+		{"🤖 Search Docs", "20260102-150405-search-docs.md"},
+		{"a / b", "20260102-150405-a-b.md"},
+		{"🤖", "20260102-150405-result.md"},
+		{"", "20260102-150405-result.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := ResultFileName(tt.title, at); got != tt.want {
+				t.Errorf("ResultFileName(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveResult(t *testing.T) {
+	orig := DataDir
+	DataDir = t.TempDir()
+	defer func() { DataDir = orig }()
+
+	path, err := SaveResult("get_logs", "line 1\nline 2\n")
+	if err != nil {
+		t.Fatalf("SaveResult() error = %v", err)
+	}
+	if filepath.Dir(path) != ResultsDir() {
+		t.Errorf("SaveResult() path = %q, want it in %q", path, ResultsDir())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "line 1\nline 2\n" {
+		t.Errorf("saved content = %q", data)
+	}
+}
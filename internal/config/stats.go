@@ -0,0 +1,68 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CommandStat counts how often a resource command has been run.
+type CommandStat struct {
+	Count    int       `json:"count"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// CommandStats maps CommandKey values to usage counts.
+type CommandStats map[string]CommandStat
+
+// CommandKey returns the stats key for a command in a resource. The command
+// text is hashed so edits to a command start a fresh count.
+func CommandKey(resource, command string) string {
+	sum := sha256.Sum256([]byte(command))
+	return resource + "/" + hex.EncodeToString(sum[:8])
+}
+
+// LoadCommandStats loads command usage counts from disk.
+func LoadCommandStats() CommandStats {
+	data, err := os.ReadFile(filepath.Join(DataDir, "command_stats.json"))
+	if err != nil {
+		return CommandStats{}
+	}
+
+	var stats CommandStats
+	if err := json.Unmarshal(data, &stats); err != nil || stats == nil {
+		return CommandStats{}
+	}
+
+	return stats
+}
+
+// SaveCommandStats saves command usage counts to disk.
+func SaveCommandStats(stats CommandStats) error {
+	if err := os.MkdirAll(DataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(DataDir, "command_stats.json"), data, 0644)
+}
+
+// Record counts one run of the command with the given key.
+func (s CommandStats) Record(key string, at time.Time) {
+	stat := s[key]
+	stat.Count++
+	stat.LastUsed = at
+	s[key] = stat
+}
+
+// Count returns how many times the command with the given key has run.
+func (s CommandStats) Count(key string) int {
+	return s[key].Count
+}
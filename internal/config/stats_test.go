@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommandStats(t *testing.T) {
+	stats := CommandStats{}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	docker := CommandKey("docker", "docker ps")
+	stats.Record(docker, now)
+	stats.Record(docker, now.Add(time.Minute))
+
+	if got := stats.Count(docker); got != 2 {
+		t.Errorf("Count() = %d, want 2", got)
+	}
+	if got := stats[docker].LastUsed; !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("LastUsed = %v, want %v", got, now.Add(time.Minute))
+	}
+
+	tests := []struct {
+		name     string
+		resource string
+		command  string
+	}{
+		{"same command in another resource", "podman", "docker ps"},
+		{"edited command", "docker", "docker ps -a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stats.Count(CommandKey(tt.resource, tt.command)); got != 0 {
+				t.Errorf("Count() = %d, want 0", got)
+			}
+		})
+	}
+}
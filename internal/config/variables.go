@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Variables are the values {{NAME}} placeholders in commands resolve from.
+// Global values apply in every resource; a resource's own values take
+// precedence over them.
+type Variables struct {
+	Global    map[string]string            `json:"global,omitempty"`
+	Resources map[string]map[string]string `json:"resources,omitempty"`
+}
+
+// LoadVariables loads the variables store from disk.
+func LoadVariables() Variables {
+	data, err := os.ReadFile(filepath.Join(DataDir, "variables.json"))
+	if err != nil {
+		return Variables{}
+	}
+
+	var vars Variables
+	if err := json.Unmarshal(data, &vars); err != nil {
+		return Variables{}
+	}
+	return vars
+}
+
+// SaveVariables saves the variables store to disk.
+func SaveVariables(vars Variables) error {
+	if err := os.MkdirAll(DataDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(vars, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(DataDir, "variables.json"), data, 0600)
+}
+
+// Lookup returns the value of name in resource, falling back to its global
+// value.
+func (v Variables) Lookup(resource, name string) (string, bool) {
+	if value, ok := v.Resources[resource][name]; ok {
+		return value, true
+	}
+	value, ok := v.Global[name]
+	return value, ok
+}
+
+// Set sets name in resource, or globally when resource is empty.
+func (v *Variables) Set(resource, name, value string) {
+	if resource == "" {
+		if v.Global == nil {
+			v.Global = make(map[string]string)
+		}
+		v.Global[name] = value
+		return
+	}
+	if v.Resources == nil {
+		v.Resources = make(map[string]map[string]string)
+	}
+	if v.Resources[resource] == nil {
+		v.Resources[resource] = make(map[string]string)
+	}
+	v.Resources[resource][name] = value
+}
+
+// Delete removes name from resource, or from the global values when
+// resource is empty.
+func (v *Variables) Delete(resource, name string) {
+	if resource == "" {
+		delete(v.Global, name)
+		return
+	}
+	delete(v.Resources[resource], name)
+	if len(v.Resources[resource]) == 0 {
+		delete(v.Resources, resource)
+	}
+}
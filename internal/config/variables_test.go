@@ -0,0 +1,42 @@
+package config
+
+import "testing"
+
+func TestVariables(t *testing.T) {
+	orig := DataDir
+	DataDir = t.TempDir()
+	defer func() { DataDir = orig }()
+
+	var vars Variables
+	vars.Set("", "SUBSCRIPTION_ID", "sub-1")
+	vars.Set("", "CLUSTER", "dev")
+	vars.Set("kubectl", "CLUSTER", "prod")
+	if err := SaveVariables(vars); err != nil {
+		t.Fatal(err)
+	}
+	vars = LoadVariables()
+
+	tests := []struct {
+		resource, name string
+		want           string
+		ok             bool
+	}{
+		{"kubectl", "CLUSTER", "prod", true},
+		{"docker", "CLUSTER", "dev", true},
+		{"kubectl", "SUBSCRIPTION_ID", "sub-1", true},
+		{"kubectl", "NAMESPACE", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := vars.Lookup(tt.resource, tt.name); got != tt.want || ok != tt.ok {
+			t.Errorf("Lookup(%q, %q) = %q, %v, want %q, %v", tt.resource, tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	vars.Delete("kubectl", "CLUSTER")
+	if got, _ := vars.Lookup("kubectl", "CLUSTER"); got != "dev" {
+		t.Errorf("after Delete, Lookup = %q, want the global value", got)
+	}
+	if _, ok := vars.Resources["kubectl"]; ok {
+		t.Error("empty resource scope kept")
+	}
+}
@@ -0,0 +1,334 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// MethodFargate runs the agent as a one-off ECS task on Fargate.
+const MethodFargate = "fargate"
+
+// awsLogGroup is the CloudWatch log group agent tasks write to.
+const awsLogGroup = "/skitz/agents"
+
+// aws runs agents against Bedrock models as ECS Fargate tasks. The task
+// role must allow bedrock:InvokeModel; no API key is passed to the task.
+type aws struct{}
+
+func (*aws) Name() string    { return "aws" }
+func (*aws) Label() string   { return "AWS" }
+func (*aws) Available() bool { return onPath("aws") }
+
+func (*aws) InstallHint() string {
+	return "AWS CLI required. Install from:\nhttps://docs.aws.amazon.com/cli/latest/userguide/getting-started-install.html"
+}
+
+// Accounts returns the account and region of the active AWS CLI profile.
+func (*aws) Accounts(ctx context.Context) ([]Account, error) {
+	id, err := output(ctx, "aws", "sts", "get-caller-identity", "--query", "Account", "--output", "text")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AWS account: %w", err)
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		out, _ := output(ctx, "aws", "configure", "get", "region")
+		region = string(out)
+	}
+	if region == "" {
+		return nil, errors.New("no AWS region configured (run aws configure or set AWS_REGION)")
+	}
+
+	return []Account{{Name: string(id), Kind: "AWS account", Location: region}}, nil
+}
+
+// Models lists the on-demand text models Bedrock offers in the account's
+// region.
+func (*aws) Models(ctx context.Context, account Account) ([]Model, error) {
+	out, err := output(ctx, "aws", "bedrock", "list-foundation-models",
+		"--region", account.Location,
+		"--by-output-modality", "TEXT",
+		"--by-inference-type", "ON_DEMAND",
+		"--query", "modelSummaries[].{id:modelId, provider:providerName}",
+		"--output", "json",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Bedrock models: %w", err)
+	}
+
+	var summaries []struct {
+		ID       string `json:"id"`
+		Provider string `json:"provider"`
+	}
+	if err := json.Unmarshal(out, &summaries); err != nil {
+		return nil, fmt.Errorf("failed to parse Bedrock models: %w", err)
+	}
+
+	models := make([]Model, 0, len(summaries))
+	for _, s := range summaries {
+		models = append(models, Model{Name: s.ID, ID: s.ID, Vendor: s.Provider})
+	}
+	return models, nil
+}
+
+func (*aws) Methods() []Method {
+	return []Method{
+		{ID: MethodFargate, Label: "ECS Fargate task", Hint: "Run once on Fargate", Fields: []Field{
+			{Key: "cluster", Label: "ECS cluster", Default: "default", Required: true},
+			{Key: "subnets", Label: "Subnet IDs (comma-separated)", Placeholder: "subnet-0abc,subnet-0def", Required: true},
+			{Key: "security_groups", Label: "Security group IDs (optional)", Placeholder: "sg-0abc"},
+			{Key: "execution_role", Label: "Task execution role ARN", Placeholder: "arn:aws:iam::123456789012:role/ecsTaskExecutionRole", Required: true},
+			{Key: "task_role", Label: "Task role ARN (needs bedrock:InvokeModel)", Placeholder: "arn:aws:iam::123456789012:role/skitz-agent", Required: true},
+		}},
+	}
+}
+
+func (a *aws) Deploy(ctx context.Context, req Request) (Result, error) {
+	method, err := FindMethod(a, req.Method)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := checkFields(method, req.Values); err != nil {
+		return Result{}, err
+	}
+
+	containers, err := awsContainerDefinitions(req)
+	if err != nil {
+		return Result{}, err
+	}
+	region := req.Account.Location
+
+	register := exec.CommandContext(ctx, "aws", "ecs", "register-task-definition",
+		"--region", region,
+		"--family", req.Name,
+		"--requires-compatibilities", "FARGATE",
+		"--network-mode", "awsvpc",
+		"--cpu", "256",
+		"--memory", "512",
+		"--execution-role-arn", req.Values["execution_role"],
+		"--task-role-arn", req.Values["task_role"],
+		"--container-definitions", containers,
+	)
+	if out, err := register.CombinedOutput(); err != nil {
+		return Result{}, fmt.Errorf("failed to register task definition: %s", strings.TrimSpace(string(out)))
+	}
+
+	d := record(a, req)
+	taskARN, err := runTask(ctx, d)
+	if err != nil {
+		return Result{}, err
+	}
+	d.Ref = taskARN
+
+	return Result{
+		Summary: fmt.Sprintf("Task started on cluster '%s'\nTask: %s\nRegion: %s\nModel: %s",
+			req.Values["cluster"], taskARN, region, req.Model.ID),
+		Deployment: d,
+	}, nil
+}
+
+// runTask starts the task definition registered for d and returns the
+// task ARN.
+func runTask(ctx context.Context, d config.Deployment) (string, error) {
+	out, err := exec.CommandContext(ctx, "aws", "ecs", "run-task",
+		"--region", d.Location,
+		"--cluster", d.Values["cluster"],
+		"--launch-type", "FARGATE",
+		"--task-definition", d.Name,
+		"--network-configuration", awsNetworkConfiguration(d.Values),
+		"--query", "tasks[0].taskArn",
+		"--output", "text",
+	).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to run task: %s", strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (*aws) Status(ctx context.Context, d config.Deployment) (Status, error) {
+	out, err := output(ctx, "aws", "ecs", "describe-tasks",
+		"--region", d.Location,
+		"--cluster", d.Values["cluster"],
+		"--tasks", d.Ref,
+		"--query", "tasks[0].[lastStatus, containers[0].exitCode]",
+		"--output", "text",
+	)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to describe task: %w", err)
+	}
+
+	// ECS forgets stopped tasks after about an hour.
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 || fields[0] == "None" {
+		return Status{State: StateStopped, Detail: "task expired"}, nil
+	}
+	status := fields[0]
+
+	switch status {
+	case "RUNNING":
+		return Status{State: StateRunning, Detail: status}, nil
+	case "PROVISIONING", "PENDING", "ACTIVATING":
+		return Status{State: StatePending, Detail: status}, nil
+	case "STOPPED":
+		if len(fields) > 1 && fields[1] == "0" {
+			return Status{State: StateSucceeded, Detail: "exit 0"}, nil
+		}
+		if len(fields) > 1 && fields[1] != "None" {
+			return Status{State: StateFailed, Detail: "exit " + fields[1]}, nil
+		}
+		return Status{State: StateStopped, Detail: status}, nil
+	case "DEACTIVATING", "STOPPING", "DEPROVISIONING":
+		return Status{State: StateStopped, Detail: status}, nil
+	}
+	return Status{State: StateUnknown, Detail: status}, nil
+}
+
+func (*aws) LogsCommand(d config.Deployment) []string {
+	return []string{"aws", "logs", "tail", awsLogGroup,
+		"--region", d.Location,
+		"--log-stream-name-prefix", d.Name,
+		"--follow",
+	}
+}
+
+// Restart runs a new task from the deployment's task definition.
+func (*aws) Restart(ctx context.Context, d config.Deployment) (config.Deployment, error) {
+	taskARN, err := runTask(ctx, d)
+	if err != nil {
+		return d, err
+	}
+	d.Ref = taskARN
+	d.Timestamp = time.Now()
+	return d, nil
+}
+
+// Teardown stops the task and deregisters every revision of its task
+// definition.
+func (*aws) Teardown(ctx context.Context, d config.Deployment) error {
+	if d.Ref != "" {
+		if _, err := output(ctx, "aws", "ecs", "stop-task",
+			"--region", d.Location,
+			"--cluster", d.Values["cluster"],
+			"--task", d.Ref,
+		); err != nil && !strings.Contains(err.Error(), "not found") {
+			return fmt.Errorf("failed to stop task: %w", err)
+		}
+	}
+
+	out, err := output(ctx, "aws", "ecs", "list-task-definitions",
+		"--region", d.Location,
+		"--family-prefix", d.Name,
+		"--query", "taskDefinitionArns",
+		"--output", "text",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to list task definitions: %w", err)
+	}
+	for _, arn := range awsFamilyRevisions(strings.Fields(string(out)), d.Name) {
+		if _, err := output(ctx, "aws", "ecs", "deregister-task-definition",
+			"--region", d.Location,
+			"--task-definition", arn,
+		); err != nil {
+			return fmt.Errorf("failed to deregister task definition: %w", err)
+		}
+	}
+	return nil
+}
+
+// awsFamilyRevisions returns the task definition ARNs of family itself:
+// --family-prefix also lists families that only start with its name, such
+// as agent-prod for agent.
+func awsFamilyRevisions(arns []string, family string) []string {
+	var matched []string
+	for _, arn := range arns {
+		// arn:aws:ecs:<region>:<account>:task-definition/<family>:<revision>
+		_, def, ok := strings.Cut(arn, ":task-definition/")
+		if !ok {
+			continue
+		}
+		if name, _, _ := strings.Cut(def, ":"); name == family {
+			matched = append(matched, arn)
+		}
+	}
+	return matched
+}
+
+// awsContainerDefinitions returns the task's container definitions as the
+// JSON register-task-definition expects.
+func awsContainerDefinitions(req Request) (string, error) {
+	type keyValue struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	type container struct {
+		Name             string     `json:"name"`
+		Image            string     `json:"image"`
+		Essential        bool       `json:"essential"`
+		Command          []string   `json:"command"`
+		Environment      []keyValue `json:"environment"`
+		LogConfiguration struct {
+			LogDriver string            `json:"logDriver"`
+			Options   map[string]string `json:"options"`
+		} `json:"logConfiguration"`
+	}
+
+	c := container{
+		Name:      "agent",
+		Image:     AgentImage,
+		Essential: true,
+		Command:   []string{"/bin/sh", "-c", bedrockAgentScript},
+		Environment: []keyValue{
+			{"AWS_REGION", req.Account.Location},
+			{"BEDROCK_MODEL_ID", req.Model.ID},
+			{"AGENT_PROMPT", req.Prompt},
+		},
+	}
+	c.LogConfiguration.LogDriver = "awslogs"
+	c.LogConfiguration.Options = map[string]string{
+		"awslogs-group":         awsLogGroup,
+		"awslogs-region":        req.Account.Location,
+		"awslogs-stream-prefix": req.Name,
+		"awslogs-create-group":  "true",
+	}
+
+	data, err := json.Marshal([]container{c})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode container definitions: %w", err)
+	}
+	return string(data), nil
+}
+
+// awsNetworkConfiguration returns the run-task awsvpc configuration in the
+// AWS CLI shorthand syntax.
+func awsNetworkConfiguration(values map[string]string) string {
+	cfg := fmt.Sprintf("awsvpcConfiguration={subnets=[%s]", strings.Join(splitList(values["subnets"]), ","))
+	if groups := splitList(values["security_groups"]); len(groups) > 0 {
+		cfg += fmt.Sprintf(",securityGroups=[%s]", strings.Join(groups, ","))
+	}
+	return cfg + ",assignPublicIp=ENABLED}"
+}
+
+// bedrockAgentScript sends AGENT_PROMPT to BEDROCK_MODEL_ID with the
+// Converse API, authenticating as the task role.
+const bedrockAgentScript = `pip install -q boto3 && python3 -c "
+import os, boto3
+
+client = boto3.client('bedrock-runtime')
+response = client.converse(
+    modelId=os.environ['BEDROCK_MODEL_ID'],
+    messages=[{'role': 'user', 'content': [{'text': os.environ['AGENT_PROMPT']}]}],
+)
+
+print(response['output']['message']['content'][0]['text'])
+"`
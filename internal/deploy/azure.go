@@ -0,0 +1,362 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	azurepkg "github.com/htelsiz/skitz/internal/azure"
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Azure deploy methods.
+const (
+	MethodACI      = "aci"
+	MethodPipeline = "pipeline"
+)
+
+// azure runs agents against Azure OpenAI deployments, either in an Azure
+// Container Instance or an Azure DevOps pipeline. Management calls go
+// through internal/azure; pipelines always need the az CLI. With no
+// subscription set, the credential's default one is used.
+type azure struct {
+	subscription string
+	client       azurepkg.Client
+}
+
+func (*azure) Name() string  { return "azure" }
+func (*azure) Label() string { return "Azure" }
+
+// Available is always true: the Azure SDK is built in, and its credentials
+// are only checked when the wizard connects.
+func (*azure) Available() bool { return true }
+
+func (*azure) InstallHint() string {
+	return "Azure CLI required. Install from:\nhttps://docs.microsoft.com/en-us/cli/azure/install-azure-cli"
+}
+
+// azureClient returns the provider's management client, creating it on
+// first use.
+func (a *azure) azureClient(ctx context.Context) (azurepkg.Client, error) {
+	if a.client == nil {
+		client, err := azurepkg.New(ctx, a.subscription)
+		if err != nil {
+			return nil, err
+		}
+		a.client = client
+	}
+	return a.client, nil
+}
+
+func (a *azure) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	client, err := a.azureClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	subs, err := client.Subscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Subscription, 0, len(subs))
+	for _, s := range subs {
+		result = append(result, Subscription(s))
+	}
+	return result, nil
+}
+
+func (*azure) WithSubscription(id string) Provider {
+	return &azure{subscription: id}
+}
+
+// args appends --subscription to az arguments when a subscription is set.
+func (a *azure) args(args ...string) []string {
+	if a.subscription != "" {
+		args = append(args, "--subscription", a.subscription)
+	}
+	return args
+}
+
+func (a *azure) Accounts(ctx context.Context) ([]Account, error) {
+	client, err := a.azureClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := client.Accounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Account, 0, len(accounts))
+	for _, acc := range accounts {
+		result = append(result, Account{
+			Name:          acc.Name,
+			Kind:          acc.Kind,
+			Location:      acc.Location,
+			ResourceGroup: acc.ResourceGroup,
+			Endpoint:      acc.Endpoint,
+		})
+	}
+	return result, nil
+}
+
+func (a *azure) Models(ctx context.Context, account Account) ([]Model, error) {
+	client, err := a.azureClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := client.Deployments(ctx, account.ResourceGroup, account.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]Model, 0, len(deployments))
+	for _, d := range deployments {
+		models = append(models, Model{Name: d.Name, ID: d.Model, Version: d.Version})
+	}
+	return models, nil
+}
+
+func (*azure) Methods() []Method {
+	return []Method{
+		{ID: MethodACI, Label: "Azure Container Instance", Hint: "Run once in a container"},
+		{ID: MethodPipeline, Label: "Azure Pipeline", Hint: "Run as CI/CD pipeline", Fields: []Field{
+			{Key: "org", Label: "Azure DevOps Org URL", Placeholder: "https://dev.azure.com/myorg", Required: true},
+			{Key: "project", Label: "Project name", Placeholder: "MyProject", Required: true},
+		}},
+	}
+}
+
+func (a *azure) Deploy(ctx context.Context, req Request) (Result, error) {
+	method, err := FindMethod(a, req.Method)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := checkFields(method, req.Values); err != nil {
+		return Result{}, err
+	}
+
+	if req.Method == MethodPipeline {
+		return a.deployPipeline(ctx, req)
+	}
+
+	client, err := a.azureClient(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	key, err := client.Key(ctx, req.Account.ResourceGroup, req.Account.Name)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if err := client.CreateContainer(ctx, azurepkg.Container{
+		ResourceGroup: req.Account.ResourceGroup,
+		Name:          req.Name,
+		Location:      req.Account.Location,
+		Image:         AgentImage,
+		Command:       []string{"/bin/sh", "-c", azureAgentScript},
+		Env: map[string]string{
+			"AZURE_OPENAI_ENDPOINT":   req.Account.Endpoint,
+			"AZURE_OPENAI_DEPLOYMENT": req.Model.Name,
+			"AGENT_PROMPT":            req.Prompt,
+		},
+		SecureEnv: map[string]string{"AZURE_OPENAI_API_KEY": key},
+	}); err != nil {
+		return Result{}, fmt.Errorf("deployment failed: %w", err)
+	}
+
+	d := record(a, req)
+	d.Subscription = a.subscription
+	return Result{
+		Summary: fmt.Sprintf("Container '%s' deployed\nResource Group: %s\nLocation: %s\nModel: %s",
+			req.Name, req.Account.ResourceGroup, req.Account.Location, req.Model.ID),
+		Deployment: d,
+	}, nil
+}
+
+func (a *azure) deployPipeline(ctx context.Context, req Request) (Result, error) {
+	d := record(a, req)
+	d.Subscription = a.subscription
+	runID, err := runPipeline(ctx, d)
+	if err != nil {
+		return Result{}, err
+	}
+	d.Ref = runID
+	return Result{
+		Summary:    fmt.Sprintf("Pipeline '%s' started in %s (run %s)", req.Name, req.Values["project"], runID),
+		Deployment: d,
+	}, nil
+}
+
+// runPipeline queues the pipeline named after d and returns the run ID.
+func runPipeline(ctx context.Context, d config.Deployment) (string, error) {
+	if exec.CommandContext(ctx, "az", "extension", "show", "--name", "azure-devops").Run() != nil {
+		return "", errors.New("Azure DevOps CLI required. Install with: az extension add --name azure-devops")
+	}
+
+	out, err := exec.CommandContext(ctx, "az", "pipelines", "run",
+		"--org", d.Values["org"],
+		"--project", d.Values["project"],
+		"--name", d.Name,
+		"--query", "id",
+		"-o", "tsv",
+	).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("pipeline failed: %s", strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (a *azure) Status(ctx context.Context, d config.Deployment) (Status, error) {
+	if d.Method == MethodPipeline {
+		return pipelineStatus(ctx, d)
+	}
+
+	client, err := a.azureClient(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	state, err := client.ContainerState(ctx, d.ResourceGroup, d.Name)
+	if errors.Is(err, azurepkg.ErrNotFound) {
+		return Status{State: StateDeleted}, nil
+	}
+	if err != nil {
+		return Status{}, err
+	}
+
+	switch state {
+	case "Running":
+		return Status{State: StateRunning, Detail: state}, nil
+	case "Succeeded":
+		return Status{State: StateSucceeded, Detail: state}, nil
+	case "Failed":
+		return Status{State: StateFailed, Detail: state}, nil
+	case "Stopped", "Terminated":
+		return Status{State: StateStopped, Detail: state}, nil
+	case "Pending", "Creating", "Waiting", "":
+		return Status{State: StatePending, Detail: state}, nil
+	}
+	return Status{State: StateUnknown, Detail: state}, nil
+}
+
+// pipelineStatus maps an Azure DevOps run's status and result to a Status.
+func pipelineStatus(ctx context.Context, d config.Deployment) (Status, error) {
+	if d.Ref == "" {
+		return Status{State: StateUnknown}, nil
+	}
+	out, err := output(ctx, "az", "pipelines", "runs", "show",
+		"--id", d.Ref,
+		"--org", d.Values["org"],
+		"--project", d.Values["project"],
+		"--query", "[status, result]",
+		"-o", "tsv",
+	)
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to get pipeline run: %w", err)
+	}
+
+	fields := strings.Fields(string(out))
+	status, result := "", ""
+	if len(fields) > 0 {
+		status = fields[0]
+	}
+	if len(fields) > 1 {
+		result = fields[1]
+	}
+	detail := strings.TrimSpace(status + " " + result)
+
+	switch {
+	case status == "inProgress" || status == "cancelling":
+		return Status{State: StateRunning, Detail: detail}, nil
+	case status == "notStarted" || status == "postponed":
+		return Status{State: StatePending, Detail: detail}, nil
+	case result == "succeeded" || result == "partiallySucceeded":
+		return Status{State: StateSucceeded, Detail: detail}, nil
+	case result == "failed":
+		return Status{State: StateFailed, Detail: detail}, nil
+	case result == "canceled":
+		return Status{State: StateStopped, Detail: detail}, nil
+	}
+	return Status{State: StateUnknown, Detail: detail}, nil
+}
+
+func (a *azure) LogsCommand(d config.Deployment) []string {
+	if d.Method == MethodPipeline || !azurepkg.CLIAvailable() {
+		return nil
+	}
+	return append([]string{"az"}, a.args("container", "logs",
+		"--resource-group", d.ResourceGroup,
+		"--name", d.Name,
+		"--follow",
+	)...)
+}
+
+func (a *azure) Restart(ctx context.Context, d config.Deployment) (config.Deployment, error) {
+	if d.Method == MethodPipeline {
+		runID, err := runPipeline(ctx, d)
+		if err != nil {
+			return d, err
+		}
+		d.Ref = runID
+		d.Timestamp = time.Now()
+		return d, nil
+	}
+
+	client, err := a.azureClient(ctx)
+	if err != nil {
+		return d, err
+	}
+	if err := client.StartContainer(ctx, d.ResourceGroup, d.Name); err != nil {
+		return d, err
+	}
+	d.Timestamp = time.Now()
+	return d, nil
+}
+
+// Teardown deletes the container group, or cancels the pipeline run.
+func (a *azure) Teardown(ctx context.Context, d config.Deployment) error {
+	if d.Method == MethodPipeline {
+		if d.Ref == "" {
+			return nil
+		}
+		if _, err := output(ctx, "az", "pipelines", "build", "cancel",
+			"--build-id", d.Ref,
+			"--org", d.Values["org"],
+			"--project", d.Values["project"],
+		); err != nil {
+			return fmt.Errorf("failed to cancel pipeline run: %w", err)
+		}
+		return nil
+	}
+
+	client, err := a.azureClient(ctx)
+	if err != nil {
+		return err
+	}
+	if err := client.DeleteContainer(ctx, d.ResourceGroup, d.Name); err != nil && !errors.Is(err, azurepkg.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// azureAgentScript sends AGENT_PROMPT to the deployment named by
+// AZURE_OPENAI_DEPLOYMENT.
+const azureAgentScript = `pip install -q openai && python3 -c "
+import os
+from openai import AzureOpenAI
+
+client = AzureOpenAI(
+    azure_endpoint=os.environ['AZURE_OPENAI_ENDPOINT'],
+    api_key=os.environ['AZURE_OPENAI_API_KEY'],
+    api_version='2024-02-15-preview',
+)
+response = client.chat.completions.create(
+    model=os.environ['AZURE_OPENAI_DEPLOYMENT'],
+    messages=[{'role': 'user', 'content': os.environ['AGENT_PROMPT']}],
+)
+
+print(response.choices[0].message.content)
+"`
@@ -0,0 +1,255 @@
+// Package deploy runs one-shot agents on a cloud provider. Each provider
+// discovers where models are hosted (an Azure AI account, an AWS region, a
+// GCP project), lists the models available there and deploys a container
+// that sends the task to the selected model.
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// AgentImage is the container image agents run in. The agent script
+// installs the provider SDK at startup.
+const AgentImage = "python:3.11-slim"
+
+// Sentinel errors for provider lookup and deployment.
+var (
+	ErrUnknownProvider = errors.New("unknown deploy provider")
+	ErrUnknownMethod   = errors.New("unknown deploy method")
+	ErrMissingField    = errors.New("missing required field")
+)
+
+// Provider is a cloud that can host agent runs.
+type Provider interface {
+	// Name is the identifier used in history and on the command line.
+	Name() string
+	// Label is a human-readable name for menus.
+	Label() string
+	// Available reports whether the provider's CLI is on PATH.
+	Available() bool
+	// InstallHint tells the user how to install the provider's CLI.
+	InstallHint() string
+	// Accounts lists the places models can be used from.
+	Accounts(ctx context.Context) ([]Account, error)
+	// Models lists the models usable from account.
+	Models(ctx context.Context, account Account) ([]Model, error)
+	// Methods lists the ways an agent can be run.
+	Methods() []Method
+	// Deploy starts an agent run.
+	Deploy(ctx context.Context, req Request) (Result, error)
+
+	// Status reports the current state of a recorded deployment.
+	Status(ctx context.Context, d config.Deployment) (Status, error)
+	// LogsCommand returns a command that shows the deployment's output, or
+	// nil when the method has no logs to show.
+	LogsCommand(d config.Deployment) []string
+	// Restart runs the deployment again and returns its updated record.
+	Restart(ctx context.Context, d config.Deployment) (config.Deployment, error)
+	// Teardown deletes the deployment's cloud resources, or cancels it when
+	// there is nothing to delete.
+	Teardown(ctx context.Context, d config.Deployment) error
+}
+
+// Deployment states reported by Status.State.
+const (
+	StatePending   = "pending"
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+	StateStopped   = "stopped"
+	StateDeleted   = "deleted"
+	StateUnknown   = "unknown"
+)
+
+// Status is a deployment's live state.
+type Status struct {
+	State string
+	// Detail is the provider's own status text, shown next to State.
+	Detail string
+}
+
+// SubscriptionProvider is implemented by providers whose accounts live in
+// a subscription picked before Accounts is called.
+type SubscriptionProvider interface {
+	Provider
+	// Subscriptions lists the subscriptions the CLI is logged in to.
+	Subscriptions(ctx context.Context) ([]Subscription, error)
+	// WithSubscription returns a copy of the provider whose commands all
+	// target the subscription with the given ID.
+	WithSubscription(id string) Provider
+}
+
+// Subscription is a billing scope within a tenant, such as an Azure
+// subscription.
+type Subscription struct {
+	ID         string
+	Name       string
+	TenantID   string
+	TenantName string
+	Default    bool
+}
+
+// Account is where models are hosted: an Azure AI account, an AWS account
+// and region, or a GCP project.
+type Account struct {
+	Name     string
+	Kind     string
+	Location string
+
+	// ResourceGroup and Endpoint are only set for Azure.
+	ResourceGroup string
+	Endpoint      string
+}
+
+// Model is a model the agent can call. For Azure, Name is the deployment
+// and ID the underlying model; elsewhere both are the model ID.
+type Model struct {
+	Name    string
+	ID      string
+	Version string
+	Vendor  string
+}
+
+// Method is a way of running the agent, such as a container instance or a
+// pipeline. Fields are asked for after the method is chosen.
+type Method struct {
+	ID     string
+	Label  string
+	Hint   string
+	Fields []Field
+}
+
+// Field is an extra input a method needs.
+type Field struct {
+	Key         string
+	Label       string
+	Placeholder string
+	Default     string
+	Required    bool
+}
+
+// Request describes an agent deployment.
+type Request struct {
+	Name    string
+	Prompt  string
+	Account Account
+	Model   Model
+	Method  string
+	// Values holds the method's Field values by key.
+	Values map[string]string
+}
+
+// Result describes a started deployment.
+type Result struct {
+	Summary    string
+	Deployment config.Deployment
+}
+
+var providers = []Provider{
+	&azure{},
+	&aws{},
+	&gcp{},
+}
+
+// All returns every provider in menu order.
+func All() []Provider {
+	return providers
+}
+
+// Get returns the provider with the given name.
+func Get(name string) (Provider, error) {
+	for _, p := range providers {
+		if p.Name() == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+}
+
+// ForDeployment returns the provider that created d, scoped to its
+// subscription when the provider has them.
+func ForDeployment(d config.Deployment) (Provider, error) {
+	p, err := Get(d.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if sp, ok := p.(SubscriptionProvider); ok && d.Subscription != "" {
+		return sp.WithSubscription(d.Subscription), nil
+	}
+	return p, nil
+}
+
+// record returns the deployment record for req.
+func record(p Provider, req Request) config.Deployment {
+	return config.Deployment{
+		Name:          req.Name,
+		Provider:      p.Name(),
+		Method:        req.Method,
+		Account:       req.Account.Name,
+		ResourceGroup: req.Account.ResourceGroup,
+		Location:      req.Account.Location,
+		Endpoint:      req.Account.Endpoint,
+		Model:         req.Model.Name,
+		Values:        req.Values,
+		Timestamp:     time.Now(),
+	}
+}
+
+// FindMethod returns the method with the given ID.
+func FindMethod(p Provider, id string) (Method, error) {
+	for _, m := range p.Methods() {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return Method{}, fmt.Errorf("%w: %s", ErrUnknownMethod, id)
+}
+
+// checkFields verifies that every required field of method has a value.
+func checkFields(method Method, values map[string]string) error {
+	for _, f := range method.Fields {
+		if f.Required && strings.TrimSpace(values[f.Key]) == "" {
+			return fmt.Errorf("%w: %s", ErrMissingField, f.Label)
+		}
+	}
+	return nil
+}
+
+// onPath reports whether binary is on PATH.
+func onPath(binary string) bool {
+	_, err := exec.LookPath(binary)
+	return err == nil
+}
+
+// output runs a CLI and returns its trimmed stdout, including stderr in the
+// error when it fails.
+func output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%s %s: %s", name, args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return []byte(strings.TrimSpace(string(out))), nil
+}
+
+// splitList splits a comma-separated field value, dropping blanks.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestAvailable verifies CLI detection for every provider
+func TestAvailable(t *testing.T) {
+	for _, p := range All() {
+		t.Logf("%s Available() = %v", p.Name(), p.Available())
+	}
+	if _, err := Get("oracle"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Get(oracle) error = %v, want ErrUnknownProvider", err)
+	}
+}
+
+func TestAzureAvailable(t *testing.T) {
+	p, err := Get("azure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The SDK is built in, so az is not needed
+	t.Setenv("PATH", t.TempDir())
+	if !p.Available() {
+		t.Error("Available() = false without az")
+	}
+	if hint := p.InstallHint(); !strings.Contains(hint, "Azure CLI") || !strings.Contains(hint, "https://") {
+		t.Errorf("InstallHint() = %q", hint)
+	}
+}
+
+func TestAWSFamilyRevisions(t *testing.T) {
+	arns := []string{
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/agent:1",
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/agent:2",
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/agent-prod:1",
+		"arn:aws:ecs:us-east-1:123456789012:task-definition/agent2:4",
+		"None",
+	}
+	got := awsFamilyRevisions(arns, "agent")
+	if len(got) != 2 || !strings.HasSuffix(got[0], "/agent:1") || !strings.HasSuffix(got[1], "/agent:2") {
+		t.Errorf("awsFamilyRevisions() = %v, want only agent's revisions", got)
+	}
+}
+
+func TestDeployChecksFields(t *testing.T) {
+	tests := []struct {
+		provider string
+		method   string
+		values   map[string]string
+		want     error
+	}{
+		{"aws", "aci", nil, ErrUnknownMethod},
+		{"aws", MethodFargate, map[string]string{"cluster": "default"}, ErrMissingField},
+		{"azure", MethodPipeline, map[string]string{"org": "https://dev.azure.com/x", "project": " "}, ErrMissingField},
+		{"gcp", MethodFargate, nil, ErrUnknownMethod},
+	}
+
+	for _, tt := range tests {
+		p, err := Get(tt.provider)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", tt.provider, err)
+		}
+		_, err = p.Deploy(context.Background(), Request{Name: "agent-1", Method: tt.method, Values: tt.values})
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s Deploy(%s) error = %v, want %v", tt.provider, tt.method, err, tt.want)
+		}
+	}
+}
+
+func TestAWSTaskConfig(t *testing.T) {
+	req := Request{
+		Name:    "agent-1",
+		Prompt:  `say "hi", then exit`,
+		Account: Account{Name: "123456789012", Location: "us-east-1"},
+		Model:   Model{Name: "anthropic.claude-3-haiku", ID: "anthropic.claude-3-haiku"},
+		Values:  map[string]string{"subnets": "subnet-a, subnet-b,", "security_groups": "sg-1"},
+	}
+
+	want := "awsvpcConfiguration={subnets=[subnet-a,subnet-b],securityGroups=[sg-1],assignPublicIp=ENABLED}"
+	if got := awsNetworkConfiguration(req.Values); got != want {
+		t.Errorf("awsNetworkConfiguration() = %s, want %s", got, want)
+	}
+
+	data, err := awsContainerDefinitions(req)
+	if err != nil {
+		t.Fatalf("awsContainerDefinitions() error = %v", err)
+	}
+	var defs []struct {
+		Image       string `json:"image"`
+		Environment []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"environment"`
+	}
+	if err := json.Unmarshal([]byte(data), &defs); err != nil {
+		t.Fatalf("container definitions are not JSON: %v", err)
+	}
+	if len(defs) != 1 || defs[0].Image != AgentImage {
+		t.Fatalf("container definitions = %s", data)
+	}
+	env := make(map[string]string)
+	for _, kv := range defs[0].Environment {
+		env[kv.Name] = kv.Value
+	}
+	if env["AGENT_PROMPT"] != req.Prompt || env["BEDROCK_MODEL_ID"] != req.Model.ID {
+		t.Errorf("environment = %v", env)
+	}
+}
+
+func TestGCPDeployArgs(t *testing.T) {
+	req := Request{
+		Name:    "agent-1",
+		Account: Account{Name: "my-project", Location: "europe-west1"},
+		Values:  map[string]string{"service_account": "sa@my-project.iam.gserviceaccount.com"},
+	}
+
+	args := strings.Join(gcpDeployArgs(req, "/tmp/env.yaml"), " ")
+	for _, want := range []string{
+		"run jobs deploy agent-1",
+		"--project my-project",
+		"--region europe-west1",
+		"--env-vars-file /tmp/env.yaml",
+		"--execute-now",
+		"--service-account sa@my-project.iam.gserviceaccount.com",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("gcpDeployArgs() = %s, missing %q", args, want)
+		}
+	}
+	if strings.Contains(vertexAgentScript, "|") {
+		t.Error("vertexAgentScript contains the ^|^ delimiter")
+	}
+}
+
+func TestAzureSubscriptionArgs(t *testing.T) {
+	p, err := Get("azure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp, ok := p.(SubscriptionProvider)
+	if !ok {
+		t.Fatal("azure does not implement SubscriptionProvider")
+	}
+
+	if got := strings.Join(p.(*azure).args("container", "list"), " "); got != "container list" {
+		t.Errorf("args() without subscription = %s", got)
+	}
+	scoped := sp.WithSubscription("sub-1").(*azure)
+	if got := strings.Join(scoped.args("container", "list"), " "); got != "container list --subscription sub-1" {
+		t.Errorf("args() with subscription = %s", got)
+	}
+	if p.(*azure).subscription != "" {
+		t.Error("WithSubscription modified the shared provider")
+	}
+}
@@ -0,0 +1,248 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// MethodCloudRun runs the agent as a Cloud Run job that executes once.
+const MethodCloudRun = "cloudrun"
+
+// defaultGCPRegion is used when gcloud has no run/region configured.
+const defaultGCPRegion = "us-central1"
+
+// vertexModels are the Gemini models offered on Vertex AI. Publisher models
+// are not listed per project by gcloud, so the choices are fixed.
+var vertexModels = []Model{
+	{Name: "gemini-2.5-pro", ID: "gemini-2.5-pro", Vendor: "Google"},
+	{Name: "gemini-2.5-flash", ID: "gemini-2.5-flash", Vendor: "Google"},
+	{Name: "gemini-2.5-flash-lite", ID: "gemini-2.5-flash-lite", Vendor: "Google"},
+	{Name: "gemini-2.0-flash", ID: "gemini-2.0-flash", Vendor: "Google"},
+}
+
+// gcp runs agents against Vertex AI models as Cloud Run jobs. The job's
+// service account must have the Vertex AI User role.
+type gcp struct{}
+
+func (*gcp) Name() string    { return "gcp" }
+func (*gcp) Label() string   { return "Google Cloud" }
+func (*gcp) Available() bool { return onPath("gcloud") }
+
+func (*gcp) InstallHint() string {
+	return "Google Cloud CLI required. Install from:\nhttps://cloud.google.com/sdk/docs/install"
+}
+
+// Accounts lists the projects the active gcloud account can see, located
+// in the configured Cloud Run region.
+func (*gcp) Accounts(ctx context.Context) ([]Account, error) {
+	out, err := output(ctx, "gcloud", "projects", "list", "--format", "json(projectId,name)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list GCP projects: %w", err)
+	}
+
+	var projects []struct {
+		ProjectID string `json:"projectId"`
+		Name      string `json:"name"`
+	}
+	if err := json.Unmarshal(out, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse GCP projects: %w", err)
+	}
+
+	region, _ := output(ctx, "gcloud", "config", "get-value", "run/region")
+	location := string(region)
+	if location == "" {
+		location = defaultGCPRegion
+	}
+
+	accounts := make([]Account, 0, len(projects))
+	for _, p := range projects {
+		accounts = append(accounts, Account{Name: p.ProjectID, Kind: p.Name, Location: location})
+	}
+	return accounts, nil
+}
+
+func (*gcp) Models(context.Context, Account) ([]Model, error) {
+	return vertexModels, nil
+}
+
+func (*gcp) Methods() []Method {
+	return []Method{
+		{ID: MethodCloudRun, Label: "Cloud Run job", Hint: "Run once as a Cloud Run job", Fields: []Field{
+			{Key: "service_account", Label: "Service account (optional)", Placeholder: "skitz-agent@my-project.iam.gserviceaccount.com"},
+		}},
+	}
+}
+
+func (g *gcp) Deploy(ctx context.Context, req Request) (Result, error) {
+	if _, err := FindMethod(g, req.Method); err != nil {
+		return Result{}, err
+	}
+
+	envFile, err := writeGCPEnvFile(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer os.Remove(envFile)
+
+	out, err := exec.CommandContext(ctx, "gcloud", gcpDeployArgs(req, envFile)...).CombinedOutput()
+	if err != nil {
+		return Result{}, fmt.Errorf("deployment failed: %s", strings.TrimSpace(string(out)))
+	}
+
+	return Result{
+		Summary: fmt.Sprintf("Cloud Run job '%s' started\nProject: %s\nRegion: %s\nModel: %s",
+			req.Name, req.Account.Name, req.Account.Location, req.Model.ID),
+		Deployment: record(g, req),
+	}, nil
+}
+
+// Status reports the state of the job's latest execution.
+func (*gcp) Status(ctx context.Context, d config.Deployment) (Status, error) {
+	out, err := output(ctx, "gcloud", "run", "jobs", "executions", "list",
+		"--job", d.Name,
+		"--project", d.Account,
+		"--region", d.Location,
+		"--limit", "1",
+		"--format", "json(status.runningCount, status.succeededCount, status.failedCount, status.cancelledCount)",
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "NOT_FOUND") || strings.Contains(err.Error(), "could not be found") {
+			return Status{State: StateDeleted}, nil
+		}
+		return Status{}, fmt.Errorf("failed to list job executions: %w", err)
+	}
+
+	var executions []struct {
+		Status struct {
+			Running   int `json:"runningCount"`
+			Succeeded int `json:"succeededCount"`
+			Failed    int `json:"failedCount"`
+			Cancelled int `json:"cancelledCount"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(out, &executions); err != nil {
+		return Status{}, fmt.Errorf("failed to parse job executions: %w", err)
+	}
+	if len(executions) == 0 {
+		return Status{State: StatePending}, nil
+	}
+
+	st := executions[0].Status
+	switch {
+	case st.Running > 0:
+		return Status{State: StateRunning}, nil
+	case st.Failed > 0:
+		return Status{State: StateFailed}, nil
+	case st.Cancelled > 0:
+		return Status{State: StateStopped, Detail: "cancelled"}, nil
+	case st.Succeeded > 0:
+		return Status{State: StateSucceeded}, nil
+	}
+	return Status{State: StatePending}, nil
+}
+
+func (*gcp) LogsCommand(d config.Deployment) []string {
+	return []string{"gcloud", "logging", "read",
+		fmt.Sprintf(`resource.type="cloud_run_job" AND resource.labels.job_name="%s"`, d.Name),
+		"--project", d.Account,
+		"--format", "value(textPayload)",
+		"--order", "asc",
+	}
+}
+
+// Restart executes the job again.
+func (*gcp) Restart(ctx context.Context, d config.Deployment) (config.Deployment, error) {
+	if _, err := output(ctx, "gcloud", "run", "jobs", "execute", d.Name,
+		"--project", d.Account,
+		"--region", d.Location,
+	); err != nil {
+		return d, fmt.Errorf("failed to execute job: %w", err)
+	}
+	d.Timestamp = time.Now()
+	return d, nil
+}
+
+// Teardown deletes the job and its executions.
+func (*gcp) Teardown(ctx context.Context, d config.Deployment) error {
+	if _, err := output(ctx, "gcloud", "run", "jobs", "delete", d.Name,
+		"--project", d.Account,
+		"--region", d.Location,
+		"--quiet",
+	); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	return nil
+}
+
+// gcpDeployArgs returns the gcloud arguments that create and execute the
+// agent job. The script is passed with gcloud's ^|^ list delimiter so its
+// commas survive.
+func gcpDeployArgs(req Request, envFile string) []string {
+	args := []string{"run", "jobs", "deploy", req.Name,
+		"--project", req.Account.Name,
+		"--region", req.Account.Location,
+		"--image", AgentImage,
+		"--command", "/bin/sh",
+		"--args", "^|^-c|" + vertexAgentScript,
+		"--env-vars-file", envFile,
+		"--max-retries", "0",
+		"--execute-now",
+	}
+	if sa := strings.TrimSpace(req.Values["service_account"]); sa != "" {
+		args = append(args, "--service-account", sa)
+	}
+	return args
+}
+
+// writeGCPEnvFile writes the job's environment to a temporary YAML file so
+// the prompt needs no escaping.
+func writeGCPEnvFile(req Request) (string, error) {
+	data, err := yaml.Marshal(map[string]string{
+		"GOOGLE_CLOUD_PROJECT":  req.Account.Name,
+		"GOOGLE_CLOUD_LOCATION": req.Account.Location,
+		"VERTEX_MODEL":          req.Model.ID,
+		"AGENT_PROMPT":          req.Prompt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job environment: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "skitz-agent-env-*.yaml")
+	if err != nil {
+		return "", fmt.Errorf("failed to create job environment file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write job environment file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// vertexAgentScript sends AGENT_PROMPT to VERTEX_MODEL, authenticating as
+// the job's service account. It must not contain "|".
+const vertexAgentScript = `pip install -q google-genai && python3 -c "
+import os
+from google import genai
+
+client = genai.Client(
+    vertexai=True,
+    project=os.environ['GOOGLE_CLOUD_PROJECT'],
+    location=os.environ['GOOGLE_CLOUD_LOCATION'],
+)
+response = client.models.generate_content(
+    model=os.environ['VERTEX_MODEL'],
+    contents=os.environ['AGENT_PROMPT'],
+)
+
+print(response.text)
+"`
@@ -0,0 +1,133 @@
+// Package features implements feature flags for experimental subsystems.
+//
+// A flag's value is resolved, highest precedence first, from a per-session
+// override, the SKITZ_FEATURE_<NAME> environment variable, the `features`
+// map in config.yaml, and finally the flag's built-in default.
+package features
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Flag describes a feature flag.
+type Flag struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+// Known lists every feature flag skitz understands. An experimental
+// subsystem adds its flag here and stays hidden unless Flags.Enabled
+// reports it on.
+var Known = []Flag{
+	{Name: E2B, Description: "E2B cloud sandboxes as an agent runtime"},
+}
+
+// Names of the known flags.
+const (
+	E2B = "e2b"
+)
+
+// Source identifies where a flag's effective value came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceConfig  Source = "config"
+	SourceEnv     Source = "env"
+	SourceSession Source = "session"
+)
+
+// State is the resolved value of a flag.
+type State struct {
+	Flag
+	Enabled bool
+	Source  Source
+}
+
+// Flags resolves feature flags from config, environment and session overrides.
+type Flags struct {
+	lock    sync.RWMutex
+	config  map[string]bool
+	session map[string]bool
+	lookup  func(string) (string, bool)
+}
+
+// New creates a flag set from the config `features` map.
+func New(cfg map[string]bool) *Flags {
+	return &Flags{
+		config:  cfg,
+		session: make(map[string]bool),
+		lookup:  os.LookupEnv,
+	}
+}
+
+// SetConfig replaces config values, keeping session overrides.
+func (f *Flags) SetConfig(cfg map[string]bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.config = cfg
+}
+
+// Enabled reports whether the named flag is on.
+func (f *Flags) Enabled(name string) bool {
+	return f.resolve(name).Enabled
+}
+
+// Toggle flips the flag for the rest of the session and returns the new value.
+func (f *Flags) Toggle(name string) bool {
+	enabled := !f.Enabled(name)
+	f.lock.Lock()
+	f.session[name] = enabled
+	f.lock.Unlock()
+	return enabled
+}
+
+// States returns the resolved state of every known flag.
+func (f *Flags) States() []State {
+	states := make([]State, 0, len(Known))
+	for _, flag := range Known {
+		states = append(states, f.resolve(flag.Name))
+	}
+	return states
+}
+
+// EnvVar returns the environment variable that controls a flag.
+func EnvVar(name string) string {
+	return "SKITZ_FEATURE_" + strings.ToUpper(name)
+}
+
+func (f *Flags) resolve(name string) State {
+	state := State{Flag: Flag{Name: name}, Source: SourceDefault}
+	for _, flag := range Known {
+		if flag.Name == name {
+			state.Flag = flag
+			state.Enabled = flag.Default
+			break
+		}
+	}
+
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if v, ok := f.session[name]; ok {
+		state.Enabled = v
+		state.Source = SourceSession
+		return state
+	}
+	if raw, ok := f.lookup(EnvVar(name)); ok {
+		if v, err := strconv.ParseBool(raw); err == nil {
+			state.Enabled = v
+			state.Source = SourceEnv
+			return state
+		}
+	}
+	if v, ok := f.config[name]; ok {
+		state.Enabled = v
+		state.Source = SourceConfig
+	}
+	return state
+}
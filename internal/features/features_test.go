@@ -0,0 +1,73 @@
+package features
+
+import (
+	"testing"
+)
+
+// testFlag is a flag known for the duration of a test
+const testFlag = "test_flag"
+
+func TestResolve(t *testing.T) {
+	defer func(known []Flag) { Known = known }(Known)
+	Known = []Flag{{Name: testFlag, Description: "Test"}}
+
+	tests := []struct {
+		name       string
+		config     map[string]bool
+		env        map[string]string
+		toggle     bool
+		wantOn     bool
+		wantSource Source
+	}{
+		{
+			name:       "default",
+			wantOn:     false,
+			wantSource: SourceDefault,
+		},
+		{
+			name:       "config",
+			config:     map[string]bool{testFlag: true},
+			wantOn:     true,
+			wantSource: SourceConfig,
+		},
+		{
+			name:       "env overrides config",
+			config:     map[string]bool{testFlag: true},
+			env:        map[string]string{"SKITZ_FEATURE_TEST_FLAG": "0"},
+			wantOn:     false,
+			wantSource: SourceEnv,
+		},
+		{
+			name:       "invalid env ignored",
+			config:     map[string]bool{testFlag: true},
+			env:        map[string]string{"SKITZ_FEATURE_TEST_FLAG": "maybe"},
+			wantOn:     true,
+			wantSource: SourceConfig,
+		},
+		{
+			name:       "session overrides env",
+			env:        map[string]string{"SKITZ_FEATURE_TEST_FLAG": "1"},
+			toggle:     true,
+			wantOn:     false,
+			wantSource: SourceSession,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(tt.config)
+			f.lookup = func(key string) (string, bool) {
+				v, ok := tt.env[key]
+				return v, ok
+			}
+			if tt.toggle {
+				f.Toggle(testFlag)
+			}
+
+			got := f.resolve(testFlag)
+			if got.Enabled != tt.wantOn || got.Source != tt.wantSource {
+				t.Errorf("resolve() = %v/%s, want %v/%s", got.Enabled, got.Source, tt.wantOn, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+// Package github lists pull requests and issues from the GitHub API for the
+// repository skitz is run in.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the GitHub REST API used unless one is configured.
+const DefaultAPIURL = "https://api.github.com"
+
+// Sentinel errors.
+var (
+	ErrNoToken   = errors.New("no GitHub token: set integrations.github.token, GITHUB_TOKEN, or run gh auth login")
+	ErrNotGitHub = errors.New("origin is not a GitHub repository")
+)
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// Repo is a GitHub repository.
+type Repo struct {
+	Owner string
+	Name  string
+}
+
+func (r Repo) String() string {
+	return r.Owner + "/" + r.Name
+}
+
+// Issue is an issue or pull request from the search API.
+type Issue struct {
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	URL       string    `json:"html_url"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Draft     bool      `json:"draft"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// IsPullRequest reports whether the issue is a pull request.
+func (i Issue) IsPullRequest() bool {
+	return i.PullRequest != nil
+}
+
+// Token returns configured, then $GITHUB_TOKEN or $GH_TOKEN, then the
+// token gh keeps in the system keychain.
+func Token(ctx context.Context, configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if t := os.Getenv(env); t != "" {
+			return t, nil
+		}
+	}
+	if _, err := exec.LookPath("gh"); err == nil {
+		out, err := exec.CommandContext(ctx, "gh", "auth", "token").Output()
+		if t := strings.TrimSpace(string(out)); err == nil && t != "" {
+			return t, nil
+		}
+	}
+	return "", ErrNoToken
+}
+
+var remoteRe = regexp.MustCompile(`^(?:https?://|ssh://)?(?:[^@/]+@)?github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseRemote returns the repository of a GitHub remote URL in HTTPS, SSH
+// or scp-like form.
+func ParseRemote(remote string) (Repo, error) {
+	m := remoteRe.FindStringSubmatch(strings.TrimSpace(remote))
+	if m == nil {
+		return Repo{}, fmt.Errorf("%w: %s", ErrNotGitHub, remote)
+	}
+	return Repo{Owner: m[1], Name: m[2]}, nil
+}
+
+// ParseRepo returns the repository named by s, either "owner/name" or a
+// GitHub URL.
+func ParseRepo(s string) (Repo, error) {
+	s = strings.TrimSpace(s)
+	owner, name, ok := strings.Cut(s, "/")
+	if ok && owner != "" && name != "" && !strings.ContainsAny(owner, ":@") && !strings.Contains(name, "/") {
+		return Repo{Owner: owner, Name: strings.TrimSuffix(name, ".git")}, nil
+	}
+	return ParseRemote(s)
+}
+
+// CurrentRepo returns the GitHub repository of the origin remote in the
+// working directory.
+func CurrentRepo(ctx context.Context) (Repo, error) {
+	out, err := exec.CommandContext(ctx, "git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return Repo{}, fmt.Errorf("failed to read the origin remote: %w", err)
+	}
+	return ParseRemote(string(out))
+}
+
+// Client calls the GitHub REST API.
+type Client struct {
+	Token   string
+	BaseURL string // defaults to DefaultAPIURL
+}
+
+// MyPullRequests returns the open pull requests the user opened in repo.
+func (c Client) MyPullRequests(ctx context.Context, repo Repo) ([]Issue, error) {
+	return c.Search(ctx, fmt.Sprintf("repo:%s is:pr is:open author:@me", repo))
+}
+
+// AssignedIssues returns the open issues in repo assigned to the user.
+func (c Client) AssignedIssues(ctx context.Context, repo Repo) ([]Issue, error) {
+	return c.Search(ctx, fmt.Sprintf("repo:%s is:issue is:open assignee:@me", repo))
+}
+
+// Search runs an issue search query, most recently updated first.
+func (c Client) Search(ctx context.Context, query string) ([]Issue, error) {
+	q := url.Values{"q": {query}, "sort": {"updated"}, "per_page": {"30"}}
+	body, err := c.get(ctx, "/search/issues?"+q.Encode(), "application/vnd.github+json")
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Items []Issue `json:"items"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+	return result.Items, nil
+}
+
+var pullRequestURLRe = regexp.MustCompile(`^https?://[^/]+/([^/]+)/([^/]+)/pull/(\d+)(?:[/?#].*)?$`)
+
+// ParsePullRequestURL returns the repository and number of a pull request
+// URL such as https://github.com/htelsiz/skitz/pull/12.
+func ParsePullRequestURL(u string) (Repo, int, error) {
+	m := pullRequestURLRe.FindStringSubmatch(strings.TrimSpace(u))
+	if m == nil {
+		return Repo{}, 0, fmt.Errorf("not a pull request URL: %s", u)
+	}
+	number, _ := strconv.Atoi(m[3])
+	return Repo{Owner: m[1], Name: m[2]}, number, nil
+}
+
+// PullRequestDiff returns the unified diff of a pull request.
+func (c Client) PullRequestDiff(ctx context.Context, repo Repo, number int) (string, error) {
+	body, err := c.get(ctx, fmt.Sprintf("/repos/%s/pulls/%d", repo, number), "application/vnd.github.diff")
+	return string(body), err
+}
+
+// Readme returns the README of repo's default branch as markdown.
+func (c Client) Readme(ctx context.Context, repo Repo) (string, error) {
+	body, err := c.get(ctx, fmt.Sprintf("/repos/%s/readme", repo), "application/vnd.github.raw")
+	return string(body), err
+}
+
+// Gist is a gist to create: its description and file contents by name.
+type Gist struct {
+	Description string
+	Public      bool
+	Files       map[string]string
+}
+
+// CreateGist creates gist and returns its URL.
+func (c Client) CreateGist(ctx context.Context, gist Gist) (string, error) {
+	files := make(map[string]map[string]string, len(gist.Files))
+	for name, content := range gist.Files {
+		files[name] = map[string]string{"content": content}
+	}
+	data, err := json.Marshal(map[string]any{
+		"description": gist.Description,
+		"public":      gist.Public,
+		"files":       files,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the gist: %w", err)
+	}
+	body, err := c.do(ctx, http.MethodPost, "/gists", "application/vnd.github+json", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		URL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return "", fmt.Errorf("failed to decode the gist: %w", err)
+	}
+	return created.URL, nil
+}
+
+// get requests path from the API and returns the body of a successful
+// response in the accept media type.
+func (c Client) get(ctx context.Context, path, accept string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, path, accept, nil)
+}
+
+// do sends a request with a JSON payload, if any, to path and returns the
+// body of a successful response in the accept media type.
+func (c Client) do(ctx context.Context, method, path, accept string, payload io.Reader) ([]byte, error) {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(base, "/")+path, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(body, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(body))
+		}
+		return nil, fmt.Errorf("GitHub returned %s: %s", resp.Status, apiErr.Message)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response: %w", err)
+	}
+	return body, nil
+}
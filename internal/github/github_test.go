@@ -0,0 +1,199 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		remote  string
+		want    string
+		wantErr bool
+	}{
+		{"git@github.com:htelsiz/skitz.git", "htelsiz/skitz", false},
+		{"https://github.com/htelsiz/skitz.git\n", "htelsiz/skitz", false},
+		{"https://github.com/htelsiz/skitz", "htelsiz/skitz", false},
+		{"ssh://git@github.com/htelsiz/skitz.git", "htelsiz/skitz", false},
+		{"https://gitlab.com/htelsiz/skitz.git", "", true},
+		{"git@github.com:htelsiz", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			repo, err := ParseRemote(tt.remote)
+			if tt.wantErr {
+				if !errors.Is(err, ErrNotGitHub) {
+					t.Errorf("err = %v, want ErrNotGitHub", err)
+				}
+				return
+			}
+			if err != nil || repo.String() != tt.want {
+				t.Errorf("ParseRemote() = %v, %v; want %s", repo, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "from-env")
+	if got, _ := Token(context.Background(), "from-config"); got != "from-config" {
+		t.Errorf("Token() = %q, want the configured token", got)
+	}
+	if got, _ := Token(context.Background(), ""); got != "from-env" {
+		t.Errorf("Token() = %q, want the env token", got)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	var gotQuery, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery, gotAuth = r.URL.Query().Get("q"), r.Header.Get("Authorization")
+		if r.URL.Path != "/search/issues" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"items":[
+			{"number":12,"title":"Fix login","html_url":"https://github.com/o/r/pull/12","pull_request":{}},
+			{"number":7,"title":"Crash on start","html_url":"https://github.com/o/r/issues/7"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Token: "t0k", BaseURL: srv.URL}
+	items, err := c.MyPullRequests(context.Background(), Repo{Owner: "o", Name: "r"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery != "repo:o/r is:pr is:open author:@me" || gotAuth != "Bearer t0k" {
+		t.Errorf("query = %q, auth = %q", gotQuery, gotAuth)
+	}
+	if len(items) != 2 || !items[0].IsPullRequest() || items[1].IsPullRequest() || items[0].Title != "Fix login" {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"Bad credentials"}`))
+	}))
+	defer srv.Close()
+
+	_, err := Client{BaseURL: srv.URL}.AssignedIssues(context.Background(), Repo{Owner: "o", Name: "r"})
+	if err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("err = %v, want the API message", err)
+	}
+}
+
+func TestParsePullRequestURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		repo    Repo
+		number  int
+		wantErr bool
+	}{
+		{url: "https://github.com/htelsiz/skitz/pull/12", repo: Repo{Owner: "htelsiz", Name: "skitz"}, number: 12},
+		{url: "https://github.com/o/r/pull/7/files#diff-1", repo: Repo{Owner: "o", Name: "r"}, number: 7},
+		{url: " https://ghe.example.com/o/r/pull/3 ", repo: Repo{Owner: "o", Name: "r"}, number: 3},
+		{url: "https://github.com/o/r/issues/7", wantErr: true},
+		{url: "o/r#7", wantErr: true},
+	}
+	for _, tt := range tests {
+		repo, number, err := ParsePullRequestURL(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePullRequestURL(%q) err = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if repo != tt.repo || number != tt.number {
+			t.Errorf("ParsePullRequestURL(%q) = %v, %d, want %v, %d", tt.url, repo, number, tt.repo, tt.number)
+		}
+	}
+}
+
+func TestPullRequestDiff(t *testing.T) {
+	var gotPath, gotAccept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAccept = r.URL.Path, r.Header.Get("Accept")
+		w.Write([]byte("diff --git a/x b/x\n"))
+	}))
+	defer srv.Close()
+
+	diff, err := Client{BaseURL: srv.URL}.PullRequestDiff(context.Background(), Repo{Owner: "o", Name: "r"}, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/repos/o/r/pulls/12" || gotAccept != "application/vnd.github.diff" {
+		t.Errorf("path = %q, accept = %q", gotPath, gotAccept)
+	}
+	if diff != "diff --git a/x b/x\n" {
+		t.Errorf("diff = %q", diff)
+	}
+}
+
+func TestParseRepo(t *testing.T) {
+	for _, s := range []string{"cli/cli", "https://github.com/cli/cli", "git@github.com:cli/cli.git", " cli/cli.git\n"} {
+		if repo, err := ParseRepo(s); err != nil || repo.String() != "cli/cli" {
+			t.Errorf("ParseRepo(%q) = %v, %v; want cli/cli", s, repo, err)
+		}
+	}
+	for _, s := range []string{"cli", "/cli", "https://gitlab.com/cli/cli"} {
+		if _, err := ParseRepo(s); err == nil {
+			t.Errorf("ParseRepo(%q) succeeded", s)
+		}
+	}
+}
+
+func TestReadme(t *testing.T) {
+	var gotPath, gotAccept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAccept = r.URL.Path, r.Header.Get("Accept")
+		w.Write([]byte("# cli\n"))
+	}))
+	defer srv.Close()
+
+	readme, err := Client{BaseURL: srv.URL}.Readme(context.Background(), Repo{Owner: "cli", Name: "cli"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/repos/cli/cli/readme" || gotAccept != "application/vnd.github.raw" || readme != "# cli\n" {
+		t.Errorf("path = %q, accept = %q, readme = %q", gotPath, gotAccept, readme)
+	}
+}
+
+func TestCreateGist(t *testing.T) {
+	var got struct {
+		Description string                       `json:"description"`
+		Public      bool                         `json:"public"`
+		Files       map[string]map[string]string `json:"files"`
+	}
+	var gotMethod, gotPath, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotAuth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url": "https://gist.github.com/octocat/aa5a315d"}`))
+	}))
+	defer srv.Close()
+
+	url, err := Client{Token: "t0ken", BaseURL: srv.URL}.CreateGist(context.Background(), Gist{
+		Description: "Agent run",
+		Files:       map[string]string{"run.md": "# Run\n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://gist.github.com/octocat/aa5a315d" {
+		t.Errorf("url = %q", url)
+	}
+	if gotMethod != http.MethodPost || gotPath != "/gists" || gotAuth != "Bearer t0ken" {
+		t.Errorf("request = %s %s, authorization %q", gotMethod, gotPath, gotAuth)
+	}
+	if got.Description != "Agent run" || got.Public || got.Files["run.md"]["content"] != "# Run\n" {
+		t.Errorf("gist = %+v", got)
+	}
+}
@@ -0,0 +1,195 @@
+// Package hooks runs the user's pre-run and post-run hooks around commands.
+//
+// A hook is run with sh -c. It gets the command as an Event in JSON on
+// stdin and in SKITZ_* environment variables. Pre-run hooks may print
+// KEY=VALUE lines (optionally prefixed with export) to add variables to the
+// command's environment; a pre-run hook that fails stops the command.
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// DefaultTimeout bounds a hook without its own timeout.
+const DefaultTimeout = 30 * time.Second
+
+// Hook stages, passed to hooks as SKITZ_HOOK.
+const (
+	PreRun  = "pre_run"
+	PostRun = "post_run"
+)
+
+// ErrPreRunFailed is returned when a pre-run hook stops a command.
+var ErrPreRunFailed = errors.New("pre-run hook failed")
+
+// Event describes the command a hook runs around. ExitCode and Duration are
+// only set for post-run hooks; ExitCode is -1 when the command could not
+// be started.
+type Event struct {
+	Stage    string        `json:"stage"`
+	Command  string        `json:"command"`
+	Resource string        `json:"resource,omitempty"`
+	Mode     string        `json:"mode"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration_ns,omitempty"`
+}
+
+// env returns the event as SKITZ_* variables.
+func (e Event) env() []string {
+	env := []string{
+		"SKITZ_HOOK=" + e.Stage,
+		"SKITZ_COMMAND=" + e.Command,
+		"SKITZ_RESOURCE=" + e.Resource,
+		"SKITZ_MODE=" + e.Mode,
+	}
+	if e.Stage == PostRun {
+		env = append(env,
+			"SKITZ_EXIT_CODE="+strconv.Itoa(e.ExitCode),
+			"SKITZ_DURATION_MS="+strconv.FormatInt(e.Duration.Milliseconds(), 10),
+		)
+	}
+	return env
+}
+
+// ExitCode returns the exit status for a command's error: 0 for nil, the
+// process's code for an exit error, and -1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// Matching returns the hooks that apply to ev. Hooks with an invalid
+// Match never apply.
+func Matching(hooks []config.Hook, ev Event) []config.Hook {
+	var matched []config.Hook
+	for _, h := range hooks {
+		if strings.TrimSpace(h.Run) == "" {
+			continue
+		}
+		if len(h.Resources) > 0 && !slices.Contains(h.Resources, ev.Resource) {
+			continue
+		}
+		if h.Match != "" {
+			re, err := regexp.Compile(h.Match)
+			if err != nil || !re.MatchString(ev.Command) {
+				continue
+			}
+		}
+		if ev.Stage == PostRun && h.OnFailure && ev.ExitCode == 0 {
+			continue
+		}
+		matched = append(matched, h)
+	}
+	return matched
+}
+
+// RunPre runs the pre-run hooks that match ev in order and returns the
+// variables they exported. It stops at the first hook that fails.
+func RunPre(ctx context.Context, hooks []config.Hook, ev Event) ([]string, error) {
+	ev.Stage = PreRun
+	var env []string
+	for _, h := range Matching(hooks, ev) {
+		out, err := run(ctx, h, ev, env)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrPreRunFailed, name(h), err)
+		}
+		env = append(env, parseEnv(out)...)
+	}
+	return env, nil
+}
+
+// RunPost runs every post-run hook that matches ev and returns the errors
+// of those that failed.
+func RunPost(ctx context.Context, hooks []config.Hook, ev Event) []error {
+	ev.Stage = PostRun
+	var errs []error
+	for _, h := range Matching(hooks, ev) {
+		if _, err := run(ctx, h, ev, nil); err != nil {
+			errs = append(errs, fmt.Errorf("post-run hook %s failed: %w", name(h), err))
+		}
+	}
+	return errs
+}
+
+// name returns the hook's name, or its command when it has none.
+func name(h config.Hook) string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return strings.Fields(h.Run)[0]
+}
+
+// run executes one hook with the event on stdin and returns its stdout.
+// env holds variables exported by earlier pre-run hooks.
+func run(ctx context.Context, h config.Hook, ev Event, env []string) ([]byte, error) {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	in, err := json.Marshal(ev)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode hook event: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", h.Run)
+	cmd.Env = append(append(os.Environ(), env...), ev.env()...)
+	cmd.Stdin = bytes.NewReader(append(in, '\n'))
+	// Don't wait on children that keep stdout open after a timeout
+	cmd.WaitDelay = time.Second
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+var envLine = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+
+// parseEnv returns the KEY=VALUE lines in a pre-run hook's output. Values
+// may be quoted; other lines are ignored.
+func parseEnv(out []byte) []string {
+	var env []string
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		m := envLine.FindStringSubmatch(strings.TrimSpace(sc.Text()))
+		if m == nil {
+			continue
+		}
+		value := m[2]
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		env = append(env, m[1]+"="+value)
+	}
+	return env
+}
@@ -0,0 +1,118 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestMatching(t *testing.T) {
+	hooks := []config.Hook{
+		{Name: "all", Run: "true"},
+		{Name: "az", Run: "true", Match: `^az\b`},
+		{Name: "docker", Run: "true", Resources: []string{"docker"}},
+		{Name: "failures", Run: "true", OnFailure: true},
+		{Name: "bad regexp", Run: "true", Match: "("},
+		{Name: "empty"},
+	}
+
+	tests := []struct {
+		name string
+		ev   Event
+		want []string
+	}{
+		{"pre-run az", Event{Stage: PreRun, Command: "az vm list", Resource: "azure"}, []string{"all", "az", "failures"}},
+		{"pre-run docker", Event{Stage: PreRun, Command: "docker ps", Resource: "docker"}, []string{"all", "docker", "failures"}},
+		{"post-run success", Event{Stage: PostRun, Command: "ls"}, []string{"all"}},
+		{"post-run failure", Event{Stage: PostRun, Command: "ls", ExitCode: 2}, []string{"all", "failures"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, h := range Matching(hooks, tt.ev) {
+				got = append(got, h.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPre(t *testing.T) {
+	hooks := []config.Hook{
+		{Run: `echo "export TOKEN='s3cret'"; echo "noise"; echo 'REGION="west us"'`},
+		{Run: `test "$TOKEN" = s3cret && echo "SEEN=$SKITZ_COMMAND"`},
+	}
+	env, err := RunPre(context.Background(), hooks, Event{Command: "az group list", Mode: "embedded"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"TOKEN=s3cret", "REGION=west us", "SEEN=az group list"}
+	if !slices.Equal(env, want) {
+		t.Errorf("env = %q, want %q", env, want)
+	}
+}
+
+func TestRunPreFailure(t *testing.T) {
+	hooks := []config.Hook{
+		{Name: "login", Run: "echo 'not logged in' >&2; exit 1"},
+		{Run: "echo NEVER=1"},
+	}
+	env, err := RunPre(context.Background(), hooks, Event{Command: "az vm list"})
+	if !errors.Is(err, ErrPreRunFailed) || !strings.Contains(err.Error(), "not logged in") {
+		t.Errorf("err = %v, want ErrPreRunFailed with stderr", err)
+	}
+	if env != nil {
+		t.Errorf("env = %q, want none", env)
+	}
+}
+
+func TestRunPost(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "audit")
+	hooks := []config.Hook{
+		{Run: `echo "$SKITZ_HOOK $SKITZ_RESOURCE $SKITZ_EXIT_CODE $SKITZ_DURATION_MS" > ` + out + `; cat >> ` + out},
+		{Name: "slow", Run: "sleep 5", Timeout: 50 * time.Millisecond},
+	}
+	errs := RunPost(context.Background(), hooks, Event{Command: "make", Resource: "go", ExitCode: 2, Duration: 1500 * time.Millisecond})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "slow") {
+		t.Errorf("errs = %v, want the slow hook to time out", errs)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitN(string(data), "\n", 2)
+	if lines[0] != "post_run go 2 1500" {
+		t.Errorf("env line = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], `"command":"make"`) || !strings.Contains(lines[1], `"exit_code":2`) {
+		t.Errorf("stdin = %q", lines[1])
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	err := exec.Command("/bin/sh", "-c", "exit 3").Run()
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{err, 3},
+		{errors.New("no pty"), -1},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
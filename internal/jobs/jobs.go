@@ -0,0 +1,334 @@
+// Package jobs runs commands detached from skitz, so that they keep
+// running after it exits and can be listed, followed and cleaned up from
+// a later session.
+//
+// A job is a directory in Dir holding job.json, which describes it,
+// output.log with everything the command printed, and, once the command
+// finished, exit with its exit code. The command runs in its own session
+// under /bin/sh, which writes the exit file. When the job has values to
+// redact its output goes through skitz itself, re-run as a filter (see
+// Filter), so that they never reach output.log.
+//
+// Along with the PID a job records when its process started, since the PID
+// may belong to an unrelated process once the job is gone: a job is only
+// running, and only signalled, while both still match.
+package jobs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+	"github.com/htelsiz/skitz/internal/secrets"
+)
+
+const (
+	infoFile   = "job.json"
+	outputFile = "output.log"
+	exitFile   = "exit"
+)
+
+// supervisor runs the command given as its arguments and records its exit
+// code in $SKITZ_JOB_EXIT
+const supervisor = `"$@"; echo $? > "$SKITZ_JOB_EXIT"`
+
+// filteredSupervisor is supervisor with the command's output piped through
+// the filter $SKITZ_JOB_FILTER, which alone sees $SKITZ_JOB_REDACT. The exit
+// code comes back on fd 3 and is only recorded once the filter wrote all of
+// the output (to fd 4, the output file).
+const filteredSupervisor = `exec 4>&1
+code=$({ { unset SKITZ_JOB_REDACT; "$@" 3>&- 4>&-; echo $? >&3; } 2>&1 | "$SKITZ_JOB_FILTER" 3>&- >&4; } 3>&1)
+echo "$code" > "$SKITZ_JOB_EXIT"`
+
+// redactEnv holds the JSON list of values a filter redacts
+const redactEnv = "SKITZ_JOB_REDACT"
+
+// Job is a detached command.
+type Job struct {
+	ID       string    `json:"id"`
+	Command  string    `json:"command"`
+	Resource string    `json:"resource,omitempty"`
+	Shell    string    `json:"shell,omitempty"`
+	PID      int       `json:"pid"`
+	Started  time.Time `json:"started"`
+
+	// ProcStart is when the process at PID started, see processStart
+	ProcStart string `json:"proc_start,omitempty"`
+
+	// ExitCode is set once the command finished, at Finished
+	ExitCode *int      `json:"-"`
+	Finished time.Time `json:"-"`
+
+	// Redact lists values, such as secrets, replaced in the output
+	Redact []string `json:"-"`
+
+	dir string
+}
+
+// Dir returns the directory jobs are kept in.
+func Dir(dataDir string) string {
+	return filepath.Join(dataDir, "jobs")
+}
+
+// NewID returns a job ID for command started at t, e.g.
+// "20260102-150405-make-build".
+func NewID(command string, t time.Time) string {
+	slug := config.Slug(command, "job")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	return t.Format("20060102-150405") + "-" + slug
+}
+
+// Start runs name with args as job j in a new directory in dir, with env
+// added to the environment. j.ID is made unique if a job already has it.
+func Start(dir string, j Job, name string, args []string, env []string) (*Job, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+	id := j.ID
+	for i := 2; ; i++ {
+		err := os.Mkdir(filepath.Join(dir, id), 0700)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create job directory: %w", err)
+		}
+		id = j.ID + "-" + strconv.Itoa(i)
+	}
+	j.ID = id
+	j.dir = filepath.Join(dir, id)
+
+	out, err := os.OpenFile(j.OutputPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job output: %w", err)
+	}
+	defer out.Close()
+
+	script := supervisor
+	cmdEnv := append(os.Environ(), env...)
+	cmdEnv = append(cmdEnv, "SKITZ_JOB_EXIT="+filepath.Join(j.dir, exitFile))
+	if redact := slices.DeleteFunc(slices.Clone(j.Redact), func(v string) bool { return v == "" }); len(redact) > 0 {
+		self, err := os.Executable()
+		if err != nil {
+			os.RemoveAll(j.dir)
+			return nil, fmt.Errorf("failed to find the output filter: %w", err)
+		}
+		values, _ := json.Marshal(redact)
+		script = filteredSupervisor
+		cmdEnv = append(cmdEnv, "SKITZ_JOB_FILTER="+self, redactEnv+"="+string(values))
+	}
+
+	cmd := exec.Command("/bin/sh", append([]string{"-c", script, "sh", name}, args...)...)
+	cmd.Env = cmdEnv
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// A session of its own keeps the job out of skitz's terminal, which
+	// hangs up its processes when it closes
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(j.dir)
+		return nil, fmt.Errorf("failed to start job: %w", err)
+	}
+	j.PID = cmd.Process.Pid
+	// Read before the supervisor can be reaped, when it would be gone
+	j.ProcStart = processStart(j.PID)
+	// Reap the supervisor if it exits while skitz is still running
+	go cmd.Wait()
+
+	if j.Started.IsZero() {
+		j.Started = time.Now()
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(j.dir, infoFile), data, 0600)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write job: %w", err)
+	}
+	return &j, nil
+}
+
+// Filter copies its input to its output with the values in
+// $SKITZ_JOB_REDACT redacted, a line at a time, and reports whether it did:
+// main calls it first, so that a job can run skitz as its output filter.
+func Filter() bool {
+	data, ok := os.LookupEnv(redactEnv)
+	if !ok {
+		return false
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(data), &values); err != nil {
+		fmt.Fprintf(os.Stderr, "skitz: invalid %s: %v\n", redactEnv, err)
+		os.Exit(1)
+	}
+	// Stopping a job signals the filter too: keep going until the
+	// command's output ends, so that none of it is lost
+	signal.Ignore(syscall.SIGTERM)
+	if err := filter(os.Stdin, os.Stdout, values); err != nil {
+		fmt.Fprintf(os.Stderr, "skitz: %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+// filter copies r to w a line at a time, redacting values
+func filter(r io.Reader, w io.Writer, values []string) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if _, werr := io.WriteString(w, secrets.Redact(line, values)); werr != nil {
+			return werr
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Load reads the job in the directory path.
+func Load(path string) (*Job, error) {
+	data, err := os.ReadFile(filepath.Join(path, infoFile))
+	if err != nil {
+		return nil, err
+	}
+	var j Job
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("invalid job: %w", err)
+	}
+	j.dir = path
+	j.Refresh()
+	return &j, nil
+}
+
+// Refresh reads the exit code, if the command finished since the job was
+// loaded.
+func (j *Job) Refresh() {
+	if j.ExitCode != nil {
+		return
+	}
+	path := filepath.Join(j.dir, exitFile)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return
+	}
+	j.ExitCode = &code
+	if info, err := os.Stat(path); err == nil {
+		j.Finished = info.ModTime()
+	}
+}
+
+// Running reports whether the command is still running. A job without an
+// exit code whose process is gone, or whose PID now belongs to another
+// process, was stopped or killed. Jobs without a recorded start time
+// can't be told apart from such a process and are never running.
+func (j *Job) Running() bool {
+	if j.ExitCode != nil || j.PID <= 0 || j.ProcStart == "" {
+		return false
+	}
+	return processStart(j.PID) == j.ProcStart
+}
+
+// processStart returns when the process pid started, or "" if there is no
+// such process. On Linux this is the start time in clock ticks since boot
+// from /proc, elsewhere the time ps reports.
+func processStart(pid int) string {
+	if runtime.GOOS == "linux" {
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			return ""
+		}
+		// The command name in parentheses may hold spaces, so fields are
+		// counted from after it: starttime is field 22, state field 3
+		stat := string(data)
+		fields := strings.Fields(stat[strings.LastIndexByte(stat, ')')+1:])
+		if len(fields) < 20 {
+			return ""
+		}
+		return fields[19]
+	}
+	out, err := exec.Command("ps", "-o", "lstart=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// OutputPath returns the file the command's output is written to.
+func (j *Job) OutputPath() string {
+	return filepath.Join(j.dir, outputFile)
+}
+
+// Stop sends SIGTERM to the job's processes, if the job is still running.
+func (j *Job) Stop() error {
+	if !j.Running() {
+		return nil
+	}
+	if err := syscall.Kill(-j.PID, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return fmt.Errorf("failed to stop job: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes a job that is no longer running, with its output.
+func (j *Job) Remove() error {
+	if j.Running() {
+		return errors.New("the job is still running")
+	}
+	if err := os.RemoveAll(j.dir); err != nil {
+		return fmt.Errorf("failed to remove job: %w", err)
+	}
+	return nil
+}
+
+// List loads every job in dir, newest first. Directories that can't be
+// read are skipped.
+func List(dir string) ([]*Job, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jobs directory: %w", err)
+	}
+
+	var list []*Job
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		j, err := Load(filepath.Join(dir, e.Name()))
+		if err != nil {
+			slog.Warn("skipping job", "dir", e.Name(), "error", err)
+			continue
+		}
+		list = append(list, j)
+	}
+	sort.Slice(list, func(i, k int) bool {
+		return list[i].Started.After(list[k].Started)
+	})
+	return list, nil
+}
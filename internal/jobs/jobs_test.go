@@ -0,0 +1,150 @@
+package jobs
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestMain lets jobs run the test binary as their output filter, as they
+// run skitz
+func TestMain(m *testing.M) {
+	if Filter() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// wait polls until j stops running
+func wait(t *testing.T, j *Job) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for j.Refresh(); j.Running(); j.Refresh() {
+		if time.Now().After(deadline) {
+			t.Fatal("job still running")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestJob(t *testing.T) {
+	dir := t.TempDir()
+	started := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	j, err := Start(dir, Job{ID: NewID("make build", started), Command: "make build", Started: started},
+		"/bin/sh", []string{"-c", `echo "built $NAME"; exit 3`}, []string{"NAME=skitz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.ID != "20260102-150405-make-build" || j.PID == 0 {
+		t.Errorf("job = %+v", j)
+	}
+	wait(t, j)
+
+	list, err := List(dir)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("List = %v, %v", list, err)
+	}
+	got := list[0]
+	if got.Command != "make build" || got.ExitCode == nil || *got.ExitCode != 3 || got.Running() {
+		t.Errorf("listed job = %+v", got)
+	}
+	if out, _ := os.ReadFile(got.OutputPath()); string(out) != "built skitz\n" {
+		t.Errorf("output = %q", out)
+	}
+
+	// A second job with the same ID gets a new one
+	again, err := Start(dir, Job{ID: j.ID}, "/bin/sh", []string{"-c", "true"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != j.ID+"-2" {
+		t.Errorf("ID = %q, want %q", again.ID, j.ID+"-2")
+	}
+	wait(t, again)
+
+	if err := got.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := List(dir); len(list) != 1 {
+		t.Errorf("%d jobs after removing one, want 1", len(list))
+	}
+}
+
+func TestRedact(t *testing.T) {
+	j, err := Start(t.TempDir(), Job{ID: "deploy", Redact: []string{"s3cr3t", ""}},
+		"/bin/sh", []string{"-c", `echo "token $TOKEN"; printf 'redact? %s' "$SKITZ_JOB_REDACT" >&2; exit 2`},
+		[]string{"TOKEN=s3cr3t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wait(t, j)
+
+	if j.ExitCode == nil || *j.ExitCode != 2 {
+		t.Errorf("exit code = %v, want 2", j.ExitCode)
+	}
+	out, _ := os.ReadFile(j.OutputPath())
+	if string(out) != "token ****\nredact? " {
+		t.Errorf("output = %q", out)
+	}
+
+	var b strings.Builder
+	if err := filter(strings.NewReader("a s3cr3t\nno newline s3cr3t"), &b, []string{"s3cr3t"}); err != nil || b.String() != "a ****\nno newline ****" {
+		t.Errorf("filter() = %q, %v", b.String(), err)
+	}
+}
+
+func TestStop(t *testing.T) {
+	j, err := Start(t.TempDir(), Job{ID: "sleep"}, "/bin/sh", []string{"-c", "sleep 30"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !j.Running() {
+		t.Fatal("job not running")
+	}
+	if err := j.Remove(); err == nil {
+		t.Error("removed a running job")
+	}
+	if err := j.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	wait(t, j)
+	if j.ExitCode != nil {
+		t.Errorf("stopped job has exit code %d", *j.ExitCode)
+	}
+}
+
+func TestReusedPID(t *testing.T) {
+	// A process that took over the PID of a finished job
+	other := exec.Command("sleep", "30")
+	other.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := other.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer other.Process.Kill()
+	go other.Wait()
+
+	pid := other.Process.Pid
+	if processStart(pid) == "" {
+		t.Fatal("no start time for a running process")
+	}
+	j := &Job{ID: "old", PID: pid, ProcStart: "1", dir: t.TempDir()}
+	if j.Running() {
+		t.Error("job running in another process")
+	}
+	if err := j.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(pid, 0); err != nil {
+		t.Errorf("stopping the job signalled another process: %v", err)
+	}
+
+	// A job from before start times were recorded is never running
+	j.ProcStart = ""
+	if j.Running() {
+		t.Error("job without a start time running")
+	}
+}
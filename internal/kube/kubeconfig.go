@@ -0,0 +1,178 @@
+// Package kube reads kubeconfig files and switches the current context and
+// namespace with kubectl.
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultNamespace is used by kubectl when a context sets no namespace.
+const DefaultNamespace = "default"
+
+// ErrNoConfig is returned when no kubeconfig file exists.
+var ErrNoConfig = errors.New("no kubeconfig found")
+
+// Context is a named kubeconfig context.
+type Context struct {
+	Name      string
+	Cluster   string
+	User      string
+	Namespace string
+}
+
+// Config is the part of a kubeconfig skitz needs.
+type Config struct {
+	CurrentContext string
+	Contexts       []Context
+}
+
+// rawConfig mirrors the kubeconfig YAML layout.
+type rawConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// Paths returns the kubeconfig files kubectl reads: the entries of
+// $KUBECONFIG, or ~/.kube/config.
+func Paths() []string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		var paths []string
+		for _, p := range filepath.SplitList(env) {
+			if p != "" {
+				paths = append(paths, p)
+			}
+		}
+		return paths
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(home, ".kube", "config")}
+}
+
+// Load reads and merges the kubeconfig files from Paths. As with kubectl,
+// the first file to set a value wins.
+func Load() (Config, error) {
+	var merged Config
+	found := false
+	for _, path := range Paths() {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read kubeconfig: %w", err)
+		}
+		cfg, err := Parse(data)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		found = true
+		if merged.CurrentContext == "" {
+			merged.CurrentContext = cfg.CurrentContext
+		}
+		for _, c := range cfg.Contexts {
+			if _, ok := merged.Context(c.Name); !ok {
+				merged.Contexts = append(merged.Contexts, c)
+			}
+		}
+	}
+	if !found {
+		return Config{}, ErrNoConfig
+	}
+	return merged, nil
+}
+
+// Parse parses a single kubeconfig file.
+func Parse(data []byte) (Config, error) {
+	var raw rawConfig
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return Config{}, err
+	}
+	cfg := Config{CurrentContext: raw.CurrentContext}
+	for _, c := range raw.Contexts {
+		cfg.Contexts = append(cfg.Contexts, Context{
+			Name:      c.Name,
+			Cluster:   c.Context.Cluster,
+			User:      c.Context.User,
+			Namespace: c.Context.Namespace,
+		})
+	}
+	return cfg, nil
+}
+
+// Context returns the context called name.
+func (c Config) Context(name string) (Context, bool) {
+	for _, ctx := range c.Contexts {
+		if ctx.Name == name {
+			return ctx, true
+		}
+	}
+	return Context{}, false
+}
+
+// Current returns the current context and its namespace, which defaults
+// to DefaultNamespace.
+func (c Config) Current() (Context, bool) {
+	ctx, ok := c.Context(c.CurrentContext)
+	if !ok {
+		return Context{}, false
+	}
+	if ctx.Namespace == "" {
+		ctx.Namespace = DefaultNamespace
+	}
+	return ctx, true
+}
+
+// Namespaces returns the namespaces named by any context, with
+// DefaultNamespace first.
+func (c Config) Namespaces() []string {
+	namespaces := []string{DefaultNamespace}
+	for _, ctx := range c.Contexts {
+		if ctx.Namespace != "" && !slices.Contains(namespaces, ctx.Namespace) {
+			namespaces = append(namespaces, ctx.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// ClusterNamespaces lists the namespaces of the cluster behind kubeContext.
+func ClusterNamespaces(ctx context.Context, kubeContext string) ([]string, error) {
+	out, err := exec.CommandContext(ctx, "kubectl", "--context", kubeContext,
+		"get", "namespaces", "-o", "jsonpath={.items[*].metadata.name}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// Switch makes kubeContext current and sets its namespace.
+func Switch(ctx context.Context, kubeContext, namespace string) error {
+	if out, err := exec.CommandContext(ctx, "kubectl", "config", "use-context", kubeContext).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to switch context: %s", strings.TrimSpace(string(out)))
+	}
+	if namespace == "" {
+		return nil
+	}
+	if out, err := exec.CommandContext(ctx, "kubectl", "config", "set-context", "--current", "--namespace", namespace).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set namespace: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
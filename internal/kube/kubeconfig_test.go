@@ -0,0 +1,101 @@
+package kube
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const devConfig = `apiVersion: v1
+kind: Config
+current-context: dev
+contexts:
+- name: dev
+  context:
+    cluster: dev-cluster
+    user: dev-user
+    namespace: team-a
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: admin
+`
+
+const prodConfig = `current-context: prod
+contexts:
+- name: prod
+  context:
+    cluster: other
+    namespace: ignored
+- name: staging
+  context:
+    cluster: staging-cluster
+    namespace: team-b
+`
+
+func TestCurrent(t *testing.T) {
+	tests := []struct {
+		name          string
+		current       string
+		wantOK        bool
+		wantNamespace string
+	}{
+		{"explicit namespace", "dev", true, "team-a"},
+		{"default namespace", "prod", true, DefaultNamespace},
+		{"unknown context", "missing", false, ""},
+		{"no current context", "", false, ""},
+	}
+
+	cfg, err := Parse([]byte(devConfig))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.CurrentContext = tt.current
+			ctx, ok := cfg.Current()
+			if ok != tt.wantOK || ctx.Namespace != tt.wantNamespace {
+				t.Errorf("Current() = %+v, %v, want namespace %q, %v", ctx, ok, tt.wantNamespace, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLoadMergesKubeconfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	dev := filepath.Join(dir, "dev")
+	prod := filepath.Join(dir, "prod")
+	if err := os.WriteFile(dev, []byte(devConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(prod, []byte(prodConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", dev+string(os.PathListSeparator)+filepath.Join(dir, "missing")+string(os.PathListSeparator)+prod)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.CurrentContext != "dev" {
+		t.Errorf("CurrentContext = %q, want dev", cfg.CurrentContext)
+	}
+	if prodCtx, _ := cfg.Context("prod"); prodCtx.Cluster != "prod-cluster" {
+		t.Errorf("prod cluster = %q, want the first file's prod-cluster", prodCtx.Cluster)
+	}
+	if _, ok := cfg.Context("staging"); !ok {
+		t.Error("staging context from the second file is missing")
+	}
+	if got, want := cfg.Namespaces(), []string{DefaultNamespace, "team-a", "team-b"}; !slices.Equal(got, want) {
+		t.Errorf("Namespaces() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadNoConfig(t *testing.T) {
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	if _, err := Load(); !errors.Is(err, ErrNoConfig) {
+		t.Errorf("Load() error = %v, want ErrNoConfig", err)
+	}
+}
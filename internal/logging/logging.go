@@ -0,0 +1,217 @@
+// Package logging configures structured, leveled logging for skitz.
+//
+// Logs are written with log/slog to a size-rotated file under
+// ~/.local/share/skitz/logs so that nothing is printed over the TUI.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+const (
+	fileName = "skitz.log"
+
+	// DefaultMaxSize is the size in bytes at which the log file is rotated.
+	DefaultMaxSize int64 = 5 * 1024 * 1024
+
+	// DefaultMaxBackups is the number of rotated files kept alongside the active log.
+	DefaultMaxBackups = 3
+)
+
+// Options controls how logging is set up.
+type Options struct {
+	Debug      bool
+	Dir        string
+	MaxSize    int64
+	MaxBackups int
+}
+
+var (
+	lock    sync.Mutex
+	current *RotatingWriter
+	level   = new(slog.LevelVar)
+)
+
+// Dir returns the directory log files are written to.
+func Dir() string {
+	return filepath.Join(config.DataDir, "logs")
+}
+
+// Path returns the path of the active log file.
+func Path() string {
+	lock.Lock()
+	defer lock.Unlock()
+	if current != nil {
+		return current.path
+	}
+	return filepath.Join(Dir(), fileName)
+}
+
+// DebugEnabled reports whether debug logging is active.
+func DebugEnabled() bool {
+	return level.Level() <= slog.LevelDebug
+}
+
+// Setup installs a slog default logger writing to a rotated log file.
+// Output from the standard log package (including third-party libraries)
+// is routed into the same file at debug level.
+func Setup(opts Options) error {
+	if opts.Dir == "" {
+		opts.Dir = Dir()
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	if opts.MaxBackups <= 0 {
+		opts.MaxBackups = DefaultMaxBackups
+	}
+
+	w, err := NewRotatingWriter(filepath.Join(opts.Dir, fileName), opts.MaxSize, opts.MaxBackups)
+	if err != nil {
+		return err
+	}
+
+	if opts.Debug {
+		level.Set(slog.LevelDebug)
+	} else {
+		level.Set(slog.LevelInfo)
+	}
+
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})
+	slog.SetDefault(slog.New(handler))
+	log.SetFlags(0)
+	log.SetOutput(slog.NewLogLogger(handler, slog.LevelDebug).Writer())
+
+	lock.Lock()
+	prev := current
+	current = w
+	lock.Unlock()
+
+	if prev != nil {
+		prev.Close()
+	}
+	return nil
+}
+
+// Close flushes and closes the active log file.
+func Close() error {
+	lock.Lock()
+	w := current
+	current = nil
+	lock.Unlock()
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	log.SetOutput(io.Discard)
+
+	if w == nil {
+		return nil
+	}
+	return w.Close()
+}
+
+// RotatingWriter is an io.WriteCloser that rotates the underlying file
+// once it grows past maxSize, keeping at most maxBackups old files
+// named <path>.1 (newest) through <path>.N (oldest).
+type RotatingWriter struct {
+	lock       sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewRotatingWriter opens (or creates) path for appending.
+func NewRotatingWriter(path string, maxSize int64, maxBackups int) (*RotatingWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	w := &RotatingWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write implements io.Writer.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the underlying file.
+func (w *RotatingWriter) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *RotatingWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+	w.file = nil
+
+	os.Remove(w.backupPath(w.maxBackups))
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		os.Rename(w.backupPath(i), w.backupPath(i+1))
+	}
+	if err := os.Rename(w.path, w.backupPath(1)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	return w.open()
+}
+
+func (w *RotatingWriter) backupPath(n int) string {
+	return w.path + "." + strconv.Itoa(n)
+}
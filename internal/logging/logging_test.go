@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingWriter(t *testing.T) {
+	tests := []struct {
+		name        string
+		writes      []string
+		maxSize     int64
+		maxBackups  int
+		wantCurrent string
+		wantBackups []string
+	}{
+		{
+			name:        "no rotation under limit",
+			writes:      []string{"aaa\n", "bbb\n"},
+			maxSize:     64,
+			maxBackups:  2,
+			wantCurrent: "aaa\nbbb\n",
+		},
+		{
+			name:        "rotates when limit exceeded",
+			writes:      []string{"aaa\n", "bbb\n"},
+			maxSize:     6,
+			maxBackups:  2,
+			wantCurrent: "bbb\n",
+			wantBackups: []string{"aaa\n"},
+		},
+		{
+			name:        "drops oldest backup",
+			writes:      []string{"aaa\n", "bbb\n", "ccc\n", "ddd\n"},
+			maxSize:     4,
+			maxBackups:  2,
+			wantCurrent: "ddd\n",
+			wantBackups: []string{"ccc\n", "bbb\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "test.log")
+			w, err := NewRotatingWriter(path, tt.maxSize, tt.maxBackups)
+			if err != nil {
+				t.Fatalf("NewRotatingWriter() error = %v", err)
+			}
+			for _, s := range tt.writes {
+				if _, err := w.Write([]byte(s)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			assertFile(t, path, tt.wantCurrent)
+			for i, want := range tt.wantBackups {
+				assertFile(t, w.backupPath(i+1), want)
+			}
+			if _, err := os.Stat(w.backupPath(len(tt.wantBackups) + 1)); !os.IsNotExist(err) {
+				t.Errorf("unexpected backup %s", w.backupPath(len(tt.wantBackups)+1))
+			}
+		})
+	}
+}
+
+func assertFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) error = %v", path, err)
+	}
+	if got := string(data); got != want {
+		t.Errorf("%s = %q, want %q", filepath.Base(path), got, want)
+	}
+}
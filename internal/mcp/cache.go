@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ToolCache holds the last tool list fetched from each server, keyed by
+// server URL, so tools can still be listed offline.
+type ToolCache map[string][]mcp.Tool
+
+// ToolCachePath returns the tool cache file in dataDir.
+func ToolCachePath(dataDir string) string {
+	return filepath.Join(dataDir, "mcp-tools.json")
+}
+
+// LoadToolCache reads the cache at path. A missing or unreadable cache is
+// empty.
+func LoadToolCache(path string) ToolCache {
+	cache := make(ToolCache)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return make(ToolCache)
+	}
+	return cache
+}
+
+// Save writes the cache to path.
+func (c ToolCache) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode tool cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write tool cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestToolCacheRoundTrip(t *testing.T) {
+	path := ToolCachePath(t.TempDir())
+	if got := LoadToolCache(path); len(got) != 0 {
+		t.Fatalf("LoadToolCache() of a missing file = %v", got)
+	}
+
+	tool := mcp.NewTool("search", mcp.WithDescription("Search docs"), mcp.WithString("query", mcp.Required()))
+	if err := (ToolCache{"http://localhost:8001/mcp/": {tool}}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got := LoadToolCache(path)["http://localhost:8001/mcp/"]
+	if len(got) != 1 || got[0].Name != "search" || got[0].Description != "Search docs" {
+		t.Fatalf("LoadToolCache() = %+v", got)
+	}
+	if _, ok := got[0].InputSchema.Properties["query"]; !ok || len(got[0].InputSchema.Required) != 1 {
+		t.Errorf("input schema = %+v", got[0].InputSchema)
+	}
+}
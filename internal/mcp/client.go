@@ -9,6 +9,8 @@ import (
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/htelsiz/skitz/internal/metrics"
 )
 
 // ServerStatus holds the status of a connected MCP server.
@@ -26,8 +28,20 @@ type ServerStatus struct {
 	ResourcesError         string
 	ResourceTemplatesError string
 	LastUpdated            time.Time
+
+	// ErrorKind says whether Error is a connection or protocol failure.
+	ErrorKind ErrorKind
+	// Degraded is set while a server that was connected fails fewer than
+	// DegradedAfter refreshes in a row; the last known lists are kept.
+	Degraded bool
+	// Failures counts consecutive failed refreshes.
+	Failures int
 }
 
+// DegradedAfter is how many consecutive failed refreshes a connected server
+// is reported degraded before it is reported disconnected.
+const DegradedAfter = 3
+
 // Client wraps the mcp-go client for an MCP server.
 type Client struct {
 	client    *client.Client
@@ -83,20 +97,29 @@ func NewClient(serverURL string) (*Client, error) {
 	}, nil
 }
 
-// Connect initializes the MCP connection.
+// Connect initializes the MCP connection, retrying transient network
+// errors with the current retry policy.
 func (m *Client) Connect(ctx context.Context) error {
 	if m.connected {
 		return nil
 	}
 
-	if err := m.client.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start MCP client: %w", err)
-	}
-
-	_, err := m.client.Initialize(ctx, buildInitializeRequest())
+	err := currentRetryPolicy().do(ctx, isConnectionError, func() error {
+		if err := m.client.Start(ctx); err != nil {
+			return fmt.Errorf("failed to start MCP client: %w", err)
+		}
+		if _, err := m.client.Initialize(ctx, buildInitializeRequest()); err != nil {
+			m.client.Close()
+			// A closed client cannot be started again
+			if c, newErr := client.NewStreamableHttpClient(m.serverURL); newErr == nil {
+				m.client = c
+			}
+			return fmt.Errorf("failed to initialize MCP client: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		m.client.Close()
-		return fmt.Errorf("failed to initialize MCP client: %w", err)
+		return err
 	}
 
 	m.connected = true
@@ -144,12 +167,21 @@ func (m *Client) CallTool(ctx context.Context, name string, args map[string]any)
 		},
 	}
 
-	result, err := m.client.CallTool(ctx, request)
+	// Tool calls are not idempotent, so only retry requests that were
+	// never sent
+	var result *mcp.CallToolResult
+	err := currentRetryPolicy().do(ctx, isDialError, func() error {
+		var err error
+		result, err = m.client.CallTool(ctx, request)
+		return err
+	})
 	if err != nil {
+		metrics.MCPCalls.Inc(name, "error")
 		return nil, fmt.Errorf("failed to call tool %s: %w", name, err)
 	}
 
 	if result.IsError {
+		metrics.MCPCalls.Inc(name, "tool_error")
 		if len(result.Content) > 0 {
 			if textContent, ok := result.Content[0].(mcp.TextContent); ok {
 				return nil, fmt.Errorf("tool error: %s", textContent.Text)
@@ -158,6 +190,7 @@ func (m *Client) CallTool(ctx context.Context, name string, args map[string]any)
 		return nil, fmt.Errorf("tool %s returned an error", name)
 	}
 
+	metrics.MCPCalls.Inc(name, "ok")
 	return result, nil
 }
 
@@ -259,23 +292,30 @@ func FetchServerStatus(ctx context.Context, name string, url string) ServerStatu
 		return status
 	}
 
-	c, err := client.NewStreamableHttpClient(url)
+	var c *client.Client
+	err := currentRetryPolicy().do(ctx, isConnectionError, func() error {
+		var err error
+		c, err = client.NewStreamableHttpClient(url)
+		if err != nil {
+			return fmt.Errorf("client init: %w", err)
+		}
+		if err := c.Start(ctx); err != nil {
+			c.Close()
+			return fmt.Errorf("connect: %w", err)
+		}
+		if _, err := c.Initialize(ctx, buildInitializeRequest()); err != nil {
+			c.Close()
+			return fmt.Errorf("init: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		status.Error = fmt.Sprintf("client init: %v", err)
+		status.Error = err.Error()
+		status.ErrorKind = ClassifyError(err)
 		return status
 	}
 	defer c.Close()
 
-	if err := c.Start(ctx); err != nil {
-		status.Error = fmt.Sprintf("connect: %v", err)
-		return status
-	}
-
-	if _, err := c.Initialize(ctx, buildInitializeRequest()); err != nil {
-		status.Error = fmt.Sprintf("init: %v", err)
-		return status
-	}
-
 	status.Connected = true
 	tools, err := c.ListTools(ctx, mcp.ListToolsRequest{})
 	if err != nil {
@@ -324,6 +364,40 @@ func FetchServerStatus(ctx context.Context, name string, url string) ServerStatu
 	return status
 }
 
+// Settle combines a freshly fetched status with the previous one for the
+// same server. A server that was connected and starts failing is reported
+// degraded, keeping its last known lists, until it has failed
+// DegradedAfter refreshes in a row.
+func Settle(prev, cur ServerStatus) ServerStatus {
+	if cur.Connected {
+		return cur
+	}
+	cur.Failures = prev.Failures + 1
+	if (prev.Connected || prev.Degraded) && cur.Failures < DegradedAfter {
+		cur.Degraded = true
+		cur.Tools, cur.Prompts = prev.Tools, prev.Prompts
+		cur.Resources, cur.ResourceTemplates = prev.Resources, prev.ResourceTemplates
+	}
+	return cur
+}
+
+// SettleAll applies Settle to each status in cur, matching previous
+// statuses by name and URL.
+func SettleAll(prev, cur []ServerStatus) []ServerStatus {
+	settled := make([]ServerStatus, len(cur))
+	for i, st := range cur {
+		var previous ServerStatus
+		for _, p := range prev {
+			if p.Name == st.Name && p.URL == st.URL {
+				previous = p
+				break
+			}
+		}
+		settled[i] = Settle(previous, st)
+	}
+	return settled
+}
+
 // Global MCP client instance (lazy initialized)
 var globalClient *Client
 
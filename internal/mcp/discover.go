@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Found is an MCP server defined in another client's config.
+type Found struct {
+	Server config.MCPServerConfig
+	// Source says where it was found, e.g. "Claude Desktop".
+	Source string
+	// Command is the launch command of a stdio server. skitz only
+	// connects over HTTP, so these are listed but can't be imported.
+	Command string
+}
+
+// Importable reports whether skitz can connect to the server.
+func (f Found) Importable() bool {
+	return f.Server.URL != ""
+}
+
+// clientConfig is the mcpServers file shared by Claude Desktop and Cursor
+type clientConfig struct {
+	MCPServers map[string]struct {
+		URL       string   `json:"url"`
+		ServerURL string   `json:"serverUrl"`
+		Command   string   `json:"command"`
+		Args      []string `json:"args"`
+	} `json:"mcpServers"`
+}
+
+// Discover finds the MCP servers in Claude Desktop's
+// claude_desktop_config.json and Cursor's global and project mcp.json.
+// Servers whose URL is already in existing are left out; names are made
+// unique against existing.
+func Discover(existing []config.MCPServerConfig) []Found {
+	configDir, _ := os.UserConfigDir()
+	home, _ := os.UserHomeDir()
+	cwd, _ := os.Getwd()
+	return discover(configDir, home, cwd, existing)
+}
+
+func discover(configDir, home, cwd string, existing []config.MCPServerConfig) []Found {
+	type source struct{ name, path string }
+	var sources []source
+	if configDir != "" {
+		sources = append(sources, source{"Claude Desktop", filepath.Join(configDir, "Claude", "claude_desktop_config.json")})
+	}
+	if home != "" {
+		sources = append(sources, source{"Cursor", filepath.Join(home, ".cursor", "mcp.json")})
+	}
+	if cwd != "" && cwd != home {
+		sources = append(sources, source{"Cursor (project)", filepath.Join(cwd, ".cursor", "mcp.json")})
+	}
+
+	seen := make(map[string]bool)
+	names := make(map[string]bool)
+	for _, s := range existing {
+		seen[s.URL] = true
+		names[s.Name] = true
+	}
+
+	var found []Found
+	for _, src := range sources {
+		for _, f := range readClientConfig(src.path, src.name) {
+			key := f.Server.URL
+			if key == "" {
+				key = f.Command
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			f.Server.Name = uniqueName(names, f.Server.Name)
+			names[f.Server.Name] = true
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+// readClientConfig returns the servers in the mcpServers file at path,
+// sorted by name. A missing or unreadable file has none.
+func readClientConfig(path, source string) []Found {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cfg clientConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+
+	var found []Found
+	for _, name := range slices.Sorted(maps.Keys(cfg.MCPServers)) {
+		s := cfg.MCPServers[name]
+		f := Found{Server: config.MCPServerConfig{Name: name}, Source: source}
+		switch {
+		case s.URL != "":
+			f.Server.URL = s.URL
+		case s.ServerURL != "":
+			f.Server.URL = s.ServerURL
+		case remoteURL(s.Args) != "":
+			f.Server.URL = remoteURL(s.Args)
+		case s.Command != "":
+			f.Command = strings.Join(append([]string{s.Command}, s.Args...), " ")
+		default:
+			continue
+		}
+		found = append(found, f)
+	}
+	return found
+}
+
+// remoteURL returns the URL of a server that stdio-only clients reach
+// through the mcp-remote proxy, e.g. npx -y mcp-remote https://...
+func remoteURL(args []string) string {
+	for i, a := range args {
+		if a == "mcp-remote" || strings.HasPrefix(a, "mcp-remote@") {
+			for _, next := range args[i+1:] {
+				if strings.HasPrefix(next, "http://") || strings.HasPrefix(next, "https://") {
+					return next
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// uniqueName returns name, or name with a numeric suffix when taken
+func uniqueName(taken map[string]bool, name string) string {
+	if !taken[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := name + "-" + strconv.Itoa(i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
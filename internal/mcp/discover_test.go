@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	configDir, home, cwd := t.TempDir(), t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(configDir, "Claude", "claude_desktop_config.json"), `{
+		"mcpServers": {
+			"filesystem": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-filesystem", "/tmp"]},
+			"linear": {"command": "npx", "args": ["-y", "mcp-remote", "https://mcp.linear.app/sse"]}
+		}
+	}`)
+	writeFile(t, filepath.Join(home, ".cursor", "mcp.json"), `{
+		"mcpServers": {
+			"local": {"url": "http://localhost:8001/mcp/"},
+			"datadog": {"url": "https://mcp.datadoghq.com/mcp"},
+			"windsurf-style": {"serverUrl": "https://mcp.example.com/mcp"}
+		}
+	}`)
+	writeFile(t, filepath.Join(cwd, ".cursor", "mcp.json"), `{"mcpServers": {"datadog": {"url": "https://mcp.datadoghq.eu/mcp"}}}`)
+
+	existing := []config.MCPServerConfig{{Name: "local", URL: "http://localhost:8001/mcp/"}}
+	found := discover(configDir, home, cwd, existing)
+
+	want := []struct {
+		name, url, source string
+		importable        bool
+	}{
+		{"filesystem", "", "Claude Desktop", false},
+		{"linear", "https://mcp.linear.app/sse", "Claude Desktop", true},
+		{"datadog", "https://mcp.datadoghq.com/mcp", "Cursor", true},
+		{"windsurf-style", "https://mcp.example.com/mcp", "Cursor", true},
+		{"datadog-2", "https://mcp.datadoghq.eu/mcp", "Cursor (project)", true},
+	}
+	if len(found) != len(want) {
+		t.Fatalf("discover() found %d servers, want %d: %+v", len(found), len(want), found)
+	}
+	for i, w := range want {
+		f := found[i]
+		if f.Server.Name != w.name || f.Server.URL != w.url || f.Source != w.source || f.Importable() != w.importable {
+			t.Errorf("found[%d] = %+v, want %+v", i, f, w)
+		}
+	}
+	if found[0].Command != "npx -y @modelcontextprotocol/server-filesystem /tmp" {
+		t.Errorf("stdio command = %q", found[0].Command)
+	}
+}
+
+func TestDiscoverMissingConfigs(t *testing.T) {
+	if found := discover(t.TempDir(), t.TempDir(), "", nil); len(found) != 0 {
+		t.Errorf("discover() = %+v, want none", found)
+	}
+}
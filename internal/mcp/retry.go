@@ -0,0 +1,136 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how connections and tool calls are retried after
+// transient network errors.
+type RetryPolicy struct {
+	Attempts  int           // total attempts, including the first
+	BaseDelay time.Duration // delay before the first retry, doubled for each further one
+	MaxDelay  time.Duration // upper bound on a single delay
+}
+
+// DefaultRetryPolicy is used unless SetRetryPolicy is called.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:  3,
+	BaseDelay: 200 * time.Millisecond,
+	MaxDelay:  2 * time.Second,
+}
+
+var (
+	retryMu     sync.RWMutex
+	retryPolicy = DefaultRetryPolicy
+)
+
+// SetRetryPolicy replaces the retry policy. Zero fields keep their
+// DefaultRetryPolicy values.
+func SetRetryPolicy(p RetryPolicy) {
+	if p.Attempts <= 0 {
+		p.Attempts = DefaultRetryPolicy.Attempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	retryMu.Lock()
+	defer retryMu.Unlock()
+	retryPolicy = p
+}
+
+func currentRetryPolicy() RetryPolicy {
+	retryMu.RLock()
+	defer retryMu.RUnlock()
+	return retryPolicy
+}
+
+// backoff returns the delay before retry number attempt (starting at 1):
+// exponential, capped at MaxDelay, with jitter in [d/2, d).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxDelay)
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(d-half)+1))
+}
+
+// do runs fn until it succeeds, returns an error retryable rejects, or the
+// attempts or ctx run out.
+func (p RetryPolicy) do(ctx context.Context, retryable func(error) bool, fn func() error) error {
+	attempts := max(p.Attempts, 1)
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= attempts || !retryable(err) {
+			return err
+		}
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// ErrorKind classifies why talking to a server failed.
+type ErrorKind string
+
+const (
+	// ErrorConnection means the server could not be reached: refused or
+	// reset connections, timeouts and DNS failures. These are retried.
+	ErrorConnection ErrorKind = "connection"
+	// ErrorProtocol means the server answered but the MCP exchange failed,
+	// for example an HTTP error status or an invalid response.
+	ErrorProtocol ErrorKind = "protocol"
+)
+
+// ClassifyError returns the kind of err, or "" for nil.
+func ClassifyError(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	if isConnectionError(err) {
+		return ErrorConnection
+	}
+	return ErrorProtocol
+}
+
+// isConnectionError reports whether err is a transient network failure
+func isConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// isDialError reports whether err happened before a request was sent, so
+// a non-idempotent call can safely be retried
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"nil", nil, ""},
+		{"connection refused", fmt.Errorf("connect: %w", syscall.ECONNREFUSED), ErrorConnection},
+		{"dial", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route")}, ErrorConnection},
+		{"timeout", fmt.Errorf("init: %w", context.DeadlineExceeded), ErrorConnection},
+		{"http status", errors.New("request failed with status 500: boom"), ErrorProtocol},
+		{"cancelled", context.Canceled, ErrorProtocol},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("ClassifyError() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	p := RetryPolicy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	transient := fmt.Errorf("connect: %w", syscall.ECONNREFUSED)
+
+	tests := []struct {
+		name      string
+		errs      []error // returned by successive calls
+		wantCalls int
+		wantErr   bool
+	}{
+		{"succeeds first time", []error{nil}, 1, false},
+		{"recovers after transient errors", []error{transient, transient, nil}, 3, false},
+		{"gives up after attempts", []error{transient, transient, transient, nil}, 3, true},
+		{"does not retry protocol errors", []error{errors.New("bad response"), nil}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := p.do(context.Background(), isConnectionError, func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if calls != tt.wantCalls || (err != nil) != tt.wantErr {
+				t.Errorf("do() calls = %d, err = %v, want %d calls, error %v", calls, err, tt.wantCalls, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt, limit := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+		for range 20 {
+			if d := p.backoff(attempt); d < limit/2 || d > limit {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, limit/2, limit)
+			}
+		}
+	}
+}
+
+func TestSettle(t *testing.T) {
+	connected := ServerStatus{Name: "local", Connected: true, Tools: []string{"search"}}
+	failed := ServerStatus{Name: "local", Error: "connect: refused", ErrorKind: ErrorConnection}
+
+	st := Settle(connected, failed)
+	if !st.Degraded || st.Failures != 1 || len(st.Tools) != 1 {
+		t.Fatalf("first failure = %+v, want degraded with tools kept", st)
+	}
+	for range DegradedAfter - 2 {
+		st = Settle(st, failed)
+	}
+	if !st.Degraded {
+		t.Fatalf("failure %d = %+v, want still degraded", st.Failures, st)
+	}
+	st = Settle(st, failed)
+	if st.Degraded || st.Failures != DegradedAfter {
+		t.Errorf("failure %d = %+v, want disconnected", st.Failures, st)
+	}
+
+	if st := Settle(st, connected); !st.Connected || st.Failures != 0 || st.Degraded {
+		t.Errorf("recovered = %+v, want connected", st)
+	}
+	if st := Settle(ServerStatus{}, failed); st.Degraded {
+		t.Errorf("never connected = %+v, want not degraded", st)
+	}
+}
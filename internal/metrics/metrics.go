@@ -0,0 +1,275 @@
+// Package metrics keeps process-wide counters and serves them in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics recorded by skitz.
+var (
+	CommandsRun = NewCounterVec("skitz_commands_run_total", "Commands run from the TUI.", "mode")
+	MCPCalls    = NewCounterVec("skitz_mcp_calls_total", "MCP tool calls.", "tool", "result")
+	AITokens    = NewCounterVec("skitz_ai_tokens_total", "AI tokens used, as reported by the provider.", "provider", "kind")
+	Errors      = NewCounterVec("skitz_errors_total", "Errors shown to the user or returned by commands.", "source")
+
+	RenderSeconds = NewHistogram("skitz_render_duration_seconds", "Time spent rendering a TUI frame.",
+		[]float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25})
+)
+
+// collector is a metric that can write itself in the text format.
+type collector interface {
+	write(w io.Writer)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []collector
+)
+
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, c)
+}
+
+// CounterVec is a counter partitioned by label values.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64 // keyed by joined label values
+}
+
+// NewCounterVec creates and registers a counter with the given labels.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+	register(c)
+	return c
+}
+
+// Add adds n to the counter for the label values, given in the order the
+// labels were declared. Negative n is ignored.
+func (c *CounterVec) Add(n float64, values ...string) {
+	if n < 0 || len(values) != len(c.labels) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[strings.Join(values, "\xff")] += n
+}
+
+// Inc adds one to the counter for the label values.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Value returns the counter for the label values.
+func (c *CounterVec) Value(values ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[strings.Join(values, "\xff")]
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		var values []string
+		if len(c.labels) > 0 {
+			values = strings.Split(k, "\xff")
+		}
+		fmt.Fprintf(w, "%s%s %s\n", c.name, labelString(c.labels, values), formatFloat(c.values[k]))
+	}
+}
+
+// Histogram counts observations into cumulative buckets.
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+
+	mu     sync.Mutex
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram creates and registers a histogram with the given upper
+// bounds, which must be sorted.
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+	register(h)
+	return h
+}
+
+// Observe records one value.
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i, _ := slices.BinarySearch(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+// Since records the seconds elapsed since start.
+func (h *Histogram) Since(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	var cumulative uint64
+	for i, le := range h.buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, formatFloat(le), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count)
+	fmt.Fprintf(w, "%s_sum %s\n", h.name, formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count %d\n", h.name, h.count)
+}
+
+// labelString formats labels as {a="x",b="y"}.
+func labelString(labels, values []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(labels))
+	for i, l := range labels {
+		pairs[i] = l + "=" + strconv.Quote(values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Write writes every registered metric in the text format.
+func Write(w io.Writer) {
+	registryMu.Lock()
+	collectors := slices.Clone(registry)
+	registryMu.Unlock()
+
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Handler serves the registered metrics.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		Write(w)
+	})
+}
+
+// Serve starts serving /metrics on addr in the background. It returns once
+// the address is bound, with a function that stops the server.
+func Serve(addr string) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler())
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("metrics server stopped", "error", err)
+		}
+	}()
+	slog.Info("metrics server started", "addr", ln.Addr().String())
+	return func() { srv.Close() }, nil
+}
+
+// contentType is the media type of the text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Push sends every registered metric to endpoint in the text format, as a
+// Prometheus Pushgateway or a telemetry collector accepts it.
+func Push(ctx context.Context, endpoint string) error {
+	var b bytes.Buffer
+	Write(&b)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, &b)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to push metrics: %s", resp.Status)
+	}
+	return nil
+}
+
+// StartPush pushes the metrics to endpoint every interval in the
+// background. It returns a function that stops pushing after a last push.
+func StartPush(endpoint string, interval time.Duration) func() {
+	push := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := Push(ctx, endpoint); err != nil {
+			slog.Warn("telemetry push failed", "endpoint", endpoint, "error", err)
+		}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				push()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		push()
+	}
+}
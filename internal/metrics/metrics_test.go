@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCounterVecWrite(t *testing.T) {
+	c := &CounterVec{name: "test_total", help: "Test counter.", labels: []string{"mode", "result"}, values: make(map[string]float64)}
+	c.Inc("embedded", "ok")
+	c.Add(2, "interactive", "error")
+	c.Inc("embedded", "ok")
+	c.Add(-1, "embedded", "ok") // ignored
+	c.Inc("missing-label")      // ignored
+
+	var b strings.Builder
+	c.write(&b)
+	want := `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{mode="embedded",result="ok"} 2
+test_total{mode="interactive",result="error"} 2
+`
+	if b.String() != want {
+		t.Errorf("write() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestHistogramWrite(t *testing.T) {
+	h := &Histogram{name: "test_seconds", help: "Test histogram.", buckets: []float64{0.1, 1}, counts: make([]uint64, 2)}
+	for _, v := range []float64{0.05, 0.1, 0.5, 3} {
+		h.Observe(v)
+	}
+
+	var b strings.Builder
+	h.write(&b)
+	want := `# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{le="0.1"} 2
+test_seconds_bucket{le="1"} 3
+test_seconds_bucket{le="+Inf"} 4
+test_seconds_sum 3.65
+test_seconds_count 4
+`
+	if b.String() != want {
+		t.Errorf("write() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	CommandsRun.Inc("embedded")
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `skitz_commands_run_total{mode="embedded"}`) {
+		t.Errorf("metrics output missing commands counter:\n%s", rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestServeAddressInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if stop, err := Serve(ln.Addr().String()); err == nil {
+		stop()
+		t.Error("Serve() on a bound address succeeded, want error")
+	}
+}
+
+func TestStartPush(t *testing.T) {
+	CommandsRun.Inc("embedded")
+
+	bodies := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPut || !strings.HasPrefix(r.Header.Get("Content-Type"), "text/plain") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		bodies <- string(b)
+	}))
+	defer srv.Close()
+
+	// Stopping pushes once more, so the last values aren't lost
+	stop := StartPush(srv.URL, time.Hour)
+	stop()
+	select {
+	case body := <-bodies:
+		if !strings.Contains(body, `skitz_commands_run_total{mode="embedded"}`) {
+			t.Errorf("pushed metrics missing commands counter:\n%s", body)
+		}
+	default:
+		t.Fatal("no metrics pushed when stopping")
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	if err := Push(context.Background(), failing.URL); err == nil {
+		t.Error("Push() succeeded against a failing endpoint")
+	}
+}
@@ -0,0 +1,52 @@
+// Package notify sends OS desktop notifications.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// sendTimeout bounds how long a notifier command may run.
+const sendTimeout = 5 * time.Second
+
+// Send shows a desktop notification with notify-send on Linux and
+// osascript on macOS. When neither is available it rings the terminal
+// bell instead.
+func Send(title, message string) error {
+	name, args := Command(runtime.GOOS, title, message)
+	if name != "" {
+		if _, err := exec.LookPath(name); err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			defer cancel()
+			if err := exec.CommandContext(ctx, name, args...).Run(); err != nil {
+				return fmt.Errorf("failed to run %s: %w", name, err)
+			}
+			return nil
+		}
+	}
+	_, err := io.WriteString(os.Stdout, "\a")
+	return err
+}
+
+// Command returns the notifier command for goos, or "" when there is none.
+func Command(goos, title, message string) (string, []string) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{"--app-name=skitz", title, message}
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}
+	}
+	return "", nil
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
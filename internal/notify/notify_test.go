@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantName string
+		wantArgs []string
+	}{
+		{"linux", "notify-send", []string{"--app-name=skitz", `Done "build"`, `C:\tmp`}},
+		{"darwin", "osascript", []string{"-e", `display notification "C:\\tmp" with title "Done \"build\""`}},
+		{"windows", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			name, args := Command(tt.goos, `Done "build"`, `C:\tmp`)
+			if name != tt.wantName || !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("Command() = %q %q, want %q %q", name, args, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Package packs installs community resource packs listed in a registry.
+//
+// A registry is an index JSON served over HTTPS:
+//
+//	{"packs": [{"name": "terraform", "description": "Terraform CLI",
+//	  "version": "1.2.0", "files": [{"name": "terraform.md",
+//	  "url": "terraform/terraform.md", "sha256": "..."}]}]}
+//
+// File URLs may be relative to the index. Each pack is installed into its
+// own directory under Dir, and the installed versions are recorded so that
+// newer versions in the registry can be offered as updates.
+package packs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxFileSize bounds the index and each file downloaded
+const maxFileSize = 1 << 20
+
+// Index lists the packs a registry offers.
+type Index struct {
+	Packs []Pack `json:"packs"`
+}
+
+// Pack is a set of resource files.
+type Pack struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+	Author      string `json:"author,omitempty"`
+	Files       []File `json:"files"`
+}
+
+// File is one resource file of a pack, e.g. terraform.md or
+// terraform-detail.md.
+type File struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+var packNameRe = regexp.MustCompile(`^[a-zA-Z0-9][\w.-]*$`)
+
+// Validate checks that the pack can be installed safely: its name and file
+// names can't leave the pack's directory, and every file is markdown.
+func (p Pack) Validate() error {
+	if !packNameRe.MatchString(p.Name) {
+		return fmt.Errorf("invalid pack name %q", p.Name)
+	}
+	if len(p.Files) == 0 {
+		return fmt.Errorf("pack %s has no files", p.Name)
+	}
+	for _, f := range p.Files {
+		if !packNameRe.MatchString(f.Name) || !strings.HasSuffix(f.Name, ".md") {
+			return fmt.Errorf("pack %s: invalid file name %q", p.Name, f.Name)
+		}
+		if f.URL == "" {
+			return fmt.Errorf("pack %s: %s has no url", p.Name, f.Name)
+		}
+	}
+	return nil
+}
+
+// FetchIndex downloads the index at indexURL.
+func FetchIndex(ctx context.Context, indexURL string) (Index, error) {
+	data, err := get(ctx, indexURL)
+	if err != nil {
+		return Index{}, fmt.Errorf("failed to fetch registry: %w", err)
+	}
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return Index{}, fmt.Errorf("failed to parse registry: %w", err)
+	}
+	return index, nil
+}
+
+// Dir returns the directory packs are installed in under resourcesDir.
+func Dir(resourcesDir string) string {
+	return filepath.Join(resourcesDir, "packs")
+}
+
+// Install downloads the files of p, resolving relative URLs against
+// indexURL, and replaces the pack's directory under dir with them. A
+// failed download leaves an installed version in place.
+func Install(ctx context.Context, p Pack, indexURL, dir string) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return fmt.Errorf("invalid registry url: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create packs directory: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(dir, "."+p.Name+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, f := range p.Files {
+		ref, err := url.Parse(f.URL)
+		if err != nil {
+			return fmt.Errorf("invalid url for %s: %w", f.Name, err)
+		}
+		data, err := get(ctx, base.ResolveReference(ref).String())
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", f.Name, err)
+		}
+		if f.SHA256 != "" {
+			sum := sha256.Sum256(data)
+			if !strings.EqualFold(hex.EncodeToString(sum[:]), f.SHA256) {
+				return fmt.Errorf("checksum mismatch for %s", f.Name)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(tmp, f.Name), data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.Name, err)
+		}
+	}
+
+	target := filepath.Join(dir, p.Name)
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("failed to remove the installed version: %w", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return fmt.Errorf("failed to install %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// get returns the body at rawURL
+func get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxFileSize {
+		return nil, errors.New("response is larger than 1 MB")
+	}
+	return data, nil
+}
+
+// Installed maps the names of installed packs to their versions.
+type Installed map[string]InstalledPack
+
+// InstalledPack is the installed version of a pack.
+type InstalledPack struct {
+	Version     string    `json:"version"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// InstalledPath returns the installed packs file in dataDir.
+func InstalledPath(dataDir string) string {
+	return filepath.Join(dataDir, "packs.json")
+}
+
+// LoadInstalled reads the installed packs. A missing or unreadable file
+// has none.
+func LoadInstalled(path string) Installed {
+	installed := make(Installed)
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &installed); err != nil {
+			return make(Installed)
+		}
+	}
+	return installed
+}
+
+// Save writes the installed packs to path.
+func (i Installed) Save(path string) error {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write installed packs: %w", err)
+	}
+	return nil
+}
+
+// Updates returns the installed packs whose version in index differs from
+// the installed one.
+func Updates(index Index, installed Installed) []Pack {
+	var updates []Pack
+	for _, p := range index.Packs {
+		if inst, ok := installed[p.Name]; ok && inst.Version != p.Version {
+			updates = append(updates, p)
+		}
+	}
+	return updates
+}
@@ -0,0 +1,106 @@
+package packs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	files := map[string]string{
+		"/index.json":                    `{"packs": [{"name": "terraform", "version": "1.0.0", "files": [{"name": "terraform.md", "url": "terraform/terraform.md"}]}]}`,
+		"/terraform/terraform.md":        "# terraform\n\n`terraform plan` Plan ^run\n",
+		"/terraform/terraform-detail.md": "## Notes\n",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	indexURL := srv.URL + "/index.json"
+	index, err := FetchIndex(context.Background(), indexURL)
+	if err != nil {
+		t.Fatalf("FetchIndex() error = %v", err)
+	}
+	if len(index.Packs) != 1 || index.Packs[0].Name != "terraform" {
+		t.Fatalf("FetchIndex() = %+v", index)
+	}
+
+	dir := t.TempDir()
+	if err := Install(context.Background(), index.Packs[0], indexURL, dir); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "terraform", "terraform.md"))
+	if err != nil || !strings.Contains(string(data), "terraform plan") {
+		t.Fatalf("installed file = %q, %v", data, err)
+	}
+
+	// An update replaces the pack's files
+	sum := sha256.Sum256([]byte(files["/terraform/terraform-detail.md"]))
+	update := Pack{Name: "terraform", Version: "1.1.0", Files: []File{
+		{Name: "terraform-detail.md", URL: srv.URL + "/terraform/terraform-detail.md", SHA256: hex.EncodeToString(sum[:])},
+	}}
+	if err := Install(context.Background(), update, indexURL, dir); err != nil {
+		t.Fatalf("Install() update error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "terraform", "terraform.md")); !os.IsNotExist(err) {
+		t.Error("files of the old version should be removed")
+	}
+
+	// A failed download keeps the installed version
+	broken := Pack{Name: "terraform", Version: "2.0.0", Files: []File{{Name: "terraform.md", URL: "missing.md"}}}
+	if err := Install(context.Background(), broken, indexURL, dir); err == nil {
+		t.Error("Install() error = nil for a missing file")
+	}
+	bad := Pack{Name: "terraform", Version: "2.0.0", Files: []File{{Name: "terraform-detail.md", URL: "terraform/terraform-detail.md", SHA256: "00"}}}
+	if err := Install(context.Background(), bad, indexURL, dir); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Install() error = %v, want a checksum mismatch", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "terraform", "terraform-detail.md")); err != nil {
+		t.Errorf("installed version was removed: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, p := range []Pack{
+		{Name: "../etc", Files: []File{{Name: "a.md", URL: "a.md"}}},
+		{Name: "ok", Files: []File{{Name: "../a.md", URL: "a.md"}}},
+		{Name: "ok", Files: []File{{Name: "run.sh", URL: "run.sh"}}},
+		{Name: "ok"},
+	} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil", p)
+		}
+	}
+}
+
+func TestInstalledUpdates(t *testing.T) {
+	path := InstalledPath(t.TempDir())
+	installed := LoadInstalled(path)
+	installed["terraform"] = InstalledPack{Version: "1.0.0"}
+	installed["helm"] = InstalledPack{Version: "0.3.0"}
+	if err := installed.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	index := Index{Packs: []Pack{
+		{Name: "terraform", Version: "1.1.0"},
+		{Name: "helm", Version: "0.3.0"},
+		{Name: "ansible", Version: "1.0.0"},
+	}}
+	updates := Updates(index, LoadInstalled(path))
+	if len(updates) != 1 || updates[0].Name != "terraform" {
+		t.Errorf("Updates() = %+v, want terraform", updates)
+	}
+}
@@ -0,0 +1,202 @@
+// Package plugins discovers and runs external plugin executables.
+//
+// A plugin is an executable in the plugins directory. Run with --describe
+// it prints a Manifest as JSON. To run a command or render a panel it is
+// run without arguments, a Request as JSON on stdin, and prints a Response
+// as JSON (or plain text) on stdout.
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DescribeTimeout bounds how long a plugin may take to answer --describe.
+const DescribeTimeout = 5 * time.Second
+
+// ErrInvalidManifest is returned when --describe output can't be used.
+var ErrInvalidManifest = errors.New("invalid plugin manifest")
+
+// Manifest is what a plugin prints for --describe.
+type Manifest struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Commands    []Command `json:"commands,omitempty"`
+	Panels      []Panel   `json:"panels,omitempty"`
+}
+
+// Command is a palette item provided by a plugin.
+type Command struct {
+	ID          string  `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description,omitempty"`
+	Icon        string  `json:"icon,omitempty"`
+	Params      []Param `json:"params,omitempty"`
+}
+
+// Param is a value asked for before a command runs. Options turn the
+// input into a select.
+type Param struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title,omitempty"`
+	Placeholder string   `json:"placeholder,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+}
+
+// Panel is a block of text a plugin renders on the dashboard.
+type Panel struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Request kinds.
+const (
+	KindCommand = "command"
+	KindPanel   = "panel"
+)
+
+// Request is sent to a plugin on stdin.
+type Request struct {
+	Kind     string            `json:"kind"`
+	ID       string            `json:"id"`
+	Params   map[string]string `json:"params,omitempty"`
+	Resource string            `json:"resource,omitempty"` // resource open in skitz, if any
+	Width    int               `json:"width,omitempty"`    // columns available to a panel
+}
+
+// Response is what a plugin prints for a Request. Command output is
+// rendered as markdown; panel output is shown as it is.
+type Response struct {
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Plugin is a described plugin executable.
+type Plugin struct {
+	Path string
+	Manifest
+}
+
+// Dir returns the plugins directory inside configDir.
+func Dir(configDir string) string {
+	return filepath.Join(configDir, "plugins")
+}
+
+// Discover describes every executable in dir, sorted by name. A missing
+// directory yields no plugins; plugins that fail to describe themselves
+// are reported in the returned errors and skipped.
+func Discover(ctx context.Context, dir string) ([]Plugin, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, []error{fmt.Errorf("failed to read plugins directory: %w", err)}
+	}
+
+	var plugins []Plugin
+	var errs []error
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		info, err := os.Stat(path) // follows symlinks
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+
+		p, err := Describe(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, errs
+}
+
+// Describe runs path with --describe and parses its manifest. The name
+// defaults to the file name.
+func Describe(ctx context.Context, path string) (Plugin, error) {
+	ctx, cancel := context.WithTimeout(ctx, DescribeTimeout)
+	defer cancel()
+
+	out, err := run(ctx, path, nil, "--describe")
+	if err != nil {
+		return Plugin{}, fmt.Errorf("failed to describe plugin %s: %w", filepath.Base(path), err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(out, &m); err != nil {
+		return Plugin{}, fmt.Errorf("%w: %s: %v", ErrInvalidManifest, filepath.Base(path), err)
+	}
+	if m.Name == "" {
+		m.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	for _, c := range m.Commands {
+		if c.ID == "" || c.Title == "" {
+			return Plugin{}, fmt.Errorf("%w: %s: commands need an id and a title", ErrInvalidManifest, m.Name)
+		}
+	}
+	for _, p := range m.Panels {
+		if p.ID == "" {
+			return Plugin{}, fmt.Errorf("%w: %s: panels need an id", ErrInvalidManifest, m.Name)
+		}
+	}
+	return Plugin{Path: path, Manifest: m}, nil
+}
+
+// Invoke sends req to the plugin and returns its response. Output that is
+// not a JSON response is returned as plain text.
+func (p Plugin) Invoke(ctx context.Context, req Request) (Response, error) {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	out, err := run(ctx, p.Path, in)
+	if err != nil {
+		return Response{}, fmt.Errorf("plugin %s failed: %w", p.Name, err)
+	}
+
+	var resp Response
+	if trimmed := bytes.TrimSpace(out); len(trimmed) > 0 && trimmed[0] == '{' && json.Unmarshal(trimmed, &resp) == nil {
+		if resp.Error != "" {
+			return resp, fmt.Errorf("plugin %s: %s", p.Name, resp.Error)
+		}
+		return resp, nil
+	}
+	return Response{Output: string(out)}, nil
+}
+
+// run executes path with stdin and returns stdout. Errors include the
+// plugin's stderr.
+func run(ctx context.Context, path string, stdin []byte, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, path, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
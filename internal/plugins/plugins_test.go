@@ -0,0 +1,126 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePlugin writes an executable shell script into dir
+func writePlugin(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const greeter = `if [ "$1" = "--describe" ]; then
+  echo '{"name":"greeter","commands":[{"id":"hello","title":"Say hello","params":[{"name":"who","default":"world"}]}],"panels":[{"id":"status","title":"Status"}]}'
+  exit 0
+fi
+read req
+case "$req" in
+  *'"kind":"panel"'*) echo "all good" ;;
+  *'"who":"fail"'*) echo '{"error":"no such person"}' ;;
+  *) echo '{"output":"hello"}' ;;
+esac
+`
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "greeter", greeter)
+	writePlugin(t, dir, "broken", "echo not json\n")
+	writePlugin(t, dir, "unnamed.sh", `echo '{"commands":[{"id":"x","title":"X"}]}'`+"\n")
+	writePlugin(t, dir, ".hidden", "exit 1\n")
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("docs"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	found, errs := Discover(context.Background(), dir)
+	if len(found) != 2 {
+		t.Fatalf("got %d plugins, want 2", len(found))
+	}
+	if found[0].Name != "greeter" || found[1].Name != "unnamed" {
+		t.Errorf("names = %q, %q", found[0].Name, found[1].Name)
+	}
+	if len(found[0].Commands) != 1 || len(found[0].Panels) != 1 || found[0].Commands[0].Params[0].Default != "world" {
+		t.Errorf("manifest = %+v", found[0].Manifest)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrInvalidManifest) {
+		t.Errorf("errs = %v, want one ErrInvalidManifest", errs)
+	}
+}
+
+func TestDiscoverMissingDir(t *testing.T) {
+	found, errs := Discover(context.Background(), filepath.Join(t.TempDir(), "none"))
+	if found != nil || errs != nil {
+		t.Errorf("got %v, %v; want nothing", found, errs)
+	}
+}
+
+func TestDescribeInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"command without title", `echo '{"commands":[{"id":"x"}]}'`},
+		{"panel without id", `echo '{"panels":[{"title":"P"}]}'`},
+		{"not json", `echo hello`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writePlugin(t, t.TempDir(), "p", tt.script+"\n")
+			if _, err := Describe(context.Background(), path); !errors.Is(err, ErrInvalidManifest) {
+				t.Errorf("err = %v, want ErrInvalidManifest", err)
+			}
+		})
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	p, err := Describe(context.Background(), writePlugin(t, t.TempDir(), "greeter", greeter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		req     Request
+		want    string
+		wantErr string
+	}{
+		{"json response", Request{Kind: KindCommand, ID: "hello", Params: map[string]string{"who": "bob"}}, "hello", ""},
+		{"plain output", Request{Kind: KindPanel, ID: "status"}, "all good\n", ""},
+		{"error field", Request{Kind: KindCommand, ID: "hello", Params: map[string]string{"who": "fail"}}, "", "no such person"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := p.Invoke(context.Background(), tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Output != tt.want {
+				t.Errorf("output = %q, want %q", resp.Output, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvokeStderr(t *testing.T) {
+	path := writePlugin(t, t.TempDir(), "p", "echo 'token expired' >&2\nexit 3\n")
+	_, err := Plugin{Path: path, Manifest: Manifest{Name: "p"}}.Invoke(context.Background(), Request{Kind: KindCommand, ID: "x"})
+	if err == nil || !strings.Contains(err.Error(), "token expired") {
+		t.Errorf("err = %v, want stderr in the error", err)
+	}
+}
@@ -0,0 +1,240 @@
+// Package rag keeps a small local vector index of resources and history so
+// AI answers can use the most relevant snippets from all of them.
+package rag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultTopK is how many chunks a search returns unless configured.
+const DefaultTopK = 5
+
+// MaxChunk is the most bytes of text in one chunk.
+const MaxChunk = 1200
+
+// batchSize is how many chunks are embedded per request.
+const batchSize = 64
+
+// EmbedFunc returns one embedding vector per text.
+type EmbedFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+// Document is text to index, such as a resource section.
+type Document struct {
+	Source string // e.g. "docker › Commands"
+	Text   string
+}
+
+// Chunk is an indexed piece of a document.
+type Chunk struct {
+	Source string    `json:"source"`
+	Text   string    `json:"text"`
+	Hash   string    `json:"hash"`
+	Vector []float32 `json:"vector"`
+}
+
+// Result is a chunk found by Search.
+type Result struct {
+	Chunk
+	Score float64 // cosine similarity to the query
+}
+
+// Index is a vector index persisted as JSON. It is safe for concurrent
+// use.
+type Index struct {
+	mu     sync.Mutex
+	path   string
+	Model  string  `json:"model"`
+	Chunks []Chunk `json:"chunks"`
+}
+
+// Path returns the index file in dataDir.
+func Path(dataDir string) string {
+	return filepath.Join(dataDir, "rag-index.json")
+}
+
+// Open loads the index at path. A missing index, or one built with another
+// embedding model, starts empty.
+func Open(path, model string) (*Index, error) {
+	ix := &Index{path: path, Model: model}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ix, nil
+	}
+	if err != nil {
+		return ix, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var saved Index
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return ix, fmt.Errorf("failed to parse index: %w", err)
+	}
+	if saved.Model == model {
+		ix.Chunks = saved.Chunks
+	}
+	return ix, nil
+}
+
+// Len returns the number of indexed chunks.
+func (ix *Index) Len() int {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return len(ix.Chunks)
+}
+
+// Update makes the index match docs. Only chunks whose text is new are
+// embedded; chunks no longer in docs are dropped. It returns the number of
+// chunks embedded and saves the index when anything changed.
+func (ix *Index) Update(ctx context.Context, embed EmbedFunc, docs []Document) (int, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	known := make(map[string]Chunk, len(ix.Chunks))
+	for _, c := range ix.Chunks {
+		known[c.Hash] = c
+	}
+
+	var chunks []Chunk
+	var missing []int // indices into chunks needing vectors
+	seen := make(map[string]bool)
+	for _, doc := range docs {
+		for _, c := range Split(doc) {
+			if seen[c.Hash] {
+				continue
+			}
+			seen[c.Hash] = true
+			if k, ok := known[c.Hash]; ok {
+				c.Vector = k.Vector
+			} else {
+				missing = append(missing, len(chunks))
+			}
+			chunks = append(chunks, c)
+		}
+	}
+
+	for start := 0; start < len(missing); start += batchSize {
+		batch := missing[start:min(start+batchSize, len(missing))]
+		texts := make([]string, len(batch))
+		for i, idx := range batch {
+			texts[i] = chunks[idx].Source + "\n" + chunks[idx].Text
+		}
+		vectors, err := embed(ctx, texts)
+		if err != nil {
+			return start, fmt.Errorf("failed to embed chunks: %w", err)
+		}
+		if len(vectors) != len(batch) {
+			return start, fmt.Errorf("failed to embed chunks: got %d vectors for %d texts", len(vectors), len(batch))
+		}
+		for i, idx := range batch {
+			chunks[idx].Vector = vectors[i]
+		}
+	}
+
+	if len(missing) == 0 && len(chunks) == len(ix.Chunks) {
+		return 0, nil
+	}
+	ix.Chunks = chunks
+	return len(missing), ix.save()
+}
+
+// Search returns the k chunks most similar to query, best first.
+func (ix *Index) Search(ctx context.Context, embed EmbedFunc, query string, k int) ([]Result, error) {
+	vectors, err := embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("failed to embed query: got %d vectors", len(vectors))
+	}
+	if k <= 0 {
+		k = DefaultTopK
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	results := make([]Result, 0, len(ix.Chunks))
+	for _, c := range ix.Chunks {
+		results = append(results, Result{Chunk: c, Score: cosine(vectors[0], c.Vector)})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// Split cuts doc into chunks of at most MaxChunk bytes at paragraph and
+// line boundaries.
+func Split(doc Document) []Chunk {
+	var chunks []Chunk
+	var cur strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(strings.ToValidUTF8(cur.String(), "")); text != "" {
+			chunks = append(chunks, Chunk{Source: doc.Source, Text: text, Hash: hash(doc.Source, text)})
+		}
+		cur.Reset()
+	}
+
+	for _, para := range strings.Split(doc.Text, "\n\n") {
+		for _, line := range strings.Split(para, "\n") {
+			for len(line) > MaxChunk {
+				flush()
+				cur.WriteString(line[:MaxChunk])
+				flush()
+				line = line[MaxChunk:]
+			}
+			if cur.Len()+len(line)+1 > MaxChunk {
+				flush()
+			}
+			cur.WriteString(line + "\n")
+		}
+		cur.WriteString("\n")
+	}
+	flush()
+	return chunks
+}
+
+func (ix *Index) save() error {
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	data, err := json.Marshal(ix)
+	if err != nil {
+		return fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := os.WriteFile(ix.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+func hash(source, text string) string {
+	sum := sha256.Sum256([]byte(source + "\x00" + text))
+	return hex.EncodeToString(sum[:12])
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,85 @@
+package rag
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// wordEmbed embeds a text as counts of a few words, and records how many
+// texts it was asked for
+func wordEmbed(calls *int) EmbedFunc {
+	vocab := []string{"docker", "container", "git", "branch", "pod"}
+	return func(ctx context.Context, texts []string) ([][]float32, error) {
+		*calls += len(texts)
+		vectors := make([][]float32, len(texts))
+		for i, t := range texts {
+			v := make([]float32, len(vocab))
+			for j, w := range vocab {
+				v[j] = float32(strings.Count(strings.ToLower(t), w))
+			}
+			vectors[i] = v
+		}
+		return vectors, nil
+	}
+}
+
+func TestUpdateIsIncremental(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.json")
+	ix, err := Open(path, "words")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []Document{
+		{Source: "docker › Commands", Text: "`docker ps` list containers\n\n`docker logs` container logs"},
+		{Source: "git › Commands", Text: "`git branch` list branches"},
+	}
+	var calls int
+	if n, err := ix.Update(context.Background(), wordEmbed(&calls), docs); err != nil || n != 2 {
+		t.Fatalf("Update() = %d, %v; want 2 chunks embedded", n, err)
+	}
+
+	calls = 0
+	if n, _ := ix.Update(context.Background(), wordEmbed(&calls), docs); n != 0 || calls != 0 {
+		t.Errorf("unchanged docs embedded %d chunks", calls)
+	}
+
+	docs[1].Text = "`git checkout -b` new branch"
+	if n, _ := ix.Update(context.Background(), wordEmbed(&calls), docs); n != 1 || calls != 1 {
+		t.Errorf("changed doc embedded %d chunks, want 1", calls)
+	}
+
+	reopened, err := Open(path, "words")
+	if err != nil || reopened.Len() != 2 {
+		t.Fatalf("reopened index has %d chunks, err %v", reopened.Len(), err)
+	}
+	if other, _ := Open(path, "other-model"); other.Len() != 0 {
+		t.Error("index kept chunks embedded with another model")
+	}
+
+	results, err := reopened.Search(context.Background(), wordEmbed(&calls), "which branch am I on", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Source != "git › Commands" {
+		t.Errorf("Search() = %+v, want the git chunk", results)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	long := strings.Repeat("a", MaxChunk+10)
+	chunks := Split(Document{Source: "s", Text: "intro\n\n" + long})
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	for _, c := range chunks {
+		if len(c.Text) > MaxChunk {
+			t.Errorf("chunk of %d bytes", len(c.Text))
+		}
+	}
+	if chunks[0].Hash == chunks[1].Hash {
+		t.Error("chunks share a hash")
+	}
+}
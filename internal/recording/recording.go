@@ -0,0 +1,327 @@
+// Package recording records embedded terminal sessions with their timing
+// so they can be replayed in skitz or exported as asciicast v2 (.cast)
+// files for asciinema.
+//
+// A recording is a JSON Lines file: a Header, then one Event per chunk of
+// output, then an Event with the exit code once the command finished.
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Ext is the file extension of recordings in Dir.
+const Ext = ".jsonl"
+
+// Header describes the recorded session.
+type Header struct {
+	Command  string    `json:"command"`
+	Resource string    `json:"resource,omitempty"`
+	Shell    string    `json:"shell,omitempty"`
+	Started  time.Time `json:"started"`
+	Width    int       `json:"width"`
+	Height   int       `json:"height"`
+}
+
+// Event is output written Time seconds into the session, or the exit
+// code of the command when Exit is set.
+type Event struct {
+	Time   float64 `json:"t"`
+	Output string  `json:"o,omitempty"`
+	Exit   *int    `json:"exit,omitempty"`
+}
+
+// Recording is a session loaded from Dir.
+type Recording struct {
+	ID string // file name without Ext
+	Header
+	Events   []Event // output only
+	ExitCode *int    // nil when skitz closed the terminal before the command exited
+	Duration time.Duration
+}
+
+// Dir returns the directory recordings are kept in.
+func Dir(dataDir string) string {
+	return filepath.Join(dataDir, "recordings")
+}
+
+// Recorder appends a session's output to a recording as it is written.
+// It is safe to use from the goroutine reading the terminal while another
+// closes it.
+type Recorder struct {
+	mu      sync.Mutex
+	f       *os.File
+	w       *bufio.Writer
+	enc     *json.Encoder
+	path    string
+	start   time.Time
+	now     func() time.Time
+	pending []byte // incomplete UTF-8 sequence held for the next write
+	err     error
+}
+
+// Start creates a recording named id in dir and writes its header.
+func Start(dir, id string, h Header) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+	path := filepath.Join(dir, id+Ext)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+
+	if h.Started.IsZero() {
+		h.Started = time.Now()
+	}
+	r := &Recorder{f: f, w: bufio.NewWriter(f), path: path, start: h.Started, now: time.Now}
+	r.enc = json.NewEncoder(r.w)
+	if err := r.enc.Encode(h); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write recording header: %w", err)
+	}
+	return r, nil
+}
+
+// NewID returns a recording ID for command started at t, e.g.
+// "20260102-150405-kubectl-get-pods".
+func NewID(command string, t time.Time) string {
+	slug := config.Slug(command, "session")
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	return t.Format("20060102-150405") + "-" + slug
+}
+
+// Path returns the file the recording is written to.
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Write records p as output at the current time. It never fails, so the
+// terminal keeps working when the recording can't be written; the first
+// error is logged and returned by Close.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil || r.err != nil {
+		return len(p), nil
+	}
+
+	data := append(r.pending, p...)
+	data, r.pending = splitUTF8(data)
+	if len(data) > 0 {
+		r.record(Event{Time: r.elapsed(), Output: string(data)})
+	}
+	return len(p), nil
+}
+
+// Close records the exit code, or none when exitCode is negative, and
+// closes the file.
+func (r *Recorder) Close(exitCode int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return r.err
+	}
+
+	if len(r.pending) > 0 {
+		r.record(Event{Time: r.elapsed(), Output: string(r.pending)})
+		r.pending = nil
+	}
+	if exitCode >= 0 {
+		r.record(Event{Time: r.elapsed(), Exit: &exitCode})
+	}
+	if err := r.w.Flush(); err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to write recording: %w", err)
+	}
+	if err := r.f.Close(); err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to close recording: %w", err)
+	}
+	r.f = nil
+	return r.err
+}
+
+func (r *Recorder) record(e Event) {
+	if r.err != nil {
+		return
+	}
+	if err := r.enc.Encode(e); err != nil {
+		r.err = fmt.Errorf("failed to write recording: %w", err)
+		slog.Warn("recording stopped", "path", r.path, "error", err)
+	}
+}
+
+func (r *Recorder) elapsed() float64 {
+	return math.Round(r.now().Sub(r.start).Seconds()*1e6) / 1e6
+}
+
+// splitUTF8 splits off a multi-byte character cut short at the end of b,
+// so output isn't recorded with broken characters.
+func splitUTF8(b []byte) (complete, rest []byte) {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i], append([]byte(nil), b[i:]...)
+			}
+			break
+		}
+	}
+	return b, nil
+}
+
+// Load reads the recording at path.
+func Load(path string) (*Recording, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer f.Close()
+
+	rec := &Recording{ID: strings.TrimSuffix(filepath.Base(path), Ext)}
+	dec := json.NewDecoder(f)
+	if err := dec.Decode(&rec.Header); err != nil {
+		return nil, fmt.Errorf("failed to read recording header: %w", err)
+	}
+	var last float64
+	for {
+		var e Event
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// A session cut short by a crash keeps what was written
+			slog.Debug("truncated recording", "path", path, "error", err)
+			break
+		}
+		last = e.Time
+		if e.Exit != nil {
+			rec.ExitCode = e.Exit
+			continue
+		}
+		rec.Events = append(rec.Events, e)
+	}
+	rec.Duration = time.Duration(last * float64(time.Second))
+	return rec, nil
+}
+
+// List loads every recording in dir, newest first. Files that can't be
+// read are skipped.
+func List(dir string) ([]*Recording, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recordings directory: %w", err)
+	}
+
+	var recs []*Recording
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != Ext {
+			continue
+		}
+		rec, err := Load(filepath.Join(dir, e.Name()))
+		if err != nil {
+			slog.Warn("skipping recording", "file", e.Name(), "error", err)
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	sort.Slice(recs, func(i, j int) bool {
+		return recs[i].Started.After(recs[j].Started)
+	})
+	return recs, nil
+}
+
+// Prune removes all but the keep newest recordings in dir.
+func Prune(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read recordings directory: %w", err)
+	}
+
+	// IDs start with the start time, so names sort oldest first
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == Ext {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return fmt.Errorf("failed to remove recording: %w", err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// WriteCast writes rec as an asciicast v2 file. Players shorten pauses
+// longer than idleLimit, unless it is zero.
+func WriteCast(w io.Writer, rec *Recording, idleLimit time.Duration) error {
+	header := map[string]any{
+		"version":   2,
+		"width":     rec.Width,
+		"height":    rec.Height,
+		"timestamp": rec.Started.Unix(),
+		"command":   rec.Command,
+		"title":     rec.Command,
+		"env":       map[string]string{"SHELL": rec.Shell, "TERM": "xterm-256color"},
+	}
+	if idleLimit > 0 {
+		header["idle_time_limit"] = idleLimit.Seconds()
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(header); err != nil {
+		return fmt.Errorf("failed to write cast header: %w", err)
+	}
+	for _, e := range rec.Events {
+		if err := enc.Encode([]any{e.Time, "o", e.Output}); err != nil {
+			return fmt.Errorf("failed to write cast event: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write cast: %w", err)
+	}
+	return nil
+}
+
+// Export writes the recording id in dir to path as an asciicast v2 file.
+func Export(dir, id, path string, idleLimit time.Duration) error {
+	rec, err := Load(filepath.Join(dir, id+Ext))
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create cast file: %w", err)
+	}
+	if err := WriteCast(f, rec, idleLimit); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,97 @@
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	r, err := Start(dir, NewID("kubectl get pods", start), Header{Command: "kubectl get pods", Started: start, Width: 80, Height: 24})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := start
+	r.now = func() time.Time { return now }
+
+	now = now.Add(500 * time.Millisecond)
+	r.Write([]byte("NAME\r\n"))
+	now = now.Add(time.Second)
+	// "é" split across two reads is recorded whole
+	r.Write([]byte("caf\xc3"))
+	r.Write([]byte("\xa9\r\n"))
+	if err := r.Close(1); err != nil {
+		t.Fatal(err)
+	}
+
+	rec, err := Load(r.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.ID != "20260102-150405-kubectl-get-pods" || rec.Command != "kubectl get pods" || rec.Width != 80 {
+		t.Errorf("recording = %+v", rec)
+	}
+	var out strings.Builder
+	for _, e := range rec.Events {
+		out.WriteString(e.Output)
+	}
+	if out.String() != "NAME\r\ncafé\r\n" || len(rec.Events) != 3 {
+		t.Errorf("events = %+v", rec.Events)
+	}
+	if rec.Events[1].Output != "caf" || rec.Events[2].Time != 1.5 {
+		t.Errorf("events = %+v", rec.Events)
+	}
+	if rec.ExitCode == nil || *rec.ExitCode != 1 || rec.Duration != 1500*time.Millisecond {
+		t.Errorf("exit code = %v, duration = %v", rec.ExitCode, rec.Duration)
+	}
+}
+
+func TestWriteCast(t *testing.T) {
+	rec := &Recording{
+		Header: Header{Command: "ls", Started: time.Unix(1700000000, 0), Width: 80, Height: 24},
+		Events: []Event{{Time: 0.25, Output: "a\r\n"}, {Time: 1, Output: "b"}},
+	}
+	var buf bytes.Buffer
+	if err := WriteCast(&buf, rec, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("cast = %q", buf.String())
+	}
+	var header map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header["version"] != 2.0 || header["width"] != 80.0 || header["timestamp"] != 1700000000.0 || header["idle_time_limit"] != 2.0 {
+		t.Errorf("header = %v", header)
+	}
+	if lines[1] != `[0.25,"o","a\r\n"]` {
+		t.Errorf("event = %s", lines[1])
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"20260103-000000-c", "20260101-000000-a", "20260102-000000-b"} {
+		if err := os.WriteFile(filepath.Join(dir, name+Ext), []byte("{}\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Prune(dir, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "20260101-000000-a"+Ext)); !os.IsNotExist(err) {
+		t.Error("oldest recording was kept")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("%d recordings left, want 2", len(entries))
+	}
+}
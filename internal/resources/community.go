@@ -0,0 +1,287 @@
+package resources
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Community example sources. Variables so tests can point them at a local
+// server.
+var (
+	TLDRBaseURL = "https://raw.githubusercontent.com/tldr-pages/tldr/main/pages"
+	CheatShURL  = "https://cheat.sh"
+)
+
+// tldrPlatforms are the tldr page directories searched, in order.
+var tldrPlatforms = []string{"common", "linux", "osx"}
+
+// ErrNoExamples is returned when no source has examples for a tool.
+var ErrNoExamples = errors.New("no community examples found")
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// Page is a set of community examples for a tool.
+type Page struct {
+	Tool        string
+	Description string
+	Source      string // attribution line, e.g. "tldr-pages (CC BY 4.0)"
+	Examples    []Example
+}
+
+// Example is a single command example.
+type Example struct {
+	Description string
+	Command     string
+	// Input is the skitz input variable when the command has exactly one
+	// placeholder, written as {{Input}} in Command.
+	Input string
+	// Runnable is false for commands with several placeholders, which
+	// skitz cannot prompt for; they are listed but not run.
+	Runnable bool
+}
+
+// Line returns the example as a resource command line.
+func (e Example) Line() string {
+	if !e.Runnable {
+		return fmt.Sprintf("`%s` %s", e.Command, e.Description)
+	}
+	if e.Input != "" {
+		return fmt.Sprintf("`%s` %s ^run:%s", e.Command, e.Description, e.Input)
+	}
+	return fmt.Sprintf("`%s` %s ^run", e.Command, e.Description)
+}
+
+// Markdown renders the page as resource content with an Examples section.
+func (p Page) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", p.Tool)
+	if p.Description != "" {
+		fmt.Fprintf(&b, "> %s\n\n", p.Description)
+	}
+	b.WriteString("## Examples\n\n")
+	for _, e := range p.Examples {
+		b.WriteString(e.Line() + "\n")
+	}
+	if p.Source != "" {
+		fmt.Fprintf(&b, "\nSource: %s\n", p.Source)
+	}
+	return b.String()
+}
+
+// FetchExamples fetches the tldr page for tool, falling back to cheat.sh.
+func FetchExamples(ctx context.Context, tool string) (Page, error) {
+	page, err := FetchTLDR(ctx, tool)
+	if err == nil {
+		return page, nil
+	}
+	if !errors.Is(err, ErrNoExamples) {
+		return Page{}, err
+	}
+	return FetchCheatSh(ctx, tool)
+}
+
+// FetchTLDR fetches and converts the tldr page for tool.
+func FetchTLDR(ctx context.Context, tool string) (Page, error) {
+	name := url.PathEscape(strings.ToLower(tool))
+	for _, platform := range tldrPlatforms {
+		body, err := get(ctx, fmt.Sprintf("%s/%s/%s.md", TLDRBaseURL, platform, name))
+		if errors.Is(err, ErrNoExamples) {
+			continue
+		}
+		if err != nil {
+			return Page{}, fmt.Errorf("failed to fetch tldr page: %w", err)
+		}
+		page := ParseTLDR(body)
+		if page.Tool == "" {
+			page.Tool = tool
+		}
+		if len(page.Examples) == 0 {
+			return Page{}, ErrNoExamples
+		}
+		return page, nil
+	}
+	return Page{}, ErrNoExamples
+}
+
+// FetchCheatSh fetches and converts the cheat.sh sheet for tool.
+func FetchCheatSh(ctx context.Context, tool string) (Page, error) {
+	// T disables terminal colours
+	body, err := get(ctx, fmt.Sprintf("%s/%s?T", CheatShURL, url.PathEscape(tool)))
+	if err != nil {
+		if errors.Is(err, ErrNoExamples) {
+			return Page{}, err
+		}
+		return Page{}, fmt.Errorf("failed to fetch cheat.sh sheet: %w", err)
+	}
+	page := ParseCheatSh(tool, body)
+	if len(page.Examples) == 0 {
+		return Page{}, ErrNoExamples
+	}
+	return page, nil
+}
+
+// get returns the body at rawURL, or ErrNoExamples for a 404.
+func get(ctx context.Context, rawURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "curl/8 (skitz)")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNoExamples
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ParseTLDR converts a tldr page:
+//
+//	# tar
+//	> Archiving utility.
+//	- Create an archive:
+//	`tar cf {{path/to/target.tar}} {{path/to/file}}`
+func ParseTLDR(md string) Page {
+	var page Page
+	var desc []string
+	var pending string
+
+	scanner := bufio.NewScanner(strings.NewReader(md))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "# "):
+			page.Tool = strings.TrimSpace(line[2:])
+		case strings.HasPrefix(line, "> "):
+			text := strings.TrimSpace(line[2:])
+			if !strings.HasPrefix(text, "More information") && !strings.HasPrefix(text, "See also") {
+				desc = append(desc, text)
+			}
+		case strings.HasPrefix(line, "- "):
+			pending = strings.TrimSuffix(strings.TrimSpace(line[2:]), ":")
+		case strings.HasPrefix(line, "`") && strings.HasSuffix(line, "`") && len(line) > 1:
+			if e, ok := convertExample(pending, line[1:len(line)-1], tldrPlaceholder); ok {
+				page.Examples = append(page.Examples, e)
+			}
+			pending = ""
+		}
+	}
+
+	page.Description = strings.Join(desc, " ")
+	page.Source = "tldr-pages (CC BY 4.0)"
+	return page
+}
+
+// ParseCheatSh converts a cheat.sh sheet, where "#" comment lines describe
+// the commands that follow them. Sheets for unknown topics have no
+// commands.
+func ParseCheatSh(tool, text string) Page {
+	page := Page{Tool: tool, Source: "cheat.sh"}
+	if strings.HasPrefix(strings.TrimSpace(text), "Unknown topic") {
+		return page
+	}
+	var desc []string
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "---") || cheatShHeader.MatchString(line):
+			desc = nil
+		case strings.HasPrefix(line, "#"):
+			text := strings.TrimSpace(strings.TrimLeft(line, "#"))
+			if text != "" {
+				desc = append(desc, text)
+			}
+		default:
+			description := strings.TrimSuffix(strings.Join(desc, " "), ":")
+			if e, ok := convertExample(description, line, tldrPlaceholder, cheatShPlaceholder); ok {
+				page.Examples = append(page.Examples, e)
+			}
+		}
+	}
+	return page
+}
+
+var (
+	tldrPlaceholder    = regexp.MustCompile(`\{\{(.*?)\}\}`)
+	cheatShPlaceholder = regexp.MustCompile(`<([^<>\s]+)>`)
+	cheatShHeader      = regexp.MustCompile(`^[\w.]+:\S+$`) // "cheat:tar", "tldr:tar"
+	nonWord            = regexp.MustCompile(`\W+`)
+)
+
+// convertExample turns a command with placeholders into an Example. A
+// single distinct placeholder becomes the input variable; tldr option
+// placeholders such as {{[-v|--verbose]}} become their long form.
+func convertExample(description, command string, placeholders ...*regexp.Regexp) (Example, bool) {
+	command = strings.TrimSpace(command)
+	if command == "" || strings.Contains(command, "`") {
+		return Example{}, false
+	}
+	// ^ starts a skitz annotation
+	description = strings.TrimSpace(strings.ReplaceAll(description, "^", ""))
+
+	command = tldrPlaceholder.ReplaceAllStringFunc(command, func(m string) string {
+		inner := m[2 : len(m)-2]
+		if strings.HasPrefix(inner, "[") && strings.HasSuffix(inner, "]") && strings.Contains(inner, "|") {
+			options := strings.Split(inner[1:len(inner)-1], "|")
+			return options[len(options)-1]
+		}
+		return m
+	})
+
+	var names []string
+	for _, re := range placeholders {
+		for _, m := range re.FindAllStringSubmatch(command, -1) {
+			if !slices.Contains(names, m[1]) {
+				names = append(names, m[1])
+			}
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return Example{Description: description, Command: command, Runnable: true}, true
+	case 1:
+		input := inputName(names[0])
+		for _, re := range placeholders {
+			command = re.ReplaceAllString(command, "{{"+input+"}}")
+		}
+		return Example{Description: description, Command: command, Input: input, Runnable: true}, true
+	}
+	return Example{Description: description, Command: command}, true
+}
+
+// inputName derives a skitz input variable from a placeholder such as
+// "path/to/file.txt", keeping only word characters.
+func inputName(placeholder string) string {
+	if i := strings.LastIndex(placeholder, "/"); i >= 0 && i < len(placeholder)-1 {
+		placeholder = placeholder[i+1:]
+	}
+	name := strings.Trim(nonWord.ReplaceAllString(placeholder, "_"), "_")
+	if name == "" {
+		return "input"
+	}
+	return name
+}
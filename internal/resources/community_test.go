@@ -0,0 +1,129 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const tarPage = `# tar
+
+> Archiving utility.
+> More information: <https://www.gnu.org/software/tar>.
+
+- List the contents of a tar file:
+
+` + "`tar {{[-t|--list]}} {{[-f|--file]}} {{path/to/source.tar}}`" + `
+
+- Create an archive from files:
+
+` + "`tar cf {{path/to/target.tar}} {{path/to/file1}}`" + `
+
+- Show the version:
+
+` + "`tar --version`" + `
+`
+
+func TestParseTLDR(t *testing.T) {
+	page := ParseTLDR(tarPage)
+
+	if page.Tool != "tar" || page.Description != "Archiving utility." {
+		t.Errorf("ParseTLDR() tool, description = %q, %q", page.Tool, page.Description)
+	}
+
+	want := []Example{
+		{Description: "List the contents of a tar file", Command: "tar --list --file {{source_tar}}", Input: "source_tar", Runnable: true},
+		{Description: "Create an archive from files", Command: "tar cf {{path/to/target.tar}} {{path/to/file1}}"},
+		{Description: "Show the version", Command: "tar --version", Runnable: true},
+	}
+	if !reflect.DeepEqual(page.Examples, want) {
+		t.Errorf("ParseTLDR() examples = %+v, want %+v", page.Examples, want)
+	}
+}
+
+func TestParseCheatSh(t *testing.T) {
+	sheet := ` cheat:jq 
+# Pretty print JSON:
+jq . <file>
+
+# Select a field:
+jq '.name' data.json | less
+ tldr:jq 
+# Output specific keys:
+jq '{{.key}}' {{file.json}}
+`
+	want := []Example{
+		{Description: "Pretty print JSON", Command: "jq . {{file}}", Input: "file", Runnable: true},
+		{Description: "Select a field", Command: "jq '.name' data.json | less", Runnable: true},
+		{Description: "Output specific keys", Command: "jq '{{.key}}' {{file.json}}"},
+	}
+	if got := ParseCheatSh("jq", sheet).Examples; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCheatSh() = %+v, want %+v", got, want)
+	}
+
+	if got := ParseCheatSh("nope", "Unknown topic.\nDo you mean one of these topics maybe?\n    nop\n"); len(got.Examples) != 0 {
+		t.Errorf("ParseCheatSh(unknown) = %+v, want no examples", got.Examples)
+	}
+}
+
+func TestExampleLine(t *testing.T) {
+	tests := []struct {
+		name string
+		e    Example
+		want string
+	}{
+		{"runnable", Example{Description: "Version", Command: "tar --version", Runnable: true}, "`tar --version` Version ^run"},
+		{"input", Example{Description: "List", Command: "ls {{dir}}", Input: "dir", Runnable: true}, "`ls {{dir}}` List ^run:dir"},
+		{"not runnable", Example{Description: "Copy", Command: "cp {{a}} {{b}}"}, "`cp {{a}} {{b}}` Copy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.Line(); got != tt.want {
+				t.Errorf("Line() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchExamplesFallsBackToCheatSh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tldr/linux/tar.md":
+			w.Write([]byte(tarPage))
+		case "/cheat/jq":
+			w.Write([]byte("# Pretty print:\njq . file.json\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	oldTLDR, oldCheat := TLDRBaseURL, CheatShURL
+	TLDRBaseURL, CheatShURL = srv.URL+"/tldr", srv.URL+"/cheat"
+	defer func() { TLDRBaseURL, CheatShURL = oldTLDR, oldCheat }()
+
+	ctx := context.Background()
+	tests := []struct {
+		tool       string
+		wantSource string
+		wantErr    error
+	}{
+		{"tar", "tldr-pages (CC BY 4.0)", nil},
+		{"jq", "cheat.sh", nil},
+		{"missing", "", ErrNoExamples},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			page, err := FetchExamples(ctx, tt.tool)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("FetchExamples() error = %v, want %v", err, tt.wantErr)
+			}
+			if page.Source != tt.wantSource {
+				t.Errorf("FetchExamples() source = %q, want %q", page.Source, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Package e2b runs agents in E2B cloud sandboxes. The control plane API
+// creates and kills sandboxes from a template; each sandbox runs envd,
+// whose API takes file uploads and streams the output of processes.
+package e2b
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Defaults for an unconfigured client.
+const (
+	DefaultAPIURL   = "https://api.e2b.dev"
+	DefaultDomain   = "e2b.app"
+	DefaultTemplate = "base"
+)
+
+// envdPort is the port envd listens on in every sandbox.
+const envdPort = 49983
+
+// envdUser is the sandbox user files are written and commands run as.
+const envdUser = "user"
+
+// ErrNoAPIKey is returned when no E2B API key is configured.
+var ErrNoAPIKey = errors.New("no E2B API key: set e2b.api_key or E2B_API_KEY")
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// streamClient has no timeout: a process streams for as long as it runs,
+// bounded by the caller's context.
+var streamClient = &http.Client{}
+
+// APIKey returns configured, then $E2B_API_KEY.
+func APIKey(configured string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	if key := os.Getenv("E2B_API_KEY"); key != "" {
+		return key, nil
+	}
+	return "", ErrNoAPIKey
+}
+
+// Client calls the E2B API and the envd API of its sandboxes.
+type Client struct {
+	APIKey string
+	APIURL string // defaults to DefaultAPIURL
+	Domain string // sandbox domain, defaults to DefaultDomain
+
+	// EnvdURL, when set, is used for every sandbox's envd API instead of
+	// its address under Domain, e.g. for a mock in tests.
+	EnvdURL string
+}
+
+// Template is a sandbox template.
+type Template struct {
+	ID       string   `json:"templateID"`
+	Aliases  []string `json:"aliases"`
+	CPUCount int      `json:"cpuCount"`
+	MemoryMB int      `json:"memoryMB"`
+	Public   bool     `json:"public"`
+}
+
+// Name returns the template's first alias, or its ID.
+func (t Template) Name() string {
+	if len(t.Aliases) > 0 {
+		return t.Aliases[0]
+	}
+	return t.ID
+}
+
+// Sandbox is a running sandbox.
+type Sandbox struct {
+	ID          string `json:"sandboxID"`
+	TemplateID  string `json:"templateID"`
+	AccessToken string `json:"envdAccessToken"`
+}
+
+// Templates returns the templates the API key can start sandboxes from.
+func (c Client) Templates(ctx context.Context) ([]Template, error) {
+	body, err := c.do(ctx, http.MethodGet, "/templates", nil)
+	if err != nil {
+		return nil, err
+	}
+	var templates []Template
+	if err := json.Unmarshal(body, &templates); err != nil {
+		return nil, fmt.Errorf("failed to decode templates: %w", err)
+	}
+	return templates, nil
+}
+
+// Create starts a sandbox from template that E2B kills after timeout,
+// rounded up to a second.
+func (c Client) Create(ctx context.Context, template string, timeout time.Duration) (Sandbox, error) {
+	if template == "" {
+		template = DefaultTemplate
+	}
+	payload, err := json.Marshal(map[string]any{
+		"templateID": template,
+		"timeout":    int((timeout + time.Second - 1) / time.Second),
+		"metadata":   map[string]string{"app": "skitz"},
+	})
+	if err != nil {
+		return Sandbox{}, err
+	}
+	body, err := c.do(ctx, http.MethodPost, "/sandboxes", payload)
+	if err != nil {
+		return Sandbox{}, fmt.Errorf("failed to create a %s sandbox: %w", template, err)
+	}
+	var sb Sandbox
+	if err := json.Unmarshal(body, &sb); err != nil {
+		return Sandbox{}, fmt.Errorf("failed to decode the sandbox: %w", err)
+	}
+	return sb, nil
+}
+
+// Kill stops a sandbox. A sandbox that already stopped isn't an error.
+func (c Client) Kill(ctx context.Context, id string) error {
+	_, err := c.do(ctx, http.MethodDelete, "/sandboxes/"+url.PathEscape(id), nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// APIError is an unsuccessful response from the E2B API.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("E2B API returned %d", e.Status)
+	}
+	return fmt.Sprintf("E2B API returned %d: %s", e.Status, e.Message)
+}
+
+// do sends a request with a JSON payload, if any, to the control plane
+// and returns the body of a successful response.
+func (c Client) do(ctx context.Context, method, path string, payload []byte) ([]byte, error) {
+	base := c.APIURL
+	if base == "" {
+		base = DefaultAPIURL
+	}
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(base, "/")+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", c.APIKey)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("E2B API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the E2B API response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, responseError(resp.StatusCode, body)
+	}
+	return body, nil
+}
+
+// responseError returns the APIError of an unsuccessful response
+func responseError(status int, body []byte) error {
+	var msg struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &msg) != nil || msg.Message == "" {
+		msg.Message = strings.TrimSpace(string(body))
+	}
+	return &APIError{Status: status, Message: msg.Message}
+}
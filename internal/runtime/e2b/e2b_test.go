@@ -0,0 +1,278 @@
+package e2b
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mockE2B serves the E2B API and a sandbox's envd API
+type mockE2B struct {
+	mu       sync.Mutex
+	created  map[string]any
+	killed   []string
+	uploaded map[string][]byte
+	commands []string
+	envs     map[string]string
+	dir      string
+	hang     bool // the agent process never finishes
+}
+
+func (m *mockE2B) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case r.URL.Path == "/templates":
+		if r.Header.Get("X-API-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":401,"message":"Invalid API key"}`))
+			return
+		}
+		w.Write([]byte(`[{"templateID":"rki5dems9wqfm4r03t7g","aliases":["base"],"cpuCount":2,"memoryMB":512,"public":true},{"templateID":"x1","cpuCount":4}]`))
+	case r.URL.Path == "/sandboxes" && r.Method == http.MethodPost:
+		json.NewDecoder(r.Body).Decode(&m.created)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sandboxID":"sb1","templateID":"base","envdAccessToken":"tok"}`))
+	case strings.HasPrefix(r.URL.Path, "/sandboxes/") && r.Method == http.MethodDelete:
+		m.killed = append(m.killed, strings.TrimPrefix(r.URL.Path, "/sandboxes/"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/files":
+		if r.Header.Get("X-Access-Token") != "tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(f)
+		m.uploaded[r.URL.Query().Get("path")] = data
+	case r.URL.Path == "/process.Process/Start":
+		body, _ := io.ReadAll(r.Body)
+		_, msg, _ := readFrame(bytes.NewReader(body))
+		var start struct {
+			Process struct {
+				Args []string          `json:"args"`
+				Envs map[string]string `json:"envs"`
+				Cwd  string            `json:"cwd"`
+			} `json:"process"`
+		}
+		json.Unmarshal(msg, &start)
+		command := start.Process.Args[len(start.Process.Args)-1]
+		m.commands = append(m.commands, command)
+		if strings.HasPrefix(command, "mkdir") {
+			w.Write(frame(0, []byte(`{"event":{"start":{"pid":1}}}`)))
+			w.Write(frame(0, []byte(`{"event":{"end":{"exited":true,"status":"exit status 0"}}}`)))
+			return
+		}
+		m.envs, m.dir = start.Process.Envs, start.Process.Cwd
+		hang := m.hang
+		m.mu.Unlock()
+		defer m.mu.Lock()
+
+		w.Header().Set("Content-Type", "application/connect+json")
+		for _, event := range []string{
+			`{"event":{"start":{"pid":2}}}`,
+			`{"event":{"data":{"stdout":"` + base64.StdEncoding.EncodeToString([]byte("thinking\n")) + `"}}}`,
+			`{"event":{"keepalive":{}}}`,
+			`{"event":{"data":{"stderr":"` + base64.StdEncoding.EncodeToString([]byte("warning\n")) + `"}}}`,
+		} {
+			w.Write(frame(0, []byte(event)))
+		}
+		w.(http.Flusher).Flush()
+		if hang {
+			<-r.Context().Done()
+			return
+		}
+		w.Write(frame(0, []byte(`{"event":{"end":{"exitCode":3,"exited":true,"status":"exit status 3"}}}`)))
+		w.Write(frame(endStreamFlag, []byte(`{}`)))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestRunSandbox(t *testing.T) {
+	mock := &mockE2B{uploaded: map[string][]byte{}}
+	srv := httptest.NewServer(mock)
+	defer srv.Close()
+	c := Client{APIKey: "key", APIURL: srv.URL, EnvdURL: srv.URL}
+
+	templates, err := c.Templates(context.Background())
+	if err != nil || len(templates) != 2 || templates[0].Name() != "base" || templates[1].Name() != "x1" {
+		t.Fatalf("Templates() = %+v, %v", templates, err)
+	}
+	var apiErr *APIError
+	if _, err := (Client{APIKey: "bad", APIURL: srv.URL}).Templates(context.Background()); !errors.As(err, &apiErr) || apiErr.Message != "Invalid API key" {
+		t.Errorf("Templates() with a bad key error = %v", err)
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, ".git"), 0755)
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0644)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("TOKEN=secret"), 0644)
+
+	var out bytes.Buffer
+	opts := RunOptions{
+		Template:  "agents",
+		Workspace: dir,
+		Process:   Process{Command: FastAgentCommand, Env: map[string]string{"AGENT_PROMPT": "review"}},
+		Budget:    Budget{MaxDuration: time.Hour, MaxCost: 0.05, CostPerHour: 0.6},
+	}
+	result, err := c.RunSandbox(context.Background(), opts, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 3 || result.SandboxID != "sb1" || result.Cost <= 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if !strings.Contains(out.String(), "thinking\nwarning\n") {
+		t.Errorf("output = %q", out.String())
+	}
+	// 0.05 USD at 0.6 USD an hour is five minutes
+	if mock.created["templateID"] != "agents" || mock.created["timeout"] != float64(300) {
+		t.Errorf("created %+v", mock.created)
+	}
+	if len(mock.killed) != 1 || mock.killed[0] != "sb1" {
+		t.Errorf("killed %v", mock.killed)
+	}
+	if mock.dir != WorkspaceDir || mock.envs["AGENT_PROMPT"] != "review" || len(mock.commands) != 2 {
+		t.Errorf("ran %q in %s with %v", mock.commands, mock.dir, mock.envs)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(mock.uploaded["/tmp/skitz-workspace.tar.gz"]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(zr)
+	var names []string
+	for {
+		h, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, h.Name)
+	}
+	if strings.Join(names, ",") != "main.go" {
+		t.Errorf("uploaded %v, want main.go without .git or .env", names)
+	}
+
+	// A run past its budget is stopped and its sandbox killed
+	mock.hang = true
+	opts.Workspace = ""
+	opts.Budget = Budget{MaxDuration: 200 * time.Millisecond}
+	out.Reset()
+	if _, err := c.RunSandbox(context.Background(), opts, &out); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("over budget error = %v", err)
+	}
+	if len(mock.killed) != 2 || !strings.Contains(out.String(), "thinking") {
+		t.Errorf("killed %v, output %q", mock.killed, out.String())
+	}
+}
+
+func TestBudgetLimit(t *testing.T) {
+	tests := []struct {
+		budget Budget
+		want   time.Duration
+	}{
+		{Budget{}, DefaultMaxDuration},
+		{Budget{MaxDuration: time.Hour}, time.Hour},
+		{Budget{MaxDuration: time.Hour, MaxCost: 0.05}, 30 * time.Minute},
+		{Budget{MaxCost: 1}, DefaultMaxDuration},
+	}
+	for _, tt := range tests {
+		if got := tt.budget.Limit(); got != tt.want {
+			t.Errorf("%+v Limit() = %v, want %v", tt.budget, got, tt.want)
+		}
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	t.Setenv("E2B_API_KEY", "")
+	if _, err := APIKey(""); !errors.Is(err, ErrNoAPIKey) {
+		t.Errorf("APIKey() error = %v, want ErrNoAPIKey", err)
+	}
+	t.Setenv("E2B_API_KEY", "from-env")
+	if got, _ := APIKey(""); got != "from-env" {
+		t.Errorf("APIKey() = %q, want the env key", got)
+	}
+}
+
+func TestScanWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":            "package main\n",
+		"docs/README.md":     "# docs\n",
+		".gitignore":         "# build output\nbuild/\n*.log\n/local.txt\n",
+		"build/app":          "binary",
+		"debug.log":          "log",
+		"docs/trace.log":     "log",
+		"local.txt":          "local",
+		"docs/local.txt":     "kept",
+		".env":               "TOKEN=secret",
+		".env.production":    "TOKEN=secret",
+		"certs/server.pem":   "key",
+		"id_ed25519":         "key",
+		"node_modules/x.js":  "module",
+		"web/node_modules/y": "module",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(p), 0755)
+		os.WriteFile(p, []byte(content), 0644)
+	}
+	want := ".gitignore,docs/README.md,docs/local.txt,main.go"
+
+	ws, err := ScanWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(ws.Files, ","); got != want {
+		t.Errorf("files = %s, want %s", got, want)
+	}
+	if ws.Size != int64(len(files["main.go"])+len(files["docs/README.md"])+len(files[".gitignore"])+len(files["docs/local.txt"])) {
+		t.Errorf("size = %d", ws.Size)
+	}
+	if ws.String() != "4 files, 1 KB" {
+		t.Errorf("String() = %q", ws.String())
+	}
+
+	// In a repository git decides, but secrets stay out even when committed
+	if _, err := exec.LookPath("git"); err == nil {
+		if err := exec.Command("git", "-C", dir, "init", "-q").Run(); err != nil {
+			t.Fatal(err)
+		}
+		exec.Command("git", "-C", dir, "add", "-f", ".env").Run()
+		ws, err := ScanWorkspace(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(ws.Files)
+		if got := strings.Join(ws.Files, ","); got != want {
+			t.Errorf("git files = %s, want %s", got, want)
+		}
+	}
+
+	// The scan stops once a workspace is too large
+	defer func(limit int64) { MaxWorkspaceBytes = limit }(MaxWorkspaceBytes)
+	MaxWorkspaceBytes = 10
+	if _, err := ScanWorkspace(dir); !errors.Is(err, ErrWorkspaceTooLarge) {
+		t.Errorf("over the limit error = %v", err)
+	}
+}
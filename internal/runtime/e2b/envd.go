@@ -0,0 +1,212 @@
+package e2b
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// envd serves processes over the Connect protocol: a streaming call's
+// request and responses are JSON messages, each framed by a flags byte
+// and a big-endian length. The last frame, flagged endStreamFlag, carries
+// the call's error, if any.
+const endStreamFlag = 0x02
+
+// envdURL returns the base URL of the sandbox's envd API
+func (c Client) envdURL(sb Sandbox) string {
+	if c.EnvdURL != "" {
+		return strings.TrimSuffix(c.EnvdURL, "/")
+	}
+	domain := c.Domain
+	if domain == "" {
+		domain = DefaultDomain
+	}
+	return fmt.Sprintf("https://%d-%s.%s", envdPort, sb.ID, domain)
+}
+
+// envdRequest returns a request to the sandbox's envd API, authenticated
+// as envdUser
+func (c Client) envdRequest(ctx context.Context, sb Sandbox, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.envdURL(sb)+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(envdUser, "")
+	if sb.AccessToken != "" {
+		req.Header.Set("X-Access-Token", sb.AccessToken)
+	}
+	return req, nil
+}
+
+// Upload writes the contents of r to path in the sandbox.
+func (c Client) Upload(ctx context.Context, sb Sandbox, path string, r io.Reader) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, r); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	q := url.Values{"path": {path}, "username": {envdUser}}
+	req, err := c.envdRequest(ctx, sb, http.MethodPost, "/files?"+q.Encode(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("failed to upload %s: %w", path, responseError(resp.StatusCode, msg))
+	}
+	return nil
+}
+
+// Process is a command to run in a sandbox.
+type Process struct {
+	Command string            // run with bash -l -c
+	Env     map[string]string // added to the sandbox's environment
+	Dir     string            // working directory, defaults to the user's home
+}
+
+// processEvent is a message streamed by envd's Start call
+type processEvent struct {
+	Event struct {
+		Data *struct {
+			Stdout string `json:"stdout"` // base64
+			Stderr string `json:"stderr"` // base64
+		} `json:"data"`
+		End *struct {
+			ExitCode int    `json:"exitCode"`
+			Error    string `json:"error"`
+		} `json:"end"`
+	} `json:"event"`
+}
+
+// Run runs p in the sandbox, writing its stdout and stderr to w as they
+// arrive, and returns its exit code.
+func (c Client) Run(ctx context.Context, sb Sandbox, p Process, w io.Writer) (int, error) {
+	msg, err := json.Marshal(map[string]any{
+		"process": map[string]any{
+			"cmd":  "/bin/bash",
+			"args": []string{"-l", "-c", p.Command},
+			"envs": p.Env,
+			"cwd":  p.Dir,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	req, err := c.envdRequest(ctx, sb, http.MethodPost, "/process.Process/Start", bytes.NewReader(frame(0, msg)))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/connect+json")
+	req.Header.Set("Connect-Protocol-Version", "1")
+
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start the process: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return 0, fmt.Errorf("failed to start the process: %w", responseError(resp.StatusCode, body))
+	}
+
+	r := bufio.NewReader(resp.Body)
+	for {
+		flags, msg, err := readFrame(r)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			return 0, fmt.Errorf("process stream ended early: %w", err)
+		}
+		if flags&endStreamFlag != 0 {
+			return 0, endStreamError(msg)
+		}
+
+		var event processEvent
+		if err := json.Unmarshal(msg, &event); err != nil {
+			return 0, fmt.Errorf("failed to decode a process event: %w", err)
+		}
+		if data := event.Event.Data; data != nil {
+			for _, chunk := range []string{data.Stdout, data.Stderr} {
+				if chunk == "" {
+					continue
+				}
+				out, err := base64.StdEncoding.DecodeString(chunk)
+				if err != nil {
+					return 0, fmt.Errorf("failed to decode process output: %w", err)
+				}
+				w.Write(out)
+			}
+		}
+		if end := event.Event.End; end != nil {
+			if end.Error != "" {
+				fmt.Fprintln(w, end.Error)
+			}
+			return end.ExitCode, nil
+		}
+	}
+}
+
+// frame frames msg as a Connect stream message
+func frame(flags byte, msg []byte) []byte {
+	b := make([]byte, 5, 5+len(msg))
+	b[0] = flags
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	return append(b, msg...)
+}
+
+// readFrame reads a Connect stream message
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > 16<<20 {
+		return 0, nil, fmt.Errorf("message of %d bytes is too large", size)
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return 0, nil, err
+	}
+	return header[0], msg, nil
+}
+
+// endStreamError returns the error carried by the end of a stream that
+// ended before the process did
+func endStreamError(msg []byte) error {
+	var end struct {
+		Error *struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(msg, &end) == nil && end.Error != nil {
+		return fmt.Errorf("process failed: %s: %s", end.Error.Code, end.Error.Message)
+	}
+	return errors.New("process stream ended without an exit code")
+}
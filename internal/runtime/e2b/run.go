@@ -0,0 +1,126 @@
+package e2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultMaxDuration bounds a run without a configured time budget.
+const DefaultMaxDuration = 10 * time.Minute
+
+// DefaultCostPerHour is the sandbox rate, in USD, assumed for cost budgets
+// when none is configured: about that of a default two vCPU sandbox.
+const DefaultCostPerHour = 0.10
+
+// FastAgentCommand runs fast-agent with the prompt, model and optional
+// instruction in $AGENT_PROMPT, $AGENT_MODEL and $AGENT_INSTRUCTION, as the
+// fastagent image does, installing uv first unless the template has it.
+const FastAgentCommand = `export PATH="$HOME/.local/bin:$PATH"; ` +
+	`command -v uvx >/dev/null || pip install -q --user uv >&2 || exit 1; ` +
+	`uvx --from fast-agent-mcp fast-agent -q --model "$AGENT_MODEL" ` +
+	`${AGENT_INSTRUCTION:+--instruction "$AGENT_INSTRUCTION"} --message "$AGENT_PROMPT"`
+
+// ErrBudgetExceeded is returned when a run is stopped by its budget.
+var ErrBudgetExceeded = errors.New("E2B run budget exceeded")
+
+// Budget limits how long a run's sandbox lives, directly or through what
+// it may cost.
+type Budget struct {
+	MaxDuration time.Duration // defaults to DefaultMaxDuration
+	MaxCost     float64       // USD; 0 = no cost limit
+	CostPerHour float64       // defaults to DefaultCostPerHour
+}
+
+// rate returns the hourly sandbox rate
+func (b Budget) rate() float64 {
+	if b.CostPerHour > 0 {
+		return b.CostPerHour
+	}
+	return DefaultCostPerHour
+}
+
+// Limit returns how long a run may take: the shorter of MaxDuration and
+// the time MaxCost pays for.
+func (b Budget) Limit() time.Duration {
+	limit := b.MaxDuration
+	if limit <= 0 {
+		limit = DefaultMaxDuration
+	}
+	if b.MaxCost > 0 {
+		limit = min(limit, time.Duration(b.MaxCost/b.rate()*float64(time.Hour)))
+	}
+	return limit
+}
+
+// Cost returns the estimated cost of a sandbox that ran for d.
+func (b Budget) Cost(d time.Duration) float64 {
+	return d.Hours() * b.rate()
+}
+
+// RunOptions describes a run in a new sandbox.
+type RunOptions struct {
+	Template  string // defaults to DefaultTemplate
+	Workspace string // local directory uploaded to WorkspaceDir, if set
+	Process   Process
+	Budget    Budget
+}
+
+// Result is the outcome of a finished run.
+type Result struct {
+	SandboxID string
+	ExitCode  int
+	Duration  time.Duration
+	Cost      float64 // estimated, USD
+}
+
+// RunSandbox starts a sandbox, uploads the workspace, runs the process
+// there streaming its output to w, and kills the sandbox. The sandbox is
+// created to expire with the budget, so it stops even if skitz doesn't.
+func (c Client) RunSandbox(ctx context.Context, opts RunOptions, w io.Writer) (Result, error) {
+	limit := opts.Budget.Limit()
+	ctx, cancel := context.WithTimeoutCause(ctx, limit, ErrBudgetExceeded)
+	defer cancel()
+
+	started := time.Now()
+	sb, err := c.Create(ctx, opts.Template, limit)
+	if err != nil {
+		return Result{}, runError(ctx, err)
+	}
+	result := Result{SandboxID: sb.ID}
+	defer func() {
+		killCtx, killCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer killCancel()
+		if err := c.Kill(killCtx, sb.ID); err != nil {
+			fmt.Fprintf(w, "\nfailed to stop sandbox %s: %v\n", sb.ID, err)
+		}
+	}()
+	finish := func(err error) (Result, error) {
+		result.Duration = time.Since(started)
+		result.Cost = opts.Budget.Cost(result.Duration)
+		return result, runError(ctx, err)
+	}
+
+	process := opts.Process
+	if opts.Workspace != "" {
+		if err := c.uploadWorkspace(ctx, sb, opts.Workspace, w); err != nil {
+			return finish(err)
+		}
+		if process.Dir == "" {
+			process.Dir = WorkspaceDir
+		}
+	}
+	result.ExitCode, err = c.Run(ctx, sb, process, w)
+	return finish(err)
+}
+
+// runError returns ErrBudgetExceeded for an error caused by the budget
+// running out
+func runError(ctx context.Context, err error) error {
+	if err != nil && errors.Is(context.Cause(ctx), ErrBudgetExceeded) {
+		return ErrBudgetExceeded
+	}
+	return err
+}
@@ -0,0 +1,279 @@
+package e2b
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// A workspace leaves the machine, so only what the project would commit is
+// uploaded: the files git tracks or doesn't ignore in a repository, or
+// those outside .gitignore patterns elsewhere, never likely secrets such
+// as .env files and keys. The files are listed before anything is read so
+// a directory that is too big, such as a home directory picked by mistake,
+// is refused early.
+
+// Limits of an uploaded workspace, before compression.
+var (
+	MaxWorkspaceBytes int64 = 100 << 20
+	MaxWorkspaceFiles       = 20000
+)
+
+// WorkspaceDir is where the workspace is unpacked in the sandbox.
+const WorkspaceDir = "/home/user/workspace"
+
+// ErrWorkspaceTooLarge is returned for a workspace over the limits.
+var ErrWorkspaceTooLarge = errors.New("workspace is too large to upload")
+
+// ExcludedFiles are name patterns never uploaded, even when committed.
+var ExcludedFiles = []string{
+	".env", ".env.*", "*.pem", "*.key", "*.p12", "*.pfx", "*.keystore",
+	"id_rsa*", "id_dsa*", "id_ecdsa*", "id_ed25519*",
+	".netrc", ".npmrc", ".pypirc", ".git-credentials", "*.tfstate", "*.tfstate.*",
+}
+
+// ExcludedDirs are directory names never uploaded.
+var ExcludedDirs = []string{".git", "node_modules", ".venv", "venv", "__pycache__", ".terraform"}
+
+// Workspace is the set of files of a directory that would be uploaded.
+type Workspace struct {
+	Dir   string
+	Files []string // slash-separated, relative to Dir
+	Size  int64    // total bytes
+}
+
+// ScanWorkspace lists the files of dir to upload. It fails with
+// ErrWorkspaceTooLarge as soon as they pass MaxWorkspaceFiles or
+// MaxWorkspaceBytes.
+func ScanWorkspace(dir string) (Workspace, error) {
+	ws := Workspace{Dir: dir}
+	add := func(rel string, size int64) error {
+		if excluded(rel) {
+			return nil
+		}
+		ws.Files = append(ws.Files, rel)
+		ws.Size += size
+		if len(ws.Files) > MaxWorkspaceFiles || ws.Size > MaxWorkspaceBytes {
+			return fmt.Errorf("%w: %s has over %d files or %d MB", ErrWorkspaceTooLarge, dir, MaxWorkspaceFiles, MaxWorkspaceBytes>>20)
+		}
+		return nil
+	}
+
+	if files, ok := gitFiles(dir); ok {
+		for _, rel := range files {
+			info, err := os.Lstat(filepath.Join(dir, filepath.FromSlash(rel)))
+			if err != nil || !info.Mode().IsRegular() {
+				// Deleted but still tracked, or a symlink
+				continue
+			}
+			if err := add(rel, info.Size()); err != nil {
+				return ws, err
+			}
+		}
+		return ws, nil
+	}
+
+	ignore := readGitignore(filepath.Join(dir, ".gitignore"))
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if excludedDir(d.Name()) || ignore.match(rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || ignore.match(rel, false) {
+			// Symlinks, sockets and devices aren't uploaded
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return add(rel, info.Size())
+	})
+	return ws, err
+}
+
+// gitFiles returns the files git tracks in dir, or doesn't ignore, when
+// dir is in a repository
+func gitFiles(dir string) ([]string, bool) {
+	out, err := exec.Command("git", "-C", dir, "ls-files", "-z", "--cached", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, false
+	}
+	var files []string
+	for f := range bytes.SplitSeq(out, []byte{0}) {
+		if len(f) > 0 {
+			files = append(files, string(f))
+		}
+	}
+	return files, true
+}
+
+// excluded reports whether a file is a likely secret or in an excluded
+// directory
+func excluded(rel string) bool {
+	parts := strings.Split(rel, "/")
+	for _, dir := range parts[:len(parts)-1] {
+		if excludedDir(dir) {
+			return true
+		}
+	}
+	name := parts[len(parts)-1]
+	for _, pattern := range ExcludedFiles {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func excludedDir(name string) bool {
+	for _, dir := range ExcludedDirs {
+		if name == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// gitignore is the patterns of a .gitignore file. Negations aren't
+// supported: a file ignored by any pattern stays ignored.
+type gitignore []string
+
+// readGitignore reads the patterns of the .gitignore at p, if any
+func readGitignore(p string) gitignore {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var patterns gitignore
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
+
+// match reports whether the file or directory at rel is ignored. Patterns
+// with a slash match the path from the root, others any name in it.
+func (g gitignore) match(rel string, isDir bool) bool {
+	for _, pattern := range g {
+		dirOnly := strings.HasSuffix(pattern, "/")
+		pattern = strings.TrimSuffix(pattern, "/")
+		if dirOnly && !isDir {
+			continue
+		}
+		if strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(strings.TrimPrefix(pattern, "/"), rel); ok {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Archive writes the workspace's files as a gzip-compressed tar archive
+// to w.
+func (ws Workspace) Archive(w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	for _, rel := range ws.Files {
+		if err := addToArchive(tw, ws.Dir, rel); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", rel, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// addToArchive writes the file at rel in dir to tw
+func addToArchive(tw *tar.Writer, dir, rel string) error {
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = rel
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.CopyN(tw, f, info.Size())
+	return err
+}
+
+// String describes the workspace, e.g. "42 files, 180 KB".
+func (ws Workspace) String() string {
+	return fmt.Sprintf("%d files, %d KB", len(ws.Files), (ws.Size+1023)>>10)
+}
+
+// uploadWorkspace uploads the files of dir as a compressed archive and
+// unpacks them into WorkspaceDir
+func (c Client) uploadWorkspace(ctx context.Context, sb Sandbox, dir string, w io.Writer) error {
+	ws, err := ScanWorkspace(dir)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "skitz-workspace-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if err := ws.Archive(f); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Uploading %s (%s)...\n", dir, ws)
+	const archive = "/tmp/skitz-workspace.tar.gz"
+	if err := c.Upload(ctx, sb, archive, f); err != nil {
+		return err
+	}
+	unpack := Process{Command: fmt.Sprintf("mkdir -p %s && tar -xzf %s -C %s && rm %s", WorkspaceDir, archive, WorkspaceDir, archive)}
+	code, err := c.Run(ctx, sb, unpack, w)
+	if err == nil && code != 0 {
+		err = fmt.Errorf("unpacking the workspace exited with %d", code)
+	}
+	return err
+}
@@ -0,0 +1,184 @@
+// Package runtime abstracts the container engine used to run agents. Docker,
+// Podman and nerdctl (containerd) share docker's CLI surface, so each
+// implementation differs only in its binary and a few subcommands.
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FastAgentImage is the image built from FastAgentBuildDir.
+const FastAgentImage = "skitz-fastagent"
+
+// FastAgentBuildDir is the fastagent Dockerfile directory, relative to the
+// skitz repository root.
+const FastAgentBuildDir = "docker/fastagent"
+
+// WorkspaceMount is where agent containers see the project directory
+// mounted for them.
+const WorkspaceMount = "/workspace"
+
+// Sentinel errors for runtime lookup.
+var (
+	ErrUnknownRuntime = errors.New("unknown container runtime")
+	ErrNoRuntime      = errors.New("no container runtime found (install docker, podman or nerdctl)")
+)
+
+// Runtime is a container engine CLI.
+type Runtime interface {
+	// Name is the identifier stored in config and agent history.
+	Name() string
+	// Label is a human-readable name for menus.
+	Label() string
+	// Available reports whether the engine's CLI is on PATH.
+	Available() bool
+	// ImageExists reports whether image is present locally.
+	ImageExists(ctx context.Context, image string) (bool, error)
+	// BuildCommand returns a shell command that builds dir into image.
+	BuildCommand(image, dir string) string
+	// RunCommand returns a shell command that runs a container.
+	RunCommand(opts RunOptions) string
+	// Remove force-removes a container, stopping it if it is running.
+	Remove(ctx context.Context, container string) error
+}
+
+// RunOptions describes a container run.
+type RunOptions struct {
+	Name    string
+	Image   string
+	Env     map[string]string
+	Volumes []string // host:container[:opts]
+	Remove  bool     // remove the container when it exits
+}
+
+// cli implements Runtime for docker-compatible command line tools.
+type cli struct {
+	name   string
+	label  string
+	binary string
+	// imageExistsArgs returns the arguments of a command that exits 0 only
+	// when the image exists locally.
+	imageExistsArgs func(image string) []string
+}
+
+func inspectImage(image string) []string {
+	return []string{"image", "inspect", image}
+}
+
+var runtimes = []Runtime{
+	&cli{name: "docker", label: "Docker", binary: "docker", imageExistsArgs: inspectImage},
+	&cli{name: "podman", label: "Podman", binary: "podman", imageExistsArgs: func(image string) []string {
+		return []string{"image", "exists", image}
+	}},
+	&cli{name: "nerdctl", label: "nerdctl (containerd)", binary: "nerdctl", imageExistsArgs: inspectImage},
+}
+
+// All returns every supported runtime in detection order.
+func All() []Runtime {
+	return runtimes
+}
+
+// Get returns the runtime with the given name.
+func Get(name string) (Runtime, error) {
+	for _, rt := range runtimes {
+		if rt.Name() == name {
+			return rt, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownRuntime, name)
+}
+
+// Detect returns the first available runtime.
+func Detect() (Runtime, error) {
+	for _, rt := range runtimes {
+		if rt.Available() {
+			return rt, nil
+		}
+	}
+	return nil, ErrNoRuntime
+}
+
+func (c *cli) Name() string  { return c.name }
+func (c *cli) Label() string { return c.label }
+
+func (c *cli) Available() bool {
+	_, err := exec.LookPath(c.binary)
+	return err == nil
+}
+
+func (c *cli) ImageExists(ctx context.Context, image string) (bool, error) {
+	err := exec.CommandContext(ctx, c.binary, c.imageExistsArgs(image)...).Run()
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to check image %s: %w", image, err)
+}
+
+func (c *cli) BuildCommand(image, dir string) string {
+	return strings.Join([]string{c.binary, "build", "-t", Quote(image), Quote(dir)}, " ")
+}
+
+func (c *cli) RunCommand(opts RunOptions) string {
+	args := []string{c.binary, "run"}
+	if opts.Remove {
+		args = append(args, "--rm")
+	}
+	if opts.Name != "" {
+		args = append(args, "--name", Quote(opts.Name))
+	}
+	for _, v := range opts.Volumes {
+		args = append(args, "-v", Quote(v))
+	}
+
+	keys := make([]string, 0, len(opts.Env))
+	for k := range opts.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-e", Quote(k+"="+opts.Env[k]))
+	}
+
+	return strings.Join(append(args, Quote(opts.Image)), " ")
+}
+
+func (c *cli) Remove(ctx context.Context, container string) error {
+	if err := exec.CommandContext(ctx, c.binary, "rm", "-f", container).Run(); err != nil {
+		return fmt.Errorf("failed to remove container %s: %w", container, err)
+	}
+	return nil
+}
+
+// FastAgentDir returns the fastagent build directory if it exists under
+// the working directory.
+func FastAgentDir() (string, bool) {
+	dir, err := filepath.Abs(FastAgentBuildDir)
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(filepath.Join(dir, "Dockerfile")); err != nil || info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
+
+// Quote quotes s for POSIX shells, leaving simple words unchanged.
+func Quote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
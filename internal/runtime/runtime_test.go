@@ -0,0 +1,44 @@
+package runtime
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		runtime string
+		opts    RunOptions
+		want    string
+	}{
+		{
+			runtime: "docker",
+			opts:    RunOptions{Name: "a1", Image: "skitz-fastagent", Remove: true, Env: map[string]string{"B": "2", "A": "it's"}},
+			want:    `docker run --rm --name a1 -e 'A=it'\''s' -e B=2 skitz-fastagent`,
+		},
+		{
+			runtime: "podman",
+			opts:    RunOptions{Image: "img", Volumes: []string{"/my dir:/skitz:ro"}},
+			want:    `podman run -v '/my dir:/skitz:ro' img`,
+		},
+		{
+			runtime: "nerdctl",
+			opts:    RunOptions{Image: "img", Env: map[string]string{"AGENT_PROMPT": "say $HOME"}},
+			want:    `nerdctl run -e 'AGENT_PROMPT=say $HOME' img`,
+		},
+	}
+
+	for _, tt := range tests {
+		rt, err := Get(tt.runtime)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", tt.runtime, err)
+		}
+		if got := rt.RunCommand(tt.opts); got != tt.want {
+			t.Errorf("%s RunCommand() = %s, want %s", tt.runtime, got, tt.want)
+		}
+	}
+
+	if _, err := Get("lxc"); !errors.Is(err, ErrUnknownRuntime) {
+		t.Errorf("Get(lxc) error = %v, want ErrUnknownRuntime", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package secrets resolves {{secret:NAME}} placeholders in commands.
+//
+// A secret's value never becomes part of the command line. The placeholder
+// is replaced with a reference to an environment variable, SKITZ_SECRET_NAME,
+// and the value is passed to the command in that variable, so history, the
+// terminal title and logs only ever show the reference.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// DefaultKeychainService is the keychain service of secrets without a
+// configured source.
+const DefaultKeychainService = "skitz"
+
+// Timeout bounds reading one secret.
+const Timeout = 30 * time.Second
+
+// ErrUnsupportedKeychain is returned on platforms without a supported
+// keychain tool.
+var ErrUnsupportedKeychain = errors.New("no keychain support on " + runtime.GOOS)
+
+var placeholderRe = regexp.MustCompile(`\{\{secret:(\w+)\}\}`)
+
+// Names returns the distinct secret names command refers to, in order.
+func Names(command string) []string {
+	var names []string
+	for _, m := range placeholderRe.FindAllStringSubmatch(command, -1) {
+		if !slices.Contains(names, m[1]) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// EnvName is the environment variable a secret is passed to commands in.
+func EnvName(name string) string {
+	return "SKITZ_SECRET_" + name
+}
+
+// Reference replaces each {{secret:NAME}} in command with a reference to
+// the SKITZ_SECRET_NAME environment variable in the syntax of shell, a
+// shell name or path: $env.SKITZ_SECRET_NAME for nu, $SKITZ_SECRET_NAME for
+// the others. The reference expands unquoted; POSIX shells and fish also
+// expand it in double quotes, but none in single quotes.
+func Reference(command, shell string) string {
+	if filepath.Base(shell) == "nu" {
+		return placeholderRe.ReplaceAllString(command, "$$env.SKITZ_SECRET_$1")
+	}
+	return placeholderRe.ReplaceAllString(command, "$$SKITZ_SECRET_$1")
+}
+
+// Env resolves the secrets command refers to. It returns the environment
+// entries that pass them to the command and their values, for redacting
+// output.
+func Env(ctx context.Context, command string, sources map[string]config.SecretSource) (env, values []string, err error) {
+	for _, name := range Names(command) {
+		value, err := Resolve(ctx, name, sources[name])
+		if err != nil {
+			return nil, nil, err
+		}
+		env = append(env, EnvName(name)+"="+value)
+		values = append(values, value)
+	}
+	return env, values, nil
+}
+
+// Resolve reads the secret name from src. Without a source it reads the
+// environment variable name, then the DefaultKeychainService keychain item.
+func Resolve(ctx context.Context, name string, src config.SecretSource) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	switch {
+	case src.Command != "":
+		out, err := run(ctx, "sh", "-c", src.Command)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+		}
+		return out, nil
+	case src.Keychain != "":
+		v, err := keychain(ctx, src.Keychain, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret %s from the keychain: %w", name, err)
+		}
+		return v, nil
+	case src.Env != "":
+		if v := os.Getenv(src.Env); v != "" {
+			return v, nil
+		}
+		return "", fmt.Errorf("secret %s: $%s is not set", name, src.Env)
+	}
+
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	v, err := keychain(ctx, DefaultKeychainService, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: $%s is not set and the keychain has none: %w", name, name, err)
+	}
+	return v, nil
+}
+
+// keychain reads the item of service and account from the macOS keychain
+// or, elsewhere, the Secret Service with secret-tool
+func keychain(ctx context.Context, service, account string) (string, error) {
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		args = []string{"security", "find-generic-password", "-s", service, "-a", account, "-w"}
+	case "linux", "freebsd", "openbsd":
+		args = []string{"secret-tool", "lookup", "service", service, "account", account}
+	default:
+		return "", ErrUnsupportedKeychain
+	}
+
+	out, err := run(ctx, args[0], args[1:]...)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", args[0], err)
+	}
+	if out == "" {
+		return "", fmt.Errorf("no item for service %s", service)
+	}
+	return out, nil
+}
+
+// run runs a command and returns its output without the trailing newline.
+// Its stderr goes in the error rather than to the terminal.
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// Redact replaces each of values in text with ****.
+func Redact(text string, values []string) string {
+	for _, v := range values {
+		if v != "" {
+			text = strings.ReplaceAll(text, v, "****")
+		}
+	}
+	return text
+}
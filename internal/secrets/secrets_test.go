@@ -0,0 +1,73 @@
+package secrets
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+func TestReference(t *testing.T) {
+	command := `curl -H "Authorization: Bearer {{secret:API_TOKEN}}" -u me:{{secret:PASS}} {{HOST}} {{secret:API_TOKEN}}`
+	if got, want := Names(command), []string{"API_TOKEN", "PASS"}; !slices.Equal(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	want := `curl -H "Authorization: Bearer $SKITZ_SECRET_API_TOKEN" -u me:$SKITZ_SECRET_PASS {{HOST}} $SKITZ_SECRET_API_TOKEN`
+	if got := Reference(command, "/bin/bash"); got != want {
+		t.Errorf("Reference() = %q, want %q", got, want)
+	}
+	if got, want := Reference("psql -p {{secret:PASS}}", "/usr/local/bin/nu"), "psql -p $env.SKITZ_SECRET_PASS"; got != want {
+		t.Errorf("Reference(nu) = %q, want %q", got, want)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("SKITZ_TEST_TOKEN", "from-env")
+	t.Setenv("OTHER_VAR", "from-other")
+
+	tests := []struct {
+		name    string
+		secret  string
+		src     config.SecretSource
+		want    string
+		wantErr string
+	}{
+		{name: "environment by name", secret: "SKITZ_TEST_TOKEN", want: "from-env"},
+		{name: "configured env", secret: "TOKEN", src: config.SecretSource{Env: "OTHER_VAR"}, want: "from-other"},
+		{name: "unset env", secret: "TOKEN", src: config.SecretSource{Env: "SKITZ_TEST_UNSET"}, wantErr: "$SKITZ_TEST_UNSET is not set"},
+		{name: "command", secret: "TOKEN", src: config.SecretSource{Command: "printf 's3cret\\n'"}, want: "s3cret"},
+		{name: "failing command", secret: "TOKEN", src: config.SecretSource{Command: "echo locked >&2; exit 1"}, wantErr: "locked"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(context.Background(), tt.secret, tt.src)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Resolve() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("SKITZ_TEST_TOKEN", "abc")
+	env, values, err := Env(context.Background(), "echo {{secret:SKITZ_TEST_TOKEN}} {{secret:DB}}", map[string]config.SecretSource{
+		"DB": {Command: "echo hunter2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"SKITZ_SECRET_SKITZ_TEST_TOKEN=abc", "SKITZ_SECRET_DB=hunter2"}; !slices.Equal(env, want) {
+		t.Errorf("env = %v, want %v", env, want)
+	}
+	if got := Redact("token abc, password hunter2", values); got != "token ****, password ****" {
+		t.Errorf("Redact() = %q", got)
+	}
+}
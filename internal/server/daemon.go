@@ -0,0 +1,152 @@
+package server
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// ErrUnsupportedPlatform is returned when no service manager is known for the OS.
+var ErrUnsupportedPlatform = errors.New("daemon install is only supported on linux (systemd) and macOS (launchd)")
+
+const launchdLabel = "com.htelsiz.skitz"
+
+// templateFuncs quote values for the service definitions, since the
+// executable's path and the address may hold any character
+var templateFuncs = template.FuncMap{
+	"xml":     xmlEscape,
+	"systemd": systemdQuote,
+}
+
+// xmlEscape escapes s for XML character data
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// systemdQuote quotes s as a single argument of a systemd command line,
+// where % starts a specifier and $ a variable
+func systemdQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(s)
+	return `"` + s + `"`
+}
+
+var systemdUnit = template.Must(template.New("unit").Funcs(templateFuncs).Parse(`[Unit]
+Description=skitz headless server
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart={{systemd .Exe}} serve --addr {{systemd .Addr}}
+Restart=on-failure
+RestartSec=5
+KillSignal=SIGTERM
+TimeoutStopSec=15
+
+[Install]
+WantedBy=default.target
+`))
+
+var launchdPlist = template.Must(template.New("plist").Funcs(templateFuncs).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Label}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{xml .Exe}}</string>
+		<string>serve</string>
+		<string>--addr</string>
+		<string>{{xml .Addr}}</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardErrorPath</key>
+	<string>{{xml .LogPath}}</string>
+</dict>
+</plist>
+`))
+
+// ServiceFile describes a generated service definition.
+type ServiceFile struct {
+	Kind    string `json:"kind"` // "systemd" or "launchd"
+	Path    string `json:"path"`
+	Content string `json:"-"`
+	// Enable is the command that starts the service after installation.
+	Enable string `json:"enable"`
+}
+
+// GenerateServiceFile renders the service definition for goos without writing it.
+func GenerateServiceFile(goos, home, exe, addr, logPath string) (ServiceFile, error) {
+	data := struct {
+		Exe, Addr, Label, LogPath string
+	}{exe, addr, launchdLabel, logPath}
+
+	var buf bytes.Buffer
+	switch goos {
+	case "linux":
+		if err := systemdUnit.Execute(&buf, data); err != nil {
+			return ServiceFile{}, fmt.Errorf("failed to render systemd unit: %w", err)
+		}
+		return ServiceFile{
+			Kind:    "systemd",
+			Path:    filepath.Join(home, ".config", "systemd", "user", "skitz.service"),
+			Content: buf.String(),
+			Enable:  "systemctl --user daemon-reload && systemctl --user enable --now skitz.service",
+		}, nil
+	case "darwin":
+		if err := launchdPlist.Execute(&buf, data); err != nil {
+			return ServiceFile{}, fmt.Errorf("failed to render launchd plist: %w", err)
+		}
+		path := filepath.Join(home, "Library", "LaunchAgents", launchdLabel+".plist")
+		return ServiceFile{
+			Kind:    "launchd",
+			Path:    path,
+			Content: buf.String(),
+			Enable:  "launchctl load -w " + path,
+		}, nil
+	}
+	return ServiceFile{}, ErrUnsupportedPlatform
+}
+
+// InstallService writes a systemd user unit or launchd agent that runs
+// `skitz serve` on addr for the current user.
+func InstallService(addr, logPath string) (ServiceFile, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return ServiceFile{}, fmt.Errorf("failed to resolve executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ServiceFile{}, fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	sf, err := GenerateServiceFile(runtime.GOOS, home, exe, addr, logPath)
+	if err != nil {
+		return ServiceFile{}, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(sf.Path), 0755); err != nil {
+		return ServiceFile{}, fmt.Errorf("failed to create service directory: %w", err)
+	}
+	if err := os.WriteFile(sf.Path, []byte(sf.Content), 0644); err != nil {
+		return ServiceFile{}, fmt.Errorf("failed to write service file: %w", err)
+	}
+	return sf, nil
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGenerateServiceFile(t *testing.T) {
+	exe := `/opt/R&D "tools"/100%/skitz`
+
+	unit, err := GenerateServiceFile("linux", "/home/u", exe, DefaultAddr, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unit.Kind != "systemd" || unit.Path != "/home/u/.config/systemd/user/skitz.service" || !strings.Contains(unit.Enable, "enable --now skitz.service") {
+		t.Errorf("unit = %+v", unit)
+	}
+	if want := `ExecStart="/opt/R&D \"tools\"/100%%/skitz" serve --addr "127.0.0.1:7420"`; !strings.Contains(unit.Content, want+"\n") {
+		t.Errorf("unit content = %s, want %s", unit.Content, want)
+	}
+
+	plist, err := GenerateServiceFile("darwin", "/Users/u", exe, DefaultAddr, "/tmp/<skitz>.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plist.Kind != "launchd" || plist.Path != "/Users/u/Library/LaunchAgents/com.htelsiz.skitz.plist" || plist.Enable != "launchctl load -w "+plist.Path {
+		t.Errorf("plist = %+v", plist)
+	}
+	// The plist must stay well-formed XML and keep the values intact
+	var strs []string
+	dec := xml.NewDecoder(strings.NewReader(plist.Content))
+	dec.Strict = true
+	var inString bool
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("invalid plist: %v\n%s", err, plist.Content)
+			}
+			break
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			inString = tok.Name.Local == "string"
+		case xml.CharData:
+			if inString {
+				strs = append(strs, string(tok))
+			}
+		case xml.EndElement:
+			inString = false
+		}
+	}
+	want := []string{"com.htelsiz.skitz", exe, "serve", "--addr", DefaultAddr, "/tmp/<skitz>.log"}
+	if strings.Join(strs, "|") != strings.Join(want, "|") {
+		t.Errorf("plist strings = %q, want %q", strs, want)
+	}
+
+	if _, err := GenerateServiceFile("windows", "", exe, DefaultAddr, ""); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("windows error = %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package server runs skitz headless as a long-lived HTTP service.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultAddr is the address serve mode listens on when none is given.
+const DefaultAddr = "127.0.0.1:7420"
+
+// shutdownTimeout bounds how long in-flight requests may run after SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
+// Server is the headless skitz HTTP server.
+type Server struct {
+	addr    string
+	started time.Time
+	mux     *http.ServeMux
+}
+
+// New creates a server listening on addr.
+func New(addr string) *Server {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	s := &Server{
+		addr: addr,
+		mux:  http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /healthz", s.handleHealthz)
+	return s
+}
+
+// Handle registers an additional handler on the server's mux.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// healthStatus is the JSON body returned by /healthz.
+type healthStatus struct {
+	Status        string    `json:"status"`
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(healthStatus{
+		Status:        "ok",
+		StartedAt:     s.started,
+		UptimeSeconds: int64(time.Since(s.started).Seconds()),
+	})
+}
+
+// Run serves until ctx is cancelled, then shuts down gracefully, letting
+// in-flight requests finish for up to shutdownTimeout.
+func (s *Server) Run(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+	return s.serve(ctx, ln)
+}
+
+// serve is Run on the listener ln
+func (s *Server) serve(ctx context.Context, ln net.Listener) error {
+	s.started = time.Now()
+	srv := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+	slog.Info("server started", "addr", ln.Addr().String())
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("server shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
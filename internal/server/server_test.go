@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthz(t *testing.T) {
+	s := New("")
+	if s.addr != DefaultAddr {
+		t.Errorf("addr = %q, want %q", s.addr, DefaultAddr)
+	}
+	s.started = time.Now().Add(-time.Minute)
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	var status healthStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("response = %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if status.Status != "ok" || status.UptimeSeconds < 60 {
+		t.Errorf("status = %+v", status)
+	}
+
+	rec = httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/healthz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /healthz = %d", rec.Code)
+	}
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	s := New("")
+	started := make(chan struct{})
+	s.Handle("GET /slow", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, "done")
+	}))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.serve(ctx, ln) }()
+
+	// A request in flight when the context is cancelled still completes
+	type result struct {
+		body string
+		err  error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/slow")
+		if err != nil {
+			resCh <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		resCh <- result{string(body), err}
+	}()
+	<-started
+	cancel()
+
+	if res := <-resCh; res.err != nil || res.body != "done" {
+		t.Errorf("in-flight request = %q, %v", res.body, res.err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve() = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server didn't shut down")
+	}
+	if _, err := http.Get("http://" + ln.Addr().String() + "/healthz"); err == nil {
+		t.Error("server still accepting requests")
+	}
+}
@@ -0,0 +1,251 @@
+// Package shellhistory reads zsh, bash and fish history files and groups
+// frequently used commands so they can be imported into resources.
+package shellhistory
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Shell names used by Source.
+const (
+	Zsh  = "zsh"
+	Bash = "bash"
+	Fish = "fish"
+)
+
+// trivialCommands are too common to be worth importing.
+var trivialCommands = map[string]bool{
+	"cd": true, "ls": true, "ll": true, "la": true, "l": true,
+	"pwd": true, "clear": true, "exit": true, "history": true,
+	"skitz": true,
+}
+
+// Source is a history file for a shell.
+type Source struct {
+	Shell string
+	Path  string
+}
+
+// Cluster is a group of commands sharing their first words.
+type Cluster struct {
+	// Key is the shared prefix, such as "git commit".
+	Key string
+	// Command is the most frequent command in the cluster.
+	Command string
+	// Count is how many times commands in the cluster were run.
+	Count int
+	// Variants is the number of distinct commands in the cluster.
+	Variants int
+}
+
+// Sources returns the history files that exist for the current user,
+// honouring $HISTFILE and $XDG_DATA_HOME.
+func Sources() []Source {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	fishDir := os.Getenv("XDG_DATA_HOME")
+	if fishDir == "" {
+		fishDir = filepath.Join(home, ".local", "share")
+	}
+
+	candidates := []Source{
+		{Shell: Zsh, Path: filepath.Join(home, ".zsh_history")},
+		{Shell: Bash, Path: filepath.Join(home, ".bash_history")},
+		{Shell: Fish, Path: filepath.Join(fishDir, "fish", "fish_history")},
+	}
+	if histfile := os.Getenv("HISTFILE"); histfile != "" {
+		shell := Bash
+		if strings.Contains(filepath.Base(histfile), "zsh") || strings.HasSuffix(os.Getenv("SHELL"), "zsh") {
+			shell = Zsh
+		}
+		candidates = append([]Source{{Shell: shell, Path: histfile}}, candidates...)
+	}
+
+	var sources []Source
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if seen[c.Path] {
+			continue
+		}
+		seen[c.Path] = true
+		if info, err := os.Stat(c.Path); err == nil && !info.IsDir() {
+			sources = append(sources, c)
+		}
+	}
+	return sources
+}
+
+// Read returns the commands in a history file, oldest first.
+func Read(src Source) ([]string, error) {
+	data, err := os.ReadFile(src.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s history: %w", src.Shell, err)
+	}
+	return Parse(src.Shell, data), nil
+}
+
+// Parse returns the single-line commands in history data for shell.
+// Multi-line commands are skipped.
+func Parse(shell string, data []byte) []string {
+	switch shell {
+	case Zsh:
+		return parseZsh(data)
+	case Fish:
+		return parseFish(data)
+	default:
+		return parseBash(data)
+	}
+}
+
+// parseZsh handles plain and EXTENDED_HISTORY (": <time>:<secs>;cmd")
+// lines. A trailing backslash continues a command on the next line.
+func parseZsh(data []byte) []string {
+	var commands []string
+	multiline := false
+	for _, line := range strings.Split(string(unmetafy(data)), "\n") {
+		if multiline {
+			multiline = strings.HasSuffix(line, "\\")
+			continue
+		}
+		if strings.HasPrefix(line, ": ") {
+			if i := strings.Index(line, ";"); i >= 0 {
+				line = line[i+1:]
+			}
+		}
+		if strings.HasSuffix(line, "\\") {
+			multiline = true
+			continue
+		}
+		commands = append(commands, line)
+	}
+	return clean(commands)
+}
+
+// unmetafy undoes zsh's history encoding, which stores some bytes (NUL and
+// 0x83 to 0xa2) as 0x83 followed by the byte XOR 32.
+func unmetafy(data []byte) []byte {
+	if bytes.IndexByte(data, 0x83) < 0 {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == 0x83 && i+1 < len(data) {
+			i++
+			out = append(out, data[i]^32)
+			continue
+		}
+		out = append(out, data[i])
+	}
+	return out
+}
+
+// parseBash skips the "#<time>" lines bash writes when HISTTIMEFORMAT is
+// set.
+func parseBash(data []byte) []string {
+	var commands []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if len(line) > 1 && line[0] == '#' && strings.Trim(line[1:], "0123456789") == "" {
+			continue
+		}
+		commands = append(commands, line)
+	}
+	return clean(commands)
+}
+
+// parseFish reads the "- cmd: ..." entries of fish's YAML-like history.
+func parseFish(data []byte) []string {
+	var commands []string
+	for _, line := range strings.Split(string(data), "\n") {
+		cmd, ok := strings.CutPrefix(line, "- cmd: ")
+		if !ok {
+			continue
+		}
+		if strings.Contains(cmd, `\n`) {
+			continue
+		}
+		commands = append(commands, strings.ReplaceAll(cmd, `\\`, `\`))
+	}
+	return clean(commands)
+}
+
+// clean trims commands, collapses runs of whitespace and drops empty and
+// trivial ones.
+func clean(commands []string) []string {
+	out := commands[:0]
+	for _, c := range commands {
+		fields := strings.Fields(c)
+		if len(fields) == 0 || (len(fields) == 1 && trivialCommands[fields[0]]) {
+			continue
+		}
+		if fields[0] == "cd" {
+			continue
+		}
+		out = append(out, strings.Join(fields, " "))
+	}
+	return out
+}
+
+// clusterKey groups commands by program and subcommand, e.g. "git commit"
+// for every "git commit -m ..." variant. Flags, paths and quoted arguments
+// do not count as a subcommand.
+func clusterKey(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) < 2 {
+		return command
+	}
+	sub := fields[1]
+	if strings.ContainsAny(sub[:1], `-/.~$"'`) || strings.ContainsAny(sub, "=/") {
+		return fields[0]
+	}
+	return fields[0] + " " + sub
+}
+
+// Frequent clusters commands and returns up to limit clusters, most used
+// first. A limit of zero or less returns every cluster.
+func Frequent(commands []string, limit int) []Cluster {
+	type group struct {
+		counts map[string]int
+		total  int
+	}
+	groups := make(map[string]*group)
+	for _, c := range commands {
+		key := clusterKey(c)
+		g := groups[key]
+		if g == nil {
+			g = &group{counts: make(map[string]int)}
+			groups[key] = g
+		}
+		g.counts[c]++
+		g.total++
+	}
+
+	clusters := make([]Cluster, 0, len(groups))
+	for key, g := range groups {
+		best, bestCount := "", 0
+		for c, n := range g.counts {
+			if n > bestCount || (n == bestCount && c < best) {
+				best, bestCount = c, n
+			}
+		}
+		clusters = append(clusters, Cluster{Key: key, Command: best, Count: g.total, Variants: len(g.counts)})
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Count != clusters[j].Count {
+			return clusters[i].Count > clusters[j].Count
+		}
+		return clusters[i].Key < clusters[j].Key
+	})
+	if limit > 0 && len(clusters) > limit {
+		clusters = clusters[:limit]
+	}
+	return clusters
+}
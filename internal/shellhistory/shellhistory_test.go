@@ -0,0 +1,80 @@
+package shellhistory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		shell string
+		data  string
+		want  []string
+	}{
+		{
+			name:  "zsh extended history",
+			shell: Zsh,
+			data:  ": 1700000000:0;git status\n: 1700000001:0;docker  ps -a\n",
+			want:  []string{"git status", "docker ps -a"},
+		},
+		{
+			name:  "zsh skips multi-line commands",
+			shell: Zsh,
+			data:  ": 1700000000:0;for f in *; do \\\necho $f\\\ndone\n: 1700000001:0;make test\n",
+			want:  []string{"make test"},
+		},
+		{
+			name:  "zsh metafied bytes",
+			shell: Zsh,
+			data:  "echo a \xe2\x80\x83\xb4 b\n",
+			want:  []string{"echo a — b"},
+		},
+		{
+			name:  "bash with timestamps",
+			shell: Bash,
+			data:  "#1700000000\nkubectl get pods\nls\ncd /tmp\n",
+			want:  []string{"kubectl get pods"},
+		},
+		{
+			name:  "fish",
+			shell: Fish,
+			data:  "- cmd: terraform plan\n  when: 1700000000\n- cmd: echo a\\nb\n  when: 1700000001\n- cmd: grep \\\\d file\n",
+			want:  []string{"terraform plan", `grep \d file`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.shell, []byte(tt.data))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrequent(t *testing.T) {
+	commands := []string{
+		"git commit -m one",
+		"git commit -m two",
+		"git commit -m two",
+		"git status",
+		"git status",
+		"npm ./script.js",
+		"make",
+	}
+
+	got := Frequent(commands, 2)
+	want := []Cluster{
+		{Key: "git commit", Command: "git commit -m two", Count: 3, Variants: 2},
+		{Key: "git status", Command: "git status", Count: 2, Variants: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Frequent() = %+v, want %+v", got, want)
+	}
+
+	if all := Frequent(commands, 0); len(all) != 4 {
+		t.Errorf("Frequent(limit 0) returned %d clusters, want 4", len(all))
+	}
+}
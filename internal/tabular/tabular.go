@@ -0,0 +1,164 @@
+// Package tabular parses the column-aligned output of CLIs such as
+// docker ps, kubectl get and az -o table.
+package tabular
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNotTabular is returned when output has no header with at least two
+// columns.
+var ErrNotTabular = errors.New("output is not a table")
+
+// Table is parsed command output.
+type Table struct {
+	Headers []string
+	Rows    [][]string
+	Lines   []string // the raw output line of each row
+}
+
+// Parse splits output into columns. Columns are separated by tabs when the
+// header contains one; otherwise they start where the header has a word
+// after two or more spaces, or where a rule of dashes under the header
+// (as printed by az) starts a run.
+func Parse(output string) (Table, error) {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		if line = strings.TrimRight(line, " \t\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return Table{}, ErrNotTabular
+	}
+
+	header, body := lines[0], lines[1:]
+	if strings.Contains(header, "\t") {
+		return parseTabs(header, body)
+	}
+
+	starts := columnStarts(header)
+	if len(body) > 0 && isRule(body[0]) {
+		starts = columnStarts(body[0])
+		body = body[1:]
+	}
+	if len(starts) < 2 {
+		return Table{}, ErrNotTabular
+	}
+
+	t := Table{Headers: cut([]rune(header), starts), Lines: body}
+	for _, line := range body {
+		t.Rows = append(t.Rows, cut([]rune(line), starts))
+	}
+	return t, nil
+}
+
+func parseTabs(header string, body []string) (Table, error) {
+	t := Table{Headers: splitTabs(header), Lines: body}
+	if len(t.Headers) < 2 {
+		return Table{}, ErrNotTabular
+	}
+	for _, line := range body {
+		row := splitTabs(line)
+		// Pad short rows so every row has a cell per column
+		for len(row) < len(t.Headers) {
+			row = append(row, "")
+		}
+		t.Rows = append(t.Rows, row[:len(t.Headers)])
+	}
+	return t, nil
+}
+
+func splitTabs(line string) []string {
+	cells := strings.Split(line, "\t")
+	for i, c := range cells {
+		cells[i] = strings.TrimSpace(c)
+	}
+	return cells
+}
+
+// isRule reports whether line is only dashes and spaces, like the line az
+// prints under table headers
+func isRule(line string) bool {
+	return strings.Contains(line, "-") && strings.Trim(line, "- ") == ""
+}
+
+// columnStarts returns the rune offsets where columns start: the first
+// non-space and every non-space after a run of at least two spaces, or
+// after one space when the line is a rule
+func columnStarts(line string) []int {
+	minGap := 2
+	if isRule(line) {
+		minGap = 1
+	}
+	var starts []int
+	spaces := minGap
+	for i, r := range []rune(line) {
+		if r == ' ' {
+			spaces++
+			continue
+		}
+		if spaces >= minGap {
+			starts = append(starts, i)
+		}
+		spaces = 0
+	}
+	return starts
+}
+
+// cut slices line into trimmed cells at starts
+func cut(line []rune, starts []int) []string {
+	cells := make([]string, len(starts))
+	for i, start := range starts {
+		end := len(line)
+		if i+1 < len(starts) {
+			end = min(starts[i+1], len(line))
+		}
+		if start < end {
+			cells[i] = strings.TrimSpace(string(line[start:end]))
+		}
+	}
+	return cells
+}
+
+// Sort orders the rows by column col, comparing numbers numerically. The
+// sort is stable so equal cells keep their output order.
+func (t *Table) Sort(col int, desc bool) {
+	if col < 0 || col >= len(t.Headers) {
+		return
+	}
+	idx := make([]int, len(t.Rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		c := compareCells(t.Rows[a][col], t.Rows[b][col])
+		if desc {
+			return -c
+		}
+		return c
+	})
+
+	rows := make([][]string, len(idx))
+	lines := make([]string, len(idx))
+	for i, j := range idx {
+		rows[i] = t.Rows[j]
+		if j < len(t.Lines) {
+			lines[i] = t.Lines[j]
+		}
+	}
+	t.Rows, t.Lines = rows, lines
+}
+
+func compareCells(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return cmp.Compare(x, y)
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
@@ -0,0 +1,115 @@
+package tabular
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		headers []string
+		rows    [][]string
+	}{
+		{
+			name: "docker ps",
+			output: `CONTAINER ID   IMAGE          COMMAND                  STATUS         NAMES
+3f4e8a1b2c3d   nginx:latest   "/docker-entrypoint.…"   Up 2 minutes   web
+9a8b7c6d5e4f   postgres:16    "docker-entrypoint.s…"   Up 2 minutes   db
+`,
+			headers: []string{"CONTAINER ID", "IMAGE", "COMMAND", "STATUS", "NAMES"},
+			rows: [][]string{
+				{"3f4e8a1b2c3d", "nginx:latest", `"/docker-entrypoint.…"`, "Up 2 minutes", "web"},
+				{"9a8b7c6d5e4f", "postgres:16", `"docker-entrypoint.s…"`, "Up 2 minutes", "db"},
+			},
+		},
+		{
+			name: "kubectl get with empty cell",
+			output: `NAME    READY   STATUS    RESTARTS   AGE
+web-1   1/1     Running   0          5d
+job-2   0/1               3          1h
+`,
+			headers: []string{"NAME", "READY", "STATUS", "RESTARTS", "AGE"},
+			rows: [][]string{
+				{"web-1", "1/1", "Running", "0", "5d"},
+				{"job-2", "0/1", "", "3", "1h"},
+			},
+		},
+		{
+			name: "az table with rule",
+			output: `Name    ResourceGroup    Location
+------  ---------------  ----------
+vm one  rg-prod          westeurope
+`,
+			headers: []string{"Name", "ResourceGroup", "Location"},
+			rows:    [][]string{{"vm one", "rg-prod", "westeurope"}},
+		},
+		{
+			name:    "tab separated",
+			output:  "NAME\tSIZE\nalpine\t7MB\nbusybox\n",
+			headers: []string{"NAME", "SIZE"},
+			rows:    [][]string{{"alpine", "7MB"}, {"busybox", ""}},
+		},
+		{
+			name:    "header only",
+			output:  "NAME   STATUS\n",
+			headers: []string{"NAME", "STATUS"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.output)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !slices.Equal(got.Headers, tt.headers) {
+				t.Errorf("Headers = %q, want %q", got.Headers, tt.headers)
+			}
+			if !slices.EqualFunc(got.Rows, tt.rows, slices.Equal) {
+				t.Errorf("Rows = %q, want %q", got.Rows, tt.rows)
+			}
+			if len(got.Lines) != len(got.Rows) {
+				t.Errorf("len(Lines) = %d, want %d", len(got.Lines), len(got.Rows))
+			}
+		})
+	}
+}
+
+func TestParseNotTabular(t *testing.T) {
+	for _, output := range []string{"", "\n\n", "hello world\n", "Error: no such container"} {
+		if _, err := Parse(output); !errors.Is(err, ErrNotTabular) {
+			t.Errorf("Parse(%q) error = %v, want ErrNotTabular", output, err)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	tbl, err := Parse(`NAME   RESTARTS
+web    10
+api    2
+db     2
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tbl.Sort(1, false)
+	var names []string
+	for _, row := range tbl.Rows {
+		names = append(names, row[0])
+	}
+	// Numeric, and stable for equal cells
+	if want := []string{"api", "db", "web"}; !slices.Equal(names, want) {
+		t.Errorf("ascending = %v, want %v", names, want)
+	}
+	if tbl.Lines[2] != "web    10" {
+		t.Errorf("Lines not sorted with rows: %q", tbl.Lines)
+	}
+
+	tbl.Sort(0, true)
+	if tbl.Rows[0][0] != "web" {
+		t.Errorf("descending first = %q, want web", tbl.Rows[0][0])
+	}
+}
@@ -0,0 +1,450 @@
+// Package update checks GitHub releases for newer versions of skitz, builds
+// a changelog from their notes, and replaces the running binary with a
+// release asset.
+package update
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the release this binary was built from. Release builds set it
+// with -ldflags "-X github.com/htelsiz/skitz/internal/update.Version=v1.2.3".
+var Version = "dev"
+
+// Repo is the GitHub repository releases are published in.
+const Repo = "htelsiz/skitz"
+
+// DefaultAPIURL is the GitHub REST API releases are fetched from.
+const DefaultAPIURL = "https://api.github.com"
+
+// PublicKey is the base64 ed25519 key release checksums are signed with.
+// Release builds set it with -ldflags "-X
+// github.com/htelsiz/skitz/internal/update.PublicKey=..."; when set, Install
+// requires a valid signature of the checksums file in a .sig asset.
+var PublicKey = ""
+
+// Errors returned by Install.
+var (
+	ErrNoAsset      = errors.New("no release asset for this platform")
+	ErrNoChecksum   = errors.New("release has no checksum for the binary, refusing to install it")
+	ErrBadChecksum  = errors.New("checksum mismatch")
+	ErrNoSignature  = errors.New("release checksums aren't signed, refusing to install")
+	ErrBadSignature = errors.New("release checksums signature is invalid")
+)
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// downloadClient allows for large assets on slow connections.
+var downloadClient = &http.Client{Timeout: 5 * time.Minute}
+
+// Release is a published GitHub release.
+type Release struct {
+	Tag         string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	URL         string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+	Size int64  `json:"size"`
+}
+
+// Current returns the running version: Version for release builds, the
+// module version for go install builds, or "dev".
+func Current() string {
+	if Version != "dev" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return Version
+}
+
+// Installable reports whether this binary came from a release asset and
+// so can replace itself with a newer one.
+func Installable() bool {
+	return Version != "dev"
+}
+
+// Releases returns the published, non-prerelease releases, newest first.
+func Releases(ctx context.Context, apiURL string) ([]Release, error) {
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(apiURL, "/")+"/repos/"+Repo+"/releases?per_page=20", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("failed to fetch releases: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var all []Release
+	if err := json.NewDecoder(resp.Body).Decode(&all); err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+	var releases []Release
+	for _, r := range all {
+		if !r.Draft && !r.Prerelease {
+			releases = append(releases, r)
+		}
+	}
+	return releases, nil
+}
+
+// Newer reports whether version v is newer than current. Versions are
+// compared as semantic versions with an optional "v"; anything else, such
+// as "dev", is never newer or older.
+func Newer(v, current string) bool {
+	a, aok := parseVersion(v)
+	b, bok := parseVersion(current)
+	if !aok || !bok {
+		return false
+	}
+	for i := range 3 {
+		if a.parts[i] != b.parts[i] {
+			return a.parts[i] > b.parts[i]
+		}
+	}
+	// A release is newer than its prereleases
+	return a.pre == "" && b.pre != "" || a.pre != "" && b.pre != "" && a.pre > b.pre
+}
+
+type version struct {
+	parts [3]int
+	pre   string
+}
+
+func parseVersion(s string) (version, bool) {
+	var v version
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, v.pre, _ = strings.Cut(s, "-")
+	s, _, _ = strings.Cut(s, "+")
+	fields := strings.Split(s, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return v, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.parts[i] = n
+	}
+	return v, true
+}
+
+// Since returns the releases newer than current, newest first. When
+// current isn't a release version it returns the latest release only.
+func Since(releases []Release, current string) []Release {
+	if _, ok := parseVersion(current); !ok {
+		if len(releases) > 0 {
+			return releases[:1]
+		}
+		return nil
+	}
+	var newer []Release
+	for _, r := range releases {
+		if Newer(r.Tag, current) {
+			newer = append(newer, r)
+		}
+	}
+	return newer
+}
+
+// Changelog renders release notes as Markdown.
+func Changelog(releases []Release) string {
+	var b strings.Builder
+	for i, r := range releases {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		title := r.Tag
+		if r.Name != "" && r.Name != r.Tag {
+			title += " · " + r.Name
+		}
+		fmt.Fprintf(&b, "## %s\n\n", title)
+		if !r.PublishedAt.IsZero() {
+			fmt.Fprintf(&b, "*Released %s*\n\n", r.PublishedAt.Format("Jan 2, 2006"))
+		}
+		if body := strings.TrimSpace(r.Body); body != "" {
+			b.WriteString(body + "\n")
+		} else {
+			b.WriteString("No release notes.\n")
+		}
+	}
+	return b.String()
+}
+
+// State remembers the last startup check so it runs at most once per
+// interval.
+type State struct {
+	LastChecked time.Time `json:"last_checked"`
+	Latest      string    `json:"latest,omitempty"`
+}
+
+// StatePath returns the check state file in dataDir.
+func StatePath(dataDir string) string {
+	return filepath.Join(dataDir, "update-check.json")
+}
+
+// LoadState reads the check state. A missing or unreadable file is an
+// empty state.
+func LoadState(path string) State {
+	var st State
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &st)
+	}
+	return st
+}
+
+// Save writes the check state to path.
+func (st State) Save(path string) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write update state: %w", err)
+	}
+	return nil
+}
+
+// Due reports whether interval has passed since the last check.
+func (st State) Due(interval time.Duration, now time.Time) bool {
+	return now.Sub(st.LastChecked) >= interval
+}
+
+// AssetFor returns the release's binary for goos and goarch: a raw binary
+// or a .tar.gz archive named after the platform, e.g.
+// skitz_linux_amd64.tar.gz.
+func (r Release) AssetFor(goos, goarch string) (Asset, bool) {
+	arches := []string{goarch}
+	switch goarch {
+	case "amd64":
+		arches = append(arches, "x86_64")
+	case "arm64":
+		arches = append(arches, "aarch64")
+	}
+	for _, a := range r.Assets {
+		name := strings.ToLower(a.Name)
+		if !strings.HasPrefix(name, "skitz") || !strings.Contains(name, goos) || isChecksum(name) {
+			continue
+		}
+		if ext := filepath.Ext(name); ext == ".zip" || ext == ".deb" || ext == ".rpm" {
+			continue
+		}
+		for _, arch := range arches {
+			if strings.Contains(name, arch) {
+				return a, true
+			}
+		}
+	}
+	return Asset{}, false
+}
+
+func isChecksum(name string) bool {
+	return strings.Contains(name, "checksum") || strings.HasSuffix(name, ".sha256") || strings.HasSuffix(name, ".sig")
+}
+
+// Install downloads the release's binary for this platform and replaces
+// exe with it. The download must match the sha256 in the release's
+// checksums file, and the checksums must be signed with PublicKey when the
+// binary was built with one; otherwise exe is left alone.
+func Install(ctx context.Context, r Release, goos, goarch, exe string) error {
+	asset, ok := r.AssetFor(goos, goarch)
+	if !ok {
+		return fmt.Errorf("%w: %s/%s in %s", ErrNoAsset, goos, goarch, r.Tag)
+	}
+
+	data, err := download(ctx, asset.URL)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksum(ctx, r, asset.Name, data); err != nil {
+		return err
+	}
+
+	binary := data
+	if strings.HasSuffix(asset.Name, ".tar.gz") || strings.HasSuffix(asset.Name, ".tgz") {
+		if binary, err = extractBinary(data); err != nil {
+			return err
+		}
+	}
+
+	// Write next to exe so the rename replaces it in one step
+	tmp, err := os.CreateTemp(filepath.Dir(exe), ".skitz-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the new binary: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return fmt.Errorf("failed to make the new binary executable: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return data, nil
+}
+
+// verifyChecksum checks data against the sha256 for name in the release's
+// checksums file, which must exist and, with a PublicKey, be signed.
+func verifyChecksum(ctx context.Context, r Release, name string, data []byte) error {
+	var sums *Asset
+	for i, a := range r.Assets {
+		lower := strings.ToLower(a.Name)
+		if lower == strings.ToLower(name)+".sha256" || (strings.Contains(lower, "checksums") && !strings.HasSuffix(lower, ".sig")) {
+			sums = &r.Assets[i]
+			break
+		}
+	}
+	if sums == nil {
+		return fmt.Errorf("%w: %s in %s", ErrNoChecksum, name, r.Tag)
+	}
+
+	list, err := download(ctx, sums.URL)
+	if err != nil {
+		return err
+	}
+	if err := verifySignature(ctx, r, *sums, list); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	got := hex.EncodeToString(sum[:])
+	scanner := bufio.NewScanner(bytes.NewReader(list))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		// "<sum>  <name>", or just "<sum>" in a per-asset .sha256 file
+		if len(fields) == 1 || strings.TrimPrefix(fields[len(fields)-1], "*") == name {
+			if !strings.EqualFold(fields[0], got) {
+				return fmt.Errorf("%w for %s", ErrBadChecksum, name)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s isn't listed in %s", ErrNoChecksum, name, sums.Name)
+}
+
+// verifySignature checks the ed25519 signature of the checksums file in
+// its .sig asset, raw or base64, when the binary has a PublicKey
+func verifySignature(ctx context.Context, r Release, sums Asset, list []byte) error {
+	if PublicKey == "" {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: the built-in public key is malformed", ErrBadSignature)
+	}
+	var sigAsset *Asset
+	for i, a := range r.Assets {
+		if a.Name == sums.Name+".sig" {
+			sigAsset = &r.Assets[i]
+		}
+	}
+	if sigAsset == nil {
+		return fmt.Errorf("%w: no %s.sig in %s", ErrNoSignature, sums.Name, r.Tag)
+	}
+	sig, err := download(ctx, sigAsset.URL)
+	if err != nil {
+		return err
+	}
+	if len(sig) != ed25519.SignatureSize {
+		if sig, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig))); err != nil {
+			return fmt.Errorf("%w: %s isn't a signature", ErrBadSignature, sigAsset.Name)
+		}
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), list, sig) {
+		return fmt.Errorf("%w: %s", ErrBadSignature, sigAsset.Name)
+	}
+	return nil
+}
+
+// extractBinary returns the skitz executable in a .tar.gz archive.
+func extractBinary(data []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no skitz binary in the archive")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && (filepath.Base(hdr.Name) == "skitz" || filepath.Base(hdr.Name) == "skitz.exe") {
+			return io.ReadAll(tr)
+		}
+	}
+}
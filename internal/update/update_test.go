@@ -0,0 +1,299 @@
+package update
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		v, current string
+		want       bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"1.2.0", "v1.2.0", false},
+		{"v1.2.0", "v1.2.0-rc.1", true},
+		{"v1.2.0-rc.2", "v1.2.0-rc.1", true},
+		{"v1.2.0-rc.1", "v1.2.0", false},
+		{"v2", "v1.9.9", true},
+		{"v1.1.9", "v1.2.0", false},
+		{"v1.2.0", "dev", false},
+		{"dev", "v1.2.0", false},
+		{"", "v1.2.0", false},
+	}
+	for _, tt := range tests {
+		if got := Newer(tt.v, tt.current); got != tt.want {
+			t.Errorf("Newer(%q, %q) = %v, want %v", tt.v, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestReleases(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/"+Repo+"/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode([]Release{
+			{Tag: "v1.3.0-rc.1", Prerelease: true},
+			{Tag: "v1.2.0", Body: "Faster palette"},
+			{Tag: "v1.1.1", Draft: true},
+			{Tag: "v1.1.0"},
+		})
+	}))
+	defer srv.Close()
+
+	releases, err := Releases(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for _, r := range releases {
+		tags = append(tags, r.Tag)
+	}
+	if got := strings.Join(tags, ","); got != "v1.2.0,v1.1.0" {
+		t.Errorf("tags = %s, want v1.2.0,v1.1.0", got)
+	}
+
+	srv.Close()
+	if _, err := Releases(context.Background(), srv.URL); err == nil {
+		t.Error("expected an error when the API is unreachable")
+	}
+}
+
+func TestSinceAndChangelog(t *testing.T) {
+	releases := []Release{
+		{Tag: "v1.2.0", Name: "Palette", Body: "Faster palette", PublishedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{Tag: "v1.1.0"},
+		{Tag: "v1.0.0", Body: "First release"},
+	}
+
+	if got := Since(releases, "v1.0.0"); len(got) != 2 {
+		t.Errorf("Since(v1.0.0) = %d releases, want 2", len(got))
+	}
+	if got := Since(releases, "v1.2.0"); len(got) != 0 {
+		t.Errorf("Since(v1.2.0) = %d releases, want 0", len(got))
+	}
+	if got := Since(releases, "dev"); len(got) != 1 || got[0].Tag != "v1.2.0" {
+		t.Errorf("Since(dev) = %v, want the latest release", got)
+	}
+
+	log := Changelog(releases[:2])
+	for _, want := range []string{"## v1.2.0 · Palette", "*Released Mar 1, 2026*", "Faster palette", "## v1.1.0", "No release notes."} {
+		if !strings.Contains(log, want) {
+			t.Errorf("changelog missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	path := StatePath(t.TempDir())
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	st := LoadState(path)
+	if !st.Due(24*time.Hour, now) {
+		t.Error("a first check should be due")
+	}
+
+	if err := (State{LastChecked: now, Latest: "v1.2.0"}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	st = LoadState(path)
+	if st.Latest != "v1.2.0" {
+		t.Errorf("Latest = %q, want v1.2.0", st.Latest)
+	}
+	if st.Due(24*time.Hour, now.Add(time.Hour)) {
+		t.Error("check due an hour after the last one")
+	}
+	if !st.Due(24*time.Hour, now.Add(25*time.Hour)) {
+		t.Error("check not due after the interval")
+	}
+}
+
+func TestAssetFor(t *testing.T) {
+	r := Release{Assets: []Asset{
+		{Name: "checksums.txt"},
+		{Name: "skitz_darwin_arm64.tar.gz"},
+		{Name: "skitz_linux_x86_64.tar.gz"},
+		{Name: "skitz_linux_amd64.deb"},
+		{Name: "skitz_windows_amd64.zip"},
+	}}
+
+	tests := []struct {
+		goos, goarch string
+		want         string
+	}{
+		{"linux", "amd64", "skitz_linux_x86_64.tar.gz"},
+		{"darwin", "arm64", "skitz_darwin_arm64.tar.gz"},
+		{"windows", "amd64", ""},
+		{"linux", "arm64", ""},
+	}
+	for _, tt := range tests {
+		a, ok := r.AssetFor(tt.goos, tt.goarch)
+		if a.Name != tt.want || ok != (tt.want != "") {
+			t.Errorf("AssetFor(%s, %s) = %q, %v, want %q", tt.goos, tt.goarch, a.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new\n")
+	archive := tarGz(t, "skitz", binary)
+	sum := sha256.Sum256(archive)
+	checksums := hex.EncodeToString(sum[:]) + "  skitz_linux_amd64.tar.gz\n"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/skitz_linux_amd64.tar.gz":
+			w.Write(archive)
+		case "/checksums.txt":
+			w.Write([]byte(checksums))
+		case "/bad_checksums.txt":
+			w.Write([]byte(strings.Repeat("0", 64) + "  skitz_linux_amd64.tar.gz\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	release := func(sums string) Release {
+		return Release{Tag: "v1.2.0", Assets: []Asset{
+			{Name: "skitz_linux_amd64.tar.gz", URL: srv.URL + "/skitz_linux_amd64.tar.gz"},
+			{Name: "checksums.txt", URL: srv.URL + sums},
+		}}
+	}
+
+	exe := filepath.Join(t.TempDir(), "skitz")
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Install(context.Background(), release("/bad_checksums.txt"), "linux", "amd64", exe); err == nil {
+		t.Error("expected a checksum mismatch")
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Error("binary replaced despite a checksum mismatch")
+	}
+
+	if err := Install(context.Background(), release("/checksums.txt"), "linux", "amd64", exe); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, binary) {
+		t.Errorf("binary = %q, want %q", data, binary)
+	}
+	if info, _ := os.Stat(exe); info.Mode().Perm()&0100 == 0 {
+		t.Errorf("binary mode = %v, want executable", info.Mode())
+	}
+
+	if err := Install(context.Background(), release("/checksums.txt"), "plan9", "386", exe); err == nil {
+		t.Error("expected an error for a platform without an asset")
+	}
+
+	// Without a checksums asset the binary isn't installed
+	os.WriteFile(exe, []byte("old"), 0755)
+	unverified := release("/checksums.txt")
+	unverified.Assets = unverified.Assets[:1]
+	if err := Install(context.Background(), unverified, "linux", "amd64", exe); !errors.Is(err, ErrNoChecksum) {
+		t.Errorf("Install() without checksums error = %v, want ErrNoChecksum", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Error("binary replaced without a checksum")
+	}
+}
+
+func TestInstallSignature(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new\n")
+	sum := sha256.Sum256(binary)
+	checksums := []byte(hex.EncodeToString(sum[:]) + "  skitz_linux_amd64\n")
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/skitz_linux_amd64":
+			w.Write(binary)
+		case "/checksums.txt":
+			w.Write(checksums)
+		case "/good.sig":
+			w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, checksums)) + "\n"))
+		case "/bad.sig":
+			w.Write(ed25519.Sign(otherPriv, checksums))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	orig := PublicKey
+	PublicKey = base64.StdEncoding.EncodeToString(pub)
+	defer func() { PublicKey = orig }()
+
+	release := func(sig string) Release {
+		r := Release{Tag: "v1.2.0", Assets: []Asset{
+			{Name: "skitz_linux_amd64", URL: srv.URL + "/skitz_linux_amd64"},
+			{Name: "checksums.txt", URL: srv.URL + "/checksums.txt"},
+		}}
+		if sig != "" {
+			r.Assets = append(r.Assets, Asset{Name: "checksums.txt.sig", URL: srv.URL + sig})
+		}
+		return r
+	}
+	exe := filepath.Join(t.TempDir(), "skitz")
+
+	if err := Install(context.Background(), release(""), "linux", "amd64", exe); !errors.Is(err, ErrNoSignature) {
+		t.Errorf("unsigned release error = %v, want ErrNoSignature", err)
+	}
+	if err := Install(context.Background(), release("/bad.sig"), "linux", "amd64", exe); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("wrongly signed release error = %v, want ErrBadSignature", err)
+	}
+	if _, err := os.Stat(exe); err == nil {
+		t.Fatal("binary installed without a valid signature")
+	}
+	if err := Install(context.Background(), release("/good.sig"), "linux", "amd64", exe); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(exe); !bytes.Equal(data, binary) {
+		t.Errorf("binary = %q", data)
+	}
+}
+
+func tarGz(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "README.md", Mode: 0644, Size: 2, Typeflag: tar.TypeReg})
+	tw.Write([]byte("hi"))
+	tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(data)), Typeflag: tar.TypeReg})
+	tw.Write(data)
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,195 @@
+// Package webhook posts run summaries to Slack, Microsoft Teams and generic
+// JSON webhooks.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+// Webhook types.
+const (
+	TypeSlack = "slack"
+	TypeTeams = "teams"
+	TypeJSON  = "json"
+)
+
+// Events a webhook can subscribe to.
+const (
+	EventAgent  = "agent"
+	EventDeploy = "deploy"
+)
+
+// MaxOutput is how much of a run's output is posted; longer output keeps
+// its end, where results and errors usually are.
+const MaxOutput = 1500
+
+// ErrUnknownType is returned for a webhook type other than slack, teams
+// or json.
+var ErrUnknownType = errors.New("unknown webhook type")
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Summary describes a finished agent run or deployment.
+type Summary struct {
+	Event    string        `json:"event"`
+	Title    string        `json:"title"` // e.g. "Agent code-reviewer"
+	Task     string        `json:"task"`
+	Success  bool          `json:"success"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration_ns"`
+	Output   string        `json:"output,omitempty"`
+	User     string        `json:"user"`              // user@host that ran it
+	Details  []Detail      `json:"details,omitempty"` // e.g. provider and model
+}
+
+// Detail is a labelled value shown with a summary.
+type Detail struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CurrentUser returns user@host for Summary.User.
+func CurrentUser() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		return name
+	}
+	return name + "@" + host
+}
+
+// Wants reports whether hook should receive s.
+func Wants(hook config.WebhookConfig, s Summary) bool {
+	if hook.URL == "" {
+		return false
+	}
+	if len(hook.Events) > 0 && !slices.Contains(hook.Events, s.Event) {
+		return false
+	}
+	return !hook.OnlyFailures || !s.Success
+}
+
+// Post sends s to hook.
+func Post(ctx context.Context, hook config.WebhookConfig, s Summary) error {
+	body, err := Payload(hook.Type, s)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Payload formats s as the JSON body for a webhook type.
+func Payload(kind string, s Summary) ([]byte, error) {
+	s.Output = truncateOutput(s.Output)
+	switch kind {
+	case "", TypeSlack:
+		return json.Marshal(slackPayload(s))
+	case TypeTeams:
+		return json.Marshal(teamsPayload(s))
+	case TypeJSON:
+		return json.Marshal(s)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownType, kind)
+}
+
+// headline is the one-line description of s, e.g.
+// "✅ Agent code-reviewer succeeded in 1m30s".
+func headline(s Summary) string {
+	icon := "✅"
+	if !s.Success {
+		icon = "❌"
+	}
+	return fmt.Sprintf("%s %s %s in %s", icon, s.Title, s.Status, s.Duration.Round(time.Second))
+}
+
+func slackPayload(s Summary) map[string]any {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*\n", headline(s))
+	fmt.Fprintf(&b, "*Task:* %s\n", s.Task)
+	for _, d := range s.Details {
+		fmt.Fprintf(&b, "*%s:* %s\n", d.Name, d.Value)
+	}
+	fmt.Fprintf(&b, "*By:* %s", s.User)
+	if s.Output != "" {
+		fmt.Fprintf(&b, "\n```%s```", s.Output)
+	}
+	return map[string]any{
+		"text": headline(s),
+		"blocks": []map[string]any{{
+			"type": "section",
+			"text": map[string]string{"type": "mrkdwn", "text": b.String()},
+		}},
+	}
+}
+
+func teamsPayload(s Summary) map[string]any {
+	color := "2EB67D"
+	if !s.Success {
+		color = "E01E5A"
+	}
+	facts := []map[string]string{{"name": "Task", "value": s.Task}}
+	for _, d := range s.Details {
+		facts = append(facts, map[string]string{"name": d.Name, "value": d.Value})
+	}
+	facts = append(facts, map[string]string{"name": "By", "value": s.User})
+
+	section := map[string]any{"facts": facts}
+	if s.Output != "" {
+		section["text"] = "<pre>" + html.EscapeString(s.Output) + "</pre>"
+	}
+	return map[string]any{
+		"@type":      "MessageCard",
+		"@context":   "https://schema.org/extensions",
+		"themeColor": color,
+		"summary":    headline(s),
+		"title":      headline(s),
+		"sections":   []map[string]any{section},
+	}
+}
+
+// truncateOutput keeps the last MaxOutput bytes of output, cut at a line
+// start where possible.
+func truncateOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) <= MaxOutput {
+		return output
+	}
+	tail := output[len(output)-MaxOutput:]
+	if i := strings.IndexByte(tail, '\n'); i >= 0 && i < len(tail)-1 {
+		tail = tail[i+1:]
+	}
+	return "…\n" + strings.ToValidUTF8(tail, "")
+}
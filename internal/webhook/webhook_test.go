@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/htelsiz/skitz/internal/config"
+)
+
+var summary = Summary{
+	Event:    EventDeploy,
+	Title:    "Deploy agent-1",
+	Task:     "Review PR 42",
+	Success:  false,
+	Status:   "failed",
+	Duration: 95 * time.Second,
+	Output:   "quota exceeded <eastus>",
+	User:     "ada@build",
+	Details:  []Detail{{Name: "Provider", Value: "Azure"}},
+}
+
+func TestWants(t *testing.T) {
+	tests := []struct {
+		name string
+		hook config.WebhookConfig
+		s    Summary
+		want bool
+	}{
+		{"no url", config.WebhookConfig{}, summary, false},
+		{"all events", config.WebhookConfig{URL: "u"}, summary, true},
+		{"other event", config.WebhookConfig{URL: "u", Events: []string{EventAgent}}, summary, false},
+		{"only failures, failed", config.WebhookConfig{URL: "u", OnlyFailures: true}, summary, true},
+		{"only failures, succeeded", config.WebhookConfig{URL: "u", OnlyFailures: true}, Summary{Event: EventAgent, Success: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Wants(tt.hook, tt.s); got != tt.want {
+				t.Errorf("Wants() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{"", []string{`"text":"❌ Deploy agent-1 failed in 1m35s"`, `*Provider:* Azure`, "*By:* ada@build"}},
+		{TypeTeams, []string{`"@type":"MessageCard"`, `"themeColor":"E01E5A"`, `&lt;eastus&gt;`}},
+		{TypeJSON, []string{`"event":"deploy"`, `"task":"Review PR 42"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			body, err := Payload(tt.kind, summary)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var v any
+			if err := json.Unmarshal(body, &v); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			// Undo encoding/json's HTML escaping for readable expectations
+			text := strings.NewReplacer(`\u003c`, "<", `\u003e`, ">", `\u0026`, "&").Replace(string(body))
+			for _, w := range tt.want {
+				if !strings.Contains(text, w) {
+					t.Errorf("payload %s missing %q", text, w)
+				}
+			}
+		})
+	}
+
+	if _, err := Payload("discord", summary); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("err = %v, want ErrUnknownType", err)
+	}
+}
+
+func TestTruncateOutput(t *testing.T) {
+	long := strings.Repeat("line of output\n", 200) + "final error"
+	got := truncateOutput(long)
+	if len(got) > MaxOutput+5 || !strings.HasPrefix(got, "…\nline") || !strings.HasSuffix(got, "final error") {
+		t.Errorf("truncateOutput() = %q...", got[:40])
+	}
+	if got := truncateOutput("short\n"); got != "short" {
+		t.Errorf("truncateOutput(short) = %q", got)
+	}
+}
+
+func TestPost(t *testing.T) {
+	var got []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("content type = %q", r.Header.Get("Content-Type"))
+		}
+		got, _ = io.ReadAll(r.Body)
+		if strings.Contains(string(got), "bad") {
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	if err := Post(context.Background(), config.WebhookConfig{URL: srv.URL, Type: TypeJSON}, summary); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), `"user":"ada@build"`) {
+		t.Errorf("posted %s", got)
+	}
+
+	bad := summary
+	bad.Task = "bad"
+	err := Post(context.Background(), config.WebhookConfig{URL: srv.URL}, bad)
+	if err == nil || !strings.Contains(err.Error(), "invalid_payload") {
+		t.Errorf("err = %v, want the response body", err)
+	}
+}
@@ -1,20 +1,48 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 
 	"github.com/htelsiz/skitz/internal/app"
+	"github.com/htelsiz/skitz/internal/jobs"
+	"github.com/htelsiz/skitz/internal/logging"
 )
 
 func main() {
-	resource := ""
-	if len(os.Args) > 1 {
-		resource = os.Args[1]
+	if jobs.Filter() {
+		return
 	}
 
-	if err := app.Run(resource); err != nil {
+	debug := flag.Bool("debug", false, "enable debug logging (logs every UI message)")
+	jsonOutput := flag.Bool("json", false, "machine-readable JSON output for subcommands")
+	a11y := flag.Bool("a11y", false, "accessibility mode for screen readers: plain text without borders, animations or emoji")
+	flag.Parse()
+
+	if err := logging.Setup(logging.Options{Debug: *debug}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: logging disabled: %v\n", err)
+	}
+	defer logging.Close()
+
+	if app.IsCLICommand(flag.Arg(0)) {
+		err := app.RunCLI(flag.Args(), app.CLIOptions{JSON: *jsonOutput})
+		if err != nil {
+			if !errors.Is(err, app.ErrLintFailed) && !errors.Is(err, app.ErrDoctorFailed) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			}
+			logging.Close()
+			os.Exit(1)
+		}
+		return
+	}
+
+	resource := flag.Arg(0)
+
+	if err := app.Run(resource, app.RunOptions{Accessible: *a11y}); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		logging.Close()
 		os.Exit(1)
 	}
 }